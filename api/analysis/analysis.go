@@ -7,39 +7,166 @@ package analysis
 import (
 	"time"
 
+	"github.com/globocom/huskyCI/api/clone"
+	"github.com/globocom/huskyCI/api/containerenv"
 	apiContext "github.com/globocom/huskyCI/api/context"
 	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/summarize"
+	"github.com/globocom/huskyCI/api/truncate"
 	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/upload"
+	"github.com/globocom/huskyCI/api/util"
 	"gopkg.in/mgo.v2/bson"
 )
 
 const logActionStart = "StartAnalysis"
 const logInfoAnalysis = "ANALYSIS"
 
-// StartAnalysis starts the analysis given a RID and a repository.
-func StartAnalysis(RID string, repository types.Repository) {
+// startAnalysis registers and runs the analysis RID of repository, admitted into the queue with ticket. It is
+// registered as queued until it takes a slot to run.
+func startAnalysis(RID string, repository types.Repository, originRID string, ticket *queueTicket) {
 
 	// step 1: create a new analysis into MongoDB based on repository received
-	if err := registerNewAnalysis(RID, repository); err != nil {
+	queued := runningAnalysesLimited()
+	if err := registerNewAnalysis(RID, repository, originRID, queued); err != nil {
+		removeUpload(RID, repository)
 		return
 	}
-	log.Info(logActionStart, logInfoAnalysis, 101, RID)
+	if !inFlight.begin(RID, repository.URL) {
+		// the analysis was submitted right before the shutdown began, so it is left to the next API process.
+		registerInterruptedAnalysis(RID, repository.URL)
+		return
+	}
+	defer inFlight.end(RID)
+	log.ForAnalysis(RID, repository.URL).Info(logActionStart, logInfoAnalysis, 101, RID)
+
+	runAnalysis(RID, repository, nil, ticket, queued)
+}
+
+// repositoryOf returns the repository, with the parameters requested, that analysis was started for.
+func repositoryOf(analysis types.Analysis) types.Repository {
+	return types.Repository{
+		URL:               analysis.URL,
+		Branch:            analysis.Branch,
+		SubPath:           analysis.SubPath,
+		Commit:            analysis.Commit,
+		TimeOutInSeconds:  analysis.TimeOutInSeconds,
+		FailFastSeverity:  analysis.FailFastSeverity,
+		ImageReference:    analysis.ImageReference,
+		BaseRef:           analysis.BaseRef,
+		SecurityTestArgs:  analysis.SecurityTestArgs,
+		SecurityTestEnv:   containerenv.Unmasked(analysis.SecurityTestEnv),
+		Tags:              analysis.Tags,
+		Source:            analysis.Source,
+		Profile:           analysis.Profile,
+		SecurityTests:     analysis.SecurityTests,
+		BranchPolicy:      analysis.BranchPolicy,
+		FailSeverity:      analysis.FailSeverity,
+		SkipNotifications: analysis.SkipNotifications,
+	}
+}
+
+// ResumeRunningAnalyses resumes every analysis left running or interrupted by a previous API process.
+func ResumeRunningAnalyses() {
+	for _, status := range []string{"running", types.QueuedStatus, InterruptedStatus} {
+		analysisQuery := map[string]interface{}{"status": status}
+		runningAnalyses, err := apiContext.APIConfiguration.DBInstance.FindAllDBAnalysis(analysisQuery)
+		if err != nil {
+			if err.Error() != "No data found" {
+				log.Error("ResumeRunningAnalyses", logInfoAnalysis, 2018, err)
+			}
+			continue
+		}
+		for _, runningAnalysis := range runningAnalyses {
+			go ResumeAnalysis(runningAnalysis)
+		}
+	}
+}
+
+// ResumeAnalysis runs again an analysis that was interrupted, running only the securityTests
+// that did not finish. Finished ones have their results parsed again from their containers.
+// The deploy key of the repository is never stored, so the resumed securityTests use the global one.
+// Neither is its CloneURL, so a repository whose URL had credentials is cloned again without them, nor
+// the plain values of its securityTestEnv, so only the secrets it references are injected again.
+func ResumeAnalysis(interruptedAnalysis types.Analysis) {
+	if !inFlight.begin(interruptedAnalysis.RID, interruptedAnalysis.URL) {
+		return
+	}
+	defer inFlight.end(interruptedAnalysis.RID)
+	logger := log.ForAnalysis(interruptedAnalysis.RID, interruptedAnalysis.URL)
+	logger.Info("ResumeAnalysis", logInfoAnalysis, 103, interruptedAnalysis.RID)
+
+	if interruptedAnalysis.Status == InterruptedStatus {
+		analysisQuery := map[string]interface{}{"RID": interruptedAnalysis.RID}
+		updateAnalysisQuery := bson.M{"status": "running", "result": "", "errorFound": ""}
+		if err := apiContext.APIConfiguration.DBInstance.UpdateOneDBAnalysisContainer(analysisQuery, updateAnalysisQuery); err != nil {
+			logger.Error("ResumeAnalysis", logInfoAnalysis, 2020, err)
+			return
+		}
+	}
+
+	repository := repositoryOf(interruptedAnalysis)
+	queued := interruptedAnalysis.Status == types.QueuedStatus
+	runAnalysis(interruptedAnalysis.RID, repository, securitytest.CompletedContainers(interruptedAnalysis.Containers), nil, queued)
+}
+
+// removeUpload removes the files uploaded for the analysis RID of repository, if it is an uploaded archive.
+func removeUpload(RID string, repository types.Repository) {
+	if repository.Source != upload.Source {
+		return
+	}
+	if err := upload.Remove(apiContext.APIConfiguration.UploadConfig.Dir, RID); err != nil {
+		log.ForAnalysis(RID, repository.URL).Error(logActionStart, logInfoAnalysis, 1066, err)
+	}
+}
+
+// runAnalysis runs the analysis RID of repository once it takes a slot, leaving the queue, given its ticket,
+// at that moment. A queued analysis is set as running then.
+func runAnalysis(RID string, repository types.Repository, completed map[string]types.Container, ticket *queueTicket, queued bool) {
+
+	logger := log.ForAnalysis(RID, repository.URL)
+	defer acquireAnalysisSlot()()
+	ticket.leave()
+	if queued {
+		analysisQuery := map[string]interface{}{"RID": RID}
+		// the analysis still runs if it can not be set as running, as its status is set once it finishes.
+		if err := apiContext.APIConfiguration.DBInstance.UpdateOneDBAnalysisContainer(analysisQuery, bson.M{"status": "running"}); err != nil {
+			logger.Error(logActionStart, logInfoAnalysis, 2011, err)
+		}
+	}
 
 	// step 2: run enry as huskyCI initial step
 	enryScan := securitytest.SecTestScanInfo{}
 	enryScan.SecurityTestName = "enry"
-	allScansResults := securitytest.RunAllInfo{}
+	enryScan.TimeOutInSeconds = repository.TimeOutInSeconds
+	enryScan.SubPath = repository.SubPath
+	enryScan.Commit = repository.Commit
+	enryScan.SSHPrivateKey = repository.SSHPrivateKey
+	enryScan.CloneURL = repository.CloneURL
+	enryScan.CloneStrategy = cloneStrategyOf(repository)
+	enryScan.Source = repository.Source
+	enryScan.ImageReference = repository.ImageReference
+	enryScan.BaseRef = repository.BaseRef
+	enryScan.SecurityTestArgs = repository.SecurityTestArgs
+	enryScan.SecurityTestEnv = repository.SecurityTestEnv
+	allScansResults := securitytest.RunAllInfo{RID: RID, Completed: completed, SecurityTests: repository.SecurityTests, FailSeverity: repository.FailSeverity, FailFastSeverity: repository.FailFastSeverity, MaxParallelSecurityTests: apiContext.APIConfiguration.MaxParallelSecurityTests, CloneStrategy: enryScan.CloneStrategy}
 
 	defer func() {
 		err := registerFinishedAnalysis(RID, &allScansResults)
 		if err != nil {
-			log.Error(logActionStart, logInfoAnalysis, 2011, err)
+			logger.Error(logActionStart, logInfoAnalysis, 2011, err)
+			return
+		}
+		exportFindings(RID, repository, &allScansResults)
+		// uploaded files are kept while shutting down, so that the next API process can resume the analysis.
+		if !ShuttingDown() {
+			removeUpload(RID, repository)
 		}
 	}()
 
 	if err := enryScan.New(RID, repository.URL, repository.Branch, enryScan.SecurityTestName); err != nil {
-		log.Error(logActionStart, logInfoAnalysis, 2011, err)
+		logger.Error(logActionStart, logInfoAnalysis, 2011, err)
 		return
 	}
 	if err := enryScan.Start(); err != nil {
@@ -47,27 +174,93 @@ func StartAnalysis(RID string, repository types.Repository) {
 		return
 	}
 
+	// an empty repository, or one without any supported language, finishes here without running any container.
+	finished, err := allScansResults.FinishWithoutScannableContent(enryScan)
+	if err != nil {
+		allScansResults.SetAnalysisError(err)
+		return
+	}
+	if finished {
+		logger.Info("StartAnalysis", logInfoAnalysis, 47, RID, languagesOf(allScansResults.Codes))
+		return
+	}
+
 	// step 3: run generic and languages security tests based on enryScan result in parallel
 	if err := allScansResults.Start(enryScan); err != nil {
 		allScansResults.SetAnalysisError(err)
 		return
 	}
 
-	log.Info("StartAnalysis", logInfoAnalysis, 102, RID)
+	logger.Info("StartAnalysis", logInfoAnalysis, 102, RID)
 }
 
-func registerNewAnalysis(RID string, repository types.Repository) error {
+// cloneStrategyOf returns how the securityTests of an analysis of repository clone it, as configured unless the
+// analysis needs the full history of the repository. The gitleaks history scan resolves a strategy of its own.
+func cloneStrategyOf(repository types.Repository) types.CloneStrategy {
+	config := apiContext.APIConfiguration
+	return clone.Resolve(config.GitCloneDepth, config.GitSingleBranch, repository.Commit, repository.BaseRef)
+}
+
+// languagesOf returns the languages of codes.
+func languagesOf(codes []types.Code) []string {
+	languages := []string{}
+	for _, code := range codes {
+		languages = append(languages, code.Language)
+	}
+	return languages
+}
+
+// PlanAnalysis runs only the language detection of an analysis of repository and returns the
+// securityTests that the analysis would run, without running any of them nor registering the analysis.
+func PlanAnalysis(RID string, repository types.Repository) (types.AnalysisPlan, error) {
+	enryScan := securitytest.SecTestScanInfo{}
+	enryScan.TimeOutInSeconds = repository.TimeOutInSeconds
+	enryScan.SubPath = repository.SubPath
+	enryScan.Commit = repository.Commit
+	enryScan.SSHPrivateKey = repository.SSHPrivateKey
+	enryScan.CloneURL = repository.CloneURL
+	enryScan.CloneStrategy = cloneStrategyOf(repository)
+	if err := enryScan.New(RID, repository.URL, repository.Branch, "enry"); err != nil {
+		return types.AnalysisPlan{}, err
+	}
+	if err := enryScan.Start(); err != nil {
+		return types.AnalysisPlan{}, err
+	}
+	return securitytest.Plan(enryScan.Codes, repository.TimeOutInSeconds, repository.SecurityTests)
+}
+
+func registerNewAnalysis(RID string, repository types.Repository, originRID string, queued bool) error {
 
 	newAnalysis := types.Analysis{
-		RID:       RID,
-		URL:       repository.URL,
-		Branch:    repository.Branch,
-		Status:    "running",
-		StartedAt: time.Now(),
+		RID:               RID,
+		URL:               repository.URL,
+		Branch:            repository.Branch,
+		SubPath:           util.CleanSubPath(repository.SubPath),
+		Commit:            repository.Commit,
+		Status:            "running",
+		StartedAt:         time.Now(),
+		TimeOutInSeconds:  repository.TimeOutInSeconds,
+		FailFastSeverity:  repository.FailFastSeverity,
+		OriginAnalysisID:  originRID,
+		ImageReference:    repository.ImageReference,
+		BaseRef:           repository.BaseRef,
+		SecurityTestArgs:  repository.SecurityTestArgs,
+		SecurityTestEnv:   containerenv.Mask(repository.SecurityTestEnv),
+		Tags:              repository.Tags,
+		Source:            repository.Source,
+		Profile:           repository.Profile,
+		SecurityTests:     repository.SecurityTests,
+		BranchPolicy:      repository.BranchPolicy,
+		FailSeverity:      repository.FailSeverity,
+		SkipNotifications: repository.SkipNotifications,
+	}
+
+	if queued {
+		newAnalysis.Status = types.QueuedStatus
 	}
 
 	if err := apiContext.APIConfiguration.DBInstance.InsertDBAnalysis(newAnalysis); err != nil {
-		log.Error("registerNewAnalysis", logInfoAnalysis, 2011, err)
+		log.ForAnalysis(RID, repository.URL).Error("registerNewAnalysis", logInfoAnalysis, 2011, err)
 		return err
 	}
 
@@ -83,19 +276,26 @@ func registerFinishedAnalysis(RID string, allScanResults *securitytest.RunAllInf
 	} else {
 		errorString = ""
 	}
+	// the summary counts every vulnerability found, including the ones not stored beyond the limit.
+	summary := summarize.Results(allScanResults.HuskyCIResults)
+	summary.Truncated = truncate.Results(&allScanResults.HuskyCIResults, apiContext.APIConfiguration.MaxStoredFindings)
 	updateAnalysisQuery := bson.M{
-		"status":         allScanResults.Status,
-		"commitAuthors":  allScanResults.CommitAuthors,
-		"result":         allScanResults.FinalResult,
-		"containers":     allScanResults.Containers,
-		"huskyciresults": allScanResults.HuskyCIResults,
-		"codes":          allScanResults.Codes,
-		"errorFound":     errorString,
-		"finishedAt":     time.Now(),
+		"status":          allScanResults.Status,
+		"commitAuthors":   allScanResults.CommitAuthors,
+		"result":          allScanResults.FinalResult,
+		"containers":      allScanResults.Containers,
+		"huskyciresults":  allScanResults.HuskyCIResults,
+		"summary":         summary,
+		"codes":           allScanResults.Codes,
+		"errorFound":      errorString,
+		"finishedAt":      time.Now(),
+		"failFastAborted": allScanResults.FailFastAborted,
+		"components":      allScanResults.Components,
+		"cloneStrategy":   allScanResults.CloneStrategy,
 	}
 
 	if err := apiContext.APIConfiguration.DBInstance.UpdateOneDBAnalysisContainer(analysisQuery, updateAnalysisQuery); err != nil {
-		log.Error("registerFinishedAnalysis", logInfoAnalysis, 2011, err)
+		log.ForAnalysis(RID, "").Error("registerFinishedAnalysis", logInfoAnalysis, 2011, err)
 		return err
 	}
 	return nil
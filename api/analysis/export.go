@@ -0,0 +1,48 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/defectdojo"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+)
+
+const logActionExport = "ExportFindings"
+
+// exportFindings uploads the stored findings of a finished analysis of repository to the DefectDojo
+// engagement that config maps the repository to, as the test of the branch of the analysis.
+func exportFindings(RID string, repository types.Repository, allScanResults *securitytest.RunAllInfo) {
+	config := apiContext.APIConfiguration.DefectDojoConfig
+	if config == nil || config.URL == "" || allScanResults.Status != "finished" || repository.SkipNotifications {
+		return
+	}
+	engagementID, ok := config.Engagements[repository.URL]
+	if !ok {
+		return
+	}
+	logger := log.ForAnalysis(RID, repository.URL)
+	report, err := defectdojo.GenericFindings(allScanResults.HuskyCIResults)
+	if err != nil {
+		logger.Error(logActionExport, logInfoAnalysis, 1065, err)
+		return
+	}
+	testID, err := defectdojo.NewClient(config.URL, config.APIKey).Upload(engagementID, exportTestTitle(repository), report)
+	if err != nil {
+		logger.Error(logActionExport, logInfoAnalysis, 1065, err)
+		return
+	}
+	logger.Info(logActionExport, logInfoAnalysis, 38, testID)
+}
+
+// exportTestTitle returns the title of the DefectDojo test holding the findings of the branch of repository.
+func exportTestTitle(repository types.Repository) string {
+	if repository.Branch == "" {
+		return "huskyCI"
+	}
+	return "huskyCI " + repository.Branch
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"sync"
+)
+
+var (
+	// analysisSlots limits how many analyses run at the same time. A nil one sets no limit.
+	analysisSlots      chan struct{}
+	analysisSlotsMutex sync.RWMutex
+)
+
+// SetMaxRunningAnalyses sets how many analyses may run at the same time in this API process, apart from
+// how many securityTests each of them runs. Analyses beyond it wait for a free slot before running any
+// securityTest, the ones submitted through the queue being registered as queued meanwhile. A limit lower
+// than one sets no limit.
+func SetMaxRunningAnalyses(limit int) {
+	analysisSlotsMutex.Lock()
+	defer analysisSlotsMutex.Unlock()
+	if limit < 1 {
+		analysisSlots = nil
+		return
+	}
+	analysisSlots = make(chan struct{}, limit)
+}
+
+// runningAnalysesLimited reports whether SetMaxRunningAnalyses set a limit, so that analyses may be queued.
+func runningAnalysesLimited() bool {
+	analysisSlotsMutex.RLock()
+	defer analysisSlotsMutex.RUnlock()
+	return analysisSlots != nil
+}
+
+// acquireAnalysisSlot takes a slot, waiting until one is free, and returns the func that frees it.
+func acquireAnalysisSlot() func() {
+	analysisSlotsMutex.RLock()
+	slots := analysisSlots
+	analysisSlotsMutex.RUnlock()
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// Unfinished reports whether an analysis of status is still queued or running.
+func Unfinished(status string) bool {
+	return status == "running" || status == types.QueuedStatus
+}
+
+// QueueRetryAfter is how long clients are told to wait before submitting again an analysis rejected by a
+// full queue.
+const QueueRetryAfter = 30 * time.Second
+
+// ErrQueueFull is returned when an analysis is submitted while the queue holds as many analyses as it may.
+var ErrQueueFull = errors.New("analysis queue is full")
+
+// queue holds the analyses submitted to this API process that did not get a slot to run yet.
+var queue = analysisQueue{}
+
+type analysisQueue struct {
+	mutex     sync.Mutex
+	maxQueued int
+	// waiting counts the analyses admitted into the queue that did not take a slot yet.
+	waiting int
+}
+
+// queueTicket is the place of an analysis in the queue, left once it takes a slot or gives up running.
+type queueTicket struct {
+	once sync.Once
+}
+
+func (t *queueTicket) leave() {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		queue.mutex.Lock()
+		defer queue.mutex.Unlock()
+		queue.waiting--
+	})
+}
+
+// SetMaxQueuedAnalyses sets how many analyses may wait for a free slot in this API process. Analyses submitted
+// beyond it, on top of the ones the free slots can take, are rejected with ErrQueueFull. A limit lower than one
+// sets no limit.
+func SetMaxQueuedAnalyses(limit int) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	if limit < 1 {
+		limit = 0
+	}
+	queue.maxQueued = limit
+}
+
+// admit returns the place in the queue of a new analysis, or ErrQueueFull when there is no room left.
+// There is always room when the running analyses are not limited.
+func (q *analysisQueue) admit() (*queueTicket, error) {
+	analysisSlotsMutex.RLock()
+	slots := analysisSlots
+	analysisSlotsMutex.RUnlock()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if slots != nil && q.maxQueued > 0 && q.waiting >= q.maxQueued+cap(slots)-len(slots) {
+		return nil, ErrQueueFull
+	}
+	q.waiting++
+	return &queueTicket{}, nil
+}
+
+// QueueAnalysis admits the analysis RID of repository into the queue and starts it in the background,
+// returning ErrQueueFull when there is no room left for it.
+func QueueAnalysis(RID string, repository types.Repository) error {
+	ticket, err := queue.admit()
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer ticket.leave()
+		startAnalysis(RID, repository, "", ticket)
+	}()
+	return nil
+}
+
+// QueueRerun admits the rerun RID of originAnalysis into the queue and starts it in the background, with the
+// same parameters of originAnalysis, returning ErrQueueFull when there is no room left for it.
+// The deploy key of the repository is never stored, so the rerun uses the global one. Neither is
+// its CloneURL, so a repository whose URL had credentials is cloned again without them, nor the plain
+// values of its securityTestEnv, so only the secrets it references are injected again.
+func QueueRerun(RID string, originAnalysis types.Analysis) error {
+	ticket, err := queue.admit()
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer ticket.leave()
+		startAnalysis(RID, repositoryOf(originAnalysis), originAnalysis.RID, ticket)
+	}()
+	return nil
+}
+
+// GetQueueStats returns how many analyses are queued and running in this API process. Analyses resumed
+// from a previous API process wait for a slot as well, but are not counted as queued.
+func GetQueueStats() types.QueueStats {
+	analysisSlotsMutex.RLock()
+	slots := analysisSlots
+	analysisSlotsMutex.RUnlock()
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	stats := types.QueueStats{MaxQueued: queue.maxQueued}
+	if slots == nil {
+		inFlight.mutex.Lock()
+		defer inFlight.mutex.Unlock()
+		stats.Running = len(inFlight.analyses)
+		return stats
+	}
+	stats.Running, stats.MaxRunning = len(slots), cap(slots)
+	stats.Queued = queue.waiting
+	return stats
+}
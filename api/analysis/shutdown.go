@@ -0,0 +1,117 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/log"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const logActionShutdown = "Shutdown"
+
+// InterruptedStatus is the status of an analysis still running when the API shut down. Interrupted
+// analyses are resumed like running ones by the next API process when resuming analyses is enabled.
+const InterruptedStatus = "interrupted"
+
+// InterruptedError is the error of an analysis still running when the API shut down.
+const InterruptedError = "analysis interrupted by the API shutdown"
+
+// inFlight holds the analyses running in this API process.
+var inFlight = runningAnalyses{analyses: make(map[string]string)}
+
+type runningAnalyses struct {
+	mutex    sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+	// analyses are the URLs of the running analyses by RID.
+	analyses map[string]string
+}
+
+// begin records that the analysis RID is running. It returns false, recording nothing, once the API
+// is shutting down.
+func (r *runningAnalyses) begin(RID, URL string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.draining {
+		return false
+	}
+	r.analyses[RID] = URL
+	r.wg.Add(1)
+	return true
+}
+
+// end records that the analysis RID is no longer running.
+func (r *runningAnalyses) end(RID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.analyses, RID)
+	r.wg.Done()
+}
+
+// ShuttingDown reports whether the API is shutting down, meaning no new analysis can start.
+func ShuttingDown() bool {
+	inFlight.mutex.Lock()
+	defer inFlight.mutex.Unlock()
+	return inFlight.draining
+}
+
+// Drain stops new analyses from starting and waits up to gracePeriod for the running ones to finish and
+// be stored. The analyses still running after it are set as interrupted, so that they are not left
+// running forever, and their RIDs are returned.
+func Drain(gracePeriod time.Duration) []string {
+	inFlight.mutex.Lock()
+	inFlight.draining = true
+	inFlight.mutex.Unlock()
+	log.Info(logActionShutdown, logInfoAnalysis, 27, gracePeriod)
+
+	finished := make(chan struct{})
+	go func() {
+		inFlight.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-time.After(gracePeriod):
+	}
+
+	inFlight.mutex.Lock()
+	interrupted := make(map[string]string, len(inFlight.analyses))
+	for RID, URL := range inFlight.analyses {
+		interrupted[RID] = URL
+	}
+	inFlight.mutex.Unlock()
+
+	RIDs := make([]string, 0, len(interrupted))
+	for RID := range interrupted {
+		RIDs = append(RIDs, RID)
+	}
+	sort.Strings(RIDs)
+	for _, RID := range RIDs {
+		registerInterruptedAnalysis(RID, interrupted[RID])
+	}
+	return RIDs
+}
+
+func registerInterruptedAnalysis(RID, URL string) {
+	logger := log.ForAnalysis(RID, URL)
+	analysisQuery := map[string]interface{}{"RID": RID}
+	updateAnalysisQuery := bson.M{
+		"status":     InterruptedStatus,
+		"result":     "error",
+		"errorFound": InterruptedError,
+		"finishedAt": time.Now(),
+	}
+	if err := apiContext.APIConfiguration.DBInstance.UpdateOneDBAnalysisContainer(analysisQuery, updateAnalysisQuery); err != nil {
+		logger.Error(logActionShutdown, logInfoAnalysis, 2019, err)
+		return
+	}
+	logger.Warning(logActionShutdown, logInfoAnalysis, 125, RID)
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/globocom/huskyCI/api/analysis"
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDB struct {
+	db.Requests
+	mutex    sync.Mutex
+	statuses map[string][]interface{}
+	// holds make the calls to FindOneDBSecurityTest, in order, wait until they are closed.
+	holds   []chan struct{}
+	calls   int
+	started chan struct{}
+}
+
+func (f *fakeDB) InsertDBAnalysis(newAnalysis types.Analysis) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.statuses[newAnalysis.RID] = append(f.statuses[newAnalysis.RID], newAnalysis.Status)
+	return nil
+}
+
+func (f *fakeDB) UpdateOneDBAnalysisContainer(mapParams, updateQuery map[string]interface{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	RID := mapParams["RID"].(string)
+	f.statuses[RID] = append(f.statuses[RID], updateQuery["status"])
+	return nil
+}
+
+func (f *fakeDB) FindOneDBSecurityTest(mapParams map[string]interface{}) (types.SecurityTest, error) {
+	f.mutex.Lock()
+	hold := f.holds[f.calls]
+	f.calls++
+	f.mutex.Unlock()
+	f.started <- struct{}{}
+	<-hold
+	return types.SecurityTest{}, errors.New("securityTest can not run in tests")
+}
+
+func (f *fakeDB) statusesOf(RID string) []interface{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.statuses[RID]
+}
+
+var _ = Describe("Analysis", func() {
+
+	log.InitLog(true, "", "", "log_test", "log_test")
+
+	first := make(chan struct{})
+	second := make(chan struct{})
+	admitted := make(chan struct{})
+	queued := make(chan struct{})
+	quick := make(chan struct{})
+	slow := make(chan struct{})
+	fakeDatabase := &fakeDB{
+		statuses: make(map[string][]interface{}),
+		holds:    []chan struct{}{first, second, admitted, queued, quick, slow},
+		started:  make(chan struct{}),
+	}
+	apiContext.APIConfiguration = &apiContext.APIConfig{DBInstance: fakeDatabase}
+	repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", Branch: "master"}
+
+	// draining can not be undone, so these specs rely on running in the order they are defined.
+	Describe("SetMaxRunningAnalyses", func() {
+		AfterEach(func() {
+			analysis.SetMaxRunningAnalyses(0)
+		})
+
+		It("Should run at most the limit of analyses at the same time, keeping the others running until a slot is free.", func() {
+			analysis.SetMaxRunningAnalyses(1)
+			Expect(analysis.QueueAnalysis("firstRID", repository)).To(Succeed())
+			<-fakeDatabase.started
+			Expect(analysis.QueueAnalysis("secondRID", repository)).To(Succeed())
+			Eventually(func() []interface{} { return fakeDatabase.statusesOf("secondRID") }).Should(Equal([]interface{}{types.QueuedStatus}))
+			Consistently(fakeDatabase.started, 100*time.Millisecond).ShouldNot(Receive())
+
+			close(first)
+			Eventually(fakeDatabase.started).Should(Receive())
+			Expect(fakeDatabase.statusesOf("firstRID")).To(HaveLen(3))
+			close(second)
+			Eventually(func() []interface{} { return fakeDatabase.statusesOf("secondRID") }).Should(HaveLen(3))
+		})
+	})
+
+	Describe("QueueAnalysis", func() {
+		AfterEach(func() {
+			analysis.SetMaxRunningAnalyses(0)
+			analysis.SetMaxQueuedAnalyses(0)
+		})
+
+		It("Should queue the analyses waiting for a slot and reject the ones beyond the queue depth.", func() {
+			analysis.SetMaxRunningAnalyses(1)
+			analysis.SetMaxQueuedAnalyses(1)
+			Expect(analysis.QueueAnalysis("admittedRID", repository)).To(Succeed())
+			<-fakeDatabase.started
+			Expect(fakeDatabase.statusesOf("admittedRID")).To(Equal([]interface{}{types.QueuedStatus, "running"}))
+			Expect(analysis.QueueAnalysis("queuedRID", repository)).To(Succeed())
+			Eventually(func() []interface{} { return fakeDatabase.statusesOf("queuedRID") }).Should(Equal([]interface{}{types.QueuedStatus}))
+			Expect(analysis.GetQueueStats()).To(Equal(types.QueueStats{Queued: 1, Running: 1, MaxQueued: 1, MaxRunning: 1}))
+
+			Expect(analysis.QueueAnalysis("rejectedRID", repository)).To(Equal(analysis.ErrQueueFull))
+			Expect(fakeDatabase.statusesOf("rejectedRID")).To(BeEmpty())
+
+			close(admitted)
+			Eventually(fakeDatabase.started).Should(Receive())
+			Expect(fakeDatabase.statusesOf("queuedRID")).To(Equal([]interface{}{types.QueuedStatus, "running"}))
+			Expect(analysis.GetQueueStats().Queued).To(BeZero())
+			close(queued)
+			Eventually(func() []interface{} { return fakeDatabase.statusesOf("queuedRID") }).Should(HaveLen(3))
+			Eventually(analysis.GetQueueStats).Should(Equal(types.QueueStats{MaxQueued: 1, MaxRunning: 1}))
+		})
+	})
+
+	Describe("Drain", func() {
+		It("Should wait for the running analyses and set the ones not finished in the grace period as interrupted.", func() {
+			Expect(analysis.QueueAnalysis("quickRID", repository)).To(Succeed())
+			<-fakeDatabase.started
+			Expect(analysis.QueueAnalysis("slowRID", repository)).To(Succeed())
+			<-fakeDatabase.started
+			Expect(analysis.ShuttingDown()).To(BeFalse())
+
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				close(quick)
+			}()
+			interrupted := analysis.Drain(500 * time.Millisecond)
+			Expect(analysis.ShuttingDown()).To(BeTrue())
+			Expect(interrupted).To(Equal([]string{"slowRID"}))
+			Expect(fakeDatabase.statusesOf("quickRID")).NotTo(ContainElement(analysis.InterruptedStatus))
+			Expect(fakeDatabase.statusesOf("slowRID")).To(Equal([]interface{}{"running", analysis.InterruptedStatus}))
+			close(slow)
+			Eventually(func() []interface{} { return fakeDatabase.statusesOf("slowRID") }).Should(HaveLen(3))
+		})
+
+		It("Should set the analyses submitted while shutting down as interrupted without running them.", func() {
+			Expect(analysis.Drain(0)).To(BeEmpty())
+			Expect(analysis.QueueAnalysis("lateRID", repository)).To(Succeed())
+			Eventually(func() []interface{} { return fakeDatabase.statusesOf("lateRID") }).Should(Equal([]interface{}{"running", analysis.InterruptedStatus}))
+		})
+	})
+})
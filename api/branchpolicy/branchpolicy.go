@@ -0,0 +1,33 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package branchpolicy
+
+import (
+	"path"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// Resolve returns the first of policies whose pattern matches branch, and whether there is one.
+func Resolve(policies []types.BranchPolicy, branch string) (types.BranchPolicy, bool) {
+	for _, policy := range policies {
+		if matched, err := path.Match(policy.Pattern, branch); err == nil && matched {
+			return policy, true
+		}
+	}
+	return types.BranchPolicy{}, false
+}
+
+// Apply sets on repository the branch policy of policies resolved for its branch, if any.
+func Apply(repository *types.Repository, policies []types.BranchPolicy) {
+	policy, ok := Resolve(policies, repository.Branch)
+	if !ok {
+		return
+	}
+	repository.BranchPolicy = policy.Pattern
+	repository.SecurityTests = policy.SecurityTests
+	repository.FailSeverity = policy.FailSeverity
+	repository.SkipNotifications = !policy.Notify
+}
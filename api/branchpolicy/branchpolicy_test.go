@@ -0,0 +1,75 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package branchpolicy_test
+
+import (
+	"github.com/globocom/huskyCI/api/branchpolicy"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BranchPolicy", func() {
+
+	Describe("Resolve", func() {
+		policies := []types.BranchPolicy{
+			{Pattern: "main", FailSeverity: "medium", Notify: true},
+			{Pattern: "feature/*", SecurityTests: []string{"gosec", "gitleaks"}, FailSeverity: "none"},
+			{Pattern: "*", FailSeverity: "high", Notify: true},
+		}
+
+		Context("When a pattern matches the branch", func() {
+			It("Should return the first policy matching it.", func() {
+				policy, ok := branchpolicy.Resolve(policies, "feature/login")
+				Expect(ok).To(BeTrue())
+				Expect(policy).To(Equal(policies[1]))
+				policy, ok = branchpolicy.Resolve(policies, "main")
+				Expect(ok).To(BeTrue())
+				Expect(policy).To(Equal(policies[0]))
+				policy, ok = branchpolicy.Resolve(policies, "develop")
+				Expect(ok).To(BeTrue())
+				Expect(policy).To(Equal(policies[2]))
+			})
+		})
+		Context("When no pattern matches the branch", func() {
+			It("Should return no policy.", func() {
+				_, ok := branchpolicy.Resolve(policies, "feature/login/oauth")
+				Expect(ok).To(BeFalse())
+				_, ok = branchpolicy.Resolve(nil, "main")
+				Expect(ok).To(BeFalse())
+			})
+		})
+		Context("When a pattern is malformed", func() {
+			It("Should skip it.", func() {
+				policy, ok := branchpolicy.Resolve([]types.BranchPolicy{{Pattern: "["}, policies[2]}, "develop")
+				Expect(ok).To(BeTrue())
+				Expect(policy).To(Equal(policies[2]))
+			})
+		})
+	})
+
+	Describe("Apply", func() {
+		policies := []types.BranchPolicy{{Pattern: "feature/*", SecurityTests: []string{"gitleaks"}, FailSeverity: "none"}}
+
+		Context("When a policy is resolved for the branch", func() {
+			It("Should set its securityTests, threshold and notifications.", func() {
+				repository := types.Repository{Branch: "feature/login"}
+				branchpolicy.Apply(&repository, policies)
+				Expect(repository.BranchPolicy).To(Equal("feature/*"))
+				Expect(repository.SecurityTests).To(Equal([]string{"gitleaks"}))
+				Expect(repository.FailSeverity).To(Equal("none"))
+				Expect(repository.SkipNotifications).To(BeTrue())
+			})
+		})
+		Context("When no policy is resolved for the branch", func() {
+			It("Should keep the repository as requested.", func() {
+				repository := types.Repository{Branch: "main"}
+				branchpolicy.Apply(&repository, policies)
+				Expect(repository).To(Equal(types.Repository{Branch: "main"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,32 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// AnalysisQuery returns the query of the finished analyses whose results can be returned instead of
+// analyzing repository again, or nil if repository has no commit, as the tip of a branch changes over time,
+// runs only some of the default securityTests or has securityTestArgs or a securityTestEnv, which change what
+// the securityTests check. They must have analyzed the same commit with the same parameters and branch policy,
+// so that the same securityTests ran and failed with the same severity.
+func AnalysisQuery(repository types.Repository) map[string]interface{} {
+	if repository.Commit == "" || len(repository.SecurityTests) > 0 || len(repository.SecurityTestArgs) > 0 || len(repository.SecurityTestEnv) > 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"repositoryURL":     repository.URL,
+		"repositoryCommit":  repository.Commit,
+		"repositorySubPath": util.CleanSubPath(repository.SubPath),
+		"imageReference":    repository.ImageReference,
+		"repositoryBaseRef": repository.BaseRef,
+		"failFastSeverity":  repository.FailFastSeverity,
+		"branchPolicy":      repository.BranchPolicy,
+		"failSeverity":      repository.FailSeverity,
+		"status":            "finished",
+	}
+}
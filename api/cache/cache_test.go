@@ -0,0 +1,59 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache_test
+
+import (
+	"github.com/globocom/huskyCI/api/cache"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+
+	Describe("AnalysisQuery", func() {
+		Context("When the repository has no commit", func() {
+			It("Should return no query.", func() {
+				repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", Branch: "master"}
+				Expect(cache.AnalysisQuery(repository)).To(BeNil())
+			})
+		})
+		Context("When the repository has securityTestArgs", func() {
+			It("Should return no query.", func() {
+				repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", Commit: "4f53cda", SecurityTestArgs: map[string][]string{"gosec": {"-exclude=G104"}}}
+				Expect(cache.AnalysisQuery(repository)).To(BeNil())
+			})
+		})
+		Context("When the repository has a scan profile restricting its securityTests", func() {
+			It("Should return no query.", func() {
+				repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", Commit: "4f53cda", Profile: "quick", SecurityTests: []string{"gosec"}}
+				Expect(cache.AnalysisQuery(repository)).To(BeNil())
+			})
+		})
+		Context("When the repository has a commit", func() {
+			It("Should match finished analyses of the same commit and parameters, regardless of the branch.", func() {
+				repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", Branch: "master", SubPath: "./api/", Commit: "4f53cda", FailFastSeverity: "high", BaseRef: "main"}
+				Expect(cache.AnalysisQuery(repository)).To(Equal(map[string]interface{}{
+					"repositoryURL":     "https://github.com/globocom/huskyCI.git",
+					"repositoryCommit":  "4f53cda",
+					"repositorySubPath": "api",
+					"imageReference":    "",
+					"repositoryBaseRef": "main",
+					"failFastSeverity":  "high",
+					"branchPolicy":      "",
+					"failSeverity":      "",
+					"status":            "finished",
+				}))
+			})
+			It("Should only match finished analyses run under the same branch policy.", func() {
+				repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", Branch: "release/1.0", Commit: "4f53cda", BranchPolicy: "release/*", FailSeverity: "low"}
+				query := cache.AnalysisQuery(repository)
+				Expect(query).To(HaveKeyWithValue("branchPolicy", "release/*"))
+				Expect(query).To(HaveKeyWithValue("failSeverity", "low"))
+			})
+		})
+	})
+})
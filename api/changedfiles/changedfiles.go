@@ -0,0 +1,97 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package changedfiles
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// Marker and EndMarker delimit, in the output of the container that replaced
+// %GIT_CHANGED_FILES%, the files changed since the base ref, one per line.
+const (
+	Marker    = "HUSKYCI_CHANGED_FILES"
+	EndMarker = "HUSKYCI_CHANGED_FILES_END"
+)
+
+// Diff modes supported by HandleCmd.
+const (
+	// DiffMergeBase compares the cloned code to the merge base of baseRef, as a three-dot diff does, so that
+	// the changes merged into baseRef after the cloned code forked from it, or merged from it later, are not
+	// attributed to the cloned code.
+	DiffMergeBase = "merge-base"
+	// DiffDirect compares the cloned code to baseRef itself, as a two-dot diff does, so that the changes
+	// made to baseRef but not yet merged into the cloned code are reported as changed files too.
+	DiffDirect = "direct"
+)
+
+// HandleCmd will extract %GIT_CHANGED_FILES% from cmd and replace it with the commands that print,
+// between Marker and EndMarker, the files changed in the cloned code since baseRef,
+// compared as diffMode tells. Paths are relative to the current directory, so it must run inside the cloned
+// code, or to subPath, as the code restricted to it by util.HandleSubPath keeps the .git directory of the whole
+// repository. An empty baseRef prints nothing, as the whole repository is analyzed.
+func HandleCmd(cmd, baseRef, diffMode, subPath string) string {
+	changedFilesCmd := ""
+	if baseRef != "" {
+		revisions := "FETCH_HEAD...HEAD"
+		if diffMode == DiffDirect {
+			revisions = "FETCH_HEAD..HEAD"
+		}
+		relative := "--relative"
+		if cleanSubPath := util.CleanSubPath(subPath); cleanSubPath != "" {
+			relative = fmt.Sprintf("'--relative=%s/'", cleanSubPath)
+		}
+		changedFilesCmd = fmt.Sprintf(`{ git fetch --quiet origin %s 2> /dev/null && echo "%s" && git diff --name-only %s %s && echo "%s" || echo "ERROR_BASE_REF_NOT_FOUND"; }`, baseRef, Marker, relative, revisions, EndMarker)
+	}
+	return strings.Replace(cmd, "%GIT_CHANGED_FILES%", changedFilesCmd, -1)
+}
+
+// Split returns output without the changed files printed by the command of HandleCmd,
+// together with these files. found is false if output has no changed files, meaning there was no base ref.
+func Split(output string) (rest string, changedFiles []string, found bool) {
+	start := strings.Index(output, Marker+"\n")
+	if start == -1 {
+		return output, nil, false
+	}
+	end := strings.Index(output[start:], EndMarker)
+	if end == -1 {
+		return output, nil, false
+	}
+	end += start
+	changedFiles = []string{}
+	for _, file := range strings.Split(output[start+len(Marker):end], "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			changedFiles = append(changedFiles, file)
+		}
+	}
+	return output[:start] + output[end+len(EndMarker):], changedFiles, true
+}
+
+// Filter removes from output the vulnerabilities found in files other than changedFiles, which
+// are relative to the analyzed code. Vulnerabilities without a file, such as most vulnerable dependencies,
+// can not be told apart and are kept.
+func Filter(output *types.HuskyCISecurityTestOutput, changedFiles []string) {
+	changed := make(map[string]bool, len(changedFiles))
+	for _, file := range changedFiles {
+		changed[util.CodeRelativePath(file)] = true
+	}
+	filter := func(vulns []types.HuskyCIVulnerability) []types.HuskyCIVulnerability {
+		var kept []types.HuskyCIVulnerability
+		for _, vuln := range vulns {
+			if vuln.File == "" || changed[util.CodeRelativePath(vuln.File)] {
+				kept = append(kept, vuln)
+			}
+		}
+		return kept
+	}
+	output.NoSecVulns = filter(output.NoSecVulns)
+	output.LowVulns = filter(output.LowVulns)
+	output.MediumVulns = filter(output.MediumVulns)
+	output.HighVulns = filter(output.HighVulns)
+	output.AcceptedVulns = filter(output.AcceptedVulns)
+}
@@ -0,0 +1,17 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package changedfiles_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestChangedFiles(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ChangedFiles Suite")
+}
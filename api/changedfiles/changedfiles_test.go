@@ -0,0 +1,145 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package changedfiles_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/globocom/huskyCI/api/changedfiles"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChangedFiles", func() {
+
+	Describe("HandleCmd", func() {
+		inputCMD := "cd code\n%GIT_CHANGED_FILES%\nenry --json"
+
+		Context("When baseRef is empty", func() {
+			It("Should not print any changed file.", func() {
+				Expect(changedfiles.HandleCmd(inputCMD, "", changedfiles.DiffMergeBase, "")).To(Equal("cd code\n\nenry --json"))
+			})
+		})
+		Context("When baseRef is set", func() {
+			It("Should print the files changed since baseRef between the markers.", func() {
+				expected := "cd code\n" +
+					`{ git fetch --quiet origin main 2> /dev/null && echo "HUSKYCI_CHANGED_FILES" && git diff --name-only --relative FETCH_HEAD...HEAD && echo "HUSKYCI_CHANGED_FILES_END" || echo "ERROR_BASE_REF_NOT_FOUND"; }` +
+					"\nenry --json"
+				Expect(changedfiles.HandleCmd(inputCMD, "main", changedfiles.DiffMergeBase, "")).To(Equal(expected))
+			})
+		})
+		Context("When the diff mode is direct", func() {
+			It("Should compare to baseRef itself.", func() {
+				Expect(changedfiles.HandleCmd(inputCMD, "main", changedfiles.DiffDirect, "")).To(ContainSubstring(" git diff --name-only --relative FETCH_HEAD..HEAD "))
+			})
+		})
+		Context("When the analyzed branch merged baseRef before baseRef changed again", func() {
+			// origin has a feature branch forked from main, which got unrelated.go, that merged main and got
+			// feature.go. main got later.go afterwards, so only feature.go is a change of the feature branch.
+			var dir, code string
+			BeforeEach(func() {
+				if _, err := exec.LookPath("git"); err != nil {
+					Skip("git is not installed")
+				}
+				var err error
+				dir, err = ioutil.TempDir("", "huskyci-diff")
+				Expect(err).To(BeNil())
+				run := func(cwd, script string) {
+					cmd := exec.Command("bash", "-c", script)
+					cmd.Dir = cwd
+					cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=huskyCI", "GIT_AUTHOR_EMAIL=huskyci@example.com", "GIT_COMMITTER_NAME=huskyCI", "GIT_COMMITTER_EMAIL=huskyci@example.com", "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+					output, err := cmd.CombinedOutput()
+					Expect(err).To(BeNil(), string(output))
+				}
+				run(dir, `set -e
+					git init --quiet origin && cd origin && git checkout --quiet -b main
+					echo base > base.go && git add . && git commit --quiet -m base
+					git checkout --quiet -b feature
+					git checkout --quiet main && echo unrelated > unrelated.go && git add . && git commit --quiet -m unrelated
+					git checkout --quiet feature && git merge --quiet --no-edit main
+					echo feature > feature.go && git add . && git commit --quiet -m feature
+					git checkout --quiet main && echo later > later.go && git add . && git commit --quiet -m later
+					cd .. && git clone --quiet -b feature --single-branch origin code`)
+				code = dir + "/code"
+			})
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+			changedFiles := func(diffMode string) []string {
+				cmd := exec.Command("bash", "-c", changedfiles.HandleCmd("%GIT_CHANGED_FILES%", "main", diffMode, ""))
+				cmd.Dir = code
+				output, err := cmd.CombinedOutput()
+				Expect(err).To(BeNil(), string(output))
+				_, files, found := changedfiles.Split(string(output))
+				Expect(found).To(BeTrue(), string(output))
+				return files
+			}
+
+			It("Should only attribute the changes of the branch to it comparing to the merge base.", func() {
+				Expect(changedFiles(changedfiles.DiffMergeBase)).To(Equal([]string{"feature.go"}))
+			})
+
+			It("Should also report the later changes of baseRef comparing to it directly.", func() {
+				Expect(changedFiles(changedfiles.DiffDirect)).To(ConsistOf("feature.go", "later.go"))
+			})
+		})
+	})
+
+	Describe("Split", func() {
+		Context("When the output has the changed files", func() {
+			It("Should return them and the output without them.", func() {
+				rest, changedFiles, found := changedfiles.Split("HUSKYCI_CHANGED_FILES\nmain.go\r\napi/server.go\nHUSKYCI_CHANGED_FILES_END\n{\"Go\":[\"main.go\"]}")
+				Expect(found).To(BeTrue())
+				Expect(changedFiles).To(Equal([]string{"main.go", "api/server.go"}))
+				Expect(rest).To(Equal("\n{\"Go\":[\"main.go\"]}"))
+			})
+		})
+		Context("When no file changed since the base ref", func() {
+			It("Should return an empty list of changed files.", func() {
+				rest, changedFiles, found := changedfiles.Split("HUSKYCI_CHANGED_FILES\nHUSKYCI_CHANGED_FILES_END\n{}")
+				Expect(found).To(BeTrue())
+				Expect(changedFiles).To(BeEmpty())
+				Expect(rest).To(Equal("\n{}"))
+			})
+		})
+		Context("When the output has no changed files", func() {
+			It("Should return the same output.", func() {
+				rest, changedFiles, found := changedfiles.Split(`{"Go":["main.go"]}`)
+				Expect(found).To(BeFalse())
+				Expect(changedFiles).To(BeNil())
+				Expect(rest).To(Equal(`{"Go":["main.go"]}`))
+			})
+		})
+	})
+
+	Describe("Filter", func() {
+		It("Should keep only the vulnerabilities found in the changed files or without a file.", func() {
+			output := types.HuskyCISecurityTestOutput{
+				HighVulns: []types.HuskyCIVulnerability{
+					{Title: "changed absolute", File: "/go/src/code/api/server.go"},
+					{Title: "unchanged", File: "api/routes.go"},
+				},
+				MediumVulns: []types.HuskyCIVulnerability{
+					{Title: "changed relative", File: "./main.go"},
+				},
+				LowVulns: []types.HuskyCIVulnerability{
+					{Title: "dependency without a file"},
+				},
+				NoSecVulns: []types.HuskyCIVulnerability{
+					{Title: "unchanged nosec", File: "code/util.go"},
+				},
+			}
+			changedfiles.Filter(&output, []string{"main.go", "api/server.go"})
+			Expect(output.HighVulns).To(Equal([]types.HuskyCIVulnerability{{Title: "changed absolute", File: "/go/src/code/api/server.go"}}))
+			Expect(output.MediumVulns).To(Equal([]types.HuskyCIVulnerability{{Title: "changed relative", File: "./main.go"}}))
+			Expect(output.LowVulns).To(Equal([]types.HuskyCIVulnerability{{Title: "dependency without a file"}}))
+			Expect(output.NoSecVulns).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,66 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clone
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// Features of an analysis that need the full history of its repository, cloned whatever the configured depth.
+const (
+	FullHistoryGitleaks     = "gitleaks history scan"
+	FullHistoryCommit       = "commit checkout"
+	FullHistoryChangedFiles = "changed files since the base ref"
+)
+
+// Resolve returns how the repository of an analysis is cloned by its securityTests: the last depth commits of its
+// branch, 0 meaning the full history, and only that branch if singleBranch is set. The full history is cloned instead
+// when the analysis needs it: to check out commit, or to find the files changed since baseRef.
+func Resolve(depth int, singleBranch bool, commit, baseRef string) types.CloneStrategy {
+	strategy := types.CloneStrategy{Depth: depth, SingleBranch: singleBranch}
+	if depth == 0 {
+		return strategy
+	}
+	switch {
+	case commit != "":
+		strategy.FullHistoryReason = FullHistoryCommit
+	case baseRef != "":
+		strategy.FullHistoryReason = FullHistoryChangedFiles
+	default:
+		return strategy
+	}
+	strategy.Depth = 0
+	return strategy
+}
+
+// ForSecurityTest returns how the securityTest tool clones the repository of an analysis cloned as strategy:
+// gitleaks clones its full history for the history scan, if historyScan is set, and any other tool as strategy.
+func ForSecurityTest(strategy types.CloneStrategy, tool string, historyScan bool) types.CloneStrategy {
+	if tool != "gitleaks" || !historyScan || strategy.Depth == 0 {
+		return strategy
+	}
+	strategy.Depth = 0
+	strategy.FullHistoryReason = FullHistoryGitleaks
+	return strategy
+}
+
+// HandleOptions will extract %GIT_CLONE_OPTIONS% from cmd and replace it with the git clone flags that clone
+// the branch of the repository as strategy tells. git clones a single branch of a shallow clone unless told not to.
+// It has to run before util.HandleCmd, as the replacement refers to %GIT_BRANCH%.
+func HandleOptions(cmd string, strategy types.CloneStrategy) string {
+	options := "-b %GIT_BRANCH%"
+	if strategy.SingleBranch {
+		options += " --single-branch"
+	} else if strategy.Depth > 0 {
+		options += " --no-single-branch"
+	}
+	if strategy.Depth > 0 {
+		options += fmt.Sprintf(" --depth=%d", strategy.Depth)
+	}
+	return strings.Replace(cmd, "%GIT_CLONE_OPTIONS%", options, -1)
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clone_test
+
+import (
+	"os"
+
+	"github.com/globocom/huskyCI/api/clone"
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clone", func() {
+
+	Describe("Resolve", func() {
+		Context("When the analysis needs no history", func() {
+			It("Should clone as configured.", func() {
+				Expect(clone.Resolve(1, true, "", "")).To(Equal(types.CloneStrategy{Depth: 1, SingleBranch: true}))
+				Expect(clone.Resolve(50, false, "", "")).To(Equal(types.CloneStrategy{Depth: 50}))
+			})
+		})
+		Context("When the analysis needs the full history", func() {
+			It("Should clone the full history, telling which feature needed it.", func() {
+				Expect(clone.Resolve(1, true, "4f53cda", "")).To(Equal(types.CloneStrategy{SingleBranch: true, FullHistoryReason: clone.FullHistoryCommit}))
+				Expect(clone.Resolve(1, false, "", "main")).To(Equal(types.CloneStrategy{FullHistoryReason: clone.FullHistoryChangedFiles}))
+			})
+		})
+		Context("When the full history is configured", func() {
+			It("Should not tell any feature needed it.", func() {
+				Expect(clone.Resolve(0, true, "4f53cda", "main")).To(Equal(types.CloneStrategy{SingleBranch: true}))
+			})
+		})
+	})
+
+	Describe("ForSecurityTest", func() {
+		shallow := types.CloneStrategy{Depth: 1, SingleBranch: true}
+
+		Context("When the gitleaks history scan is set", func() {
+			It("Should clone the full history for gitleaks only.", func() {
+				Expect(clone.ForSecurityTest(shallow, "gitleaks", true)).To(Equal(types.CloneStrategy{SingleBranch: true, FullHistoryReason: clone.FullHistoryGitleaks}))
+				Expect(clone.ForSecurityTest(shallow, "gosec", true)).To(Equal(shallow))
+			})
+		})
+		Context("When the gitleaks history scan is not set", func() {
+			It("Should clone as the analysis for gitleaks as well.", func() {
+				Expect(clone.ForSecurityTest(shallow, "gitleaks", false)).To(Equal(shallow))
+			})
+		})
+		Context("When the analysis clones the full history already", func() {
+			It("Should keep the feature that needed it.", func() {
+				strategy := types.CloneStrategy{SingleBranch: true, FullHistoryReason: clone.FullHistoryCommit}
+				Expect(clone.ForSecurityTest(strategy, "gitleaks", true)).To(Equal(strategy))
+			})
+		})
+		Context("With the default configuration", func() {
+			It("Should clone the last commit of the branch for gosec and its full history for gitleaks.", func() {
+				for _, env := range []string{"HUSKYCI_API_GIT_CLONE_DEPTH", "HUSKYCI_API_GIT_SINGLE_BRANCH", "HUSKYCI_API_GITLEAKS_HISTORY_SCAN"} {
+					os.Unsetenv(env)
+				}
+				config := apiContext.DefaultConfig{Caller: &apiContext.ExternalCalls{}}
+				strategy := clone.Resolve(config.GetGitCloneDepth(), config.GetGitSingleBranch(), "", "")
+				inputCMD := "git clone %GIT_CLONE_OPTIONS% %GIT_REPO% code --quiet"
+				gosec := clone.HandleOptions(inputCMD, clone.ForSecurityTest(strategy, "gosec", config.GetGitleaksHistoryScan()))
+				Expect(gosec).To(Equal("git clone -b %GIT_BRANCH% --single-branch --depth=1 %GIT_REPO% code --quiet"))
+				gitleaks := clone.HandleOptions(inputCMD, clone.ForSecurityTest(strategy, "gitleaks", config.GetGitleaksHistoryScan()))
+				Expect(gitleaks).To(Equal("git clone -b %GIT_BRANCH% --single-branch %GIT_REPO% code --quiet"))
+			})
+		})
+	})
+
+	Describe("HandleOptions", func() {
+		inputCMD := "git clone %GIT_CLONE_OPTIONS% %GIT_REPO% code --quiet"
+
+		Context("When a shallow clone of a single branch is set", func() {
+			It("Should clone the last commits of the branch only.", func() {
+				cmd := clone.HandleOptions(inputCMD, types.CloneStrategy{Depth: 1, SingleBranch: true})
+				Expect(util.HandleCmd("https://github.com/globocom/secDevLabs.git", "myBranch", cmd)).To(Equal("git clone -b myBranch --single-branch --depth=1 https://github.com/globocom/secDevLabs.git code --quiet"))
+			})
+		})
+		Context("When a shallow clone of every branch is set", func() {
+			It("Should tell git not to clone a single branch.", func() {
+				Expect(clone.HandleOptions(inputCMD, types.CloneStrategy{Depth: 10})).To(Equal("git clone -b %GIT_BRANCH% --no-single-branch --depth=10 %GIT_REPO% code --quiet"))
+			})
+		})
+		Context("When the full history is set", func() {
+			It("Should clone without a depth.", func() {
+				Expect(clone.HandleOptions(inputCMD, types.CloneStrategy{SingleBranch: true})).To(Equal("git clone -b %GIT_BRANCH% --single-branch %GIT_REPO% code --quiet"))
+				Expect(clone.HandleOptions(inputCMD, types.CloneStrategy{})).To(Equal("git clone -b %GIT_BRANCH% %GIT_REPO% code --quiet"))
+			})
+		})
+	})
+})
@@ -0,0 +1,179 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package containerenv
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/secrets"
+	"github.com/globocom/huskyCI/api/util"
+	"github.com/labstack/echo"
+)
+
+const logInfoAnalysis = "ANALYSIS"
+const logActionReceiveRequest = "ReceiveRequest"
+
+// Forwarded returns the "NAME=value" environment of a securityTest container. Only environment variables
+// present in globalAllowlist or in the comma separated toolAllowlist and set according to lookupEnv are forwarded.
+func Forwarded(globalAllowlist []string, toolAllowlist string, lookupEnv func(string) (string, bool)) []string {
+	allowlist := append([]string{}, globalAllowlist...)
+	allowlist = append(allowlist, strings.Split(toolAllowlist, ",")...)
+
+	env := []string{}
+	forwarded := []string{}
+	for _, envName := range allowlist {
+		envName = strings.TrimSpace(envName)
+		if envName == "" || util.SliceContains(forwarded, envName) {
+			continue
+		}
+		if value, ok := lookupEnv(envName); ok {
+			env = append(env, fmt.Sprintf("%s=%s", envName, value))
+			forwarded = append(forwarded, envName)
+		}
+	}
+	return env
+}
+
+// Reserved holds the environment variables a repository can not set in a securityTest container,
+// as the command of the securityTest, the shell running it or the tools it runs depend on them, and so do
+// the names starting with one of ReservedPrefixes. Names are compared regardless of their case.
+var Reserved = []string{
+	"PATH", "HOME", "USER", "SHELL", "PWD", "HOSTNAME", "TMPDIR",
+	"IFS", "ENV", "BASH_ENV", "CDPATH", "PS4", "PROMPT_COMMAND", "SHELLOPTS", "BASHOPTS", "GLOBIGNORE",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+	"NODE_OPTIONS", "PYTHONPATH", "PYTHONSTARTUP", "PERL5OPT", "RUBYOPT", "JAVA_TOOL_OPTIONS", "_JAVA_OPTIONS",
+}
+
+// ReservedPrefixes holds the prefixes of the environment variables reserved as Reserved.
+var ReservedPrefixes = []string{"LD_", "DYLD_", "GIT_", "SSH_", "HUSKYCI_", "BASH_FUNC_"}
+
+var regexpEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate verifies that each one of the environment variables of env requested for tool
+// has a valid name that is not reserved, and that its value either references a secret by a valid name
+// or is a plain value.
+func Validate(tool string, env map[string]string) error {
+	for name, value := range env {
+		if !regexpEnvName.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q for securityTest %s", name, tool)
+		}
+		if reserved(name) {
+			return fmt.Errorf("environment variable %s is reserved and can not be set for securityTest %s", name, tool)
+		}
+		if strings.HasPrefix(value, secrets.ReferencePrefix) {
+			if !secrets.ValidName(strings.TrimPrefix(value, secrets.ReferencePrefix)) {
+				return fmt.Errorf("invalid secret reference %q in environment variable %s for securityTest %s", value, name, tool)
+			}
+		} else if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("invalid value of environment variable %s for securityTest %s", name, tool)
+		}
+	}
+	return nil
+}
+
+func reserved(name string) bool {
+	name = strings.ToUpper(name)
+	if util.SliceContains(Reserved, name) {
+		return true
+	}
+	for _, prefix := range ReservedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check verifies, using Validate, the environment requested for each tool.
+// The values are never logged, as they may hold credentials.
+func Check(securityTestEnv map[string]map[string]string, c echo.Context) error {
+	for tool, env := range securityTestEnv {
+		if err := Validate(tool, env); err != nil {
+			log.Error(logActionReceiveRequest, logInfoAnalysis, 1073, tool)
+			reply := map[string]interface{}{"success": false, "error": "invalid securityTestEnv"}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+	}
+	return nil
+}
+
+// Mask returns a copy of securityTestEnv with its plain values replaced by secrets.MaskedValue, as they
+// may hold credentials. The references to the secret store are kept, as they hold only the name of a secret.
+func Mask(securityTestEnv map[string]map[string]string) map[string]map[string]string {
+	if securityTestEnv == nil {
+		return nil
+	}
+	masked := make(map[string]map[string]string, len(securityTestEnv))
+	for tool, env := range securityTestEnv {
+		masked[tool] = make(map[string]string, len(env))
+		for name, value := range env {
+			if !strings.HasPrefix(value, secrets.ReferencePrefix) {
+				value = secrets.MaskedValue
+			}
+			masked[tool][name] = value
+		}
+	}
+	return masked
+}
+
+// Unmasked returns a copy of a securityTestEnv masked by Mask without the values
+// it masked, as they are lost, so that only the references to the secret store are injected again.
+func Unmasked(securityTestEnv map[string]map[string]string) map[string]map[string]string {
+	var unmasked map[string]map[string]string
+	for tool, env := range securityTestEnv {
+		for name, value := range env {
+			if value == secrets.MaskedValue {
+				continue
+			}
+			if unmasked == nil {
+				unmasked = make(map[string]map[string]string)
+			}
+			if unmasked[tool] == nil {
+				unmasked[tool] = make(map[string]string)
+			}
+			unmasked[tool][name] = value
+		}
+	}
+	return unmasked
+}
+
+// Inject returns the "NAME=value" environment env of a securityTest container with the variables
+// of requested added, sorted by name, and the values of the secrets they reference, read using lookupSecret.
+// A variable already forwarded in env keeps its value, as it is set by the API operator.
+func Inject(env []string, requested map[string]string, lookupSecret func(string) (string, error)) ([]string, []string, error) {
+	forwarded := make(map[string]bool)
+	for _, variable := range env {
+		forwarded[strings.SplitN(variable, "=", 2)[0]] = true
+	}
+	names := make([]string, 0, len(requested))
+	for name := range requested {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	injected := append([]string{}, env...)
+	injectedSecrets := []string{}
+	for _, name := range names {
+		if forwarded[name] {
+			continue
+		}
+		value := requested[name]
+		if strings.HasPrefix(value, secrets.ReferencePrefix) {
+			secret, err := lookupSecret(strings.TrimPrefix(value, secrets.ReferencePrefix))
+			if err != nil {
+				return nil, nil, err
+			}
+			value = secret
+			injectedSecrets = append(injectedSecrets, secret)
+		}
+		injected = append(injected, fmt.Sprintf("%s=%s", name, value))
+	}
+	return injected, injectedSecrets, nil
+}
@@ -0,0 +1,17 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package containerenv_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestContainerEnv(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ContainerEnv Suite")
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package containerenv_test
+
+import (
+	"errors"
+
+	"github.com/globocom/huskyCI/api/containerenv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainerEnv", func() {
+
+	Describe("Forwarded", func() {
+
+		hostEnv := map[string]string{
+			"HTTP_PROXY":               "http://proxy:3128",
+			"NPM_TOKEN":                "npm-secret",
+			"HUSKYCI_DATABASE_DB_PASS": "db-secret",
+		}
+		lookupEnv := func(envName string) (string, bool) {
+			value, ok := hostEnv[envName]
+			return value, ok
+		}
+
+		Context("When no allowlist is set", func() {
+			It("Should not forward any env var.", func() {
+				Expect(containerenv.Forwarded([]string{}, "", lookupEnv)).To(BeEmpty())
+			})
+		})
+		Context("When only the global allowlist is set", func() {
+			It("Should forward only the allowed env vars.", func() {
+				Expect(containerenv.Forwarded([]string{"HTTP_PROXY"}, "", lookupEnv)).To(Equal([]string{"HTTP_PROXY=http://proxy:3128"}))
+			})
+		})
+		Context("When a tool allowlist is set", func() {
+			It("Should forward the global and the tool env vars.", func() {
+				expected := []string{"HTTP_PROXY=http://proxy:3128", "NPM_TOKEN=npm-secret"}
+				Expect(containerenv.Forwarded([]string{"HTTP_PROXY"}, "NPM_TOKEN, HTTP_PROXY", lookupEnv)).To(Equal(expected))
+			})
+		})
+		Context("When an allowed env var is not set", func() {
+			It("Should not forward it.", func() {
+				Expect(containerenv.Forwarded([]string{"NO_PROXY"}, "GOPROXY", lookupEnv)).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Validate", func() {
+		Context("When every variable has a plain value or references a secret", func() {
+			It("Should return a nil error.", func() {
+				env := map[string]string{"ADVISORY_DB_TOKEN": "secret:advisory-db-token", "SCANNER_MODE": "strict"}
+				Expect(containerenv.Validate("safety", env)).To(BeNil())
+			})
+		})
+		Context("When a variable is reserved", func() {
+			It("Should return an error.", func() {
+				for _, name := range []string{"PATH", "ld_preload", "GIT_SSH_COMMAND", "BASH_ENV", "HUSKYCI_API_DB_PASSWORD", "https_proxy"} {
+					Expect(containerenv.Validate("safety", map[string]string{name: "value"})).To(HaveOccurred())
+				}
+			})
+		})
+		Context("When a variable name is invalid", func() {
+			It("Should return an error.", func() {
+				for _, name := range []string{"", "1TOKEN", "TOKEN=1", "TOKEN;id"} {
+					Expect(containerenv.Validate("safety", map[string]string{name: "value"})).To(HaveOccurred())
+				}
+			})
+		})
+		Context("When a secret reference is invalid", func() {
+			It("Should return an error.", func() {
+				for _, value := range []string{"secret:", "secret:../etc/passwd", "secret:.hidden", "secret:a/b"} {
+					Expect(containerenv.Validate("safety", map[string]string{"TOKEN": value})).To(HaveOccurred())
+				}
+			})
+		})
+	})
+
+	Describe("Inject", func() {
+		lookupSecret := func(name string) (string, error) {
+			if name == "advisory-db-token" {
+				return "t0k3n", nil
+			}
+			return "", errors.New("secret not found")
+		}
+		Context("When variables are requested", func() {
+			It("Should add them sorted by name and return the secrets they reference.", func() {
+				env, secrets, err := containerenv.Inject([]string{"HTTP_PROXY=http://proxy:3128"}, map[string]string{"SCANNER_MODE": "strict", "ADVISORY_DB_TOKEN": "secret:advisory-db-token"}, lookupSecret)
+				Expect(err).To(BeNil())
+				Expect(env).To(Equal([]string{"HTTP_PROXY=http://proxy:3128", "ADVISORY_DB_TOKEN=t0k3n", "SCANNER_MODE=strict"}))
+				Expect(secrets).To(Equal([]string{"t0k3n"}))
+			})
+		})
+		Context("When a variable is already forwarded by the API", func() {
+			It("Should keep the forwarded value.", func() {
+				env, _, err := containerenv.Inject([]string{"SCANNER_MODE=lenient"}, map[string]string{"SCANNER_MODE": "strict"}, lookupSecret)
+				Expect(err).To(BeNil())
+				Expect(env).To(Equal([]string{"SCANNER_MODE=lenient"}))
+			})
+		})
+		Context("When a referenced secret can not be read", func() {
+			It("Should return an error.", func() {
+				_, _, err := containerenv.Inject(nil, map[string]string{"TOKEN": "secret:missing"}, lookupSecret)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Mask", func() {
+		It("Should mask the plain values and keep the secret references, without changing the given env.", func() {
+			env := map[string]map[string]string{"safety": {"ADVISORY_DB_TOKEN": "secret:advisory-db-token", "ADVISORY_DB_PASSWORD": "s3cr3t"}}
+			Expect(containerenv.Mask(env)).To(Equal(map[string]map[string]string{"safety": {"ADVISORY_DB_TOKEN": "secret:advisory-db-token", "ADVISORY_DB_PASSWORD": "[REDACTED]"}}))
+			Expect(env["safety"]["ADVISORY_DB_PASSWORD"]).To(Equal("s3cr3t"))
+			Expect(containerenv.Mask(nil)).To(BeNil())
+		})
+	})
+
+	Describe("Unmasked", func() {
+		It("Should keep only the secret references.", func() {
+			env := map[string]map[string]string{"safety": {"ADVISORY_DB_TOKEN": "secret:advisory-db-token", "ADVISORY_DB_PASSWORD": "[REDACTED]"}, "gosec": {"GOFLAGS": "[REDACTED]"}}
+			Expect(containerenv.Unmasked(env)).To(Equal(map[string]map[string]string{"safety": {"ADVISORY_DB_TOKEN": "secret:advisory-db-token"}}))
+			Expect(containerenv.Unmasked(nil)).To(BeNil())
+		})
+	})
+})
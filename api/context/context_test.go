@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"errors"
+	"runtime"
 	"time"
 
 	. "github.com/globocom/huskyCI/api/context"
@@ -77,51 +78,1348 @@ var _ = Describe("Context", func() {
 			})
 		})
 	})
+	Describe("GetGRPCPort", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return 0, not starting the gRPC API", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGRPCPort()).To(BeZero())
+			})
+		})
+		Context("When ConvertStrToInt returns a negative port", func() {
+			It("Should return 0, not starting the gRPC API", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue: -1,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGRPCPort()).To(BeZero())
+			})
+		})
+		Context("When ConvertStrToInt returns a valid port", func() {
+			It("Should return the expected port", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         9090,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGRPCPort()).To(Equal(9090))
+			})
+		})
+	})
+	Describe("GetPublicPort", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return 0, not serving the public routes on their own port", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetPublicPort()).To(BeZero())
+			})
+		})
+		Context("When ConvertStrToInt returns a valid port", func() {
+			It("Should return the expected port", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue: 8443,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetPublicPort()).To(Equal(8443))
+			})
+		})
+	})
 	Describe("GetAPIUseTLS", func() {
 		Context("When GetEnvironmentVariable returns a valid option", func() {
 			It("Should return a true boolean", func() {
 				fakeCaller := FakeCaller{
-					expectedEnvVar: "True",
+					expectedEnvVar: "True",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAPIUseTLS()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a not valid option", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "Invalid",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAPIUseTLS()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetDefaultConfidence", func() {
+		Context("When GetEnvironmentVariable returns a valid confidence", func() {
+			It("Should return it in upper case", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "High",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDefaultConfidence()).To(Equal("HIGH"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an invalid confidence", func() {
+			It("Should return MEDIUM", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "Invalid",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDefaultConfidence()).To(Equal("MEDIUM"))
+			})
+		})
+	})
+	Describe("GetNoTestsPolicy", func() {
+		Context("When GetEnvironmentVariable returns fail", func() {
+			It("Should return fail", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "FAIL",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetNoTestsPolicy()).To(Equal("fail"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an invalid policy", func() {
+			It("Should return pass", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "Invalid",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetNoTestsPolicy()).To(Equal("pass"))
+			})
+		})
+	})
+	Describe("GetBranchPolicies", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no branch policies", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetBranchPolicies()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return the valid policies in order", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "main=failSeverity:Medium, feature/*=securityTests:gosec|gitleaks  failSeverity:none notify:false,hotfix/*=failSeverity:critical,=failSeverity:low,release/*=color:blue,*=",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetBranchPolicies()).To(Equal([]types.BranchPolicy{
+					{Pattern: "main", FailSeverity: "medium", Notify: true},
+					{Pattern: "feature/*", SecurityTests: []string{"gosec", "gitleaks"}, FailSeverity: "none", Notify: false},
+					{Pattern: "*", Notify: true},
+				}))
+			})
+		})
+	})
+	Describe("GetDBSkipIndexes", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return false", func() {
+				config := DefaultConfig{
+					Caller: &FakeCaller{expectedEnvVar: ""},
+				}
+				Expect(config.GetDBSkipIndexes()).To(BeFalse())
+			})
+		})
+		Context("When GetEnvironmentVariable returns true", func() {
+			It("Should return true", func() {
+				config := DefaultConfig{
+					Caller: &FakeCaller{expectedEnvVar: "TRUE"},
+				}
+				Expect(config.GetDBSkipIndexes()).To(BeTrue())
+			})
+		})
+	})
+	Describe("GetContainerEnvAllowlist", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return an empty allowlist", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetContainerEnvAllowlist()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each env var name", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "HTTP_PROXY, NO_PROXY,,",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetContainerEnvAllowlist()).To(Equal([]string{"HTTP_PROXY", "NO_PROXY"}))
+			})
+		})
+	})
+	Describe("GetTrivyScanTargets", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should scan the filesystem only", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetTrivyScanTargets()).To(Equal([]string{"fs"}))
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return the known targets only", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "Image, repo,fs",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetTrivyScanTargets()).To(Equal([]string{"image", "fs"}))
+			})
+		})
+	})
+	Describe("GetTrustedGitHosts", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return the public git hosts", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetTrustedGitHosts()).To(Equal([]string{"github.com", "gitlab.com", "bitbucket.org"}))
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each domain in lower case", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "GitLab.example.com, github.com,,",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetTrustedGitHosts()).To(Equal([]string{"gitlab.example.com", "github.com"}))
+			})
+		})
+	})
+	Describe("GetTokenRepositoryAllowlist", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return an empty allowlist", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetTokenRepositoryAllowlist()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each pattern in lower case", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "GitLab.example.com, github.com/GloboCom,,",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetTokenRepositoryAllowlist()).To(Equal([]string{"gitlab.example.com", "github.com/globocom"}))
+			})
+		})
+	})
+	Describe("GetImageAllowlist", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return an empty allowlist", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetImageAllowlist()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each image", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "huskyci/gosec, huskyci/bandit:1.6.2,, huskyci/npmaudit@sha256:0a1b",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetImageAllowlist()).To(Equal([]string{"huskyci/gosec", "huskyci/bandit:1.6.2", "huskyci/npmaudit@sha256:0a1b"}))
+			})
+		})
+	})
+	Describe("GetScanProfiles", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no scan profiles", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetScanProfiles()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return the securityTests of each named profile", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "quick=gosec  bandit gitleaks, full=,=gosec,nightly",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetScanProfiles()).To(Equal(map[string][]string{
+					"quick": {"gosec", "bandit", "gitleaks"},
+					"full":  {},
+				}))
+			})
+		})
+	})
+	Describe("GetAdminUsers", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no admin users", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAdminUsers()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each username", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "huskyCIUser, secOps",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAdminUsers()).To(Equal([]string{"huskyCIUser", "secOps"}))
+			})
+		})
+	})
+	Describe("GetSecurityTestInstances", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no securityTest instances", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetSecurityTestInstances()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each securityTest instance name", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "banditstrict, bandittests",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetSecurityTestInstances()).To(Equal([]string{"banditstrict", "bandittests"}))
+			})
+		})
+	})
+	Describe("GetGitSSHKnownHosts", func() {
+		Context("When GetEnvironmentVariable returns known_hosts lines", func() {
+			It("Should return them", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitSSHKnownHosts()).To(Equal("github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5"))
+			})
+		})
+	})
+	Describe("GetGitCACertificates", func() {
+		Context("When GetEnvironmentVariable returns PEM certificates", func() {
+			It("Should return them", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "-----BEGIN CERTIFICATE-----",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitCACertificates()).To(Equal("-----BEGIN CERTIFICATE-----"))
+			})
+		})
+	})
+	Describe("GetGitCACertificatesPath", func() {
+		Context("When GetEnvironmentVariable returns a path", func() {
+			It("Should return it", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "/etc/huskyci/internal-ca.pem",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitCACertificatesPath()).To(Equal("/etc/huskyci/internal-ca.pem"))
+			})
+		})
+	})
+	Describe("GetGitSSHStrictHostKeyChecking", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitSSHStrictHostKeyChecking()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns false", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "False",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitSSHStrictHostKeyChecking()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetGitCloneDepth", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return a depth of 1", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitCloneDepth()).To(Equal(1))
+			})
+		})
+		Context("When ConvertStrToInt returns 0", func() {
+			It("Should return 0, cloning the full history", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitCloneDepth()).To(Equal(0))
+			})
+		})
+		Context("When ConvertStrToInt returns a negative number", func() {
+			It("Should return a depth of 1", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         -5,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitCloneDepth()).To(Equal(1))
+			})
+		})
+	})
+	Describe("GetGitSingleBranch", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitSingleBranch()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns false", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "false",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitSingleBranch()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetRedactURLCredentials", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetRedactURLCredentials()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns false", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "false",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetRedactURLCredentials()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetWebhookSecret", func() {
+		It("Should return the webhook secret", func() {
+			fakeCaller := FakeCaller{
+				expectedEnvVar: "s3cr3t",
+			}
+			config := DefaultConfig{
+				Caller: &fakeCaller,
+			}
+			Expect(config.GetWebhookSecret()).To(Equal("s3cr3t"))
+		})
+	})
+	Describe("GetAnalysisHTTPStatuses", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no HTTP status", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAnalysisHTTPStatuses()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns HTTP statuses by result", func() {
+			It("Should return the valid ones", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "passed=200, Failed = 422,error=500,warning=ok,running=99,=202",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAnalysisHTTPStatuses()).To(Equal(map[string]int{"passed": 200, "failed": 422, "error": 500}))
+			})
+		})
+	})
+	Describe("GetSensitivePaths", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no path patterns", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetSensitivePaths()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a comma separated list", func() {
+			It("Should return each path pattern", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "auth/, crypto/",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetSensitivePaths()).To(Equal([]string{"auth/", "crypto/"}))
+			})
+		})
+	})
+	Describe("GetFailSeverity", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return medium", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFailSeverity()).To(Equal("medium"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns a known severity", func() {
+			It("Should return it lowercased", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "HIGH",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFailSeverity()).To(Equal("high"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an unknown severity", func() {
+			It("Should return medium", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "critical",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFailSeverity()).To(Equal("medium"))
+			})
+		})
+	})
+	Describe("GetDefectDojoEngagements", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no engagement", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDefectDojoEngagements()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns engagements by repository", func() {
+			It("Should return the valid ones", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "https://github.com/org/repo.git=12, git@github.com:org/other.git = 7,https://github.com/org/bad.git=abc,=3,https://github.com/org/zero.git=0",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDefectDojoEngagements()).To(Equal(map[string]int{
+					"https://github.com/org/repo.git": 12,
+					"git@github.com:org/other.git":    7,
+				}))
+			})
+		})
+	})
+	Describe("GetDiffMode", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return merge-base", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDiffMode()).To(Equal("merge-base"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns direct", func() {
+			It("Should return direct", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "Direct",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDiffMode()).To(Equal("direct"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an unknown mode", func() {
+			It("Should return merge-base", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "two-dot",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetDiffMode()).To(Equal("merge-base"))
+			})
+		})
+	})
+	Describe("GetSeverityOverrides", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no override", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetSeverityOverrides()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns severities by rule", func() {
+			It("Should return the valid ones", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "gosec:G104=low, Bandit:B101 = HIGH,gitleaks:AWS=critical,G402=low,gosec:=low",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetSeverityOverrides()).To(Equal(map[string]string{"gosec:G104": "low", "bandit:B101": "high"}))
+			})
+		})
+	})
+	Describe("GetFailOnVerifiedSecrets", func() {
+		Context("When GetEnvironmentVariable returns a valid option", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "true",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFailOnVerifiedSecrets()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns an empty option", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFailOnVerifiedSecrets()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetOutputCompression", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return no codec", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOutputCompression()).To(BeEmpty())
+			})
+		})
+		Context("When GetEnvironmentVariable returns a known codec", func() {
+			It("Should return it lowercased", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "GZIP",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOutputCompression()).To(Equal("gzip"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an unknown codec", func() {
+			It("Should return no codec", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "lz4",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOutputCompression()).To(BeEmpty())
+			})
+		})
+	})
+	Describe("GetFingerprintAlgorithm", func() {
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return the line algorithm", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFingerprintAlgorithm()).To(Equal("line"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns a valid algorithm", func() {
+			It("Should return it lowercased", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "CodeHash",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFingerprintAlgorithm()).To(Equal("codehash"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an unknown algorithm", func() {
+			It("Should return the line algorithm", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "md5",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetFingerprintAlgorithm()).To(Equal("line"))
+			})
+		})
+	})
+	Describe("GetGitleaksHistoryScan", func() {
+		Context("When GetEnvironmentVariable returns an empty option", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitleaksHistoryScan()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns false", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "false",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitleaksHistoryScan()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetGitleaksHistoryShards", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return a single shard", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitleaksHistoryShards()).To(Equal(1))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid number of shards", func() {
+			It("Should return the expected number of shards", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         4,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGitleaksHistoryShards()).To(Equal(fakeCaller.expectedIntegerValue))
+			})
+		})
+	})
+	Describe("GetResumeAnalyses", func() {
+		Context("When GetEnvironmentVariable returns a valid option", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "1",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetResumeAnalyses()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns an invalid option", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "yes",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetResumeAnalyses()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetAnalysisCache", func() {
+		Context("When GetEnvironmentVariable returns a valid option", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "true",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAnalysisCache()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns an invalid option", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAnalysisCache()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetAnalysisCacheMaxAge", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return one hour", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAnalysisCacheMaxAge()).To(Equal(time.Hour))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid number of minutes", func() {
+			It("Should return the expected max age", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         15,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetAnalysisCacheMaxAge()).To(Equal(15 * time.Minute))
+			})
+		})
+	})
+	Describe("GetOfflineMode", func() {
+		Context("When GetEnvironmentVariable returns a valid option", func() {
+			It("Should return a true boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "true",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOfflineMode()).To(BeTrue())
+			})
+		})
+		Context("When GetEnvironmentVariable returns an invalid option", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "offline",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOfflineMode()).To(BeFalse())
+			})
+		})
+	})
+	Describe("GetOfflineMirrors", func() {
+		Context("When GetEnvironmentVariable returns mirrors by securityTest", func() {
+			It("Should return them", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "npmaudit=https://npm.example.com, safety = http://safety-db.example.com/db",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOfflineMirrors()).To(Equal(map[string]string{
+					"npmaudit": "https://npm.example.com",
+					"safety":   "http://safety-db.example.com/db",
+				}))
+			})
+		})
+		Context("When GetEnvironmentVariable returns invalid mirrors", func() {
+			It("Should ignore them", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "npmaudit,yarnaudit=npm.example.com,=https://npm.example.com,safety=ftp://db.example.com,bandit=https://x.example.com/'a",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetOfflineMirrors()).To(BeEmpty())
+			})
+		})
+	})
+	Describe("GetContainerSecretScopes", func() {
+		Context("When GetEnvironmentVariable returns repository patterns by secret", func() {
+			It("Should return every pattern of each secret", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "sonar-token=https://github.com/globocom/*, sonar-token = https://gitlab.example.com/team/app.git,npm-token=https://github.com/globocom/huskyCI.git",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetContainerSecretScopes()).To(Equal(map[string][]string{
+					"sonar-token": {"https://github.com/globocom/*", "https://gitlab.example.com/team/app.git"},
+					"npm-token":   {"https://github.com/globocom/huskyCI.git"},
+				}))
+			})
+		})
+		Context("When GetEnvironmentVariable returns invalid scopes", func() {
+			It("Should ignore them", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "sonar-token,=https://github.com/globocom/*,npm-token=",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetContainerSecretScopes()).To(BeEmpty())
+			})
+		})
+	})
+	Describe("GetLogFormat", func() {
+		Context("When GetEnvironmentVariable returns a valid format", func() {
+			It("Should return it", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "Console",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetLogFormat()).To(Equal("console"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an invalid format and a Graylog address is set", func() {
+			It("Should return graylog", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "xml",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetLogFormat()).To(Equal("graylog"))
+			})
+		})
+		Context("When GetEnvironmentVariable returns an empty string", func() {
+			It("Should return json", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetLogFormat()).To(Equal("json"))
+			})
+		})
+	})
+	Describe("GetGrayLogIsDev", func() {
+		Context("When GetEnvironmentVariable returns valid option", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "False",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGraylogIsDev()).To(BeFalse())
+			})
+		})
+		Context("When GetEnvironmentVariable returns invalid option", func() {
+			It("Should return a false boolean", func() {
+				fakeCaller := FakeCaller{
+					expectedEnvVar: "",
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetGraylogIsDev()).To(BeTrue())
+			})
+		})
+	})
+	Describe("GetMaxTimeOutInSeconds", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return 3600 seconds", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxTimeOutInSeconds()).To(Equal(3600))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid timeout", func() {
+			It("Should return the expected timeout", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         7200,
+					expectedConvertStrToIntError: nil,
 				}
 				config := DefaultConfig{
 					Caller: &fakeCaller,
 				}
-				Expect(config.GetAPIUseTLS()).To(BeTrue())
+				Expect(config.GetMaxTimeOutInSeconds()).To(Equal(fakeCaller.expectedIntegerValue))
 			})
 		})
-		Context("When GetEnvironmentVariable returns a not valid option", func() {
-			It("Should return a true boolean", func() {
+	})
+	Describe("GetMaxRunningContainers", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return twice the number of CPUs", func() {
 				fakeCaller := FakeCaller{
-					expectedEnvVar: "Invalid",
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
 				}
 				config := DefaultConfig{
 					Caller: &fakeCaller,
 				}
-				Expect(config.GetAPIUseTLS()).To(BeFalse())
+				Expect(config.GetMaxRunningContainers()).To(Equal(2 * runtime.NumCPU()))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid limit", func() {
+			It("Should return the expected limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         5,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxRunningContainers()).To(Equal(fakeCaller.expectedIntegerValue))
 			})
 		})
 	})
-	Describe("GetGrayLogIsDev", func() {
-		Context("When GetEnvironmentVariable returns valid option", func() {
-			It("Should return a false boolean", func() {
+	Describe("GetMaxRunningAnalyses", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should set no limit", func() {
 				fakeCaller := FakeCaller{
-					expectedEnvVar: "False",
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
 				}
 				config := DefaultConfig{
 					Caller: &fakeCaller,
 				}
-				Expect(config.GetGraylogIsDev()).To(BeFalse())
+				Expect(config.GetMaxRunningAnalyses()).To(Equal(0))
 			})
 		})
-		Context("When GetEnvironmentVariable returns invalid option", func() {
-			It("Should return a false boolean", func() {
+		Context("When ConvertStrToInt returns a valid limit", func() {
+			It("Should return the expected limit", func() {
 				fakeCaller := FakeCaller{
-					expectedEnvVar: "",
+					expectedIntegerValue:         5,
+					expectedConvertStrToIntError: nil,
 				}
 				config := DefaultConfig{
 					Caller: &fakeCaller,
 				}
-				Expect(config.GetGraylogIsDev()).To(BeTrue())
+				Expect(config.GetMaxRunningAnalyses()).To(Equal(fakeCaller.expectedIntegerValue))
+			})
+		})
+	})
+	Describe("GetMaxQueuedAnalyses", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should set no limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxQueuedAnalyses()).To(Equal(0))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid limit", func() {
+			It("Should return the expected limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         5,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxQueuedAnalyses()).To(Equal(fakeCaller.expectedIntegerValue))
+			})
+		})
+	})
+	Describe("GetMaxStoredFindings", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should set no limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxStoredFindings()).To(Equal(0))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid limit", func() {
+			It("Should return the expected limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         10000,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxStoredFindings()).To(Equal(fakeCaller.expectedIntegerValue))
+			})
+		})
+	})
+	Describe("GetCodeSnippetLines", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should store no snippet", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetCodeSnippetLines()).To(Equal(0))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid number of lines", func() {
+			It("Should return the expected number of lines", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         3,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetCodeSnippetLines()).To(Equal(fakeCaller.expectedIntegerValue))
+			})
+		})
+	})
+	Describe("GetMaxUploadSize", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return 100 MB", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxUploadSize()).To(Equal(int64(100 << 20)))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid size", func() {
+			It("Should return it in bytes", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         20,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxUploadSize()).To(Equal(int64(20 << 20)))
+			})
+		})
+	})
+	Describe("GetMaxUploadExtractedSize", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return 1000 MB", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxUploadExtractedSize()).To(Equal(int64(1000 << 20)))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid size", func() {
+			It("Should return it in bytes", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         200,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxUploadExtractedSize()).To(Equal(int64(200 << 20)))
+			})
+		})
+	})
+	Describe("GetMaxParallelSecurityTests", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should set no limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxParallelSecurityTests()).To(Equal(0))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid limit", func() {
+			It("Should return the expected limit", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         5,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetMaxParallelSecurityTests()).To(Equal(fakeCaller.expectedIntegerValue))
+			})
+		})
+	})
+	Describe("GetImageUpdateCheckInterval", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should disable the check", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetImageUpdateCheckInterval()).To(BeZero())
+			})
+		})
+		Context("When ConvertStrToInt returns a valid number of hours", func() {
+			It("Should return the expected interval", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         24,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetImageUpdateCheckInterval()).To(Equal(24 * time.Hour))
+			})
+		})
+	})
+	Describe("GetShutdownGracePeriod", func() {
+		Context("When ConvertStrToInt returns an error", func() {
+			It("Should return the default of 30 seconds", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         0,
+					expectedConvertStrToIntError: errors.New("Failed converting string to integer"),
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetShutdownGracePeriod()).To(Equal(30 * time.Second))
+			})
+		})
+		Context("When ConvertStrToInt returns a valid number of seconds", func() {
+			It("Should return the expected grace period", func() {
+				fakeCaller := FakeCaller{
+					expectedIntegerValue:         120,
+					expectedConvertStrToIntError: nil,
+				}
+				config := DefaultConfig{
+					Caller: &fakeCaller,
+				}
+				Expect(config.GetShutdownGracePeriod()).To(Equal(2 * time.Minute))
 			})
 		})
 	})
@@ -295,12 +1593,60 @@ var _ = Describe("Context", func() {
 				}
 				apiConfig, err := config.GetAPIConfig()
 				expectedConfig := &APIConfig{
-					Port:             fakeCaller.expectedIntegerValue,
-					Version:          "0.14.0",
-					ReleaseDate:      "2020-06-24",
-					AllowOriginValue: fakeCaller.expectedEnvVar,
-					UseTLS:           true,
-					GitPrivateSSHKey: fakeCaller.expectedEnvVar,
+					Port:                        fakeCaller.expectedIntegerValue,
+					GRPCPort:                    fakeCaller.expectedIntegerValue,
+					PublicPort:                  fakeCaller.expectedIntegerValue,
+					Version:                     "0.14.0",
+					ReleaseDate:                 "2020-06-24",
+					AllowOriginValue:            fakeCaller.expectedEnvVar,
+					UseTLS:                      true,
+					ClientCAPath:                fakeCaller.expectedEnvVar,
+					GitPrivateSSHKey:            fakeCaller.expectedEnvVar,
+					GitSSHKnownHosts:            fakeCaller.expectedEnvVar,
+					GitSSHStrictHostKeyChecking: true,
+					GitCACertificates:           fakeCaller.expectedEnvVar,
+					GitCACertificatesPath:       fakeCaller.expectedEnvVar,
+					GitCloneDepth:               fakeCaller.expectedIntegerValue,
+					GitSingleBranch:             true,
+					RedactURLCredentials:        true,
+					WebhookSecret:               fakeCaller.expectedEnvVar,
+					MaxTimeOutInSeconds:         fakeCaller.expectedIntegerValue,
+					DefaultConfidence:           "MEDIUM",
+					NoTestsPolicy:               "pass",
+					BranchPolicies:              []types.BranchPolicy{},
+					FailSeverity:                "medium",
+					DiffMode:                    "merge-base",
+					SeverityOverrides:           map[string]string{},
+					ContainerEnvAllowlist:       []string{"1"},
+					ContainerSecretsDir:         fakeCaller.expectedEnvVar,
+					ContainerSecretScopes:       map[string][]string{},
+					GitleaksHistoryScan:         true,
+					GitleaksHistoryShards:       fakeCaller.expectedIntegerValue,
+					ResumeAnalyses:              true,
+					AnalysisCache:               true,
+					AnalysisCacheMaxAge:         time.Minute * time.Duration(fakeCaller.expectedIntegerValue),
+					OfflineMode:                 true,
+					OfflineMirrors:              map[string]string{},
+					SensitivePaths:              []string{"1"},
+					FailOnVerifiedSecrets:       true,
+					OutputCompression:           "",
+					FingerprintAlgorithm:        "line",
+					TrustedGitHosts:             []string{"1"},
+					TokenRepositoryAllowlist:    []string{"1"},
+					AdminUsers:                  []string{"1"},
+					TrivyScanTargets:            []string{"fs"},
+					AnalysisHTTPStatuses:        map[string]int{},
+					ImageAllowlist:              []string{"1"},
+					ScanProfiles:                map[string][]string{},
+					MaxRunningContainers:        fakeCaller.expectedIntegerValue,
+					MaxRunningAnalyses:          fakeCaller.expectedIntegerValue,
+					MaxQueuedAnalyses:           fakeCaller.expectedIntegerValue,
+					MaxStoredFindings:           fakeCaller.expectedIntegerValue,
+					CodeSnippetLines:            fakeCaller.expectedIntegerValue,
+					MaxParallelSecurityTests:    fakeCaller.expectedIntegerValue,
+					ImageUpdateCheckInterval:    time.Hour * time.Duration(fakeCaller.expectedIntegerValue),
+					ShutdownGracePeriod:         time.Second * time.Duration(fakeCaller.expectedIntegerValue),
+					LogFormat:                   "graylog",
 					GraylogConfig: &GraylogConfig{
 						Address:        fakeCaller.expectedEnvVar,
 						Protocol:       fakeCaller.expectedEnvVar,
@@ -308,6 +1654,16 @@ var _ = Describe("Context", func() {
 						Tag:            fakeCaller.expectedEnvVar,
 						DevelopmentEnv: true,
 					},
+					DefectDojoConfig: &DefectDojoConfig{
+						URL:         fakeCaller.expectedEnvVar,
+						APIKey:      fakeCaller.expectedEnvVar,
+						Engagements: map[string]int{},
+					},
+					UploadConfig: &UploadConfig{
+						Dir:              fakeCaller.expectedEnvVar,
+						MaxSize:          int64(fakeCaller.expectedIntegerValue) << 20,
+						MaxExtractedSize: int64(fakeCaller.expectedIntegerValue) << 20,
+					},
 					DBConfig: &DBConfig{
 						Address:         fakeCaller.expectedEnvVar,
 						DatabaseName:    fakeCaller.expectedEnvVar,
@@ -336,6 +1692,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					GitAuthorsSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -346,6 +1705,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					GosecSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -356,6 +1718,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					BanditSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -366,6 +1731,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					BrakemanSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -376,6 +1744,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					NpmAuditSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -386,6 +1757,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					YarnAuditSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -396,6 +1770,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					SafetySecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -406,6 +1783,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					GitleaksSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -416,6 +1796,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					SpotBugsSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -426,6 +1809,9 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
 					},
 					TFSecSecurityTest: &types.SecurityTest{
 						Name:             fakeCaller.expectedStringFromConfig,
@@ -436,8 +1822,117 @@ var _ = Describe("Context", func() {
 						Language:         fakeCaller.expectedStringFromConfig,
 						Default:          fakeCaller.expectedBoolFromConfig,
 						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					HadolintSecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					DependencyCheckSecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					TrivySecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					DetektSecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					DependencyCheckGradleSecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					OSVScannerSecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					GovulncheckSecurityTest: &types.SecurityTest{
+						Name:             fakeCaller.expectedStringFromConfig,
+						Image:            fakeCaller.expectedStringFromConfig,
+						ImageTag:         fakeCaller.expectedStringFromConfig,
+						Cmd:              fakeCaller.expectedStringFromConfig,
+						Type:             fakeCaller.expectedStringFromConfig,
+						Language:         fakeCaller.expectedStringFromConfig,
+						Default:          fakeCaller.expectedBoolFromConfig,
+						TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+						EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+						Tool:             fakeCaller.expectedStringFromConfig,
+						GraceDays:        fakeCaller.expectedIntFromConfig,
+					},
+					SecurityTestInstances: []*types.SecurityTest{
+						{
+							Name:             fakeCaller.expectedStringFromConfig,
+							Image:            fakeCaller.expectedStringFromConfig,
+							ImageTag:         fakeCaller.expectedStringFromConfig,
+							Cmd:              fakeCaller.expectedStringFromConfig,
+							Type:             fakeCaller.expectedStringFromConfig,
+							Language:         fakeCaller.expectedStringFromConfig,
+							Default:          fakeCaller.expectedBoolFromConfig,
+							TimeOutInSeconds: fakeCaller.expectedIntFromConfig,
+							EnvAllowlist:     fakeCaller.expectedStringFromConfig,
+							Tool:             fakeCaller.expectedStringFromConfig,
+							GraceDays:        fakeCaller.expectedIntFromConfig,
+						},
 					},
-					DBInstance: &db.MongoRequests{},
+					DBInstance: &db.MongoRequests{SkipIndexes: true},
 				}
 				Expect(apiConfig).To(Equal(expectedConfig))
 				Expect(err).To(BeNil())
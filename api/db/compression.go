@@ -0,0 +1,198 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// CodecGzip compresses the raw output of containers with gzip. The compressed output is stored
+// encoded in base64, so that it remains a string in every database.
+const CodecGzip = "gzip"
+
+// ErrUnknownCodec is returned for a container output stored with a codec huskyCI does not know.
+var ErrUnknownCodec = errors.New("unknown container output codec")
+
+// CompressOutput returns output compressed with codec.
+func CompressOutput(codec, output string) (string, error) {
+	if codec != CodecGzip {
+		return "", ErrUnknownCodec
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write([]byte(output)); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+// DecompressOutput returns output decompressed with codec. An output without codec, such as the
+// ones of analyses stored before compression was configured, is returned as is.
+func DecompressOutput(codec, output string) (string, error) {
+	switch codec {
+	case "":
+		return output, nil
+	case CodecGzip:
+		compressed, err := base64.StdEncoding.DecodeString(output)
+		if err != nil {
+			return "", err
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		decompressed, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+		return string(decompressed), nil
+	}
+	return "", ErrUnknownCodec
+}
+
+// CompressContainers returns a copy of containers whose outputs are compressed with codec, each
+// marked with the codec it was compressed with.
+func CompressContainers(codec string, containers []types.Container) ([]types.Container, error) {
+	compressed := make([]types.Container, len(containers))
+	for i, container := range containers {
+		if container.COutputCodec == "" && container.COutput != "" {
+			output, err := CompressOutput(codec, container.COutput)
+			if err != nil {
+				return nil, err
+			}
+			container.COutput = output
+			container.COutputCodec = codec
+		}
+		compressed[i] = container
+	}
+	return compressed, nil
+}
+
+// DecompressContainers decompresses the output of every container of containers compressed by
+// CompressContainers.
+func DecompressContainers(containers []types.Container) error {
+	for i := range containers {
+		output, err := DecompressOutput(containers[i].COutputCodec, containers[i].COutput)
+		if err != nil {
+			return err
+		}
+		containers[i].COutput = output
+		containers[i].COutputCodec = ""
+	}
+	return nil
+}
+
+// CompressedRequests implements Requests on top of another implementation, compressing the output of
+// the containers of the analyses stored and decompressing them when read.
+type CompressedRequests struct {
+	Requests
+	Codec string
+}
+
+// Migrate migrates the wrapped database when it needs to.
+func (cR *CompressedRequests) Migrate() error {
+	if migrator, ok := cR.Requests.(Migrator); ok {
+		return migrator.Migrate()
+	}
+	return nil
+}
+
+// Available tells whether the wrapped database is reachable, when it keeps track of it.
+func (cR *CompressedRequests) Available() bool {
+	if availability, ok := cR.Requests.(Availability); ok {
+		return availability.Available()
+	}
+	return true
+}
+
+// FindOneDBAnalysis returns the analysis found with its container outputs decompressed.
+func (cR *CompressedRequests) FindOneDBAnalysis(mapParams map[string]interface{}) (types.Analysis, error) {
+	analysis, err := cR.Requests.FindOneDBAnalysis(mapParams)
+	if err != nil {
+		return analysis, err
+	}
+	return analysis, DecompressContainers(analysis.Containers)
+}
+
+// FindAllDBAnalysis returns the analyses found with their container outputs decompressed.
+func (cR *CompressedRequests) FindAllDBAnalysis(mapParams map[string]interface{}) ([]types.Analysis, error) {
+	analyses, err := cR.Requests.FindAllDBAnalysis(mapParams)
+	if err != nil {
+		return analyses, err
+	}
+	for i := range analyses {
+		if err := DecompressContainers(analyses[i].Containers); err != nil {
+			return nil, err
+		}
+	}
+	return analyses, nil
+}
+
+// FindLatestDBAnalysis returns the latest analysis found with its container outputs decompressed.
+func (cR *CompressedRequests) FindLatestDBAnalysis(mapParams map[string]interface{}, finishedAfter time.Time) (types.Analysis, error) {
+	analysis, err := cR.Requests.FindLatestDBAnalysis(mapParams, finishedAfter)
+	if err != nil {
+		return analysis, err
+	}
+	return analysis, DecompressContainers(analysis.Containers)
+}
+
+// InsertDBAnalysis inserts analysis with its container outputs compressed.
+func (cR *CompressedRequests) InsertDBAnalysis(analysis types.Analysis) error {
+	containers, err := CompressContainers(cR.Codec, analysis.Containers)
+	if err != nil {
+		return err
+	}
+	analysis.Containers = containers
+	return cR.Requests.InsertDBAnalysis(analysis)
+}
+
+// UpdateOneDBAnalysis updates an analysis with the outputs of the containers of updatedAnalysis compressed.
+func (cR *CompressedRequests) UpdateOneDBAnalysis(mapParams map[string]interface{}, updatedAnalysis map[string]interface{}) error {
+	compressedAnalysis, err := cR.compressUpdate(updatedAnalysis)
+	if err != nil {
+		return err
+	}
+	return cR.Requests.UpdateOneDBAnalysis(mapParams, compressedAnalysis)
+}
+
+// UpdateOneDBAnalysisContainer updates an analysis with the outputs of the containers of updateQuery compressed.
+func (cR *CompressedRequests) UpdateOneDBAnalysisContainer(mapParams, updateQuery map[string]interface{}) error {
+	compressedQuery, err := cR.compressUpdate(updateQuery)
+	if err != nil {
+		return err
+	}
+	return cR.Requests.UpdateOneDBAnalysisContainer(mapParams, compressedQuery)
+}
+
+// compressUpdate returns a copy of update whose containers, if any, are compressed, so that the
+// containers of the caller keep their raw output.
+func (cR *CompressedRequests) compressUpdate(update map[string]interface{}) (map[string]interface{}, error) {
+	containers, ok := update["containers"].([]types.Container)
+	if !ok {
+		return update, nil
+	}
+	compressedContainers, err := CompressContainers(cR.Codec, containers)
+	if err != nil {
+		return nil, err
+	}
+	compressed := make(map[string]interface{}, len(update))
+	for key, value := range update {
+		compressed[key] = value
+	}
+	compressed["containers"] = compressedContainers
+	return compressed, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package db_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeAnalysisStore keeps the containers of the last analysis update, returning them when an analysis is read.
+type fakeAnalysisStore struct {
+	Requests
+	containers []types.Container
+}
+
+func (f *fakeAnalysisStore) UpdateOneDBAnalysisContainer(mapParams, updateQuery map[string]interface{}) error {
+	f.containers = updateQuery["containers"].([]types.Container)
+	return nil
+}
+
+func (f *fakeAnalysisStore) FindOneDBAnalysis(mapParams map[string]interface{}) (types.Analysis, error) {
+	stored := make([]types.Container, len(f.containers))
+	copy(stored, f.containers)
+	return types.Analysis{RID: "compressedRID", Containers: stored}, nil
+}
+
+func (f *fakeAnalysisStore) FindLatestDBAnalysis(mapParams map[string]interface{}, finishedAfter time.Time) (types.Analysis, error) {
+	return f.FindOneDBAnalysis(mapParams)
+}
+
+// fakeUnavailableStore is a database that is never reachable.
+type fakeUnavailableStore struct {
+	Requests
+}
+
+func (f *fakeUnavailableStore) Available() bool {
+	return false
+}
+
+var _ = Describe("Compression", func() {
+	rawOutput := `{"Issues": [` + strings.Repeat(`{"severity": "HIGH", "file": "main.go"},`, 100) + `{}]}`
+
+	Describe("CompressOutput", func() {
+		Context("When an output is compressed and decompressed with gzip", func() {
+			It("Should return the original output from a smaller one.", func() {
+				compressed, err := CompressOutput(CodecGzip, rawOutput)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(compressed)).To(BeNumerically("<", len(rawOutput)))
+				decompressed, err := DecompressOutput(CodecGzip, compressed)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decompressed).To(Equal(rawOutput))
+			})
+		})
+		Context("When the codec is unknown", func() {
+			It("Should return an error.", func() {
+				_, err := CompressOutput("lz4", rawOutput)
+				Expect(err).To(Equal(ErrUnknownCodec))
+				_, err = DecompressOutput("lz4", rawOutput)
+				Expect(err).To(Equal(ErrUnknownCodec))
+			})
+		})
+		Context("When the output has no codec", func() {
+			It("Should return it as is.", func() {
+				Expect(DecompressOutput("", rawOutput)).To(Equal(rawOutput))
+			})
+		})
+	})
+
+	Describe("CompressedRequests", func() {
+		var store *fakeAnalysisStore
+		var requests *CompressedRequests
+		BeforeEach(func() {
+			store = &fakeAnalysisStore{}
+			requests = &CompressedRequests{Requests: store, Codec: CodecGzip}
+		})
+
+		Context("When the containers of an analysis are stored and read", func() {
+			It("Should store their outputs compressed and read them decompressed.", func() {
+				containers := []types.Container{{CID: "gosecCID", COutput: rawOutput}, {CID: "emptyCID"}}
+				update := map[string]interface{}{"containers": containers}
+				Expect(requests.UpdateOneDBAnalysisContainer(map[string]interface{}{"RID": "compressedRID"}, update)).To(Succeed())
+
+				Expect(store.containers[0].COutputCodec).To(Equal(CodecGzip))
+				Expect(store.containers[0].COutput).NotTo(Equal(rawOutput))
+				Expect(store.containers[1].COutputCodec).To(BeEmpty())
+				Expect(containers[0].COutput).To(Equal(rawOutput))
+
+				analysis, err := requests.FindOneDBAnalysis(map[string]interface{}{"RID": "compressedRID"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analysis.Containers).To(Equal(containers))
+			})
+		})
+
+		Context("When a legacy analysis stored uncompressed is read", func() {
+			It("Should return its outputs as they were stored.", func() {
+				store.containers = []types.Container{{CID: "legacyCID", COutput: rawOutput}}
+				analysis, err := requests.FindLatestDBAnalysis(map[string]interface{}{}, time.Time{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analysis.Containers).To(Equal([]types.Container{{CID: "legacyCID", COutput: rawOutput}}))
+			})
+		})
+
+		Context("When a stored output cannot be decompressed", func() {
+			It("Should return an error.", func() {
+				store.containers = []types.Container{{CID: "brokenCID", COutput: "not gzip", COutputCodec: CodecGzip}}
+				_, err := requests.FindOneDBAnalysis(map[string]interface{}{})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Available", func() {
+		Context("When the wrapped database keeps track of its availability", func() {
+			It("Should tell the availability of the wrapped database.", func() {
+				requests := &CompressedRequests{Requests: &fakeUnavailableStore{}, Codec: CodecGzip}
+				Expect(requests.Available()).To(BeFalse())
+			})
+		})
+		Context("When the wrapped database does not keep track of it", func() {
+			It("Should be available.", func() {
+				requests := &CompressedRequests{Requests: &fakeAnalysisStore{}, Codec: CodecGzip}
+				Expect(requests.Available()).To(BeTrue())
+			})
+		})
+	})
+})
@@ -5,9 +5,11 @@
 package db
 
 import (
+	"errors"
 	"time"
 
 	mongoHuskyCI "github.com/globocom/huskyCI/api/db/mongo"
+	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -34,6 +36,20 @@ func (mR *MongoRequests) ConnectDB(address string, dbName string,
 		timeout)
 }
 
+// Migrate creates the indexes of the collections that do not exist yet, unless SkipIndexes is set.
+func (mR *MongoRequests) Migrate() error {
+	if mR.SkipIndexes {
+		log.Info("Migrate", "DB", 45)
+		return nil
+	}
+	return mongoHuskyCI.EnsureIndexes()
+}
+
+// Available tells whether MongoDB is reachable, as last checked by its auto reconnect.
+func (mR *MongoRequests) Available() bool {
+	return mongoHuskyCI.Available()
+}
+
 // FindOneDBRepository checks if a given repository is present into RepositoryCollection.
 func (mR *MongoRequests) FindOneDBRepository(mapParams map[string]interface{}) (types.Repository, error) {
 	repositoryResponse := types.Repository{}
@@ -58,11 +74,16 @@ func (mR *MongoRequests) FindOneDBSecurityTest(mapParams map[string]interface{})
 	return securityTestResponse, err
 }
 
-// FindOneDBAnalysis checks if a given analysis is present into AnalysisCollection.
+// FindOneDBAnalysis checks if a given analysis is present into AnalysisCollection. An empty string value in
+// the query also matches analyses without that field, as they are stored omitting empty fields.
 func (mR *MongoRequests) FindOneDBAnalysis(mapParams map[string]interface{}) (types.Analysis, error) {
 	analysisResponse := types.Analysis{}
 	analysisQuery := []bson.M{}
 	for k, v := range mapParams {
+		if v == "" {
+			analysisQuery = append(analysisQuery, bson.M{k: bson.M{"$in": []interface{}{"", nil}}})
+			continue
+		}
 		analysisQuery = append(analysisQuery, bson.M{k: v})
 	}
 	analysisFinalQuery := bson.M{"$and": analysisQuery}
@@ -95,13 +116,29 @@ func (mR *MongoRequests) FindOneDBAccessToken(mapParams map[string]interface{})
 	return aTokenResponse, err
 }
 
+// FindOneDBSecurityTestRollout checks if a given securityTest already ran for a repository in SecurityTestRolloutCollection.
+func (mR *MongoRequests) FindOneDBSecurityTestRollout(mapParams map[string]interface{}) (types.SecurityTestRollout, error) {
+	rolloutResponse := types.SecurityTestRollout{}
+	rolloutQuery := []bson.M{}
+	for k, v := range mapParams {
+		rolloutQuery = append(rolloutQuery, bson.M{k: v})
+	}
+	rolloutFinalQuery := bson.M{"$and": rolloutQuery}
+	err := mongoHuskyCI.Conn.SearchOne(rolloutFinalQuery, nil, mongoHuskyCI.SecurityTestRolloutCollection, &rolloutResponse)
+	return rolloutResponse, err
+}
+
 // FindAllDBRepository returns all Repository of a given query present into RepositoryCollection.
+// An empty query returns every Repository.
 func (mR *MongoRequests) FindAllDBRepository(mapParams map[string]interface{}) ([]types.Repository, error) {
-	repositoryQuery := []bson.M{}
-	for k, v := range mapParams {
-		repositoryQuery = append(repositoryQuery, bson.M{k: v})
+	repositoryFinalQuery := bson.M{}
+	if len(mapParams) != 0 {
+		repositoryQuery := []bson.M{}
+		for k, v := range mapParams {
+			repositoryQuery = append(repositoryQuery, bson.M{k: v})
+		}
+		repositoryFinalQuery = bson.M{"$and": repositoryQuery}
 	}
-	repositoryFinalQuery := bson.M{"$and": repositoryQuery}
 	repositoryResponse := []types.Repository{}
 	err := mongoHuskyCI.Conn.Search(repositoryFinalQuery, nil, mongoHuskyCI.RepositoryCollection, &repositoryResponse)
 	return repositoryResponse, err
@@ -131,6 +168,59 @@ func (mR *MongoRequests) FindAllDBAnalysis(mapParams map[string]interface{}) ([]
 	return analysisResponse, err
 }
 
+// FindLatestDBAnalysis returns the analysis of a given query present into AnalysisCollection that finished
+// most recently, as long as it finished after finishedAfter. An empty string value in the query also matches
+// analyses without that field, as they are stored omitting empty fields.
+func (mR *MongoRequests) FindLatestDBAnalysis(mapParams map[string]interface{}, finishedAfter time.Time) (types.Analysis, error) {
+	analysisResponse := types.Analysis{}
+	analysisQuery := []bson.M{{"finishedAt": bson.M{"$gt": finishedAfter}}}
+	for k, v := range mapParams {
+		if v == "" {
+			analysisQuery = append(analysisQuery, bson.M{k: bson.M{"$in": []interface{}{"", nil}}})
+			continue
+		}
+		analysisQuery = append(analysisQuery, bson.M{k: v})
+	}
+	analysisFinalQuery := bson.M{"$and": analysisQuery}
+
+	err := mongoHuskyCI.Conn.SearchLatest(analysisFinalQuery, "finishedAt", mongoHuskyCI.AnalysisCollection, &analysisResponse)
+	return analysisResponse, err
+}
+
+// AnalysisSummaryFields are the only fields read by FindLastDBAnalysisSummaries, so that the findings
+// and container outputs of the analyses are never loaded.
+var AnalysisSummaryFields = []string{"RID", "repositoryBranch", "repositoryCommit", "tags", "status", "result", "startedAt", "finishedAt", "summary"}
+
+// FindLastDBAnalysisSummaries returns, most recent first, the last limit analyses of a given query present
+// into AnalysisCollection that started after startedAfter, holding only their AnalysisSummaryFields. A
+// tags.KEY key of the query matches the value of the tag KEY.
+func (mR *MongoRequests) FindLastDBAnalysisSummaries(mapParams map[string]interface{}, startedAfter time.Time, limit int) ([]types.Analysis, error) {
+	analysisQuery := []bson.M{{"startedAt": bson.M{"$gt": startedAfter}}}
+	for k, v := range mapParams {
+		analysisQuery = append(analysisQuery, bson.M{k: v})
+	}
+	analysisFinalQuery := bson.M{"$and": analysisQuery}
+	analysisResponse := []types.Analysis{}
+	err := mongoHuskyCI.Conn.SearchLast(analysisFinalQuery, AnalysisSummaryFields, "startedAt", limit, mongoHuskyCI.AnalysisCollection, &analysisResponse)
+	return analysisResponse, err
+}
+
+// FindAllDBAccessToken returns all access tokens of a given query present into AccessTokenCollection.
+// An empty query returns every access token.
+func (mR *MongoRequests) FindAllDBAccessToken(mapParams map[string]interface{}) ([]types.DBToken, error) {
+	aTokenFinalQuery := bson.M{}
+	if len(mapParams) != 0 {
+		aTokenQuery := []bson.M{}
+		for k, v := range mapParams {
+			aTokenQuery = append(aTokenQuery, bson.M{k: v})
+		}
+		aTokenFinalQuery = bson.M{"$and": aTokenQuery}
+	}
+	aTokenResponse := []types.DBToken{}
+	err := mongoHuskyCI.Conn.Search(aTokenFinalQuery, nil, mongoHuskyCI.AccessTokenCollection, &aTokenResponse)
+	return aTokenResponse, err
+}
+
 // InsertDBRepository inserts a new repository into RepositoryCollection.
 func (mR *MongoRequests) InsertDBRepository(repository types.Repository) error {
 	newRepository := bson.M{
@@ -151,6 +241,9 @@ func (mR *MongoRequests) InsertDBSecurityTest(securityTest types.SecurityTest) e
 		"type":           securityTest.Type,
 		"default":        securityTest.Default,
 		"timeOutSeconds": securityTest.TimeOutInSeconds,
+		"tool":           securityTest.Tool,
+		"failSeverity":   securityTest.FailSeverity,
+		"graceDays":      securityTest.GraceDays,
 	}
 	err := mongoHuskyCI.Conn.Insert(newSecurityTest, mongoHuskyCI.SecurityTestCollection)
 	return err
@@ -167,6 +260,54 @@ func (mR *MongoRequests) InsertDBAnalysis(analysis types.Analysis) error {
 		"containers":       analysis.Containers,
 		"startedAt":        analysis.StartedAt,
 	}
+	if analysis.SubPath != "" {
+		newAnalysis["repositorySubPath"] = analysis.SubPath
+	}
+	if analysis.Commit != "" {
+		newAnalysis["repositoryCommit"] = analysis.Commit
+	}
+	if analysis.TimeOutInSeconds > 0 {
+		newAnalysis["timeOutInSeconds"] = analysis.TimeOutInSeconds
+	}
+	if analysis.FailFastSeverity != "" {
+		newAnalysis["failFastSeverity"] = analysis.FailFastSeverity
+	}
+	if analysis.OriginAnalysisID != "" {
+		newAnalysis["originAnalysisID"] = analysis.OriginAnalysisID
+	}
+	if analysis.ImageReference != "" {
+		newAnalysis["imageReference"] = analysis.ImageReference
+	}
+	if len(analysis.SecurityTests) > 0 {
+		newAnalysis["securityTests"] = analysis.SecurityTests
+	}
+	if analysis.BranchPolicy != "" {
+		newAnalysis["branchPolicy"] = analysis.BranchPolicy
+	}
+	if analysis.FailSeverity != "" {
+		newAnalysis["failSeverity"] = analysis.FailSeverity
+	}
+	if analysis.SkipNotifications {
+		newAnalysis["skipNotifications"] = analysis.SkipNotifications
+	}
+	if analysis.BaseRef != "" {
+		newAnalysis["repositoryBaseRef"] = analysis.BaseRef
+	}
+	if len(analysis.SecurityTestArgs) > 0 {
+		newAnalysis["securityTestArgs"] = analysis.SecurityTestArgs
+	}
+	if len(analysis.Tags) > 0 {
+		newAnalysis["tags"] = analysis.Tags
+	}
+	if len(analysis.SecurityTestEnv) > 0 {
+		newAnalysis["securityTestEnv"] = analysis.SecurityTestEnv
+	}
+	if analysis.Source != "" {
+		newAnalysis["source"] = analysis.Source
+	}
+	if analysis.Profile != "" {
+		newAnalysis["profile"] = analysis.Profile
+	}
 	err := mongoHuskyCI.Conn.Insert(newAnalysis, mongoHuskyCI.AnalysisCollection)
 	return err
 }
@@ -199,6 +340,41 @@ func (mR *MongoRequests) InsertDBAccessToken(accessToken types.DBToken) error {
 	return err
 }
 
+// InsertDBSecurityTestRollout inserts when a securityTest first ran for a repository into SecurityTestRolloutCollection.
+func (mR *MongoRequests) InsertDBSecurityTestRollout(rollout types.SecurityTestRollout) error {
+	newRollout := bson.M{
+		"repositoryURL":    rollout.URL,
+		"securityTestName": rollout.SecurityTestName,
+		"startedAt":        rollout.StartedAt,
+	}
+	err := mongoHuskyCI.Conn.Insert(newRollout, mongoHuskyCI.SecurityTestRolloutCollection)
+	return err
+}
+
+// FindAllDBAcceptedRisk returns all AcceptedRisk of a given query present into AcceptedRiskCollection.
+func (mR *MongoRequests) FindAllDBAcceptedRisk(mapParams map[string]interface{}) ([]types.AcceptedRisk, error) {
+	acceptedRiskQuery := []bson.M{}
+	for k, v := range mapParams {
+		acceptedRiskQuery = append(acceptedRiskQuery, bson.M{k: v})
+	}
+	acceptedRiskFinalQuery := bson.M{"$and": acceptedRiskQuery}
+	acceptedRiskResponse := []types.AcceptedRisk{}
+	err := mongoHuskyCI.Conn.Search(acceptedRiskFinalQuery, nil, mongoHuskyCI.AcceptedRiskCollection, &acceptedRiskResponse)
+	return acceptedRiskResponse, err
+}
+
+// UpsertOneDBAcceptedRisk inserts the decision of accepting the risk of a vulnerability into AcceptedRiskCollection,
+// replacing any previous decision on it.
+func (mR *MongoRequests) UpsertOneDBAcceptedRisk(mapParams map[string]interface{}, acceptedRisk types.AcceptedRisk) error {
+	acceptedRiskQuery := []bson.M{}
+	for k, v := range mapParams {
+		acceptedRiskQuery = append(acceptedRiskQuery, bson.M{k: v})
+	}
+	acceptedRiskFinalQuery := bson.M{"$and": acceptedRiskQuery}
+	_, err := mongoHuskyCI.Conn.Upsert(acceptedRiskFinalQuery, acceptedRisk, mongoHuskyCI.AcceptedRiskCollection)
+	return err
+}
+
 // UpdateOneDBRepository checks if a given repository is present into RepositoryCollection and update it.
 func (mR *MongoRequests) UpdateOneDBRepository(mapParams, updateQuery map[string]interface{}) error {
 	repositoryQuery := []bson.M{}
@@ -270,3 +446,29 @@ func (mR *MongoRequests) UpdateOneDBAccessToken(mapParams map[string]interface{}
 	err := mongoHuskyCI.Conn.Update(aTokenFinalQuery, updatedAccessToken, mongoHuskyCI.AccessTokenCollection)
 	return err
 }
+
+// DeleteAllDBRepository deletes all repositories of a given query present into RepositoryCollection.
+func (mR *MongoRequests) DeleteAllDBRepository(mapParams map[string]interface{}) error {
+	if len(mapParams) == 0 {
+		return errors.New("Empty fields to search")
+	}
+	repositoryQuery := []bson.M{}
+	for k, v := range mapParams {
+		repositoryQuery = append(repositoryQuery, bson.M{k: v})
+	}
+	repositoryFinalQuery := bson.M{"$and": repositoryQuery}
+	return mongoHuskyCI.Conn.DeleteAll(repositoryFinalQuery, mongoHuskyCI.RepositoryCollection)
+}
+
+// DeleteAllDBAccessToken deletes all access tokens of a given query present into AccessTokenCollection.
+func (mR *MongoRequests) DeleteAllDBAccessToken(mapParams map[string]interface{}) error {
+	if len(mapParams) == 0 {
+		return errors.New("Empty fields to search")
+	}
+	aTokenQuery := []bson.M{}
+	for k, v := range mapParams {
+		aTokenQuery = append(aTokenQuery, bson.M{k: v})
+	}
+	aTokenFinalQuery := bson.M{"$and": aTokenQuery}
+	return mongoHuskyCI.Conn.DeleteAll(aTokenFinalQuery, mongoHuskyCI.AccessTokenCollection)
+}
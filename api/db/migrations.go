@@ -0,0 +1,238 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Migrator is implemented by the databases that need their schema
+// to be created or updated before huskyCI API uses them.
+type Migrator interface {
+	Migrate() error
+}
+
+// Availability is implemented by the databases that keep track of
+// whether they are reachable, so that requests can be refused while
+// they are not instead of failing on their first query.
+type Availability interface {
+	Available() bool
+}
+
+// PostgresMigration is a versioned change of the huskyCI schema
+// in Postgres. Statements must be safe to run on a database created
+// by deployments/huskyci.sql, so they only create what is missing.
+type PostgresMigration struct {
+	Version   int
+	Statement string
+}
+
+// schemaMigration is a row of the table recording the migrations
+// already applied.
+type schemaMigration struct {
+	Version int `json:"version"`
+}
+
+const createSchemaMigrationTable = `CREATE TABLE IF NOT EXISTS "schemaMigration" (
+    version integer PRIMARY KEY,
+    "appliedAt" timestamp without time zone NOT NULL
+)`
+
+// PostgresMigrations holds every migration of the huskyCI schema
+// in the order they are applied.
+var PostgresMigrations = []PostgresMigration{
+	{
+		Version: 1,
+		Statement: `CREATE EXTENSION IF NOT EXISTS "uuid-ossp" WITH SCHEMA public;
+
+CREATE TABLE IF NOT EXISTS public."accessToken" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    huskytoken text NOT NULL UNIQUE,
+    "repositoryURL" text NOT NULL,
+    "isValid" boolean NOT NULL,
+    "createdAt" timestamp without time zone NOT NULL,
+    salt text NOT NULL,
+    uuid text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.analysis (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    "RID" text NOT NULL UNIQUE,
+    "repositoryURL" text NOT NULL,
+    "repositoryBranch" text NOT NULL,
+    "commitAuthors" text[],
+    status text NOT NULL,
+    result text,
+    "errorFound" text,
+    containers jsonb,
+    "startedAt" timestamp without time zone,
+    "finishedAt" timestamp without time zone,
+    codes jsonb,
+    huskyciresults jsonb
+);
+
+CREATE TABLE IF NOT EXISTS public.repository (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    "repositoryURL" text NOT NULL UNIQUE,
+    "repositoryBranch" text,
+    "createdAt" timestamp without time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public."securityTest" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    name text NOT NULL UNIQUE,
+    image text NOT NULL,
+    "imageTag" text,
+    cmd text NOT NULL,
+    type text NOT NULL,
+    language text NOT NULL,
+    "default" boolean NOT NULL,
+    "timeOutSeconds" integer NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public."user" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    username text NOT NULL UNIQUE,
+    password text NOT NULL,
+    salt text,
+    iterations integer,
+    keylen integer,
+    hashfunction text,
+    "newPassword" text,
+    "confirmNewPassword" text
+)`,
+	},
+	{
+		Version: 2,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "repositorySubPath" text;
+ALTER TABLE public."securityTest" ADD COLUMN IF NOT EXISTS "envAllowlist" text`,
+	},
+	{
+		Version:   3,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "failFastAborted" boolean`,
+	},
+	{
+		Version:   4,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "repositoryCommit" text`,
+	},
+	{
+		Version: 5,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "timeOutInSeconds" integer;
+ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "failFastSeverity" text;
+ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "originAnalysisID" text`,
+	},
+	{
+		Version:   6,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "imageReference" text`,
+	},
+	{
+		Version:   7,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS summary jsonb`,
+	},
+	{
+		Version: 8,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "securityTests" jsonb;
+ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "branchPolicy" text;
+ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "failSeverity" text;
+ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "skipNotifications" boolean`,
+	},
+	{
+		Version:   9,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "repositoryBaseRef" text`,
+	},
+	{
+		Version:   10,
+		Statement: `ALTER TABLE public."securityTest" ADD COLUMN IF NOT EXISTS tool text`,
+	},
+	{
+		Version:   11,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "securityTestArgs" jsonb`,
+	},
+	{
+		Version:   12,
+		Statement: `ALTER TABLE public."securityTest" ADD COLUMN IF NOT EXISTS "failSeverity" text`,
+	},
+	{
+		Version:   13,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS source text`,
+	},
+	{
+		Version:   14,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "tags" jsonb`,
+	},
+	{
+		Version: 15,
+		Statement: `ALTER TABLE public."securityTest" ADD COLUMN IF NOT EXISTS "graceDays" integer;
+
+CREATE TABLE IF NOT EXISTS public."securityTestRollout" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    "repositoryURL" text NOT NULL,
+    "securityTestName" text NOT NULL,
+    "startedAt" timestamp without time zone NOT NULL,
+    UNIQUE ("repositoryURL", "securityTestName")
+)`,
+	},
+	{
+		Version: 16,
+		Statement: `CREATE TABLE IF NOT EXISTS public."acceptedRisk" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    "repositoryURL" text NOT NULL,
+    fingerprint text NOT NULL,
+    "acceptedBy" text NOT NULL,
+    "acceptedAt" timestamp without time zone NOT NULL,
+    "expiresAt" timestamp without time zone,
+    note text,
+    UNIQUE ("repositoryURL", fingerprint)
+)`,
+	},
+	{
+		Version:   17,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS profile text`,
+	},
+	{
+		Version:   18,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS components jsonb`,
+	},
+	{
+		Version:   19,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "securityTestEnv" jsonb`,
+	},
+	{
+		Version:   20,
+		Statement: `ALTER TABLE public.analysis ADD COLUMN IF NOT EXISTS "cloneStrategy" jsonb`,
+	},
+}
+
+// Migrate applies to Postgres, in version order, every migration of
+// PostgresMigrations not applied yet. Each migration is recorded in
+// the same statement that applies it, so it either fully succeeds
+// or is retried on the next startup.
+func (pR *PostgresRequests) Migrate() error {
+	if _, err := pR.DataRetriever.WriteInDB(createSchemaMigrationTable); err != nil {
+		return err
+	}
+	applied := []schemaMigration{}
+	if err := pR.DataRetriever.RetrieveFromDB(
+		`SELECT version FROM "schemaMigration"`, &applied, []string{}); err != nil && err.Error() != "No data found" {
+		return err
+	}
+	appliedVersions := make(map[int]bool)
+	for _, migration := range applied {
+		appliedVersions[migration.Version] = true
+	}
+	migrations := append([]PostgresMigration{}, PostgresMigrations...)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for _, migration := range migrations {
+		if appliedVersions[migration.Version] {
+			continue
+		}
+		statement := fmt.Sprintf(
+			`%s; INSERT INTO "schemaMigration" (version, "appliedAt") VALUES (%d, now()) ON CONFLICT (version) DO NOTHING`,
+			migration.Statement, migration.Version)
+		if _, err := pR.DataRetriever.WriteInDB(statement); err != nil {
+			return fmt.Errorf("migration %d: %s", migration.Version, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"strings"
+
+	"github.com/globocom/huskyCI/api/log"
+	mgo "gopkg.in/mgo.v2"
+)
+
+const logActionEnsureIndexes = "EnsureIndexes"
+
+// Index is an index of a collection on Key, whose fields are descending when prefixed with -.
+type Index struct {
+	Collection string
+	Key        []string
+}
+
+// Indexes are the indexes of the queries huskyCI API makes, so that looking up an analysis, listing the
+// analyses of a repository and validating an access token do not scan whole collections.
+var Indexes = []Index{
+	{Collection: AnalysisCollection, Key: []string{"RID"}},
+	{Collection: AnalysisCollection, Key: []string{"status"}},
+	{Collection: AnalysisCollection, Key: []string{"repositoryURL", "-startedAt"}},
+	{Collection: AnalysisCollection, Key: []string{"repositoryURL", "repositoryCommit", "-finishedAt"}},
+	{Collection: RepositoryCollection, Key: []string{"repositoryURL"}},
+	{Collection: RepositoryCollection, Key: []string{"createdAt"}},
+	{Collection: AccessTokenCollection, Key: []string{"uuid"}},
+	{Collection: AccessTokenCollection, Key: []string{"repositoryURL", "isValid"}},
+	{Collection: AccessTokenCollection, Key: []string{"createdAt"}},
+	{Collection: UserCollection, Key: []string{"username"}},
+	{Collection: SecurityTestCollection, Key: []string{"name"}},
+	{Collection: SecurityTestRolloutCollection, Key: []string{"repositoryURL", "securityTestName"}},
+	{Collection: AcceptedRiskCollection, Key: []string{"repositoryURL"}},
+}
+
+// EnsureIndexes creates each one of Indexes that does not exist yet, logging the ones it builds, so that
+// it can run at every startup.
+func EnsureIndexes() error {
+	if !Available() {
+		return ErrUnavailable
+	}
+	session := Conn.Session.Clone()
+	defer session.Close()
+
+	existing := make(map[string]map[string]bool)
+	for _, index := range Indexes {
+		c := session.DB("").C(index.Collection)
+		if existing[index.Collection] == nil {
+			collectionIndexes, err := c.Indexes()
+			if err != nil && !isNamespaceNotFound(err) {
+				log.Warning(logActionEnsureIndexes, logInfoMongo, 133, index.Collection, "", err)
+				return err
+			}
+			existing[index.Collection] = make(map[string]bool)
+			for _, collectionIndex := range collectionIndexes {
+				existing[index.Collection][strings.Join(collectionIndex.Key, ",")] = true
+			}
+		}
+		key := strings.Join(index.Key, ",")
+		if existing[index.Collection][key] {
+			continue
+		}
+		if err := c.EnsureIndex(mgo.Index{Key: index.Key, Background: true}); err != nil {
+			log.Warning(logActionEnsureIndexes, logInfoMongo, 133, index.Collection, key, err)
+			return err
+		}
+		existing[index.Collection][key] = true
+		log.Info(logActionEnsureIndexes, logInfoMongo, 44, index.Collection, key)
+	}
+	return nil
+}
+
+// isNamespaceNotFound tells whether err is returned by listing the indexes of a collection not created yet.
+func isNamespaceNotFound(err error) bool {
+	queryError, ok := err.(*mgo.QueryError)
+	return ok && queryError.Code == 26
+}
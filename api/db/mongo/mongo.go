@@ -5,7 +5,9 @@
 package db
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/globocom/huskyCI/api/log"
@@ -18,11 +20,13 @@ var Conn *DB
 
 // Collections names used in MongoDB.
 var (
-	RepositoryCollection   = "repository"
-	SecurityTestCollection = "securityTest"
-	AnalysisCollection     = "analysis"
-	UserCollection         = "user"
-	AccessTokenCollection  = "accessToken"
+	RepositoryCollection          = "repository"
+	SecurityTestCollection        = "securityTest"
+	AnalysisCollection            = "analysis"
+	UserCollection                = "user"
+	AccessTokenCollection         = "accessToken"
+	SecurityTestRolloutCollection = "securityTestRollout"
+	AcceptedRiskCollection        = "acceptedRisk"
 )
 
 // DB is the struct that represents mongo session.
@@ -30,6 +34,19 @@ type DB struct {
 	Session *mgo.Session
 }
 
+// ErrUnavailable is returned instead of running a query while MongoDB is unreachable, until
+// autoReconnect is able to ping it again.
+var ErrUnavailable = errors.New("MongoDB is unreachable")
+
+// Backoff of autoReconnect while MongoDB is unreachable, doubling from its minimum after each failed attempt.
+var (
+	ReconnectMinBackoff = time.Second
+	ReconnectMaxBackoff = 30 * time.Second
+)
+
+// unavailable is set to 1 while the circuit is open, that is, while MongoDB is unreachable.
+var unavailable int32
+
 const logActionConnect = "Connect"
 const logActionReconnect = "autoReconnect"
 const logInfoMongo = "DB"
@@ -42,6 +59,9 @@ type Database interface {
 	UpdateAll(query, updateQuery bson.M, collection string) error
 	Upsert(query bson.M, obj interface{}, collection string) (*mgo.ChangeInfo, error)
 	SearchOne(query bson.M, selectors []string, collection string, obj interface{}) error
+	SearchLatest(query bson.M, sortField string, collection string, obj interface{}) error
+	SearchLast(query bson.M, selectors []string, sortField string, limit int, collection string, obj interface{}) error
+	DeleteAll(query bson.M, collection string) error
 }
 
 // Connect connects to mongo and returns the session.
@@ -72,33 +92,68 @@ func Connect(address, dbName, username, password string, poolLimit, port int, ti
 	}
 
 	Conn = &DB{Session: session}
+	atomic.StoreInt32(&unavailable, 0)
 	go autoReconnect()
 
 	return nil
 }
 
-// autoReconnect checks mongo's connection each second and, if an error is found, reconect to it.
+// Available tells whether MongoDB was reachable the last time autoReconnect pinged it.
+func Available() bool {
+	return atomic.LoadInt32(&unavailable) == 0
+}
+
+// ReconnectBackoff returns how long autoReconnect waits after the given number of failed attempts.
+func ReconnectBackoff(failedAttempts int) time.Duration {
+	backoff := ReconnectMinBackoff
+	for i := 1; i < failedAttempts && backoff < ReconnectMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > ReconnectMaxBackoff {
+		backoff = ReconnectMaxBackoff
+	}
+	return backoff
+}
+
+// autoReconnect checks mongo's connection each second and, if an error is found, opens the circuit and
+// tries to reconnect to it with a capped exponential backoff, closing the circuit once it succeeds.
 func autoReconnect() {
 	log.Info(logActionReconnect, logInfoMongo, 22)
-	var err error
+	failedAttempts := 0
 	for {
-		err = Conn.Session.Ping()
+		err := Conn.Session.Ping()
 		if err != nil {
-			log.Error(logActionReconnect, logInfoMongo, 2003, err)
+			if failedAttempts == 0 {
+				log.Error(logActionReconnect, logInfoMongo, 2003, err)
+				atomic.StoreInt32(&unavailable, 1)
+			}
 			Conn.Session.Refresh()
 			err = Conn.Session.Ping()
-			if err == nil {
+		}
+		if err == nil {
+			if !Available() {
 				log.Info(logActionReconnect, logInfoMongo, 23)
-			} else {
-				log.Error(logActionReconnect, logInfoMongo, 2004, err)
+				atomic.StoreInt32(&unavailable, 0)
 			}
+			failedAttempts = 0
+			time.Sleep(time.Second * 1)
+			continue
+		}
+		failedAttempts++
+		backoff := ReconnectBackoff(failedAttempts)
+		// once the backoff is capped, only every tenth attempt is logged.
+		if backoff < ReconnectMaxBackoff || failedAttempts%10 == 0 {
+			log.Error(logActionReconnect, logInfoMongo, 2004, failedAttempts, backoff, err)
 		}
-		time.Sleep(time.Second * 1)
+		time.Sleep(backoff)
 	}
 }
 
 // Insert inserts a new document.
 func (db *DB) Insert(obj interface{}, collection string) error {
+	if !Available() {
+		return ErrUnavailable
+	}
 	session := db.Session.Clone()
 	c := session.DB("").C(collection)
 	defer session.Close()
@@ -107,6 +162,9 @@ func (db *DB) Insert(obj interface{}, collection string) error {
 
 // Update updates a single document.
 func (db *DB) Update(query, updateQuery interface{}, collection string) error {
+	if !Available() {
+		return ErrUnavailable
+	}
 	session := db.Session.Clone()
 	c := session.DB("").C(collection)
 	defer session.Close()
@@ -116,6 +174,9 @@ func (db *DB) Update(query, updateQuery interface{}, collection string) error {
 
 // UpdateAll updates all documents that match the query.
 func (db *DB) UpdateAll(query, updateQuery interface{}, collection string) error {
+	if !Available() {
+		return ErrUnavailable
+	}
 	session := db.Session.Clone()
 	c := session.DB("").C(collection)
 	defer session.Close()
@@ -125,6 +186,9 @@ func (db *DB) UpdateAll(query, updateQuery interface{}, collection string) error
 
 // Search searchs all documents that match the query. If selectors are present, the return will be only the chosen fields.
 func (db *DB) Search(query bson.M, selectors []string, collection string, obj interface{}) error {
+	if !Available() {
+		return ErrUnavailable
+	}
 	session := db.Session.Clone()
 	defer session.Close()
 	c := session.DB("").C(collection)
@@ -147,6 +211,9 @@ func (db *DB) Search(query bson.M, selectors []string, collection string, obj in
 
 // Aggregation prepares a pipeline to aggregate.
 func (db *DB) Aggregation(aggregation []bson.M, collection string) (interface{}, error) {
+	if !Available() {
+		return nil, ErrUnavailable
+	}
 	session := db.Session.Clone()
 	defer session.Close()
 	c := session.DB("").C(collection)
@@ -161,6 +228,9 @@ func (db *DB) Aggregation(aggregation []bson.M, collection string) (interface{},
 
 // SearchOne searchs for the first element that matchs with the given query.
 func (db *DB) SearchOne(query bson.M, selectors []string, collection string, obj interface{}) error {
+	if !Available() {
+		return ErrUnavailable
+	}
 	session := db.Session.Clone()
 	defer session.Close()
 	c := session.DB("").C(collection)
@@ -181,8 +251,55 @@ func (db *DB) SearchOne(query bson.M, selectors []string, collection string, obj
 	return err
 }
 
+// SearchLatest searchs for the element that matchs with the given query and has the highest sortField.
+func (db *DB) SearchLatest(query bson.M, sortField string, collection string, obj interface{}) error {
+	if !Available() {
+		return ErrUnavailable
+	}
+	session := db.Session.Clone()
+	defer session.Close()
+	c := session.DB("").C(collection)
+	return c.Find(query).Sort("-" + sortField).One(obj)
+}
+
+// SearchLast searchs for the limit elements that match the given query with the highest sortField, highest
+// first. If selectors are present, the return will be only the chosen fields.
+func (db *DB) SearchLast(query bson.M, selectors []string, sortField string, limit int, collection string, obj interface{}) error {
+	if !Available() {
+		return ErrUnavailable
+	}
+	session := db.Session.Clone()
+	defer session.Close()
+	c := session.DB("").C(collection)
+
+	q := c.Find(query)
+	if selectors != nil {
+		selector := bson.M{}
+		for _, v := range selectors {
+			selector[v] = 1
+		}
+		q = q.Select(selector)
+	}
+	return q.Sort("-" + sortField).Limit(limit).All(obj)
+}
+
+// DeleteAll deletes all documents that match the query.
+func (db *DB) DeleteAll(query bson.M, collection string) error {
+	if !Available() {
+		return ErrUnavailable
+	}
+	session := db.Session.Clone()
+	c := session.DB("").C(collection)
+	defer session.Close()
+	_, err := c.RemoveAll(query)
+	return err
+}
+
 // Upsert inserts a document or update it if it already exists.
 func (db *DB) Upsert(query bson.M, obj interface{}, collection string) (*mgo.ChangeInfo, error) {
+	if !Available() {
+		return nil, ErrUnavailable
+	}
 	session := db.Session.Clone()
 	c := session.DB("").C(collection)
 	defer session.Close()
@@ -0,0 +1,36 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package db_test
+
+import (
+	"time"
+
+	db "github.com/globocom/huskyCI/api/db/mongo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReconnectBackoff", func() {
+	Context("When the reconnection keeps failing", func() {
+		It("Should double the backoff after each attempt.", func() {
+			Expect(db.ReconnectBackoff(1)).To(Equal(time.Second))
+			Expect(db.ReconnectBackoff(2)).To(Equal(2 * time.Second))
+			Expect(db.ReconnectBackoff(5)).To(Equal(16 * time.Second))
+		})
+		It("Should cap the backoff.", func() {
+			Expect(db.ReconnectBackoff(6)).To(Equal(30 * time.Second))
+			Expect(db.ReconnectBackoff(1000)).To(Equal(30 * time.Second))
+		})
+	})
+})
+
+var _ = Describe("Available", func() {
+	Context("When autoReconnect never found MongoDB unreachable", func() {
+		It("Should be true.", func() {
+			Expect(db.Available()).To(BeTrue())
+		})
+	})
+})
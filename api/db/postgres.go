@@ -3,6 +3,8 @@ package db
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -57,11 +59,30 @@ func (pR *PostgresRequests) FindOneDBSecurityTest(
 	return securityTestResponse[0], nil
 }
 
-// FindOneDBAnalysis checks if a given analysis is present into analysis table.
+// FindOneDBAnalysis checks if a given analysis is present into analysis
+// table. An empty string value in the query also matches NULL ones.
 func (pR *PostgresRequests) FindOneDBAnalysis(
 	mapParams map[string]interface{}) (types.Analysis, error) {
 	analysisResponse := []types.Analysis{}
-	query, params := ConfigureQuery(`SELECT * FROM "analysis"`, mapParams)
+	keys := make([]string, 0, len(mapParams))
+	for k := range mapParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	query := `SELECT * FROM "analysis"`
+	params := make([]interface{}, 0, len(keys))
+	for i, k := range keys {
+		condition := "WHERE"
+		if i > 0 {
+			condition = "AND"
+		}
+		params = append(params, mapParams[k])
+		if mapParams[k] == "" {
+			query = fmt.Sprintf(`%s %s COALESCE("%s", '') = $%d`, query, condition, k, len(params))
+			continue
+		}
+		query = fmt.Sprintf(`%s %s "%s" = $%d`, query, condition, k, len(params))
+	}
 	if err := pR.DataRetriever.RetrieveFromDB(
 		query, &analysisResponse, []string{"commitAuthors"}, params...); err != nil {
 		return types.Analysis{}, err
@@ -93,6 +114,19 @@ func (pR *PostgresRequests) FindOneDBAccessToken(
 	return tokenResponse[0], nil
 }
 
+// FindOneDBSecurityTestRollout checks if a given securityTest already ran for a repository
+// in securityTestRollout table.
+func (pR *PostgresRequests) FindOneDBSecurityTestRollout(
+	mapParams map[string]interface{}) (types.SecurityTestRollout, error) {
+	rolloutResponse := []types.SecurityTestRollout{}
+	query, params := ConfigureQuery(`SELECT * FROM "securityTestRollout"`, mapParams)
+	if err := pR.DataRetriever.RetrieveFromDB(
+		query, &rolloutResponse, []string{}, params...); err != nil {
+		return types.SecurityTestRollout{}, err
+	}
+	return rolloutResponse[0], nil
+}
+
 // FindAllDBRepository returns all Repository of a given query present into repository table.
 func (pR *PostgresRequests) FindAllDBRepository(
 	mapParams map[string]interface{}) ([]types.Repository, error) {
@@ -130,9 +164,86 @@ func (pR *PostgresRequests) FindAllDBAnalysis(
 	return analysisResponse, nil
 }
 
+// FindLatestDBAnalysis returns the analysis of a given query present into
+// analysis table that finished most recently, as long as it finished after
+// finishedAfter. An empty string value in the query also matches NULL ones.
+func (pR *PostgresRequests) FindLatestDBAnalysis(
+	mapParams map[string]interface{}, finishedAfter time.Time) (types.Analysis, error) {
+	analysisResponse := []types.Analysis{}
+	keys := make([]string, 0, len(mapParams))
+	for k := range mapParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	query := `SELECT * FROM "analysis" WHERE "finishedAt" > $1`
+	params := []interface{}{finishedAfter}
+	for _, k := range keys {
+		params = append(params, mapParams[k])
+		if _, ok := mapParams[k].(string); ok {
+			query = fmt.Sprintf(`%s AND COALESCE("%s", '') = $%d`, query, k, len(params))
+			continue
+		}
+		query = fmt.Sprintf(`%s AND "%s" = $%d`, query, k, len(params))
+	}
+	query = fmt.Sprintf(`%s ORDER BY "finishedAt" DESC LIMIT 1`, query)
+	if err := pR.DataRetriever.RetrieveFromDB(
+		query, &analysisResponse, []string{"commitAuthors"}, params...); err != nil {
+		return types.Analysis{}, err
+	}
+	return analysisResponse[0], nil
+}
+
+// FindLastDBAnalysisSummaries returns, most recent first, the last limit
+// analyses of a given query present into analysis table that started after
+// startedAfter, holding only their AnalysisSummaryFields. A tags.KEY key of
+// the query matches the value of the tag KEY.
+func (pR *PostgresRequests) FindLastDBAnalysisSummaries(
+	mapParams map[string]interface{}, startedAfter time.Time, limit int) ([]types.Analysis, error) {
+	analysisResponse := []types.Analysis{}
+	keys := make([]string, 0, len(mapParams))
+	for k := range mapParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	columns := make([]string, 0, len(AnalysisSummaryFields))
+	for _, field := range AnalysisSummaryFields {
+		columns = append(columns, fmt.Sprintf(`"%s"`, field))
+	}
+	query := fmt.Sprintf(`SELECT %s FROM "analysis" WHERE "startedAt" > $1`, strings.Join(columns, ", "))
+	params := []interface{}{startedAfter}
+	for _, k := range keys {
+		params = append(params, mapParams[k])
+		if tag := strings.TrimPrefix(k, "tags."); tag != k {
+			query = fmt.Sprintf(`%s AND "tags"->>'%s' = $%d`, query, tag, len(params))
+			continue
+		}
+		query = fmt.Sprintf(`%s AND "%s" = $%d`, query, k, len(params))
+	}
+	params = append(params, limit)
+	query = fmt.Sprintf(`%s ORDER BY "startedAt" DESC LIMIT $%d`, query, len(params))
+	if err := pR.DataRetriever.RetrieveFromDB(
+		query, &analysisResponse, []string{}, params...); err != nil {
+		return analysisResponse, err
+	}
+	return analysisResponse, nil
+}
+
+// FindAllDBAccessToken returns all access tokens of a given query present
+// into accessToken table.
+func (pR *PostgresRequests) FindAllDBAccessToken(
+	mapParams map[string]interface{}) ([]types.DBToken, error) {
+	aTokenResponse := []types.DBToken{}
+	query, params := ConfigureQuery(`SELECT * FROM "accessToken"`, mapParams)
+	if err := pR.DataRetriever.RetrieveFromDB(
+		query, &aTokenResponse, []string{}, params...); err != nil {
+		return aTokenResponse, err
+	}
+	return aTokenResponse, nil
+}
+
 // InsertDBRepository inserts a new repository into repository table.
 func (pR *PostgresRequests) InsertDBRepository(repository types.Repository) error {
-	if (types.Repository{}) == repository {
+	if reflect.DeepEqual(types.Repository{}, repository) {
 		return errors.New("Empty repository data")
 	}
 	repositoryMap := map[string]interface{}{
@@ -165,6 +276,10 @@ func (pR *PostgresRequests) InsertDBSecurityTest(securityTest types.SecurityTest
 		"type":           securityTest.Type,
 		"default":        securityTest.Default,
 		"timeOutSeconds": securityTest.TimeOutInSeconds,
+		"envAllowlist":   securityTest.EnvAllowlist,
+		"tool":           securityTest.Tool,
+		"failSeverity":   securityTest.FailSeverity,
+		"graceDays":      securityTest.GraceDays,
 	}
 	finalQuery, values := ConfigureInsertQuery(
 		`INSERT into "securityTest"`, securityTestMap)
@@ -184,11 +299,27 @@ func (pR *PostgresRequests) InsertDBAnalysis(analysis types.Analysis) error {
 		return errors.New("Empty Analysis data")
 	}
 	analysisMap := map[string]interface{}{
-		"RID":              analysis.RID,
-		"repositoryURL":    analysis.URL,
-		"repositoryBranch": analysis.Branch,
-		"status":           analysis.Status,
-		"startedAt":        analysis.StartedAt,
+		"RID":               analysis.RID,
+		"repositoryURL":     analysis.URL,
+		"repositoryBranch":  analysis.Branch,
+		"repositorySubPath": analysis.SubPath,
+		"repositoryCommit":  analysis.Commit,
+		"status":            analysis.Status,
+		"startedAt":         analysis.StartedAt,
+		"timeOutInSeconds":  analysis.TimeOutInSeconds,
+		"failFastSeverity":  analysis.FailFastSeverity,
+		"originAnalysisID":  analysis.OriginAnalysisID,
+		"imageReference":    analysis.ImageReference,
+		"securityTests":     analysis.SecurityTests,
+		"branchPolicy":      analysis.BranchPolicy,
+		"failSeverity":      analysis.FailSeverity,
+		"skipNotifications": analysis.SkipNotifications,
+		"repositoryBaseRef": analysis.BaseRef,
+		"securityTestArgs":  analysis.SecurityTestArgs,
+		"tags":              analysis.Tags,
+		"securityTestEnv":   analysis.SecurityTestEnv,
+		"source":            analysis.Source,
+		"profile":           analysis.Profile,
 	}
 	analysisMap, err := pR.ConfigureAnalysisData(analysisMap)
 	if err != nil {
@@ -256,6 +387,72 @@ func (pR *PostgresRequests) InsertDBAccessToken(accessToken types.DBToken) error
 	return nil
 }
 
+// FindAllDBAcceptedRisk returns all accepted risks of a given query
+// present in acceptedRisk table.
+func (pR *PostgresRequests) FindAllDBAcceptedRisk(
+	mapParams map[string]interface{}) ([]types.AcceptedRisk, error) {
+	acceptedRiskResponse := []types.AcceptedRisk{}
+	query, params := ConfigureQuery(`SELECT * FROM "acceptedRisk"`, mapParams)
+	if err := pR.DataRetriever.RetrieveFromDB(
+		query, &acceptedRiskResponse, []string{}, params...); err != nil {
+		return acceptedRiskResponse, err
+	}
+	return acceptedRiskResponse, nil
+}
+
+// InsertDBSecurityTestRollout inserts when a securityTest first ran for a repository
+// into securityTestRollout table.
+func (pR *PostgresRequests) InsertDBSecurityTestRollout(rollout types.SecurityTestRollout) error {
+	if (types.SecurityTestRollout{}) == rollout {
+		return errors.New("Empty SecurityTestRollout data")
+	}
+	rolloutMap := map[string]interface{}{
+		"repositoryURL":    rollout.URL,
+		"securityTestName": rollout.SecurityTestName,
+		"startedAt":        rollout.StartedAt,
+	}
+	finalQuery, values := ConfigureInsertQuery(
+		`INSERT into "securityTestRollout"`, rolloutMap)
+	rowsAff, err := pR.DataRetriever.WriteInDB(finalQuery, values...)
+	if err != nil {
+		return err
+	}
+	if rowsAff == int64(0) {
+		return errors.New("No data was inserted")
+	}
+	return nil
+}
+
+// UpsertOneDBAcceptedRisk inserts the decision of accepting the risk of
+// a vulnerability into acceptedRisk table, replacing any previous one.
+func (pR *PostgresRequests) UpsertOneDBAcceptedRisk(
+	mapParams map[string]interface{}, acceptedRisk types.AcceptedRisk) error {
+	if acceptedRisk.RepositoryURL == "" || acceptedRisk.Fingerprint == "" {
+		return errors.New("Empty AcceptedRisk data")
+	}
+	if len(mapParams) == 0 {
+		return errors.New("Empty fields to search")
+	}
+	acceptedRiskMap := map[string]interface{}{
+		"repositoryURL": acceptedRisk.RepositoryURL,
+		"fingerprint":   acceptedRisk.Fingerprint,
+		"acceptedBy":    acceptedRisk.AcceptedBy,
+		"acceptedAt":    acceptedRisk.AcceptedAt,
+		"expiresAt":     acceptedRisk.ExpiresAt,
+		"note":          acceptedRisk.Note,
+	}
+	finalQuery, values := ConfigureUpsertQuery(
+		`INSERT into "acceptedRisk"`, mapParams, acceptedRiskMap)
+	rowsAff, err := pR.DataRetriever.WriteInDB(finalQuery, values...)
+	if err != nil {
+		return err
+	}
+	if rowsAff == int64(0) {
+		return errors.New("No data was updated")
+	}
+	return nil
+}
+
 // UpdateOneDBRepository checks if a given repository is present into repository table
 // and update it.
 func (pR *PostgresRequests) UpdateOneDBRepository(
@@ -297,6 +494,10 @@ func (pR *PostgresRequests) UpsertOneDBSecurityTest(
 		"language":       updatedSecurityTest.Language,
 		"default":        updatedSecurityTest.Default,
 		"timeOutSeconds": updatedSecurityTest.TimeOutInSeconds,
+		"envAllowlist":   updatedSecurityTest.EnvAllowlist,
+		"tool":           updatedSecurityTest.Tool,
+		"failSeverity":   updatedSecurityTest.FailSeverity,
+		"graceDays":      updatedSecurityTest.GraceDays,
 	}
 	finalQuery, values := ConfigureUpsertQuery(
 		`INSERT into "securityTest"`, mapParams, updatedSecurityMap)
@@ -421,6 +622,30 @@ func (pR *PostgresRequests) UpdateOneDBAccessToken(
 	return nil
 }
 
+// DeleteAllDBRepository deletes all repositories of a given query present
+// into repository table.
+func (pR *PostgresRequests) DeleteAllDBRepository(
+	mapParams map[string]interface{}) error {
+	if len(mapParams) == 0 {
+		return errors.New("Empty fields to search")
+	}
+	query, params := ConfigureQuery(`DELETE FROM repository`, mapParams)
+	_, err := pR.DataRetriever.WriteInDB(query, params...)
+	return err
+}
+
+// DeleteAllDBAccessToken deletes all access tokens of a given query present
+// into accessToken table.
+func (pR *PostgresRequests) DeleteAllDBAccessToken(
+	mapParams map[string]interface{}) error {
+	if len(mapParams) == 0 {
+		return errors.New("Empty fields to search")
+	}
+	query, params := ConfigureQuery(`DELETE FROM "accessToken"`, mapParams)
+	_, err := pR.DataRetriever.WriteInDB(query, params...)
+	return err
+}
+
 // GetMetricByType returns data about the metric received
 func (pR *PostgresRequests) GetMetricByType(
 	metricType string, queryStringParams map[string][]string) (interface{}, error) {
@@ -564,6 +789,55 @@ func (pR *PostgresRequests) ConfigureAnalysisData(
 		}
 		updatedAnalysis["containers"] = containerJSON
 	}
+	if summary, ok := updatedAnalysis["summary"].(types.AnalysisSummary); ok {
+		summaryJSON, err := pR.JSONHandler.Marshal(summary)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["summary"] = summaryJSON
+	}
+	if securityTests, ok := updatedAnalysis["securityTests"].([]string); ok {
+		securityTestsJSON, err := pR.JSONHandler.Marshal(securityTests)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["securityTests"] = securityTestsJSON
+	}
+	if securityTestArgs, ok := updatedAnalysis["securityTestArgs"].(map[string][]string); ok {
+		securityTestArgsJSON, err := pR.JSONHandler.Marshal(securityTestArgs)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["securityTestArgs"] = securityTestArgsJSON
+	}
+	if securityTestEnv, ok := updatedAnalysis["securityTestEnv"].(map[string]map[string]string); ok {
+		securityTestEnvJSON, err := pR.JSONHandler.Marshal(securityTestEnv)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["securityTestEnv"] = securityTestEnvJSON
+	}
+	if tags, ok := updatedAnalysis["tags"].(map[string]string); ok {
+		tagsJSON, err := pR.JSONHandler.Marshal(tags)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["tags"] = tagsJSON
+	}
+	if components, ok := updatedAnalysis["components"].([]types.Component); ok {
+		componentsJSON, err := pR.JSONHandler.Marshal(components)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["components"] = componentsJSON
+	}
+	if cloneStrategy, ok := updatedAnalysis["cloneStrategy"].(types.CloneStrategy); ok {
+		cloneStrategyJSON, err := pR.JSONHandler.Marshal(cloneStrategy)
+		if err != nil {
+			return updatedAnalysis, err
+		}
+		updatedAnalysis["cloneStrategy"] = cloneStrategyJSON
+	}
 	if huskyciresults, ok := updatedAnalysis["huskyciresults"].(types.HuskyCIResults); ok {
 		huskyJSON, err := pR.JSONHandler.Marshal(huskyciresults)
 		if err != nil {
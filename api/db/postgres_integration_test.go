@@ -0,0 +1,117 @@
+//go:build integration
+// +build integration
+
+package db_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/globocom/huskyCI/api/db"
+	postgres "github.com/globocom/huskyCI/api/db/postgres"
+	"github.com/globocom/huskyCI/api/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// These specs run against the Postgres instance set on the
+// HUSKYCI_TEST_POSTGRES_* env vars. Every table is emptied
+// before each of them, so never point them to a real database:
+//
+//	go test -tags integration ./db/
+var _ = Describe("Postgres integration", func() {
+
+	var (
+		requests  *PostgresRequests
+		retriever *SQLJSONRetrieve
+	)
+
+	BeforeEach(func() {
+		address := os.Getenv("HUSKYCI_TEST_POSTGRES_ADDRESS")
+		if address == "" {
+			Skip("HUSKYCI_TEST_POSTGRES_ADDRESS is not set")
+		}
+		jsonHandler := JSONCaller{}
+		retriever = &SQLJSONRetrieve{
+			Psql:        &postgres.SQLConfig{Postgres: &postgres.PostgresHandler{}},
+			JSONHandler: &jsonHandler,
+		}
+		requests = &PostgresRequests{DataRetriever: retriever, JSONHandler: &jsonHandler}
+		Expect(requests.ConnectDB(
+			address,
+			os.Getenv("HUSKYCI_TEST_POSTGRES_DB"),
+			os.Getenv("HUSKYCI_TEST_POSTGRES_USER"),
+			os.Getenv("HUSKYCI_TEST_POSTGRES_PASSWORD"),
+			time.Minute, 0, 0, 5, 5, time.Minute)).To(Succeed())
+		Expect(requests.Migrate()).To(Succeed())
+		_, err := retriever.WriteInDB(`TRUNCATE "accessToken", analysis, repository, "securityTest", "user"`)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("Migrate", func() {
+		It("Should do nothing when the schema is up to date.", func() {
+			Expect(requests.Migrate()).To(Succeed())
+			versions := []map[string]interface{}{}
+			Expect(retriever.RetrieveFromDB(`SELECT version FROM "schemaMigration"`, &versions, []string{})).To(Succeed())
+			Expect(versions).To(HaveLen(len(PostgresMigrations)))
+		})
+	})
+
+	Describe("Repositories", func() {
+		It("Should find an inserted repository.", func() {
+			repository := types.Repository{URL: "https://github.com/globocom/huskyCI.git", CreatedAt: time.Now()}
+			Expect(requests.InsertDBRepository(repository)).To(Succeed())
+			found, err := requests.FindOneDBRepository(map[string]interface{}{"repositoryURL": repository.URL})
+			Expect(err).To(BeNil())
+			Expect(found.URL).To(Equal(repository.URL))
+		})
+	})
+
+	Describe("Access tokens", func() {
+		It("Should find an inserted access token and its updates.", func() {
+			accessToken := types.DBToken{
+				HuskyToken: "hashedToken",
+				URL:        "https://github.com/globocom/huskyCI.git",
+				IsValid:    true,
+				CreatedAt:  time.Now(),
+				Salt:       "salt",
+				UUID:       "e7d1b6a2-5f3c-4d8e-9a0b-1c2d3e4f5a6b",
+			}
+			Expect(requests.InsertDBAccessToken(accessToken)).To(Succeed())
+			accessToken.IsValid = false
+			Expect(requests.UpdateOneDBAccessToken(map[string]interface{}{"uuid": accessToken.UUID}, accessToken)).To(Succeed())
+			found, err := requests.FindOneDBAccessToken(map[string]interface{}{"uuid": accessToken.UUID})
+			Expect(err).To(BeNil())
+			Expect(found.HuskyToken).To(Equal(accessToken.HuskyToken))
+			Expect(found.IsValid).To(BeFalse())
+		})
+	})
+
+	Describe("Analyses", func() {
+		It("Should find an inserted analysis with its containers.", func() {
+			analysis := types.Analysis{
+				RID:       "integrationRID",
+				URL:       "https://github.com/globocom/huskyCI.git",
+				Branch:    "master",
+				Status:    "running",
+				StartedAt: time.Now(),
+			}
+			Expect(requests.InsertDBAnalysis(analysis)).To(Succeed())
+			containers := []types.Container{{
+				SecurityTest: types.SecurityTest{Name: "gosec"},
+				CStatus:      "finished",
+				CResult:      "passed",
+				Image:        "huskyci/gosec:v2.3.0",
+			}}
+			Expect(requests.UpdateOneDBAnalysisContainer(
+				map[string]interface{}{"RID": analysis.RID},
+				map[string]interface{}{"containers": containers})).To(Succeed())
+			found, err := requests.FindOneDBAnalysis(map[string]interface{}{"RID": analysis.RID})
+			Expect(err).To(BeNil())
+			Expect(found.Status).To(Equal("running"))
+			Expect(found.Containers).To(HaveLen(1))
+			Expect(found.Containers[0].SecurityTest.Name).To(Equal("gosec"))
+			Expect(found.Containers[0].Image).To(Equal("huskyci/gosec:v2.3.0"))
+		})
+	})
+})
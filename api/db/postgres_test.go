@@ -1,7 +1,9 @@
 package db_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	. "github.com/globocom/huskyCI/api/db"
@@ -21,6 +23,10 @@ type FakeRetriever struct {
 	expectedNumberRows    int64
 	expectedConnectError  error
 	expectedPqArray       interface{}
+	expectedRetrievedJSON string
+	writtenQueries        []string
+	retrievedQueries      []string
+	retrievedParams       []interface{}
 }
 
 func (fR *FakeRetriever) Connect(
@@ -36,6 +42,8 @@ func (fR *FakeRetriever) Connect(
 
 func (fR *FakeRetriever) RetrieveFromDB(
 	query string, response interface{}, arrayColumns []string, params ...interface{}) error {
+	fR.retrievedQueries = append(fR.retrievedQueries, query)
+	fR.retrievedParams = params
 	if fR.expectedRetrieveError == nil {
 		switch r := response.(type) {
 		case *[]types.Repository:
@@ -53,12 +61,17 @@ func (fR *FakeRetriever) RetrieveFromDB(
 		case *[]types.DBToken:
 			// newV := response.(*[]types.DBToken)
 			(*r) = append((*r), fR.expectedDBToken)
+		default:
+			if fR.expectedRetrievedJSON != "" {
+				return json.Unmarshal([]byte(fR.expectedRetrievedJSON), response)
+			}
 		}
 	}
 	return fR.expectedRetrieveError
 }
 
 func (fR *FakeRetriever) WriteInDB(query string, args ...interface{}) (int64, error) {
+	fR.writtenQueries = append(fR.writtenQueries, query)
 	return fR.expectedNumberRows, fR.expectedWriteError
 }
 
@@ -137,6 +150,49 @@ var _ = Describe("Postgres", func() {
 		})
 	})
 
+	Describe("Migrate", func() {
+		Context("When no migration was applied yet", func() {
+			It("Should apply every migration in order.", func() {
+				fakeRetriever := FakeRetriever{expectedRetrieveError: errors.New("No data found")}
+				postgres := PostgresRequests{DataRetriever: &fakeRetriever}
+				Expect(postgres.Migrate()).To(Succeed())
+				Expect(fakeRetriever.writtenQueries).To(HaveLen(len(PostgresMigrations) + 1))
+				Expect(fakeRetriever.writtenQueries[0]).To(ContainSubstring(`CREATE TABLE IF NOT EXISTS "schemaMigration"`))
+				for i, migration := range PostgresMigrations {
+					Expect(fakeRetriever.writtenQueries[i+1]).To(HavePrefix(migration.Statement))
+					Expect(fakeRetriever.writtenQueries[i+1]).To(ContainSubstring(fmt.Sprintf("VALUES (%d, now())", migration.Version)))
+				}
+			})
+		})
+		Context("When some migrations were already applied", func() {
+			It("Should apply only the missing ones.", func() {
+				fakeRetriever := FakeRetriever{expectedRetrievedJSON: `[{"version": 1}]`}
+				postgres := PostgresRequests{DataRetriever: &fakeRetriever}
+				Expect(postgres.Migrate()).To(Succeed())
+				Expect(fakeRetriever.writtenQueries).To(HaveLen(len(PostgresMigrations)))
+				Expect(fakeRetriever.writtenQueries[1]).To(ContainSubstring("VALUES (2, now())"))
+			})
+		})
+		Context("When the applied migrations can not be read", func() {
+			It("Should return the same error", func() {
+				fakeRetriever := FakeRetriever{expectedRetrieveError: errors.New("connection refused")}
+				postgres := PostgresRequests{DataRetriever: &fakeRetriever}
+				Expect(postgres.Migrate()).To(MatchError("connection refused"))
+				Expect(fakeRetriever.writtenQueries).To(HaveLen(1))
+			})
+		})
+		Context("When the schema can not be written", func() {
+			It("Should return the same error", func() {
+				fakeRetriever := FakeRetriever{
+					expectedRetrieveError: errors.New("No data found"),
+					expectedWriteError:    errors.New("permission denied"),
+				}
+				postgres := PostgresRequests{DataRetriever: &fakeRetriever}
+				Expect(postgres.Migrate()).To(MatchError("permission denied"))
+			})
+		})
+	})
+
 	Describe("FindOneDBRepository", func() {
 		Context("When RetrieveFromDB returns an error", func() {
 			It("Should return an empty Repository with the same error", func() {
@@ -241,6 +297,97 @@ var _ = Describe("Postgres", func() {
 				Expect(err).To(BeNil())
 			})
 		})
+		Context("When the query has an empty string value", func() {
+			It("Should match NULL values as well", func() {
+				fakeRetriever := FakeRetriever{
+					expectedAnalysis: types.Analysis{RID: "teste"},
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				_, err := postgres.FindOneDBAnalysis(
+					map[string]interface{}{"repositoryURL": "teste", "repositoryBranch": "master", "repositorySubPath": ""})
+				Expect(err).To(BeNil())
+				Expect(fakeRetriever.retrievedQueries).To(Equal([]string{
+					`SELECT * FROM "analysis" WHERE "repositoryBranch" = $1 AND COALESCE("repositorySubPath", '') = $2 AND "repositoryURL" = $3`,
+				}))
+				Expect(fakeRetriever.retrievedParams).To(Equal([]interface{}{"master", "", "teste"}))
+			})
+		})
+	})
+	Describe("FindLatestDBAnalysis", func() {
+		finishedAfter := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		Context("When RetrieveFromDB returns an error", func() {
+			It("Should return an empty Analysis with the same error", func() {
+				fakeRetriever := FakeRetriever{
+					expectedRetrieveError: errors.New("No data found"),
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				analysis, err := postgres.FindLatestDBAnalysis(
+					map[string]interface{}{"repositoryCommit": "4f53cda"}, finishedAfter)
+				Expect(analysis).To(Equal(types.Analysis{}))
+				Expect(err).To(Equal(fakeRetriever.expectedRetrieveError))
+			})
+		})
+		Context("When RetrieveFromDB returns the valid Analysis struct", func() {
+			It("Should query the latest analysis finished after the given time", func() {
+				fakeRetriever := FakeRetriever{
+					expectedRetrieveError: nil,
+					expectedAnalysis: types.Analysis{
+						RID:    "teste",
+						URL:    "teste",
+						Commit: "4f53cda",
+					},
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				analysis, err := postgres.FindLatestDBAnalysis(
+					map[string]interface{}{"repositoryURL": "teste", "repositoryCommit": "4f53cda"}, finishedAfter)
+				Expect(analysis).To(Equal(fakeRetriever.expectedAnalysis))
+				Expect(err).To(BeNil())
+				Expect(fakeRetriever.retrievedQueries).To(Equal([]string{
+					`SELECT * FROM "analysis" WHERE "finishedAt" > $1 AND COALESCE("repositoryCommit", '') = $2 AND COALESCE("repositoryURL", '') = $3 ORDER BY "finishedAt" DESC LIMIT 1`,
+				}))
+				Expect(fakeRetriever.retrievedParams).To(Equal([]interface{}{finishedAfter, "4f53cda", "teste"}))
+			})
+		})
+	})
+	Describe("FindLastDBAnalysisSummaries", func() {
+		startedAfter := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		Context("When RetrieveFromDB returns the valid Analysis struct", func() {
+			It("Should query only the summary fields of the last analyses started after the given time", func() {
+				fakeRetriever := FakeRetriever{
+					expectedAnalysis: types.Analysis{RID: "teste", Status: "finished"},
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				analyses, err := postgres.FindLastDBAnalysisSummaries(
+					map[string]interface{}{"repositoryURL": "teste"}, startedAfter, 5)
+				Expect(analyses).To(Equal([]types.Analysis{fakeRetriever.expectedAnalysis}))
+				Expect(err).To(BeNil())
+				Expect(fakeRetriever.retrievedQueries).To(Equal([]string{
+					`SELECT "RID", "repositoryBranch", "repositoryCommit", "tags", "status", "result", "startedAt", "finishedAt", "summary" FROM "analysis" WHERE "startedAt" > $1 AND "repositoryURL" = $2 ORDER BY "startedAt" DESC LIMIT $3`,
+				}))
+				Expect(fakeRetriever.retrievedParams).To(Equal([]interface{}{startedAfter, "teste", 5}))
+			})
+			It("Should match the tags of the query by their values", func() {
+				fakeRetriever := FakeRetriever{}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				_, err := postgres.FindLastDBAnalysisSummaries(
+					map[string]interface{}{"repositoryURL": "teste", "tags.team": "security"}, startedAfter, 5)
+				Expect(err).To(BeNil())
+				Expect(fakeRetriever.retrievedQueries).To(Equal([]string{
+					`SELECT "RID", "repositoryBranch", "repositoryCommit", "tags", "status", "result", "startedAt", "finishedAt", "summary" FROM "analysis" WHERE "startedAt" > $1 AND "repositoryURL" = $2 AND "tags"->>'team' = $3 ORDER BY "startedAt" DESC LIMIT $4`,
+				}))
+				Expect(fakeRetriever.retrievedParams).To(Equal([]interface{}{startedAfter, "teste", "security", 5}))
+			})
+		})
 	})
 	Describe("FindOneDBUser", func() {
 		Context("When RetrieveFromDB returns an error", func() {
@@ -1063,6 +1210,87 @@ var _ = Describe("Postgres", func() {
 			})
 		})
 	})
+	Describe("FindAllDBAccessToken", func() {
+		Context("When RetrieveFromDB returns an error", func() {
+			It("Should return an empty array and the same error", func() {
+				fakeRetriever := FakeRetriever{
+					expectedRetrieveError: errors.New("Failed to retrieve data"),
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				accessTokens, err := postgres.FindAllDBAccessToken(map[string]interface{}{})
+				Expect(accessTokens).To(Equal([]types.DBToken{}))
+				Expect(err).To(Equal(fakeRetriever.expectedRetrieveError))
+			})
+		})
+		Context("When RetrieveFromDB returns a nil error", func() {
+			It("Should return every access token and a nil error", func() {
+				fakeRetriever := FakeRetriever{
+					expectedDBToken: accessToken,
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				accessTokens, err := postgres.FindAllDBAccessToken(map[string]interface{}{})
+				Expect(accessTokens).To(Equal([]types.DBToken{accessToken}))
+				Expect(err).To(BeNil())
+				Expect(fakeRetriever.retrievedQueries).To(Equal([]string{`SELECT * FROM "accessToken"`}))
+			})
+		})
+	})
+	Describe("DeleteAllDBRepository", func() {
+		Context("When an empty mapParams is passed as argument", func() {
+			It("Should return the expected error for empty mapParams", func() {
+				postgres := PostgresRequests{}
+				Expect(postgres.DeleteAllDBRepository(map[string]interface{}{})).To(
+					Equal(errors.New("Empty fields to search")))
+			})
+		})
+		Context("When WriteInDB returns an error", func() {
+			It("Should return the same error", func() {
+				fakeRetriever := FakeRetriever{
+					expectedWriteError: errors.New("Failed to write in DB"),
+				}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				Expect(postgres.DeleteAllDBRepository(validParams)).To(
+					Equal(fakeRetriever.expectedWriteError))
+			})
+		})
+		Context("When WriteInDB returns a nil error", func() {
+			It("Should return a nil error", func() {
+				fakeRetriever := FakeRetriever{}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				Expect(postgres.DeleteAllDBRepository(validParams)).To(BeNil())
+				Expect(fakeRetriever.writtenQueries).To(HaveLen(1))
+				Expect(fakeRetriever.writtenQueries[0]).To(HavePrefix("DELETE FROM repository WHERE"))
+			})
+		})
+	})
+	Describe("DeleteAllDBAccessToken", func() {
+		Context("When an empty mapParams is passed as argument", func() {
+			It("Should return the expected error for empty mapParams", func() {
+				postgres := PostgresRequests{}
+				Expect(postgres.DeleteAllDBAccessToken(map[string]interface{}{})).To(
+					Equal(errors.New("Empty fields to search")))
+			})
+		})
+		Context("When WriteInDB returns a nil error", func() {
+			It("Should return a nil error", func() {
+				fakeRetriever := FakeRetriever{}
+				postgres := PostgresRequests{
+					DataRetriever: &fakeRetriever,
+				}
+				Expect(postgres.DeleteAllDBAccessToken(validParams)).To(BeNil())
+				Expect(fakeRetriever.writtenQueries).To(HaveLen(1))
+				Expect(fakeRetriever.writtenQueries[0]).To(HavePrefix(`DELETE FROM "accessToken" WHERE`))
+			})
+		})
+	})
 	Describe("UpsertOneDBSecurityTest", func() {
 		Context("When an empty SecurityTest is passed", func() {
 			It("Should return the expected error and a nil interface", func() {
@@ -22,23 +22,36 @@ type Requests interface {
 	FindAllDBRepository(mapParams map[string]interface{}) ([]types.Repository, error)
 	FindAllDBSecurityTest(mapParams map[string]interface{}) ([]types.SecurityTest, error)
 	FindAllDBAnalysis(mapParams map[string]interface{}) ([]types.Analysis, error)
+	FindAllDBAccessToken(mapParams map[string]interface{}) ([]types.DBToken, error)
+	FindLatestDBAnalysis(mapParams map[string]interface{}, finishedAfter time.Time) (types.Analysis, error)
+	FindLastDBAnalysisSummaries(mapParams map[string]interface{}, startedAfter time.Time, limit int) ([]types.Analysis, error)
+	FindOneDBSecurityTestRollout(mapParams map[string]interface{}) (types.SecurityTestRollout, error)
 	InsertDBRepository(repository types.Repository) error
 	InsertDBSecurityTest(securityTest types.SecurityTest) error
 	InsertDBAnalysis(analysis types.Analysis) error
 	InsertDBUser(user types.User) error
 	InsertDBAccessToken(accessToken types.DBToken) error
+	InsertDBSecurityTestRollout(rollout types.SecurityTestRollout) error
+	FindAllDBAcceptedRisk(mapParams map[string]interface{}) ([]types.AcceptedRisk, error)
+	UpsertOneDBAcceptedRisk(mapParams map[string]interface{}, acceptedRisk types.AcceptedRisk) error
 	UpdateOneDBRepository(mapParams, updateQuery map[string]interface{}) error
 	UpsertOneDBSecurityTest(mapParams map[string]interface{}, updatedSecurityTest types.SecurityTest) (interface{}, error)
 	UpdateOneDBAnalysis(mapParams map[string]interface{}, updatedAnalysis map[string]interface{}) error
 	UpdateOneDBUser(mapParams map[string]interface{}, updatedUser types.User) error
 	UpdateOneDBAnalysisContainer(mapParams, updateQuery map[string]interface{}) error
 	UpdateOneDBAccessToken(mapParams map[string]interface{}, updatedAccessToken types.DBToken) error
+	DeleteAllDBRepository(mapParams map[string]interface{}) error
+	DeleteAllDBAccessToken(mapParams map[string]interface{}) error
 	GetMetricByType(metricType string, queryStringParams map[string][]string) (interface{}, error)
 }
 
 // MongoRequests implements Requests
 // for Mongo, a non-relational DB.
-type MongoRequests struct{}
+type MongoRequests struct {
+	// SkipIndexes leaves the creation of the
+	// indexes to the DBAs managing the database.
+	SkipIndexes bool
+}
 
 // JSON interface defines the functions that will threat data
 // to be transformed to JSON or a JSON that will be mapped in
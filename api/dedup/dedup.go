@@ -0,0 +1,88 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dedup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// Key returns the key used to tell whether two vulnerabilities refer to the same issue.
+// Vulnerable dependencies share a key when they are about the same package and CVE. Any other
+// vulnerability shares a key when it is about the same file, line, rule and commit, the rule being its
+// Type or, when a securityTest sets none, its Title. Vulnerabilities without a line, such as leaks found
+// by gitleaks, must have the same code as well. An empty key is returned for vulnerabilities that have
+// neither a CVE nor a file, such as securityTest warnings, as they are never duplicates.
+func Key(vuln types.HuskyCIVulnerability) string {
+	if vuln.CVE != "" {
+		return fmt.Sprintf("dependency|%s|%s", strings.ToLower(vuln.Code), strings.ToUpper(vuln.CVE))
+	}
+	if vuln.File == "" {
+		return ""
+	}
+	rule := vuln.Type
+	if rule == "" {
+		rule = vuln.Title
+	}
+	code := ""
+	if vuln.Line == "" {
+		code = vuln.Code
+	}
+	return fmt.Sprintf("file|%s|%s|%s|%s|%q", vuln.File, vuln.Line, rule, vuln.CommitHash, code)
+}
+
+// Results merges the vulnerabilities of results that share a Key into a single one,
+// kept in the output of the first securityTest that found it with the highest severity. The merged
+// vulnerability lists every securityTool that found it in SecurityTools.
+func Results(results *types.HuskyCIResults) {
+	type position struct {
+		output, bucket, index int
+	}
+	outputs := util.SecurityTestOutputs(results)
+	buckets := func(output *types.HuskyCISecurityTestOutput) []*[]types.HuskyCIVulnerability {
+		return []*[]types.HuskyCIVulnerability{&output.HighVulns, &output.MediumVulns, &output.LowVulns}
+	}
+
+	kept := make(map[string]position)
+	tools := make(map[string][]string)
+	for bucket := 0; bucket < 3; bucket++ {
+		for o, output := range outputs {
+			for i, vuln := range *buckets(output)[bucket] {
+				key := Key(vuln)
+				if key == "" {
+					continue
+				}
+				if _, ok := kept[key]; !ok {
+					kept[key] = position{o, bucket, i}
+				}
+				if !util.SliceContains(tools[key], vuln.SecurityTool) {
+					tools[key] = append(tools[key], vuln.SecurityTool)
+				}
+			}
+		}
+	}
+
+	for o, output := range outputs {
+		for bucket, vulns := range buckets(output) {
+			var uniqueVulns []types.HuskyCIVulnerability
+			for i, vuln := range *vulns {
+				key := Key(vuln)
+				if key != "" {
+					if kept[key] != (position{o, bucket, i}) {
+						continue
+					}
+					if len(tools[key]) > 1 {
+						vuln.SecurityTools = tools[key]
+					}
+				}
+				uniqueVulns = append(uniqueVulns, vuln)
+			}
+			*vulns = uniqueVulns
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dedup_test
+
+import (
+	"github.com/globocom/huskyCI/api/dedup"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dedup", func() {
+
+	Describe("Key", func() {
+		Context("When vulnerabilities are about the same package and CVE", func() {
+			It("Should return the same key.", func() {
+				npmVuln := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", Code: "lodash", CVE: "CVE-2019-10744", Title: "Prototype Pollution"}
+				yarnVuln := types.HuskyCIVulnerability{SecurityTool: "YarnAudit", Code: "Lodash", CVE: "cve-2019-10744", Severity: "medium"}
+				Expect(dedup.Key(npmVuln)).To(Equal(dedup.Key(yarnVuln)))
+			})
+		})
+		Context("When vulnerabilities are about the same package and different CVEs", func() {
+			It("Should return different keys.", func() {
+				vuln := types.HuskyCIVulnerability{Code: "lodash", CVE: "CVE-2019-10744"}
+				otherVuln := types.HuskyCIVulnerability{Code: "lodash", CVE: "CVE-2018-16487"}
+				Expect(dedup.Key(vuln)).NotTo(Equal(dedup.Key(otherVuln)))
+			})
+		})
+		Context("When vulnerabilities are about the same file, line and rule", func() {
+			It("Should return the same key.", func() {
+				vuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", File: "main.go", Line: "10", Type: "G101"}
+				otherVuln := types.HuskyCIVulnerability{SecurityTool: "Other", File: "main.go", Line: "10", Type: "G101", Confidence: "HIGH"}
+				Expect(dedup.Key(vuln)).To(Equal(dedup.Key(otherVuln)))
+			})
+			It("Should use the title as the rule when there is no type.", func() {
+				vuln := types.HuskyCIVulnerability{File: "main.py", Line: "3", Title: "Use of assert detected."}
+				otherVuln := types.HuskyCIVulnerability{File: "main.py", Line: "3", Title: "Possible hardcoded password."}
+				Expect(dedup.Key(vuln)).NotTo(Equal(dedup.Key(otherVuln)))
+			})
+		})
+		Context("When vulnerabilities are about the same rule in different lines", func() {
+			It("Should return different keys.", func() {
+				vuln := types.HuskyCIVulnerability{File: "main.go", Line: "10", Type: "G101"}
+				otherVuln := types.HuskyCIVulnerability{File: "main.go", Line: "20", Type: "G101"}
+				Expect(dedup.Key(vuln)).NotTo(Equal(dedup.Key(otherVuln)))
+			})
+		})
+		Context("When vulnerabilities without a line are about the same rule in the same file", func() {
+			It("Should return different keys for different code or commits.", func() {
+				leak := types.HuskyCIVulnerability{SecurityTool: "GitLeaks", File: "config.yaml", Type: "AWS Secret Key", Code: "key: AKIA1111", CommitHash: "4f53cda"}
+				otherLeak := types.HuskyCIVulnerability{SecurityTool: "GitLeaks", File: "config.yaml", Type: "AWS Secret Key", Code: "key: AKIA2222", CommitHash: "4f53cda"}
+				sameLeakInOtherCommit := types.HuskyCIVulnerability{SecurityTool: "GitLeaks", File: "config.yaml", Type: "AWS Secret Key", Code: "key: AKIA1111", CommitHash: "9b2e1f0"}
+				Expect(dedup.Key(leak)).NotTo(Equal(dedup.Key(otherLeak)))
+				Expect(dedup.Key(leak)).NotTo(Equal(dedup.Key(sameLeakInOtherCommit)))
+			})
+		})
+		Context("When a vulnerability has neither a CVE nor a file", func() {
+			It("Should return an empty key.", func() {
+				vuln := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", Title: "No package-lock.json found."}
+				Expect(dedup.Key(vuln)).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Results", func() {
+		npmVuln := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", Severity: "medium", Code: "lodash", CVE: "CVE-2019-10744"}
+		yarnVuln := types.HuskyCIVulnerability{SecurityTool: "YarnAudit", Severity: "high", Code: "lodash", CVE: "CVE-2019-10744"}
+		npmWarning := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", Severity: "low", Title: "No package-lock.json found."}
+		yarnWarning := types.HuskyCIVulnerability{SecurityTool: "YarnAudit", Severity: "low", Title: "No package-lock.json found."}
+		gosecVuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "10", Details: "G101"}
+
+		rawResults := types.HuskyCIResults{}
+		rawResults.JavaScriptResults.HuskyCINpmAuditOutput.MediumVulns = []types.HuskyCIVulnerability{npmVuln}
+		rawResults.JavaScriptResults.HuskyCINpmAuditOutput.LowVulns = []types.HuskyCIVulnerability{npmWarning}
+		rawResults.JavaScriptResults.HuskyCIYarnAuditOutput.HighVulns = []types.HuskyCIVulnerability{yarnVuln}
+		rawResults.JavaScriptResults.HuskyCIYarnAuditOutput.LowVulns = []types.HuskyCIVulnerability{yarnWarning}
+		rawResults.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{gosecVuln}
+
+		Context("When securityTests found the same vulnerability", func() {
+			results := rawResults
+			dedup.Results(&results)
+
+			It("Should keep the one with the highest severity listing every securityTool that found it.", func() {
+				mergedVuln := yarnVuln
+				mergedVuln.SecurityTools = []string{"YarnAudit", "NpmAudit"}
+				Expect(results.JavaScriptResults.HuskyCIYarnAuditOutput.HighVulns).To(Equal([]types.HuskyCIVulnerability{mergedVuln}))
+				Expect(results.JavaScriptResults.HuskyCINpmAuditOutput.MediumVulns).To(BeEmpty())
+			})
+			It("Should not merge vulnerabilities without a key.", func() {
+				Expect(results.JavaScriptResults.HuskyCINpmAuditOutput.LowVulns).To(Equal([]types.HuskyCIVulnerability{npmWarning}))
+				Expect(results.JavaScriptResults.HuskyCIYarnAuditOutput.LowVulns).To(Equal([]types.HuskyCIVulnerability{yarnWarning}))
+			})
+			It("Should keep unique vulnerabilities untouched.", func() {
+				Expect(results.GoResults.HuskyCIGosecOutput.HighVulns).To(Equal([]types.HuskyCIVulnerability{gosecVuln}))
+			})
+		})
+
+		Context("When gitleaks found two leaks of the same rule in one file", func() {
+			It("Should keep both.", func() {
+				leaks := []types.HuskyCIVulnerability{
+					{SecurityTool: "GitLeaks", Severity: "MEDIUM", File: "config.yaml", Type: "AWS Secret Key", Code: "key: AKIA1111", CommitHash: "4f53cda"},
+					{SecurityTool: "GitLeaks", Severity: "MEDIUM", File: "config.yaml", Type: "AWS Secret Key", Code: "secret: AKIA2222", CommitHash: "4f53cda"},
+				}
+				results := types.HuskyCIResults{}
+				results.GenericResults.HuskyCIGitleaksOutput.MediumVulns = leaks
+				dedup.Results(&results)
+				Expect(results.GenericResults.HuskyCIGitleaksOutput.MediumVulns).To(Equal(leaks))
+			})
+		})
+	})
+})
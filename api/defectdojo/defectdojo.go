@@ -0,0 +1,128 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defectdojo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Client uploads reports to the import-scan API of a DefectDojo instance, authenticating with an API v2 key.
+type Client struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// NewClient returns a new Client of the DefectDojo instance at URL.
+func NewClient(URL, APIKey string) Client {
+	return Client{URL: URL, APIKey: APIKey, Client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// Upload uploads report, in the generic findings format, as the test titled testTitle of the engagement
+// engagementID and returns the ID of the test. When the engagement already has that test, the report is
+// re-imported into it, so that findings already known are updated instead of duplicated and the ones no
+// longer reported are closed.
+func (c Client) Upload(engagementID int, testTitle string, report []byte) (int, error) {
+	testID, err := c.findTest(engagementID, testTitle)
+	if err != nil {
+		return 0, err
+	}
+	if testID != 0 {
+		return c.importScan("/api/v2/reimport-scan/", map[string]string{
+			"scan_type":          ScanType,
+			"test":               strconv.Itoa(testID),
+			"close_old_findings": "true",
+		}, report)
+	}
+	return c.importScan("/api/v2/import-scan/", map[string]string{
+		"scan_type":  ScanType,
+		"engagement": strconv.Itoa(engagementID),
+		"test_title": testTitle,
+		"active":     "true",
+		"verified":   "false",
+	}, report)
+}
+
+// findTest returns the ID of the test titled testTitle of the engagement engagementID, or 0 if there is none.
+func (c Client) findTest(engagementID int, testTitle string) (int, error) {
+	query := url.Values{}
+	query.Set("engagement", strconv.Itoa(engagementID))
+	query.Set("title", testTitle)
+	req, err := http.NewRequest(http.MethodGet, c.URL+"/api/v2/tests/?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	tests := struct {
+		Results []struct {
+			ID    int    `json:"id"`
+			Title string `json:"title"`
+		} `json:"results"`
+	}{}
+	if err := c.do(req, &tests); err != nil {
+		return 0, err
+	}
+	for _, test := range tests.Results {
+		if test.Title == testTitle {
+			return test.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+func (c Client) importScan(path string, fields map[string]string, report []byte) (int, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return 0, err
+		}
+	}
+	file, err := form.CreateFormFile("file", "huskyci.json")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Write(report); err != nil {
+		return 0, err
+	}
+	if err := form.Close(); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	imported := struct {
+		Test int `json:"test"`
+	}{}
+	if err := c.do(req, &imported); err != nil {
+		return 0, err
+	}
+	return imported.Test, nil
+}
+
+func (c Client) do(req *http.Request, reply interface{}) error {
+	req.Header.Set("Authorization", "Token "+c.APIKey)
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("defectdojo returned status code %d for %s: %s", resp.StatusCode, req.URL.Path, bytes.TrimSpace(message))
+	}
+	return json.NewDecoder(resp.Body).Decode(reply)
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defectdojo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/globocom/huskyCI/api/defectdojo"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeDefectDojo mocks the tests and import-scan APIs of DefectDojo, keeping the findings of each test by unique ID.
+type fakeDefectDojo struct {
+	mutex      sync.Mutex
+	tests      map[int]string
+	findings   map[int]map[string]string
+	engagement map[int]int
+	calls      []string
+	apiKeys    []string
+}
+
+func newFakeDefectDojo() *fakeDefectDojo {
+	return &fakeDefectDojo{tests: map[int]string{}, findings: map[int]map[string]string{}, engagement: map[int]int{}}
+}
+
+func (f *fakeDefectDojo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	f.apiKeys = append(f.apiKeys, r.Header.Get("Authorization"))
+	switch r.URL.Path {
+	case "/api/v2/tests/":
+		results := []map[string]interface{}{}
+		for id, title := range f.tests {
+			if fmt.Sprint(f.engagement[id]) == r.URL.Query().Get("engagement") && title == r.URL.Query().Get("title") {
+				results = append(results, map[string]interface{}{"id": id, "title": title})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"count": len(results), "results": results})
+	case "/api/v2/import-scan/", "/api/v2/reimport-scan/":
+		if r.FormValue("scan_type") != defectdojo.ScanType {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"scan_type": ["invalid"]}`)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		Expect(err).To(BeNil())
+		report := defectdojo.GenericReport{}
+		Expect(json.NewDecoder(file).Decode(&report)).To(Succeed())
+
+		var testID int
+		if r.URL.Path == "/api/v2/import-scan/" {
+			testID = len(f.tests) + 1
+			engagementID := 0
+			fmt.Sscan(r.FormValue("engagement"), &engagementID)
+			f.tests[testID] = r.FormValue("test_title")
+			f.engagement[testID] = engagementID
+			f.findings[testID] = map[string]string{}
+		} else {
+			fmt.Sscan(r.FormValue("test"), &testID)
+			if r.FormValue("close_old_findings") == "true" {
+				f.findings[testID] = map[string]string{}
+			}
+		}
+		for _, finding := range report.Findings {
+			f.findings[testID][finding.UniqueIDFromTool] = finding.Title
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"test": testID, "scan_type": defectdojo.ScanType})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+var _ = Describe("DefectDojo", func() {
+	results := func(fingerprints ...string) types.HuskyCIResults {
+		results := types.HuskyCIResults{}
+		for _, fingerprint := range fingerprints {
+			results.GoResults.HuskyCIGosecOutput.HighVulns = append(results.GoResults.HuskyCIGosecOutput.HighVulns, types.HuskyCIVulnerability{
+				SecurityTool: "GoSec",
+				Severity:     "HIGH",
+				Title:        "Finding " + fingerprint,
+				Details:      "Details of " + fingerprint,
+				File:         "main.go",
+				Line:         "10",
+				Fingerprint:  fingerprint,
+			})
+		}
+		return results
+	}
+
+	Describe("GenericFindings", func() {
+		It("Should return the reported vulnerabilities in the generic findings format.", func() {
+			huskyCIResults := results("f1")
+			huskyCIResults.JavaScriptResults.HuskyCINpmAuditOutput.LowVulns = []types.HuskyCIVulnerability{
+				{SecurityTool: "NpmAudit", Severity: "low", Details: "Prototype Pollution", Code: "minimist", Version: "0.0.8", CVE: "CVE-2020-7598"},
+			}
+			huskyCIResults.GoResults.HuskyCIGosecOutput.NoSecVulns = []types.HuskyCIVulnerability{{SecurityTool: "GoSec", Severity: "HIGH"}}
+			report, err := defectdojo.GenericFindings(huskyCIResults)
+			Expect(err).To(BeNil())
+
+			parsed := defectdojo.GenericReport{}
+			Expect(json.Unmarshal(report, &parsed)).To(Succeed())
+			Expect(parsed.Findings).To(HaveLen(2))
+			Expect(parsed.Findings).To(ContainElement(defectdojo.GenericFinding{
+				Title:            "Finding f1",
+				Description:      "Details of f1\n\nFound by GoSec.",
+				Severity:         "High",
+				FilePath:         "main.go",
+				Line:             10,
+				UniqueIDFromTool: "f1",
+				StaticFinding:    true,
+			}))
+			Expect(parsed.Findings).To(ContainElement(defectdojo.GenericFinding{
+				Title:            "Prototype Pollution",
+				Description:      "Prototype Pollution\n\nFound by NpmAudit.",
+				Severity:         "Low",
+				CVE:              "CVE-2020-7598",
+				ComponentName:    "minimist",
+				ComponentVersion: "0.0.8",
+				StaticFinding:    true,
+			}))
+		})
+
+		It("Should return an empty list of findings when there is no vulnerability.", func() {
+			report, err := defectdojo.GenericFindings(types.HuskyCIResults{})
+			Expect(err).To(BeNil())
+			Expect(string(report)).To(Equal(`{"findings":[]}`))
+		})
+	})
+
+	Describe("Upload", func() {
+		var (
+			fake   *fakeDefectDojo
+			server *httptest.Server
+			client defectdojo.Client
+		)
+		BeforeEach(func() {
+			fake = newFakeDefectDojo()
+			server = httptest.NewServer(fake)
+			client = defectdojo.NewClient(server.URL, "s3cr3t")
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+
+		Context("When the engagement has no test with the title", func() {
+			It("Should import the report as a new test.", func() {
+				report, _ := defectdojo.GenericFindings(results("f1", "f2"))
+				testID, err := client.Upload(12, "huskyCI master", report)
+				Expect(err).To(BeNil())
+				Expect(testID).To(Equal(1))
+				Expect(fake.calls).To(Equal([]string{"GET /api/v2/tests/", "POST /api/v2/import-scan/"}))
+				Expect(fake.apiKeys).To(ConsistOf("Token s3cr3t", "Token s3cr3t"))
+				Expect(fake.tests).To(Equal(map[int]string{1: "huskyCI master"}))
+				Expect(fake.engagement).To(Equal(map[int]int{1: 12}))
+				Expect(fake.findings[1]).To(HaveLen(2))
+			})
+		})
+
+		Context("When the engagement already has a test with the title", func() {
+			It("Should re-import the report into it without duplicating findings.", func() {
+				report, _ := defectdojo.GenericFindings(results("f1", "f2"))
+				firstID, err := client.Upload(12, "huskyCI master", report)
+				Expect(err).To(BeNil())
+
+				report, _ = defectdojo.GenericFindings(results("f2", "f3"))
+				secondID, err := client.Upload(12, "huskyCI master", report)
+				Expect(err).To(BeNil())
+				Expect(secondID).To(Equal(firstID))
+				Expect(fake.calls[2:]).To(Equal([]string{"GET /api/v2/tests/", "POST /api/v2/reimport-scan/"}))
+				Expect(fake.tests).To(HaveLen(1))
+				Expect(fake.findings[firstID]).To(Equal(map[string]string{"f2": "Finding f2", "f3": "Finding f3"}))
+			})
+
+			It("Should import a new test for another engagement or title.", func() {
+				report, _ := defectdojo.GenericFindings(results("f1"))
+				firstID, _ := client.Upload(12, "huskyCI master", report)
+				otherEngagementID, err := client.Upload(7, "huskyCI master", report)
+				Expect(err).To(BeNil())
+				otherTitleID, err := client.Upload(12, "huskyCI develop", report)
+				Expect(err).To(BeNil())
+				Expect([]int{firstID, otherEngagementID, otherTitleID}).To(Equal([]int{1, 2, 3}))
+			})
+		})
+
+		Context("When DefectDojo replies with an error", func() {
+			It("Should return it.", func() {
+				server.Close()
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+					fmt.Fprint(w, `{"detail": "Invalid token."}`)
+				}))
+				client = defectdojo.NewClient(server.URL, "wrong")
+				_, err := client.Upload(12, "huskyCI master", []byte(`{"findings":[]}`))
+				Expect(err).To(MatchError(`defectdojo returned status code 403 for /api/v2/tests/: {"detail": "Invalid token."}`))
+			})
+		})
+	})
+})
@@ -0,0 +1,92 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defectdojo
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// ScanType is the DefectDojo parser of the reports returned by GenericFindings.
+const ScanType = "Generic Findings Import"
+
+// GenericReport is the struct that holds the findings of a report in the DefectDojo generic findings format.
+type GenericReport struct {
+	Findings []GenericFinding `json:"findings"`
+}
+
+// GenericFinding is the struct that holds a vulnerability in the DefectDojo generic findings format.
+type GenericFinding struct {
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	Severity         string `json:"severity"`
+	FilePath         string `json:"file_path,omitempty"`
+	Line             int    `json:"line,omitempty"`
+	CVE              string `json:"cve,omitempty"`
+	ComponentName    string `json:"component_name,omitempty"`
+	ComponentVersion string `json:"component_version,omitempty"`
+	VulnIDFromTool   string `json:"vuln_id_from_tool,omitempty"`
+	UniqueIDFromTool string `json:"unique_id_from_tool,omitempty"`
+	StaticFinding    bool   `json:"static_finding"`
+}
+
+// genericSeverities maps huskyCI severities into DefectDojo ones.
+var genericSeverities = map[string]string{
+	"high":   "High",
+	"medium": "Medium",
+	"low":    "Low",
+}
+
+// GenericFindings returns the vulnerabilities of results, except the nosec ones, as a report in the DefectDojo
+// generic findings format. Each finding carries the fingerprint of its vulnerability as its unique ID, so
+// that DefectDojo matches the findings of a re-imported report with the ones it already has.
+func GenericFindings(results types.HuskyCIResults) ([]byte, error) {
+	report := GenericReport{Findings: []GenericFinding{}}
+	for _, vuln := range util.ReportedVulnerabilities(results) {
+		finding := GenericFinding{
+			Title:            vuln.Title,
+			Description:      vuln.Details,
+			Severity:         genericSeverities[strings.ToLower(vuln.Severity)],
+			FilePath:         vuln.File,
+			CVE:              vuln.CVE,
+			VulnIDFromTool:   vuln.Type,
+			UniqueIDFromTool: vuln.Fingerprint,
+			StaticFinding:    true,
+		}
+		if line, err := strconv.Atoi(vuln.Line); err == nil && line > 0 {
+			finding.Line = line
+		}
+		if vuln.CVE != "" || vuln.Version != "" {
+			finding.ComponentName = vuln.Code
+			finding.ComponentVersion = vuln.Version
+		}
+		if finding.Title == "" {
+			finding.Title = vuln.Details
+		}
+		if finding.Description == "" {
+			finding.Description = finding.Title
+		}
+		if finding.Severity == "" {
+			finding.Severity = "Info"
+		}
+		if tools := securityTools(vuln); tools != "" {
+			finding.Description += "\n\nFound by " + tools + "."
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	return json.Marshal(report)
+}
+
+// securityTools returns the securityTools that found vuln, which are many when it was deduplicated.
+func securityTools(vuln types.HuskyCIVulnerability) string {
+	if len(vuln.SecurityTools) > 0 {
+		return strings.Join(vuln.SecurityTools, ", ")
+	}
+	return vuln.SecurityTool
+}
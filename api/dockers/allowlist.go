@@ -0,0 +1,84 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrImageNotApproved is returned by DockerRun when the image of the container is not in the image allowlist.
+var ErrImageNotApproved = errors.New("image not approved")
+
+var (
+	imageAllowlist      []string
+	imageAllowlistMutex sync.RWMutex
+)
+
+// SetImageAllowlist sets the images DockerRun may create containers from, as accepted by ImageApproved.
+// An empty allowlist approves every image.
+func SetImageAllowlist(allowlist []string) {
+	imageAllowlistMutex.Lock()
+	defer imageAllowlistMutex.Unlock()
+	imageAllowlist = allowlist
+}
+
+func currentImageAllowlist() []string {
+	imageAllowlistMutex.RLock()
+	defer imageAllowlistMutex.RUnlock()
+	return imageAllowlist
+}
+
+// ImageApproved reports whether allowlist approves the image:tag whose registry digest is digest, empty if
+// unknown. An entry approves every tag of a repository, such as huskyci/gosec, a single tag, such as
+// huskyci/gosec:v2.3.0, or a single digest, such as huskyci/gosec@sha256:... An empty allowlist approves
+// every image.
+func ImageApproved(allowlist []string, image, tag, digest string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	repository := normalizeRepository(image)
+	for _, entry := range allowlist {
+		entryRepository, entryTag, entryDigest := splitImageReference(entry)
+		if normalizeRepository(entryRepository) != repository {
+			continue
+		}
+		switch {
+		case entryDigest != "":
+			if digest != "" && entryDigest == digest {
+				return true
+			}
+		case entryTag != "":
+			if entryTag == tag {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// splitImageReference splits a repository[:tag][@digest] reference. A colon before the last slash is the
+// port of a registry, not a tag.
+func splitImageReference(reference string) (string, string, string) {
+	repository, digest := reference, ""
+	if i := strings.Index(reference, "@"); i >= 0 {
+		repository, digest = reference[:i], reference[i+1:]
+	}
+	tag := ""
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return repository, tag, digest
+}
+
+// normalizeRepository returns repository without the Docker Hub domain and library namespace that
+// configureImagePath adds or omits, so that huskyci/gosec and docker.io/huskyci/gosec are the same.
+func normalizeRepository(repository string) string {
+	repository = strings.TrimPrefix(repository, "docker.io/")
+	return strings.TrimPrefix(repository, "library/")
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers_test
+
+import (
+	"github.com/globocom/huskyCI/api/dockers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImageApproved", func() {
+
+	digest := "sha256:8d2c7ac1f5b3c5a3e1f3c8fbfb0b8d3e1e1f5c8b1a2f9b7c4d3f4a1b2c3d4e5f"
+
+	Context("When the allowlist is empty", func() {
+		It("Should approve every image.", func() {
+			Expect(dockers.ImageApproved(nil, "huskyci/gosec", "v2.3.0", "")).To(BeTrue())
+		})
+	})
+
+	Context("When the allowlist has the repository of the image", func() {
+		It("Should approve every tag of it.", func() {
+			allowlist := []string{"huskyci/gosec"}
+			Expect(dockers.ImageApproved(allowlist, "huskyci/gosec", "v2.3.0", "")).To(BeTrue())
+			Expect(dockers.ImageApproved(allowlist, "docker.io/huskyci/gosec", "latest", "")).To(BeTrue())
+			Expect(dockers.ImageApproved(allowlist, "huskyci/gosec-fork", "latest", "")).To(BeFalse())
+		})
+	})
+
+	Context("When the allowlist has an exact tag", func() {
+		allowlist := []string{"huskyci/bandit:1.6.2", "registry.example.com:5000/huskyci/gosec:v2.3.0"}
+		It("Should approve the image with that tag.", func() {
+			Expect(dockers.ImageApproved(allowlist, "huskyci/bandit", "1.6.2", digest)).To(BeTrue())
+			Expect(dockers.ImageApproved(allowlist, "registry.example.com:5000/huskyci/gosec", "v2.3.0", "")).To(BeTrue())
+		})
+		It("Should not approve the image with any other tag.", func() {
+			Expect(dockers.ImageApproved(allowlist, "huskyci/bandit", "latest", digest)).To(BeFalse())
+			Expect(dockers.ImageApproved(allowlist, "registry.example.com:5000/huskyci/gosec", "latest", "")).To(BeFalse())
+		})
+	})
+
+	Context("When the allowlist has a digest", func() {
+		allowlist := []string{"huskyci/npmaudit@" + digest}
+		It("Should approve the image pinned to that digest, whatever its tag.", func() {
+			Expect(dockers.ImageApproved(allowlist, "huskyci/npmaudit", "latest", digest)).To(BeTrue())
+		})
+		It("Should not approve the image pinned to another digest.", func() {
+			Expect(dockers.ImageApproved(allowlist, "huskyci/npmaudit", "latest", "sha256:0000")).To(BeFalse())
+		})
+		It("Should not approve the image whose digest is unknown.", func() {
+			Expect(dockers.ImageApproved(allowlist, "huskyci/npmaudit", "latest", "")).To(BeFalse())
+		})
+	})
+})
@@ -6,9 +6,12 @@ package dockers
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	dockerTypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -23,6 +26,7 @@ import (
 type Docker struct {
 	CID    string `json:"Id"`
 	client *client.Client
+	logger log.Entry
 }
 
 // CreateContainerPayload is a struct that represents all data needed to create a container.
@@ -75,38 +79,88 @@ func NewDocker() (*Docker, error) {
 	return docker, nil
 }
 
-// CreateContainer creates a new container and return its CID and an error
-func (d Docker) CreateContainer(image, cmd string) (string, error) {
+// CreateContainer creates a new container labeled as a scan container of the analysis RID and return its CID
+// and an error
+func (d Docker) CreateContainer(image, cmd string, env []string, RID string) (string, error) {
 	ctx := goContext.Background()
 	resp, err := d.client.ContainerCreate(ctx, &container.Config{
-		Image: image,
-		Tty:   true,
-		Cmd:   []string{"/bin/sh", "-c", cmd},
+		Image:  image,
+		Tty:    true,
+		Cmd:    []string{"/bin/sh", "-c", cmd},
+		Env:    env,
+		Labels: scanContainerLabels(RID),
 	}, nil, nil, "")
 
 	if err != nil {
-		log.Error("CreateContainer", logInfoAPI, 3005, err)
+		d.logger.Error("CreateContainer", logInfoAPI, 3005, err)
 		return "", err
 	}
 	return resp.ID, nil
 }
 
+// CopyToContainer extracts content, a tar archive, into the root directory of a container that was not started yet.
+func (d Docker) CopyToContainer(content io.Reader) error {
+	ctx := goContext.Background()
+	return d.client.CopyToContainer(ctx, d.CID, "/", content, dockerTypes.CopyToContainerOptions{})
+}
+
+// WorkingDir returns the directory the command of a container runs in, the root directory when its image
+// sets none.
+func (d Docker) WorkingDir() (string, error) {
+	ctx := goContext.Background()
+	info, err := d.client.ContainerInspect(ctx, d.CID)
+	if err != nil {
+		return "", err
+	}
+	if info.Config == nil || info.Config.WorkingDir == "" {
+		return "/", nil
+	}
+	return info.Config.WorkingDir, nil
+}
+
+// CopyFromContainer returns a tar archive of filePath, an absolute path in a container, and the stat of it.
+func (d Docker) CopyFromContainer(filePath string) (io.ReadCloser, dockerTypes.ContainerPathStat, error) {
+	ctx := goContext.Background()
+	return d.client.CopyFromContainer(ctx, d.CID, filePath)
+}
+
 // StartContainer starts a container and returns its error.
 func (d Docker) StartContainer() error {
 	ctx := goContext.Background()
 	return d.client.ContainerStart(ctx, d.CID, dockerTypes.ContainerStartOptions{})
 }
 
-// WaitContainer returns when container finishes executing cmd.
-func (d Docker) WaitContainer(timeOutInSeconds int) error {
-	ctx := goContext.Background()
+// WaitContainer returns the exit code of the container when it finishes executing cmd or -1 if it did not exit.
+// It stops waiting with ErrCancelled if cancelRun is closed before the container finishes. A container still
+// running after timeOutInSeconds is stopped and removed, as it would otherwise keep running unattended.
+func (d Docker) WaitContainer(timeOutInSeconds int, cancelRun <-chan struct{}) (int, error) {
+	ctx, cancel := goContext.WithTimeout(goContext.Background(), time.Duration(timeOutInSeconds)*time.Second)
+	defer cancel()
+	go func() {
+		select {
+		case <-cancelRun:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 	statusCode, err := d.client.ContainerWait(ctx, d.CID)
+	if err != nil {
+		if isClosed(cancelRun) {
+			return -1, ErrCancelled
+		}
+		if ctx.Err() == goContext.DeadlineExceeded {
+			if err := d.StopContainer(); err == nil {
+				d.RemoveContainer()
+			}
+		}
+		return -1, err
+	}
 
 	if statusCode != 0 {
-		return fmt.Errorf("Error in POST to wait the container with statusCode %d", statusCode)
+		return int(statusCode), fmt.Errorf("Error in POST to wait the container with statusCode %d", statusCode)
 	}
 
-	return err
+	return 0, nil
 }
 
 // StopContainer stops an active container by it's CID
@@ -114,7 +168,7 @@ func (d Docker) StopContainer() error {
 	ctx := goContext.Background()
 	err := d.client.ContainerStop(ctx, d.CID, nil)
 	if err != nil {
-		log.Error("StopContainer", logInfoAPI, 3022, err)
+		d.logger.Error("StopContainer", logInfoAPI, 3022, err)
 	}
 	return err
 }
@@ -124,7 +178,7 @@ func (d Docker) RemoveContainer() error {
 	ctx := goContext.Background()
 	err := d.client.ContainerRemove(ctx, d.CID, dockerTypes.ContainerRemoveOptions{})
 	if err != nil {
-		log.Error("RemoveContainer", logInfoAPI, 3023, err)
+		d.logger.Error("RemoveContainer", logInfoAPI, 3023, err)
 	}
 	return err
 }
@@ -143,7 +197,7 @@ func (d Docker) ListStoppedContainers() ([]Docker, error) {
 
 	containerList, err := d.client.ContainerList(ctx, options)
 	if err != nil {
-		log.Error("ListContainer", logInfoAPI, 3021, err)
+		d.logger.Error("ListContainer", logInfoAPI, 3021, err)
 		return nil, err
 	}
 
@@ -159,6 +213,38 @@ func (d Docker) ListStoppedContainers() ([]Docker, error) {
 	return dockerList, nil
 }
 
+// ListScanContainers returns every container, running or not, labeled as a huskyCI scan container.
+func (d Docker) ListScanContainers() ([]ScanContainer, error) {
+	ctx := goContext.Background()
+	dockerFilters := filters.NewArgs()
+	dockerFilters.Add("label", ScanContainerLabel+"=true")
+	options := dockerTypes.ContainerListOptions{
+		All:     true,
+		Filters: dockerFilters,
+	}
+
+	containerList, err := d.client.ContainerList(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	scanContainers := []ScanContainer{}
+	for _, c := range containerList {
+		scanContainers = append(scanContainers, ScanContainer{
+			CID:     c.ID,
+			RID:     c.Labels[AnalysisLabel],
+			Running: c.State == "running",
+		})
+	}
+	return scanContainers, nil
+}
+
+// ForceRemoveContainer removes a container by its CID, stopping it first if it is running.
+func (d Docker) ForceRemoveContainer(CID string) error {
+	ctx := goContext.Background()
+	return d.client.ContainerRemove(ctx, CID, dockerTypes.ContainerRemoveOptions{Force: true})
+}
+
 // DieContainers stops and removes all containers
 func (d Docker) DieContainers() error {
 	containerList, err := d.ListStoppedContainers()
@@ -185,13 +271,13 @@ func (d Docker) ReadOutput() (string, error) {
 	ctx := goContext.Background()
 	out, err := d.client.ContainerLogs(ctx, d.CID, dockerTypes.ContainerLogsOptions{ShowStdout: true})
 	if err != nil {
-		log.Error("ReadOutput", logInfoAPI, 3006, err)
+		d.logger.Error("ReadOutput", logInfoAPI, 3006, err)
 		return "", nil
 	}
 
 	body, err := ioutil.ReadAll(out)
 	if err != nil {
-		log.Error("ReadOutput", logInfoAPI, 3007, err)
+		d.logger.Error("ReadOutput", logInfoAPI, 3007, err)
 		return "", err
 	}
 	return string(body), err
@@ -202,13 +288,13 @@ func (d Docker) ReadOutputStderr() (string, error) {
 	ctx := goContext.Background()
 	out, err := d.client.ContainerLogs(ctx, d.CID, dockerTypes.ContainerLogsOptions{ShowStderr: true})
 	if err != nil {
-		log.Error("ReadOutputStderr", logInfoAPI, 3006, err)
+		d.logger.Error("ReadOutputStderr", logInfoAPI, 3006, err)
 		return "", nil
 	}
 
 	body, err := ioutil.ReadAll(out)
 	if err != nil {
-		log.Error("ReadOutputStderr", logInfoAPI, 3008, err)
+		d.logger.Error("ReadOutputStderr", logInfoAPI, 3008, err)
 		return "", err
 	}
 	return string(body), err
@@ -219,7 +305,7 @@ func (d Docker) PullImage(image string) error {
 	ctx := goContext.Background()
 	_, err := d.client.ImagePull(ctx, image, dockerTypes.ImagePullOptions{})
 	if err != nil {
-		log.Error("PullImage", logInfoAPI, 3009, err)
+		d.logger.Error("PullImage", logInfoAPI, 3009, err)
 	}
 	return err
 }
@@ -233,13 +319,48 @@ func (d Docker) ImageIsLoaded(image string) bool {
 	ctx := goContext.Background()
 	result, err := d.client.ImageList(ctx, options)
 	if err != nil {
-		log.Error("ImageIsLoaded", logInfoAPI, 3010, err)
+		d.logger.Error("ImageIsLoaded", logInfoAPI, 3010, err)
 		panic(err)
 	}
 
 	return len(result) != 0
 }
 
+// ImageReference returns the reference of a loaded image pinned to its digest, or image itself if
+// the docker API does not know its digest, as happens with images that were never pushed.
+func (d Docker) ImageReference(image string) string {
+	ctx := goContext.Background()
+	inspect, _, err := d.client.ImageInspectWithRaw(ctx, image)
+	if err != nil || len(inspect.RepoDigests) == 0 {
+		return image
+	}
+	return inspect.RepoDigests[0]
+}
+
+// ImageDigest returns the registry digest of the loaded image:tag, or an empty string if the
+// docker API does not know it, as happens with images that were never pushed.
+func (d Docker) ImageDigest(image, tag string) (string, error) {
+	ctx := goContext.Background()
+	inspect, _, err := d.client.ImageInspectWithRaw(ctx, fmt.Sprintf("%s:%s", image, tag))
+	if err != nil {
+		return "", err
+	}
+	digest := ""
+	for _, repoDigest := range inspect.RepoDigests {
+		parts := strings.SplitN(repoDigest, "@", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if parts[0] == image {
+			return parts[1], nil
+		}
+		if digest == "" {
+			digest = parts[1]
+		}
+	}
+	return digest, nil
+}
+
 // ListImages returns docker images, like docker image ls.
 func (d Docker) ListImages() ([]dockerTypes.ImageSummary, error) {
 	ctx := goContext.Background()
@@ -0,0 +1,71 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/globocom/huskyCI/api/log"
+)
+
+// ErrFileTooLarge is returned by ContainerFiles when a file is bigger than the size it may read.
+var ErrFileTooLarge = errors.New("file too large")
+
+// ErrNotRegularFile is returned by ContainerFiles when a path is a directory, a link or a device.
+var ErrNotRegularFile = errors.New("not a regular file")
+
+// ContainerFiles reads the files of a finished container that DockerRun kept, and removes it afterwards.
+type ContainerFiles struct {
+	d          *Docker
+	workingDir string
+}
+
+// OpenContainerFiles returns the ContainerFiles of the finished container CID.
+func OpenContainerFiles(CID string, logger log.Entry) (*ContainerFiles, error) {
+	d, err := NewDocker()
+	if err != nil {
+		return nil, err
+	}
+	d.CID = CID
+	d.logger = logger
+	workingDir, err := d.WorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerFiles{d: d, workingDir: workingDir}, nil
+}
+
+// ReadFile returns the content of filePath, relative to the working directory of the container unless it
+// is absolute. Files bigger than maxSize bytes are not read, failing with ErrFileTooLarge.
+func (f *ContainerFiles) ReadFile(filePath string, maxSize int64) ([]byte, error) {
+	if !path.IsAbs(filePath) {
+		filePath = path.Join(f.workingDir, filePath)
+	}
+	archive, stat, err := f.d.CopyFromContainer(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	if !stat.Mode.IsRegular() {
+		return nil, ErrNotRegularFile
+	}
+	if stat.Size > maxSize {
+		return nil, ErrFileTooLarge
+	}
+	file := tar.NewReader(archive)
+	if _, err := file.Next(); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(file, maxSize))
+}
+
+// Remove removes the container.
+func (f *ContainerFiles) Remove() error {
+	return f.d.RemoveContainer()
+}
@@ -7,6 +7,7 @@ package dockers
 import (
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"regexp"
@@ -18,6 +19,9 @@ const logActionRun = "DockerRun"
 const logInfoHuskyDocker = "HUSKYDOCKER"
 const logActionPull = "pullImage"
 
+// ErrCancelled is returned by DockerRun when its run is cancelled before the container finishes.
+var ErrCancelled = errors.New("container run cancelled")
+
 const urlRegexp = `([\w\-_]+(?:(?:\.[\w\-_]+)+))([\w\-\.,@?^=%&amp;:/~\+#]*[\w\-\@?^=%&amp;/~\+#])?`
 
 func configureImagePath(image, tag string) (string, string) {
@@ -33,57 +37,125 @@ func configureImagePath(image, tag string) (string, string) {
 	return canonicalURL, fullContainerImage
 }
 
-// DockerRun starts a new container and returns its output and an error.
-func DockerRun(image, imageTag, cmd string, timeOutInSeconds int) (string, string, error) {
+// RunInfo holds how a container started by DockerRun ran.
+type RunInfo struct {
+	CID      string
+	Output   string
+	Image    string
+	ExitCode int
+}
+
+// DockerRun starts a new container with the given env and returns how it ran and an error.
+// The returned RunInfo is filled as far as the container got, even when an error is returned.
+// Closing cancel stops and removes the container, making DockerRun return ErrCancelled.
+// files, when set, is the path of a tar archive extracted into the container before it starts.
+// The container is labeled as a scan container of the analysis RID, empty if it runs for none.
+// keep, when set, keeps the container once it finished successfully, for OpenContainerFiles to read its files.
+func DockerRun(RID, image, imageTag, cmd string, env []string, files string, keep bool, timeOutInSeconds int, cancel <-chan struct{}, logger log.Entry) (RunInfo, error) {
+
+	canonicalURL, fullContainerImage := configureImagePath(image, imageTag)
+	runInfo := RunInfo{Image: fullContainerImage, ExitCode: -1}
 
 	// step 1: create a new docker API client
 	d, err := NewDocker()
 	if err != nil {
-		return "", "", err
+		return runInfo, err
 	}
+	d.logger = logger
 
-	canonicalURL, fullContainerImage := configureImagePath(image, imageTag)
 	// step 2: pull image if it is not there yet
 	if !d.ImageIsLoaded(fullContainerImage) {
 		if err := pullImage(d, canonicalURL, fullContainerImage); err != nil {
-			return "", "", err
+			return runInfo, err
+		}
+	}
+	runInfo.Image = d.ImageReference(fullContainerImage)
+
+	// step 3: refuse images that are not in the image allowlist, pinned digest included
+	if allowlist := currentImageAllowlist(); len(allowlist) != 0 {
+		digest, _ := d.ImageDigest(image, imageTag)
+		if !ImageApproved(allowlist, image, imageTag, digest) {
+			d.logger.Error(logActionRun, logInfoHuskyDocker, 3030, runInfo.Image)
+			return runInfo, ErrImageNotApproved
 		}
 	}
 
-	// step 3: create a new container given an image and it's cmd
-	CID, err := d.CreateContainer(fullContainerImage, cmd)
+	// step 4: wait for a free slot and create a new container given an image and it's cmd
+	semaphore := containerSemaphore()
+	if !semaphore.AcquireUnless(cancel) {
+		return runInfo, ErrCancelled
+	}
+	defer semaphore.Release()
+	CID, err := d.CreateContainer(fullContainerImage, cmd, env, RID)
 	if err != nil {
-		return "", "", err
+		return runInfo, err
 	}
 	d.CID = CID
+	runInfo.CID = CID
+	if files != "" {
+		if err := copyFiles(d, files); err != nil {
+			d.logger.Error(logActionRun, logInfoHuskyDocker, 3029, err)
+			d.RemoveContainer()
+			return runInfo, err
+		}
+	}
 
-	// step 4: start container
+	// step 5: start container
 	if err := d.StartContainer(); err != nil {
-		log.Error(logActionRun, logInfoHuskyDocker, 3015, err)
-		return "", "", err
+		d.logger.Error(logActionRun, logInfoHuskyDocker, 3015, err)
+		return runInfo, err
 	}
-	log.Info(logActionRun, logInfoHuskyDocker, 32, fullContainerImage, d.CID)
+	d.logger.Info(logActionRun, logInfoHuskyDocker, 32, fullContainerImage, d.CID)
 
-	// step 5: wait container finish
-	if err := d.WaitContainer(timeOutInSeconds); err != nil {
-		log.Error(logActionRun, logInfoHuskyDocker, 3016, err)
-		return "", "", err
+	// step 6: wait container finish
+	runInfo.ExitCode, err = d.WaitContainer(timeOutInSeconds, cancel)
+	if err == ErrCancelled {
+		if err := d.StopContainer(); err == nil {
+			d.RemoveContainer()
+		}
+		return runInfo, err
+	}
+	if err != nil {
+		d.logger.Error(logActionRun, logInfoHuskyDocker, 3016, err)
+		return runInfo, err
 	}
 
-	// step 6: read container's output when it finishes
-	cOutput, err := d.ReadOutput()
+	// step 7: read container's output when it finishes
+	runInfo.Output, err = d.ReadOutput()
 	if err != nil {
-		return "", "", err
+		return runInfo, err
+	}
+	d.logger.Info(logActionRun, logInfoHuskyDocker, 34, fullContainerImage, d.CID)
+	if keep {
+		return runInfo, nil
 	}
-	log.Info(logActionRun, logInfoHuskyDocker, 34, fullContainerImage, d.CID)
 
-	// step 7: remove container from docker API
+	// step 8: remove container from docker API
 	if err := d.RemoveContainer(); err != nil {
-		log.Error(logActionRun, logInfoHuskyDocker, 3027, err)
-		return "", "", err
+		d.logger.Error(logActionRun, logInfoHuskyDocker, 3027, err)
+		return runInfo, err
 	}
 
-	return CID, cOutput, nil
+	return runInfo, nil
+}
+
+func copyFiles(d *Docker, files string) error {
+	archive, err := os.Open(files)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	return d.CopyToContainer(archive)
+}
+
+// isClosed reports whether cancel was closed. A nil cancel is never closed.
+func isClosed(cancel <-chan struct{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
 }
 
 func pullImage(d *Docker, canonicalURL, image string) error {
@@ -93,16 +165,16 @@ func pullImage(d *Docker, canonicalURL, image string) error {
 		select {
 		case <-timeout:
 			timeOutErr := errors.New("timeout")
-			log.Error(logActionPull, logInfoHuskyDocker, 3013, timeOutErr)
+			d.logger.Error(logActionPull, logInfoHuskyDocker, 3013, timeOutErr)
 			return timeOutErr
 		case <-retryTick.C:
-			log.Info(logActionPull, logInfoHuskyDocker, 31, image)
+			d.logger.Info(logActionPull, logInfoHuskyDocker, 31, image)
 			if d.ImageIsLoaded(image) {
-				log.Info(logActionPull, logInfoHuskyDocker, 35, image)
+				d.logger.Info(logActionPull, logInfoHuskyDocker, 35, image)
 				return nil
 			}
 			if err := d.PullImage(canonicalURL); err != nil {
-				log.Error(logActionPull, logInfoHuskyDocker, 3013, err)
+				d.logger.Error(logActionPull, logInfoHuskyDocker, 3013, err)
 				return err
 			}
 		}
@@ -0,0 +1,113 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+)
+
+const logActionImageUpdate = "CheckImageUpdates"
+
+// ImageUpdate is the result of comparing the loaded image of a securityTest with the latest one of its registry.
+type ImageUpdate struct {
+	SecurityTest    string    `json:"securityTest"`
+	Image           string    `json:"image"`
+	ImageTag        string    `json:"imageTag"`
+	LocalDigest     string    `json:"localDigest"`
+	LatestDigest    string    `json:"latestDigest"`
+	UpdateAvailable bool      `json:"updateAvailable"`
+	CheckedAt       time.Time `json:"checkedAt"`
+}
+
+// LocalImages returns the digest of a loaded image.
+type LocalImages interface {
+	ImageDigest(image, tag string) (string, error)
+}
+
+// ImageRegistry returns the latest digest of an image in its registry.
+type ImageRegistry interface {
+	LatestDigest(image, tag string) (string, error)
+}
+
+var (
+	imageUpdatesMutex sync.RWMutex
+	imageUpdates      = make(map[string]ImageUpdate)
+)
+
+// CheckImageUpdate compares the digest of the loaded image of securityTest with the latest one of its
+// registry. An image that is not loaded yet is never reported as outdated, as it will be pulled
+// with its latest digest by the next analysis that runs it.
+func CheckImageUpdate(securityTest types.SecurityTest, local LocalImages, registry ImageRegistry) (ImageUpdate, error) {
+	update := ImageUpdate{
+		SecurityTest: securityTest.Name,
+		Image:        securityTest.Image,
+		ImageTag:     securityTest.ImageTag,
+		CheckedAt:    time.Now(),
+	}
+	latestDigest, err := registry.LatestDigest(securityTest.Image, securityTest.ImageTag)
+	if err != nil {
+		return update, err
+	}
+	update.LatestDigest = latestDigest
+	if localDigest, err := local.ImageDigest(securityTest.Image, securityTest.ImageTag); err == nil {
+		update.LocalDigest = localDigest
+	}
+	update.UpdateAvailable = update.LocalDigest != "" && update.LocalDigest != update.LatestDigest
+	return update, nil
+}
+
+// CheckImageUpdates checks every securityTest for a newer image, logging the ones that have it. It never
+// pulls the newer images, leaving the update to whoever runs huskyCI.
+func CheckImageUpdates(securityTests []types.SecurityTest, local LocalImages, registry ImageRegistry) {
+	for _, securityTest := range securityTests {
+		update, err := CheckImageUpdate(securityTest, local, registry)
+		if err != nil {
+			log.Error(logActionImageUpdate, logInfoHuskyDocker, 3028, securityTest.Name, err)
+			continue
+		}
+		if update.UpdateAvailable {
+			log.Warning(logActionImageUpdate, logInfoHuskyDocker, 118, securityTest.Name, update.Image+":"+update.ImageTag, update.LatestDigest)
+		}
+		imageUpdatesMutex.Lock()
+		imageUpdates[securityTest.Name] = update
+		imageUpdatesMutex.Unlock()
+	}
+}
+
+// StartImageUpdateCheck checks the images of securityTests for updates right away and then once every interval.
+func StartImageUpdateCheck(interval time.Duration, securityTests []types.SecurityTest) {
+	log.Info(logActionImageUpdate, logInfoHuskyDocker, 37, interval)
+	registry := NewRegistry()
+	check := func() {
+		d, err := NewDocker()
+		if err != nil {
+			return
+		}
+		CheckImageUpdates(securityTests, d, registry)
+	}
+	go func() {
+		check()
+		for range time.Tick(interval) {
+			check()
+		}
+	}()
+}
+
+// LastImageUpdates returns the result of the last check of each securityTest image, sorted by securityTest.
+func LastImageUpdates() []ImageUpdate {
+	imageUpdatesMutex.RLock()
+	defer imageUpdatesMutex.RUnlock()
+	updates := make([]ImageUpdate, 0, len(imageUpdates))
+	for _, update := range imageUpdates {
+		updates = append(updates, update)
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].SecurityTest < updates[j].SecurityTest })
+	return updates
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/dockers"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeLocalImages map[string]string
+
+func (f fakeLocalImages) ImageDigest(image, tag string) (string, error) {
+	digest, ok := f[image+":"+tag]
+	if !ok {
+		return "", errors.New("No such image")
+	}
+	return digest, nil
+}
+
+// newFakeRegistry returns a registry that asks for a bearer token, as Docker Hub does, before
+// answering the digests of the given image:tag references.
+func newFakeRegistry(digests map[string]string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("service") != "fake-registry" || !strings.HasPrefix(r.URL.Query().Get("scope"), "repository:") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "fake-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fake-token" {
+			repository := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v2/"), "/manifests/", 2)[0]
+			w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake-registry",scope="repository:%s:pull"`, server.URL, repository))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "application/vnd.docker.distribution.manifest.list.v2+json") {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		reference := strings.Replace(strings.TrimPrefix(r.URL.Path, "/v2/"), "/manifests/", ":", 1)
+		digest, ok := digests[reference]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	}))
+	return server
+}
+
+var _ = Describe("ImageUpdate", func() {
+
+	log.InitLog(true, "", "", "log_test", "log_test")
+
+	server := newFakeRegistry(map[string]string{
+		"huskyci/gosec:v2.3.0":  "sha256:new",
+		"huskyci/bandit:1.6.2":  "sha256:same",
+		"huskyci/safety:1.9.0":  "sha256:latest",
+		"huskyci/hadolint:v1.0": "sha256:hadolint",
+	})
+	registry := dockers.Registry{Client: server.Client()}
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	Describe("Registry", func() {
+		Context("When the registry requires a bearer token", func() {
+			It("Should request a token and return the digest of the tag.", func() {
+				digest, err := registry.LatestDigest(host+"/huskyci/gosec", "v2.3.0")
+				Expect(err).To(BeNil())
+				Expect(digest).To(Equal("sha256:new"))
+			})
+		})
+
+		Context("When the tag does not exist", func() {
+			It("Should return an error.", func() {
+				_, err := registry.LatestDigest(host+"/huskyci/gosec", "v0.0.0")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("CheckImageUpdate", func() {
+		local := fakeLocalImages{
+			host + "/huskyci/gosec:v2.3.0": "sha256:old",
+			host + "/huskyci/bandit:1.6.2": "sha256:same",
+		}
+
+		Context("When the loaded image digest differs from the registry one", func() {
+			It("Should report an update.", func() {
+				update, err := dockers.CheckImageUpdate(types.SecurityTest{Name: "gosec", Image: host + "/huskyci/gosec", ImageTag: "v2.3.0"}, local, registry)
+				Expect(err).To(BeNil())
+				Expect(update.SecurityTest).To(Equal("gosec"))
+				Expect(update.LocalDigest).To(Equal("sha256:old"))
+				Expect(update.LatestDigest).To(Equal("sha256:new"))
+				Expect(update.UpdateAvailable).To(BeTrue())
+			})
+		})
+
+		Context("When the loaded image is the latest one", func() {
+			It("Should not report an update.", func() {
+				update, err := dockers.CheckImageUpdate(types.SecurityTest{Name: "bandit", Image: host + "/huskyci/bandit", ImageTag: "1.6.2"}, local, registry)
+				Expect(err).To(BeNil())
+				Expect(update.UpdateAvailable).To(BeFalse())
+			})
+		})
+
+		Context("When the image is not loaded yet", func() {
+			It("Should not report an update.", func() {
+				update, err := dockers.CheckImageUpdate(types.SecurityTest{Name: "safety", Image: host + "/huskyci/safety", ImageTag: "1.9.0"}, local, registry)
+				Expect(err).To(BeNil())
+				Expect(update.LocalDigest).To(BeEmpty())
+				Expect(update.LatestDigest).To(Equal("sha256:latest"))
+				Expect(update.UpdateAvailable).To(BeFalse())
+			})
+		})
+
+		Context("When the registry does not have the image", func() {
+			It("Should return an error.", func() {
+				_, err := dockers.CheckImageUpdate(types.SecurityTest{Name: "tfsec", Image: host + "/huskyci/tfsec", ImageTag: "v0.19.0"}, local, registry)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("CheckImageUpdates", func() {
+		It("Should keep the result of the last check of each securityTest.", func() {
+			local := fakeLocalImages{
+				host + "/huskyci/gosec:v2.3.0":  "sha256:old",
+				host + "/huskyci/hadolint:v1.0": "sha256:hadolint",
+			}
+			dockers.CheckImageUpdates([]types.SecurityTest{
+				{Name: "hadolint", Image: host + "/huskyci/hadolint", ImageTag: "v1.0"},
+				{Name: "gosec", Image: host + "/huskyci/gosec", ImageTag: "v2.3.0"},
+				{Name: "tfsec", Image: host + "/huskyci/tfsec", ImageTag: "v0.19.0"},
+			}, local, registry)
+			updates := dockers.LastImageUpdates()
+			Expect(updates).To(HaveLen(2))
+			Expect(updates[0].SecurityTest).To(Equal("gosec"))
+			Expect(updates[0].UpdateAvailable).To(BeTrue())
+			Expect(updates[1].SecurityTest).To(Equal("hadolint"))
+			Expect(updates[1].UpdateAvailable).To(BeFalse())
+		})
+	})
+})
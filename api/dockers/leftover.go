@@ -0,0 +1,100 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers
+
+import (
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/log"
+	mgo "gopkg.in/mgo.v2"
+)
+
+const logActionRemoveLeftover = "RemoveLeftoverContainers"
+
+// ScanContainerLabel labels every container created by huskyCI to run a securityTest, so that only those are
+// ever removed as leftovers.
+const ScanContainerLabel = "io.huskyci.scan"
+
+// AnalysisLabel labels a scan container with the RID of the analysis it runs for.
+const AnalysisLabel = "io.huskyci.analysisID"
+
+// ScanContainer is a container labeled as a huskyCI scan container.
+type ScanContainer struct {
+	CID string
+	// RID is the analysis the container runs for, empty if it runs for none, such as a self-test.
+	RID     string
+	Running bool
+}
+
+// ScanContainerHost lists and removes the scan containers of a Docker host.
+type ScanContainerHost interface {
+	ListScanContainers() ([]ScanContainer, error)
+	ForceRemoveContainer(CID string) error
+}
+
+// AnalysisRunning tells whether the analysis of RID is still running.
+type AnalysisRunning func(RID string) (bool, error)
+
+func scanContainerLabels(RID string) map[string]string {
+	labels := map[string]string{ScanContainerLabel: "true"}
+	if RID != "" {
+		labels[AnalysisLabel] = RID
+	}
+	return labels
+}
+
+// RemoveLeftoverContainers removes the scan containers of host left behind by a previous API process: the
+// stopped ones and the running ones whose analysis is no longer running. A running container is kept when
+// it runs for no analysis or when it can not be told whether its analysis is running. It returns the
+// removed containers.
+func RemoveLeftoverContainers(host ScanContainerHost, analysisRunning AnalysisRunning) ([]ScanContainer, error) {
+	scanContainers, err := host.ListScanContainers()
+	if err != nil {
+		log.Error(logActionRemoveLeftover, logInfoAPI, 3031, err)
+		return nil, err
+	}
+	removed := []ScanContainer{}
+	for _, scanContainer := range scanContainers {
+		if scanContainer.Running {
+			if scanContainer.RID == "" {
+				continue
+			}
+			running, err := analysisRunning(scanContainer.RID)
+			if err != nil || running {
+				continue
+			}
+		}
+		if err := host.ForceRemoveContainer(scanContainer.CID); err != nil {
+			log.Error(logActionRemoveLeftover, logInfoAPI, 3032, scanContainer.CID, err)
+			continue
+		}
+		log.Info(logActionRemoveLeftover, logInfoAPI, 43, scanContainer.CID, scanContainer.RID)
+		removed = append(removed, scanContainer)
+	}
+	return removed, nil
+}
+
+// CleanupLeftoverContainers removes the scan containers of the Docker host left behind by a previous API
+// process, as RemoveLeftoverContainers does, looking up their analyses in the database.
+func CleanupLeftoverContainers() {
+	d, err := NewDocker()
+	if err != nil {
+		return
+	}
+	RemoveLeftoverContainers(d, storedAnalysisRunning)
+}
+
+// storedAnalysisRunning tells whether the stored analysis of RID is running. An analysis that is not
+// stored is not running.
+func storedAnalysisRunning(RID string) (bool, error) {
+	analysis, err := apiContext.APIConfiguration.DBInstance.FindOneDBAnalysis(map[string]interface{}{"RID": RID})
+	if err != nil {
+		if err == mgo.ErrNotFound || err.Error() == "No data found" {
+			return false, nil
+		}
+		log.Error(logActionRemoveLeftover, logInfoAPI, 2025, RID, err)
+		return false, err
+	}
+	return analysis.Status == "running", nil
+}
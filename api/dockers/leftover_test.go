@@ -0,0 +1,78 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers_test
+
+import (
+	"errors"
+
+	"github.com/globocom/huskyCI/api/dockers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeScanContainerHost struct {
+	containers []dockers.ScanContainer
+	listErr    error
+	removed    []string
+}
+
+func (f *fakeScanContainerHost) ListScanContainers() ([]dockers.ScanContainer, error) {
+	return f.containers, f.listErr
+}
+
+func (f *fakeScanContainerHost) ForceRemoveContainer(CID string) error {
+	if CID == "busy" {
+		return errors.New("removal in progress")
+	}
+	f.removed = append(f.removed, CID)
+	return nil
+}
+
+var _ = Describe("RemoveLeftoverContainers", func() {
+
+	analysisRunning := func(RID string) (bool, error) {
+		switch RID {
+		case "runningRID":
+			return true, nil
+		case "unknownRID":
+			return false, errors.New("database unreachable")
+		}
+		return false, nil
+	}
+
+	It("Should remove the stopped containers and the running ones whose analysis is no longer running.", func() {
+		host := &fakeScanContainerHost{containers: []dockers.ScanContainer{
+			{CID: "stopped", RID: "runningRID"},
+			{CID: "stoppedSelfTest"},
+			{CID: "finishedAnalysis", RID: "finishedRID", Running: true},
+			{CID: "runningAnalysis", RID: "runningRID", Running: true},
+			{CID: "runningSelfTest", Running: true},
+			{CID: "unknownAnalysis", RID: "unknownRID", Running: true},
+		}}
+		removed, err := dockers.RemoveLeftoverContainers(host, analysisRunning)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(host.removed).To(Equal([]string{"stopped", "stoppedSelfTest", "finishedAnalysis"}))
+		Expect(removed).To(Equal([]dockers.ScanContainer{
+			{CID: "stopped", RID: "runningRID"},
+			{CID: "stoppedSelfTest"},
+			{CID: "finishedAnalysis", RID: "finishedRID", Running: true},
+		}))
+	})
+
+	It("Should go on removing the other containers when one can not be removed.", func() {
+		host := &fakeScanContainerHost{containers: []dockers.ScanContainer{{CID: "busy"}, {CID: "stopped"}}}
+		removed, err := dockers.RemoveLeftoverContainers(host, analysisRunning)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal([]dockers.ScanContainer{{CID: "stopped"}}))
+	})
+
+	It("Should return an error when the containers can not be listed.", func() {
+		host := &fakeScanContainerHost{listErr: errors.New("docker unreachable")}
+		_, err := dockers.RemoveLeftoverContainers(host, analysisRunning)
+		Expect(err).To(HaveOccurred())
+		Expect(host.removed).To(BeEmpty())
+	})
+})
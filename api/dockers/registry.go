@@ -0,0 +1,143 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const dockerHubRegistry = "registry-1.docker.io"
+
+// manifestMediaTypes are the manifests accepted from a registry. Manifest lists are listed first so
+// that the digest returned for multi-arch images is the same one docker keeps after pulling them.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Registry looks up image digests using the Docker Registry HTTP API V2, requesting an anonymous
+// bearer token when the registry asks for one.
+type Registry struct {
+	Client *http.Client
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry() Registry {
+	return Registry{Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// LatestDigest returns the digest the registry of image currently has for tag.
+func (r Registry) LatestDigest(image, tag string) (string, error) {
+	host, repository := registryRepository(image)
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, tag)
+
+	resp, err := r.headManifest(manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := r.token(resp.Header.Get("Www-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		if resp, err = r.headManifest(manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned status code %d for %s:%s", resp.StatusCode, image, tag)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry returned no digest for %s:%s", image, tag)
+	}
+	return digest, nil
+}
+
+func (r Registry) headManifest(manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// token requests an anonymous token from the realm of a Bearer challenge.
+func (r Registry) token(challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", errors.New("registry requires an unsupported authentication")
+	}
+	params := make(map[string]string)
+	for _, param := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		pair := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(pair) == 2 {
+			params[pair[0]] = strings.Trim(pair[1], `"`)
+		}
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", errors.New("registry returned an invalid authentication realm")
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := r.Client.Get(realm.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry authentication returned status code %d", resp.StatusCode)
+	}
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	return tokenResponse.AccessToken, nil
+}
+
+// registryRepository splits image into the host of its registry and its repository in it,
+// following the same rules of docker pull for images without a registry.
+func registryRepository(image string) (string, string) {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		if parts[0] != "docker.io" && parts[0] != "index.docker.io" {
+			return parts[0], parts[1]
+		}
+		image = parts[1]
+	}
+	if !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	return dockerHubRegistry, image
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ContainerSemaphore limits the number of containers running at the same time.
+type ContainerSemaphore struct {
+	slots chan struct{}
+}
+
+// NewContainerSemaphore returns a ContainerSemaphore with limit slots. A limit lower than one is set to one.
+func NewContainerSemaphore(limit int) *ContainerSemaphore {
+	if limit < 1 {
+		limit = 1
+	}
+	return &ContainerSemaphore{slots: make(chan struct{}, limit)}
+}
+
+// Acquire takes a slot, waiting until one is free.
+func (s *ContainerSemaphore) Acquire() {
+	s.slots <- struct{}{}
+}
+
+// AcquireUnless takes a slot like Acquire, but gives up and returns false if cancel is closed first.
+// A nil cancel never closes, making it the same as Acquire.
+func (s *ContainerSemaphore) AcquireUnless(cancel <-chan struct{}) bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-cancel:
+		return false
+	}
+}
+
+// Release frees a slot taken by Acquire.
+func (s *ContainerSemaphore) Release() {
+	<-s.slots
+}
+
+var (
+	runningContainers      = NewContainerSemaphore(2 * runtime.NumCPU())
+	runningContainersMutex sync.RWMutex
+)
+
+// SetMaxRunningContainers sets how many containers DockerRun may run at the same time across all analyses.
+// It should be called before any container runs: containers already running keep their slots in the previous limit.
+func SetMaxRunningContainers(limit int) {
+	runningContainersMutex.Lock()
+	defer runningContainersMutex.Unlock()
+	runningContainers = NewContainerSemaphore(limit)
+}
+
+func containerSemaphore() *ContainerSemaphore {
+	runningContainersMutex.RLock()
+	defer runningContainersMutex.RUnlock()
+	return runningContainers
+}
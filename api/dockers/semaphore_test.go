@@ -0,0 +1,98 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockers_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/globocom/huskyCI/api/dockers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainerSemaphore", func() {
+
+	Context("When every slot is taken", func() {
+		It("Should queue until a slot frees.", func() {
+			semaphore := dockers.NewContainerSemaphore(1)
+			semaphore.Acquire()
+
+			acquired := make(chan struct{})
+			go func() {
+				semaphore.Acquire()
+				close(acquired)
+			}()
+
+			Consistently(acquired, 100*time.Millisecond).ShouldNot(BeClosed())
+			semaphore.Release()
+			Eventually(acquired).Should(BeClosed())
+			semaphore.Release()
+		})
+	})
+
+	Context("When a run waiting for a slot is cancelled", func() {
+		It("Should give up without taking a slot.", func() {
+			semaphore := dockers.NewContainerSemaphore(1)
+			semaphore.Acquire()
+
+			cancel := make(chan struct{})
+			acquired := make(chan bool)
+			go func() {
+				acquired <- semaphore.AcquireUnless(cancel)
+			}()
+
+			Consistently(acquired, 100*time.Millisecond).ShouldNot(Receive())
+			close(cancel)
+			Eventually(acquired).Should(Receive(BeFalse()))
+			semaphore.Release()
+			Expect(semaphore.AcquireUnless(nil)).To(BeTrue())
+		})
+	})
+
+	Context("When many containers run at the same time", func() {
+		It("Should never run more than the limit.", func() {
+			limit := 3
+			semaphore := dockers.NewContainerSemaphore(limit)
+
+			var mutex sync.Mutex
+			var wg sync.WaitGroup
+			running, maxRunning := 0, 0
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					semaphore.Acquire()
+					defer semaphore.Release()
+					mutex.Lock()
+					running++
+					if running > maxRunning {
+						maxRunning = running
+					}
+					mutex.Unlock()
+					time.Sleep(10 * time.Millisecond)
+					mutex.Lock()
+					running--
+					mutex.Unlock()
+				}()
+			}
+			wg.Wait()
+			Expect(maxRunning).To(Equal(limit))
+		})
+	})
+
+	Context("When the limit is lower than one", func() {
+		It("Should still allow one container to run.", func() {
+			semaphore := dockers.NewContainerSemaphore(0)
+			acquired := make(chan struct{})
+			go func() {
+				semaphore.Acquire()
+				close(acquired)
+			}()
+			Eventually(acquired).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,131 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// Fingerprint algorithms supported by Vulnerability.
+const (
+	// Line tells vulnerabilities apart by file, line and rule. It is the most precise
+	// one, but a vulnerability gets a new fingerprint whenever lines are added above it.
+	Line = "line"
+	// NoLine tells vulnerabilities apart by file and rule only, so that they keep their
+	// fingerprint when lines shift, at the cost of sharing it with the same rule in the same file.
+	NoLine = "noline"
+	// CodeHash tells vulnerabilities apart by file, rule and a hash of their code snippet,
+	// so that they keep their fingerprint when lines shift but not when the snippet changes.
+	// Vulnerabilities without a snippet fall back to NoLine.
+	CodeHash = "codehash"
+)
+
+// Vulnerability returns a stable identifier of vuln, used to match it against the ones of
+// previous analyses, computed with one of the fingerprint algorithms. Vulnerable dependencies are
+// always identified by their package and CVE, and vulnerabilities without a file by their
+// securityTool, title and details, regardless of the algorithm.
+func Vulnerability(vuln types.HuskyCIVulnerability, algorithm string) string {
+	rule := vuln.Type
+	if rule == "" {
+		rule = vuln.Title
+	}
+	var components []string
+	switch {
+	case vuln.CVE != "":
+		components = []string{"dependency", strings.ToLower(vuln.Code), strings.ToUpper(vuln.CVE)}
+	case vuln.File == "":
+		components = []string{"tool", vuln.SecurityTool, vuln.Title, vuln.Details}
+	case algorithm == CodeHash && strings.TrimSpace(vuln.Code) != "":
+		codeHash := sha256.Sum256([]byte(strings.Join(strings.Fields(vuln.Code), " ")))
+		components = []string{"codehash", vuln.File, rule, hex.EncodeToString(codeHash[:])}
+	case algorithm == NoLine || algorithm == CodeHash:
+		components = []string{"noline", vuln.File, rule}
+	default:
+		components = []string{"line", vuln.File, vuln.Line, rule}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(components, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Results sets the Fingerprint of every vulnerability of results using algorithm.
+func Results(results *types.HuskyCIResults, algorithm string) {
+	for _, output := range util.SecurityTestOutputs(results) {
+		for _, vulns := range [][]types.HuskyCIVulnerability{output.NoSecVulns, output.LowVulns, output.MediumVulns, output.HighVulns, output.AcceptedVulns} {
+			for i := range vulns {
+				vulns[i].Fingerprint = Vulnerability(vulns[i], algorithm)
+			}
+		}
+	}
+}
+
+// Compare returns the findings of head added, removed and unchanged since base, the vulnerabilities
+// of high, medium and low severity told apart by their fingerprint. The vulnerabilities stored without one
+// are fingerprinted with algorithm. Unchanged findings are the ones of head, with their current severity.
+func Compare(base, head types.HuskyCIResults, algorithm string) types.AnalysisComparison {
+	type finding struct {
+		vuln        types.HuskyCIVulnerability
+		severity    string
+		fingerprint string
+	}
+	findings := func(results types.HuskyCIResults) []finding {
+		found := []finding{}
+		for _, output := range util.SecurityTestOutputs(&results) {
+			for _, bucket := range []struct {
+				severity string
+				vulns    []types.HuskyCIVulnerability
+			}{{"high", output.HighVulns}, {"medium", output.MediumVulns}, {"low", output.LowVulns}} {
+				for _, vuln := range bucket.vulns {
+					id := vuln.Fingerprint
+					if id == "" {
+						id = Vulnerability(vuln, algorithm)
+					}
+					found = append(found, finding{vuln, bucket.severity, id})
+				}
+			}
+		}
+		return found
+	}
+	newCount := func() types.ComparisonCount {
+		return types.ComparisonCount{BySeverity: map[string]int{"high": 0, "medium": 0, "low": 0}}
+	}
+	comparison := types.AnalysisComparison{
+		Added:     []types.HuskyCIVulnerability{},
+		Removed:   []types.HuskyCIVulnerability{},
+		Unchanged: []types.HuskyCIVulnerability{},
+		Summary:   types.ComparisonSummary{Added: newCount(), Removed: newCount(), Unchanged: newCount()},
+	}
+
+	// findings sharing a fingerprint are matched one to one, so that a new occurrence is still added.
+	baseFingerprints := make(map[string]int)
+	for _, f := range findings(base) {
+		baseFingerprints[f.fingerprint]++
+	}
+	for _, f := range findings(head) {
+		if baseFingerprints[f.fingerprint] > 0 {
+			baseFingerprints[f.fingerprint]--
+			comparison.Unchanged = append(comparison.Unchanged, f.vuln)
+			comparison.Summary.Unchanged.Total++
+			comparison.Summary.Unchanged.BySeverity[f.severity]++
+			continue
+		}
+		comparison.Added = append(comparison.Added, f.vuln)
+		comparison.Summary.Added.Total++
+		comparison.Summary.Added.BySeverity[f.severity]++
+	}
+	for _, f := range findings(base) {
+		if baseFingerprints[f.fingerprint] > 0 {
+			baseFingerprints[f.fingerprint]--
+			comparison.Removed = append(comparison.Removed, f.vuln)
+			comparison.Summary.Removed.Total++
+			comparison.Summary.Removed.BySeverity[f.severity]++
+		}
+	}
+	return comparison
+}
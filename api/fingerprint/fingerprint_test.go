@@ -0,0 +1,111 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fingerprint_test
+
+import (
+	"github.com/globocom/huskyCI/api/fingerprint"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fingerprint", func() {
+
+	Describe("Vulnerability", func() {
+		vuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", File: "main.go", Line: "10", Type: "G101", Code: "password := \"hunter2\""}
+		shiftedVuln := vuln
+		shiftedVuln.Line = "14"
+		reindentedVuln := shiftedVuln
+		reindentedVuln.Code = "\tpassword   := \"hunter2\""
+		changedVuln := shiftedVuln
+		changedVuln.Code = "password := \"correct horse\""
+
+		Context("When the algorithm is line", func() {
+			It("Should return the same fingerprint for the same finding.", func() {
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.Line)).To(Equal(fingerprint.Vulnerability(vuln, fingerprint.Line)))
+			})
+			It("Should return a different fingerprint when the line shifts.", func() {
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.Line)).NotTo(Equal(fingerprint.Vulnerability(shiftedVuln, fingerprint.Line)))
+			})
+		})
+		Context("When the algorithm is noline", func() {
+			It("Should return the same fingerprint when the line shifts.", func() {
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.NoLine)).To(Equal(fingerprint.Vulnerability(shiftedVuln, fingerprint.NoLine)))
+			})
+			It("Should return a different fingerprint for another rule in the same file.", func() {
+				otherVuln := shiftedVuln
+				otherVuln.Type = "G104"
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.NoLine)).NotTo(Equal(fingerprint.Vulnerability(otherVuln, fingerprint.NoLine)))
+			})
+		})
+		Context("When the algorithm is codehash", func() {
+			It("Should return the same fingerprint when the line shifts.", func() {
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.CodeHash)).To(Equal(fingerprint.Vulnerability(shiftedVuln, fingerprint.CodeHash)))
+			})
+			It("Should ignore whitespace changes in the code snippet.", func() {
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.CodeHash)).To(Equal(fingerprint.Vulnerability(reindentedVuln, fingerprint.CodeHash)))
+			})
+			It("Should return a different fingerprint when the code snippet changes.", func() {
+				Expect(fingerprint.Vulnerability(vuln, fingerprint.CodeHash)).NotTo(Equal(fingerprint.Vulnerability(changedVuln, fingerprint.CodeHash)))
+			})
+			It("Should fall back to noline when there is no code snippet.", func() {
+				noCodeVuln := vuln
+				noCodeVuln.Code = ""
+				Expect(fingerprint.Vulnerability(noCodeVuln, fingerprint.CodeHash)).To(Equal(fingerprint.Vulnerability(noCodeVuln, fingerprint.NoLine)))
+			})
+		})
+		Context("When the vulnerability is about a package and CVE", func() {
+			It("Should return the same fingerprint regardless of the algorithm.", func() {
+				depVuln := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", File: "package-lock.json", Line: "120", Code: "lodash", CVE: "CVE-2019-10744"}
+				shiftedDepVuln := depVuln
+				shiftedDepVuln.Line = "240"
+				for _, algorithm := range []string{fingerprint.Line, fingerprint.NoLine, fingerprint.CodeHash} {
+					Expect(fingerprint.Vulnerability(depVuln, algorithm)).To(Equal(fingerprint.Vulnerability(shiftedDepVuln, fingerprint.Line)), algorithm)
+				}
+			})
+		})
+	})
+
+	Describe("Results", func() {
+		It("Should set the fingerprint of every vulnerability.", func() {
+			results := types.HuskyCIResults{}
+			results.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{{File: "main.go", Line: "10", Type: "G101"}}
+			results.GenericResults.HuskyCIGitleaksOutput.LowVulns = []types.HuskyCIVulnerability{{File: "config.yml", Line: "1", Title: "AWS secret key"}}
+			fingerprint.Results(&results, fingerprint.NoLine)
+			gosecVuln := results.GoResults.HuskyCIGosecOutput.HighVulns[0]
+			gitleaksVuln := results.GenericResults.HuskyCIGitleaksOutput.LowVulns[0]
+			Expect(gosecVuln.Fingerprint).To(Equal(fingerprint.Vulnerability(gosecVuln, fingerprint.NoLine)))
+			Expect(gitleaksVuln.Fingerprint).To(Equal(fingerprint.Vulnerability(gitleaksVuln, fingerprint.NoLine)))
+			Expect(gosecVuln.Fingerprint).NotTo(Equal(gitleaksVuln.Fingerprint))
+		})
+	})
+
+	Describe("Compare", func() {
+		It("Should match the findings one to one by fingerprint, fingerprinting the ones stored without one.", func() {
+			unfingerprinted := types.HuskyCIVulnerability{File: "main.go", Line: "10", Type: "G101"}
+			stored := unfingerprinted
+			stored.Fingerprint = fingerprint.Vulnerability(stored, fingerprint.Line)
+			occurrence := types.HuskyCIVulnerability{File: "util.go", Line: "3", Type: "G104", Fingerprint: "occurrence"}
+			nosec := types.HuskyCIVulnerability{File: "nosec.go", Line: "1", Type: "G404", Fingerprint: "nosec"}
+
+			base := types.HuskyCIResults{}
+			base.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{unfingerprinted}
+			base.GoResults.HuskyCIGosecOutput.LowVulns = []types.HuskyCIVulnerability{occurrence}
+			head := types.HuskyCIResults{}
+			head.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{stored}
+			head.GoResults.HuskyCIGosecOutput.LowVulns = []types.HuskyCIVulnerability{occurrence, occurrence}
+			head.GoResults.HuskyCIGosecOutput.NoSecVulns = []types.HuskyCIVulnerability{nosec}
+
+			comparison := fingerprint.Compare(base, head, fingerprint.Line)
+			Expect(comparison.Unchanged).To(Equal([]types.HuskyCIVulnerability{stored, occurrence}))
+			Expect(comparison.Added).To(Equal([]types.HuskyCIVulnerability{occurrence}))
+			Expect(comparison.Removed).To(BeEmpty())
+			Expect(comparison.Summary.Unchanged).To(Equal(types.ComparisonCount{Total: 2, BySeverity: map[string]int{"high": 1, "medium": 0, "low": 1}}))
+			Expect(comparison.Summary.Added).To(Equal(types.ComparisonCount{Total: 1, BySeverity: map[string]int{"high": 0, "medium": 0, "low": 1}}))
+			Expect(comparison.Summary.Removed.Total).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,108 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FormatGraylog sends logs to Graylog using glbgelf.
+	FormatGraylog = "graylog"
+	// FormatJSON writes logs as one JSON object per line.
+	FormatJSON = "json"
+	// FormatConsole writes logs in a human readable format, for local runs.
+	FormatConsole = "console"
+)
+
+// JSONLogger writes each log as a JSON object in a single line, holding its time, level, msg
+// and every extra field, such as action, info, analysisID, repo and securityTest.
+type JSONLogger struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewJSONLogger returns a JSONLogger writing to writer.
+func NewJSONLogger(writer io.Writer) *JSONLogger {
+	return &JSONLogger{writer: writer, now: time.Now}
+}
+
+// SendLog writes a log as a JSON object.
+func (l *JSONLogger) SendLog(extra map[string]interface{}, loglevel string, messages ...interface{}) error {
+	entry := make(map[string]interface{}, len(extra)+3)
+	for k, v := range extra {
+		entry[k] = v
+	}
+	entry["time"] = l.now().UTC().Format(time.RFC3339)
+	entry["level"] = loglevel
+	entry["msg"] = formatMessages(messages)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, err = l.writer.Write(append(line, '\n'))
+	return err
+}
+
+// ConsoleLogger writes each log as a human readable line, such as:
+// 2019-10-14T12:00:00Z INFO [ANALYSIS] StartAnalysis: Analysis started: 42 analysisID=42 repo=https://github.com/globocom/huskyCI.git
+type ConsoleLogger struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewConsoleLogger returns a ConsoleLogger writing to writer.
+func NewConsoleLogger(writer io.Writer) *ConsoleLogger {
+	return &ConsoleLogger{writer: writer, now: time.Now}
+}
+
+// SendLog writes a log as a human readable line.
+func (l *ConsoleLogger) SendLog(extra map[string]interface{}, loglevel string, messages ...interface{}) error {
+	line := fmt.Sprintf("%s %s [%v] %v: %s", l.now().UTC().Format(time.RFC3339), loglevel, extra["info"], extra["action"], formatMessages(messages))
+
+	keys := []string{}
+	for k := range extra {
+		if k != "action" && k != "info" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		line += fmt.Sprintf(" %s=%v", k, extra[k])
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, err := fmt.Fprintln(l.writer, line)
+	return err
+}
+
+// formatMessages joins messages, including the ones inside nested slices, into a single string.
+func formatMessages(messages []interface{}) string {
+	parts := []string{}
+	for _, message := range messages {
+		var part string
+		if nested, ok := message.([]interface{}); ok {
+			part = formatMessages(nested)
+		} else {
+			part = strings.TrimSpace(fmt.Sprint(message))
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
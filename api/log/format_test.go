@@ -0,0 +1,60 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/globocom/huskyCI/api/log"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewJSONLogger(&buf)
+
+	extra := map[string]interface{}{"action": "StartAnalysis", "info": "ANALYSIS", "analysisID": "42", "repo": "https://github.com/globocom/huskyCI.git", "securityTest": "gosec"}
+	if err := logger.SendLog(extra, "INFO", "Analysis started: ", []interface{}{"42"}); err != nil {
+		t.Fatalf("expected no error, but got %s", err)
+	}
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("expected a single line, but got %q", buf.String())
+	}
+	got := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected a JSON object, but got %q: %s", buf.String(), err)
+	}
+	want := map[string]string{
+		"level":        "INFO",
+		"msg":          "Analysis started: 42",
+		"action":       "StartAnalysis",
+		"info":         "ANALYSIS",
+		"analysisID":   "42",
+		"repo":         "https://github.com/globocom/huskyCI.git",
+		"securityTest": "gosec",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("in %s key, we expected %s; but got %v", key, value, got[key])
+		}
+	}
+	if _, ok := got["time"]; !ok {
+		t.Error("expected a time key, but there was none")
+	}
+}
+
+func TestConsoleLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewConsoleLogger(&buf)
+
+	extra := map[string]interface{}{"action": "analyzeGosec", "info": "GOSEC", "analysisID": "42", "securityTest": "gosec"}
+	if err := logger.SendLog(extra, "ERROR", "Could not Unmarshall the following gosecOutput: ", []interface{}{"output", "invalid JSON"}); err != nil {
+		t.Fatalf("expected no error, but got %s", err)
+	}
+
+	want := " ERROR [GOSEC] analyzeGosec: Could not Unmarshall the following gosecOutput: output invalid JSON analysisID=42 securityTest=gosec\n"
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("expected a line ending with %q, but got %q", want, buf.String())
+	}
+}
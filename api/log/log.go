@@ -6,6 +6,7 @@ package log
 
 import (
 	"log"
+	"os"
 
 	"github.com/globocom/glbgelf"
 )
@@ -26,36 +27,89 @@ func InitLog(developmentEnv bool, address, protocol, appName, tag string) {
 	Logger = glbgelf.Logger
 }
 
-// Info sends an info type log using glbgelf.
-func Info(action, info string, msgCode int, message ...interface{}) {
-	if err := Logger.SendLog(map[string]interface{}{
-		"action": action,
-		"info":   info},
-		"INFO", MsgCode[msgCode], message); err != nil {
-		ErrorGlbgelf(err)
+// InitLogFormat starts logging in the given format: FormatJSON and FormatConsole write to stdout,
+// while any other format starts glbgelf logging as InitLog does.
+func InitLogFormat(format string, developmentEnv bool, address, protocol, appName, tag string) {
+	switch format {
+	case FormatJSON:
+		Logger = NewJSONLogger(os.Stdout)
+	case FormatConsole:
+		Logger = NewConsoleLogger(os.Stdout)
+	default:
+		InitLog(developmentEnv, address, protocol, appName, tag)
 	}
 }
 
-// Warning sends a warning type log using glbgelf.
-func Warning(action, info string, msgCode int, message ...interface{}) {
-	if err := Logger.SendLog(map[string]interface{}{
-		"action": action,
-		"info":   info},
-		"WARNING", MsgCode[msgCode], message); err != nil {
-		ErrorGlbgelf(err)
+// Entry sends logs along with correlation fields, such as the ID of the analysis they belong to,
+// so that every log of an analysis can be found together.
+type Entry struct {
+	fields map[string]interface{}
+}
+
+// ForAnalysis returns an Entry that correlates logs with the analysis of RID on repositoryURL.
+func ForAnalysis(RID, repositoryURL string) Entry {
+	return Entry{}.with("analysisID", RID).with("repo", repositoryURL)
+}
+
+// WithSecurityTest returns a copy of e that also correlates logs with securityTestName.
+func (e Entry) WithSecurityTest(securityTestName string) Entry {
+	return e.with("securityTest", securityTestName)
+}
+
+func (e Entry) with(key, value string) Entry {
+	fields := make(map[string]interface{}, len(e.fields)+1)
+	for k, v := range e.fields {
+		fields[k] = v
 	}
+	if value != "" {
+		fields[key] = value
+	}
+	return Entry{fields: fields}
 }
 
-// Error sends an error type log using glbgelf.
-func Error(action, info string, msgCode int, message ...interface{}) {
-	if err := Logger.SendLog(map[string]interface{}{
+// Info sends an info type log with the fields of e.
+func (e Entry) Info(action, info string, msgCode int, message ...interface{}) {
+	e.send("INFO", action, info, msgCode, message)
+}
+
+// Warning sends a warning type log with the fields of e.
+func (e Entry) Warning(action, info string, msgCode int, message ...interface{}) {
+	e.send("WARNING", action, info, msgCode, message)
+}
+
+// Error sends an error type log with the fields of e.
+func (e Entry) Error(action, info string, msgCode int, message ...interface{}) {
+	e.send("ERROR", action, info, msgCode, message)
+}
+
+func (e Entry) send(loglevel, action, info string, msgCode int, message []interface{}) {
+	extra := map[string]interface{}{
 		"action": action,
-		"info":   info},
-		"ERROR", MsgCode[msgCode], message); err != nil {
+		"info":   info,
+	}
+	for k, v := range e.fields {
+		extra[k] = v
+	}
+	if err := Logger.SendLog(extra, loglevel, MsgCode[msgCode], message); err != nil {
 		ErrorGlbgelf(err)
 	}
 }
 
+// Info sends an info type log.
+func Info(action, info string, msgCode int, message ...interface{}) {
+	Entry{}.send("INFO", action, info, msgCode, message)
+}
+
+// Warning sends a warning type log.
+func Warning(action, info string, msgCode int, message ...interface{}) {
+	Entry{}.send("WARNING", action, info, msgCode, message)
+}
+
+// Error sends an error type log.
+func Error(action, info string, msgCode int, message ...interface{}) {
+	Entry{}.send("ERROR", action, info, msgCode, message)
+}
+
 // ErrorGlbgelf handles glbgelf error.
 func ErrorGlbgelf(err error) {
 	log.Println(err)
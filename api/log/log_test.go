@@ -163,6 +163,33 @@ func TestLog(t *testing.T) {
 
 }
 
+func TestForAnalysis(t *testing.T) {
+	stub := &stubLogger{}
+	log.Logger = stub
+
+	log.ForAnalysis("42", "https://github.com/globocom/huskyCI.git").WithSecurityTest("gosec").Info("action", "info", 11, "got some info!")
+
+	extra := stub.calledWith["extra"].(map[string]interface{})
+	want := map[string]string{
+		"action":       "action",
+		"info":         "info",
+		"analysisID":   "42",
+		"repo":         "https://github.com/globocom/huskyCI.git",
+		"securityTest": "gosec",
+	}
+	for key, value := range want {
+		if got, ok := extra[key]; !ok || got != value {
+			t.Errorf("in %s key, we expected %s; but got %v", key, value, got)
+		}
+	}
+
+	log.ForAnalysis("42", "").Error("action", "info", 11, "got some error!")
+	extra = stub.calledWith["extra"].(map[string]interface{})
+	if _, ok := extra["repo"]; ok {
+		t.Error("expected no repo key for an empty repository, but there was one")
+	}
+}
+
 type stubLogger struct {
 	calledWith map[string]interface{}
 	err        error
@@ -19,10 +19,17 @@ var MsgCode = map[int]string{
 	19: "SecurityTest upserted in MondoDB: ",
 	20: "Default User found in MongoDB.",
 	24: "URL received to generate a new token: ",
+	25: "Returning the cached analysis of the following commit: ",
+	26: "Repository deleted with all its access tokens: ",
+	27: "Shutting down, waiting for the running analyses up to: ",
+	28: "Shutdown finished.",
+	29: "Self-test finished, all components passed: ",
+	30: "Webhook ignored, as it is not a push to a branch: ",
 
 	// HuskyCI API warnings
 	101: "Analysis started: ",
 	102: "Analysis finished: ",
+	103: "Analysis resumed: ",
 	104: "An analysis is already in place for this URL: ",
 	105: "The following analysis timed out inside MonitorAnalysis: ",
 	106: "Analysis not found using the following RID: ",
@@ -32,6 +39,32 @@ var MsgCode = map[int]string{
 	110: "The following repository is already in MongoDB: ",
 	111: "Invalid user input for time range query string parameter: ",
 	112: "Invalid user input for metric type: ",
+	113: "Invalid user input for minConfidence query string parameter: ",
+	114: "Could not parse the output of the following securityTest: ",
+	115: "Raw output not found for the following securityTest: ",
+	116: "Could not rerun the following analysis, as it is still running: ",
+	117: "Received a token validation batch larger than the limit: ",
+	118: "A newer image is available upstream for the following securityTest: ",
+	119: "Access token requested for a repository outside the allowlist: ",
+	120: "Invalid user input for format query string parameter: ",
+	121: "Admin route requested by a user that is not an admin: ",
+	122: "Invalid user input for pagination query string parameters: ",
+	123: "Could not delete the following repository, as it is not registered: ",
+	124: "Analysis submission rejected, as huskyCI is shutting down: ",
+	125: "Analysis interrupted by the shutdown: ",
+	126: "Self-test failed for the following component: ",
+	127: "Webhook rejected, as its signature could not be verified: ",
+	128: "Received an invalid webhook payload: ",
+	129: "Analyses of uploaded archives can not be rerun: ",
+	130: "Could not build the Java project to run SpotBugs: ",
+	131: "Received an invalid accepted risk request: ",
+	132: "Received an invalid reevaluation policy: ",
+	133: "Could not create the index, with its collection and keys: ",
+	134: "Received an invalid analysis trend parameter: ",
+	135: "The analysis queue is full, rejected the analysis: ",
+	136: "Could not read the code snippets of the vulnerabilities from their container: ",
+	137: "Received an invalid analysis comparison: ",
+	138: "Received an invalid analysis list parameter: ",
 
 	// HuskyCI API errors
 	1001: "Error(s) found when starting HuskyCI API: ",
@@ -74,6 +107,46 @@ var MsgCode = map[int]string{
 	1038: "Could not Unmarshall the following gitleaksOutput: ",
 	1039: "Could not Unmarshall the following spotbugsOutput: ",
 	1040: "Could not Unmarshall the following tfsecOutput: ",
+	1041: "Received an invalid parse request JSON: ",
+	1042: "Received an invalid repository subpath: ",
+	1043: "Could not plan the analysis of the repository: ",
+	1044: "Could not Unmarshall the following hadolintOutput: ",
+	1045: "Could not post-process the results of the analysis with the following processor: ",
+	1046: "Received an invalid failFastSeverity: ",
+	1047: "Received an invalid repository commit: ",
+	1048: "Could not find the following commit in the repository: ",
+	1049: "Could not Unmarshall the following dependencyCheckOutput: ",
+	1050: "Internal error running Dependency-Check: ",
+	1051: "Received an invalid SSH private key.",
+	1052: "Received an invalid image reference: ",
+	1053: "Could not Unmarshal the following trivyOutput: ",
+	1054: "Internal error running Trivy: ",
+	1055: "Could not Unmarshal the following detektOutput: ",
+	1056: "Internal error running detekt: ",
+	1057: "Could not look up a cached analysis, starting a new one: ",
+	1058: "Could not list the registered repositories: ",
+	1059: "Could not delete the following repository: ",
+	1060: "Received an invalid repository base ref: ",
+	1061: "Could not find the following base ref in the repository: ",
+	1062: "Received invalid securityTest arguments: ",
+	1063: "Could not Unmarshal the following osvscannerOutput: ",
+	1064: "Internal error running osv-scanner: ",
+	1065: "Could not export the findings of the analysis to DefectDojo: ",
+	1066: "Could not remove the files uploaded for the analysis: ",
+	1067: "Received an invalid archive to analyze: ",
+	1068: "Could not store the uploaded archive: ",
+	1069: "Could not record the accepted risk: ",
+	1070: "Could not serve the gRPC API: ",
+	1071: "Received an unknown scan profile: ",
+	1072: "Could not re-evaluate the analysis: ",
+	1073: "Received an invalid securityTest environment: ",
+	1074: "Could not inject the securityTest environment: ",
+	1075: "Could not Unmarshal the following govulncheckOutput: ",
+	1076: "Internal error running govulncheck: ",
+	1077: "Could not set up the TLS configuration of the API: ",
+	1078: "Received invalid analysis tags: ",
+	1079: "Received a securityTestEnv referencing a secret not permitted for the repository: ",
+	1080: "Could not serve the public routes of the API: ",
 
 	// MongoDB infos
 	21: "Connecting to MongoDB.",
@@ -91,7 +164,7 @@ var MsgCode = map[int]string{
 	2001: "Error connecting to MongoDB: ",
 	2002: "Error pinging MongoDB after connection: ",
 	2003: "Error pinging MongoDB in autoReconnect: ",
-	2004: "Reconnect to MongoDB failed: ",
+	2004: "Reconnect to MongoDB failed, with the attempt, the backoff until the next one and the error: ",
 	2005: "Could not find default securityTests: ",
 	2006: "Could not find securityTestName: ",
 	2007: "Could not update AnalysisCollection: ",
@@ -105,6 +178,14 @@ var MsgCode = map[int]string{
 	2015: "Could not create a new repository: ",
 	2016: "Could not create a new securityTest: ",
 	2017: "Error running the MongoDB aggregation for the following metric: ",
+	2018: "Could not find running analyses to resume: ",
+	2019: "Could not set the following analysis as interrupted: ",
+	2020: "Could not set the following interrupted analysis as running again: ",
+	2021: "Could not find when the securityTest first ran for the repository: ",
+	2022: "Could not record when the securityTest first ran for the repository: ",
+	2023: "Could not find the accepted risks of the repository: ",
+	2024: "Could not find the current configuration of the securityTest: ",
+	2025: "Could not find the analysis of the leftover scan container: ",
 
 	// Docker API info
 	31: "Waiting pull image...",
@@ -113,6 +194,18 @@ var MsgCode = map[int]string{
 	34: "Container finished successfully: ",
 	35: "Container image has been pulled successfully: ",
 	36: "Container cOutput read sucessfully for CID: ",
+	37: "Checking for newer securityTest images every: ",
+	38: "Findings of the analysis exported to the following DefectDojo test: ",
+	39: "CA certificates trusted to clone repositories over HTTPS: ",
+	40: "Risk accepted, with the repository, the fingerprint, the user and the expiry: ",
+	41: "Starting the gRPC API on port: ",
+	42: "Analysis re-evaluated, with its stored and new results: ",
+	43: "Leftover scan container removed, with its CID and analysis: ",
+	44: "MongoDB index built, with its collection and keys: ",
+	45: "MongoDB index creation skipped, as the indexes are managed outside huskyCI.",
+	46: "Mutual TLS enabled, requiring client certificates issued by the CA certificates of: ",
+	47: "Analysis finished without scannable content, with the languages detected: ",
+	48: "Serving /healthcheck and /webhook/:provider without client certificates on port: ",
 
 	// Docker API warning
 	301: "",
@@ -143,6 +236,11 @@ var MsgCode = map[int]string{
 	3025: "Could not update listed containers: ",
 	3026: "Could not initialize default configurations: ",
 	3027: "Could not remove container via huskyCI: ",
+	3028: "Could not check for a newer image of the following securityTest: ",
+	3029: "Could not copy the uploaded files into the container: ",
+	3030: "The following image is not in the image allowlist: ",
+	3031: "Could not list the leftover scan containers: ",
+	3032: "Could not remove the leftover scan container: ",
 
 	// Util package errors
 	4001: "Could not read certificate file: ",
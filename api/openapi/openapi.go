@@ -0,0 +1,204 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the document returned by Document.
+const Version = "3.0.3"
+
+// Security schemes of the operations. BasicAuth is the credential of a huskyCI API user, while
+// HuskyToken is the access token of a repository, optional for repositories without any.
+const (
+	BasicAuth  = "basicAuth"
+	HuskyToken = "huskyToken"
+)
+
+// Operation documents a route of huskyCI API.
+type Operation struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	Security    string
+	Parameters  []Parameter
+	// RequestBody, when set, is a value of the type bound from the JSON body of the request.
+	RequestBody interface{}
+	// RequestForm, when set, are the fields of the multipart/form-data body of the request.
+	RequestForm []FormField
+	Responses   map[int]Response
+}
+
+// FormField documents a field of a multipart/form-data request body.
+type FormField struct {
+	Name        string
+	Description string
+	Required    bool
+	// File tells whether the field holds the content of a file.
+	File bool
+}
+
+// Parameter documents a path, query or header parameter of an operation.
+type Parameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+}
+
+// Response documents a response of an operation.
+type Response struct {
+	Description string
+	// Body, when set, is a value of the type replied as JSON.
+	Body interface{}
+	// ContentType, when set, is the media type of a body that is not JSON.
+	ContentType string
+	// RequestID tells whether the X-Request-ID header holds the RID of the analysis.
+	RequestID bool
+}
+
+// Reply is the body of most responses, telling whether the request succeeded or why it did not.
+type Reply struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// AnalysisReply is the body of the responses of the routes starting analyses.
+type AnalysisReply struct {
+	Success          bool   `json:"success"`
+	Error            string `json:"error"`
+	RID              string `json:"RID,omitempty"`
+	Cached           bool   `json:"cached,omitempty"`
+	OriginAnalysisID string `json:"originAnalysisID,omitempty"`
+	Ignored          bool   `json:"ignored,omitempty"`
+}
+
+// Document returns the OpenAPI document of operations, whose request and response bodies are
+// described by the schemas of their Go types.
+func Document(apiVersion string, operations []Operation) map[string]interface{} {
+	generator := newSchemaGenerator()
+	paths := make(map[string]interface{})
+	for _, operation := range operations {
+		pathItem, ok := paths[operation.Path].(map[string]interface{})
+		if !ok {
+			pathItem = make(map[string]interface{})
+			paths[operation.Path] = pathItem
+		}
+		pathItem[strings.ToLower(operation.Method)] = generator.operation(operation)
+	}
+	return map[string]interface{}{
+		"openapi": Version,
+		"info": map[string]interface{}{
+			"title":       "huskyCI API",
+			"description": "huskyCI runs security tests inside CI pipelines.",
+			"version":     apiVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": generator.components,
+			"securitySchemes": map[string]interface{}{
+				BasicAuth:  map[string]interface{}{"type": "http", "scheme": "basic"},
+				HuskyToken: map[string]interface{}{"type": "apiKey", "in": "header", "name": "Husky-Token"},
+			},
+		},
+	}
+}
+
+func (g *schemaGenerator) operation(operation Operation) map[string]interface{} {
+	item := map[string]interface{}{
+		"operationId": operation.OperationID,
+		"summary":     operation.Summary,
+		"tags":        []string{operation.Tag},
+	}
+	switch operation.Security {
+	case BasicAuth:
+		item["security"] = []map[string][]string{{BasicAuth: {}}}
+	case HuskyToken:
+		item["security"] = []map[string][]string{{HuskyToken: {}}, {}}
+	}
+	if len(operation.Parameters) > 0 {
+		parameters := []map[string]interface{}{}
+		for _, parameter := range operation.Parameters {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        parameter.Name,
+				"in":          parameter.In,
+				"description": parameter.Description,
+				"required":    parameter.Required,
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+		item["parameters"] = parameters
+	}
+	if operation.RequestBody != nil {
+		item["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  g.content("application/json", operation.RequestBody),
+		}
+	}
+	if len(operation.RequestForm) > 0 {
+		item["requestBody"] = formBody(operation.RequestForm)
+	}
+	responses := make(map[string]interface{})
+	for status, response := range operation.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(status)
+		}
+		responseItem := map[string]interface{}{"description": description}
+		if response.ContentType != "" {
+			responseItem["content"] = map[string]interface{}{
+				response.ContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+			}
+		} else if response.Body != nil {
+			responseItem["content"] = g.content("application/json", response.Body)
+		}
+		if response.RequestID {
+			responseItem["headers"] = map[string]interface{}{
+				"X-Request-ID": map[string]interface{}{
+					"description": "RID of the analysis.",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}
+		}
+		responses[strconv.Itoa(status)] = responseItem
+	}
+	item["responses"] = responses
+	return item
+}
+
+func formBody(fields []FormField) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, field := range fields {
+		property := map[string]interface{}{"type": "string", "description": field.Description}
+		if field.File {
+			property["format"] = "binary"
+		}
+		properties[field.Name] = property
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return map[string]interface{}{
+		"required": true,
+		"content":  map[string]interface{}{"multipart/form-data": map[string]interface{}{"schema": schema}},
+	}
+}
+
+func (g *schemaGenerator) content(mediaType string, body interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		mediaType: map[string]interface{}{"schema": g.schema(reflect.TypeOf(body))},
+	}
+}
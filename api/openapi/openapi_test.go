@@ -0,0 +1,88 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/openapi"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// routePattern matches the routes registered, and not commented out, in server.go.
+var routePattern = regexp.MustCompile(`(?m)^\s*(g|echoInstance)\.(GET|POST|PUT|DELETE)\("([^"]*)"`)
+
+var pathParamPattern = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+func registeredRoutes() []string {
+	server, err := ioutil.ReadFile("../server.go")
+	Expect(err).NotTo(HaveOccurred())
+	routes := []string{}
+	for _, match := range routePattern.FindAllStringSubmatch(string(server), -1) {
+		path := pathParamPattern.ReplaceAllString(match[3], "{$1}")
+		if match[1] == "g" {
+			path = "/api/1.0" + path
+		}
+		routes = append(routes, match[2]+" "+path)
+	}
+	return routes
+}
+
+var _ = Describe("OpenAPI", func() {
+
+	Context("When the operations are compared with the routes of the server", func() {
+		It("Should document every route and nothing else", func() {
+			documented := []string{}
+			for _, operation := range openapi.Operations {
+				documented = append(documented, operation.Method+" "+operation.Path)
+			}
+			Expect(documented).To(ConsistOf(registeredRoutes()))
+		})
+	})
+
+	Context("When the document is generated", func() {
+		document := openapi.Document("0.1.0", openapi.Operations)
+		rawDocument, err := json.Marshal(document)
+		decoded := map[string]interface{}{}
+
+		It("Should be valid JSON of the OpenAPI version and the API version", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(rawDocument, &decoded)).To(Succeed())
+			Expect(decoded["openapi"]).To(Equal(openapi.Version))
+			Expect(decoded["info"]).To(HaveKeyWithValue("version", "0.1.0"))
+		})
+		It("Should describe both security schemes", func() {
+			Expect(json.Unmarshal(rawDocument, &decoded)).To(Succeed())
+			schemes := decoded["components"].(map[string]interface{})["securitySchemes"].(map[string]interface{})
+			Expect(schemes[openapi.BasicAuth]).To(HaveKeyWithValue("scheme", "basic"))
+			Expect(schemes[openapi.HuskyToken]).To(HaveKeyWithValue("name", "Husky-Token"))
+		})
+		It("Should describe the analysis and token payloads by their json tags", func() {
+			Expect(json.Unmarshal(rawDocument, &decoded)).To(Succeed())
+			schemas := decoded["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+			repository := schemas["Repository"].(map[string]interface{})["properties"].(map[string]interface{})
+			Expect(repository).To(HaveKey("repositoryURL"))
+			Expect(repository).To(HaveKey("repositoryBranch"))
+			Expect(schemas["AccessToken"].(map[string]interface{})["properties"]).To(HaveKey("huskytoken"))
+			Expect(schemas).To(HaveKey("TokenRequest"))
+			Expect(schemas).To(HaveKey("Analysis"))
+		})
+		It("Should document the status codes, security and headers of starting an analysis", func() {
+			Expect(json.Unmarshal(rawDocument, &decoded)).To(Succeed())
+			post := decoded["paths"].(map[string]interface{})["/analysis"].(map[string]interface{})["post"].(map[string]interface{})
+			Expect(post["security"]).To(ContainElement(HaveKey(openapi.HuskyToken)))
+			responses := post["responses"].(map[string]interface{})
+			Expect(responses).To(HaveKey("201"))
+			Expect(responses).To(HaveKey("409"))
+			Expect(responses["201"]).To(HaveKeyWithValue("headers", HaveKey("X-Request-ID")))
+			Expect(strings.Contains(string(rawDocument), `"$ref":"#/components/schemas/Repository"`)).To(BeTrue())
+		})
+	})
+})
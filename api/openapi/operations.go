@@ -0,0 +1,420 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"net/http"
+
+	docker "github.com/globocom/huskyCI/api/dockers"
+	"github.com/globocom/huskyCI/api/selftest"
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// TokenValidationReply is the body of the response of a batch token validation.
+type TokenValidationReply struct {
+	Success bool                          `json:"success"`
+	Error   string                        `json:"error"`
+	Results []types.TokenValidationResult `json:"results"`
+}
+
+// ParseReply is the body of the response of a securityTest output parsed without an analysis.
+type ParseReply struct {
+	Success         bool                            `json:"success"`
+	Vulnerabilities types.HuskyCISecurityTestOutput `json:"vulnerabilities"`
+}
+
+// ImageUpdatesReply is the body of the response listing the last image update check of each securityTest.
+type ImageUpdatesReply struct {
+	Success bool                 `json:"success"`
+	Updates []docker.ImageUpdate `json:"updates"`
+}
+
+// AcceptedRiskReply is the body of the response of an accepted risk recorded.
+type AcceptedRiskReply struct {
+	Success      bool               `json:"success"`
+	Error        string             `json:"error"`
+	AcceptedRisk types.AcceptedRisk `json:"acceptedRisk"`
+}
+
+// AcceptedRisksReply is the body of the response listing the accepted risks of a repository.
+type AcceptedRisksReply struct {
+	Success       bool                 `json:"success"`
+	Error         string               `json:"error"`
+	AcceptedRisks []types.AcceptedRisk `json:"acceptedRisks"`
+}
+
+// SelfTestReply is the body of the response of an admin self-test.
+type SelfTestReply struct {
+	Success bool             `json:"success"`
+	Checks  []selftest.Check `json:"checks"`
+}
+
+func pathParameter(name, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true}
+}
+
+func queryParameter(name, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description}
+}
+
+var (
+	badRequest     = Response{Description: "Invalid request.", Body: Reply{}}
+	unauthorized   = Response{Description: "Missing or invalid credentials."}
+	forbidden      = Response{Description: "Admin credential required.", Body: Reply{}}
+	internalError  = Response{Body: Reply{}}
+	permission     = Response{Description: "Permission denied for the Husky-Token given.", Body: Reply{}}
+	notFound       = Response{Body: Reply{}}
+	shuttingDown   = Response{Description: "huskyCI is shutting down and does not start new analyses, or its database is unreachable.", Body: Reply{}}
+	unavailable    = Response{Description: "The database of huskyCI is unreachable.", Body: Reply{}}
+	queueFull      = Response{Description: "The analysis queue is full. The Retry-After header tells when to submit it again.", Body: Reply{}}
+	analysisIDPath = pathParameter("id", "RID of the analysis.")
+)
+
+// Operations documents every route served by huskyCI API. It must be changed together with the
+// routes registered by the server.
+var Operations = []Operation{
+	{
+		Method: http.MethodPost, Path: "/api/1.0/token", OperationID: "HandleToken", Tag: "token", Security: BasicAuth,
+		Summary:     "Generates an access token for a repository of the allowlist.",
+		RequestBody: types.TokenRequest{},
+		Responses: map[int]Response{
+			http.StatusCreated:             {Body: types.AccessToken{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusForbidden:           {Description: "Repository not permitted.", Body: Reply{}},
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/1.0/token/deactivate", OperationID: "HandleDeactivation", Tag: "token", Security: BasicAuth,
+		Summary:     "Deactivates an access token.",
+		RequestBody: types.AccessToken{},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: Reply{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/1.0/token/validate", OperationID: "HandleTokenValidation", Tag: "token", Security: BasicAuth,
+		Summary:     "Validates a batch of access token and repository URL pairs.",
+		RequestBody: types.TokenValidationBatch{},
+		Responses: map[int]Response{
+			http.StatusOK:                 {Body: TokenValidationReply{}},
+			http.StatusBadRequest:         badRequest,
+			http.StatusUnauthorized:       unauthorized,
+			http.StatusServiceUnavailable: unavailable,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/1.0/securitytest/parse", OperationID: "ParseSecurityTestOutput", Tag: "securityTest", Security: BasicAuth,
+		Summary:     "Parses the raw output of a securityTest without running an analysis.",
+		RequestBody: types.ParseRequest{},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: ParseReply{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusNotFound:            {Description: "securityTest not found.", Body: Reply{}},
+			http.StatusUnprocessableEntity: {Description: "The output could not be parsed.", Body: Reply{}},
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/1.0/securitytest/updates", OperationID: "GetSecurityTestImageUpdates", Tag: "securityTest", Security: BasicAuth,
+		Summary: "Lists the last check for newer images of each securityTest.",
+		Responses: map[int]Response{
+			http.StatusOK:                 {Body: ImageUpdatesReply{}},
+			http.StatusUnauthorized:       unauthorized,
+			http.StatusServiceUnavailable: unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/1.0/repositories", OperationID: "ListRepositories", Tag: "repository", Security: BasicAuth,
+		Summary: "Lists a page of the registered repositories. Restricted to admins.",
+		Parameters: []Parameter{
+			queryParameter("page", "Page to list, starting from 1."),
+			queryParameter("pageSize", "Number of repositories of each page."),
+		},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.RegisteredRepositoryPage{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusForbidden:           forbidden,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/1.0/repositories", OperationID: "DeleteRepository", Tag: "repository", Security: BasicAuth,
+		Summary:    "Deletes a registered repository and all its access tokens. Restricted to admins.",
+		Parameters: []Parameter{{Name: "repositoryURL", In: "query", Description: "URL of the repository.", Required: true}},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: Reply{}},
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusForbidden:           forbidden,
+			http.StatusNotFound:            notFound,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/1.0/acceptedrisks", OperationID: "AcceptRisk", Tag: "acceptedRisk", Security: BasicAuth,
+		Summary:     "Accepts the risk of a vulnerability of a repository, so that it does not fail its analyses until the optional expiry.",
+		RequestBody: types.AcceptedRiskRequest{},
+		Responses: map[int]Response{
+			http.StatusCreated:             {Body: AcceptedRiskReply{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/1.0/acceptedrisks", OperationID: "ListAcceptedRisks", Tag: "acceptedRisk", Security: BasicAuth,
+		Summary:    "Lists every accepted risk of a repository, including the expired ones.",
+		Parameters: []Parameter{{Name: "repositoryURL", In: "query", Description: "URL of the repository.", Required: true}},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: AcceptedRisksReply{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/1.0/selftest", OperationID: "SelfTest", Tag: "admin", Security: BasicAuth,
+		Summary: "Checks DB, Docker, the registry and a sample securityTest run. Restricted to admins.",
+		Responses: map[int]Response{
+			http.StatusOK:                 {Body: SelfTestReply{}},
+			http.StatusUnauthorized:       unauthorized,
+			http.StatusForbidden:          forbidden,
+			http.StatusServiceUnavailable: {Description: "A component failed, or the database is unreachable.", Body: SelfTestReply{}},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/healthcheck", OperationID: "HealthCheck", Tag: "generic",
+		Summary: "Tells whether the API is up.",
+		Responses: map[int]Response{
+			http.StatusOK: {ContentType: "text/plain"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/version", OperationID: "GetAPIVersion", Tag: "generic",
+		Summary: "Returns the version and release date of the API.",
+		Responses: map[int]Response{
+			http.StatusOK: {Body: map[string]string{}},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", OperationID: "GetOpenAPI", Tag: "generic",
+		Summary: "Returns this document.",
+		Responses: map[int]Response{
+			http.StatusOK: {Body: map[string]interface{}{}},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/analysis", OperationID: "ReceiveRequest", Tag: "analysis", Security: HuskyToken,
+		Summary:     "Starts an analysis of a repository, or returns a cached one of the same commit.",
+		RequestBody: types.Repository{},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Description: "A cached analysis of the same commit.", Body: AnalysisReply{}, RequestID: true},
+			http.StatusCreated:             {Description: "The analysis was started.", Body: AnalysisReply{}, RequestID: true},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusConflict:            {Description: "An analysis of the repository is already running.", Body: Reply{}},
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  shuttingDown,
+			http.StatusTooManyRequests:     queueFull,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/analysis/upload", OperationID: "ReceiveUpload", Tag: "analysis", Security: HuskyToken,
+		Summary: "Starts an analysis of the files of an uploaded zip or tar.gz archive.",
+		RequestForm: []FormField{
+			{Name: "file", Description: "The zip or tar.gz archive to analyze.", Required: true, File: true},
+			{Name: "name", Description: "Name of the analyzed code, registered as upload://<name>. It defaults to the name of the archive."},
+		},
+		Responses: map[int]Response{
+			http.StatusCreated:               {Description: "The analysis was started.", Body: AnalysisReply{}, RequestID: true},
+			http.StatusBadRequest:            {Description: "The archive is invalid, unsupported or has files out of its root.", Body: Reply{}},
+			http.StatusUnauthorized:          permission,
+			http.StatusRequestEntityTooLarge: {Description: "The archive or its extracted files exceed their size limit.", Body: Reply{}},
+			http.StatusInternalServerError:   internalError,
+			http.StatusServiceUnavailable:    shuttingDown,
+			http.StatusTooManyRequests:       queueFull,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/analysis/plan", OperationID: "PlanAnalysis", Tag: "analysis", Security: HuskyToken,
+		Summary:     "Returns the securityTests an analysis of a repository would run, without running them.",
+		RequestBody: types.Repository{},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.AnalysisPlan{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/analysis", OperationID: "ListAnalyses", Tag: "analysis", Security: HuskyToken,
+		Summary: "Lists the last analyses of a repository, most recent first, with their status, tags and number of vulnerabilities.",
+		Parameters: []Parameter{
+			{Name: "repositoryURL", In: "query", Description: "URL of the repository.", Required: true},
+			queryParameter("repositoryBranch", "Branch the analyses are filtered by."),
+			queryParameter("tag", "KEY:VALUE tag the analyses are filtered by. It may be repeated to match several tags."),
+			queryParameter("limit", "Number of analyses returned, 10 by default and at most 100."),
+		},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.AnalysisList{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/analysis/trend", OperationID: "GetAnalysisTrend", Tag: "analysis", Security: HuskyToken,
+		Summary: "Returns the last analyses of a repository, oldest first, with their status and vulnerability counts by severity.",
+		Parameters: []Parameter{
+			{Name: "repositoryURL", In: "query", Description: "URL of the repository.", Required: true},
+			queryParameter("limit", "Number of analyses returned, 10 by default and at most 100."),
+			queryParameter("since", "Date or RFC 3339 time after which the analyses started."),
+			queryParameter("tag", "KEY:VALUE tag the analyses are filtered by. It may be repeated to match several tags."),
+		},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.AnalysisTrend{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/analysis/compare", OperationID: "CompareAnalyses", Tag: "analysis", Security: HuskyToken,
+		Summary: "Returns the findings added, removed and unchanged from a finished analysis to another one of the same repository, matched by their fingerprints.",
+		Parameters: []Parameter{
+			{Name: "base", In: "query", Description: "ID of the analysis compared to.", Required: true},
+			{Name: "head", In: "query", Description: "ID of the analysis compared.", Required: true},
+		},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.AnalysisComparison{}},
+			http.StatusBadRequest:          {Description: "Invalid request, or analyses of different repositories.", Body: Reply{}},
+			http.StatusUnauthorized:        permission,
+			http.StatusNotFound:            notFound,
+			http.StatusConflict:            {Description: "An analysis is still running.", Body: Reply{}},
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/analysis/{id}", OperationID: "GetAnalysis", Tag: "analysis", Security: HuskyToken,
+		Summary: "Returns an analysis. Its status code may be configured by the status and result of the analysis.",
+		Parameters: []Parameter{
+			analysisIDPath,
+			queryParameter("minConfidence", "Lowest confidence of the vulnerabilities returned."),
+			queryParameter("format", "codeclimate returns the vulnerabilities as a Code Climate report and cyclonedx the dependencies as a CycloneDX SBOM."),
+		},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.Analysis{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusNotFound:            notFound,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/analysis/{id}/output/{securityTestName}", OperationID: "GetAnalysisOutput", Tag: "analysis", Security: HuskyToken,
+		Summary:    "Returns the raw output of a securityTest of an analysis.",
+		Parameters: []Parameter{analysisIDPath, pathParameter("securityTestName", "Name of the securityTest.")},
+		Responses: map[int]Response{
+			http.StatusOK:                  {ContentType: "application/octet-stream"},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusNotFound:            notFound,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/analysis/{id}/rerun", OperationID: "RerunAnalysis", Tag: "analysis", Security: HuskyToken,
+		Summary:    "Starts a new analysis with the parameters of a previous one.",
+		Parameters: []Parameter{analysisIDPath},
+		Responses: map[int]Response{
+			http.StatusCreated:             {Body: AnalysisReply{}, RequestID: true},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusNotFound:            notFound,
+			http.StatusConflict:            {Description: "An analysis of the repository is already running.", Body: Reply{}},
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  shuttingDown,
+			http.StatusTooManyRequests:     queueFull,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/analysis/{id}/reevaluate", OperationID: "ReevaluateAnalysis", Tag: "analysis", Security: HuskyToken,
+		Summary:     "Returns the verdict of a finished analysis under the current, or the given, severity threshold and overrides, recomputed from its stored findings.",
+		Parameters:  []Parameter{analysisIDPath},
+		RequestBody: types.ReevaluationPolicy{},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: types.Reevaluation{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        permission,
+			http.StatusNotFound:            notFound,
+			http.StatusConflict:            {Description: "The analysis is still running.", Body: Reply{}},
+			http.StatusInternalServerError: internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/webhook/{provider}", OperationID: "ReceiveWebhook", Tag: "analysis",
+		Summary:    "Starts an analysis from a push webhook signed with the configured webhook secret.",
+		Parameters: []Parameter{pathParameter("provider", "github or gitlab.")},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Description: "The webhook is not a push to a branch and was ignored.", Body: AnalysisReply{}},
+			http.StatusCreated:             {Body: AnalysisReply{}, RequestID: true},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        {Description: "Invalid webhook signature.", Body: Reply{}},
+			http.StatusNotFound:            {Description: "Webhook provider not found.", Body: Reply{}},
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  shuttingDown,
+			http.StatusTooManyRequests:     queueFull,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/stats/queue", OperationID: "GetQueueStats", Tag: "stats",
+		Summary: "Returns how many analyses are queued and running in the API process, and their limits.",
+		Responses: map[int]Response{
+			http.StatusOK: {Body: types.QueueStats{}},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/stats/{metric_type}", OperationID: "GetMetric", Tag: "stats",
+		Summary:    "Returns a metric of the analyses, filtered by its query string parameters.",
+		Parameters: []Parameter{pathParameter("metric_type", "Metric to return, such as language or container.")},
+		Responses: map[int]Response{
+			http.StatusOK:                  {Body: []interface{}{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/user", OperationID: "UpdateUser", Tag: "user",
+		Summary:     "Changes the password of a user.",
+		RequestBody: types.User{},
+		Responses: map[int]Response{
+			http.StatusCreated:             {Body: Reply{}},
+			http.StatusBadRequest:          badRequest,
+			http.StatusUnauthorized:        {Description: "Wrong password.", Body: Reply{}},
+			http.StatusNotFound:            notFound,
+			http.StatusInternalServerError: internalError,
+			http.StatusServiceUnavailable:  unavailable,
+		},
+	},
+}
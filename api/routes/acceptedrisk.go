@@ -0,0 +1,91 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+	"github.com/labstack/echo"
+	mgo "gopkg.in/mgo.v2"
+)
+
+const logInfoAcceptedRisk = "ACCEPTEDRISK"
+
+// fingerprintRegexp matches the fingerprints returned by fingerprint.Vulnerability.
+var fingerprintRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// AcceptRisk records that the API user accepts the risk of a vulnerability of a repository, identified by its
+// fingerprint, so that it does not fail the next analyses of the repository until the optional expiry.
+func AcceptRisk(c echo.Context) error {
+	request := types.AcceptedRiskRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Warning("AcceptRisk", logInfoAcceptedRisk, 131, err)
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid accepted risk JSON"})
+	}
+	repositoryURL, err := util.CheckMaliciousRepoURL(request.RepositoryURL)
+	if err != nil {
+		log.Warning("AcceptRisk", logInfoAcceptedRisk, 131, err)
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid repository URL"})
+	}
+	if !fingerprintRegexp.MatchString(request.Fingerprint) {
+		log.Warning("AcceptRisk", logInfoAcceptedRisk, 131, request.Fingerprint)
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid fingerprint"})
+	}
+	now := time.Now().UTC()
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(now) {
+			log.Warning("AcceptRisk", logInfoAcceptedRisk, 131, request.ExpiresAt.Format(time.RFC3339))
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "expiresAt must be in the future"})
+		}
+		expiresAt := request.ExpiresAt.UTC()
+		request.ExpiresAt = &expiresAt
+	}
+	username, _, _ := c.Request().BasicAuth()
+	acceptedRisk := types.AcceptedRisk{
+		RepositoryURL: util.RedactURLCredentials(repositoryURL),
+		Fingerprint:   request.Fingerprint,
+		AcceptedBy:    username,
+		AcceptedAt:    now,
+		ExpiresAt:     request.ExpiresAt,
+		Note:          request.Note,
+	}
+	acceptedRiskQuery := map[string]interface{}{"repositoryURL": acceptedRisk.RepositoryURL, "fingerprint": acceptedRisk.Fingerprint}
+	if err := apiContext.APIConfiguration.DBInstance.UpsertOneDBAcceptedRisk(acceptedRiskQuery, acceptedRisk); err != nil {
+		log.Error("AcceptRisk", logInfoAcceptedRisk, 1069, err)
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"success": false, "error": "internal error"})
+	}
+	expiry := "never"
+	if acceptedRisk.ExpiresAt != nil {
+		expiry = acceptedRisk.ExpiresAt.Format(time.RFC3339)
+	}
+	log.Info("AcceptRisk", logInfoAcceptedRisk, 40, acceptedRisk.RepositoryURL, acceptedRisk.Fingerprint, acceptedRisk.AcceptedBy, expiry)
+	return c.JSON(http.StatusCreated, map[string]interface{}{"success": true, "error": "", "acceptedRisk": acceptedRisk})
+}
+
+// ListAcceptedRisks returns every accepted risk of the repository given by the repositoryURL query string
+// parameter, including the expired ones, so that the decisions made on its vulnerabilities can be audited.
+func ListAcceptedRisks(c echo.Context) error {
+	repositoryURL, err := util.CheckMaliciousRepoURL(c.QueryParam("repositoryURL"))
+	if err != nil {
+		log.Warning("ListAcceptedRisks", logInfoAcceptedRisk, 131, err)
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid repository URL"})
+	}
+	acceptedRiskQuery := map[string]interface{}{"repositoryURL": util.RedactURLCredentials(repositoryURL)}
+	acceptedRisks, err := apiContext.APIConfiguration.DBInstance.FindAllDBAcceptedRisk(acceptedRiskQuery)
+	if err == mgo.ErrNotFound || (err != nil && err.Error() == "No data found") {
+		acceptedRisks, err = []types.AcceptedRisk{}, nil
+	}
+	if err != nil {
+		log.Error("ListAcceptedRisks", logInfoAcceptedRisk, 2023, err)
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"success": false, "error": "internal error"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": true, "error": "", "acceptedRisks": acceptedRisks})
+}
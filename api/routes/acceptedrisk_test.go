@@ -0,0 +1,147 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeAcceptedRiskDB struct {
+	db.Requests
+	acceptedRisks []types.AcceptedRisk
+}
+
+func (f *fakeAcceptedRiskDB) UpsertOneDBAcceptedRisk(mapParams map[string]interface{}, acceptedRisk types.AcceptedRisk) error {
+	f.acceptedRisks = append(f.acceptedRisks, acceptedRisk)
+	return nil
+}
+
+func (f *fakeAcceptedRiskDB) FindAllDBAcceptedRisk(mapParams map[string]interface{}) ([]types.AcceptedRisk, error) {
+	acceptedRisks := []types.AcceptedRisk{}
+	for _, acceptedRisk := range f.acceptedRisks {
+		if acceptedRisk.RepositoryURL == mapParams["repositoryURL"] {
+			acceptedRisks = append(acceptedRisks, acceptedRisk)
+		}
+	}
+	if len(acceptedRisks) == 0 {
+		return nil, errors.New("No data found")
+	}
+	return acceptedRisks, nil
+}
+
+var _ = Describe("Accepted risks", func() {
+
+	e := echo.New()
+	fingerprint := strings.Repeat("ab", 32)
+
+	var fakeDB *fakeAcceptedRiskDB
+	var previousConfig *apiContext.APIConfig
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		fakeDB = &fakeAcceptedRiskDB{}
+		apiContext.APIConfiguration = &apiContext.APIConfig{DBInstance: fakeDB}
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	accept := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/1.0/acceptedrisks", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.SetBasicAuth("husky", "password")
+		rec := httptest.NewRecorder()
+		Expect(routes.AcceptRisk(e.NewContext(req, rec))).To(Succeed())
+		return rec
+	}
+	list := func(repositoryURL string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/1.0/acceptedrisks?repositoryURL="+repositoryURL, nil)
+		rec := httptest.NewRecorder()
+		Expect(routes.ListAcceptedRisks(e.NewContext(req, rec))).To(Succeed())
+		return rec
+	}
+
+	Describe("AcceptRisk", func() {
+		Context("When the fingerprint is not valid", func() {
+			It("Should return bad request.", func() {
+				rec := accept(`{"repositoryURL": "https://github.com/globocom/huskyCI.git", "fingerprint": "B307"}`)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				Expect(rec.Body.String()).To(ContainSubstring("invalid fingerprint"))
+				Expect(fakeDB.acceptedRisks).To(BeEmpty())
+			})
+		})
+
+		Context("When the expiry is not in the future", func() {
+			It("Should return bad request.", func() {
+				rec := accept(`{"repositoryURL": "https://github.com/globocom/huskyCI.git", "fingerprint": "` + fingerprint + `", "expiresAt": "2020-01-01T00:00:00Z"}`)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				Expect(rec.Body.String()).To(ContainSubstring("expiresAt must be in the future"))
+				Expect(fakeDB.acceptedRisks).To(BeEmpty())
+			})
+		})
+
+		Context("When the request is valid", func() {
+			It("Should record who accepted the risk and until when.", func() {
+				expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+				rec := accept(`{"repositoryURL": "https://github.com/globocom/huskyCI.git", "fingerprint": "` + fingerprint + `", "expiresAt": "` + expiresAt.Format(time.RFC3339) + `", "note": "false positive"}`)
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				Expect(fakeDB.acceptedRisks).To(HaveLen(1))
+				acceptedRisk := fakeDB.acceptedRisks[0]
+				Expect(acceptedRisk.RepositoryURL).To(Equal("https://github.com/globocom/huskyCI.git"))
+				Expect(acceptedRisk.Fingerprint).To(Equal(fingerprint))
+				Expect(acceptedRisk.AcceptedBy).To(Equal("husky"))
+				Expect(acceptedRisk.AcceptedAt).To(BeTemporally("~", time.Now(), time.Minute))
+				Expect(acceptedRisk.ExpiresAt).NotTo(BeNil())
+				Expect(acceptedRisk.ExpiresAt.Equal(expiresAt)).To(BeTrue())
+				Expect(acceptedRisk.Note).To(Equal("false positive"))
+			})
+		})
+	})
+
+	Describe("ListAcceptedRisks", func() {
+		Context("When the repository has no accepted risk", func() {
+			It("Should return an empty list.", func() {
+				rec := list("https://github.com/globocom/huskyCI.git")
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Body.String()).To(MatchJSON(`{"success": true, "error": "", "acceptedRisks": []}`))
+			})
+		})
+
+		Context("When the repository has accepted risks", func() {
+			It("Should return them.", func() {
+				accept(`{"repositoryURL": "https://github.com/globocom/huskyCI.git", "fingerprint": "` + fingerprint + `"}`)
+				accept(`{"repositoryURL": "https://github.com/globocom/glbgelf.git", "fingerprint": "` + fingerprint + `"}`)
+				rec := list("https://github.com/globocom/huskyCI.git")
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				reply := struct {
+					AcceptedRisks []types.AcceptedRisk `json:"acceptedRisks"`
+				}{}
+				Expect(json.Unmarshal(rec.Body.Bytes(), &reply)).To(Succeed())
+				Expect(reply.AcceptedRisks).To(HaveLen(1))
+				Expect(reply.AcceptedRisks[0].AcceptedBy).To(Equal("husky"))
+				Expect(reply.AcceptedRisks[0].ExpiresAt).To(BeNil())
+			})
+		})
+
+		Context("When the repository URL is not valid", func() {
+			It("Should return bad request.", func() {
+				Expect(list("not-a-url").Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+})
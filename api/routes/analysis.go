@@ -5,15 +5,27 @@
 package routes
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/globocom/huskyCI/api/analysis"
 	"github.com/globocom/huskyCI/api/auth"
+	"github.com/globocom/huskyCI/api/branchpolicy"
+	"github.com/globocom/huskyCI/api/cache"
+	"github.com/globocom/huskyCI/api/containerenv"
 	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/fingerprint"
 	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/secrets"
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/tags"
 	"github.com/globocom/huskyCI/api/token"
 	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/upload"
 	"github.com/globocom/huskyCI/api/util"
 	"github.com/labstack/echo"
 	mgo "gopkg.in/mgo.v2"
@@ -37,8 +49,20 @@ func init() {
 
 const logActionReceiveRequest = "ReceiveRequest"
 const logActionGetAnalysis = "GetAnalysis"
+const logActionGetAnalysisOutput = "GetAnalysisOutput"
+const logActionPlanAnalysis = "PlanAnalysis"
+const logActionRerunAnalysis = "RerunAnalysis"
+const logActionListAnalyses = "ListAnalyses"
+const logActionGetAnalysisTrend = "GetAnalysisTrend"
+const logActionReevaluateAnalysis = "ReevaluateAnalysis"
+const logActionCompareAnalyses = "CompareAnalyses"
 const logInfoAnalysis = "ANALYSIS"
 
+const (
+	defaultAnalysisListLimit = 10
+	maxAnalysisListLimit     = 100
+)
+
 // GetAnalysis returns the status of a given analysis given a RID.
 func GetAnalysis(c echo.Context) error {
 
@@ -64,7 +88,80 @@ func GetAnalysis(c echo.Context) error {
 		reply := map[string]interface{}{"success": false, "error": "internal error"}
 		return c.JSON(http.StatusInternalServerError, reply)
 	}
-	return c.JSON(http.StatusOK, analysisResult)
+	if minConfidence := c.QueryParam("minConfidence"); minConfidence != "" {
+		filteredResults, err := util.FilterResultsByConfidence(analysisResult.HuskyCIResults, minConfidence, apiContext.APIConfiguration.DefaultConfidence)
+		if err != nil {
+			log.Warning(logActionGetAnalysis, logInfoAnalysis, 113, minConfidence)
+			reply := map[string]interface{}{"success": false, "error": "invalid minConfidence"}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+		analysisResult.HuskyCIResults = filteredResults
+	}
+	// analyses stored before their securityTestEnv was masked are masked as well.
+	analysisResult.SecurityTestEnv = containerenv.Mask(analysisResult.SecurityTestEnv)
+	if format := c.QueryParam("format"); format != "" {
+		var report []byte
+		switch format {
+		case "codeclimate":
+			report, err = securitytest.ToCodeClimate(analysisResult.HuskyCIResults)
+		case "cyclonedx":
+			report, err = securitytest.ToCycloneDX(analysisResult)
+		default:
+			log.Warning(logActionGetAnalysis, logInfoAnalysis, 120, format)
+			reply := map[string]interface{}{"success": false, "error": "invalid format"}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+		if err != nil {
+			log.Error(logActionGetAnalysis, logInfoAnalysis, 1020, err)
+			reply := map[string]interface{}{"success": false, "error": "internal error"}
+			return c.JSON(http.StatusInternalServerError, reply)
+		}
+		return c.JSONBlob(http.StatusOK, report)
+	}
+	return c.JSON(util.AnalysisHTTPStatus(analysisResult, apiContext.APIConfiguration.AnalysisHTTPStatuses), analysisResult)
+}
+
+// GetAnalysisOutput returns the raw output of a securityTest stored in a given analysis.
+func GetAnalysisOutput(c echo.Context) error {
+
+	RID := c.Param("id")
+	securityTestName := c.Param("securityTestName")
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	if err := util.CheckMaliciousRID(RID, c); err != nil || c.Response().Committed {
+		return err
+	}
+	analysisQuery := map[string]interface{}{"RID": RID}
+	analysisResult, err := apiContext.APIConfiguration.DBInstance.FindOneDBAnalysis(analysisQuery)
+	if err != nil {
+		if err == mgo.ErrNotFound || err.Error() == "No data found" {
+			log.Warning(logActionGetAnalysisOutput, logInfoAnalysis, 106, RID)
+			reply := map[string]interface{}{"success": false, "error": "analysis not found"}
+			return c.JSON(http.StatusNotFound, reply)
+		}
+		log.Error(logActionGetAnalysisOutput, logInfoAnalysis, 1020, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	if !tokenValidator.HasAuthorization(attemptToken, analysisResult.URL) {
+		log.Error(logActionGetAnalysisOutput, logInfoAnalysis, 1027, RID)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+	for _, container := range analysisResult.Containers {
+		if container.SecurityTest.Name != securityTestName {
+			continue
+		}
+		contentType, extension := echo.MIMETextPlainCharsetUTF8, "txt"
+		if json.Valid([]byte(container.COutput)) {
+			contentType, extension = echo.MIMEApplicationJSONCharsetUTF8, "json"
+		}
+		fileName := fmt.Sprintf("%s-%s.%s", RID, securityTestName, extension)
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+		return c.Stream(http.StatusOK, contentType, strings.NewReader(container.COutput))
+	}
+	log.Warning(logActionGetAnalysisOutput, logInfoAnalysis, 115, securityTestName, RID)
+	reply := map[string]interface{}{"success": false, "error": "securityTest output not found"}
+	return c.JSON(http.StatusNotFound, reply)
 }
 
 // ReceiveRequest receives the request and performs several checks before starting a new analysis.
@@ -72,6 +169,11 @@ func ReceiveRequest(c echo.Context) error {
 
 	RID := c.Response().Header().Get(echo.HeaderXRequestID)
 	attemptToken := c.Request().Header.Get("Husky-Token")
+	if analysis.ShuttingDown() {
+		log.Warning(logActionReceiveRequest, logInfoAnalysis, 124, RID)
+		reply := map[string]interface{}{"success": false, "error": "huskyCI is shutting down"}
+		return c.JSON(http.StatusServiceUnavailable, reply)
+	}
 
 	// step-00: is this a valid JSON?
 	repository := types.Repository{}
@@ -81,17 +183,126 @@ func ReceiveRequest(c echo.Context) error {
 		reply := map[string]interface{}{"success": false, "error": "invalid repository JSON"}
 		return c.JSON(http.StatusBadRequest, reply)
 	}
-	if !tokenValidator.HasAuthorization(attemptToken, repository.URL) {
+	// the token is checked against the URL without its credentials, as it is registered and stored.
+	if !tokenValidator.HasAuthorization(attemptToken, util.RedactURLCredentials(repository.URL)) {
 		log.Error("ReceivedRequest", logInfoAnalysis, 1027, RID)
 		reply := map[string]interface{}{"success": false, "error": "permission denied"}
 		return c.JSON(http.StatusUnauthorized, reply)
 	}
+	return submitAnalysis(c, RID, repository)
+}
+
+// ReceiveUpload receives an archive, a zip or tar.gz file, and starts a new analysis of its files, that are
+// analyzed as a repository would be. The analysis is named after the name field, or the name of the archive.
+func ReceiveUpload(c echo.Context) error {
+
+	RID := c.Response().Header().Get(echo.HeaderXRequestID)
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	if analysis.ShuttingDown() {
+		log.Warning(logActionReceiveRequest, logInfoAnalysis, 124, RID)
+		reply := map[string]interface{}{"success": false, "error": "huskyCI is shutting down"}
+		return c.JSON(http.StatusServiceUnavailable, reply)
+	}
+
+	uploadConfig := apiContext.APIConfiguration.UploadConfig
+	// the form around the archive is small, so the body is limited a bit beyond the archive size limit.
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, uploadConfig.MaxSize+1<<20)
+	archive, err := c.FormFile("file")
+	if err != nil {
+		log.Error(logActionReceiveRequest, logInfoAnalysis, 1067, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid archive upload"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	name := c.FormValue("name")
+	if name == "" {
+		name = upload.Name(archive.Filename)
+	}
+	if !upload.IsValidName(name) {
+		log.Error(logActionReceiveRequest, logInfoAnalysis, 1067, "invalid name ", name)
+		reply := map[string]interface{}{"success": false, "error": "invalid archive name"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	repository := types.Repository{URL: upload.URL(name), Branch: upload.Branch, Source: upload.Source}
+	if !tokenValidator.HasAuthorization(attemptToken, repository.URL) {
+		log.Error(logActionReceiveRequest, logInfoAnalysis, 1027, RID)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+
+	archiveFile, err := archive.Open()
+	if err != nil {
+		log.Error(logActionReceiveRequest, logInfoAnalysis, 1067, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid archive upload"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	defer archiveFile.Close()
+	limits := upload.Limits{MaxSize: uploadConfig.MaxSize, MaxExtractedSize: uploadConfig.MaxExtractedSize}
+	if err := upload.Store(uploadConfig.Dir, RID, archiveFile, limits); err != nil {
+		switch err {
+		case upload.ErrArchiveTooLarge:
+			log.Error(logActionReceiveRequest, logInfoAnalysis, 1067, err)
+			reply := map[string]interface{}{"success": false, "error": err.Error()}
+			return c.JSON(http.StatusRequestEntityTooLarge, reply)
+		case upload.ErrUnsupportedArchive, upload.ErrUnsafePath:
+			log.Error(logActionReceiveRequest, logInfoAnalysis, 1067, err)
+			reply := map[string]interface{}{"success": false, "error": err.Error()}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+		log.Error(logActionReceiveRequest, logInfoAnalysis, 1068, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+
+	if err := analysis.QueueAnalysis(RID, repository); err != nil {
+		if err := upload.Remove(uploadConfig.Dir, RID); err != nil {
+			log.Error(logActionReceiveRequest, logInfoAnalysis, 1066, err)
+		}
+		return replyQueueFull(c, logActionReceiveRequest, RID)
+	}
+	log.ForAnalysis(RID, repository.URL).Info(logActionReceiveRequest, logInfoAnalysis, 16, repository.Branch, repository.URL)
+	reply := map[string]interface{}{"success": true, "error": "", "RID": RID}
+	return c.JSON(http.StatusCreated, reply)
+}
+
+// submitAnalysis starts an analysis of repository under RID, unless the same commit was recently analyzed
+// or an analysis of the same branch is still running, replying the client with the outcome.
+func submitAnalysis(c echo.Context, RID string, repository types.Repository) error {
 	// step-01: Check malicious inputs
 	sanitizedRepoURL, err := util.CheckValidInput(repository, c)
-	if err != nil {
+	if err != nil || c.Response().Committed {
+		return err
+	}
+	if err := containerenv.Check(repository.SecurityTestEnv, c); err != nil || c.Response().Committed {
 		return err
 	}
 	repository.URL = sanitizedRepoURL
+	branchpolicy.Apply(&repository, apiContext.APIConfiguration.BranchPolicies)
+	if apiContext.APIConfiguration.RedactURLCredentials {
+		util.RedactRepositoryURL(&repository)
+	}
+	if err := util.CheckScanProfile(&repository, apiContext.APIConfiguration.ScanProfiles, c); err != nil || c.Response().Committed {
+		return err
+	}
+	if err := secrets.CheckScopes(repository, apiContext.APIConfiguration.ContainerSecretScopes, c); err != nil || c.Response().Committed {
+		return err
+	}
+
+	// step-01-a: was this commit recently analyzed with the same parameters?
+	if cacheQuery := cache.AnalysisQuery(repository); cacheQuery != nil && apiContext.APIConfiguration.AnalysisCache && !repository.Force {
+		finishedAfter := time.Now().Add(-apiContext.APIConfiguration.AnalysisCacheMaxAge)
+		cachedAnalysis, err := apiContext.APIConfiguration.DBInstance.FindLatestDBAnalysis(cacheQuery, finishedAfter)
+		// Analyses restricted to some securityTests or run with securityTestArgs or a securityTestEnv may have
+		// skipped checks, so their results are never reused.
+		if err == nil && len(cachedAnalysis.SecurityTests) == 0 && len(cachedAnalysis.SecurityTestArgs) == 0 && len(cachedAnalysis.SecurityTestEnv) == 0 {
+			log.ForAnalysis(cachedAnalysis.RID, repository.URL).Info(logActionReceiveRequest, logInfoAnalysis, 25, repository.Commit, repository.URL)
+			c.Response().Header().Set(echo.HeaderXRequestID, cachedAnalysis.RID)
+			reply := map[string]interface{}{"success": true, "error": "", "RID": cachedAnalysis.RID, "cached": true}
+			return c.JSON(http.StatusOK, reply)
+		}
+		if err != nil && err != mgo.ErrNotFound && err.Error() != "No data found" {
+			log.Error(logActionReceiveRequest, logInfoAnalysis, 1057, err)
+		}
+	}
 
 	// step-02: is this repository already in MongoDB?
 	repositoryQuery := map[string]interface{}{"repositoryURL": repository.URL}
@@ -113,8 +324,9 @@ func ReceiveRequest(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, reply)
 		}
 	} else { // err == nil
-		// step-03: repository found! does it have a running status analysis?
-		analysisQuery := map[string]interface{}{"repositoryURL": repository.URL, "repositoryBranch": repository.Branch}
+		// step-03: repository found! does it have a running status analysis of the same subPath?
+		// An empty subPath matches the analyses of the whole repository only.
+		analysisQuery := map[string]interface{}{"repositoryURL": repository.URL, "repositoryBranch": repository.Branch, "repositorySubPath": util.CleanSubPath(repository.SubPath)}
 		analysisResult, err := apiContext.APIConfiguration.DBInstance.FindOneDBAnalysis(analysisQuery)
 		if err != nil {
 			if err == mgo.ErrNotFound || err.Error() == "No data found" {
@@ -126,8 +338,8 @@ func ReceiveRequest(c echo.Context) error {
 				return c.JSON(http.StatusInternalServerError, reply)
 			}
 		} else { // err == nil
-			// step 03-a: Ops, this analysis is already running!
-			if analysisResult.Status == "running" {
+			// step 03-a: Ops, this analysis is already queued or running!
+			if analysis.Unfinished(analysisResult.Status) {
 				log.Warning(logActionReceiveRequest, logInfoAnalysis, 104, analysisResult.URL)
 				reply := map[string]interface{}{"success": false, "error": "an analysis is already in place for this URL and branch"}
 				return c.JSON(http.StatusConflict, reply)
@@ -135,9 +347,362 @@ func ReceiveRequest(c echo.Context) error {
 		}
 	}
 
-	// step 04: lets start this analysis!
-	log.Info(logActionReceiveRequest, logInfoAnalysis, 16, repository.Branch, repository.URL)
-	go analysis.StartAnalysis(RID, repository)
+	// step 04: lets queue this analysis!
+	if err := analysis.QueueAnalysis(RID, repository); err != nil {
+		return replyQueueFull(c, logActionReceiveRequest, RID)
+	}
+	log.ForAnalysis(RID, repository.URL).Info(logActionReceiveRequest, logInfoAnalysis, 16, repository.Branch, repository.URL)
 	reply := map[string]interface{}{"success": true, "error": ""}
 	return c.JSON(http.StatusCreated, reply)
 }
+
+// replyQueueFull replies that the analysis RID was rejected by a full queue, telling the client when to submit it again.
+func replyQueueFull(c echo.Context, logAction, RID string) error {
+	log.Warning(logAction, logInfoAnalysis, 135, RID)
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(analysis.QueueRetryAfter.Seconds())))
+	reply := map[string]interface{}{"success": false, "error": "the analysis queue is full, try again later"}
+	return c.JSON(http.StatusTooManyRequests, reply)
+}
+
+// RerunAnalysis starts a new analysis with the same parameters of a given finished analysis and returns its RID.
+func RerunAnalysis(c echo.Context) error {
+
+	RID := c.Param("id")
+	newRID := c.Response().Header().Get(echo.HeaderXRequestID)
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	if analysis.ShuttingDown() {
+		log.Warning(logActionRerunAnalysis, logInfoAnalysis, 124, newRID)
+		reply := map[string]interface{}{"success": false, "error": "huskyCI is shutting down"}
+		return c.JSON(http.StatusServiceUnavailable, reply)
+	}
+	if err := util.CheckMaliciousRID(RID, c); err != nil || c.Response().Committed {
+		return err
+	}
+	analysisQuery := map[string]interface{}{"RID": RID}
+	originAnalysis, err := apiContext.APIConfiguration.DBInstance.FindOneDBAnalysis(analysisQuery)
+	if err != nil {
+		if err == mgo.ErrNotFound || err.Error() == "No data found" {
+			log.Warning(logActionRerunAnalysis, logInfoAnalysis, 106, RID)
+			reply := map[string]interface{}{"success": false, "error": "analysis not found"}
+			return c.JSON(http.StatusNotFound, reply)
+		}
+		log.Error(logActionRerunAnalysis, logInfoAnalysis, 1020, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	if !tokenValidator.HasAuthorization(attemptToken, originAnalysis.URL) {
+		log.Error(logActionRerunAnalysis, logInfoAnalysis, 1027, RID)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+	if originAnalysis.Source == upload.Source {
+		log.Warning(logActionRerunAnalysis, logInfoAnalysis, 129, RID)
+		reply := map[string]interface{}{"success": false, "error": "analyses of uploaded archives can not be rerun"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	if analysis.Unfinished(originAnalysis.Status) {
+		log.Warning(logActionRerunAnalysis, logInfoAnalysis, 116, RID)
+		reply := map[string]interface{}{"success": false, "error": "analysis is still running"}
+		return c.JSON(http.StatusConflict, reply)
+	}
+
+	if err := analysis.QueueRerun(newRID, originAnalysis); err != nil {
+		return replyQueueFull(c, logActionRerunAnalysis, newRID)
+	}
+	log.ForAnalysis(newRID, originAnalysis.URL).Info(logActionRerunAnalysis, logInfoAnalysis, 16, originAnalysis.Branch, originAnalysis.URL)
+	reply := map[string]interface{}{"success": true, "error": "", "RID": newRID, "originAnalysisID": RID}
+	return c.JSON(http.StatusCreated, reply)
+}
+
+// ReevaluateAnalysis returns the verdict of a finished analysis under the current severity threshold and
+// overrides, or the ones in the optional policy JSON, recomputed from its stored findings without running
+// any securityTest. The stored analysis is not changed.
+func ReevaluateAnalysis(c echo.Context) error {
+
+	RID := c.Param("id")
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	if err := util.CheckMaliciousRID(RID, c); err != nil || c.Response().Committed {
+		return err
+	}
+	policy := types.ReevaluationPolicy{}
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&policy); err != nil {
+			log.Warning(logActionReevaluateAnalysis, logInfoAnalysis, 132, err)
+			reply := map[string]interface{}{"success": false, "error": "invalid reevaluation policy JSON"}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+	}
+	analysisQuery := map[string]interface{}{"RID": RID}
+	storedAnalysis, err := apiContext.APIConfiguration.DBInstance.FindOneDBAnalysis(analysisQuery)
+	if err != nil {
+		if err == mgo.ErrNotFound || err.Error() == "No data found" {
+			log.Warning(logActionReevaluateAnalysis, logInfoAnalysis, 106, RID)
+			reply := map[string]interface{}{"success": false, "error": "analysis not found"}
+			return c.JSON(http.StatusNotFound, reply)
+		}
+		log.Error(logActionReevaluateAnalysis, logInfoAnalysis, 1020, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	if !tokenValidator.HasAuthorization(attemptToken, storedAnalysis.URL) {
+		log.Error(logActionReevaluateAnalysis, logInfoAnalysis, 1027, RID)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+	if analysis.Unfinished(storedAnalysis.Status) {
+		log.Warning(logActionReevaluateAnalysis, logInfoAnalysis, 116, RID)
+		reply := map[string]interface{}{"success": false, "error": "analysis is still running"}
+		return c.JSON(http.StatusConflict, reply)
+	}
+
+	reevaluation, err := securitytest.Reevaluate(storedAnalysis, policy)
+	if err == securitytest.ErrInvalidSeverity {
+		log.Warning(logActionReevaluateAnalysis, logInfoAnalysis, 132, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid severity, it must be low, medium or high"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	if err != nil {
+		log.ForAnalysis(RID, storedAnalysis.URL).Error(logActionReevaluateAnalysis, logInfoAnalysis, 1072, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	log.ForAnalysis(RID, storedAnalysis.URL).Info(logActionReevaluateAnalysis, logInfoAnalysis, 42, reevaluation.StoredResult, reevaluation.Result)
+	return c.JSON(http.StatusOK, reevaluation)
+}
+
+// PlanAnalysis detects the languages of a repository and returns the securityTests an analysis of it would
+// run, together with the ones that would be skipped, without running any securityTest.
+func PlanAnalysis(c echo.Context) error {
+
+	RID := c.Response().Header().Get(echo.HeaderXRequestID)
+	attemptToken := c.Request().Header.Get("Husky-Token")
+
+	repository := types.Repository{}
+	if err := c.Bind(&repository); err != nil {
+		log.Error(logActionPlanAnalysis, logInfoAnalysis, 1015, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid repository JSON"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	if !tokenValidator.HasAuthorization(attemptToken, util.RedactURLCredentials(repository.URL)) {
+		log.Error(logActionPlanAnalysis, logInfoAnalysis, 1027, RID)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+	sanitizedRepoURL, err := util.CheckValidInput(repository, c)
+	if err != nil || c.Response().Committed {
+		return err
+	}
+	if err := containerenv.Check(repository.SecurityTestEnv, c); err != nil || c.Response().Committed {
+		return err
+	}
+	repository.URL = sanitizedRepoURL
+	branchpolicy.Apply(&repository, apiContext.APIConfiguration.BranchPolicies)
+	if apiContext.APIConfiguration.RedactURLCredentials {
+		util.RedactRepositoryURL(&repository)
+	}
+	if err := util.CheckScanProfile(&repository, apiContext.APIConfiguration.ScanProfiles, c); err != nil || c.Response().Committed {
+		return err
+	}
+	if err := secrets.CheckScopes(repository, apiContext.APIConfiguration.ContainerSecretScopes, c); err != nil || c.Response().Committed {
+		return err
+	}
+
+	plan, err := analysis.PlanAnalysis(RID, repository)
+	if err != nil {
+		log.ForAnalysis(RID, repository.URL).Error(logActionPlanAnalysis, logInfoAnalysis, 1043, err)
+		reply := map[string]interface{}{"success": false, "error": "could not plan the analysis"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	return c.JSON(http.StatusOK, plan)
+}
+
+// ListAnalyses returns, most recent first, the last analyses of the repository given by the repositoryURL
+// query string parameter, with their status, tags and number of vulnerabilities. The repositoryBranch
+// parameter only keeps the analyses of a branch, each tag parameter, a KEY:VALUE pair, only the analyses
+// tagged with it and the limit parameter sets how many of them are returned.
+func ListAnalyses(c echo.Context) error {
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	repositoryURL, err := util.CheckMaliciousRepoURL(c.QueryParam("repositoryURL"))
+	if err != nil {
+		log.Warning(logActionListAnalyses, logInfoAnalysis, 138, "repositoryURL")
+		reply := map[string]interface{}{"success": false, "error": "invalid repository URL"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	repositoryURL = util.RedactURLCredentials(repositoryURL)
+	limit := defaultAnalysisListLimit
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 || limit > maxAnalysisListLimit {
+			log.Warning(logActionListAnalyses, logInfoAnalysis, 138, limitParam)
+			reply := map[string]interface{}{"success": false, "error": "invalid limit"}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+	}
+	analysisQuery, err := tags.ParseFilters(c.QueryParams()["tag"])
+	if err != nil {
+		log.Warning(logActionListAnalyses, logInfoAnalysis, 138, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid tag"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	if branch := c.QueryParam("repositoryBranch"); branch != "" {
+		if err := util.CheckMaliciousRepoBranch(branch, c); err != nil {
+			return err
+		}
+		analysisQuery["repositoryBranch"] = branch
+	}
+	if !tokenValidator.HasAuthorization(attemptToken, repositoryURL) {
+		log.Error(logActionListAnalyses, logInfoAnalysis, 1027, repositoryURL)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+
+	analysisQuery["repositoryURL"] = repositoryURL
+	analyses, err := apiContext.APIConfiguration.DBInstance.FindLastDBAnalysisSummaries(analysisQuery, time.Time{}, limit)
+	if err == mgo.ErrNotFound || (err != nil && err.Error() == "No data found") {
+		analyses, err = []types.Analysis{}, nil
+	}
+	if err != nil {
+		log.Error(logActionListAnalyses, logInfoAnalysis, 1020, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	list := types.AnalysisList{RepositoryURL: repositoryURL, Analyses: make([]types.AnalysisListItem, 0, len(analyses))}
+	for _, analysis := range analyses {
+		list.Analyses = append(list.Analyses, types.AnalysisListItem{
+			RID:        analysis.RID,
+			Branch:     analysis.Branch,
+			Commit:     analysis.Commit,
+			Tags:       analysis.Tags,
+			Status:     analysis.Status,
+			Result:     analysis.Result,
+			StartedAt:  analysis.StartedAt,
+			FinishedAt: analysis.FinishedAt,
+			Total:      analysis.Summary.Total,
+		})
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// GetAnalysisTrend returns the last analyses of the repository given by the repositoryURL query string
+// parameter, oldest first, with their status and the counts of their vulnerabilities by severity, so that
+// a regression of the repository can be spotted. The limit parameter sets how many of them are returned
+// and the since parameter, a date or an RFC 3339 time, the time after which they started. Each tag parameter,
+// a KEY:VALUE pair, only keeps the analyses tagged with it.
+func GetAnalysisTrend(c echo.Context) error {
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	repositoryURL, err := util.CheckMaliciousRepoURL(c.QueryParam("repositoryURL"))
+	if err != nil {
+		log.Warning(logActionGetAnalysisTrend, logInfoAnalysis, 134, "repositoryURL")
+		reply := map[string]interface{}{"success": false, "error": "invalid repository URL"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	repositoryURL = util.RedactURLCredentials(repositoryURL)
+	limit := defaultAnalysisListLimit
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 || limit > maxAnalysisListLimit {
+			log.Warning(logActionGetAnalysisTrend, logInfoAnalysis, 134, limitParam)
+			reply := map[string]interface{}{"success": false, "error": "invalid limit"}
+			return c.JSON(http.StatusBadRequest, reply)
+		}
+	}
+	since := time.Time{}
+	if sinceParam := c.QueryParam("since"); sinceParam != "" {
+		if since, err = time.Parse(time.RFC3339, sinceParam); err != nil {
+			if since, err = time.Parse("2006-01-02", sinceParam); err != nil {
+				log.Warning(logActionGetAnalysisTrend, logInfoAnalysis, 134, sinceParam)
+				reply := map[string]interface{}{"success": false, "error": "invalid since"}
+				return c.JSON(http.StatusBadRequest, reply)
+			}
+		}
+	}
+	analysisQuery, err := tags.ParseFilters(c.QueryParams()["tag"])
+	if err != nil {
+		log.Warning(logActionGetAnalysisTrend, logInfoAnalysis, 134, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid tag"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	if !tokenValidator.HasAuthorization(attemptToken, repositoryURL) {
+		log.Error(logActionGetAnalysisTrend, logInfoAnalysis, 1027, repositoryURL)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+
+	analysisQuery["repositoryURL"] = repositoryURL
+	analyses, err := apiContext.APIConfiguration.DBInstance.FindLastDBAnalysisSummaries(analysisQuery, since, limit)
+	if err == mgo.ErrNotFound || (err != nil && err.Error() == "No data found") {
+		analyses, err = []types.Analysis{}, nil
+	}
+	if err != nil {
+		log.Error(logActionGetAnalysisTrend, logInfoAnalysis, 1020, err)
+		reply := map[string]interface{}{"success": false, "error": "internal error"}
+		return c.JSON(http.StatusInternalServerError, reply)
+	}
+	trend := types.AnalysisTrend{RepositoryURL: repositoryURL, Analyses: make([]types.AnalysisTrendPoint, 0, len(analyses))}
+	for i := len(analyses) - 1; i >= 0; i-- {
+		analysis := analyses[i]
+		trend.Analyses = append(trend.Analyses, types.AnalysisTrendPoint{
+			RID:        analysis.RID,
+			Branch:     analysis.Branch,
+			Commit:     analysis.Commit,
+			Tags:       analysis.Tags,
+			Status:     analysis.Status,
+			Result:     analysis.Result,
+			StartedAt:  analysis.StartedAt,
+			FinishedAt: analysis.FinishedAt,
+			Total:      analysis.Summary.Total,
+			BySeverity: analysis.Summary.BySeverity,
+		})
+	}
+	return c.JSON(http.StatusOK, trend)
+}
+
+// CompareAnalyses returns the findings added, removed and unchanged from the finished analysis given by the
+// base query string parameter to the one given by head, matched by their fingerprints, with their counts by
+// severity. Both analyses have to be of the same repository.
+func CompareAnalyses(c echo.Context) error {
+	attemptToken := c.Request().Header.Get("Husky-Token")
+	baseRID, headRID := c.QueryParam("base"), c.QueryParam("head")
+	if baseRID == "" || headRID == "" {
+		log.Warning(logActionCompareAnalyses, logInfoAnalysis, 137, "base and head are required")
+		reply := map[string]interface{}{"success": false, "error": "base and head analysis IDs are required"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	analyses := make([]types.Analysis, 0, 2)
+	for _, RID := range []string{baseRID, headRID} {
+		if err := util.CheckMaliciousRID(RID, c); err != nil || c.Response().Committed {
+			return err
+		}
+		analysisQuery := map[string]interface{}{"RID": RID}
+		storedAnalysis, err := apiContext.APIConfiguration.DBInstance.FindOneDBAnalysis(analysisQuery)
+		if err != nil {
+			if err == mgo.ErrNotFound || err.Error() == "No data found" {
+				log.Warning(logActionCompareAnalyses, logInfoAnalysis, 106, RID)
+				reply := map[string]interface{}{"success": false, "error": "analysis not found"}
+				return c.JSON(http.StatusNotFound, reply)
+			}
+			log.Error(logActionCompareAnalyses, logInfoAnalysis, 1020, err)
+			reply := map[string]interface{}{"success": false, "error": "internal error"}
+			return c.JSON(http.StatusInternalServerError, reply)
+		}
+		if !tokenValidator.HasAuthorization(attemptToken, storedAnalysis.URL) {
+			log.Error(logActionCompareAnalyses, logInfoAnalysis, 1027, RID)
+			reply := map[string]interface{}{"success": false, "error": "permission denied"}
+			return c.JSON(http.StatusUnauthorized, reply)
+		}
+		if analysis.Unfinished(storedAnalysis.Status) {
+			log.Warning(logActionCompareAnalyses, logInfoAnalysis, 116, RID)
+			reply := map[string]interface{}{"success": false, "error": "analysis is still running"}
+			return c.JSON(http.StatusConflict, reply)
+		}
+		analyses = append(analyses, storedAnalysis)
+	}
+	base, head := analyses[0], analyses[1]
+	if base.URL != head.URL {
+		log.Warning(logActionCompareAnalyses, logInfoAnalysis, 137, base.URL, head.URL)
+		reply := map[string]interface{}{"success": false, "error": "the analyses are of different repositories"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+
+	comparison := fingerprint.Compare(base.HuskyCIResults, head.HuskyCIResults, apiContext.APIConfiguration.FingerprintAlgorithm)
+	comparison.RepositoryURL, comparison.BaseRID, comparison.HeadRID = head.URL, base.RID, head.RID
+	return c.JSON(http.StatusOK, comparison)
+}
@@ -0,0 +1,25 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// RequireClientCertificate replies 401 to the requests that did not come through a TLS connection whose client
+// certificate was verified, before any route checks their credentials. With mutual TLS, such clients are already
+// rejected by the TLS handshake, but the calls of the gRPC API reach the routes without a connection of their own.
+// It applies to every route, /healthcheck and /webhook/:provider included, so those are served on a port of their
+// own, without it, for the probes and the webhooks that present no client certificate.
+func RequireClientCertificate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if state := c.Request().TLS; state == nil || len(state.VerifiedChains) == 0 {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{"success": false, "error": "a valid client certificate is required"})
+		}
+		return next(c)
+	}
+}
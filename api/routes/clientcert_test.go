@@ -0,0 +1,55 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequireClientCertificate", func() {
+
+	doRequest := func(state *tls.ConnectionState) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Use(routes.RequireClientCertificate)
+		e.GET("/analysis/:id", func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		})
+		request := httptest.NewRequest(http.MethodGet, "/analysis/abc", nil)
+		request.TLS = state
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, request)
+		return rec
+	}
+
+	Context("When the client certificate of the connection was verified", func() {
+		It("Should call the next handler.", func() {
+			state := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+			Expect(doRequest(state).Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Context("When the connection has no verified client certificate", func() {
+		It("Should reply 401.", func() {
+			rec := doRequest(&tls.ConnectionState{})
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Body.String()).To(ContainSubstring("a valid client certificate is required"))
+		})
+	})
+
+	Context("When the request did not come through TLS", func() {
+		It("Should reply 401.", func() {
+			Expect(doRequest(nil).Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
@@ -0,0 +1,34 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/labstack/echo"
+)
+
+// databaseFreePaths are the routes served without querying the database.
+var databaseFreePaths = map[string]bool{
+	"/healthcheck":  true,
+	"/version":      true,
+	"/openapi.json": true,
+}
+
+// RequireDatabase replies 503 to the requests of routes depending on the database while it is unreachable,
+// instead of letting them fail on their first query.
+func RequireDatabase(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if databaseFreePaths[c.Path()] {
+			return next(c)
+		}
+		if availability, ok := apiContext.APIConfiguration.DBInstance.(db.Availability); ok && !availability.Available() {
+			return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{"success": false, "error": "database unavailable"})
+		}
+		return next(c)
+	}
+}
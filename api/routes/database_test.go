@@ -0,0 +1,82 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeAvailabilityDB struct {
+	db.Requests
+	available bool
+}
+
+func (f *fakeAvailabilityDB) Available() bool {
+	return f.available
+}
+
+var _ = Describe("RequireDatabase", func() {
+
+	var fakeDB *fakeAvailabilityDB
+	var previousConfig *apiContext.APIConfig
+	var e *echo.Echo
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		fakeDB = &fakeAvailabilityDB{available: true}
+		apiContext.APIConfiguration = &apiContext.APIConfig{DBInstance: fakeDB}
+		e = echo.New()
+		e.Use(routes.RequireDatabase)
+		ok := func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		}
+		e.GET("/healthcheck", ok)
+		e.GET("/analysis/:id", ok)
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	doRequest := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	Context("When the database is available", func() {
+		It("Should call the next handler.", func() {
+			Expect(doRequest("/analysis/abc").Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Context("When the database is unreachable", func() {
+		BeforeEach(func() {
+			fakeDB.available = false
+		})
+		It("Should reply 503 to the routes depending on it.", func() {
+			rec := doRequest("/analysis/abc")
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rec.Body.String()).To(ContainSubstring("database unavailable"))
+		})
+		It("Should still serve the routes not depending on it.", func() {
+			Expect(doRequest("/healthcheck").Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Context("When the database does not keep track of its availability", func() {
+		It("Should call the next handler.", func() {
+			apiContext.APIConfiguration.DBInstance = &fakeRepositoryDB{}
+			Expect(doRequest("/analysis/abc").Code).To(Equal(http.StatusNoContent))
+		})
+	})
+})
@@ -0,0 +1,18 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/openapi"
+	"github.com/labstack/echo"
+)
+
+// GetOpenAPI returns the OpenAPI document of every route of the API.
+func GetOpenAPI(c echo.Context) error {
+	return c.JSON(http.StatusOK, openapi.Document(apiContext.APIConfiguration.Version, openapi.Operations))
+}
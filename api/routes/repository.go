@@ -0,0 +1,78 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/token"
+	"github.com/globocom/huskyCI/api/util"
+	"github.com/labstack/echo"
+)
+
+const logInfoRepository = "REPOSITORY"
+
+const (
+	defaultRepositoryPageSize = 20
+	maxRepositoryPageSize     = 100
+)
+
+// RequireAdmin only lets the API users configured as admins, authenticated by the basic auth
+// middleware, reach the next handler.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		username, _, _ := c.Request().BasicAuth()
+		if !util.SliceContains(apiContext.APIConfiguration.AdminUsers, username) {
+			log.Warning("RequireAdmin", logInfoRepository, 121, username)
+			return c.JSON(http.StatusForbidden, map[string]interface{}{"success": false, "error": "admin credential required"})
+		}
+		return next(c)
+	}
+}
+
+// ListRepositories returns a page of the registered repositories, with the validity of their
+// access tokens and the date of their last analysis.
+func ListRepositories(c echo.Context) error {
+	page, pageSize := 1, defaultRepositoryPageSize
+	var err error
+	if pageParam := c.QueryParam("page"); pageParam != "" {
+		if page, err = strconv.Atoi(pageParam); err != nil || page < 1 {
+			log.Warning("ListRepositories", logInfoRepository, 122, pageParam)
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid page"})
+		}
+	}
+	if pageSizeParam := c.QueryParam("pageSize"); pageSizeParam != "" {
+		if pageSize, err = strconv.Atoi(pageSizeParam); err != nil || pageSize < 1 || pageSize > maxRepositoryPageSize {
+			log.Warning("ListRepositories", logInfoRepository, 122, pageSizeParam)
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid pageSize"})
+		}
+	}
+	repositoryPage, err := tokenHandler.ListRepositories(page, pageSize)
+	if err != nil {
+		log.Error("ListRepositories", logInfoRepository, 1058, err)
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"success": false, "error": "internal error"})
+	}
+	return c.JSON(http.StatusOK, repositoryPage)
+}
+
+// DeleteRepository deletes the registered repository given in the repositoryURL query string
+// parameter together with all its access tokens.
+func DeleteRepository(c echo.Context) error {
+	repositoryURL := c.QueryParam("repositoryURL")
+	err := tokenHandler.DeleteRepository(repositoryURL)
+	if err == token.ErrRepositoryNotFound {
+		log.Warning("DeleteRepository", logInfoRepository, 123, repositoryURL)
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"success": false, "error": "repository not found"})
+	}
+	if err != nil {
+		log.Error("DeleteRepository", logInfoRepository, 1059, repositoryURL, err)
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"success": false, "error": "internal error"})
+	}
+	log.Info("DeleteRepository", logInfoRepository, 26, repositoryURL)
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": true, "error": ""})
+}
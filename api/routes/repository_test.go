@@ -0,0 +1,166 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeRepositoryDB struct {
+	db.Requests
+	accessTokens []types.DBToken
+	deleted      []interface{}
+}
+
+func (f *fakeRepositoryDB) FindAllDBAccessToken(mapParams map[string]interface{}) ([]types.DBToken, error) {
+	return f.accessTokens, nil
+}
+
+func (f *fakeRepositoryDB) FindAllDBRepository(mapParams map[string]interface{}) ([]types.Repository, error) {
+	return nil, errors.New("No data found")
+}
+
+func (f *fakeRepositoryDB) FindLatestDBAnalysis(mapParams map[string]interface{}, finishedAfter time.Time) (types.Analysis, error) {
+	if mapParams["repositoryURL"] != "https://github.com/globocom/huskyCI.git" {
+		return types.Analysis{}, errors.New("No data found")
+	}
+	return types.Analysis{FinishedAt: time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)}, nil
+}
+
+func (f *fakeRepositoryDB) DeleteAllDBAccessToken(mapParams map[string]interface{}) error {
+	f.deleted = append(f.deleted, mapParams["repositoryURL"])
+	return nil
+}
+
+func (f *fakeRepositoryDB) DeleteAllDBRepository(mapParams map[string]interface{}) error {
+	f.deleted = append(f.deleted, mapParams["repositoryURL"])
+	return nil
+}
+
+var _ = Describe("Repositories", func() {
+
+	e := echo.New()
+
+	var fakeDB *fakeRepositoryDB
+	var previousConfig *apiContext.APIConfig
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		fakeDB = &fakeRepositoryDB{
+			accessTokens: []types.DBToken{
+				{URL: "https://github.com/globocom/huskyCI.git", IsValid: true},
+				{URL: "https://github.com/globocom/huskyCI.git", IsValid: false},
+				{URL: "https://github.com/globocom/glbgelf.git", IsValid: true},
+			},
+		}
+		apiContext.APIConfiguration = &apiContext.APIConfig{
+			DBInstance: fakeDB,
+			AdminUsers: []string{"admin"},
+		}
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	Describe("RequireAdmin", func() {
+		next := func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		}
+		doRequest := func(username string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/1.0/repositories", nil)
+			req.SetBasicAuth(username, "password")
+			rec := httptest.NewRecorder()
+			Expect(routes.RequireAdmin(next)(e.NewContext(req, rec))).To(Succeed())
+			return rec
+		}
+
+		Context("When the user is an admin", func() {
+			It("Should call the next handler.", func() {
+				Expect(doRequest("admin").Code).To(Equal(http.StatusNoContent))
+			})
+		})
+
+		Context("When the user is not an admin", func() {
+			It("Should return forbidden.", func() {
+				rec := doRequest("huskyCIUser")
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+				Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "admin credential required"}`))
+			})
+		})
+	})
+
+	Describe("ListRepositories", func() {
+		doRequest := func(query string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/1.0/repositories"+query, nil)
+			rec := httptest.NewRecorder()
+			Expect(routes.ListRepositories(e.NewContext(req, rec))).To(Succeed())
+			return rec
+		}
+
+		Context("When no page is given", func() {
+			It("Should return the first page of the registered repositories.", func() {
+				rec := doRequest("")
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Body.String()).To(MatchJSON(`{"repositories": [
+					{"repositoryURL": "https://github.com/globocom/glbgelf.git", "validTokens": 1, "invalidTokens": 0},
+					{"repositoryURL": "https://github.com/globocom/huskyCI.git", "validTokens": 1, "invalidTokens": 1, "lastAnalysisAt": "2020-05-04T10:00:00Z"}
+				], "page": 1, "pageSize": 20, "total": 2}`))
+			})
+		})
+
+		Context("When the page size is over the limit", func() {
+			It("Should return bad request.", func() {
+				rec := doRequest("?pageSize=101")
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "invalid pageSize"}`))
+			})
+		})
+
+		Context("When the page is not a positive number", func() {
+			It("Should return bad request.", func() {
+				rec := doRequest("?page=0")
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "invalid page"}`))
+			})
+		})
+	})
+
+	Describe("DeleteRepository", func() {
+		doRequest := func(repositoryURL string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodDelete, "/api/1.0/repositories?repositoryURL="+repositoryURL, nil)
+			rec := httptest.NewRecorder()
+			Expect(routes.DeleteRepository(e.NewContext(req, rec))).To(Succeed())
+			return rec
+		}
+
+		Context("When the repository is registered", func() {
+			It("Should delete it and its access tokens.", func() {
+				rec := doRequest("https://github.com/globocom/huskyCI.git")
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Body.String()).To(MatchJSON(`{"success": true, "error": ""}`))
+				Expect(fakeDB.deleted).To(Equal([]interface{}{"https://github.com/globocom/huskyCI.git", "https://github.com/globocom/huskyCI.git"}))
+			})
+		})
+
+		Context("When the repository is not registered", func() {
+			It("Should return not found.", func() {
+				rec := doRequest("https://github.com/globocom/unknown.git")
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				Expect(fakeDB.deleted).To(BeEmpty())
+			})
+		})
+	})
+})
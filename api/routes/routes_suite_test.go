@@ -7,11 +7,13 @@ package routes_test
 import (
 	"testing"
 
+	"github.com/globocom/huskyCI/api/log"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
 func TestRoutes(t *testing.T) {
 	RegisterFailHandler(Fail)
+	log.InitLog(true, "", "", "log_test", "log_test")
 	RunSpecs(t, "Routes Suite")
 }
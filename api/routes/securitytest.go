@@ -0,0 +1,47 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+
+	docker "github.com/globocom/huskyCI/api/dockers"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+)
+
+const logActionParseOutput = "ParseSecurityTestOutput"
+const logInfoSecurityTest = "SECURITYTEST"
+
+// ParseSecurityTestOutput parses the raw output of a securityTest without running an analysis
+// and returns the vulnerabilities found, so that parser mappings can be validated.
+func ParseSecurityTestOutput(c echo.Context) error {
+	parseRequest := types.ParseRequest{}
+	if err := c.Bind(&parseRequest); err != nil {
+		log.Error(logActionParseOutput, logInfoSecurityTest, 1041, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid parse JSON"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	vulnerabilities, err := securitytest.Parse(parseRequest.SecurityTestName, parseRequest.Output)
+	if err != nil {
+		if err == securitytest.ErrUnknownSecurityTest {
+			reply := map[string]interface{}{"success": false, "error": "securityTest not found"}
+			return c.JSON(http.StatusNotFound, reply)
+		}
+		log.Warning(logActionParseOutput, logInfoSecurityTest, 114, parseRequest.SecurityTestName, err)
+		reply := map[string]interface{}{"success": false, "error": err.Error()}
+		return c.JSON(http.StatusUnprocessableEntity, reply)
+	}
+	reply := map[string]interface{}{"success": true, "vulnerabilities": vulnerabilities}
+	return c.JSON(http.StatusOK, reply)
+}
+
+// GetSecurityTestImageUpdates returns the result of the last check for newer images of each securityTest.
+func GetSecurityTestImageUpdates(c echo.Context) error {
+	reply := map[string]interface{}{"success": true, "updates": docker.LastImageUpdates()}
+	return c.JSON(http.StatusOK, reply)
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseSecurityTestOutput", func() {
+
+	e := echo.New()
+
+	doRequest := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/1.0/securitytest/parse", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		Expect(routes.ParseSecurityTestOutput(e.NewContext(req, rec))).To(Succeed())
+		return rec
+	}
+
+	Context("When the output is a valid gosec output", func() {
+		It("Should return the parsed vulnerabilities.", func() {
+			gosecOutput := `{"Issues":[{"severity":"HIGH","confidence":"MEDIUM","rule_id":"G101","details":"Potential hardcoded credentials","file":"main.go","code":"password := \"123\"","line":"10"}],"Stats":{"files":1,"lines":20,"nosec":0,"found":1}}`
+			body, err := json.Marshal(types.ParseRequest{SecurityTestName: "gosec", Output: gosecOutput})
+			Expect(err).NotTo(HaveOccurred())
+
+			rec := doRequest(string(body))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			reply := struct {
+				Success         bool                            `json:"success"`
+				Vulnerabilities types.HuskyCISecurityTestOutput `json:"vulnerabilities"`
+			}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &reply)).To(Succeed())
+			Expect(reply.Success).To(BeTrue())
+			Expect(reply.Vulnerabilities.HighVulns).To(Equal([]types.HuskyCIVulnerability{
+				{
+					Language:     "Go",
+					SecurityTool: "GoSec",
+					Severity:     "HIGH",
+					Confidence:   "MEDIUM",
+					File:         "main.go",
+					Line:         "10",
+					Code:         `password := "123"`,
+					Details:      "Potential hardcoded credentials",
+					Title:        "Potential hardcoded credentials",
+					Rule:         "G101",
+				},
+			}))
+		})
+	})
+
+	Context("When the output is malformed", func() {
+		It("Should return a parse error.", func() {
+			body, err := json.Marshal(types.ParseRequest{SecurityTestName: "gosec", Output: `{"Issues": [`})
+			Expect(err).NotTo(HaveOccurred())
+
+			rec := doRequest(string(body))
+			Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).To(ContainSubstring(`"success":false`))
+		})
+	})
+
+	Context("When the securityTest is unknown", func() {
+		It("Should return not found.", func() {
+			rec := doRequest(`{"securityTestName": "mytool", "output": ""}`)
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "securityTest not found"}`))
+		})
+	})
+
+	Context("When the request is not a valid JSON", func() {
+		It("Should return bad request.", func() {
+			rec := doRequest(`{"securityTestName": `)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "invalid parse JSON"}`))
+		})
+	})
+})
@@ -0,0 +1,30 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"net/http"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/selftest"
+	"github.com/labstack/echo"
+)
+
+// selfTestDependencies returns the components checked by SelfTest.
+var selfTestDependencies = func() selftest.Dependencies {
+	return selftest.NewDependencies(apiContext.APIConfiguration.DBInstance, *apiContext.APIConfiguration.EnrySecurityTest)
+}
+
+// SelfTest checks DB, Docker, the registry and a sample securityTest run end-to-end, returning
+// which of them passed with their detail. It replies 503 when any of them failed.
+func SelfTest(c echo.Context) error {
+	report := selftest.Run(selfTestDependencies())
+	status := http.StatusOK
+	if !report.Passed {
+		status = http.StatusServiceUnavailable
+	}
+	reply := map[string]interface{}{"success": report.Passed, "checks": report.Checks}
+	return c.JSON(status, reply)
+}
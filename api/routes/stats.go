@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/globocom/huskyCI/api/analysis"
 	apiContext "github.com/globocom/huskyCI/api/context"
 	"github.com/globocom/huskyCI/api/log"
 	"github.com/labstack/echo"
@@ -28,6 +29,11 @@ func GetMetric(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+// GetQueueStats returns how many analyses are queued and running in this API process, and their limits.
+func GetQueueStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, analysis.GetQueueStats())
+}
+
 func checkError(err error, metricType string) (int, map[string]interface{}) {
 	switch err.Error() {
 	case "invalid time_range query string param":
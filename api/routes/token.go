@@ -5,15 +5,20 @@
 package routes
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/globocom/huskyCI/api/auth"
 	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/token"
 	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
 	"github.com/labstack/echo"
 )
 
+// maxTokenValidationBatch is the maximum number of access tokens validated in a single request.
+const maxTokenValidationBatch = 100
+
 var (
 	tokenHandler token.THandler
 )
@@ -34,8 +39,12 @@ func HandleToken(c echo.Context) error {
 		log.Error("HandleToken", "TOKEN", 1025, err)
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid token JSON"})
 	}
-	log.Info("HandleToken", "TOKEN", 24, repoRequest.RepositoryURL)
+	log.Info("HandleToken", "TOKEN", 24, util.RedactURLCredentials(repoRequest.RepositoryURL))
 	accessToken, err := tokenHandler.GenerateAccessToken(repoRequest)
+	if err == token.ErrRepositoryNotPermitted {
+		log.Warning("HandleToken", "TOKEN", 119, util.RedactURLCredentials(repoRequest.RepositoryURL))
+		return c.JSON(http.StatusForbidden, map[string]interface{}{"success": false, "error": "Repository not permitted"})
+	}
 	if err != nil {
 		log.Error("HandleToken ", "TOKEN", 1026, err)
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"success": false, "error": "token generation failure"})
@@ -57,3 +66,25 @@ func HandleDeactivation(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, map[string]interface{}{"success": true, "error": ""})
 }
+
+// HandleTokenValidation validates a batch of access token and repository URL pairs
+// passed in the body of the request, returning the validity of each pair in order
+func HandleTokenValidation(c echo.Context) error {
+	batch := types.TokenValidationBatch{}
+	if err := c.Bind(&batch); err != nil {
+		log.Error("HandleTokenValidation", "TOKEN", 1025, err)
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": "invalid token JSON"})
+	}
+	if len(batch.Tokens) > maxTokenValidationBatch {
+		log.Warning("HandleTokenValidation", "TOKEN", 117, len(batch.Tokens))
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"success": false, "error": fmt.Sprintf("at most %d tokens can be validated at once", maxTokenValidationBatch)})
+	}
+	results := make([]types.TokenValidationResult, len(batch.Tokens))
+	for i, err := range tokenHandler.ValidateTokens(batch.Tokens) {
+		results[i] = types.TokenValidationResult{
+			RepositoryURL: batch.Tokens[i].RepositoryURL,
+			Valid:         err == nil,
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": true, "error": "", "results": results})
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HandleTokenValidation", func() {
+
+	e := echo.New()
+
+	var previousConfig *apiContext.APIConfig
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		apiContext.APIConfiguration = &apiContext.APIConfig{DBInstance: &fakeAnalysisDB{}}
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	doRequest := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/1.0/token/validate", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		Expect(routes.HandleTokenValidation(e.NewContext(req, rec))).To(Succeed())
+		return rec
+	}
+
+	Context("When the access tokens are not found", func() {
+		It("Should return each pair as not valid.", func() {
+			rec := doRequest(`{"tokens": [
+				{"huskytoken": "dXVpZDpyYW5kb20=", "repositoryURL": "https://github.com/globocom/huskyCI.git"},
+				{"huskytoken": "invalid", "repositoryURL": "https://github.com/globocom/huskyCI.git"}
+			]}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": true, "error": "", "results": [
+				{"repositoryURL": "https://github.com/globocom/huskyCI.git", "valid": false},
+				{"repositoryURL": "https://github.com/globocom/huskyCI.git", "valid": false}
+			]}`))
+		})
+	})
+
+	Context("When the batch has more tokens than the limit", func() {
+		It("Should return bad request.", func() {
+			pairs := make([]string, 101)
+			for i := range pairs {
+				pairs[i] = `{"huskytoken": "dXVpZDpyYW5kb20=", "repositoryURL": "https://github.com/globocom/huskyCI.git"}`
+			}
+			rec := doRequest(`{"tokens": [` + strings.Join(pairs, ",") + `]}`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "at most 100 tokens can be validated at once"}`))
+		})
+	})
+
+	Context("When the body is not a valid JSON", func() {
+		It("Should return bad request.", func() {
+			rec := doRequest(`{"tokens": `)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
+
+var _ = Describe("HandleToken", func() {
+
+	e := echo.New()
+
+	var previousConfig *apiContext.APIConfig
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		apiContext.APIConfiguration = &apiContext.APIConfig{
+			DBInstance:               &fakeAnalysisDB{},
+			TrustedGitHosts:          []string{"github.com"},
+			TokenRepositoryAllowlist: []string{"github.com/globocom"},
+		}
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	Context("When the repository is outside the allowlist", func() {
+		It("Should return forbidden.", func() {
+			req := httptest.NewRequest(http.MethodPost, "/api/1.0/token", strings.NewReader(`{"repositoryURL": "https://github.com/someone/huskyCI.git"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			Expect(routes.HandleToken(e.NewContext(req, rec))).To(Succeed())
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "Repository not permitted"}`))
+		})
+	})
+})
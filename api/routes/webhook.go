@@ -0,0 +1,65 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/globocom/huskyCI/api/analysis"
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/webhook"
+	"github.com/labstack/echo"
+)
+
+const logActionReceiveWebhook = "ReceiveWebhook"
+
+// ReceiveWebhook starts an analysis of the branch and commit pushed, given by the push webhook of the
+// provider in the URL, once its signature is verified against the configured webhook secret.
+func ReceiveWebhook(c echo.Context) error {
+
+	RID := c.Response().Header().Get(echo.HeaderXRequestID)
+	provider := c.Param("provider")
+	if analysis.ShuttingDown() {
+		log.Warning(logActionReceiveWebhook, logInfoAnalysis, 124, RID)
+		reply := map[string]interface{}{"success": false, "error": "huskyCI is shutting down"}
+		return c.JSON(http.StatusServiceUnavailable, reply)
+	}
+
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		log.Warning(logActionReceiveWebhook, logInfoAnalysis, 128, provider, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid webhook payload"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	if err := webhook.VerifySignature(provider, apiContext.APIConfiguration.WebhookSecret, c.Request().Header, body); err != nil {
+		if err == webhook.ErrUnknownProvider {
+			reply := map[string]interface{}{"success": false, "error": "webhook provider not found"}
+			return c.JSON(http.StatusNotFound, reply)
+		}
+		log.Warning(logActionReceiveWebhook, logInfoAnalysis, 127, provider, err)
+		reply := map[string]interface{}{"success": false, "error": "permission denied"}
+		return c.JSON(http.StatusUnauthorized, reply)
+	}
+
+	if !webhook.IsPush(provider, c.Request().Header) {
+		log.Info(logActionReceiveWebhook, logInfoAnalysis, 30, provider)
+		reply := map[string]interface{}{"success": true, "error": "", "ignored": true}
+		return c.JSON(http.StatusOK, reply)
+	}
+	repository, err := webhook.ParsePush(provider, body)
+	if err == webhook.ErrNotBranchPush {
+		log.Info(logActionReceiveWebhook, logInfoAnalysis, 30, provider)
+		reply := map[string]interface{}{"success": true, "error": "", "ignored": true}
+		return c.JSON(http.StatusOK, reply)
+	}
+	if err != nil {
+		log.Warning(logActionReceiveWebhook, logInfoAnalysis, 128, provider, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid webhook payload"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	return submitAnalysis(c, RID, repository)
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routes_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/routes"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var _ = Describe("ReceiveWebhook", func() {
+
+	e := echo.New()
+	fakeDB := &fakeCacheDB{
+		fakeRerunDB:  fakeRerunDB{inserted: make(chan types.Analysis, 1)},
+		cacheQueries: make(chan map[string]interface{}, 1),
+	}
+	pushBody := `{"ref": "refs/heads/master", "after": "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d", "repository": {"clone_url": "https://github.com/globocom/huskyCI.git"}}`
+
+	var previousConfig *apiContext.APIConfig
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		apiContext.APIConfiguration = &apiContext.APIConfig{
+			DBInstance:    fakeDB,
+			WebhookSecret: "s3cr3t",
+		}
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	doRequest := func(provider, event, signature, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/"+provider, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-GitHub-Event", event)
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Response().Header().Set(echo.HeaderXRequestID, "d4e5f6")
+		c.SetParamNames("provider")
+		c.SetParamValues(provider)
+		Expect(routes.ReceiveWebhook(c)).To(Succeed())
+		return rec
+	}
+
+	Context("When a signed push webhook is received", func() {
+		It("Should start an analysis of the commit pushed.", func() {
+			rec := doRequest("github", "push", signWebhook("s3cr3t", pushBody), pushBody)
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			var inserted types.Analysis
+			Eventually(fakeDB.inserted).Should(Receive(&inserted))
+			Expect(inserted.RID).To(Equal("d4e5f6"))
+			Expect(inserted.URL).To(Equal("https://github.com/globocom/huskyCI.git"))
+			Expect(inserted.Branch).To(Equal("master"))
+			Expect(inserted.Commit).To(Equal("4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d"))
+		})
+	})
+
+	Context("When the webhook is not signed", func() {
+		It("Should return unauthorized without starting an analysis.", func() {
+			rec := doRequest("github", "push", "", pushBody)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": false, "error": "permission denied"}`))
+			Consistently(fakeDB.inserted).ShouldNot(Receive())
+		})
+	})
+
+	Context("When the webhook is signed with another secret", func() {
+		It("Should return unauthorized without starting an analysis.", func() {
+			rec := doRequest("github", "push", signWebhook("another", pushBody), pushBody)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Consistently(fakeDB.inserted).ShouldNot(Receive())
+		})
+	})
+
+	Context("When no webhook secret is configured", func() {
+		It("Should return unauthorized without starting an analysis.", func() {
+			apiContext.APIConfiguration.WebhookSecret = ""
+			rec := doRequest("github", "push", signWebhook("", pushBody), pushBody)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Consistently(fakeDB.inserted).ShouldNot(Receive())
+		})
+	})
+
+	Context("When a signed webhook of another event is received", func() {
+		It("Should ignore it.", func() {
+			body := `{"zen": "Keep it logically awesome."}`
+			rec := doRequest("github", "ping", signWebhook("s3cr3t", body), body)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(MatchJSON(`{"success": true, "error": "", "ignored": true}`))
+			Consistently(fakeDB.inserted).ShouldNot(Receive())
+		})
+	})
+
+	Context("When the provider is unknown", func() {
+		It("Should return not found.", func() {
+			rec := doRequest("bitbucket", "push", signWebhook("s3cr3t", pushBody), pushBody)
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+			Consistently(fakeDB.inserted).ShouldNot(Receive())
+		})
+	})
+})
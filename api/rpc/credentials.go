@@ -0,0 +1,41 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// HuskyToken is the access token of a repository sent as a call credential, authorizing the analysis calls
+// as the Husky-Token header does in the HTTP API.
+type HuskyToken string
+
+// GetRequestMetadata returns the husky-token metadata of the call.
+func (t HuskyToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"husky-token": string(t)}, nil
+}
+
+// RequireTransportSecurity returns false, as the HTTP API accepts access tokens without TLS when it is disabled.
+func (t HuskyToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// BasicAuth is the credential of an API user sent as a call credential, authenticating the token calls.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// GetRequestMetadata returns the authorization metadata of the call.
+func (b BasicAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	credentials := base64.StdEncoding.EncodeToString([]byte(b.Username + ":" + b.Password))
+	return map[string]string{"authorization": "Basic " + credentials}, nil
+}
+
+// RequireTransportSecurity returns false, as the HTTP API accepts basic auth without TLS when it is disabled.
+func (b BasicAuth) RequireTransportSecurity() bool {
+	return false
+}
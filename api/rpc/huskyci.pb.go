@@ -0,0 +1,1536 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.23.0
+// 	protoc        (unknown)
+// source: huskyci.proto
+
+package rpc
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type TokenRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RepositoryUrl string `protobuf:"bytes,1,opt,name=repository_url,json=repositoryUrl,proto3" json:"repository_url,omitempty"`
+}
+
+func (x *TokenRequest) Reset() {
+	*x = TokenRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenRequest) ProtoMessage() {}
+
+func (x *TokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenRequest.ProtoReflect.Descriptor instead.
+func (*TokenRequest) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TokenRequest) GetRepositoryUrl() string {
+	if x != nil {
+		return x.RepositoryUrl
+	}
+	return ""
+}
+
+type TokenReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HuskyToken string `protobuf:"bytes,1,opt,name=husky_token,json=huskyToken,proto3" json:"husky_token,omitempty"`
+}
+
+func (x *TokenReply) Reset() {
+	*x = TokenReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenReply) ProtoMessage() {}
+
+func (x *TokenReply) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenReply.ProtoReflect.Descriptor instead.
+func (*TokenReply) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TokenReply) GetHuskyToken() string {
+	if x != nil {
+		return x.HuskyToken
+	}
+	return ""
+}
+
+type TokenPair struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HuskyToken    string `protobuf:"bytes,1,opt,name=husky_token,json=huskyToken,proto3" json:"husky_token,omitempty"`
+	RepositoryUrl string `protobuf:"bytes,2,opt,name=repository_url,json=repositoryUrl,proto3" json:"repository_url,omitempty"`
+}
+
+func (x *TokenPair) Reset() {
+	*x = TokenPair{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenPair) ProtoMessage() {}
+
+func (x *TokenPair) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenPair.ProtoReflect.Descriptor instead.
+func (*TokenPair) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TokenPair) GetHuskyToken() string {
+	if x != nil {
+		return x.HuskyToken
+	}
+	return ""
+}
+
+func (x *TokenPair) GetRepositoryUrl() string {
+	if x != nil {
+		return x.RepositoryUrl
+	}
+	return ""
+}
+
+type ValidateTokensRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tokens []*TokenPair `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+}
+
+func (x *ValidateTokensRequest) Reset() {
+	*x = ValidateTokensRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTokensRequest) ProtoMessage() {}
+
+func (x *ValidateTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTokensRequest.ProtoReflect.Descriptor instead.
+func (*ValidateTokensRequest) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValidateTokensRequest) GetTokens() []*TokenPair {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type TokenValidation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RepositoryUrl string `protobuf:"bytes,1,opt,name=repository_url,json=repositoryUrl,proto3" json:"repository_url,omitempty"`
+	Valid         bool   `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"`
+}
+
+func (x *TokenValidation) Reset() {
+	*x = TokenValidation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenValidation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenValidation) ProtoMessage() {}
+
+func (x *TokenValidation) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenValidation.ProtoReflect.Descriptor instead.
+func (*TokenValidation) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TokenValidation) GetRepositoryUrl() string {
+	if x != nil {
+		return x.RepositoryUrl
+	}
+	return ""
+}
+
+func (x *TokenValidation) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+type ValidateTokensReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*TokenValidation `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *ValidateTokensReply) Reset() {
+	*x = ValidateTokensReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateTokensReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTokensReply) ProtoMessage() {}
+
+func (x *ValidateTokensReply) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTokensReply.ProtoReflect.Descriptor instead.
+func (*ValidateTokensReply) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValidateTokensReply) GetResults() []*TokenValidation {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SecurityTestArgs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Args []string `protobuf:"bytes,1,rep,name=args,proto3" json:"args,omitempty"`
+}
+
+func (x *SecurityTestArgs) Reset() {
+	*x = SecurityTestArgs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecurityTestArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityTestArgs) ProtoMessage() {}
+
+func (x *SecurityTestArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityTestArgs.ProtoReflect.Descriptor instead.
+func (*SecurityTestArgs) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SecurityTestArgs) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+// SecurityTestEnv holds the environment variables set in the container of a securityTest, each value either
+// plain or "secret:NAME", referencing a secret of the secret store.
+type SecurityTestEnv struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Env map[string]string `protobuf:"bytes,1,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *SecurityTestEnv) Reset() {
+	*x = SecurityTestEnv{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecurityTestEnv) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityTestEnv) ProtoMessage() {}
+
+func (x *SecurityTestEnv) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityTestEnv.ProtoReflect.Descriptor instead.
+func (*SecurityTestEnv) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SecurityTestEnv) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+// AnalysisRequest holds the fields of the repository JSON of POST /analysis.
+type AnalysisRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RepositoryUrl     string                       `protobuf:"bytes,1,opt,name=repository_url,json=repositoryUrl,proto3" json:"repository_url,omitempty"`
+	RepositoryBranch  string                       `protobuf:"bytes,2,opt,name=repository_branch,json=repositoryBranch,proto3" json:"repository_branch,omitempty"`
+	RepositorySubPath string                       `protobuf:"bytes,3,opt,name=repository_sub_path,json=repositorySubPath,proto3" json:"repository_sub_path,omitempty"`
+	RepositoryCommit  string                       `protobuf:"bytes,4,opt,name=repository_commit,json=repositoryCommit,proto3" json:"repository_commit,omitempty"`
+	TimeOutInSeconds  int32                        `protobuf:"varint,5,opt,name=time_out_in_seconds,json=timeOutInSeconds,proto3" json:"time_out_in_seconds,omitempty"`
+	FailFastSeverity  string                       `protobuf:"bytes,6,opt,name=fail_fast_severity,json=failFastSeverity,proto3" json:"fail_fast_severity,omitempty"`
+	SshPrivateKey     string                       `protobuf:"bytes,7,opt,name=ssh_private_key,json=sshPrivateKey,proto3" json:"ssh_private_key,omitempty"`
+	ImageReference    string                       `protobuf:"bytes,8,opt,name=image_reference,json=imageReference,proto3" json:"image_reference,omitempty"`
+	RepositoryBaseRef string                       `protobuf:"bytes,9,opt,name=repository_base_ref,json=repositoryBaseRef,proto3" json:"repository_base_ref,omitempty"`
+	Force             bool                         `protobuf:"varint,10,opt,name=force,proto3" json:"force,omitempty"`
+	SecurityTestArgs  map[string]*SecurityTestArgs `protobuf:"bytes,11,rep,name=security_test_args,json=securityTestArgs,proto3" json:"security_test_args,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// profile is the scan profile whose securityTests run instead of all the default ones.
+	Profile string `protobuf:"bytes,12,opt,name=profile,proto3" json:"profile,omitempty"`
+	// tags label the analysis, such as the team or the pipeline that submitted it.
+	Tags            map[string]string           `protobuf:"bytes,13,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	SecurityTestEnv map[string]*SecurityTestEnv `protobuf:"bytes,14,rep,name=security_test_env,json=securityTestEnv,proto3" json:"security_test_env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *AnalysisRequest) Reset() {
+	*x = AnalysisRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalysisRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalysisRequest) ProtoMessage() {}
+
+func (x *AnalysisRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalysisRequest.ProtoReflect.Descriptor instead.
+func (*AnalysisRequest) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AnalysisRequest) GetRepositoryUrl() string {
+	if x != nil {
+		return x.RepositoryUrl
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetRepositoryBranch() string {
+	if x != nil {
+		return x.RepositoryBranch
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetRepositorySubPath() string {
+	if x != nil {
+		return x.RepositorySubPath
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetRepositoryCommit() string {
+	if x != nil {
+		return x.RepositoryCommit
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetTimeOutInSeconds() int32 {
+	if x != nil {
+		return x.TimeOutInSeconds
+	}
+	return 0
+}
+
+func (x *AnalysisRequest) GetFailFastSeverity() string {
+	if x != nil {
+		return x.FailFastSeverity
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetSshPrivateKey() string {
+	if x != nil {
+		return x.SshPrivateKey
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetImageReference() string {
+	if x != nil {
+		return x.ImageReference
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetRepositoryBaseRef() string {
+	if x != nil {
+		return x.RepositoryBaseRef
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *AnalysisRequest) GetSecurityTestArgs() map[string]*SecurityTestArgs {
+	if x != nil {
+		return x.SecurityTestArgs
+	}
+	return nil
+}
+
+func (x *AnalysisRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *AnalysisRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *AnalysisRequest) GetSecurityTestEnv() map[string]*SecurityTestEnv {
+	if x != nil {
+		return x.SecurityTestEnv
+	}
+	return nil
+}
+
+type SubmitAnalysisReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rid string `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	// cached states that the commit was recently analyzed and rid is the RID of that analysis.
+	Cached bool `protobuf:"varint,2,opt,name=cached,proto3" json:"cached,omitempty"`
+}
+
+func (x *SubmitAnalysisReply) Reset() {
+	*x = SubmitAnalysisReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitAnalysisReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAnalysisReply) ProtoMessage() {}
+
+func (x *SubmitAnalysisReply) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAnalysisReply.ProtoReflect.Descriptor instead.
+func (*SubmitAnalysisReply) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SubmitAnalysisReply) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *SubmitAnalysisReply) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type GetAnalysisRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rid string `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+}
+
+func (x *GetAnalysisRequest) Reset() {
+	*x = GetAnalysisRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAnalysisRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnalysisRequest) ProtoMessage() {}
+
+func (x *GetAnalysisRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnalysisRequest.ProtoReflect.Descriptor instead.
+func (*GetAnalysisRequest) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetAnalysisRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+type SecurityTestStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Result string `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	Info   string `protobuf:"bytes,4,opt,name=info,proto3" json:"info,omitempty"`
+}
+
+func (x *SecurityTestStatus) Reset() {
+	*x = SecurityTestStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecurityTestStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityTestStatus) ProtoMessage() {}
+
+func (x *SecurityTestStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityTestStatus.ProtoReflect.Descriptor instead.
+func (*SecurityTestStatus) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SecurityTestStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SecurityTestStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SecurityTestStatus) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *SecurityTestStatus) GetInfo() string {
+	if x != nil {
+		return x.Info
+	}
+	return ""
+}
+
+type Analysis struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rid              string                `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	RepositoryUrl    string                `protobuf:"bytes,2,opt,name=repository_url,json=repositoryUrl,proto3" json:"repository_url,omitempty"`
+	RepositoryBranch string                `protobuf:"bytes,3,opt,name=repository_branch,json=repositoryBranch,proto3" json:"repository_branch,omitempty"`
+	RepositoryCommit string                `protobuf:"bytes,4,opt,name=repository_commit,json=repositoryCommit,proto3" json:"repository_commit,omitempty"`
+	Status           string                `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Result           string                `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	ErrorFound       string                `protobuf:"bytes,7,opt,name=error_found,json=errorFound,proto3" json:"error_found,omitempty"`
+	SecurityTests    []*SecurityTestStatus `protobuf:"bytes,8,rep,name=security_tests,json=securityTests,proto3" json:"security_tests,omitempty"`
+	// json is the analysis as returned by GET /analysis/{RID}, with the findings of every securityTest.
+	Json []byte `protobuf:"bytes,9,opt,name=json,proto3" json:"json,omitempty"`
+}
+
+func (x *Analysis) Reset() {
+	*x = Analysis{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_huskyci_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Analysis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Analysis) ProtoMessage() {}
+
+func (x *Analysis) ProtoReflect() protoreflect.Message {
+	mi := &file_huskyci_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Analysis.ProtoReflect.Descriptor instead.
+func (*Analysis) Descriptor() ([]byte, []int) {
+	return file_huskyci_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Analysis) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *Analysis) GetRepositoryUrl() string {
+	if x != nil {
+		return x.RepositoryUrl
+	}
+	return ""
+}
+
+func (x *Analysis) GetRepositoryBranch() string {
+	if x != nil {
+		return x.RepositoryBranch
+	}
+	return ""
+}
+
+func (x *Analysis) GetRepositoryCommit() string {
+	if x != nil {
+		return x.RepositoryCommit
+	}
+	return ""
+}
+
+func (x *Analysis) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Analysis) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *Analysis) GetErrorFound() string {
+	if x != nil {
+		return x.ErrorFound
+	}
+	return ""
+}
+
+func (x *Analysis) GetSecurityTests() []*SecurityTestStatus {
+	if x != nil {
+		return x.SecurityTests
+	}
+	return nil
+}
+
+func (x *Analysis) GetJson() []byte {
+	if x != nil {
+		return x.Json
+	}
+	return nil
+}
+
+var File_huskyci_proto protoreflect.FileDescriptor
+
+var file_huskyci_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x07, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x22, 0x35, 0x0a, 0x0c, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x55, 0x72, 0x6c, 0x22,
+	0x2d, 0x0a, 0x0a, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a,
+	0x0b, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x53,
+	0x0a, 0x09, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x50, 0x61, 0x69, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x68,
+	0x75, 0x73, 0x6b, 0x79, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x25, 0x0a, 0x0e,
+	0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79,
+	0x55, 0x72, 0x6c, 0x22, 0x43, 0x0a, 0x15, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x06,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x68,
+	0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x50, 0x61, 0x69, 0x72,
+	0x52, 0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x22, 0x4e, 0x0a, 0x0f, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x72,
+	0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x55,
+	0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x22, 0x49, 0x0a, 0x13, 0x56, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x32, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x22, 0x26, 0x0a, 0x10, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54,
+	0x65, 0x73, 0x74, 0x41, 0x72, 0x67, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x22, 0x7e, 0x0a, 0x0f, 0x53,
+	0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x45, 0x6e, 0x76, 0x12, 0x33,
+	0x0a, 0x03, 0x65, 0x6e, 0x76, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x68, 0x75,
+	0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65,
+	0x73, 0x74, 0x45, 0x6e, 0x76, 0x2e, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03,
+	0x65, 0x6e, 0x76, 0x1a, 0x36, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xb8, 0x07, 0x0a, 0x0f,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x25, 0x0a, 0x0e, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x72,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x6f, 0x72, 0x79, 0x55, 0x72, 0x6c, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x10, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x42, 0x72, 0x61,
+	0x6e, 0x63, 0x68, 0x12, 0x2e, 0x0a, 0x13, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72,
+	0x79, 0x5f, 0x73, 0x75, 0x62, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x53, 0x75, 0x62, 0x50,
+	0x61, 0x74, 0x68, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72,
+	0x79, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x12, 0x2d, 0x0a, 0x13, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x5f, 0x69, 0x6e, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x74,
+	0x69, 0x6d, 0x65, 0x4f, 0x75, 0x74, 0x49, 0x6e, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12,
+	0x2c, 0x0a, 0x12, 0x66, 0x61, 0x69, 0x6c, 0x5f, 0x66, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x76,
+	0x65, 0x72, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x66, 0x61, 0x69,
+	0x6c, 0x46, 0x61, 0x73, 0x74, 0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x26, 0x0a,
+	0x0f, 0x73, 0x73, 0x68, 0x5f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x73, 0x68, 0x50, 0x72, 0x69, 0x76, 0x61,
+	0x74, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x2e,
+	0x0a, 0x13, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x61, 0x73,
+	0x65, 0x5f, 0x72, 0x65, 0x66, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x72, 0x65, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x42, 0x61, 0x73, 0x65, 0x52, 0x65, 0x66, 0x12, 0x14,
+	0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66,
+	0x6f, 0x72, 0x63, 0x65, 0x12, 0x5c, 0x0a, 0x12, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79,
+	0x5f, 0x74, 0x65, 0x73, 0x74, 0x5f, 0x61, 0x72, 0x67, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x2e, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x73, 0x69, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72,
+	0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x41, 0x72, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x10, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x41, 0x72,
+	0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x68, 0x75, 0x73,
+	0x6b, 0x79, 0x63, 0x69, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x12, 0x59, 0x0a, 0x11, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79,
+	0x5f, 0x74, 0x65, 0x73, 0x74, 0x5f, 0x65, 0x6e, 0x76, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x2d, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73,
+	0x69, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69,
+	0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0f,
+	0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x45, 0x6e, 0x76, 0x1a,
+	0x5e, 0x0a, 0x15, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x41,
+	0x72, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2f, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x68, 0x75, 0x73, 0x6b,
+	0x79, 0x63, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74,
+	0x41, 0x72, 0x67, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0x37, 0x0a, 0x09, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x5c, 0x0a, 0x14, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x2e, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x45, 0x6e, 0x76, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x3f, 0x0a, 0x13, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x72, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x69, 0x64, 0x12,
+	0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x22, 0x26, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x41, 0x6e,
+	0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x72, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x69, 0x64, 0x22,
+	0x6c, 0x0a, 0x12, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x66,
+	0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0xc6, 0x02,
+	0x0a, 0x08, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x69, 0x64, 0x12, 0x25, 0x0a, 0x0e,
+	0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79,
+	0x55, 0x72, 0x6c, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72,
+	0x79, 0x5f, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x42, 0x72, 0x61, 0x6e, 0x63, 0x68,
+	0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x42,
+	0x0a, 0x0e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x74, 0x65, 0x73, 0x74, 0x73,
+	0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69,
+	0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73, 0x74, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x0d, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x54, 0x65, 0x73,
+	0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x32, 0xe2, 0x02, 0x0a, 0x07, 0x48, 0x75, 0x73, 0x6b, 0x79,
+	0x43, 0x49, 0x12, 0x3b, 0x0a, 0x0d, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x15, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x68, 0x75, 0x73,
+	0x6b, 0x79, 0x63, 0x69, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x4e, 0x0a, 0x0e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x73, 0x12, 0x1e, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x56, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1c, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x56, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x48, 0x0a, 0x0e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69,
+	0x73, 0x12, 0x18, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x73, 0x69, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x68, 0x75,
+	0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x73, 0x69, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x3d, 0x0a, 0x0b, 0x47, 0x65, 0x74,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x12, 0x1b, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79,
+	0x63, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69, 0x2e,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x12, 0x41, 0x0a, 0x0d, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x12, 0x1b, 0x2e, 0x68, 0x75, 0x73, 0x6b,
+	0x79, 0x63, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x63, 0x69,
+	0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x30, 0x01, 0x42, 0x25, 0x5a, 0x23, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6c, 0x6f, 0x62, 0x6f, 0x63,
+	0x6f, 0x6d, 0x2f, 0x68, 0x75, 0x73, 0x6b, 0x79, 0x43, 0x49, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x72,
+	0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_huskyci_proto_rawDescOnce sync.Once
+	file_huskyci_proto_rawDescData = file_huskyci_proto_rawDesc
+)
+
+func file_huskyci_proto_rawDescGZIP() []byte {
+	file_huskyci_proto_rawDescOnce.Do(func() {
+		file_huskyci_proto_rawDescData = protoimpl.X.CompressGZIP(file_huskyci_proto_rawDescData)
+	})
+	return file_huskyci_proto_rawDescData
+}
+
+var file_huskyci_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_huskyci_proto_goTypes = []interface{}{
+	(*TokenRequest)(nil),          // 0: huskyci.TokenRequest
+	(*TokenReply)(nil),            // 1: huskyci.TokenReply
+	(*TokenPair)(nil),             // 2: huskyci.TokenPair
+	(*ValidateTokensRequest)(nil), // 3: huskyci.ValidateTokensRequest
+	(*TokenValidation)(nil),       // 4: huskyci.TokenValidation
+	(*ValidateTokensReply)(nil),   // 5: huskyci.ValidateTokensReply
+	(*SecurityTestArgs)(nil),      // 6: huskyci.SecurityTestArgs
+	(*SecurityTestEnv)(nil),       // 7: huskyci.SecurityTestEnv
+	(*AnalysisRequest)(nil),       // 8: huskyci.AnalysisRequest
+	(*SubmitAnalysisReply)(nil),   // 9: huskyci.SubmitAnalysisReply
+	(*GetAnalysisRequest)(nil),    // 10: huskyci.GetAnalysisRequest
+	(*SecurityTestStatus)(nil),    // 11: huskyci.SecurityTestStatus
+	(*Analysis)(nil),              // 12: huskyci.Analysis
+	nil,                           // 13: huskyci.SecurityTestEnv.EnvEntry
+	nil,                           // 14: huskyci.AnalysisRequest.SecurityTestArgsEntry
+	nil,                           // 15: huskyci.AnalysisRequest.TagsEntry
+	nil,                           // 16: huskyci.AnalysisRequest.SecurityTestEnvEntry
+}
+var file_huskyci_proto_depIdxs = []int32{
+	2,  // 0: huskyci.ValidateTokensRequest.tokens:type_name -> huskyci.TokenPair
+	4,  // 1: huskyci.ValidateTokensReply.results:type_name -> huskyci.TokenValidation
+	13, // 2: huskyci.SecurityTestEnv.env:type_name -> huskyci.SecurityTestEnv.EnvEntry
+	14, // 3: huskyci.AnalysisRequest.security_test_args:type_name -> huskyci.AnalysisRequest.SecurityTestArgsEntry
+	15, // 4: huskyci.AnalysisRequest.tags:type_name -> huskyci.AnalysisRequest.TagsEntry
+	16, // 5: huskyci.AnalysisRequest.security_test_env:type_name -> huskyci.AnalysisRequest.SecurityTestEnvEntry
+	11, // 6: huskyci.Analysis.security_tests:type_name -> huskyci.SecurityTestStatus
+	6,  // 7: huskyci.AnalysisRequest.SecurityTestArgsEntry.value:type_name -> huskyci.SecurityTestArgs
+	7,  // 8: huskyci.AnalysisRequest.SecurityTestEnvEntry.value:type_name -> huskyci.SecurityTestEnv
+	0,  // 9: huskyci.HuskyCI.GenerateToken:input_type -> huskyci.TokenRequest
+	3,  // 10: huskyci.HuskyCI.ValidateTokens:input_type -> huskyci.ValidateTokensRequest
+	8,  // 11: huskyci.HuskyCI.SubmitAnalysis:input_type -> huskyci.AnalysisRequest
+	10, // 12: huskyci.HuskyCI.GetAnalysis:input_type -> huskyci.GetAnalysisRequest
+	10, // 13: huskyci.HuskyCI.WatchAnalysis:input_type -> huskyci.GetAnalysisRequest
+	1,  // 14: huskyci.HuskyCI.GenerateToken:output_type -> huskyci.TokenReply
+	5,  // 15: huskyci.HuskyCI.ValidateTokens:output_type -> huskyci.ValidateTokensReply
+	9,  // 16: huskyci.HuskyCI.SubmitAnalysis:output_type -> huskyci.SubmitAnalysisReply
+	12, // 17: huskyci.HuskyCI.GetAnalysis:output_type -> huskyci.Analysis
+	12, // 18: huskyci.HuskyCI.WatchAnalysis:output_type -> huskyci.Analysis
+	14, // [14:19] is the sub-list for method output_type
+	9,  // [9:14] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_huskyci_proto_init() }
+func file_huskyci_proto_init() {
+	if File_huskyci_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_huskyci_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenPair); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateTokensRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenValidation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateTokensReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecurityTestArgs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecurityTestEnv); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalysisRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitAnalysisReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAnalysisRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecurityTestStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_huskyci_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Analysis); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_huskyci_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_huskyci_proto_goTypes,
+		DependencyIndexes: file_huskyci_proto_depIdxs,
+		MessageInfos:      file_huskyci_proto_msgTypes,
+	}.Build()
+	File_huskyci_proto = out.File
+	file_huskyci_proto_rawDesc = nil
+	file_huskyci_proto_goTypes = nil
+	file_huskyci_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// HuskyCIClient is the client API for HuskyCI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type HuskyCIClient interface {
+	// GenerateToken generates an access token for a repository, as POST /api/1.0/token does.
+	GenerateToken(ctx context.Context, in *TokenRequest, opts ...grpc.CallOption) (*TokenReply, error)
+	// ValidateTokens validates a batch of access tokens, as POST /api/1.0/token/validate does.
+	ValidateTokens(ctx context.Context, in *ValidateTokensRequest, opts ...grpc.CallOption) (*ValidateTokensReply, error)
+	// SubmitAnalysis starts an analysis of a repository, as POST /analysis does.
+	SubmitAnalysis(ctx context.Context, in *AnalysisRequest, opts ...grpc.CallOption) (*SubmitAnalysisReply, error)
+	// GetAnalysis returns an analysis, as GET /analysis/{RID} does.
+	GetAnalysis(ctx context.Context, in *GetAnalysisRequest, opts ...grpc.CallOption) (*Analysis, error)
+	// WatchAnalysis streams an analysis every time its status or one of its securityTests changes, until it
+	// is no longer running.
+	WatchAnalysis(ctx context.Context, in *GetAnalysisRequest, opts ...grpc.CallOption) (HuskyCI_WatchAnalysisClient, error)
+}
+
+type huskyCIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHuskyCIClient(cc grpc.ClientConnInterface) HuskyCIClient {
+	return &huskyCIClient{cc}
+}
+
+func (c *huskyCIClient) GenerateToken(ctx context.Context, in *TokenRequest, opts ...grpc.CallOption) (*TokenReply, error) {
+	out := new(TokenReply)
+	err := c.cc.Invoke(ctx, "/huskyci.HuskyCI/GenerateToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *huskyCIClient) ValidateTokens(ctx context.Context, in *ValidateTokensRequest, opts ...grpc.CallOption) (*ValidateTokensReply, error) {
+	out := new(ValidateTokensReply)
+	err := c.cc.Invoke(ctx, "/huskyci.HuskyCI/ValidateTokens", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *huskyCIClient) SubmitAnalysis(ctx context.Context, in *AnalysisRequest, opts ...grpc.CallOption) (*SubmitAnalysisReply, error) {
+	out := new(SubmitAnalysisReply)
+	err := c.cc.Invoke(ctx, "/huskyci.HuskyCI/SubmitAnalysis", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *huskyCIClient) GetAnalysis(ctx context.Context, in *GetAnalysisRequest, opts ...grpc.CallOption) (*Analysis, error) {
+	out := new(Analysis)
+	err := c.cc.Invoke(ctx, "/huskyci.HuskyCI/GetAnalysis", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *huskyCIClient) WatchAnalysis(ctx context.Context, in *GetAnalysisRequest, opts ...grpc.CallOption) (HuskyCI_WatchAnalysisClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_HuskyCI_serviceDesc.Streams[0], "/huskyci.HuskyCI/WatchAnalysis", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &huskyCIWatchAnalysisClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type HuskyCI_WatchAnalysisClient interface {
+	Recv() (*Analysis, error)
+	grpc.ClientStream
+}
+
+type huskyCIWatchAnalysisClient struct {
+	grpc.ClientStream
+}
+
+func (x *huskyCIWatchAnalysisClient) Recv() (*Analysis, error) {
+	m := new(Analysis)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// HuskyCIServer is the server API for HuskyCI service.
+type HuskyCIServer interface {
+	// GenerateToken generates an access token for a repository, as POST /api/1.0/token does.
+	GenerateToken(context.Context, *TokenRequest) (*TokenReply, error)
+	// ValidateTokens validates a batch of access tokens, as POST /api/1.0/token/validate does.
+	ValidateTokens(context.Context, *ValidateTokensRequest) (*ValidateTokensReply, error)
+	// SubmitAnalysis starts an analysis of a repository, as POST /analysis does.
+	SubmitAnalysis(context.Context, *AnalysisRequest) (*SubmitAnalysisReply, error)
+	// GetAnalysis returns an analysis, as GET /analysis/{RID} does.
+	GetAnalysis(context.Context, *GetAnalysisRequest) (*Analysis, error)
+	// WatchAnalysis streams an analysis every time its status or one of its securityTests changes, until it
+	// is no longer running.
+	WatchAnalysis(*GetAnalysisRequest, HuskyCI_WatchAnalysisServer) error
+}
+
+// UnimplementedHuskyCIServer can be embedded to have forward compatible implementations.
+type UnimplementedHuskyCIServer struct {
+}
+
+func (*UnimplementedHuskyCIServer) GenerateToken(context.Context, *TokenRequest) (*TokenReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateToken not implemented")
+}
+func (*UnimplementedHuskyCIServer) ValidateTokens(context.Context, *ValidateTokensRequest) (*ValidateTokensReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateTokens not implemented")
+}
+func (*UnimplementedHuskyCIServer) SubmitAnalysis(context.Context, *AnalysisRequest) (*SubmitAnalysisReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitAnalysis not implemented")
+}
+func (*UnimplementedHuskyCIServer) GetAnalysis(context.Context, *GetAnalysisRequest) (*Analysis, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAnalysis not implemented")
+}
+func (*UnimplementedHuskyCIServer) WatchAnalysis(*GetAnalysisRequest, HuskyCI_WatchAnalysisServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAnalysis not implemented")
+}
+
+func RegisterHuskyCIServer(s *grpc.Server, srv HuskyCIServer) {
+	s.RegisterService(&_HuskyCI_serviceDesc, srv)
+}
+
+func _HuskyCI_GenerateToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HuskyCIServer).GenerateToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/huskyci.HuskyCI/GenerateToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HuskyCIServer).GenerateToken(ctx, req.(*TokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HuskyCI_ValidateTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HuskyCIServer).ValidateTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/huskyci.HuskyCI/ValidateTokens",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HuskyCIServer).ValidateTokens(ctx, req.(*ValidateTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HuskyCI_SubmitAnalysis_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalysisRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HuskyCIServer).SubmitAnalysis(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/huskyci.HuskyCI/SubmitAnalysis",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HuskyCIServer).SubmitAnalysis(ctx, req.(*AnalysisRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HuskyCI_GetAnalysis_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAnalysisRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HuskyCIServer).GetAnalysis(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/huskyci.HuskyCI/GetAnalysis",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HuskyCIServer).GetAnalysis(ctx, req.(*GetAnalysisRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HuskyCI_WatchAnalysis_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAnalysisRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HuskyCIServer).WatchAnalysis(m, &huskyCIWatchAnalysisServer{stream})
+}
+
+type HuskyCI_WatchAnalysisServer interface {
+	Send(*Analysis) error
+	grpc.ServerStream
+}
+
+type huskyCIWatchAnalysisServer struct {
+	grpc.ServerStream
+}
+
+func (x *huskyCIWatchAnalysisServer) Send(m *Analysis) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _HuskyCI_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "huskyci.HuskyCI",
+	HandlerType: (*HuskyCIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateToken",
+			Handler:    _HuskyCI_GenerateToken_Handler,
+		},
+		{
+			MethodName: "ValidateTokens",
+			Handler:    _HuskyCI_ValidateTokens_Handler,
+		},
+		{
+			MethodName: "SubmitAnalysis",
+			Handler:    _HuskyCI_SubmitAnalysis_Handler,
+		},
+		{
+			MethodName: "GetAnalysis",
+			Handler:    _HuskyCI_GetAnalysis_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAnalysis",
+			Handler:       _HuskyCI_WatchAnalysis_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "huskyci.proto",
+}
@@ -0,0 +1,19 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc_test
+
+import (
+	"testing"
+
+	"github.com/globocom/huskyCI/api/log"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRPC(t *testing.T) {
+	RegisterFailHandler(Fail)
+	log.InitLog(true, "", "", "log_test", "log_test")
+	RunSpecs(t, "RPC Suite")
+}
@@ -0,0 +1,324 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. huskyci.proto
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/globocom/huskyCI/api/analysis"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPollInterval is how often WatchAnalysis looks for changes of the analysis.
+const DefaultPollInterval = 2 * time.Second
+
+// watchNotFoundTimeout is how long WatchAnalysis waits for an analysis to be registered, as SubmitAnalysis
+// replies before the analysis is stored.
+const watchNotFoundTimeout = 30 * time.Second
+
+// Server implements HuskyCIServer by dispatching every call to the route of the HTTP API it mirrors, so that
+// both APIs share the same authentication, checks and handlers.
+type Server struct {
+	handler      http.Handler
+	PollInterval time.Duration
+}
+
+// NewServer returns a Server dispatching the calls to handler, that serves the routes of the HTTP API.
+func NewServer(handler http.Handler) *Server {
+	return &Server{handler: handler, PollInterval: DefaultPollInterval}
+}
+
+// NewGRPCServer returns a gRPC server with the HuskyCI service registered, dispatching the calls to handler.
+// If tlsConfig is set, it is served with the same TLS configuration as the HTTP API, mutual TLS included.
+func NewGRPCServer(handler http.Handler, tlsConfig *tls.Config) (*grpc.Server, error) {
+	options := []grpc.ServerOption{}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(options...)
+	RegisterHuskyCIServer(grpcServer, NewServer(handler))
+	return grpcServer, nil
+}
+
+// GenerateToken generates an access token for a repository, as POST /api/1.0/token does.
+func (s *Server) GenerateToken(ctx context.Context, request *TokenRequest) (*TokenReply, error) {
+	response, err := s.dispatch(ctx, http.MethodPost, "/api/1.0/token", types.TokenRequest{RepositoryURL: request.RepositoryUrl})
+	if err != nil {
+		return nil, err
+	}
+	accessToken := types.AccessToken{}
+	if err := response.decode(&accessToken); err != nil {
+		return nil, err
+	}
+	return &TokenReply{HuskyToken: accessToken.HuskyToken}, nil
+}
+
+// ValidateTokens validates a batch of access tokens, as POST /api/1.0/token/validate does.
+func (s *Server) ValidateTokens(ctx context.Context, request *ValidateTokensRequest) (*ValidateTokensReply, error) {
+	batch := types.TokenValidationBatch{}
+	for _, pair := range request.Tokens {
+		batch.Tokens = append(batch.Tokens, types.TokenValidationRequest{HuskyToken: pair.HuskyToken, RepositoryURL: pair.RepositoryUrl})
+	}
+	response, err := s.dispatch(ctx, http.MethodPost, "/api/1.0/token/validate", batch)
+	if err != nil {
+		return nil, err
+	}
+	validation := struct {
+		Results []types.TokenValidationResult `json:"results"`
+	}{}
+	if err := response.decode(&validation); err != nil {
+		return nil, err
+	}
+	reply := &ValidateTokensReply{}
+	for _, result := range validation.Results {
+		reply.Results = append(reply.Results, &TokenValidation{RepositoryUrl: result.RepositoryURL, Valid: result.Valid})
+	}
+	return reply, nil
+}
+
+// SubmitAnalysis starts an analysis of a repository, as POST /analysis does.
+func (s *Server) SubmitAnalysis(ctx context.Context, request *AnalysisRequest) (*SubmitAnalysisReply, error) {
+	repository := types.Repository{
+		URL:              request.RepositoryUrl,
+		Branch:           request.RepositoryBranch,
+		SubPath:          request.RepositorySubPath,
+		Commit:           request.RepositoryCommit,
+		TimeOutInSeconds: int(request.TimeOutInSeconds),
+		FailFastSeverity: request.FailFastSeverity,
+		SSHPrivateKey:    request.SshPrivateKey,
+		ImageReference:   request.ImageReference,
+		BaseRef:          request.RepositoryBaseRef,
+		Force:            request.Force,
+		Profile:          request.Profile,
+		Tags:             request.Tags,
+	}
+	for tool, securityTestArgs := range request.SecurityTestArgs {
+		if repository.SecurityTestArgs == nil {
+			repository.SecurityTestArgs = make(map[string][]string)
+		}
+		repository.SecurityTestArgs[tool] = securityTestArgs.GetArgs()
+	}
+	// the HTTP API validates the securityTestEnv and the secrets it references, as for POST /analysis.
+	for tool, securityTestEnv := range request.SecurityTestEnv {
+		if repository.SecurityTestEnv == nil {
+			repository.SecurityTestEnv = make(map[string]map[string]string)
+		}
+		repository.SecurityTestEnv[tool] = securityTestEnv.GetEnv()
+	}
+	response, err := s.dispatch(ctx, http.MethodPost, "/analysis", repository)
+	if err != nil {
+		return nil, err
+	}
+	submitted := struct {
+		Cached bool `json:"cached"`
+	}{}
+	if err := response.decode(&submitted); err != nil {
+		return nil, err
+	}
+	return &SubmitAnalysisReply{Rid: response.header.Get(echo.HeaderXRequestID), Cached: submitted.Cached}, nil
+}
+
+// GetAnalysis returns an analysis, as GET /analysis/:id does.
+func (s *Server) GetAnalysis(ctx context.Context, request *GetAnalysisRequest) (*Analysis, error) {
+	response := s.serve(ctx, http.MethodGet, "/analysis/"+url.PathEscape(request.Rid), nil)
+	// the status of an analysis may be configured to be replied with an error status, but it is still found.
+	analysis := types.Analysis{}
+	if json.Unmarshal(response.body.Bytes(), &analysis) != nil || analysis.RID == "" {
+		return nil, response.err()
+	}
+	reply := &Analysis{
+		Rid:              analysis.RID,
+		RepositoryUrl:    analysis.URL,
+		RepositoryBranch: analysis.Branch,
+		RepositoryCommit: analysis.Commit,
+		Status:           analysis.Status,
+		Result:           analysis.Result,
+		ErrorFound:       analysis.ErrorFound,
+		Json:             response.body.Bytes(),
+	}
+	for _, container := range analysis.Containers {
+		reply.SecurityTests = append(reply.SecurityTests, &SecurityTestStatus{
+			Name:   container.SecurityTest.Name,
+			Status: container.CStatus,
+			Result: container.CResult,
+			Info:   container.CInfo,
+		})
+	}
+	return reply, nil
+}
+
+// WatchAnalysis streams an analysis every time its status or one of its securityTests changes, until it is
+// no longer queued or running.
+func (s *Server) WatchAnalysis(request *GetAnalysisRequest, stream HuskyCI_WatchAnalysisServer) error {
+	var last *Analysis
+	notFoundUntil := time.Now().Add(watchNotFoundTimeout)
+	for {
+		current, err := s.GetAnalysis(stream.Context(), request)
+		if err != nil && (last != nil || status.Code(err) != codes.NotFound || time.Now().After(notFoundUntil)) {
+			return err
+		}
+		if err == nil {
+			if last == nil || !sameProgress(last, current) {
+				if err := stream.Send(current); err != nil {
+					return err
+				}
+				last = current
+			}
+			if !analysis.Unfinished(current.Status) {
+				return nil
+			}
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, stream.Context().Err().Error())
+		case <-time.After(s.PollInterval):
+		}
+	}
+}
+
+// sameProgress tells whether analyses a and b have the same status and securityTests.
+func sameProgress(a, b *Analysis) bool {
+	if a.Status != b.Status || a.Result != b.Result || a.ErrorFound != b.ErrorFound || len(a.SecurityTests) != len(b.SecurityTests) {
+		return false
+	}
+	for i := range a.SecurityTests {
+		x, y := a.SecurityTests[i], b.SecurityTests[i]
+		if x.Name != y.Name || x.Status != y.Status || x.Result != y.Result || x.Info != y.Info {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch serves a request of the HTTP API with body as JSON and returns its response, or the gRPC error
+// matching its status if it did not succeed.
+func (s *Server) dispatch(ctx context.Context, method, path string, body interface{}) (*response, error) {
+	response := s.serve(ctx, method, path, body)
+	if response.status < http.StatusOK || response.status >= http.StatusMultipleChoices {
+		return nil, response.err()
+	}
+	return response, nil
+}
+
+// serve serves a request of the HTTP API with body as JSON, authenticated by the credentials in the
+// metadata of the call.
+func (s *Server) serve(ctx context.Context, method, path string, body interface{}) *response {
+	var requestBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			return &response{status: http.StatusBadRequest, header: http.Header{}}
+		}
+	}
+	request, err := http.NewRequest(method, path, &requestBody)
+	if err != nil {
+		return &response{status: http.StatusBadRequest, header: http.Header{}}
+	}
+	request = request.WithContext(ctx)
+	// the routes see the TLS connection of the call, so that they check its client certificate with mutual TLS.
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			request.TLS = &tlsInfo.State
+		}
+	}
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if huskyToken := md.Get("husky-token"); len(huskyToken) > 0 {
+			request.Header.Set("Husky-Token", huskyToken[0])
+		}
+		if authorization := md.Get("authorization"); len(authorization) > 0 {
+			request.Header.Set(echo.HeaderAuthorization, authorization[0])
+		}
+	}
+	response := &response{header: http.Header{}}
+	s.handler.ServeHTTP(response, request)
+	return response
+}
+
+// response records the response of the HTTP API to a call.
+type response struct {
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *response) Header() http.Header {
+	return r.header
+}
+
+func (r *response) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *response) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *response) decode(v interface{}) error {
+	if err := json.Unmarshal(r.body.Bytes(), v); err != nil {
+		return status.Error(codes.Internal, "invalid reply")
+	}
+	return nil
+}
+
+// err returns the gRPC error matching the status of the response, with the error replied by the HTTP API.
+func (r *response) err() error {
+	reply := struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}{}
+	_ = json.Unmarshal(r.body.Bytes(), &reply)
+	message := reply.Error
+	if message == "" {
+		// echo replies its own errors, such as those of the basic auth middleware, with a message.
+		message = reply.Message
+	}
+	if message == "" {
+		message = http.StatusText(r.status)
+	}
+	return status.Error(code(r.status), message)
+}
+
+// code returns the gRPC code matching an HTTP status.
+func code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,299 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/globocom/huskyCI/api/containerenv"
+	"github.com/globocom/huskyCI/api/rpc"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeAPI serves the routes of the HTTP API the gRPC API dispatches to.
+type fakeAPI struct {
+	mutex        sync.Mutex
+	repositories []types.Repository
+	// progress are the analyses replied by the following GET /analysis/:id, the last one from then on.
+	progress []types.Analysis
+}
+
+func (f *fakeAPI) echo() *echo.Echo {
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	g := e.Group("/api/1.0")
+	g.Use(middleware.BasicAuth(func(username, password string, c echo.Context) (bool, error) {
+		return username == "husky" && password == "secret", nil
+	}))
+	g.POST("/token", func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]interface{}{"huskytoken": "dXVpZDpyYW5kb20="})
+	})
+	g.POST("/token/validate", func(c echo.Context) error {
+		batch := types.TokenValidationBatch{}
+		if err := c.Bind(&batch); err != nil {
+			return err
+		}
+		results := []types.TokenValidationResult{}
+		for _, pair := range batch.Tokens {
+			results = append(results, types.TokenValidationResult{RepositoryURL: pair.RepositoryURL, Valid: pair.HuskyToken == "valid"})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{"success": true, "error": "", "results": results})
+	})
+	e.POST("/analysis", func(c echo.Context) error {
+		if c.Request().Header.Get("Husky-Token") != "dXVpZDpyYW5kb20=" {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{"success": false, "error": "permission denied"})
+		}
+		repository := types.Repository{}
+		if err := c.Bind(&repository); err != nil {
+			return err
+		}
+		// the securityTestEnv is checked as POST /analysis checks it.
+		if err := containerenv.Check(repository.SecurityTestEnv, c); err != nil || c.Response().Committed {
+			return err
+		}
+		f.mutex.Lock()
+		f.repositories = append(f.repositories, repository)
+		f.mutex.Unlock()
+		return c.JSON(http.StatusCreated, map[string]interface{}{"success": true, "error": ""})
+	})
+	e.GET("/analysis/:id", func(c echo.Context) error {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		if c.Param("id") != "huskyRID" || len(f.progress) == 0 {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{"success": false, "error": "analysis not found"})
+		}
+		analysis := f.progress[0]
+		if len(f.progress) > 1 {
+			f.progress = f.progress[1:]
+		}
+		if analysis.Result == "failed" {
+			// as replied when the statuses of the analyses are configured.
+			return c.JSON(http.StatusUnprocessableEntity, analysis)
+		}
+		return c.JSON(http.StatusOK, analysis)
+	})
+	return e
+}
+
+var _ = Describe("Server", func() {
+
+	var api *fakeAPI
+	var grpcServer *grpc.Server
+	var listener *bufconn.Listener
+	var conns []*grpc.ClientConn
+	BeforeEach(func() {
+		api = &fakeAPI{}
+		listener = bufconn.Listen(1 << 20)
+		server := rpc.NewServer(api.echo())
+		server.PollInterval = 10 * time.Millisecond
+		grpcServer = grpc.NewServer()
+		rpc.RegisterHuskyCIServer(grpcServer, server)
+		go grpcServer.Serve(listener)
+	})
+	AfterEach(func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		conns = nil
+		grpcServer.Stop()
+	})
+
+	client := func(options ...grpc.DialOption) rpc.HuskyCIClient {
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.Dial()
+		}
+		options = append(options, grpc.WithContextDialer(dialer), grpc.WithInsecure())
+		conn, err := grpc.Dial("bufnet", options...)
+		Expect(err).NotTo(HaveOccurred())
+		conns = append(conns, conn)
+		return rpc.NewHuskyCIClient(conn)
+	}
+	runningAnalysis := func(containers ...types.Container) types.Analysis {
+		return types.Analysis{RID: "huskyRID", URL: "https://github.com/globocom/huskyCI.git", Branch: "master", Status: "running", Containers: containers}
+	}
+
+	Describe("GenerateToken", func() {
+		Context("When the call has the credentials of an API user", func() {
+			It("Should return the access token generated by the HTTP API.", func() {
+				reply, err := client(grpc.WithPerRPCCredentials(rpc.BasicAuth{Username: "husky", Password: "secret"})).
+					GenerateToken(context.Background(), &rpc.TokenRequest{RepositoryUrl: "https://github.com/globocom/huskyCI.git"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reply.HuskyToken).To(Equal("dXVpZDpyYW5kb20="))
+			})
+		})
+		Context("When the call has no credentials", func() {
+			It("Should return unauthenticated.", func() {
+				_, err := client().GenerateToken(context.Background(), &rpc.TokenRequest{RepositoryUrl: "https://github.com/globocom/huskyCI.git"})
+				Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+			})
+		})
+	})
+
+	Describe("ValidateTokens", func() {
+		It("Should return the validity of each pair in order.", func() {
+			reply, err := client(grpc.WithPerRPCCredentials(rpc.BasicAuth{Username: "husky", Password: "secret"})).
+				ValidateTokens(context.Background(), &rpc.ValidateTokensRequest{Tokens: []*rpc.TokenPair{
+					{HuskyToken: "valid", RepositoryUrl: "https://github.com/globocom/huskyCI.git"},
+					{HuskyToken: "invalid", RepositoryUrl: "https://github.com/globocom/glbgelf.git"},
+				}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reply.Results).To(HaveLen(2))
+			Expect(reply.Results[0].Valid).To(BeTrue())
+			Expect(reply.Results[1].RepositoryUrl).To(Equal("https://github.com/globocom/glbgelf.git"))
+			Expect(reply.Results[1].Valid).To(BeFalse())
+		})
+	})
+
+	Describe("SubmitAnalysis", func() {
+		Context("When the call has the access token of the repository", func() {
+			It("Should start the analysis and return its RID.", func() {
+				reply, err := client(grpc.WithPerRPCCredentials(rpc.HuskyToken("dXVpZDpyYW5kb20="))).
+					SubmitAnalysis(context.Background(), &rpc.AnalysisRequest{
+						RepositoryUrl:    "https://github.com/globocom/huskyCI.git",
+						RepositoryBranch: "master",
+						SecurityTestArgs: map[string]*rpc.SecurityTestArgs{"gosec": {Args: []string{"-exclude=G104"}}},
+						Profile:          "quick",
+					})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reply.Rid).NotTo(BeEmpty())
+				Expect(reply.Cached).To(BeFalse())
+				Expect(api.repositories).To(HaveLen(1))
+				Expect(api.repositories[0].Branch).To(Equal("master"))
+				Expect(api.repositories[0].SecurityTestArgs).To(Equal(map[string][]string{"gosec": {"-exclude=G104"}}))
+				Expect(api.repositories[0].Profile).To(Equal("quick"))
+			})
+		})
+		Context("When the call has tags", func() {
+			It("Should submit the analysis with its tags.", func() {
+				_, err := client(grpc.WithPerRPCCredentials(rpc.HuskyToken("dXVpZDpyYW5kb20="))).
+					SubmitAnalysis(context.Background(), &rpc.AnalysisRequest{
+						RepositoryUrl:    "https://github.com/globocom/huskyCI.git",
+						RepositoryBranch: "master",
+						Tags:             map[string]string{"team": "security", "pipeline-id": "1234"},
+					})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.repositories).To(HaveLen(1))
+				Expect(api.repositories[0].Tags).To(Equal(map[string]string{"team": "security", "pipeline-id": "1234"}))
+			})
+		})
+		Context("When the call has a securityTestEnv", func() {
+			It("Should submit the analysis with the environment of each securityTest.", func() {
+				_, err := client(grpc.WithPerRPCCredentials(rpc.HuskyToken("dXVpZDpyYW5kb20="))).
+					SubmitAnalysis(context.Background(), &rpc.AnalysisRequest{
+						RepositoryUrl:    "https://github.com/globocom/huskyCI.git",
+						RepositoryBranch: "master",
+						SecurityTestEnv:  map[string]*rpc.SecurityTestEnv{"safety": {Env: map[string]string{"ADVISORY_DB_TOKEN": "secret:advisory-db-token"}}},
+					})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.repositories).To(HaveLen(1))
+				Expect(api.repositories[0].SecurityTestEnv).To(Equal(map[string]map[string]string{"safety": {"ADVISORY_DB_TOKEN": "secret:advisory-db-token"}}))
+			})
+			It("Should return the error of the HTTP API if it rejects the securityTestEnv.", func() {
+				_, err := client(grpc.WithPerRPCCredentials(rpc.HuskyToken("dXVpZDpyYW5kb20="))).
+					SubmitAnalysis(context.Background(), &rpc.AnalysisRequest{
+						RepositoryUrl:    "https://github.com/globocom/huskyCI.git",
+						RepositoryBranch: "master",
+						SecurityTestEnv:  map[string]*rpc.SecurityTestEnv{"safety": {Env: map[string]string{"PATH": "/tmp"}}},
+					})
+				Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+				Expect(status.Convert(err).Message()).To(Equal("invalid securityTestEnv"))
+				Expect(api.repositories).To(BeEmpty())
+			})
+		})
+		Context("When the call has no access token", func() {
+			It("Should return unauthenticated with the error of the HTTP API.", func() {
+				_, err := client().SubmitAnalysis(context.Background(), &rpc.AnalysisRequest{RepositoryUrl: "https://github.com/globocom/huskyCI.git"})
+				Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+				Expect(status.Convert(err).Message()).To(Equal("permission denied"))
+			})
+		})
+	})
+
+	Describe("GetAnalysis", func() {
+		Context("When the analysis is found", func() {
+			It("Should return its status and the status of its securityTests.", func() {
+				api.progress = []types.Analysis{runningAnalysis(types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "running"})}
+				analysis, err := client().GetAnalysis(context.Background(), &rpc.GetAnalysisRequest{Rid: "huskyRID"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analysis.Status).To(Equal("running"))
+				Expect(analysis.SecurityTests).To(HaveLen(1))
+				Expect(analysis.SecurityTests[0].Name).To(Equal("gosec"))
+				Expect(string(analysis.Json)).To(ContainSubstring(`"RID":"huskyRID"`))
+			})
+			It("Should return it even if the HTTP API replies it with an error status.", func() {
+				failedAnalysis := runningAnalysis()
+				failedAnalysis.Status, failedAnalysis.Result = "finished", "failed"
+				api.progress = []types.Analysis{failedAnalysis}
+				analysis, err := client().GetAnalysis(context.Background(), &rpc.GetAnalysisRequest{Rid: "huskyRID"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analysis.Result).To(Equal("failed"))
+			})
+		})
+		Context("When the analysis is not found", func() {
+			It("Should return not found.", func() {
+				_, err := client().GetAnalysis(context.Background(), &rpc.GetAnalysisRequest{Rid: "otherRID"})
+				Expect(status.Code(err)).To(Equal(codes.NotFound))
+			})
+		})
+	})
+
+	Describe("WatchAnalysis", func() {
+		It("Should stream each change of the analysis until it is no longer running.", func() {
+			gosecRunning := types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "running"}
+			gosecPassed := types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "finished", CResult: "passed"}
+			finishedAnalysis := runningAnalysis(gosecPassed)
+			finishedAnalysis.Status, finishedAnalysis.Result = "finished", "passed"
+			api.progress = []types.Analysis{
+				runningAnalysis(gosecRunning),
+				runningAnalysis(gosecRunning),
+				runningAnalysis(gosecPassed),
+				finishedAnalysis,
+			}
+			stream, err := client().WatchAnalysis(context.Background(), &rpc.GetAnalysisRequest{Rid: "huskyRID"})
+			Expect(err).NotTo(HaveOccurred())
+			updates := []*rpc.Analysis{}
+			for {
+				analysis, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				updates = append(updates, analysis)
+			}
+			Expect(updates).To(HaveLen(3))
+			Expect(updates[0].SecurityTests[0].Status).To(Equal("running"))
+			Expect(updates[1].SecurityTests[0].Result).To(Equal("passed"))
+			Expect(updates[2].Status).To(Equal("finished"))
+		})
+
+		It("Should wait for the analysis to be registered.", func() {
+			stream, err := client().WatchAnalysis(context.Background(), &rpc.GetAnalysisRequest{Rid: "huskyRID"})
+			Expect(err).NotTo(HaveOccurred())
+			time.Sleep(50 * time.Millisecond)
+			finishedAnalysis := runningAnalysis()
+			finishedAnalysis.Status = "finished"
+			api.mutex.Lock()
+			api.progress = []types.Analysis{finishedAnalysis}
+			api.mutex.Unlock()
+			analysis, err := stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis.Status).To(Equal("finished"))
+		})
+	})
+})
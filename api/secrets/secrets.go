@@ -0,0 +1,115 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+	"github.com/labstack/echo"
+)
+
+const logInfoAnalysis = "ANALYSIS"
+const logActionReceiveRequest = "ReceiveRequest"
+
+// ReferencePrefix marks a value of a securityTestEnv as the name of a secret of the secret store.
+const ReferencePrefix = "secret:"
+
+// ErrNoStore is returned when a securityTestEnv references a secret and no secret store is configured.
+var ErrNoStore = errors.New("no secret store configured")
+
+// ErrNotPermitted is returned when a securityTestEnv references a secret that is not scoped to its repository.
+var ErrNotPermitted = errors.New("secret not permitted for the repository")
+
+// MaskedValue replaces the plain values of a securityTestEnv in the analyses stored and returned.
+const MaskedValue = "[REDACTED]"
+
+var regexpName = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+
+// ValidName reports whether name is a valid name of a secret of the secret store.
+func ValidName(name string) bool {
+	return regexpName.MatchString(name)
+}
+
+// Permitted reports whether the secret name may be referenced by the securityTestEnv of the repository
+// repositoryURL, as one of the patterns of scopes for the secret, matched using path.Match, matches the URL
+// without its credentials. A secret with no scope can not be referenced by any repository.
+func Permitted(name, repositoryURL string, scopes map[string][]string) bool {
+	repositoryURL = util.RedactURLCredentials(repositoryURL)
+	for _, pattern := range scopes[name] {
+		if matched, err := path.Match(pattern, repositoryURL); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckScopes verifies, using Permitted, that each secret referenced by the securityTestEnv of
+// repository is scoped to it, so that a token of a repository can not inject the secrets of another one.
+func CheckScopes(repository types.Repository, scopes map[string][]string, c echo.Context) error {
+	for tool, env := range repository.SecurityTestEnv {
+		for _, value := range env {
+			if !strings.HasPrefix(value, ReferencePrefix) {
+				continue
+			}
+			name := strings.TrimPrefix(value, ReferencePrefix)
+			if !Permitted(name, repository.URL, scopes) {
+				log.Error(logActionReceiveRequest, logInfoAnalysis, 1079, tool, name)
+				reply := map[string]interface{}{"success": false, "error": "invalid securityTestEnv"}
+				return c.JSON(http.StatusBadRequest, reply)
+			}
+		}
+	}
+	return nil
+}
+
+// ScopedLookup returns lookup restricted to the secrets scopes permits for the repository repositoryURL.
+// Any other secret is returned as ErrNotPermitted, even for an analysis rerun or resumed from one that was
+// submitted before its scope changed.
+func ScopedLookup(lookup func(string) (string, error), scopes map[string][]string, repositoryURL string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if !Permitted(name, repositoryURL, scopes) {
+			return "", ErrNotPermitted
+		}
+		return lookup(name)
+	}
+}
+
+// DirLookup returns the lookup of the secrets of the secret store dir, holding each secret in a file
+// named after it, as the secrets mounted by Docker and Kubernetes. A trailing newline is not part of a secret.
+// The lookup returns ErrNoStore if dir is empty.
+func DirLookup(dir string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if dir == "" {
+			return "", ErrNoStore
+		}
+		if !ValidName(name) {
+			return "", fmt.Errorf("invalid secret name %q", name)
+		}
+		secret, err := ioutil.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return "", fmt.Errorf("secret %s not found in the secret store", name)
+		}
+		return strings.TrimRight(string(secret), "\r\n"), nil
+	}
+}
+
+// Redact returns text with each one of secrets replaced by MaskedValue.
+func Redact(text string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.Replace(text, secret, MaskedValue, -1)
+		}
+	}
+	return text
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/fingerprint"
+	"github.com/globocom/huskyCI/api/types"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// unexpiredAcceptedRisks returns, by fingerprint, the accepted risks of the repository of enryScan that did not
+// expire by now. If the database could not tell, none is returned, so that every vulnerability is enforced.
+func unexpiredAcceptedRisks(enryScan SecTestScanInfo, now time.Time) map[string]types.AcceptedRisk {
+	acceptedRisks, err := apiContext.APIConfiguration.DBInstance.FindAllDBAcceptedRisk(map[string]interface{}{"repositoryURL": enryScan.URL})
+	if err != nil {
+		if err != mgo.ErrNotFound && err.Error() != "No data found" {
+			enryScan.logger().Error("unexpiredAcceptedRisks", "SECURITYTEST", 2023, err)
+		}
+		return nil
+	}
+	unexpired := make(map[string]types.AcceptedRisk, len(acceptedRisks))
+	for _, acceptedRisk := range acceptedRisks {
+		if acceptedRisk.ExpiresAt == nil || now.Before(*acceptedRisk.ExpiresAt) {
+			unexpired[acceptedRisk.Fingerprint] = acceptedRisk
+		}
+	}
+	return unexpired
+}
+
+// applyAcceptedRisks moves the vulnerabilities of scanInfo whose risk was accepted to its AcceptedVulns, so that
+// they do not fail the analysis, and sets the result of its container again without them.
+func (scanInfo *SecTestScanInfo) applyAcceptedRisks(acceptedRisks map[string]types.AcceptedRisk) {
+	if len(acceptedRisks) == 0 {
+		return
+	}
+	moved := false
+	accept := func(vulns []types.HuskyCIVulnerability) []types.HuskyCIVulnerability {
+		var kept []types.HuskyCIVulnerability
+		for _, vuln := range vulns {
+			vulnFingerprint := fingerprint.Vulnerability(vuln, apiContext.APIConfiguration.FingerprintAlgorithm)
+			acceptedRisk, ok := acceptedRisks[vulnFingerprint]
+			if !ok {
+				kept = append(kept, vuln)
+				continue
+			}
+			vuln.Fingerprint = vulnFingerprint
+			vuln.AcceptedRisk = &acceptedRisk
+			scanInfo.Vulnerabilities.AcceptedVulns = append(scanInfo.Vulnerabilities.AcceptedVulns, vuln)
+			moved = true
+		}
+		return kept
+	}
+	scanInfo.Vulnerabilities.HighVulns = accept(scanInfo.Vulnerabilities.HighVulns)
+	scanInfo.Vulnerabilities.MediumVulns = accept(scanInfo.Vulnerabilities.MediumVulns)
+	scanInfo.Vulnerabilities.LowVulns = accept(scanInfo.Vulnerabilities.LowVulns)
+	if moved && scanInfo.resultFromVulnerabilities {
+		scanInfo.setVulnerabilitiesResult()
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"strconv"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 	"github.com/globocom/huskyCI/api/util"
 )
@@ -37,7 +36,7 @@ func analyzeBandit(banditScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a Bandit struct.
 	if err := json.Unmarshal([]byte(banditScan.Container.COutput), &banditOutput); err != nil {
-		log.Error("analyzeBandit", "BANDIT", 1006, banditScan.Container.COutput, err)
+		banditScan.logger().Error("analyzeBandit", "BANDIT", 1006, banditScan.Container.COutput, err)
 		banditScan.ErrorFound = err
 		return err
 	}
@@ -63,11 +62,13 @@ func (banditScan *SecTestScanInfo) prepareBanditVulns() {
 		banditVuln := types.HuskyCIVulnerability{}
 		banditVuln.Language = "Python"
 		banditVuln.SecurityTool = "Bandit"
+		issue.IssueSeverity = banditScan.overrideSeverity(issue.TestID, issue.IssueSeverity)
 		noHuskyInLine := util.VerifyNoHusky(issue.Code, issue.LineNumber, banditVuln.SecurityTool)
 		if noHuskyInLine {
 			issue.IssueSeverity = "NOSEC"
 		}
 		banditVuln.Severity = issue.IssueSeverity
+		banditVuln.Rule = issue.TestID
 		banditVuln.Confidence = issue.IssueConfidence
 		banditVuln.Title = issue.IssueText
 		banditVuln.Details = issue.IssueText
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
@@ -40,7 +39,7 @@ func analyzeBrakeman(brakemanScan *SecTestScanInfo) error {
 	}
 	// Unmarshall rawOutput into finalOutput, that is a Brakeman struct.
 	if err := json.Unmarshal([]byte(brakemanScan.Container.COutput), &brakemanOutput); err != nil {
-		log.Error("analyzeBrakeman", "BRAKEMAN", 1005, brakemanScan.Container.COutput, err)
+		brakemanScan.logger().Error("analyzeBrakeman", "BRAKEMAN", 1005, brakemanScan.Container.COutput, err)
 		brakemanScan.ErrorFound = err
 		return err
 	}
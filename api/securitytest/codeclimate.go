@@ -0,0 +1,101 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/fingerprint"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// CodeClimateIssue is the struct that holds a vulnerability in the Code Climate format, read by GitLab Code Quality reports.
+type CodeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Content     CodeClimateContent  `json:"content"`
+	Categories  []string            `json:"categories"`
+	Location    CodeClimateLocation `json:"location"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+}
+
+// CodeClimateContent is the struct that holds the details of a Code Climate issue.
+type CodeClimateContent struct {
+	Body string `json:"body"`
+}
+
+// CodeClimateLocation is the struct that holds the file and line of a Code Climate issue.
+type CodeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeClimateLines `json:"lines"`
+}
+
+// CodeClimateLines is the struct that holds the line range of a Code Climate issue.
+type CodeClimateLines struct {
+	Begin int `json:"begin"`
+}
+
+// codeClimateSeverities maps huskyCI severities into Code Climate ones.
+var codeClimateSeverities = map[string]string{
+	"high":   "critical",
+	"medium": "major",
+	"low":    "minor",
+}
+
+// ToCodeClimate returns the vulnerabilities of results, except the nosec ones, as a Code Climate JSON report,
+// the format of GitLab Code Quality reports. Vulnerabilities found without a file, such as missing lock files,
+// are reported in the repository root and vulnerabilities without a fingerprint get one using its line.
+func ToCodeClimate(results types.HuskyCIResults) ([]byte, error) {
+	issues := []CodeClimateIssue{}
+	for _, vuln := range util.ReportedVulnerabilities(results) {
+		issue := CodeClimateIssue{
+			Type:        "issue",
+			CheckName:   vuln.SecurityTool,
+			Description: vuln.Title,
+			Content:     CodeClimateContent{Body: vuln.Details},
+			Categories:  []string{"Security"},
+			Location: CodeClimateLocation{
+				Path:  repositoryFile(vuln.File),
+				Lines: CodeClimateLines{Begin: codeClimateLine(vuln.Line)},
+			},
+			Severity:    codeClimateSeverities[strings.ToLower(vuln.Severity)],
+			Fingerprint: vuln.Fingerprint,
+		}
+		if vuln.Type != "" {
+			issue.CheckName = vuln.SecurityTool + " " + vuln.Type
+		}
+		if issue.Description == "" {
+			issue.Description = vuln.Details
+		}
+		if issue.Location.Path == "" {
+			issue.Location.Path = "."
+		}
+		if issue.Severity == "" {
+			issue.Severity = "info"
+		}
+		if issue.Fingerprint == "" {
+			issue.Fingerprint = fingerprint.Vulnerability(vuln, fingerprint.Line)
+		}
+		issues = append(issues, issue)
+	}
+	return json.MarshalIndent(issues, "", "  ")
+}
+
+// codeClimateLine returns the first line of a vulnerability, such as 10 for the range 10-12, or 1 if it has none.
+func codeClimateLine(line string) int {
+	end := strings.IndexFunc(line, func(r rune) bool { return r < '0' || r > '9' })
+	if end != -1 {
+		line = line[:end]
+	}
+	if begin, err := strconv.Atoi(line); err == nil && begin > 0 {
+		return begin
+	}
+	return 1
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ToCodeClimate", func() {
+	Context("When the results have vulnerabilities of every severity", func() {
+		results := types.HuskyCIResults{}
+		results.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{
+			{
+				Language:     "Go",
+				SecurityTool: "GoSec",
+				Severity:     "HIGH",
+				Title:        "Potential hardcoded credentials",
+				Details:      "Potential hardcoded credentials @ [password := \"hunter2\"]",
+				File:         "/go/src/code/api/main.go",
+				Line:         "10",
+				Type:         "G101",
+				Fingerprint:  "0f8e2c5b7d4a1e3f6c9b8a7d5e4f3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d",
+			},
+		}
+		results.GoResults.HuskyCIGosecOutput.NoSecVulns = []types.HuskyCIVulnerability{
+			{SecurityTool: "GoSec", Severity: "LOW", Title: "Errors unhandled.", File: "/go/src/code/api/main.go", Line: "20", Type: "G104"},
+		}
+		results.PythonResults.HuskyCIBanditOutput.LowVulns = []types.HuskyCIVulnerability{
+			{
+				Language:     "Python",
+				SecurityTool: "Bandit",
+				Severity:     "low",
+				Details:      "Use of assert detected.",
+				File:         "./app/views.py",
+				Line:         "3-5",
+			},
+		}
+		results.JavaScriptResults.HuskyCINpmAuditOutput.MediumVulns = []types.HuskyCIVulnerability{
+			{
+				Language:     "JavaScript",
+				SecurityTool: "NpmAudit",
+				Severity:     "medium",
+				Title:        "Vulnerable Dependency: lodash 4.17.11 (Prototype Pollution)",
+				Details:      "Prototype Pollution",
+				Code:         "lodash",
+				CVE:          "CVE-2019-10744",
+			},
+		}
+
+		golden, err := ioutil.ReadFile("testdata/codeclimate_report.json")
+		It("Should read the golden file.", func() {
+			Expect(err).To(BeNil())
+		})
+
+		It("Should match the sample Code Quality report.", func() {
+			report, err := securitytest.ToCodeClimate(results)
+			Expect(err).To(BeNil())
+			Expect(report).To(MatchJSON(golden))
+		})
+	})
+
+	Context("When the results have no vulnerabilities", func() {
+		It("Should return an empty report.", func() {
+			report, err := securitytest.ToCodeClimate(types.HuskyCIResults{})
+			Expect(err).To(BeNil())
+			Expect(report).To(MatchJSON(`[]`))
+		})
+	})
+})
@@ -0,0 +1,61 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"regexp"
+)
+
+// CVSS is the struct that holds the CVSS score and vector of an advisory, as given by npm and yarn audit.
+type CVSS struct {
+	Score        float64 `json:"score"`
+	VectorString string  `json:"vectorString"`
+}
+
+var cveRegexp = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+
+// CVSSSeverity returns the huskyCI severity bucket of a CVSS score, following the CVSS v3 qualitative
+// rating: scores from 7.0 are high, from 4.0 medium and any other positive score low. It returns an empty
+// string when there is no score.
+func CVSSSeverity(score float64) string {
+	switch {
+	case score >= 7.0:
+		return "high"
+	case score >= 4.0:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return ""
+	}
+}
+
+// auditSeverity returns the huskyCI severity of a npm or yarn audit advisory. The CVSS score is only
+// used when the advisory has no severity category huskyCI knows.
+func auditSeverity(severity string, cvss CVSS) string {
+	switch severity {
+	case "info", "low":
+		return "low"
+	case "moderate":
+		return "medium"
+	case "high", "critical":
+		return "high"
+	default:
+		return CVSSSeverity(cvss.Score)
+	}
+}
+
+// findCVEs returns the CVE ids mentioned in text, in the order they first appear.
+func findCVEs(text string) []string {
+	cves := []string{}
+	found := make(map[string]bool)
+	for _, cve := range cveRegexp.FindAllString(text, -1) {
+		if !found[cve] {
+			found[cve] = true
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
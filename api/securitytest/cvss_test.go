@@ -0,0 +1,27 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CVSSSeverity", func() {
+	It("Should bucket CVSS scores into huskyCI severities.", func() {
+		Expect(securitytest.CVSSSeverity(9.8)).To(Equal("high"))
+		Expect(securitytest.CVSSSeverity(7.0)).To(Equal("high"))
+		Expect(securitytest.CVSSSeverity(6.9)).To(Equal("medium"))
+		Expect(securitytest.CVSSSeverity(4.0)).To(Equal("medium"))
+		Expect(securitytest.CVSSSeverity(3.9)).To(Equal("low"))
+		Expect(securitytest.CVSSSeverity(0.1)).To(Equal("low"))
+	})
+
+	It("Should return no severity without a score.", func() {
+		Expect(securitytest.CVSSSeverity(0)).To(BeEmpty())
+	})
+})
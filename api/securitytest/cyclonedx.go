@@ -0,0 +1,123 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// DependencyPackage is the struct that holds a dependency listed by a dependency securityTest in its output.
+type DependencyPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// CycloneDXBOM is the struct that holds the dependencies of an analysis as a CycloneDX JSON SBOM.
+type CycloneDXBOM struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    CycloneDXMetadata    `json:"metadata"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata is the struct that holds the tool that generated a CycloneDX SBOM and the repository it describes.
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp,omitempty"`
+	Tools     []CycloneDXTool    `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+// CycloneDXTool is the struct that holds a tool that generated a CycloneDX SBOM.
+type CycloneDXTool struct {
+	Vendor string `json:"vendor"`
+	Name   string `json:"name"`
+}
+
+// CycloneDXComponent is the struct that holds a component of a CycloneDX SBOM.
+type CycloneDXComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+// components returns the packages of a dependency securityTest as components of ecosystem, skipping the
+// ones without a name or a version.
+func components(ecosystem string, packages []DependencyPackage) []types.Component {
+	found := []types.Component{}
+	for _, pkg := range packages {
+		name, version := strings.TrimSpace(pkg.Name), strings.TrimSpace(pkg.Version)
+		if name == "" || version == "" {
+			continue
+		}
+		found = append(found, types.Component{Ecosystem: ecosystem, Name: name, Version: version})
+	}
+	return found
+}
+
+// ToCycloneDX returns the dependencies found by the dependency securityTests of analysis, vulnerable or not,
+// as a CycloneDX 1.4 JSON SBOM describing its repository. Each dependency is listed once, sorted by its purl.
+// The vulnerabilities of the analysis are not part of it.
+func ToCycloneDX(analysis types.Analysis) ([]byte, error) {
+	bom := CycloneDXBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Tools:     []CycloneDXTool{{Vendor: "Globo.com", Name: "huskyCI"}},
+			Component: CycloneDXComponent{Type: "application", BOMRef: analysis.URL, Name: analysis.URL, Version: analysis.Commit},
+		},
+		Components: []CycloneDXComponent{},
+	}
+	if !analysis.FinishedAt.IsZero() {
+		bom.Metadata.Timestamp = analysis.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	listed := make(map[string]bool)
+	for _, component := range analysis.Components {
+		cycloneDXComponent := toCycloneDXComponent(component)
+		if listed[cycloneDXComponent.PURL] {
+			continue
+		}
+		listed[cycloneDXComponent.PURL] = true
+		bom.Components = append(bom.Components, cycloneDXComponent)
+	}
+	sort.Slice(bom.Components, func(i, j int) bool {
+		return bom.Components[i].PURL < bom.Components[j].PURL
+	})
+	return json.Marshal(bom)
+}
+
+// toCycloneDXComponent returns component as a library of a CycloneDX SBOM, identified by its purl. The scope
+// of a npm package is its group and Python package names are normalized in the purl, as PyPI compares them.
+func toCycloneDXComponent(component types.Component) CycloneDXComponent {
+	cycloneDXComponent := CycloneDXComponent{Type: "library", Name: component.Name, Version: component.Version}
+	purlName := purlEscape(component.Name)
+	switch component.Ecosystem {
+	case "npm":
+		if i := strings.Index(component.Name, "/"); strings.HasPrefix(component.Name, "@") && i > 0 {
+			cycloneDXComponent.Group, cycloneDXComponent.Name = component.Name[:i], component.Name[i+1:]
+			purlName = purlEscape(cycloneDXComponent.Group) + "/" + purlEscape(cycloneDXComponent.Name)
+		}
+	case "pypi":
+		purlName = purlEscape(strings.ReplaceAll(strings.ToLower(component.Name), "_", "-"))
+	}
+	cycloneDXComponent.PURL = "pkg:" + component.Ecosystem + "/" + purlName + "@" + purlEscape(component.Version)
+	cycloneDXComponent.BOMRef = cycloneDXComponent.PURL
+	return cycloneDXComponent
+}
+
+// purlEscape percent-encodes a segment of a purl, @ included as it separates the version.
+func purlEscape(segment string) string {
+	return strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ToCycloneDX", func() {
+
+	toBOM := func(analysis types.Analysis) securitytest.CycloneDXBOM {
+		raw, err := securitytest.ToCycloneDX(analysis)
+		Expect(err).NotTo(HaveOccurred())
+		bom := securitytest.CycloneDXBOM{}
+		Expect(json.Unmarshal(raw, &bom)).To(Succeed())
+		return bom
+	}
+
+	Context("When the analysis found Python and npm dependencies", func() {
+		analysis := types.Analysis{
+			URL:        "https://github.com/globocom/huskyCI.git",
+			Commit:     "3f2a1b",
+			FinishedAt: time.Date(2020, 5, 4, 12, 30, 0, 0, time.UTC),
+			Components: []types.Component{
+				{Ecosystem: "pypi", Name: "Flask_Cors", Version: "3.0.8"},
+				{Ecosystem: "npm", Name: "@babel/core", Version: "7.9.0"},
+				{Ecosystem: "npm", Name: "lodash", Version: "4.17.11"},
+				{Ecosystem: "npm", Name: "lodash", Version: "4.17.11"},
+			},
+		}
+
+		It("Should describe the repository of the analysis.", func() {
+			bom := toBOM(analysis)
+			Expect(bom.BOMFormat).To(Equal("CycloneDX"))
+			Expect(bom.SpecVersion).To(Equal("1.4"))
+			Expect(bom.Metadata.Timestamp).To(Equal("2020-05-04T12:30:00Z"))
+			Expect(bom.Metadata.Component).To(Equal(securitytest.CycloneDXComponent{
+				Type:    "application",
+				BOMRef:  "https://github.com/globocom/huskyCI.git",
+				Name:    "https://github.com/globocom/huskyCI.git",
+				Version: "3f2a1b",
+			}))
+		})
+
+		It("Should list each dependency once, sorted by its purl.", func() {
+			Expect(toBOM(analysis).Components).To(Equal([]securitytest.CycloneDXComponent{
+				{Type: "library", BOMRef: "pkg:npm/%40babel/core@7.9.0", Group: "@babel", Name: "core", Version: "7.9.0", PURL: "pkg:npm/%40babel/core@7.9.0"},
+				{Type: "library", BOMRef: "pkg:npm/lodash@4.17.11", Name: "lodash", Version: "4.17.11", PURL: "pkg:npm/lodash@4.17.11"},
+				{Type: "library", BOMRef: "pkg:pypi/flask-cors@3.0.8", Name: "Flask_Cors", Version: "3.0.8", PURL: "pkg:pypi/flask-cors@3.0.8"},
+			}))
+		})
+	})
+
+	Context("When the analysis found no dependency", func() {
+		It("Should return an empty list of components without a timestamp.", func() {
+			raw, err := securitytest.ToCycloneDX(types.Analysis{URL: "https://github.com/globocom/huskyCI.git"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(ContainSubstring(`"components":[]`))
+			Expect(string(raw)).NotTo(ContainSubstring("timestamp"))
+		})
+	})
+})
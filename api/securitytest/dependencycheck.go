@@ -0,0 +1,163 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// dependencyCheckLanguages holds the language of the dependencies each Dependency-Check securityTest scans.
+var dependencyCheckLanguages = map[string]string{
+	dependencycheck:       "Swift",
+	dependencycheckgradle: "Kotlin",
+}
+
+// DependencyCheckOutput is the struct that holds all data from OWASP Dependency-Check JSON report.
+type DependencyCheckOutput struct {
+	Dependencies []DependencyCheckDependency `json:"dependencies"`
+}
+
+// DependencyCheckDependency is the struct that holds a dependency found by Dependency-Check and its vulnerabilities.
+type DependencyCheckDependency struct {
+	FileName          string                         `json:"fileName"`
+	FilePath          string                         `json:"filePath"`
+	ProjectReferences []string                       `json:"projectReferences"`
+	Packages          []DependencyCheckPackage       `json:"packages"`
+	Vulnerabilities   []DependencyCheckVulnerability `json:"vulnerabilities"`
+}
+
+// DependencyCheckPackage is the struct that holds the package URL of a dependency, such as pkg:cocoapods/Alamofire@4.7.0
+// or pkg:maven/com.squareup.okhttp3/okhttp@3.12.0.
+type DependencyCheckPackage struct {
+	ID string `json:"id"`
+}
+
+// DependencyCheckVulnerability is the struct that holds detailed information of an advisory from Dependency-Check output.
+type DependencyCheckVulnerability struct {
+	Source      string `json:"source"`
+	Name        string `json:"name"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+func analyzeDependencyCheck(dependencyCheckScan *SecTestScanInfo) error {
+
+	dependencyCheckOutput := DependencyCheckOutput{}
+	dependencyCheckScan.FinalOutput = dependencyCheckOutput
+
+	// check if there were any internal errors running Dependency-Check
+	if strings.Contains(dependencyCheckScan.Container.COutput, "ERROR_RUNNING_DEPENDENCYCHECK") {
+		errorMsg := errors.New("internal error dependencycheck - ERROR_RUNNING_DEPENDENCYCHECK")
+		dependencyCheckScan.logger().Error("analyzeDependencyCheck", "DEPENDENCYCHECK", 1050, errorMsg)
+		dependencyCheckScan.ErrorFound = errorMsg
+		dependencyCheckScan.prepareContainerAfterScan()
+		return errorMsg
+	}
+
+	// nil cOutput states that no Podfile.lock, Package.resolved nor Gradle build was found.
+	if strings.TrimSpace(dependencyCheckScan.Container.COutput) == "" {
+		dependencyCheckScan.prepareContainerAfterScan()
+		return nil
+	}
+
+	// Unmarshall rawOutput into finalOutput, that is a DependencyCheckOutput struct.
+	if err := json.Unmarshal([]byte(dependencyCheckScan.Container.COutput), &dependencyCheckOutput); err != nil {
+		dependencyCheckScan.logger().Error("analyzeDependencyCheck", "DEPENDENCYCHECK", 1049, dependencyCheckScan.Container.COutput, err)
+		dependencyCheckScan.ErrorFound = err
+		dependencyCheckScan.prepareContainerAfterScan()
+		return err
+	}
+	dependencyCheckScan.FinalOutput = dependencyCheckOutput
+
+	// check results and prepare all vulnerabilities found
+	dependencyCheckScan.prepareDependencyCheckVulns()
+	dependencyCheckScan.prepareContainerAfterScan()
+	return nil
+}
+
+func (dependencyCheckScan *SecTestScanInfo) prepareDependencyCheckVulns() {
+
+	huskyCIdependencyCheckResults := types.HuskyCISecurityTestOutput{}
+	dependencyCheckOutput := dependencyCheckScan.FinalOutput.(DependencyCheckOutput)
+
+	for _, dependency := range dependencyCheckOutput.Dependencies {
+		packageName, packageVersion := dependency.packageNameAndVersion()
+		for _, vulnerability := range dependency.Vulnerabilities {
+			dependencyCheckVuln := types.HuskyCIVulnerability{}
+			dependencyCheckVuln.Language = dependencyCheckLanguages[dependencyCheckScan.tool()]
+			dependencyCheckVuln.SecurityTool = "DependencyCheck"
+			dependencyCheckVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", packageName, packageVersion, vulnerability.Name)
+			dependencyCheckVuln.Details = vulnerability.Description
+			dependencyCheckVuln.Code = packageName
+			dependencyCheckVuln.Version = packageVersion
+			dependencyCheckVuln.CVE = vulnerability.Name
+			dependencyCheckVuln.File = dependency.file()
+
+			switch strings.ToLower(vulnerability.Severity) {
+			case "critical", "high":
+				dependencyCheckVuln.Severity = "high"
+				huskyCIdependencyCheckResults.HighVulns = append(huskyCIdependencyCheckResults.HighVulns, dependencyCheckVuln)
+			case "medium", "moderate":
+				dependencyCheckVuln.Severity = "medium"
+				huskyCIdependencyCheckResults.MediumVulns = append(huskyCIdependencyCheckResults.MediumVulns, dependencyCheckVuln)
+			default:
+				dependencyCheckVuln.Severity = "low"
+				huskyCIdependencyCheckResults.LowVulns = append(huskyCIdependencyCheckResults.LowVulns, dependencyCheckVuln)
+			}
+		}
+	}
+
+	dependencyCheckScan.Vulnerabilities = huskyCIdependencyCheckResults
+}
+
+// packageNameAndVersion returns the name and the version of the dependency from its package URL,
+// falling back to the name Dependency-Check gives to the dependency if it has no package URL.
+func (dependency DependencyCheckDependency) packageNameAndVersion() (string, string) {
+	for _, dependencyPackage := range dependency.Packages {
+		purl := strings.TrimPrefix(dependencyPackage.ID, "pkg:")
+		slash := strings.Index(purl, "/")
+		at := strings.LastIndex(purl, "@")
+		if slash == -1 || at < slash {
+			continue
+		}
+		name, err := url.PathUnescape(purl[slash+1 : at])
+		if err != nil {
+			continue
+		}
+		return name, purl[at+1:]
+	}
+	fileName := dependency.FileName
+	if separator := strings.LastIndex(fileName, ":"); separator != -1 {
+		fileName = fileName[separator+1:]
+	}
+	return strings.TrimSpace(fileName), ""
+}
+
+// file returns where a dependency is declared: the path, relative to the repository, of its lock file or,
+// for the dependencies resolved by Gradle out of the repository, the configurations of the projects using it.
+func (dependency DependencyCheckDependency) file() string {
+	if !strings.Contains(dependency.FilePath, "/code/") && len(dependency.ProjectReferences) > 0 {
+		return strings.Join(dependency.ProjectReferences, ", ")
+	}
+	return repositoryFile(dependency.FilePath)
+}
+
+// repositoryFile returns the path, relative to the repository, of a file reported by a securityTest,
+// such as the lock file declaring a dependency.
+func repositoryFile(filePath string) string {
+	if question := strings.Index(filePath, "?"); question != -1 {
+		filePath = filePath[:question]
+	}
+	if code := strings.Index(filePath, "/code/"); code != -1 {
+		filePath = filePath[code+len("/code/"):]
+	}
+	return strings.TrimPrefix(filePath, "./")
+}
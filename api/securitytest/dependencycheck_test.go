@@ -0,0 +1,96 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DependencyCheck", func() {
+	Describe("Parse", func() {
+		Context("When the output has vulnerable CocoaPods and Swift Package Manager dependencies", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/dependencycheck_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map Dependency-Check severities into huskyCI severities.", func() {
+				output, err := securitytest.Parse("dependencycheck", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(2))
+				Expect(output.HighVulns[0].CVE).To(Equal("CVE-2019-1000001"))
+				Expect(output.HighVulns[1].CVE).To(Equal("CVE-2020-9840"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].CVE).To(Equal("CVE-2019-1000002"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].CVE).To(Equal("sonatype-2020-0001"))
+			})
+
+			It("Should extract the package, version and advisory of each vulnerability.", func() {
+				output, err := securitytest.Parse("dependencycheck", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns[0].Code).To(Equal("Alamofire"))
+				Expect(output.HighVulns[0].Version).To(Equal("4.7.0"))
+				Expect(output.HighVulns[0].File).To(Equal("ios/Podfile.lock"))
+				Expect(output.HighVulns[0].Title).To(Equal("Vulnerable Dependency: Alamofire 4.7.0 (CVE-2019-1000001)"))
+				Expect(output.HighVulns[0].Language).To(Equal("Swift"))
+				Expect(output.HighVulns[0].SecurityTool).To(Equal("DependencyCheck"))
+				Expect(output.HighVulns[1].Code).To(Equal("github.com/apple/swift-nio"))
+				Expect(output.HighVulns[1].Version).To(Equal("2.18.0"))
+				Expect(output.HighVulns[1].File).To(Equal("Package.resolved"))
+			})
+		})
+
+		Context("When the output has vulnerable Gradle dependencies", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/dependencycheckgradle_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should report them as Kotlin dependencies used by the Gradle projects.", func() {
+				output, err := securitytest.Parse("dependencycheckgradle", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Code).To(Equal("com.squareup.okhttp3/okhttp"))
+				Expect(output.HighVulns[0].Version).To(Equal("3.12.0"))
+				Expect(output.HighVulns[0].CVE).To(Equal("CVE-2021-0341"))
+				Expect(output.HighVulns[0].File).To(Equal("app:releaseRuntimeClasspath"))
+				Expect(output.HighVulns[0].Language).To(Equal("Kotlin"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].File).To(Equal("app:debugRuntimeClasspath, app:releaseRuntimeClasspath"))
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When the repository has no Podfile.lock nor Package.resolved", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("dependencycheck", "")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When Dependency-Check fails to run", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("dependencycheck", "ERROR_RUNNING_DEPENDENCYCHECK\nUnable to download the NVD data feeds")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("When the output is malformed", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("dependencycheck", `{"dependencies": `)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,119 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// DetektOutput is the struct that holds all data from detekt SARIF report.
+type DetektOutput struct {
+	Runs []DetektRun `json:"runs"`
+}
+
+// DetektRun is the struct that holds the issues detekt found in a run.
+type DetektRun struct {
+	Results []DetektResult `json:"results"`
+}
+
+// DetektResult is the struct that holds detailed information of an issue from detekt output.
+type DetektResult struct {
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []DetektLocation `json:"locations"`
+}
+
+// DetektLocation is the struct that holds the file and line of an issue from detekt output.
+type DetektLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+func analyzeDetekt(detektScan *SecTestScanInfo) error {
+
+	detektOutput := DetektOutput{}
+	detektScan.FinalOutput = detektOutput
+
+	// check if there were any internal errors running detekt
+	if strings.Contains(detektScan.Container.COutput, "ERROR_RUNNING_DETEKT") {
+		errorMsg := errors.New("internal error detekt - ERROR_RUNNING_DETEKT")
+		detektScan.logger().Error("analyzeDetekt", "DETEKT", 1056, errorMsg)
+		detektScan.ErrorFound = errorMsg
+		detektScan.prepareContainerAfterScan()
+		return errorMsg
+	}
+
+	// nil cOutput states that no Kotlin file was found.
+	if strings.TrimSpace(detektScan.Container.COutput) == "" {
+		detektScan.prepareContainerAfterScan()
+		return nil
+	}
+
+	// Unmarshall rawOutput into finalOutput, that is a DetektOutput struct.
+	if err := json.Unmarshal([]byte(detektScan.Container.COutput), &detektOutput); err != nil {
+		detektScan.logger().Error("analyzeDetekt", "DETEKT", 1055, detektScan.Container.COutput, err)
+		detektScan.ErrorFound = err
+		detektScan.prepareContainerAfterScan()
+		return err
+	}
+	detektScan.FinalOutput = detektOutput
+
+	// check results and prepare all vulnerabilities found
+	detektScan.prepareDetektVulns()
+	detektScan.prepareContainerAfterScan()
+	return nil
+}
+
+func (detektScan *SecTestScanInfo) prepareDetektVulns() {
+
+	huskyCIdetektResults := types.HuskyCISecurityTestOutput{}
+	detektOutput := detektScan.FinalOutput.(DetektOutput)
+
+	for _, run := range detektOutput.Runs {
+		for _, result := range run.Results {
+			// ruleId is detekt.<ruleset>.<rule>, such as detekt.style.ForbiddenMethodCall.
+			rule := result.RuleID[strings.LastIndex(result.RuleID, ".")+1:]
+			detektVuln := types.HuskyCIVulnerability{}
+			detektVuln.Language = "Kotlin"
+			detektVuln.SecurityTool = "Detekt"
+			detektVuln.Title = rule
+			detektVuln.Details = rule + " @ [" + result.Message.Text + "]"
+			detektVuln.Type = strings.TrimPrefix(result.RuleID, "detekt.")
+			if len(result.Locations) > 0 {
+				location := result.Locations[0].PhysicalLocation
+				detektVuln.File = repositoryFile(strings.TrimPrefix(location.ArtifactLocation.URI, "file://"))
+				detektVuln.Line = strconv.Itoa(location.Region.StartLine)
+			}
+
+			switch strings.ToLower(result.Level) {
+			case "error":
+				detektVuln.Severity = "high"
+				huskyCIdetektResults.HighVulns = append(huskyCIdetektResults.HighVulns, detektVuln)
+			case "warning":
+				detektVuln.Severity = "medium"
+				huskyCIdetektResults.MediumVulns = append(huskyCIdetektResults.MediumVulns, detektVuln)
+			default:
+				detektVuln.Severity = "low"
+				huskyCIdetektResults.LowVulns = append(huskyCIdetektResults.LowVulns, detektVuln)
+			}
+		}
+	}
+
+	detektScan.Vulnerabilities = huskyCIdetektResults
+}
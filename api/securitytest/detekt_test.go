@@ -0,0 +1,72 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detekt", func() {
+	Describe("Parse", func() {
+		Context("When the output has issues of each level", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/detekt_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map detekt levels into huskyCI severities.", func() {
+				output, err := securitytest.Parse("detekt", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Title).To(Equal("ForbiddenMethodCall"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Title).To(Equal("ForbiddenImport"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Title).To(Equal("SwallowedException"))
+			})
+
+			It("Should extract the rule, file and line of each issue.", func() {
+				output, err := securitytest.Parse("detekt", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns[0].Language).To(Equal("Kotlin"))
+				Expect(output.HighVulns[0].SecurityTool).To(Equal("Detekt"))
+				Expect(output.HighVulns[0].Type).To(Equal("style.ForbiddenMethodCall"))
+				Expect(output.HighVulns[0].Details).To(ContainSubstring("java.lang.Runtime.exec"))
+				Expect(output.HighVulns[0].File).To(Equal("app/src/main/kotlin/com/example/Shell.kt"))
+				Expect(output.HighVulns[0].Line).To(Equal("14"))
+				Expect(output.MediumVulns[0].File).To(Equal("app/src/main/kotlin/com/example/Token.kt"))
+			})
+		})
+
+		Context("When the repository has no Kotlin file", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("detekt", "")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When detekt fails to run", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("detekt", "ERROR_RUNNING_DETEKT\nInvalid config")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("When the output is malformed", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("detekt", `{"runs": `)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
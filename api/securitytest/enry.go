@@ -7,9 +7,11 @@ package securitytest
 import (
 	"encoding/json"
 	"errors"
+	"path"
 	"reflect"
+	"strings"
 
-	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/changedfiles"
 	"github.com/globocom/huskyCI/api/types"
 )
 
@@ -18,27 +20,93 @@ type EnryOutput struct {
 	Codes []types.Code
 }
 
+// fileTriggeredLanguages holds the languages whose securityTests run whenever one of their files is
+// found in the repository, regardless of the language enry classified the file as.
+var fileTriggeredLanguages = []struct {
+	language string
+	match    func(file string) bool
+}{
+	{"HCL", isTerraformFile},
+	{"Dockerfile", isDockerfile},
+	{"Swift", isSwiftDependencyFile},
+	{"Kotlin", isGradleBuildFile},
+	{"Dart", isPubspecLockFile},
+	{"Go", isGoModFile},
+}
+
+func isTerraformFile(file string) bool {
+	return path.Ext(file) == ".tf"
+}
+
+func isDockerfile(file string) bool {
+	base := path.Base(file)
+	return base == "Dockerfile" || strings.HasPrefix(base, "Dockerfile.") || strings.HasSuffix(base, ".dockerfile")
+}
+
+func isSwiftDependencyFile(file string) bool {
+	switch path.Base(file) {
+	case "Podfile", "Podfile.lock", "Package.swift", "Package.resolved":
+		return true
+	}
+	return false
+}
+
+func isGradleBuildFile(file string) bool {
+	switch path.Base(file) {
+	case "build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts":
+		return true
+	}
+	return false
+}
+
+// isJavaBuildFile returns whether file is a Maven or Gradle build file at the root of the repository, where
+// spotbugs builds the project from.
+func isJavaBuildFile(file string) bool {
+	switch file {
+	case "pom.xml", "build.gradle", "build.gradle.kts":
+		return true
+	}
+	return false
+}
+
+func isPubspecLockFile(file string) bool {
+	return path.Base(file) == "pubspec.lock"
+}
+
+func isGoModFile(file string) bool {
+	return path.Base(file) == "go.mod"
+}
+
 func analyzeEnry(enryScan *SecTestScanInfo) error {
+	// an empty repository has no branch to clone, and so no language to detect.
+	if strings.Contains(enryScan.Container.COutput, "EMPTY_REPOSITORY") {
+		enryScan.Codes = []types.Code{}
+		return nil
+	}
+	// the files changed since the base ref, if any, are printed before the enry output.
+	enryOutput, changedFiles, changedFilesOnly := changedfiles.Split(enryScan.Container.COutput)
+	enryScan.ChangedFiles = changedFiles
+	enryScan.ChangedFilesOnly = changedFilesOnly
 	// Unmarshall rawOutput into finalOutput, that is a EnryOutput struct.
-	if err := json.Unmarshal([]byte(enryScan.Container.COutput), &enryScan.FinalOutput); err != nil {
-		log.Error("analyzeEnry", "ENRY", 1003, enryScan.Container.COutput, err)
+	if err := json.Unmarshal([]byte(enryOutput), &enryScan.FinalOutput); err != nil {
+		enryScan.logger().Error("analyzeEnry", "ENRY", 1003, enryScan.Container.COutput, err)
 		enryScan.ErrorFound = err
 		return err
 	}
 	// get all languages and files found based on Enry output
-	if err := enryScan.prepareEnryOutput(); err != nil {
+	if err := enryScan.prepareEnryOutput(enryOutput); err != nil {
 		enryScan.ErrorFound = err
 		return err
 	}
 	return nil
 }
 
-func (enryScan *SecTestScanInfo) prepareEnryOutput() error {
+func (enryScan *SecTestScanInfo) prepareEnryOutput(enryOutput string) error {
 	repositoryLanguages := []types.Code{}
 	mapLanguages := make(map[string][]interface{})
-	err := json.Unmarshal([]byte(enryScan.Container.COutput), &mapLanguages)
+	err := json.Unmarshal([]byte(enryOutput), &mapLanguages)
 	if err != nil {
-		log.Error("prepareEnryOutput", "ENRY", 1003, enryScan.Container.COutput, err)
+		enryScan.logger().Error("prepareEnryOutput", "ENRY", 1003, enryOutput, err)
 		return err
 	}
 	for name, files := range mapLanguages {
@@ -48,7 +116,7 @@ func (enryScan *SecTestScanInfo) prepareEnryOutput() error {
 				fs = append(fs, f.(string))
 			} else {
 				errMsg := errors.New("error mapping languages")
-				log.Error("prepareEnryOutput", "ENRY", 1032, errMsg)
+				enryScan.logger().Error("prepareEnryOutput", "ENRY", 1032, errMsg)
 				return errMsg
 			}
 		}
@@ -58,6 +126,41 @@ func (enryScan *SecTestScanInfo) prepareEnryOutput() error {
 		}
 		repositoryLanguages = append(repositoryLanguages, newLanguage)
 	}
-	enryScan.Codes = repositoryLanguages
+	enryScan.Codes = AddFileTriggeredCodes(repositoryLanguages)
 	return nil
 }
+
+// AddFileTriggeredCodes adds to codes the files of each fileTriggeredLanguages found under other
+// languages, so that their securityTests run even when they are not detected as a language.
+func AddFileTriggeredCodes(codes []types.Code) []types.Code {
+	for _, triggered := range fileTriggeredLanguages {
+		languageIndex := -1
+		knownFiles := make(map[string]bool)
+		for i, code := range codes {
+			if code.Language == triggered.language {
+				languageIndex = i
+				for _, file := range code.Files {
+					knownFiles[file] = true
+				}
+			}
+		}
+		foundFiles := []string{}
+		for _, code := range codes {
+			for _, file := range code.Files {
+				if triggered.match(file) && !knownFiles[file] {
+					knownFiles[file] = true
+					foundFiles = append(foundFiles, file)
+				}
+			}
+		}
+		if len(foundFiles) == 0 {
+			continue
+		}
+		if languageIndex == -1 {
+			codes = append(codes, types.Code{Language: triggered.language, Files: foundFiles})
+			continue
+		}
+		codes[languageIndex].Files = append(codes[languageIndex].Files, foundFiles...)
+	}
+	return codes
+}
@@ -0,0 +1,144 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Enry", func() {
+	Describe("AddFileTriggeredCodes", func() {
+		Context("When Terraform files and Dockerfiles are not detected as languages", func() {
+			It("Should add them so that their securityTests run.", func() {
+				codes := []types.Code{
+					{Language: "Go", Files: []string{"main.go", "Dockerfile"}},
+					{Language: "Text", Files: []string{"infra/main.tf", "build/api.dockerfile"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "Go", Files: []string{"main.go", "Dockerfile"}},
+					{Language: "Text", Files: []string{"infra/main.tf", "build/api.dockerfile"}},
+					{Language: "HCL", Files: []string{"infra/main.tf"}},
+					{Language: "Dockerfile", Files: []string{"Dockerfile", "build/api.dockerfile"}},
+				}))
+			})
+		})
+		Context("When the language is already detected", func() {
+			It("Should add only files it does not have yet.", func() {
+				codes := []types.Code{
+					{Language: "HCL", Files: []string{"main.tf"}},
+					{Language: "Go", Files: []string{"main.go", "Dockerfile.dev"}},
+					{Language: "Dockerfile", Files: []string{"Dockerfile"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "HCL", Files: []string{"main.tf"}},
+					{Language: "Go", Files: []string{"main.go", "Dockerfile.dev"}},
+					{Language: "Dockerfile", Files: []string{"Dockerfile", "Dockerfile.dev"}},
+				}))
+			})
+		})
+		Context("When CocoaPods or Swift Package Manager files are found", func() {
+			It("Should add them as Swift so that its securityTests run.", func() {
+				codes := []types.Code{
+					{Language: "Ruby", Files: []string{"ios/Podfile", "ios/Podfile.lock"}},
+					{Language: "JSON", Files: []string{"Package.resolved", "package.json"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "Ruby", Files: []string{"ios/Podfile", "ios/Podfile.lock"}},
+					{Language: "JSON", Files: []string{"Package.resolved", "package.json"}},
+					{Language: "Swift", Files: []string{"ios/Podfile", "ios/Podfile.lock", "Package.resolved"}},
+				}))
+			})
+		})
+		Context("When a go.mod is found", func() {
+			It("Should add it as Go so that its securityTests run.", func() {
+				codes := []types.Code{
+					{Language: "Go", Files: []string{"main.go"}},
+					{Language: "Text", Files: []string{"go.mod", "tools/go.mod"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "Go", Files: []string{"main.go", "go.mod", "tools/go.mod"}},
+					{Language: "Text", Files: []string{"go.mod", "tools/go.mod"}},
+				}))
+			})
+		})
+		Context("When a pubspec.lock is found", func() {
+			It("Should add it as Dart so that its securityTests run.", func() {
+				codes := []types.Code{
+					{Language: "Dart", Files: []string{"lib/main.dart"}},
+					{Language: "YAML", Files: []string{"pubspec.yaml", "pubspec.lock"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "Dart", Files: []string{"lib/main.dart", "pubspec.lock"}},
+					{Language: "YAML", Files: []string{"pubspec.yaml", "pubspec.lock"}},
+				}))
+			})
+			It("Should add a Dart code to projects whose Dart files were not detected.", func() {
+				codes := []types.Code{
+					{Language: "YAML", Files: []string{"app/pubspec.lock"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "YAML", Files: []string{"app/pubspec.lock"}},
+					{Language: "Dart", Files: []string{"app/pubspec.lock"}},
+				}))
+			})
+		})
+		Context("When Gradle build files are found", func() {
+			It("Should add them as Kotlin so that its securityTests run.", func() {
+				codes := []types.Code{
+					{Language: "Kotlin", Files: []string{"app/src/main/kotlin/MainActivity.kt", "app/build.gradle.kts"}},
+					{Language: "Gradle", Files: []string{"build.gradle", "settings.gradle"}},
+					{Language: "Java", Files: []string{"app/src/main/java/Legacy.java"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "Kotlin", Files: []string{"app/src/main/kotlin/MainActivity.kt", "app/build.gradle.kts", "build.gradle", "settings.gradle"}},
+					{Language: "Gradle", Files: []string{"build.gradle", "settings.gradle"}},
+					{Language: "Java", Files: []string{"app/src/main/java/Legacy.java"}},
+				}))
+			})
+			It("Should add a Kotlin code to Java only Android projects.", func() {
+				codes := []types.Code{
+					{Language: "Gradle", Files: []string{"app/build.gradle"}},
+					{Language: "Java", Files: []string{"app/src/main/java/MainActivity.java"}},
+				}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal([]types.Code{
+					{Language: "Gradle", Files: []string{"app/build.gradle"}},
+					{Language: "Java", Files: []string{"app/src/main/java/MainActivity.java"}},
+					{Language: "Kotlin", Files: []string{"app/build.gradle"}},
+				}))
+			})
+		})
+		Context("When no Terraform file nor Dockerfile is found", func() {
+			It("Should keep the codes unchanged.", func() {
+				codes := []types.Code{{Language: "Python", Files: []string{"main.py"}}}
+				Expect(securitytest.AddFileTriggeredCodes(codes)).To(Equal(codes))
+			})
+		})
+	})
+
+	Describe("Parse", func() {
+		Context("When the output has the files changed since the base ref", func() {
+			It("Should parse the enry output after them.", func() {
+				_, err := securitytest.Parse("enry", "HUSKYCI_CHANGED_FILES\nmain.go\nHUSKYCI_CHANGED_FILES_END\n"+`{"Go":["main.go","server.go"]}`)
+				Expect(err).To(BeNil())
+			})
+		})
+		Context("When the repository is empty", func() {
+			It("Should not return an error.", func() {
+				_, err := securitytest.Parse("enry", "EMPTY_REPOSITORY\n")
+				Expect(err).To(BeNil())
+			})
+		})
+		Context("When the base ref is not found", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("enry", "ERROR_BASE_REF_NOT_FOUND\n"+`{"Go":["main.go"]}`)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,80 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// CancelledStatus is the status and result of the container of a securityTest cancelled by fail fast.
+const CancelledStatus = "cancelled"
+
+// FailFastNote returns the informational note of a securityTest cancelled by fail fast.
+func FailFastNote(severity string) string {
+	return fmt.Sprintf("Cancelled by fail fast after a finding of %s severity or higher.", strings.ToLower(severity))
+}
+
+// ReachesSeverity returns whether vulns holds a vulnerability of the given severity or higher.
+func ReachesSeverity(vulns types.HuskyCISecurityTestOutput, severity string) bool {
+	found := false
+	switch strings.ToLower(severity) {
+	case "low":
+		found = found || len(vulns.LowVulns) > 0
+		fallthrough
+	case "medium":
+		found = found || len(vulns.MediumVulns) > 0
+		fallthrough
+	case "high":
+		found = found || len(vulns.HighVulns) > 0
+	}
+	return found
+}
+
+// checkFailFast aborts the analysis, cancelling the securityTests still running, when the
+// vulnerabilities found by scan reach the fail fast severity, unless scan is within its grace days.
+func (results *RunAllInfo) checkFailFast(scan SecTestScanInfo) {
+	if results.failFast == nil || scan.Container.GracePeriod || !ReachesSeverity(scan.Vulnerabilities, results.FailFastSeverity) {
+		return
+	}
+	results.failFastOnce.Do(func() {
+		results.mutex.Lock()
+		results.FailFastAborted = true
+		results.mutex.Unlock()
+		close(results.failFast)
+	})
+}
+
+// failFastCancelled returns whether the analysis was aborted by fail fast.
+func (results *RunAllInfo) failFastCancelled() bool {
+	if results.failFast == nil {
+		return false
+	}
+	select {
+	case <-results.failFast:
+		return true
+	default:
+		return false
+	}
+}
+
+// failFastContainer returns container, or a new one when it was not created yet, recording that
+// securityTest was cancelled by fail fast.
+func (results *RunAllInfo) failFastContainer(container types.Container, securityTest types.SecurityTest) types.Container {
+	now := time.Now()
+	if container.SecurityTest.Name == "" {
+		container.SecurityTest = securityTest
+		container.StartedAt = now
+		container.ExitCode = -1
+	}
+	container.CStatus = CancelledStatus
+	container.CResult = CancelledStatus
+	container.CInfo = FailFastNote(results.FailFastSeverity)
+	container.FinishedAt = now
+	return container
+}
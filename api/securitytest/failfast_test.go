@@ -0,0 +1,43 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FailFast", func() {
+	mediumOnly := types.HuskyCISecurityTestOutput{MediumVulns: []types.HuskyCIVulnerability{{Severity: "Medium"}}}
+
+	Describe("ReachesSeverity", func() {
+		Context("When the vulnerabilities are below the severity", func() {
+			It("Should return false.", func() {
+				Expect(securitytest.ReachesSeverity(mediumOnly, "high")).To(BeFalse())
+				Expect(securitytest.ReachesSeverity(types.HuskyCISecurityTestOutput{}, "low")).To(BeFalse())
+			})
+		})
+		Context("When the vulnerabilities reach the severity", func() {
+			It("Should return true for the severity and the ones below it.", func() {
+				Expect(securitytest.ReachesSeverity(mediumOnly, "medium")).To(BeTrue())
+				Expect(securitytest.ReachesSeverity(mediumOnly, "LOW")).To(BeTrue())
+			})
+		})
+		Context("When the severity is unknown", func() {
+			It("Should return false.", func() {
+				Expect(securitytest.ReachesSeverity(mediumOnly, "")).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("FailFastNote", func() {
+		It("Should name the severity that aborted the analysis.", func() {
+			Expect(securitytest.FailFastNote("High")).To(Equal("Cancelled by fail fast after a finding of high severity or higher."))
+		})
+	})
+})
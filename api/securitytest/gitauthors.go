@@ -6,8 +6,6 @@ package securitytest
 
 import (
 	"encoding/json"
-
-	"github.com/globocom/huskyCI/api/log"
 )
 
 // GitAuthorsOutput is the struct that holds all commit authors from a branch.
@@ -22,7 +20,7 @@ func analyzeGitAuthors(gitAuthorsScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a GitAuthors struct.
 	if err := json.Unmarshal([]byte(gitAuthorsScan.Container.COutput), &gitAuthorsOutput); err != nil {
-		log.Error("analyzeGitAuthors", "GITAUTHORS", 1035, gitAuthorsScan.Container.COutput, err)
+		gitAuthorsScan.logger().Error("analyzeGitAuthors", "GITAUTHORS", 1035, gitAuthorsScan.Container.COutput, err)
 		gitAuthorsScan.ErrorFound = err
 		gitAuthorsScan.prepareContainerAfterScan()
 		return err
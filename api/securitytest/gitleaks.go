@@ -6,9 +6,10 @@ package securitytest
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"strings"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
@@ -30,6 +31,8 @@ type GitLeaksIssue struct {
 	Date          string `json:"date"`
 	Tags          string `json:"tags"`
 	Severity      string `json:"severity"`
+	// Verified is set by secret scanners that confirmed with its provider that the secret is live.
+	Verified bool `json:"verified"`
 }
 
 func analyseGitleaks(gitleaksScan *SecTestScanInfo) error {
@@ -60,8 +63,9 @@ func analyseGitleaks(gitleaksScan *SecTestScanInfo) error {
 	}
 
 	// Unmarshall rawOutput into finalOutput, that is a GitleaksOutput struct.
-	if err := json.Unmarshal([]byte(gitleaksScan.Container.COutput), &gitLeaksOutput); err != nil {
-		log.Error("analyzeGitleaks", "GITLEAKS", 1038, gitleaksScan.Container.COutput, err)
+	gitLeaksOutput, err := decodeGitleaksOutput(gitleaksScan.Container.COutput)
+	if err != nil {
+		gitleaksScan.logger().Error("analyzeGitleaks", "GITLEAKS", 1038, gitleaksScan.Container.COutput, err)
 		gitleaksScan.ErrorFound = err
 		gitleaksScan.prepareContainerAfterScan()
 		return err
@@ -74,6 +78,30 @@ func analyseGitleaks(gitleaksScan *SecTestScanInfo) error {
 	return nil
 }
 
+// decodeGitleaksOutput decodes the reports found in rawOutput. A sharded history scan outputs one report
+// per commit range, so reports are merged. Ranges share no commit, but an issue reported twice is still
+// kept only once.
+func decodeGitleaksOutput(rawOutput string) (GitleaksOutput, error) {
+	gitLeaksOutput := GitleaksOutput{}
+	foundIssues := make(map[GitLeaksIssue]bool)
+	decoder := json.NewDecoder(strings.NewReader(rawOutput))
+	for {
+		report := GitleaksOutput{}
+		if err := decoder.Decode(&report); err == io.EOF {
+			return gitLeaksOutput, nil
+		} else if err != nil {
+			return gitLeaksOutput, err
+		}
+		for _, issue := range report {
+			if foundIssues[issue] {
+				continue
+			}
+			foundIssues[issue] = true
+			gitLeaksOutput = append(gitLeaksOutput, issue)
+		}
+	}
+}
+
 func (gitleaksScan *SecTestScanInfo) prepareGitleaksVulns() {
 
 	huskyCIgitleaksResults := types.HuskyCISecurityTestOutput{}
@@ -114,7 +142,15 @@ func (gitleaksScan *SecTestScanInfo) prepareGitleaksVulns() {
 		gitleaksVuln.Title = issue.Rule + " sensitive data found"
 		gitleaksVuln.File = issue.File
 		gitleaksVuln.Code = issue.Line
+		gitleaksVuln.Type = issue.Rule
+		gitleaksVuln.Rule = issue.Rule
 		gitleaksVuln.Title = "Hard Coded " + issue.Rule + " in: " + issue.File
+		gitleaksVuln.CommitHash = issue.Commit
+		gitleaksVuln.CommitAuthor = issue.Author
+		gitleaksVuln.Verified = issue.Verified
+		if issue.Email != "" {
+			gitleaksVuln.CommitAuthor = fmt.Sprintf("%s <%s>", issue.Author, issue.Email)
+		}
 
 		switch issue.Rule {
 		case "PKCS8", "RSA", "SSH", "PGP", "EC":
@@ -124,6 +160,7 @@ func (gitleaksScan *SecTestScanInfo) prepareGitleaksVulns() {
 		default:
 			gitleaksVuln.Severity = "LOW"
 		}
+		gitleaksVuln.Severity = gitleaksScan.overrideSeverity(issue.Rule, gitleaksVuln.Severity)
 
 		switch gitleaksVuln.Severity {
 		case "LOW":
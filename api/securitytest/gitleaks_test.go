@@ -0,0 +1,185 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeGitleaks reports, as gitleaks does, the files of every commit it audits, walking the history
+// from --commit-from, or HEAD, back to --commit-to.
+const fakeGitleaks = `#!/bin/bash
+for arg; do
+    case $arg in
+        --report=*) report=${arg#*=} ;;
+        --repo-path=*) repo=${arg#*=} ;;
+        --commit-from=*) from=${arg#*=} ;;
+        --commit-to=*) to=${arg#*=} ;;
+    esac
+done
+auditing=$([ -z "$from" ] && echo yes)
+issues=""
+for commit in $(git -C $repo rev-list HEAD 2> /dev/null); do
+    [ "$commit" = "$from" ] && auditing=yes
+    if [ -n "$auditing" ]; then
+        for file in $(git -C $repo diff-tree --no-commit-id --name-only -r --root $commit); do
+            issues="$issues{\"commit\": \"$commit\", \"file\": \"$file\", \"rule\": \"RSA\"},"
+        done
+    fi
+    [ "$commit" = "$to" ] && break
+done
+echo "[${issues%,}]" > $report
+`
+
+// gitleaksScan runs the gitleaks scan of config.yaml, without its clone and output handling, over the
+// repository in dir/code with shards commit ranges, and returns the findings it reported.
+func gitleaksScan(dir string, shards int) types.HuskyCISecurityTestOutput {
+	config := viper.New()
+	config.SetConfigFile("../config.yaml")
+	Expect(config.ReadInConfig()).To(Succeed())
+	cmd := config.GetString("gitleaks.cmd")
+	start, end := strings.Index(cmd, "touch /tmp/results.json"), strings.Index(cmd, "if [[ $STATUS")
+	Expect(start).To(BeNumerically(">", 0))
+	Expect(end).To(BeNumerically(">", start))
+	cmd = strings.Replace(cmd[start:end], "/tmp/", dir+"/tmp/", -1)
+	cmd = util.HandleGitleaksShards(util.HandleGitleaksDepth(cmd, true), shards)
+	cmd = strings.Replace(cmd, "%GIT_BRANCH%", "master", -1)
+
+	Expect(os.RemoveAll(dir + "/tmp")).To(Succeed())
+	Expect(os.Mkdir(dir+"/tmp", 0777)).To(Succeed())
+	script := exec.Command("bash", "-c", cmd)
+	script.Dir = dir
+	script.Env = append(os.Environ(), "PATH="+dir+"/bin:"+os.Getenv("PATH"))
+	output, err := script.CombinedOutput()
+	Expect(err).To(BeNil(), string(output))
+	results, err := ioutil.ReadFile(dir + "/tmp/results.json")
+	Expect(err).To(BeNil())
+	findings, err := securitytest.Parse("gitleaks", string(results))
+	Expect(err).To(BeNil())
+	return findings
+}
+
+var _ = Describe("Gitleaks", func() {
+	Describe("Parse", func() {
+		Context("When the output is a full history report", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should return the commit and author that introduced each secret.", func() {
+				output, err := securitytest.Parse("gitleaks", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Type).To(Equal("AWS Secret Key"))
+				Expect(output.MediumVulns[0].File).To(Equal("config/settings.py"))
+				Expect(output.MediumVulns[0].CommitHash).To(Equal("3f7c1a9e1b2d4c5e6f708192a3b4c5d6e7f80912"))
+				Expect(output.MediumVulns[0].CommitAuthor).To(Equal("Jane Doe <jane.doe@example.com>"))
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Type).To(Equal("RSA"))
+				Expect(output.HighVulns[0].CommitHash).To(Equal("a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"))
+				Expect(output.HighVulns[0].CommitAuthor).To(Equal("John Roe"))
+			})
+
+			It("Should skip secrets found in dependencies.", func() {
+				output, err := securitytest.Parse("gitleaks", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When the output reports a verified secret", func() {
+			It("Should mark only that secret as verified.", func() {
+				rawOutput := `[{"rule": "RSA", "file": "keys/id_rsa", "verified": true}, {"rule": "PGP", "file": "keys/key.asc"}]`
+				output, err := securitytest.Parse("gitleaks", rawOutput)
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(2))
+				Expect(output.HighVulns[0].Verified).To(BeTrue())
+				Expect(output.HighVulns[1].Verified).To(BeFalse())
+			})
+		})
+
+		Context("When the output is a sharded history report", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should return the same findings as a single-threaded scan.", func() {
+				issues := securitytest.GitleaksOutput{}
+				Expect(json.Unmarshal(rawOutput, &issues)).To(Succeed())
+				Expect(len(issues)).To(BeNumerically(">", 2))
+
+				// the second issue is reported by both reports.
+				firstShard, err := json.Marshal(issues[:2])
+				Expect(err).To(BeNil())
+				secondShard, err := json.Marshal(issues[1:])
+				Expect(err).To(BeNil())
+				shardedOutput := string(firstShard) + string(secondShard) + "[]"
+
+				singleThreaded, err := securitytest.Parse("gitleaks", string(rawOutput))
+				Expect(err).To(BeNil())
+				sharded, err := securitytest.Parse("gitleaks", shardedOutput)
+				Expect(err).To(BeNil())
+				Expect(sharded.HighVulns).To(ConsistOf(singleThreaded.HighVulns))
+				Expect(sharded.MediumVulns).To(ConsistOf(singleThreaded.MediumVulns))
+				Expect(sharded.LowVulns).To(ConsistOf(singleThreaded.LowVulns))
+			})
+		})
+	})
+
+	Describe("History scan", func() {
+		var dir string
+		BeforeEach(func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not installed")
+			}
+			var err error
+			dir, err = ioutil.TempDir("", "huskyci-gitleaks")
+			Expect(err).To(BeNil())
+			Expect(os.MkdirAll(dir+"/bin", 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(dir+"/bin/gitleaks", []byte(fakeGitleaks), 0755)).To(Succeed())
+			// the image runs the busybox timeout, that takes the duration with -t.
+			Expect(ioutil.WriteFile(dir+"/bin/timeout", []byte("#!/bin/bash\nshift 2\nexec \"$@\"\n"), 0755)).To(Succeed())
+			Expect(exec.Command("git", "init", "--quiet", dir+"/code").Run()).To(Succeed())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		Context("When the history is split into concurrent commit ranges", func() {
+			It("Should report the same findings as a single scan.", func() {
+				for i := 1; i <= 7; i++ {
+					commit := exec.Command("bash", "-c", fmt.Sprintf("echo %d > key%d.pem && git add . && git -c user.name=huskyCI -c user.email=huskyci@localhost commit --quiet -m %d", i, i, i))
+					commit.Dir = dir + "/code"
+					output, err := commit.CombinedOutput()
+					Expect(err).To(BeNil(), string(output))
+				}
+				singleScan := gitleaksScan(dir, 1)
+				Expect(singleScan.HighVulns).To(HaveLen(7))
+				Expect(gitleaksScan(dir, 3).HighVulns).To(ConsistOf(singleScan.HighVulns))
+			})
+		})
+
+		Context("When the repository has no commits", func() {
+			It("Should scan it at once.", func() {
+				Expect(gitleaksScan(dir, 3).HighVulns).To(BeEmpty())
+			})
+		})
+	})
+})
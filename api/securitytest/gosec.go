@@ -7,7 +7,6 @@ package securitytest
 import (
 	"encoding/json"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
@@ -49,7 +48,7 @@ func analyzeGosec(gosecScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a GosecOutput struct.
 	if err := json.Unmarshal([]byte(gosecScan.Container.COutput), &goSecOutput); err != nil {
-		log.Error("analyzeGosec", "GOSEC", 1002, gosecScan.Container.COutput, err)
+		gosecScan.logger().Error("analyzeGosec", "GOSEC", 1002, gosecScan.Container.COutput, err)
 		gosecScan.ErrorFound = err
 		gosecScan.prepareContainerAfterScan()
 		return err
@@ -72,7 +71,8 @@ func (gosecScan *SecTestScanInfo) prepareGosecVulns() {
 		gosecVuln.Language = "Go"
 		gosecVuln.SecurityTool = "GoSec"
 		gosecVuln.Title = issue.Details
-		gosecVuln.Severity = issue.Severity
+		gosecVuln.Rule = issue.RuleID
+		gosecVuln.Severity = gosecScan.overrideSeverity(issue.RuleID, issue.Severity)
 		gosecVuln.Confidence = issue.Confidence
 		gosecVuln.Details = issue.Details
 		gosecVuln.File = issue.File
@@ -0,0 +1,245 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// The reachability of a vulnerability found by govulncheck, from the most to the least precise one.
+const (
+	// ReachabilityCalled states that the code of the repository calls a vulnerable function.
+	ReachabilityCalled = "called"
+	// ReachabilityImported states that the code of the repository imports a vulnerable package without calling its vulnerable functions.
+	ReachabilityImported = "imported"
+	// ReachabilityRequired states that the repository only requires a vulnerable module.
+	ReachabilityRequired = "required"
+)
+
+// GovulncheckOutput is the struct that holds all data from govulncheck JSON output, merging the outputs of every scanned module.
+type GovulncheckOutput struct {
+	Modules []GovulncheckModule `json:"modules"`
+}
+
+// GovulncheckModule is the struct that holds the messages govulncheck printed scanning the module of a go.mod.
+type GovulncheckModule struct {
+	Dir      string               `json:"dir"`
+	Messages []GovulncheckMessage `json:"messages"`
+}
+
+// GovulncheckMessage is the struct that holds a message of govulncheck JSON stream. Only advisories and findings are read.
+type GovulncheckMessage struct {
+	OSV     *GovulncheckOSV     `json:"osv"`
+	Finding *GovulncheckFinding `json:"finding"`
+}
+
+// GovulncheckOSV is the struct that holds an advisory of the Go vulnerability database.
+type GovulncheckOSV struct {
+	ID      string   `json:"id"`
+	Summary string   `json:"summary"`
+	Details string   `json:"details"`
+	Aliases []string `json:"aliases"`
+}
+
+// GovulncheckFinding is the struct that holds a finding of an advisory. The first frame of its trace is the vulnerable
+// module, package or function, as precise as the finding is, and the last one is where the code of the repository reaches it.
+type GovulncheckFinding struct {
+	OSV          string             `json:"osv"`
+	FixedVersion string             `json:"fixed_version"`
+	Trace        []GovulncheckFrame `json:"trace"`
+}
+
+// GovulncheckFrame is the struct that holds a frame of the trace of a finding.
+type GovulncheckFrame struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Receiver string `json:"receiver"`
+	Position *struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+	} `json:"position"`
+}
+
+func analyzeGovulncheck(govulncheckScan *SecTestScanInfo) error {
+
+	govulncheckOutput := GovulncheckOutput{}
+	govulncheckScan.FinalOutput = govulncheckOutput
+
+	// check if there were any internal errors running govulncheck
+	if strings.Contains(govulncheckScan.Container.COutput, "ERROR_RUNNING_GOVULNCHECK") {
+		errorMsg := errors.New("internal error govulncheck - ERROR_RUNNING_GOVULNCHECK")
+		govulncheckScan.logger().Error("analyzeGovulncheck", "GOVULNCHECK", 1076, errorMsg)
+		govulncheckScan.ErrorFound = errorMsg
+		govulncheckScan.prepareContainerAfterScan()
+		return errorMsg
+	}
+
+	// nil cOutput states that no go.mod was found.
+	if strings.TrimSpace(govulncheckScan.Container.COutput) == "" {
+		govulncheckScan.prepareContainerAfterScan()
+		return nil
+	}
+
+	// Unmarshall rawOutput into finalOutput, that is a GovulncheckOutput struct.
+	if err := json.Unmarshal([]byte(govulncheckScan.Container.COutput), &govulncheckOutput); err != nil {
+		govulncheckScan.logger().Error("analyzeGovulncheck", "GOVULNCHECK", 1075, govulncheckScan.Container.COutput, err)
+		govulncheckScan.ErrorFound = err
+		govulncheckScan.prepareContainerAfterScan()
+		return err
+	}
+	govulncheckScan.FinalOutput = govulncheckOutput
+
+	// check results and prepare all vulnerabilities found
+	govulncheckScan.prepareGovulncheckVulns()
+	govulncheckScan.prepareContainerAfterScan()
+	return nil
+}
+
+// govulncheckVuln holds the findings of an advisory in a module of a go.mod, reported as a single vulnerability.
+type govulncheckVuln struct {
+	dir          string
+	osv          string
+	fixedVersion string
+	reachability string
+	vulnerable   GovulncheckFrame
+	entry        GovulncheckFrame
+	called       []string
+}
+
+func (govulncheckScan *SecTestScanInfo) prepareGovulncheckVulns() {
+
+	huskyCIgovulncheckResults := types.HuskyCISecurityTestOutput{}
+	govulncheckOutput := govulncheckScan.FinalOutput.(GovulncheckOutput)
+
+	advisories := make(map[string]GovulncheckOSV)
+	for _, module := range govulncheckOutput.Modules {
+		for _, message := range module.Messages {
+			if message.OSV != nil {
+				advisories[message.OSV.ID] = *message.OSV
+			}
+		}
+	}
+
+	for _, vuln := range govulncheckVulns(govulncheckOutput) {
+		advisory := advisories[vuln.osv]
+		govulncheckVuln := types.HuskyCIVulnerability{}
+		govulncheckVuln.Language = "Go"
+		govulncheckVuln.SecurityTool = "govulncheck"
+		govulncheckVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", vuln.vulnerable.Module, vuln.vulnerable.Version, vuln.osv)
+		govulncheckVuln.Details = govulncheckDetails(vuln, advisory)
+		govulncheckVuln.Type = vuln.osv
+		govulncheckVuln.Code = vuln.vulnerable.Module
+		govulncheckVuln.Version = vuln.vulnerable.Version
+		govulncheckVuln.Reachability = vuln.reachability
+		govulncheckVuln.File = repositoryFile(path.Join(vuln.dir, "go.mod"))
+		if vuln.entry.Position != nil {
+			govulncheckVuln.File = govulncheckFile(vuln.dir, vuln.entry.Position.Filename)
+			govulncheckVuln.Line = fmt.Sprintf("%d", vuln.entry.Position.Line)
+		}
+		for _, alias := range append([]string{advisory.ID}, advisory.Aliases...) {
+			if strings.HasPrefix(alias, "CVE-") {
+				govulncheckVuln.CVE = alias
+				break
+			}
+		}
+
+		// the Go vulnerability database rates no severity, so it is set by reachability: only the
+		// vulnerabilities whose functions are called put the repository at risk right away.
+		if vuln.reachability == ReachabilityCalled {
+			govulncheckVuln.Severity = "high"
+			huskyCIgovulncheckResults.HighVulns = append(huskyCIgovulncheckResults.HighVulns, govulncheckVuln)
+		} else {
+			govulncheckVuln.Severity = "low"
+			huskyCIgovulncheckResults.LowVulns = append(huskyCIgovulncheckResults.LowVulns, govulncheckVuln)
+		}
+	}
+
+	govulncheckScan.Vulnerabilities = huskyCIgovulncheckResults
+}
+
+// govulncheckVulns merges the findings of each advisory in each module of output, as govulncheck reports an advisory
+// once for each level it reaches, into a vulnerability of the most precise reachability, in the order they were found.
+func govulncheckVulns(output GovulncheckOutput) []*govulncheckVuln {
+	vulns := []*govulncheckVuln{}
+	found := make(map[string]*govulncheckVuln)
+	for _, module := range output.Modules {
+		for _, message := range module.Messages {
+			if message.Finding == nil || len(message.Finding.Trace) == 0 {
+				continue
+			}
+			finding := message.Finding
+			vulnerable := finding.Trace[0]
+			key := module.Dir + " " + finding.OSV + " " + vulnerable.Module
+			vuln, ok := found[key]
+			if !ok {
+				vuln = &govulncheckVuln{dir: module.Dir, osv: finding.OSV, fixedVersion: finding.FixedVersion, reachability: ReachabilityRequired, vulnerable: vulnerable}
+				found[key] = vuln
+				vulns = append(vulns, vuln)
+			}
+			switch {
+			case vulnerable.Function != "":
+				if vuln.reachability != ReachabilityCalled {
+					vuln.reachability = ReachabilityCalled
+					vuln.entry = finding.Trace[len(finding.Trace)-1]
+				}
+				if symbol := govulncheckSymbol(vulnerable); !util.SliceContains(vuln.called, symbol) {
+					vuln.called = append(vuln.called, symbol)
+				}
+			case vulnerable.Package != "" && vuln.reachability == ReachabilityRequired:
+				vuln.reachability = ReachabilityImported
+			}
+		}
+	}
+	return vulns
+}
+
+// govulncheckDetails returns the summary and details of an advisory, followed by the vulnerable functions the
+// repository calls and the version fixing it when known.
+func govulncheckDetails(vuln *govulncheckVuln, advisory GovulncheckOSV) string {
+	details := []string{}
+	for _, detail := range []string{advisory.Summary, advisory.Details} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	switch vuln.reachability {
+	case ReachabilityCalled:
+		details = append(details, "Vulnerable functions called: "+strings.Join(vuln.called, ", ")+".")
+	case ReachabilityImported:
+		details = append(details, "A vulnerable package is imported, but none of its vulnerable functions is called.")
+	default:
+		details = append(details, "The vulnerable module is required, but none of its vulnerable packages is imported.")
+	}
+	if vuln.fixedVersion != "" {
+		details = append(details, "Fixed in version "+vuln.fixedVersion+".")
+	}
+	return strings.Join(details, "\n")
+}
+
+// govulncheckSymbol returns the function of frame qualified by its package and receiver.
+func govulncheckSymbol(frame GovulncheckFrame) string {
+	symbol := frame.Function
+	if receiver := strings.TrimPrefix(frame.Receiver, "*"); receiver != "" {
+		symbol = receiver + "." + symbol
+	}
+	return frame.Package + "." + symbol
+}
+
+// govulncheckFile returns filename, printed by govulncheck running in the module of dir, relative to the repository.
+func govulncheckFile(dir, filename string) string {
+	if path.IsAbs(filename) {
+		return repositoryFile(filename)
+	}
+	return repositoryFile(path.Join(dir, filename))
+}
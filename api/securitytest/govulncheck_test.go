@@ -0,0 +1,87 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Govulncheck", func() {
+	Describe("Parse", func() {
+		Context("When the output has findings of several modules", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/govulncheck_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should report each advisory of a module once, at its most precise reachability.", func() {
+				output, err := securitytest.Parse("govulncheck", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Type).To(Equal("GO-2022-0969"))
+				Expect(output.HighVulns[0].Reachability).To(Equal(securitytest.ReachabilityCalled))
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(HaveLen(2))
+				Expect(output.LowVulns[0].Type).To(Equal("GO-2021-0113"))
+				Expect(output.LowVulns[0].Reachability).To(Equal(securitytest.ReachabilityImported))
+				Expect(output.LowVulns[1].Type).To(Equal("GO-2023-1571"))
+				Expect(output.LowVulns[1].Reachability).To(Equal(securitytest.ReachabilityRequired))
+			})
+
+			It("Should point a called vulnerability to the code of the repository calling it.", func() {
+				output, err := securitytest.Parse("govulncheck", string(rawOutput))
+				Expect(err).To(BeNil())
+				called := output.HighVulns[0]
+				Expect(called.SecurityTool).To(Equal("govulncheck"))
+				Expect(called.Language).To(Equal("Go"))
+				Expect(called.Title).To(Equal("Vulnerable Dependency: golang.org/x/net v0.0.0-20220722155237-a158d28d115b (GO-2022-0969)"))
+				Expect(called.Code).To(Equal("golang.org/x/net"))
+				Expect(called.Version).To(Equal("v0.0.0-20220722155237-a158d28d115b"))
+				Expect(called.CVE).To(Equal("CVE-2022-27664"))
+				Expect(called.File).To(Equal("server/server.go"))
+				Expect(called.Line).To(Equal("27"))
+				Expect(called.Details).To(ContainSubstring("Vulnerable functions called: golang.org/x/net/http2.Server.ServeConn."))
+				Expect(called.Details).To(ContainSubstring("Fixed in version v0.0.0-20220906165146-f3363e06e74c."))
+			})
+
+			It("Should point the other vulnerabilities to the go.mod of their module.", func() {
+				output, err := securitytest.Parse("govulncheck", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.LowVulns[0].File).To(Equal("go.mod"))
+				Expect(output.LowVulns[0].Details).To(ContainSubstring("none of its vulnerable functions is called"))
+				Expect(output.LowVulns[1].File).To(Equal("tools/go.mod"))
+				Expect(output.LowVulns[1].CVE).To(BeEmpty())
+			})
+		})
+
+		Context("When the repository has no go.mod", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("govulncheck", "")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When govulncheck fails to run", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("govulncheck", "ERROR_RUNNING_GOVULNCHECK\ngo: updates to go.mod needed")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("When the output is malformed", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("govulncheck", `{"modules": `)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
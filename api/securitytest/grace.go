@@ -0,0 +1,60 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/types"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// GracePeriodNote is the informational note of a securityTest whose findings did not fail the analysis,
+// as it was within its grace days for the repository.
+const GracePeriodNote = "Issues found. They do not fail the analysis during the grace days of this securityTest."
+
+// applyGracePeriod turns the failed container of scanInfo into a passed one while its securityTest is within
+// its grace days for the repository. The first time the securityTest runs for the repository is recorded,
+// starting its grace days, which end only once they are over.
+func (scanInfo *SecTestScanInfo) applyGracePeriod() {
+	graceDays := scanInfo.Container.SecurityTest.GraceDays
+	if graceDays <= 0 || scanInfo.Container.CStatus != "finished" {
+		return
+	}
+	now := time.Now()
+	startedAt, ok := scanInfo.rolloutStart(now)
+	if !ok || scanInfo.Container.CResult != "failed" {
+		return
+	}
+	if now.Before(startedAt.AddDate(0, 0, graceDays)) {
+		scanInfo.Container.CResult = "passed"
+		scanInfo.Container.CInfo = GracePeriodNote
+		scanInfo.Container.GracePeriod = true
+	}
+}
+
+// rolloutStart returns when the securityTest of scanInfo first ran for the repository, recording now if it
+// never did. It returns false when the database could not tell, so the securityTest is enforced.
+func (scanInfo *SecTestScanInfo) rolloutStart(now time.Time) (time.Time, bool) {
+	rolloutQuery := map[string]interface{}{"repositoryURL": scanInfo.URL, "securityTestName": scanInfo.Container.SecurityTest.Name}
+	rollout, err := apiContext.APIConfiguration.DBInstance.FindOneDBSecurityTestRollout(rolloutQuery)
+	if err == nil {
+		return rollout.StartedAt, true
+	}
+	if err != mgo.ErrNotFound && err.Error() != "No data found" {
+		scanInfo.logger().Error("applyGracePeriod", "SECURITYTEST", 2021, err)
+		return time.Time{}, false
+	}
+	rollout = types.SecurityTestRollout{URL: scanInfo.URL, SecurityTestName: scanInfo.Container.SecurityTest.Name, StartedAt: now}
+	if err := apiContext.APIConfiguration.DBInstance.InsertDBSecurityTestRollout(rollout); err != nil {
+		// another analysis of the repository may have recorded it first.
+		if rollout, err = apiContext.APIConfiguration.DBInstance.FindOneDBSecurityTestRollout(rolloutQuery); err != nil {
+			scanInfo.logger().Error("applyGracePeriod", "SECURITYTEST", 2022, err)
+			return time.Time{}, false
+		}
+	}
+	return rollout.StartedAt, true
+}
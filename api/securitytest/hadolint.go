@@ -0,0 +1,83 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// HadolintOutput is the struct that holds all data from Hadolint output.
+type HadolintOutput []HadolintIssue
+
+// HadolintIssue is the struct that holds detailed information of issues from Hadolint output.
+type HadolintIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Column  int    `json:"column"`
+	File    string `json:"file"`
+	Level   string `json:"level"`
+	Line    int    `json:"line"`
+}
+
+func analyzeHadolint(hadolintScan *SecTestScanInfo) error {
+
+	hadolintOutput := HadolintOutput{}
+	hadolintScan.FinalOutput = hadolintOutput
+
+	// nil cOutput states that no Dockerfile was found.
+	if strings.TrimSpace(hadolintScan.Container.COutput) == "" {
+		hadolintScan.prepareContainerAfterScan()
+		return nil
+	}
+
+	// Unmarshall rawOutput into finalOutput, that is a HadolintOutput struct.
+	if err := json.Unmarshal([]byte(hadolintScan.Container.COutput), &hadolintOutput); err != nil {
+		hadolintScan.logger().Error("analyzeHadolint", "HADOLINT", 1044, hadolintScan.Container.COutput, err)
+		hadolintScan.ErrorFound = err
+		hadolintScan.prepareContainerAfterScan()
+		return err
+	}
+	hadolintScan.FinalOutput = hadolintOutput
+
+	// check results and prepare all vulnerabilities found
+	hadolintScan.prepareHadolintVulns()
+	hadolintScan.prepareContainerAfterScan()
+	return nil
+}
+
+func (hadolintScan *SecTestScanInfo) prepareHadolintVulns() {
+
+	huskyCIhadolintResults := types.HuskyCISecurityTestOutput{}
+	hadolintOutput := hadolintScan.FinalOutput.(HadolintOutput)
+
+	for _, issue := range hadolintOutput {
+		hadolintVuln := types.HuskyCIVulnerability{}
+		hadolintVuln.Language = "Dockerfile"
+		hadolintVuln.SecurityTool = "Hadolint"
+		hadolintVuln.Title = issue.Message
+		hadolintVuln.Details = issue.Code + " @ [" + issue.Message + "]"
+		hadolintVuln.Type = issue.Code
+		hadolintVuln.File = strings.TrimPrefix(issue.File, "./")
+		hadolintVuln.Line = strconv.Itoa(issue.Line)
+
+		switch strings.ToLower(issue.Level) {
+		case "error":
+			hadolintVuln.Severity = "High"
+			huskyCIhadolintResults.HighVulns = append(huskyCIhadolintResults.HighVulns, hadolintVuln)
+		case "warning":
+			hadolintVuln.Severity = "Medium"
+			huskyCIhadolintResults.MediumVulns = append(huskyCIhadolintResults.MediumVulns, hadolintVuln)
+		case "info", "style":
+			hadolintVuln.Severity = "Low"
+			huskyCIhadolintResults.LowVulns = append(huskyCIhadolintResults.LowVulns, hadolintVuln)
+		}
+	}
+
+	hadolintScan.Vulnerabilities = huskyCIhadolintResults
+}
@@ -0,0 +1,67 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hadolint", func() {
+	Describe("Parse", func() {
+		Context("When the output has issues of every level", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/hadolint_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map hadolint levels into huskyCI severities.", func() {
+				output, err := securitytest.Parse("hadolint", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Type).To(Equal("DL3000"))
+				Expect(output.HighVulns[0].Severity).To(Equal("High"))
+				Expect(output.MediumVulns).To(HaveLen(2))
+				Expect(output.MediumVulns[0].Severity).To(Equal("Medium"))
+				Expect(output.LowVulns).To(HaveLen(2))
+				Expect(output.LowVulns[0].Type).To(Equal("SC2086"))
+				Expect(output.LowVulns[1].Type).To(Equal("DL3059"))
+			})
+
+			It("Should extract the file and line of each issue.", func() {
+				output, err := securitytest.Parse("hadolint", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns[1].File).To(Equal("Dockerfile"))
+				Expect(output.MediumVulns[1].Line).To(Equal("4"))
+				Expect(output.MediumVulns[1].Title).To(Equal("Last USER should not be root"))
+				Expect(output.HighVulns[0].File).To(Equal("deployments/api.Dockerfile"))
+				Expect(output.HighVulns[0].Line).To(Equal("9"))
+				Expect(output.HighVulns[0].Language).To(Equal("Dockerfile"))
+				Expect(output.HighVulns[0].SecurityTool).To(Equal("Hadolint"))
+			})
+		})
+
+		Context("When the repository has no Dockerfile", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("hadolint", "")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When the output is malformed", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("hadolint", `[{"line": `)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -9,15 +9,20 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
-// NpmAuditOutput is the struct that stores all npm audit output
+// NpmAuditOutput is the struct that stores all npm audit output. Reports of npm 6 hold their advisories
+// in Advisories, while reports of npm 7 and later, whose AuditReportVersion is 2, hold them in the Via
+// of each vulnerable package of Vulnerabilities.
 type NpmAuditOutput struct {
-	Advisories      map[string]Vulnerability `json:"advisories"`
-	Metadata        Metadata                 `json:"metadata"`
-	PackageNotFound bool
+	AuditReportVersion int                        `json:"auditReportVersion"`
+	Advisories         map[string]Vulnerability   `json:"advisories"`
+	Vulnerabilities    map[string]NpmAuditPackage `json:"vulnerabilities"`
+	Metadata           Metadata                   `json:"metadata"`
+	PackageNotFound    bool
+	// Packages are the production dependencies of package-lock.json, vulnerable or not, added by huskyCI.
+	Packages []DependencyPackage `json:"huskyciPackages"`
 }
 
 // Vulnerability is the granular output of a security info found
@@ -29,6 +34,39 @@ type Vulnerability struct {
 	Severity           string    `json:"severity"`
 	Overview           string    `json:"overview"`
 	Title              string    `json:"title"`
+	CVEs               []string  `json:"cves"`
+	CVSS               CVSS      `json:"cvss"`
+}
+
+// NpmAuditPackage is a vulnerable package of a npm 7+ report.
+type NpmAuditPackage struct {
+	Name     string        `json:"name"`
+	Severity string        `json:"severity"`
+	Range    string        `json:"range"`
+	Via      []NpmAuditVia `json:"via"`
+}
+
+// NpmAuditVia is the reason a package of a npm 7+ report is vulnerable: either an advisory affecting
+// the package itself or, when only Dependency is set, a vulnerable package it depends on.
+type NpmAuditVia struct {
+	Source     int    `json:"source"`
+	Name       string `json:"name"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Severity   string `json:"severity"`
+	Range      string `json:"range"`
+	CVSS       CVSS   `json:"cvss"`
+	Dependency string `json:"-"`
+}
+
+// UnmarshalJSON decodes via, which npm reports as an advisory object or as the name of a dependency.
+func (via *NpmAuditVia) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*via = NpmAuditVia{}
+		return json.Unmarshal(data, &via.Dependency)
+	}
+	type advisory NpmAuditVia
+	return json.Unmarshal(data, (*advisory)(via))
 }
 
 // Finding holds the version of a given security issue found
@@ -72,10 +110,11 @@ func analyzeNpmaudit(npmAuditScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a NpmAuditOutput struct.
 	if err := json.Unmarshal([]byte(npmAuditScan.Container.COutput), &npmAuditOutput); err != nil {
-		log.Error("analyzeNpmaudit", "NPMAUDIT", 1014, npmAuditScan.Container.COutput, err)
+		npmAuditScan.logger().Error("analyzeNpmaudit", "NPMAUDIT", 1014, npmAuditScan.Container.COutput, err)
 		return err
 	}
 	npmAuditScan.FinalOutput = npmAuditOutput
+	npmAuditScan.Components = components("npm", npmAuditOutput.Packages)
 
 	// step 4: find Issues that have severity "MEDIUM" or "HIGH" and confidence "HIGH".
 	npmAuditScan.prepareNpmAuditVulns()
@@ -100,6 +139,11 @@ func (npmAuditScan *SecTestScanInfo) prepareNpmAuditVulns() {
 		return
 	}
 
+	if npmAuditOutput.AuditReportVersion >= 2 {
+		npmAuditScan.Vulnerabilities = npmAuditV7Vulns(npmAuditOutput)
+		return
+	}
+
 	for _, issue := range npmAuditOutput.Advisories {
 		npmauditVuln := types.HuskyCIVulnerability{}
 		npmauditVuln.Language = "JavaScript"
@@ -108,19 +152,20 @@ func (npmAuditScan *SecTestScanInfo) prepareNpmAuditVulns() {
 		npmauditVuln.Details = issue.Overview
 		npmauditVuln.VunerableBelow = issue.VulnerableVersions
 		npmauditVuln.Code = issue.ModuleName
+		npmauditVuln.CVE = strings.Join(issue.CVEs, ", ")
+		npmauditVuln.CVSSScore = issue.CVSS.Score
+		npmauditVuln.CVSSVector = issue.CVSS.VectorString
 		for _, findings := range issue.Findings {
 			npmauditVuln.Version = findings.Version
 		}
 
-		switch issue.Severity {
-		case "info", "low":
-			npmauditVuln.Severity = "low"
+		npmauditVuln.Severity = auditSeverity(issue.Severity, issue.CVSS)
+		switch npmauditVuln.Severity {
+		case "low":
 			huskyCInpmauditResults.LowVulns = append(huskyCInpmauditResults.LowVulns, npmauditVuln)
-		case "moderate":
-			npmauditVuln.Severity = "medium"
+		case "medium":
 			huskyCInpmauditResults.MediumVulns = append(huskyCInpmauditResults.MediumVulns, npmauditVuln)
-		case "high", "critical":
-			npmauditVuln.Severity = "high"
+		case "high":
 			huskyCInpmauditResults.HighVulns = append(huskyCInpmauditResults.HighVulns, npmauditVuln)
 		}
 
@@ -128,3 +173,47 @@ func (npmAuditScan *SecTestScanInfo) prepareNpmAuditVulns() {
 
 	npmAuditScan.Vulnerabilities = huskyCInpmauditResults
 }
+
+// npmAuditV7Vulns returns the advisories of a npm 7+ report. Packages only vulnerable through one of
+// their dependencies are skipped, as the advisory is already reported for that dependency.
+func npmAuditV7Vulns(npmAuditOutput NpmAuditOutput) types.HuskyCISecurityTestOutput {
+
+	huskyCInpmauditResults := types.HuskyCISecurityTestOutput{}
+
+	for _, pkg := range npmAuditOutput.Vulnerabilities {
+		for _, via := range pkg.Via {
+			if via.Dependency != "" {
+				continue
+			}
+			moduleName := via.Name
+			if moduleName == "" {
+				moduleName = pkg.Name
+			}
+			npmauditVuln := types.HuskyCIVulnerability{}
+			npmauditVuln.Language = "JavaScript"
+			npmauditVuln.SecurityTool = "NpmAudit"
+			npmauditVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", moduleName, via.Range, via.Title)
+			npmauditVuln.Details = via.Title
+			if via.URL != "" {
+				npmauditVuln.Details = fmt.Sprintf("%s. More info: %s", via.Title, via.URL)
+			}
+			npmauditVuln.VunerableBelow = via.Range
+			npmauditVuln.Code = moduleName
+			npmauditVuln.CVE = strings.Join(findCVEs(via.Title), ", ")
+			npmauditVuln.CVSSScore = via.CVSS.Score
+			npmauditVuln.CVSSVector = via.CVSS.VectorString
+
+			npmauditVuln.Severity = auditSeverity(via.Severity, via.CVSS)
+			switch npmauditVuln.Severity {
+			case "low":
+				huskyCInpmauditResults.LowVulns = append(huskyCInpmauditResults.LowVulns, npmauditVuln)
+			case "medium":
+				huskyCInpmauditResults.MediumVulns = append(huskyCInpmauditResults.MediumVulns, npmauditVuln)
+			case "high":
+				huskyCInpmauditResults.HighVulns = append(huskyCInpmauditResults.HighVulns, npmauditVuln)
+			}
+		}
+	}
+
+	return huskyCInpmauditResults
+}
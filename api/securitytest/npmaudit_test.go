@@ -0,0 +1,105 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NpmAudit", func() {
+	Describe("Parse", func() {
+		Context("When the advisories have CVSS data", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/npmaudit_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should keep the CVE ids and CVSS scores of the advisories.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Code).To(Equal("minimist"))
+				Expect(output.LowVulns[0].CVE).To(Equal("CVE-2020-7598"))
+				Expect(output.LowVulns[0].CVSSScore).To(Equal(5.6))
+				Expect(output.LowVulns[0].CVSSVector).To(Equal("CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:L/A:L"))
+			})
+
+			It("Should prefer the severity given by npm audit over the CVSS score.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.LowVulns[0].Severity).To(Equal("low"))
+			})
+
+			It("Should derive the severity from the CVSS score when npm audit gives an unknown one.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Code).To(Equal("lodash"))
+				Expect(output.HighVulns[0].Severity).To(Equal("high"))
+				Expect(output.HighVulns[0].CVSSScore).To(Equal(7.4))
+			})
+		})
+
+		Context("When an advisory has no CVSS data", func() {
+			rawOutput, _ := ioutil.ReadFile("testdata/npmaudit_output.json")
+			It("Should leave the CVSS fields empty.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Code).To(Equal("node-fetch"))
+				Expect(output.MediumVulns[0].CVE).To(BeEmpty())
+				Expect(output.MediumVulns[0].CVSSScore).To(BeZero())
+				Expect(output.MediumVulns[0].CVSSVector).To(BeEmpty())
+			})
+		})
+
+		Context("When the output is a npm 7+ report", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/npmaudit_v7_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should return every advisory of the vulnerable packages.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Code).To(Equal("minimist"))
+				Expect(output.HighVulns[0].VunerableBelow).To(Equal("<0.2.4"))
+				Expect(output.HighVulns[0].Title).To(Equal("Vulnerable Dependency: minimist <0.2.4 (Prototype Pollution in minimist)"))
+				Expect(output.HighVulns[0].Details).To(ContainSubstring("https://github.com/advisories/GHSA-xvch-5gv4-984h"))
+				Expect(output.HighVulns[0].CVSSScore).To(Equal(9.8))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Code).To(Equal("minimist"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Code).To(Equal("node-fetch"))
+				Expect(output.LowVulns[0].CVSSVector).To(BeEmpty())
+			})
+
+			It("Should not report packages only vulnerable through a dependency.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				for _, vuln := range append(append(output.HighVulns, output.MediumVulns...), output.LowVulns...) {
+					Expect(vuln.Code).NotTo(Equal("mkdirp"))
+				}
+			})
+		})
+
+		Context("When the output is a legacy report", func() {
+			rawOutput, _ := ioutil.ReadFile("testdata/npmaudit_output.json")
+			It("Should still return its advisories.", func() {
+				output, err := securitytest.Parse("npmaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.LowVulns).To(HaveLen(1))
+			})
+		})
+	})
+})
@@ -0,0 +1,56 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// SkippedStatus is the status and result of the container of a securityTest that did not run.
+const SkippedStatus = "skipped"
+
+// networkSecurityTests are the securityTests that need network access to reach their vulnerability database.
+var networkSecurityTests = map[string]bool{
+	safety:                true,
+	npmaudit:              true,
+	yarnaudit:             true,
+	dependencycheck:       true,
+	dependencycheckgradle: true,
+	osvscanner:            true,
+	govulncheck:           true,
+	trivy:                 true,
+}
+
+// OfflineMirror returns the mirror that securityTestName has to use in offline mode and whether it can run.
+// securityTests that do not need network access always run without a mirror, as they all do when not offline.
+func OfflineMirror(securityTestName string, offlineMode bool, mirrors map[string]string) (string, bool) {
+	if !offlineMode || !networkSecurityTests[securityTestName] {
+		return "", true
+	}
+	mirror, ok := mirrors[securityTestName]
+	return mirror, ok
+}
+
+// OfflineSkipNote returns the informational note of a securityTest skipped in offline mode.
+func OfflineSkipNote(securityTestName string) string {
+	return fmt.Sprintf("%s needs network access and was skipped in offline mode, as no mirror is configured for it.", securityTestName)
+}
+
+// offlineSkippedContainer returns the container recording that securityTest was skipped in offline mode.
+func offlineSkippedContainer(securityTest types.SecurityTest) types.Container {
+	now := time.Now()
+	return types.Container{
+		SecurityTest: securityTest,
+		CStatus:      SkippedStatus,
+		CResult:      SkippedStatus,
+		CInfo:        OfflineSkipNote(securityTest.Name),
+		StartedAt:    now,
+		FinishedAt:   now,
+		ExitCode:     -1,
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Offline", func() {
+	mirrors := map[string]string{"npmaudit": "https://npm.example.com"}
+
+	Describe("OfflineMirror", func() {
+		Context("When offline mode is disabled", func() {
+			It("Should run every securityTest without a mirror.", func() {
+				for _, name := range []string{"safety", "npmaudit", "gosec"} {
+					mirror, ok := securitytest.OfflineMirror(name, false, mirrors)
+					Expect(ok).To(BeTrue(), name)
+					Expect(mirror).To(BeEmpty(), name)
+				}
+			})
+		})
+		Context("When offline mode is enabled", func() {
+			It("Should run securityTests that do not need network access without a mirror.", func() {
+				mirror, ok := securitytest.OfflineMirror("gosec", true, mirrors)
+				Expect(ok).To(BeTrue())
+				Expect(mirror).To(BeEmpty())
+			})
+			It("Should route securityTests that need network access to their mirror.", func() {
+				mirror, ok := securitytest.OfflineMirror("npmaudit", true, mirrors)
+				Expect(ok).To(BeTrue())
+				Expect(mirror).To(Equal("https://npm.example.com"))
+			})
+			It("Should skip securityTests that need network access and have no mirror.", func() {
+				for _, name := range []string{"safety", "yarnaudit"} {
+					_, ok := securitytest.OfflineMirror(name, true, mirrors)
+					Expect(ok).To(BeFalse(), name)
+				}
+			})
+		})
+	})
+
+	Describe("OfflineSkipNote", func() {
+		It("Should name the skipped securityTest and why it was skipped.", func() {
+			Expect(securitytest.OfflineSkipNote("safety")).To(Equal("safety needs network access and was skipped in offline mode, as no mirror is configured for it."))
+		})
+	})
+})
@@ -0,0 +1,170 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// OSVScannerOutput is the struct that holds all data from osv-scanner JSON report, merging the reports of every scanned lockfile.
+type OSVScannerOutput struct {
+	Results []OSVScannerResult `json:"results"`
+}
+
+// OSVScannerResult is the struct that holds the vulnerable packages osv-scanner found in a lockfile.
+type OSVScannerResult struct {
+	Source struct {
+		Path string `json:"path"`
+	} `json:"source"`
+	Packages []OSVScannerPackage `json:"packages"`
+}
+
+// OSVScannerPackage is the struct that holds a package of a lockfile and the advisories affecting its version.
+type OSVScannerPackage struct {
+	Package struct {
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Vulnerabilities []OSVScannerVulnerability `json:"vulnerabilities"`
+}
+
+// OSVScannerVulnerability is the struct that holds detailed information of an advisory from osv-scanner output.
+type OSVScannerVulnerability struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+	Affected []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []struct {
+				Fixed string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+}
+
+func analyzeOSVScanner(osvScannerScan *SecTestScanInfo) error {
+
+	osvScannerOutput := OSVScannerOutput{}
+	osvScannerScan.FinalOutput = osvScannerOutput
+
+	// check if there were any internal errors running osv-scanner
+	if strings.Contains(osvScannerScan.Container.COutput, "ERROR_RUNNING_OSVSCANNER") {
+		errorMsg := errors.New("internal error osv-scanner - ERROR_RUNNING_OSVSCANNER")
+		osvScannerScan.logger().Error("analyzeOSVScanner", "OSVSCANNER", 1064, errorMsg)
+		osvScannerScan.ErrorFound = errorMsg
+		osvScannerScan.prepareContainerAfterScan()
+		return errorMsg
+	}
+
+	// nil cOutput states that no pubspec.lock was found.
+	if strings.TrimSpace(osvScannerScan.Container.COutput) == "" {
+		osvScannerScan.prepareContainerAfterScan()
+		return nil
+	}
+
+	// Unmarshall rawOutput into finalOutput, that is a OSVScannerOutput struct.
+	if err := json.Unmarshal([]byte(osvScannerScan.Container.COutput), &osvScannerOutput); err != nil {
+		osvScannerScan.logger().Error("analyzeOSVScanner", "OSVSCANNER", 1063, osvScannerScan.Container.COutput, err)
+		osvScannerScan.ErrorFound = err
+		osvScannerScan.prepareContainerAfterScan()
+		return err
+	}
+	osvScannerScan.FinalOutput = osvScannerOutput
+
+	// check results and prepare all vulnerabilities found
+	osvScannerScan.prepareOSVScannerVulns()
+	osvScannerScan.prepareContainerAfterScan()
+	return nil
+}
+
+func (osvScannerScan *SecTestScanInfo) prepareOSVScannerVulns() {
+
+	huskyCIosvScannerResults := types.HuskyCISecurityTestOutput{}
+	osvScannerOutput := osvScannerScan.FinalOutput.(OSVScannerOutput)
+
+	for _, result := range osvScannerOutput.Results {
+		for _, pkg := range result.Packages {
+			for _, vulnerability := range pkg.Vulnerabilities {
+				osvScannerVuln := types.HuskyCIVulnerability{}
+				osvScannerVuln.Language = "Dart"
+				osvScannerVuln.SecurityTool = "OSV-Scanner"
+				osvScannerVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", pkg.Package.Name, pkg.Package.Version, vulnerability.ID)
+				osvScannerVuln.Details = osvScannerDetails(pkg.Package.Name, vulnerability)
+				osvScannerVuln.Type = vulnerability.ID
+				osvScannerVuln.File = repositoryFile(result.Source.Path)
+				osvScannerVuln.Code = pkg.Package.Name
+				osvScannerVuln.Version = pkg.Package.Version
+				for _, alias := range append([]string{vulnerability.ID}, vulnerability.Aliases...) {
+					if strings.HasPrefix(alias, "CVE-") {
+						osvScannerVuln.CVE = alias
+						break
+					}
+				}
+				for _, severity := range vulnerability.Severity {
+					if severity.Type == "CVSS_V3" {
+						osvScannerVuln.CVSSVector = severity.Score
+					}
+				}
+
+				// GitHub advisories rate their severity as npm does, as LOW, MODERATE, HIGH or CRITICAL.
+				osvScannerVuln.Severity = auditSeverity(strings.ToLower(vulnerability.DatabaseSpecific.Severity), CVSS{})
+				if osvScannerVuln.Severity == "" {
+					osvScannerVuln.Severity = "low"
+				}
+
+				switch osvScannerVuln.Severity {
+				case "high":
+					huskyCIosvScannerResults.HighVulns = append(huskyCIosvScannerResults.HighVulns, osvScannerVuln)
+				case "medium":
+					huskyCIosvScannerResults.MediumVulns = append(huskyCIosvScannerResults.MediumVulns, osvScannerVuln)
+				case "low":
+					huskyCIosvScannerResults.LowVulns = append(huskyCIosvScannerResults.LowVulns, osvScannerVuln)
+				}
+			}
+		}
+	}
+
+	osvScannerScan.Vulnerabilities = huskyCIosvScannerResults
+}
+
+// osvScannerDetails returns the summary and details of an advisory, followed by the version fixing it in packageName when known.
+func osvScannerDetails(packageName string, vulnerability OSVScannerVulnerability) string {
+	details := []string{}
+	for _, detail := range []string{vulnerability.Summary, vulnerability.Details} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	for _, affected := range vulnerability.Affected {
+		if affected.Package.Name != packageName {
+			continue
+		}
+		for _, affectedRange := range affected.Ranges {
+			for _, event := range affectedRange.Events {
+				if event.Fixed != "" {
+					return strings.Join(append(details, "Fixed in version "+event.Fixed+"."), "\n")
+				}
+			}
+		}
+	}
+	return strings.Join(details, "\n")
+}
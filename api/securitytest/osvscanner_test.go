@@ -0,0 +1,78 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OSVScanner", func() {
+	Describe("Parse", func() {
+		Context("When the output has advisories of several lockfiles", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/osvscanner_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map GitHub advisory severities into huskyCI severities.", func() {
+				output, err := securitytest.Parse("osvscanner", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Code).To(Equal("jwt_decode"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Code).To(Equal("http"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Code).To(Equal("flutter_downloader"))
+			})
+
+			It("Should extract the package, version and advisory of each finding.", func() {
+				output, err := securitytest.Parse("osvscanner", string(rawOutput))
+				Expect(err).To(BeNil())
+				http := output.MediumVulns[0]
+				Expect(http.SecurityTool).To(Equal("OSV-Scanner"))
+				Expect(http.Language).To(Equal("Dart"))
+				Expect(http.Title).To(Equal("Vulnerable Dependency: http 0.13.0 (GHSA-4rgh-jx4f-qfcq)"))
+				Expect(http.Type).To(Equal("GHSA-4rgh-jx4f-qfcq"))
+				Expect(http.Version).To(Equal("0.13.0"))
+				Expect(http.File).To(Equal("pubspec.lock"))
+				Expect(http.CVE).To(Equal("CVE-2020-35669"))
+				Expect(http.CVSSVector).To(Equal("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:L/A:N"))
+				Expect(http.Details).To(ContainSubstring("HTTP request splitting"))
+				Expect(http.Details).To(ContainSubstring("Fixed in version 0.13.3."))
+				Expect(output.HighVulns[0].CVE).To(BeEmpty())
+				Expect(output.LowVulns[0].File).To(Equal("packages/app/pubspec.lock"))
+			})
+		})
+
+		Context("When the repository has no pubspec.lock", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("osvscanner", "")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When osv-scanner fails to run", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("osvscanner", "ERROR_RUNNING_OSVSCANNER\nfailed to query osv.dev")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("When the output is malformed", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("osvscanner", `{"results": `)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
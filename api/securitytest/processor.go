@@ -0,0 +1,172 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"sync"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/dedup"
+	"github.com/globocom/huskyCI/api/fingerprint"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/sensitivepath"
+	"github.com/globocom/huskyCI/api/sorting"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// PostProcessing holds the aggregated results of a finished analysis handed to every ResultProcessor.
+type PostProcessing struct {
+	RID     string
+	Results *types.HuskyCIResults
+	// Failed, once set by a processor, makes the analysis fail regardless of its containers results.
+	Failed bool
+}
+
+// ResultProcessor changes the aggregated results of an analysis before they are stored, e.g. to
+// enrich its vulnerabilities. A processor must not fail on empty results.
+type ResultProcessor interface {
+	Name() string
+	Process(analysis *PostProcessing) error
+}
+
+var (
+	processorsMutex  sync.RWMutex
+	customProcessors []ResultProcessor
+)
+
+// RegisterResultProcessor adds processor to the ones run on the results of every analysis.
+// Custom processors run in registration order, after the results are deduplicated and fingerprinted.
+func RegisterResultProcessor(processor ResultProcessor) {
+	processorsMutex.Lock()
+	defer processorsMutex.Unlock()
+	customProcessors = append(customProcessors, processor)
+}
+
+// ResetResultProcessors removes every processor added by RegisterResultProcessor.
+func ResetResultProcessors() {
+	processorsMutex.Lock()
+	defer processorsMutex.Unlock()
+	customProcessors = nil
+}
+
+// ResultProcessors returns, in the order they run, the built-in processors together with the
+// registered ones. Sorting always runs last so custom processors do not need to keep any order.
+func ResultProcessors() []ResultProcessor {
+	processorsMutex.RLock()
+	defer processorsMutex.RUnlock()
+	processors := []ResultProcessor{SensitivePathsProcessor{}, VerifiedSecretsProcessor{}, DeduplicateProcessor{}, FingerprintProcessor{}}
+	processors = append(processors, customProcessors...)
+	return append(processors, SortProcessor{})
+}
+
+// SensitivePathsProcessor raises the severity of vulnerabilities found in the configured sensitive
+// paths. Their securityTests are gated again once every processor ran, so a raised vulnerability fails
+// the analysis only if it reaches the fail severity of its securityTest.
+type SensitivePathsProcessor struct{}
+
+// Name returns the name of the processor.
+func (SensitivePathsProcessor) Name() string {
+	return "sensitivepaths"
+}
+
+// Process raises the severity of the vulnerabilities of analysis found in a sensitive path.
+func (SensitivePathsProcessor) Process(analysis *PostProcessing) error {
+	sensitivepath.Escalate(analysis.Results, apiContext.APIConfiguration.SensitivePaths)
+	return nil
+}
+
+// VerifiedSecretsProcessor fails the analysis when a secret scanner found a secret verified as live,
+// whatever its severity, if FailOnVerifiedSecrets is configured. It runs before deduplicating so that
+// a verified secret also found unverified by another securityTest is not missed.
+type VerifiedSecretsProcessor struct{}
+
+// Name returns the name of the processor.
+func (VerifiedSecretsProcessor) Name() string {
+	return "verifiedsecrets"
+}
+
+// Process fails analysis if it holds a verified secret.
+func (VerifiedSecretsProcessor) Process(analysis *PostProcessing) error {
+	if apiContext.APIConfiguration.FailOnVerifiedSecrets && util.HasVerifiedSecret(analysis.Results) {
+		analysis.Failed = true
+	}
+	return nil
+}
+
+// DeduplicateProcessor merges the vulnerabilities reported more than once into a single one.
+type DeduplicateProcessor struct{}
+
+// Name returns the name of the processor.
+func (DeduplicateProcessor) Name() string {
+	return "deduplicate"
+}
+
+// Process merges the duplicated vulnerabilities of analysis.
+func (DeduplicateProcessor) Process(analysis *PostProcessing) error {
+	dedup.Results(analysis.Results)
+	return nil
+}
+
+// FingerprintProcessor sets the fingerprint of every vulnerability using the configured algorithm.
+type FingerprintProcessor struct{}
+
+// Name returns the name of the processor.
+func (FingerprintProcessor) Name() string {
+	return "fingerprint"
+}
+
+// Process sets the fingerprint of the vulnerabilities of analysis.
+func (FingerprintProcessor) Process(analysis *PostProcessing) error {
+	fingerprint.Results(analysis.Results, apiContext.APIConfiguration.FingerprintAlgorithm)
+	return nil
+}
+
+// SortProcessor sorts the vulnerabilities of the results deterministically.
+type SortProcessor struct{}
+
+// Name returns the name of the processor.
+func (SortProcessor) Name() string {
+	return "sort"
+}
+
+// Process sorts the vulnerabilities of analysis.
+func (SortProcessor) Process(analysis *PostProcessing) error {
+	sorting.Results(analysis.Results)
+	return nil
+}
+
+// postProcess runs every ResultProcessor on the results, stopping at the first one that fails.
+func (results *RunAllInfo) postProcess() (bool, error) {
+	analysis := PostProcessing{RID: results.RID, Results: &results.HuskyCIResults}
+	for _, processor := range ResultProcessors() {
+		if err := processor.Process(&analysis); err != nil {
+			log.ForAnalysis(results.RID, "").Error("postProcess", "SECURITYTEST", 1045, processor.Name(), err)
+			return false, err
+		}
+	}
+	return analysis.Failed, nil
+}
+
+// gateProcessedResults fails the passed containers whose vulnerabilities, as changed by the result processors,
+// now reach the fail severity of their securityTest under the branch policy of the analysis. The grace days
+// of the securityTest still apply. Containers are never turned from failed into passed, as deduplicating
+// moves vulnerabilities out of the securityTests that found them too.
+func (results *RunAllInfo) gateProcessedResults() {
+	for i, container := range results.Containers {
+		if container.CResult != "passed" || !resultFromVulnerabilities(container) {
+			continue
+		}
+		scanInfo := SecTestScanInfo{RID: results.RID, URL: results.url, SecurityTestName: container.SecurityTest.Name, FailSeverity: results.FailSeverity, Container: container}
+		output := util.SecurityTestOutput(&results.HuskyCIResults, container.SecurityTest.Name)
+		if output == nil || !ReachesSeverity(*output, scanInfo.failSeverity()) {
+			continue
+		}
+		scanInfo.Container.CInfo = issuesFoundInfo
+		scanInfo.Container.CResult = "failed"
+		scanInfo.applyGracePeriod()
+		results.Containers[i] = scanInfo.Container
+	}
+}
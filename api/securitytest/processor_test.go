@@ -0,0 +1,97 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// ticketProcessor attaches an internal ticket link to every high vulnerability.
+type ticketProcessor struct {
+	err error
+}
+
+func (p ticketProcessor) Name() string {
+	return "ticket"
+}
+
+func (p ticketProcessor) Process(analysis *securitytest.PostProcessing) error {
+	if p.err != nil {
+		return p.err
+	}
+	vulns := analysis.Results.GenericResults.HuskyCIGitleaksOutput.HighVulns
+	for i := range vulns {
+		if !strings.Contains(vulns[i].Details, "Ticket:") {
+			vulns[i].Details += " Ticket: https://tickets.example.com/" + analysis.RID
+		}
+	}
+	return nil
+}
+
+var _ = Describe("ResultProcessor", func() {
+
+	// The configuration read by the built-in processors is set by the Run suite.
+	Describe("Conformance", func() {
+		processors := append(securitytest.ResultProcessors(), ticketProcessor{})
+
+		It("Should give every processor a unique name.", func() {
+			names := map[string]bool{}
+			for _, processor := range processors {
+				Expect(processor.Name()).NotTo(BeEmpty())
+				Expect(names).NotTo(HaveKey(processor.Name()))
+				names[processor.Name()] = true
+			}
+		})
+
+		It("Should keep empty results empty.", func() {
+			for _, processor := range processors {
+				analysis := securitytest.PostProcessing{RID: "conformanceRID", Results: &types.HuskyCIResults{}}
+				Expect(processor.Process(&analysis)).To(Succeed(), processor.Name())
+				Expect(*analysis.Results).To(Equal(types.HuskyCIResults{}), processor.Name())
+				Expect(analysis.Failed).To(BeFalse(), processor.Name())
+			}
+		})
+
+		It("Should not drop a unique vulnerability.", func() {
+			for _, processor := range processors {
+				results := types.HuskyCIResults{}
+				results.GenericResults.HuskyCIGitleaksOutput.HighVulns = []types.HuskyCIVulnerability{
+					{SecurityTool: "GitLeaks", Severity: "High", File: "config.yml", Line: "1", Details: "AWS secret key"},
+				}
+				analysis := securitytest.PostProcessing{RID: "conformanceRID", Results: &results}
+				Expect(processor.Process(&analysis)).To(Succeed(), processor.Name())
+				Expect(results.GenericResults.HuskyCIGitleaksOutput.HighVulns).To(HaveLen(1), processor.Name())
+			}
+		})
+	})
+
+	Describe("RegisterResultProcessor", func() {
+		AfterEach(func() {
+			securitytest.ResetResultProcessors()
+		})
+
+		It("Should run custom processors after deduplicating and before sorting.", func() {
+			securitytest.RegisterResultProcessor(ticketProcessor{})
+			var names []string
+			for _, processor := range securitytest.ResultProcessors() {
+				names = append(names, processor.Name())
+			}
+			Expect(names).To(Equal([]string{"sensitivepaths", "verifiedsecrets", "deduplicate", "fingerprint", "ticket", "sort"}))
+		})
+
+		It("Should remove custom processors on reset.", func() {
+			securitytest.RegisterResultProcessor(ticketProcessor{err: errors.New("unreachable ticket system")})
+			securitytest.ResetResultProcessors()
+			Expect(securitytest.ResultProcessors()).To(HaveLen(5))
+		})
+	})
+})
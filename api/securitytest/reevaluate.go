@@ -0,0 +1,167 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"errors"
+	"strings"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// ErrInvalidSeverity is returned by Reevaluate when its policy holds a severity that is not low, medium or high.
+var ErrInvalidSeverity = errors.New("invalid severity")
+
+// Reevaluate returns the verdict of a finished analysis under policy, recomputed from its stored findings
+// without running any securityTest and without changing the analysis. Only the securityTests whose result
+// was set from the severity of their findings are re-evaluated: they fail if a finding, with its severity
+// overridden by the policy overrides or, when the policy sets none, by the configured ones, reaches the
+// policy fail severity or, when the policy sets none, the one of the branch policy the analysis ran with or
+// the current fail severity of the securityTest. Errors, warnings and grace days are kept as stored, as is a
+// failure by fail fast or by a verified secret.
+func Reevaluate(analysis types.Analysis, policy types.ReevaluationPolicy) (types.Reevaluation, error) {
+	policy, err := normalizePolicy(policy)
+	if err != nil {
+		return types.Reevaluation{}, err
+	}
+
+	reevaluation := types.Reevaluation{RID: analysis.RID, StoredResult: analysis.Result}
+	containers := make([]types.Container, 0, len(analysis.Containers))
+	for _, container := range analysis.Containers {
+		reevaluated := types.ReevaluatedSecurityTest{Name: container.SecurityTest.Name, StoredResult: container.CResult, Result: container.CResult}
+		if resultFromVulnerabilities(container) {
+			severity := policy.FailSeverity
+			if severity == "" {
+				severity = analysis.FailSeverity
+			}
+			if severity == "" {
+				if severity, err = currentFailSeverity(container.SecurityTest); err != nil {
+					return types.Reevaluation{}, err
+				}
+			}
+			output := types.HuskyCISecurityTestOutput{}
+			if stored := util.SecurityTestOutput(&analysis.HuskyCIResults, container.SecurityTest.Name); stored != nil {
+				output = overrideSeverities(*stored, toolOf(container.SecurityTest), policy.SeverityOverrides)
+			}
+			reevaluated.FailSeverity = severity
+			reevaluated.Result = "passed"
+			if ReachesSeverity(output, severity) {
+				reevaluated.Result = "failed"
+			}
+			container.CResult = reevaluated.Result
+		}
+		containers = append(containers, container)
+		reevaluation.SecurityTests = append(reevaluation.SecurityTests, reevaluated)
+	}
+
+	reevaluation.Result = containersResult(containers)
+	switch {
+	case analysis.Result == "error":
+		reevaluation.Result = "error"
+	case reevaluation.Result == "failed":
+	case analysis.FailFastAborted:
+		reevaluation.Result = "failed"
+	case apiContext.APIConfiguration.FailOnVerifiedSecrets && util.HasVerifiedSecret(&analysis.HuskyCIResults):
+		reevaluation.Result = "failed"
+	case reevaluation.Result == "passed" && analysis.Result == NoApplicableTestsResult:
+		reevaluation.Result = NoApplicableTestsResult
+	case reevaluation.Result == "passed" && analysis.Result == NoScannableContentResult:
+		reevaluation.Result = NoScannableContentResult
+	}
+	return reevaluation, nil
+}
+
+// normalizePolicy returns policy with its severities lowercased and the configured overrides in place of
+// missing ones, or ErrInvalidSeverity if one of them is not known.
+func normalizePolicy(policy types.ReevaluationPolicy) (types.ReevaluationPolicy, error) {
+	normalized := types.ReevaluationPolicy{SeverityOverrides: make(map[string]string)}
+	if policy.FailSeverity != "" {
+		if normalized.FailSeverity = knownSeverity(policy.FailSeverity); normalized.FailSeverity == "" {
+			return types.ReevaluationPolicy{}, ErrInvalidSeverity
+		}
+	}
+	overrides := policy.SeverityOverrides
+	if overrides == nil {
+		overrides = apiContext.APIConfiguration.SeverityOverrides
+	}
+	for rule, severity := range overrides {
+		known := knownSeverity(severity)
+		if known == "" {
+			return types.ReevaluationPolicy{}, ErrInvalidSeverity
+		}
+		normalized.SeverityOverrides[strings.ToLower(rule)] = known
+	}
+	return normalized, nil
+}
+
+func knownSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	switch severity {
+	case "low", "medium", "high":
+		return severity
+	}
+	return ""
+}
+
+// resultFromVulnerabilities tells whether the result of container was set from the severity of its
+// vulnerabilities, and not by an error, a warning or the grace days of its securityTest.
+func resultFromVulnerabilities(container types.Container) bool {
+	if container.CStatus != "finished" || container.GracePeriod {
+		return false
+	}
+	switch container.CInfo {
+	case noIssuesFoundInfo, issuesFoundInfo, warningsFoundInfo:
+		return true
+	}
+	return false
+}
+
+// currentFailSeverity returns the lowest severity of the vulnerabilities failing securityTest as it is
+// configured now, or as it was when it ran if it is no longer configured.
+func currentFailSeverity(securityTest types.SecurityTest) (string, error) {
+	current, err := apiContext.APIConfiguration.DBInstance.FindOneDBSecurityTest(map[string]interface{}{"name": securityTest.Name})
+	if err != nil {
+		if err != mgo.ErrNotFound && err.Error() != "No data found" {
+			log.Error("Reevaluate", "SECURITYTEST", 2024, err)
+			return "", err
+		}
+		current = securityTest
+	}
+	scanInfo := SecTestScanInfo{Container: types.Container{SecurityTest: current}}
+	return scanInfo.failSeverity(), nil
+}
+
+// overrideSeverities returns output with the vulnerabilities whose tool:rule has an override moved to the
+// list of their overridden severity. The other vulnerabilities keep their severity.
+func overrideSeverities(output types.HuskyCISecurityTestOutput, tool string, overrides map[string]string) types.HuskyCISecurityTestOutput {
+	if len(overrides) == 0 {
+		return output
+	}
+	overridden := output
+	overridden.LowVulns, overridden.MediumVulns, overridden.HighVulns = nil, nil, nil
+	lists := map[string]*[]types.HuskyCIVulnerability{
+		"low":    &overridden.LowVulns,
+		"medium": &overridden.MediumVulns,
+		"high":   &overridden.HighVulns,
+	}
+	move := func(vulns []types.HuskyCIVulnerability, severity string) {
+		for _, vuln := range vulns {
+			vulnSeverity := severity
+			if override, ok := overrides[strings.ToLower(tool)+":"+strings.ToLower(vuln.Rule)]; ok && vuln.Rule != "" {
+				vulnSeverity = override
+				vuln.Severity = strings.ToUpper(override)
+			}
+			*lists[vulnSeverity] = append(*lists[vulnSeverity], vuln)
+		}
+	}
+	move(output.LowVulns, "low")
+	move(output.MediumVulns, "medium")
+	move(output.HighVulns, "high")
+	return overridden
+}
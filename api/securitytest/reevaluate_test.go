@@ -0,0 +1,182 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"errors"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeSecurityTestDB returns the securityTests as they are configured now.
+type fakeSecurityTestDB struct {
+	db.Requests
+	securityTests map[string]types.SecurityTest
+}
+
+func (f *fakeSecurityTestDB) FindOneDBSecurityTest(mapParams map[string]interface{}) (types.SecurityTest, error) {
+	securityTest, ok := f.securityTests[mapParams["name"].(string)]
+	if !ok {
+		return types.SecurityTest{}, errors.New("No data found")
+	}
+	return securityTest, nil
+}
+
+var _ = Describe("Reevaluate", func() {
+
+	var previousConfig *apiContext.APIConfig
+	var fakeDatabase *fakeSecurityTestDB
+	BeforeEach(func() {
+		previousConfig = apiContext.APIConfiguration
+		fakeDatabase = &fakeSecurityTestDB{securityTests: map[string]types.SecurityTest{}}
+		apiContext.APIConfiguration = &apiContext.APIConfig{DBInstance: fakeDatabase, FailSeverity: "medium"}
+	})
+	AfterEach(func() {
+		apiContext.APIConfiguration = previousConfig
+	})
+
+	lowG104 := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "LOW", Rule: "G104", File: "main.go"}
+	mediumB101 := types.HuskyCIVulnerability{SecurityTool: "Bandit", Severity: "MEDIUM", Rule: "B101", File: "main.py"}
+	storedAnalysis := func() types.Analysis {
+		return types.Analysis{
+			RID:    "a1b2c3",
+			Status: "finished",
+			Result: "passed",
+			Containers: []types.Container{
+				{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "finished", CResult: "passed", CInfo: "Warnings found."},
+				{SecurityTest: types.SecurityTest{Name: "bandit", FailSeverity: "high"}, CStatus: "finished", CResult: "passed", CInfo: "Warnings found."},
+			},
+			HuskyCIResults: types.HuskyCIResults{
+				GoResults:     types.GoResults{HuskyCIGosecOutput: types.HuskyCISecurityTestOutput{LowVulns: []types.HuskyCIVulnerability{lowG104}}},
+				PythonResults: types.PythonResults{HuskyCIBanditOutput: types.HuskyCISecurityTestOutput{MediumVulns: []types.HuskyCIVulnerability{mediumB101}}},
+			},
+		}
+	}
+
+	Context("When no policy is given", func() {
+		It("Should keep the verdict of findings still under the current fail severities.", func() {
+			reevaluation, err := securitytest.Reevaluate(storedAnalysis(), types.ReevaluationPolicy{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.StoredResult).To(Equal("passed"))
+			Expect(reevaluation.Result).To(Equal("passed"))
+			Expect(reevaluation.SecurityTests).To(Equal([]types.ReevaluatedSecurityTest{
+				{Name: "gosec", StoredResult: "passed", Result: "passed", FailSeverity: "medium"},
+				{Name: "bandit", StoredResult: "passed", Result: "passed", FailSeverity: "high"},
+			}))
+		})
+		It("Should fail the securityTests whose current fail severity was tightened.", func() {
+			fakeDatabase.securityTests["bandit"] = types.SecurityTest{Name: "bandit", FailSeverity: "medium"}
+			reevaluation, err := securitytest.Reevaluate(storedAnalysis(), types.ReevaluationPolicy{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("failed"))
+			Expect(reevaluation.SecurityTests[1].Result).To(Equal("failed"))
+		})
+		It("Should keep the fail severity of the branch policy the analysis ran with.", func() {
+			analysis := storedAnalysis()
+			analysis.FailSeverity = "low"
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("failed"))
+			Expect(reevaluation.SecurityTests[1].FailSeverity).To(Equal("low"))
+		})
+		It("Should apply the configured severity overrides to the stored findings.", func() {
+			apiContext.APIConfiguration.SeverityOverrides = map[string]string{"gosec:G104": "high"}
+			reevaluation, err := securitytest.Reevaluate(storedAnalysis(), types.ReevaluationPolicy{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("failed"))
+			Expect(reevaluation.SecurityTests[0].Result).To(Equal("failed"))
+		})
+	})
+
+	Context("When a policy is given", func() {
+		It("Should apply its fail severity to every securityTest.", func() {
+			reevaluation, err := securitytest.Reevaluate(storedAnalysis(), types.ReevaluationPolicy{FailSeverity: "LOW"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("failed"))
+			Expect(reevaluation.SecurityTests[0].Result).To(Equal("failed"))
+			Expect(reevaluation.SecurityTests[1].Result).To(Equal("failed"))
+		})
+		It("Should apply its severity overrides in place of the configured ones.", func() {
+			apiContext.APIConfiguration.SeverityOverrides = map[string]string{"gosec:G104": "high"}
+			policy := types.ReevaluationPolicy{FailSeverity: "medium", SeverityOverrides: map[string]string{"bandit:B101": "low"}}
+			reevaluation, err := securitytest.Reevaluate(storedAnalysis(), policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("passed"))
+		})
+		It("Should not change the stored analysis.", func() {
+			analysis := storedAnalysis()
+			_, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "low", SeverityOverrides: map[string]string{"gosec:G104": "high"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis).To(Equal(storedAnalysis()))
+		})
+		It("Should apply its fail severity in place of the one of the branch policy of the analysis.", func() {
+			analysis := storedAnalysis()
+			analysis.FailSeverity = "none"
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "low"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("failed"))
+		})
+		It("Should return ErrInvalidSeverity when one of its severities is unknown.", func() {
+			_, err := securitytest.Reevaluate(storedAnalysis(), types.ReevaluationPolicy{FailSeverity: "critical"})
+			Expect(err).To(Equal(securitytest.ErrInvalidSeverity))
+			_, err = securitytest.Reevaluate(storedAnalysis(), types.ReevaluationPolicy{SeverityOverrides: map[string]string{"gosec:G104": "urgent"}})
+			Expect(err).To(Equal(securitytest.ErrInvalidSeverity))
+		})
+	})
+
+	Context("When the result of a securityTest was not set from its findings", func() {
+		It("Should keep the errors and warnings as stored.", func() {
+			analysis := storedAnalysis()
+			analysis.Result = "error"
+			analysis.Containers[0].CStatus, analysis.Containers[0].CResult, analysis.Containers[0].CInfo = "error running", "error", "Error found running container"
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "high"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("error"))
+			Expect(reevaluation.SecurityTests[0]).To(Equal(types.ReevaluatedSecurityTest{Name: "gosec", StoredResult: "error", Result: "error"}))
+		})
+		It("Should keep the securityTests within their grace days as passed.", func() {
+			analysis := storedAnalysis()
+			analysis.Containers[0].CInfo, analysis.Containers[0].GracePeriod = securitytest.GracePeriodNote, true
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "low"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.SecurityTests[0].Result).To(Equal("passed"))
+		})
+	})
+
+	Context("When the analysis was aborted by fail fast", func() {
+		It("Should keep it failed.", func() {
+			analysis := storedAnalysis()
+			analysis.Result, analysis.FailFastAborted = "failed", true
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "high"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal("failed"))
+		})
+	})
+
+	Context("When the analysis had no applicable tests", func() {
+		It("Should keep its result if it still passes.", func() {
+			analysis := storedAnalysis()
+			analysis.Result = securitytest.NoApplicableTestsResult
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "high"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal(securitytest.NoApplicableTestsResult))
+		})
+	})
+
+	Context("When the analysis had no scannable content", func() {
+		It("Should keep its result.", func() {
+			analysis := types.Analysis{RID: "emptyRID", Status: "finished", Result: securitytest.NoScannableContentResult, Codes: []types.Code{}}
+			reevaluation, err := securitytest.Reevaluate(analysis, types.ReevaluationPolicy{FailSeverity: "high"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reevaluation.Result).To(Equal(securitytest.NoScannableContentResult))
+		})
+	})
+})
@@ -1,23 +1,54 @@
 package securitytest
 
 import (
+	"errors"
 	"sync"
+	"time"
 
 	apiContext "github.com/globocom/huskyCI/api/context"
+	huskydocker "github.com/globocom/huskyCI/api/dockers"
 	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
 )
 
 // RunAllInfo store all scans results of an Analysis
 type RunAllInfo struct {
-	RID            string
-	Status         string
-	Containers     []types.Container
-	CommitAuthors  []string
-	Codes          []types.Code
-	FinalResult    string
-	ErrorFound     error
-	HuskyCIResults types.HuskyCIResults
+	RID                     string
+	Status                  string
+	Containers              []types.Container
+	CommitAuthors           []string
+	Codes                   []types.Code
+	FinalResult             string
+	ErrorFound              error
+	HuskyCIResults          types.HuskyCIResults
+	ApplicableLanguageTests int
+	// Components are the dependencies of the repository found by the dependency securityTests.
+	Components []types.Component
+	// CloneStrategy is how the securityTests of the analysis cloned its repository, unless their containers
+	// record a strategy of their own.
+	CloneStrategy types.CloneStrategy
+	// Completed holds, by securityTest name, the containers already finished by a previous attempt of this analysis.
+	Completed map[string]types.Container
+	// SecurityTests, when set, are the names of the only default securityTests run.
+	SecurityTests []string
+	// FailSeverity, when set by the branch policy of the analysis, is the lowest severity failing every securityTest.
+	FailSeverity string
+	// FailFastSeverity, when set, cancels the securityTests still running once one of them finds a
+	// vulnerability of this severity or higher, setting FailFastAborted.
+	FailFastSeverity string
+	FailFastAborted  bool
+	failFast         chan struct{}
+	failFastOnce     sync.Once
+	// MaxParallelSecurityTests, when set, limits how many securityTests of this analysis run at the same time,
+	// apart from the limit of containers running across every analysis.
+	MaxParallelSecurityTests int
+	slots                    *huskydocker.ContainerSemaphore
+	mutex                    sync.Mutex
+	// acceptedRisks holds, by fingerprint, the unexpired accepted risks of the repository.
+	acceptedRisks map[string]types.AcceptedRisk
+	// url is the URL of the analyzed repository, whose securityTests rollouts start their grace days.
+	url string
 }
 
 const bandit = "bandit"
@@ -29,18 +60,64 @@ const yarnaudit = "yarnaudit"
 const spotbugs = "spotbugs"
 const gitleaks = "gitleaks"
 const tfsec = "tfsec"
+const hadolint = "hadolint"
+const dependencycheck = "dependencycheck"
+const detekt = "detekt"
+const dependencycheckgradle = "dependencycheckgradle"
+const osvscanner = "osvscanner"
+const govulncheck = "govulncheck"
+const trivy = "trivy"
+
+// NoApplicableTestsResult is the final result of an analysis that passed without any language securityTest applicable to it.
+const NoApplicableTestsResult = "no applicable tests"
+
+// NoScannableContentResult is the final result of an analysis of a repository that is empty or has no file of a
+// language supported by a default securityTest, finished without running any securityTest.
+const NoScannableContentResult = "no scannable content"
+
+// FinishWithoutScannableContent finishes with NoScannableContentResult, keeping the languages detected in Codes,
+// an analysis whose repository enryScan found empty or without any file of a language supported by a default
+// securityTest. It returns whether the analysis was finished, in which case no securityTest is to run.
+func (results *RunAllInfo) FinishWithoutScannableContent(enryScan SecTestScanInfo) (bool, error) {
+	for _, code := range enryScan.Codes {
+		if len(code.Files) == 0 {
+			continue
+		}
+		codeTests, err := getAllDefaultSecurityTests("Language", code.Language)
+		if err != nil {
+			return false, err
+		}
+		if len(codeTests) > 0 {
+			return false, nil
+		}
+	}
+	results.Codes = enryScan.Codes
+	if results.Codes == nil {
+		results.Codes = []types.Code{}
+	}
+	results.Status = "finished"
+	results.FinalResult = NoScannableContentResult
+	return true, nil
+}
 
 // Start runs both generic and language security
 func (results *RunAllInfo) Start(enryScan SecTestScanInfo) error {
 
 	results.Codes = enryScan.Codes
+	results.url = enryScan.URL
+	results.acceptedRisks = unexpiredAcceptedRisks(enryScan, time.Now())
+	if results.FailFastSeverity != "" && results.failFast == nil {
+		results.failFast = make(chan struct{})
+	}
+	if results.MaxParallelSecurityTests > 0 && results.slots == nil {
+		results.slots = huskydocker.NewContainerSemaphore(results.MaxParallelSecurityTests)
+	}
 	errChan := make(chan error)
 	waitChan := make(chan struct{})
 	syncChan := make(chan struct{})
 
 	var wg sync.WaitGroup
 
-	defer close(errChan)
 	defer results.setToAnalysis()
 	wg.Add(2)
 
@@ -91,40 +168,61 @@ func (results *RunAllInfo) runGenericScans(enryScan SecTestScanInfo) error {
 
 	var wg sync.WaitGroup
 
-	defer close(errChan)
-
 	genericTests, err := getAllDefaultSecurityTests("Generic", "")
 	if err != nil {
 		return err
 	}
+	genericTests = selectSecurityTests(genericTests, results.SecurityTests)
 
 	for genericTestIndex := range genericTests {
 		wg.Add(1)
 		go func(genericTest *types.SecurityTest) {
 			defer wg.Done()
-			newGenericScan := SecTestScanInfo{}
-			if err := newGenericScan.New(enryScan.RID, enryScan.URL, enryScan.Branch, genericTest.Name); err != nil {
-				select {
-				case <-syncChan:
-					return
-				case errChan <- err:
+			newGenericScan := results.newScan(enryScan)
+			if !newGenericScan.resume(enryScan.RID, enryScan.URL, enryScan.Branch, results.Completed[genericTest.Name]) {
+				if results.failFastCancelled() {
+					results.AddContainer(results.failFastContainer(types.Container{}, *genericTest))
 					return
 				}
-			}
-			if err := newGenericScan.Start(); err != nil {
-				select {
-				case <-syncChan:
-					return
-				case errChan <- err:
+				if !results.acquireSlot() {
+					results.AddContainer(results.failFastContainer(types.Container{}, *genericTest))
 					return
 				}
+				defer results.releaseSlot()
+				if err := newGenericScan.New(enryScan.RID, enryScan.URL, enryScan.Branch, genericTest.Name); err != nil {
+					if results.failFastCancelled() {
+						results.AddContainer(results.failFastContainer(types.Container{}, *genericTest))
+						return
+					}
+					select {
+					case <-syncChan:
+						return
+					case errChan <- err:
+						return
+					}
+				}
+				if err := newGenericScan.Start(); err != nil {
+					if results.failFastCancelled() {
+						results.AddContainer(results.failFastContainer(newGenericScan.Container, *genericTest))
+						return
+					}
+					select {
+					case <-syncChan:
+						return
+					case errChan <- err:
+						return
+					}
+				}
 			}
-			results.Containers = append(results.Containers, newGenericScan.Container)
-			if genericTest.Name == "gitauthors" {
+			if tool := toolOf(*genericTest); tool == "gitauthors" {
 				results.CommitAuthors = newGenericScan.CommitAuthors.Authors
-			} else if genericTest.Name == "gitleaks" {
+			} else if tool == gitleaks || tool == trivy {
+				newGenericScan.applyAcceptedRisks(results.acceptedRisks)
+				newGenericScan.applyGracePeriod()
 				results.setVulns(newGenericScan)
+				results.checkFailFast(newGenericScan)
 			}
+			results.AddContainer(newGenericScan.Container)
 		}(&genericTests[genericTestIndex])
 	}
 
@@ -150,41 +248,70 @@ func (results *RunAllInfo) runLanguageScans(enryScan SecTestScanInfo) error {
 
 	var wg sync.WaitGroup
 
-	defer close(errChan)
-
 	languageTests := []types.SecurityTest{}
 	for _, code := range enryScan.Codes {
 		codeTests, err := getAllDefaultSecurityTests("Language", code.Language)
 		if err != nil {
 			return err
 		}
-		languageTests = append(languageTests, codeTests...)
+		for _, codeTest := range selectSecurityTests(codeTests, results.SecurityTests) {
+			if buildFileFound(codeTest, enryScan.Codes) {
+				languageTests = append(languageTests, codeTest)
+			}
+		}
 	}
+	results.ApplicableLanguageTests = len(languageTests)
 
 	for languageTestIndex := range languageTests {
 		wg.Add(1)
 		go func(languageTest *types.SecurityTest) {
 			defer wg.Done()
-			newLanguageScan := SecTestScanInfo{}
-			if err := newLanguageScan.New(enryScan.RID, enryScan.URL, enryScan.Branch, languageTest.Name); err != nil {
-				select {
-				case <-syncChan:
-					return
-				case errChan <- err:
-					return
-				}
+			if _, ok := OfflineMirror(toolOf(*languageTest), apiContext.APIConfiguration.OfflineMode, apiContext.APIConfiguration.OfflineMirrors); !ok {
+				results.AddContainer(offlineSkippedContainer(*languageTest))
+				return
 			}
-			if err := newLanguageScan.Start(); err != nil {
-				results.Containers = append(results.Containers, newLanguageScan.Container)
-				select {
-				case <-syncChan:
+			newLanguageScan := results.newScan(enryScan)
+			if !newLanguageScan.resume(enryScan.RID, enryScan.URL, enryScan.Branch, results.Completed[languageTest.Name]) {
+				if results.failFastCancelled() {
+					results.AddContainer(results.failFastContainer(types.Container{}, *languageTest))
 					return
-				case errChan <- err:
+				}
+				if !results.acquireSlot() {
+					results.AddContainer(results.failFastContainer(types.Container{}, *languageTest))
 					return
 				}
+				defer results.releaseSlot()
+				if err := newLanguageScan.New(enryScan.RID, enryScan.URL, enryScan.Branch, languageTest.Name); err != nil {
+					if results.failFastCancelled() {
+						results.AddContainer(results.failFastContainer(types.Container{}, *languageTest))
+						return
+					}
+					select {
+					case <-syncChan:
+						return
+					case errChan <- err:
+						return
+					}
+				}
+				if err := newLanguageScan.Start(); err != nil {
+					if results.failFastCancelled() {
+						results.AddContainer(results.failFastContainer(newLanguageScan.Container, *languageTest))
+						return
+					}
+					results.AddContainer(newLanguageScan.Container)
+					select {
+					case <-syncChan:
+						return
+					case errChan <- err:
+						return
+					}
+				}
 			}
-			results.Containers = append(results.Containers, newLanguageScan.Container)
+			newLanguageScan.applyAcceptedRisks(results.acceptedRisks)
+			newLanguageScan.applyGracePeriod()
 			results.setVulns(newLanguageScan)
+			results.checkFailFast(newLanguageScan)
+			results.AddContainer(newLanguageScan.Container)
 		}(&languageTests[languageTestIndex])
 	}
 
@@ -202,8 +329,92 @@ func (results *RunAllInfo) runLanguageScans(enryScan SecTestScanInfo) error {
 	}
 }
 
+// newScan returns the scan of a securityTest of the analysis, cloning the repository enryScan cloned with
+// the parameters it was requested with. It is cancelled along with the analysis on fail fast.
+func (results *RunAllInfo) newScan(enryScan SecTestScanInfo) SecTestScanInfo {
+	return SecTestScanInfo{
+		FailSeverity:     results.FailSeverity,
+		CloneURL:         enryScan.CloneURL,
+		CloneStrategy:    enryScan.CloneStrategy,
+		Source:           enryScan.Source,
+		TimeOutInSeconds: enryScan.TimeOutInSeconds,
+		SubPath:          enryScan.SubPath,
+		Commit:           enryScan.Commit,
+		SSHPrivateKey:    enryScan.SSHPrivateKey,
+		ImageReference:   enryScan.ImageReference,
+		BaseRef:          enryScan.BaseRef,
+		ChangedFiles:     enryScan.ChangedFiles,
+		ChangedFilesOnly: enryScan.ChangedFilesOnly,
+		SecurityTestArgs: enryScan.SecurityTestArgs,
+		SecurityTestEnv:  enryScan.SecurityTestEnv,
+		Cancel:           results.failFast,
+	}
+}
+
+// acquireSlot takes one of the slots of the securityTests of the analysis, waiting until one is free, when
+// they are limited. It returns false, taking no slot, if fail fast cancels the analysis first.
+func (results *RunAllInfo) acquireSlot() bool {
+	if results.slots == nil {
+		return true
+	}
+	return results.slots.AcquireUnless(results.failFast)
+}
+
+// releaseSlot frees a slot taken by acquireSlot.
+func (results *RunAllInfo) releaseSlot() {
+	if results.slots != nil {
+		results.slots.Release()
+	}
+}
+
+// AddContainer stores the container of a securityTest run and persists every container stored so far into
+// the analysis, so that it can be resumed later. Adding a container of a securityTest again replaces the
+// previous one, making it safe to be called more than once for the same securityTest.
+func (results *RunAllInfo) AddContainer(container types.Container) {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+
+	replaced := false
+	for i := range results.Containers {
+		if results.Containers[i].SecurityTest.Name == container.SecurityTest.Name {
+			results.Containers[i] = container
+			replaced = true
+		}
+	}
+	if !replaced {
+		results.Containers = append(results.Containers, container)
+	}
+
+	if results.RID == "" {
+		return
+	}
+	analysisQuery := map[string]interface{}{"RID": results.RID}
+	updateContainersQuery := map[string]interface{}{"containers": results.Containers}
+	if err := apiContext.APIConfiguration.DBInstance.UpdateOneDBAnalysisContainer(analysisQuery, updateContainersQuery); err != nil {
+		log.ForAnalysis(results.RID, "").Error("AddContainer", "SECURITYTEST", 2007, err)
+	}
+}
+
+// CompletedContainers returns, by securityTest name, the containers that finished running.
+func CompletedContainers(containers []types.Container) map[string]types.Container {
+	completed := make(map[string]types.Container)
+	for _, container := range containers {
+		if container.CStatus == "finished" {
+			completed[container.SecurityTest.Name] = container
+		}
+	}
+	return completed
+}
+
 func (results *RunAllInfo) setVulns(securityTestScan SecTestScanInfo) {
 
+	results.addComponents(securityTestScan.Components)
+
+	if securityTestScan.tool() != securityTestScan.SecurityTestName {
+		results.setInstanceVulns(securityTestScan)
+		return
+	}
+
 	for _, highVuln := range securityTestScan.Vulnerabilities.HighVulns {
 		switch securityTestScan.SecurityTestName {
 		case bandit:
@@ -224,6 +435,20 @@ func (results *RunAllInfo) setVulns(securityTestScan SecTestScanInfo) {
 			results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns = append(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns, highVuln)
 		case tfsec:
 			results.HuskyCIResults.HclResults.HuskyCITFSecOutput.HighVulns = append(results.HuskyCIResults.HclResults.HuskyCITFSecOutput.HighVulns, highVuln)
+		case hadolint:
+			results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.HighVulns = append(results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.HighVulns, highVuln)
+		case dependencycheck:
+			results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.HighVulns = append(results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.HighVulns, highVuln)
+		case detekt:
+			results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.HighVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.HighVulns, highVuln)
+		case dependencycheckgradle:
+			results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.HighVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.HighVulns, highVuln)
+		case osvscanner:
+			results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.HighVulns = append(results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.HighVulns, highVuln)
+		case govulncheck:
+			results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.HighVulns = append(results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.HighVulns, highVuln)
+		case trivy:
+			results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.HighVulns = append(results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.HighVulns, highVuln)
 		}
 	}
 
@@ -247,6 +472,20 @@ func (results *RunAllInfo) setVulns(securityTestScan SecTestScanInfo) {
 			results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.MediumVulns = append(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.MediumVulns, mediumVuln)
 		case tfsec:
 			results.HuskyCIResults.HclResults.HuskyCITFSecOutput.MediumVulns = append(results.HuskyCIResults.HclResults.HuskyCITFSecOutput.MediumVulns, mediumVuln)
+		case hadolint:
+			results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.MediumVulns = append(results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.MediumVulns, mediumVuln)
+		case dependencycheck:
+			results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.MediumVulns = append(results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.MediumVulns, mediumVuln)
+		case detekt:
+			results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.MediumVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.MediumVulns, mediumVuln)
+		case dependencycheckgradle:
+			results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.MediumVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.MediumVulns, mediumVuln)
+		case osvscanner:
+			results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.MediumVulns = append(results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.MediumVulns, mediumVuln)
+		case govulncheck:
+			results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.MediumVulns = append(results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.MediumVulns, mediumVuln)
+		case trivy:
+			results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.MediumVulns = append(results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.MediumVulns, mediumVuln)
 		}
 	}
 
@@ -270,6 +509,20 @@ func (results *RunAllInfo) setVulns(securityTestScan SecTestScanInfo) {
 			results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.LowVulns = append(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.LowVulns, lowVuln)
 		case tfsec:
 			results.HuskyCIResults.HclResults.HuskyCITFSecOutput.LowVulns = append(results.HuskyCIResults.HclResults.HuskyCITFSecOutput.LowVulns, lowVuln)
+		case hadolint:
+			results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.LowVulns = append(results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.LowVulns, lowVuln)
+		case dependencycheck:
+			results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.LowVulns = append(results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.LowVulns, lowVuln)
+		case detekt:
+			results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.LowVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.LowVulns, lowVuln)
+		case dependencycheckgradle:
+			results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.LowVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.LowVulns, lowVuln)
+		case osvscanner:
+			results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.LowVulns = append(results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.LowVulns, lowVuln)
+		case govulncheck:
+			results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.LowVulns = append(results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.LowVulns, lowVuln)
+		case trivy:
+			results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.LowVulns = append(results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.LowVulns, lowVuln)
 		}
 	}
 
@@ -293,8 +546,54 @@ func (results *RunAllInfo) setVulns(securityTestScan SecTestScanInfo) {
 			results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.NoSecVulns = append(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.NoSecVulns, noSec)
 		case tfsec:
 			results.HuskyCIResults.HclResults.HuskyCITFSecOutput.NoSecVulns = append(results.HuskyCIResults.HclResults.HuskyCITFSecOutput.NoSecVulns, noSec)
+		case hadolint:
+			results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.NoSecVulns = append(results.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.NoSecVulns, noSec)
+		case dependencycheck:
+			results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.NoSecVulns = append(results.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.NoSecVulns, noSec)
+		case detekt:
+			results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.NoSecVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.NoSecVulns, noSec)
+		case dependencycheckgradle:
+			results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.NoSecVulns = append(results.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.NoSecVulns, noSec)
+		case osvscanner:
+			results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.NoSecVulns = append(results.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.NoSecVulns, noSec)
+		case govulncheck:
+			results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.NoSecVulns = append(results.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.NoSecVulns, noSec)
+		case trivy:
+			results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.NoSecVulns = append(results.HuskyCIResults.GenericResults.HuskyCITrivyOutput.NoSecVulns, noSec)
 		}
 	}
+
+	if output := util.SecurityTestOutput(&results.HuskyCIResults, securityTestScan.SecurityTestName); output != nil {
+		output.AcceptedVulns = append(output.AcceptedVulns, securityTestScan.Vulnerabilities.AcceptedVulns...)
+	}
+}
+
+// addComponents adds the dependencies of the repository found by a dependency securityTest.
+func (results *RunAllInfo) addComponents(components []types.Component) {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+	results.Components = append(results.Components, components...)
+}
+
+// setInstanceVulns stores the vulnerabilities of a securityTest running a tool under a name of its own
+// under that name, apart from the ones of the tool and of any other securityTest running it.
+func (results *RunAllInfo) setInstanceVulns(securityTestScan SecTestScanInfo) {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+
+	if results.HuskyCIResults.Instances == nil {
+		results.HuskyCIResults.Instances = make(map[string]*types.HuskyCISecurityTestOutput)
+	}
+	output, ok := results.HuskyCIResults.Instances[securityTestScan.SecurityTestName]
+	if !ok {
+		output = &types.HuskyCISecurityTestOutput{}
+		results.HuskyCIResults.Instances[securityTestScan.SecurityTestName] = output
+	}
+	output.HighVulns = append(output.HighVulns, securityTestScan.Vulnerabilities.HighVulns...)
+	output.MediumVulns = append(output.MediumVulns, securityTestScan.Vulnerabilities.MediumVulns...)
+	output.LowVulns = append(output.LowVulns, securityTestScan.Vulnerabilities.LowVulns...)
+	output.NoSecVulns = append(output.NoSecVulns, securityTestScan.Vulnerabilities.NoSecVulns...)
+	output.AcceptedVulns = append(output.AcceptedVulns, securityTestScan.Vulnerabilities.AcceptedVulns...)
 }
 
 // SetAnalysisError sets error on an analysis that did not got to the setToAnalysis phase
@@ -315,25 +614,195 @@ func (results *RunAllInfo) setToAnalysis() {
 		return
 	}
 
-	jsWarningFlag := false
+	failedByProcessor, err := results.postProcess()
+	if err != nil {
+		results.SetAnalysisError(err)
+		return
+	}
+	results.gateProcessedResults()
+
+	results.FinalResult = containersResult(results.Containers)
+	if results.FinalResult == "failed" {
+		return
+	}
+
+	if failedByProcessor || results.FailFastAborted {
+		results.FinalResult = "failed"
+		return
+	}
 
-	for _, container := range results.Containers {
+	results.HandleNoApplicableTests(apiContext.APIConfiguration.NoTestsPolicy)
+}
+
+// containersResult returns the result of an analysis from the results of its containers: failed if any of
+// them failed, warning if any of them warned, except for a single JavaScript one, and passed otherwise.
+func containersResult(containers []types.Container) string {
+	result := "passed"
+	jsWarningFlag := false
+	for _, container := range containers {
 		switch container.CResult {
 		case "warning":
 			if container.SecurityTest.Language == "JavaScript" {
 				if jsWarningFlag {
-					results.FinalResult = "warning"
+					result = "warning"
 				} else {
 					jsWarningFlag = true
 				}
 			} else {
-				results.FinalResult = "warning"
+				result = "warning"
 			}
 		case "failed":
-			results.FinalResult = "failed"
-			return
+			return "failed"
+		}
+	}
+	return result
+}
+
+// HandleNoApplicableTests sets the final result of an analysis that had no language securityTest
+// applicable to it according to policy. With "pass", a passing analysis gets NoApplicableTestsResult.
+// With "fail", the analysis is set as an error unless the secret scanner was able to run.
+func (results *RunAllInfo) HandleNoApplicableTests(policy string) {
+	if results.ApplicableLanguageTests > 0 || results.FinalResult == "failed" {
+		return
+	}
+
+	if policy == "fail" {
+		for _, container := range results.Containers {
+			if toolOf(container.SecurityTest) == gitleaks && container.CResult != "error" {
+				return
+			}
+		}
+		results.SetAnalysisError(errors.New("no applicable securityTests found and the secret scanner did not run"))
+		return
+	}
+
+	if results.FinalResult == "passed" {
+		results.FinalResult = NoApplicableTestsResult
+	}
+}
+
+// SkipReasonNotDefault is the reason of a securityTest skipped because it is not enabled by default.
+const SkipReasonNotDefault = "securityTest is not enabled by default"
+
+// SkipReasonUnsupportedLanguage is the reason of a language skipped because no securityTest supports it.
+const SkipReasonUnsupportedLanguage = "no securityTest supports this language"
+
+// SkipReasonNoBuildFile is the reason of a securityTest skipped because it scans the built project and no
+// build file it supports was found.
+const SkipReasonNoBuildFile = "no build file supported by this securityTest found"
+
+// buildFileTools holds the tools that scan the built project, which run only when a build file they support
+// is found in the repository.
+var buildFileTools = map[string]func(file string) bool{
+	spotbugs: isJavaBuildFile,
+}
+
+// buildFileFound returns whether codes hold a build file supported by securityTest, if it needs one.
+func buildFileFound(securityTest types.SecurityTest, codes []types.Code) bool {
+	isBuildFile, ok := buildFileTools[toolOf(securityTest)]
+	if !ok {
+		return true
+	}
+	for _, code := range codes {
+		for _, file := range code.Files {
+			if isBuildFile(file) {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// SkipReasonNotSelected is the reason of a securityTest skipped because the analysis runs only other ones.
+const SkipReasonNotSelected = "securityTest is not selected for the analysis"
+
+// Plan returns the securityTests that an analysis of a repository containing codes would run, restricted to
+// the names of selected if it is not empty, without running any of them, together with the securityTests and
+// languages that would be skipped and why.
+func Plan(codes []types.Code, timeOutInSeconds int, selected []string) (types.AnalysisPlan, error) {
+	plan := types.AnalysisPlan{Codes: codes, SecurityTests: []types.PlannedSecurityTest{}}
+
+	addSecurityTests := func(securityTests []types.SecurityTest) {
+		for _, securityTest := range securityTests {
+			if !securityTest.Default {
+				plan.Skipped = append(plan.Skipped, types.SkippedSecurityTest{
+					Name:     securityTest.Name,
+					Language: securityTest.Language,
+					Reason:   SkipReasonNotDefault,
+				})
+				continue
+			}
+			if len(selected) > 0 && !util.SliceContains(selected, securityTest.Name) {
+				plan.Skipped = append(plan.Skipped, types.SkippedSecurityTest{
+					Name:     securityTest.Name,
+					Language: securityTest.Language,
+					Reason:   SkipReasonNotSelected,
+				})
+				continue
+			}
+			if !buildFileFound(securityTest, codes) {
+				plan.Skipped = append(plan.Skipped, types.SkippedSecurityTest{
+					Name:     securityTest.Name,
+					Language: securityTest.Language,
+					Reason:   SkipReasonNoBuildFile,
+				})
+				continue
+			}
+			if _, ok := OfflineMirror(toolOf(securityTest), apiContext.APIConfiguration.OfflineMode, apiContext.APIConfiguration.OfflineMirrors); !ok {
+				plan.Skipped = append(plan.Skipped, types.SkippedSecurityTest{
+					Name:     securityTest.Name,
+					Language: securityTest.Language,
+					Reason:   OfflineSkipNote(securityTest.Name),
+				})
+				continue
+			}
+			plan.SecurityTests = append(plan.SecurityTests, types.PlannedSecurityTest{
+				Name:             securityTest.Name,
+				Image:            securityTest.Image,
+				ImageTag:         securityTest.ImageTag,
+				Type:             securityTest.Type,
+				Language:         securityTest.Language,
+				TimeOutInSeconds: util.HandleTimeOut(securityTest.TimeOutInSeconds, timeOutInSeconds, apiContext.APIConfiguration.MaxTimeOutInSeconds),
+			})
+		}
+	}
+
+	genericTests, err := getAllSecurityTests(map[string]interface{}{"type": "Generic"})
+	if err != nil {
+		return plan, err
+	}
+	addSecurityTests(genericTests)
+
+	for _, code := range codes {
+		languageTests, err := getAllSecurityTests(map[string]interface{}{"language": code.Language})
+		if err != nil {
+			return plan, err
+		}
+		if len(languageTests) == 0 {
+			plan.Skipped = append(plan.Skipped, types.SkippedSecurityTest{
+				Language: code.Language,
+				Reason:   SkipReasonUnsupportedLanguage,
+			})
+			continue
+		}
+		addSecurityTests(languageTests)
+	}
+
+	return plan, nil
+}
+
+// selectSecurityTests returns the securityTests whose names are in selected, or all of them if it is empty.
+func selectSecurityTests(securityTests []types.SecurityTest, selected []string) []types.SecurityTest {
+	if len(selected) == 0 {
+		return securityTests
+	}
+	selectedTests := []types.SecurityTest{}
+	for _, securityTest := range securityTests {
+		if util.SliceContains(selected, securityTest.Name) {
+			selectedTests = append(selectedTests, securityTest)
+		}
+	}
+	return selectedTests
 }
 
 func getAllDefaultSecurityTests(typeOf, language string) ([]types.SecurityTest, error) {
@@ -341,6 +810,10 @@ func getAllDefaultSecurityTests(typeOf, language string) ([]types.SecurityTest,
 	if language != "" {
 		securityTestQuery = map[string]interface{}{"language": language, "default": true}
 	}
+	return getAllSecurityTests(securityTestQuery)
+}
+
+func getAllSecurityTests(securityTestQuery map[string]interface{}) ([]types.SecurityTest, error) {
 	securityTests, err := apiContext.APIConfiguration.DBInstance.FindAllDBSecurityTest(securityTestQuery)
 	if err != nil {
 		if err.Error() == "No data found" {
@@ -0,0 +1,1282 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/fingerprint"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/securitytest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDB struct {
+	db.Requests
+	mutex          sync.Mutex
+	securityTests  []types.SecurityTest
+	requestedTests []string
+	containers     []types.Container
+	containerSaves int
+	// waitFor makes FindOneDBSecurityTest wait until the container of this securityTest is saved.
+	waitFor string
+	// running and maxRunning count the calls to FindOneDBSecurityTest for parallel securityTests, which
+	// take a while, running at the same time.
+	running    int
+	maxRunning int
+	// rollouts holds, by repository URL and securityTest name, when each securityTest first ran.
+	rollouts map[string]time.Time
+	// acceptedRisks are returned for every repository.
+	acceptedRisks []types.AcceptedRisk
+}
+
+func (f *fakeDB) reset(securityTests []types.SecurityTest) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.securityTests = securityTests
+	f.requestedTests = nil
+	f.containers = nil
+	f.containerSaves = 0
+	f.waitFor = ""
+	f.running = 0
+	f.maxRunning = 0
+	f.rollouts = map[string]time.Time{}
+	f.acceptedRisks = nil
+}
+
+func (f *fakeDB) maxParallel() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.maxRunning
+}
+
+func (f *fakeDB) waitForSaved(securityTestName string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.waitFor = securityTestName
+}
+
+func (f *fakeDB) hasSaved(securityTestName string) bool {
+	containers, _ := f.saved()
+	_, ok := securitytest.CompletedContainers(containers)[securityTestName]
+	return ok
+}
+
+func (f *fakeDB) requested() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.requestedTests
+}
+
+func (f *fakeDB) saved() ([]types.Container, int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.containers, f.containerSaves
+}
+
+func (f *fakeDB) FindAllDBSecurityTest(mapParams map[string]interface{}) ([]types.SecurityTest, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	securityTests := []types.SecurityTest{}
+	for _, securityTest := range f.securityTests {
+		if isDefault, ok := mapParams["default"]; ok && isDefault != securityTest.Default {
+			continue
+		}
+		if securityTest.Type == mapParams["type"] || securityTest.Language == mapParams["language"] {
+			securityTests = append(securityTests, securityTest)
+		}
+	}
+	return securityTests, nil
+}
+
+func (f *fakeDB) FindOneDBSecurityTest(mapParams map[string]interface{}) (types.SecurityTest, error) {
+	f.mutex.Lock()
+	waitFor := f.waitFor
+	f.mutex.Unlock()
+	for deadline := time.Now().Add(time.Second); waitFor != "" && !f.hasSaved(waitFor) && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if strings.HasPrefix(mapParams["name"].(string), "parallel") {
+		f.mutex.Lock()
+		f.running++
+		if f.running > f.maxRunning {
+			f.maxRunning = f.running
+		}
+		f.mutex.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		f.mutex.Lock()
+		f.running--
+		f.mutex.Unlock()
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requestedTests = append(f.requestedTests, mapParams["name"].(string))
+	return types.SecurityTest{}, errors.New("securityTest can not run in tests")
+}
+
+func (f *fakeDB) startRollout(URL, securityTestName string, startedAt time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.rollouts[URL+" "+securityTestName] = startedAt
+}
+
+func (f *fakeDB) rollout(URL, securityTestName string) (time.Time, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	startedAt, ok := f.rollouts[URL+" "+securityTestName]
+	return startedAt, ok
+}
+
+func (f *fakeDB) FindOneDBSecurityTestRollout(mapParams map[string]interface{}) (types.SecurityTestRollout, error) {
+	startedAt, ok := f.rollout(mapParams["repositoryURL"].(string), mapParams["securityTestName"].(string))
+	if !ok {
+		return types.SecurityTestRollout{}, errors.New("No data found")
+	}
+	return types.SecurityTestRollout{StartedAt: startedAt}, nil
+}
+
+func (f *fakeDB) InsertDBSecurityTestRollout(rollout types.SecurityTestRollout) error {
+	f.startRollout(rollout.URL, rollout.SecurityTestName, rollout.StartedAt)
+	return nil
+}
+
+func (f *fakeDB) FindAllDBAcceptedRisk(mapParams map[string]interface{}) ([]types.AcceptedRisk, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.acceptedRisks) == 0 {
+		return nil, errors.New("No data found")
+	}
+	return f.acceptedRisks, nil
+}
+
+func (f *fakeDB) UpdateOneDBAnalysisContainer(mapParams, updateQuery map[string]interface{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.containers = append([]types.Container{}, updateQuery["containers"].([]types.Container)...)
+	f.containerSaves++
+	return nil
+}
+
+var _ = Describe("Run", func() {
+
+	// securityTests goroutines may outlive a failed Start, so the configuration is set only once.
+	fakeDatabase := &fakeDB{}
+	apiContext.APIConfiguration = &apiContext.APIConfig{DBInstance: fakeDatabase}
+
+	Describe("HandleNoApplicableTests", func() {
+
+		gitleaksContainer := func(cResult string) types.Container {
+			return types.Container{
+				SecurityTest: types.SecurityTest{Name: "gitleaks"},
+				CResult:      cResult,
+			}
+		}
+
+		Context("When policy is pass and the repository has no language", func() {
+			It("Should pass with a no applicable tests result.", func() {
+				results := securitytest.RunAllInfo{
+					FinalResult: "passed",
+					Containers:  []types.Container{gitleaksContainer("passed")},
+				}
+				results.HandleNoApplicableTests("pass")
+				Expect(results.FinalResult).To(Equal(securitytest.NoApplicableTestsResult))
+				Expect(results.ErrorFound).To(BeNil())
+			})
+			It("Should keep a failed result found by the secret scanner.", func() {
+				results := securitytest.RunAllInfo{
+					FinalResult: "failed",
+					Containers:  []types.Container{gitleaksContainer("failed")},
+				}
+				results.HandleNoApplicableTests("pass")
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+		})
+
+		Context("When policy is fail and the repository has no language", func() {
+			It("Should keep the result if the secret scanner ran.", func() {
+				results := securitytest.RunAllInfo{
+					FinalResult: "passed",
+					Containers:  []types.Container{gitleaksContainer("passed")},
+				}
+				results.HandleNoApplicableTests("fail")
+				Expect(results.FinalResult).To(Equal("passed"))
+				Expect(results.ErrorFound).To(BeNil())
+			})
+			It("Should set an error if the secret scanner did not run.", func() {
+				results := securitytest.RunAllInfo{
+					FinalResult: "passed",
+				}
+				results.HandleNoApplicableTests("fail")
+				Expect(results.Status).To(Equal("error running"))
+				Expect(results.FinalResult).To(Equal("error"))
+				Expect(results.ErrorFound).To(HaveOccurred())
+			})
+			It("Should set an error if the secret scanner returned an error.", func() {
+				results := securitytest.RunAllInfo{
+					FinalResult: "passed",
+					Containers:  []types.Container{gitleaksContainer("error")},
+				}
+				results.HandleNoApplicableTests("fail")
+				Expect(results.FinalResult).To(Equal("error"))
+			})
+		})
+
+		Context("When the repository has applicable language tests", func() {
+			It("Should not change the result.", func() {
+				results := securitytest.RunAllInfo{
+					FinalResult:             "passed",
+					ApplicableLanguageTests: 1,
+				}
+				results.HandleNoApplicableTests("fail")
+				Expect(results.FinalResult).To(Equal("passed"))
+				Expect(results.ErrorFound).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Resuming an analysis", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		gitleaksOutput, _ := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true}
+		safetyTest := types.SecurityTest{Name: "safety", Type: "Language", Language: "Python", Default: true}
+
+		finishedContainer := func(securityTest types.SecurityTest, cOutput string) types.Container {
+			return types.Container{SecurityTest: securityTest, CStatus: "finished", COutput: cOutput}
+		}
+		enryScan := securitytest.SecTestScanInfo{
+			RID:   "resumedRID",
+			Codes: []types.Code{{Language: "Python", Files: []string{"main.py"}}},
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest, banditTest, safetyTest})
+		})
+
+		Context("When every securityTest was already completed", func() {
+			It("Should restore their results without running any of them.", func() {
+				results := securitytest.RunAllInfo{
+					RID: "resumedRID",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						finishedContainer(gitleaksTest, string(gitleaksOutput)),
+						finishedContainer(gitauthorsTest, `{"authors": ["dev@example.com"]}`),
+						finishedContainer(banditTest, `{"results": []}`),
+						finishedContainer(safetyTest, `{"issues": []}`),
+					}),
+				}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(fakeDatabase.requested()).To(BeEmpty())
+				Expect(results.Containers).To(HaveLen(4))
+				Expect(results.CommitAuthors).To(Equal([]string{"dev@example.com"}))
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns).To(HaveLen(1))
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.MediumVulns).To(HaveLen(1))
+				Expect(results.FinalResult).To(Equal("failed"))
+				savedContainers, _ := fakeDatabase.saved()
+				Expect(savedContainers).To(HaveLen(4))
+			})
+		})
+
+		Context("When a completed securityTest has execution metadata", func() {
+			It("Should keep the metadata of its previous run.", func() {
+				startedAt := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+				completedGitleaks := finishedContainer(gitleaksTest, string(gitleaksOutput))
+				completedGitleaks.StartedAt = startedAt
+				completedGitleaks.FinishedAt = startedAt.Add(90 * time.Second)
+				completedGitleaks.DurationInSeconds = 90
+				completedGitleaks.Image = "huskyci/gitleaks@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+				completedGitleaks.ExitCode = 0
+				results := securitytest.RunAllInfo{
+					RID: "resumedRID",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						completedGitleaks,
+						finishedContainer(gitauthorsTest, `{"authors": []}`),
+						finishedContainer(banditTest, `{"results": []}`),
+						finishedContainer(safetyTest, `{"issues": []}`),
+					}),
+				}
+				Expect(results.Start(enryScan)).To(Succeed())
+				restored := securitytest.CompletedContainers(results.Containers)["gitleaks"]
+				Expect(restored.StartedAt).To(Equal(completedGitleaks.StartedAt))
+				Expect(restored.FinishedAt).To(Equal(completedGitleaks.FinishedAt))
+				Expect(restored.DurationInSeconds).To(Equal(float64(90)))
+				Expect(restored.Image).To(Equal(completedGitleaks.Image))
+				Expect(restored.ExitCode).To(Equal(0))
+				Expect(restored.Error).To(BeEmpty())
+			})
+		})
+
+		Context("When some securityTests were already completed", func() {
+			It("Should run only the unfinished ones.", func() {
+				results := securitytest.RunAllInfo{
+					RID: "resumedRID",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						finishedContainer(gitleaksTest, string(gitleaksOutput)),
+						finishedContainer(gitauthorsTest, `{"authors": []}`),
+						finishedContainer(banditTest, `{"results": []}`),
+						{SecurityTest: safetyTest, CStatus: "running"},
+					}),
+				}
+				Expect(results.Start(enryScan)).NotTo(Succeed())
+				Expect(fakeDatabase.requested()).To(Equal([]string{"safety"}))
+			})
+		})
+
+		Context("When the output of a completed securityTest can not be parsed", func() {
+			It("Should run it again.", func() {
+				results := securitytest.RunAllInfo{
+					Completed: securitytest.CompletedContainers([]types.Container{
+						finishedContainer(gitleaksTest, string(gitleaksOutput)),
+						finishedContainer(gitauthorsTest, `{"authors": []}`),
+						finishedContainer(banditTest, "Container Output is too large."),
+						finishedContainer(safetyTest, `{"issues": []}`),
+					}),
+				}
+				Expect(results.Start(enryScan)).NotTo(Succeed())
+				Expect(fakeDatabase.requested()).To(Equal([]string{"bandit"}))
+			})
+		})
+	})
+
+	Describe("securityTest instances", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true}
+		banditTestsTest := types.SecurityTest{Name: "bandittests", Tool: "bandit", Type: "Language", Language: "Python", Default: true}
+		strictOutput := `{"results": [{"filename": "app/main.py", "issue_severity": "HIGH", "issue_confidence": "HIGH", "issue_text": "subprocess call with shell=True identified.", "line_number": 10, "test_id": "B602"}]}`
+		lenientOutput := `{"results": [{"filename": "tests/test_main.py", "issue_severity": "LOW", "issue_confidence": "HIGH", "issue_text": "Use of assert detected.", "line_number": 3, "test_id": "B101"}]}`
+
+		finishedContainer := func(securityTest types.SecurityTest, cOutput string) types.Container {
+			return types.Container{SecurityTest: securityTest, CStatus: "finished", COutput: cOutput}
+		}
+		enryScan := securitytest.SecTestScanInfo{
+			RID:   "instancesRID",
+			Codes: []types.Code{{Language: "Python", Files: []string{"app/main.py", "tests/test_main.py"}}},
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, banditTest, banditTestsTest})
+		})
+
+		Context("When two securityTests run the same tool", func() {
+			It("Should store the results of each one apart.", func() {
+				results := securitytest.RunAllInfo{
+					RID: "instancesRID",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						finishedContainer(gitleaksTest, ""),
+						finishedContainer(banditTest, strictOutput),
+						finishedContainer(banditTestsTest, lenientOutput),
+					}),
+				}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.Containers).To(HaveLen(3))
+				banditOutput := results.HuskyCIResults.PythonResults.HuskyCIBanditOutput
+				Expect(banditOutput.HighVulns).To(HaveLen(1))
+				Expect(banditOutput.HighVulns[0].File).To(Equal("app/main.py"))
+				Expect(banditOutput.LowVulns).To(BeEmpty())
+				Expect(results.HuskyCIResults.Instances).To(HaveLen(1))
+				instanceOutput := results.HuskyCIResults.Instances["bandittests"]
+				Expect(instanceOutput.HighVulns).To(BeEmpty())
+				Expect(instanceOutput.LowVulns).To(HaveLen(1))
+				Expect(instanceOutput.LowVulns[0].File).To(Equal("tests/test_main.py"))
+				Expect(instanceOutput.LowVulns[0].SecurityTool).To(Equal("Bandit"))
+			})
+		})
+
+		Context("When only one of the securityTests running the same tool was completed", func() {
+			It("Should run only the other one.", func() {
+				results := securitytest.RunAllInfo{
+					RID: "instancesRID",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						finishedContainer(gitleaksTest, ""),
+						finishedContainer(banditTest, strictOutput),
+					}),
+				}
+				Expect(results.Start(enryScan)).NotTo(Succeed())
+				Expect(fakeDatabase.requested()).To(Equal([]string{"bandittests"}))
+			})
+		})
+	})
+
+	Describe("Post-processing results", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		gitleaksOutput, _ := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		enryScan := securitytest.SecTestScanInfo{RID: "processedRID"}
+		completed := securitytest.CompletedContainers([]types.Container{
+			{SecurityTest: gitleaksTest, CStatus: "finished", COutput: string(gitleaksOutput)},
+			{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+		})
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest})
+		})
+		AfterEach(func() {
+			securitytest.ResetResultProcessors()
+		})
+
+		Context("When a custom processor is registered", func() {
+			It("Should apply it to the results before they are stored.", func() {
+				securitytest.RegisterResultProcessor(ticketProcessor{})
+				results := securitytest.RunAllInfo{RID: "processedRID", Completed: completed}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.FinalResult).To(Equal("failed"))
+				highVulns := results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns
+				Expect(highVulns).To(HaveLen(1))
+				Expect(highVulns[0].Details).To(HaveSuffix(" Ticket: https://tickets.example.com/processedRID"))
+			})
+		})
+
+		Context("When a custom processor fails", func() {
+			It("Should set an error on the analysis.", func() {
+				securitytest.RegisterResultProcessor(ticketProcessor{err: errors.New("unreachable ticket system")})
+				results := securitytest.RunAllInfo{RID: "processedRID", Completed: completed}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.Status).To(Equal("error running"))
+				Expect(results.FinalResult).To(Equal("error"))
+				Expect(results.ErrorFound).To(MatchError("unreachable ticket system"))
+			})
+		})
+
+		Context("When failing on verified secrets is configured", func() {
+			lowSecret := func(verified bool) map[string]types.Container {
+				issue := fmt.Sprintf(`[{"line": "token = \"abc\"", "rule": "Generic Credential", "file": "app/settings.py", "verified": %t}]`, verified)
+				return securitytest.CompletedContainers([]types.Container{
+					{SecurityTest: gitleaksTest, CStatus: "finished", COutput: issue},
+					{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+				})
+			}
+			BeforeEach(func() {
+				apiContext.APIConfiguration.FailOnVerifiedSecrets = true
+			})
+			AfterEach(func() {
+				apiContext.APIConfiguration.FailOnVerifiedSecrets = false
+			})
+
+			It("Should fail the analysis for a verified secret below the severity threshold.", func() {
+				results := securitytest.RunAllInfo{RID: "processedRID", Completed: lowSecret(true)}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.LowVulns).To(HaveLen(1))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+
+			It("Should keep the severity threshold for an unverified secret.", func() {
+				results := securitytest.RunAllInfo{RID: "processedRID", Completed: lowSecret(false)}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.LowVulns).To(HaveLen(1))
+				Expect(results.FinalResult).NotTo(Equal("failed"))
+			})
+		})
+	})
+
+	Describe("Fail severity", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true, FailSeverity: "high"}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true}
+		enryScan := securitytest.SecTestScanInfo{
+			RID:   "failSeverityRID",
+			Codes: []types.Code{{Language: "Python", Files: []string{"main.py"}}},
+		}
+		mediumSecret := `[{"line": "aws_secret_access_key = abc", "rule": "AWS Secret Key", "file": "config/settings.py"}]`
+		banditIssue := func(severity string) string {
+			return fmt.Sprintf(`{"results": [{"code": "eval(x)", "filename": "main.py", "issue_confidence": "HIGH", "issue_severity": "%s", "issue_text": "Use of eval", "line_number": 1, "test_id": "B307", "test_name": "eval"}]}`, severity)
+		}
+		run := func(banditOutput string) *securitytest.RunAllInfo {
+			results := &securitytest.RunAllInfo{
+				RID: "failSeverityRID",
+				Completed: securitytest.CompletedContainers([]types.Container{
+					{SecurityTest: gitleaksTest, CStatus: "finished", COutput: mediumSecret},
+					{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+					{SecurityTest: banditTest, CStatus: "finished", COutput: banditOutput},
+				}),
+			}
+			Expect(results.Start(enryScan)).To(Succeed())
+			return results
+		}
+		cResults := func(results *securitytest.RunAllInfo) map[string]string {
+			byName := map[string]string{}
+			for _, container := range results.Containers {
+				byName[container.SecurityTest.Name] = container.CResult
+			}
+			return byName
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest, banditTest})
+		})
+		AfterEach(func() {
+			apiContext.APIConfiguration.FailSeverity = ""
+		})
+
+		Context("When no securityTest reaches its own threshold", func() {
+			It("Should pass the analysis.", func() {
+				results := run(banditIssue("LOW"))
+				Expect(cResults(results)).To(HaveKeyWithValue("gitleaks", "passed"))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "passed"))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When a securityTest without its own threshold reaches the global one", func() {
+			It("Should fail the analysis while the others keep their own threshold.", func() {
+				results := run(banditIssue("MEDIUM"))
+				Expect(cResults(results)).To(HaveKeyWithValue("gitleaks", "passed"))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+		})
+
+		Context("When the global threshold is raised", func() {
+			It("Should only apply it to the securityTests without their own threshold.", func() {
+				apiContext.APIConfiguration.FailSeverity = "high"
+				results := run(banditIssue("MEDIUM"))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "passed"))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When the global threshold is lowered", func() {
+			It("Should not lower the threshold of the securityTests setting their own one.", func() {
+				apiContext.APIConfiguration.FailSeverity = "low"
+				results := run(banditIssue("LOW"))
+				Expect(cResults(results)).To(HaveKeyWithValue("gitleaks", "passed"))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+		})
+
+		Context("When config overrides the severity of a rule", func() {
+			AfterEach(func() {
+				apiContext.APIConfiguration.SeverityOverrides = nil
+			})
+
+			It("Should rate its vulnerabilities with the override before deciding the result.", func() {
+				apiContext.APIConfiguration.SeverityOverrides = map[string]string{"bandit:B307": "low"}
+				results := run(banditIssue("HIGH"))
+				banditOutput := results.HuskyCIResults.PythonResults.HuskyCIBanditOutput
+				Expect(banditOutput.HighVulns).To(BeEmpty())
+				Expect(banditOutput.LowVulns).To(HaveLen(1))
+				Expect(banditOutput.LowVulns[0].Severity).To(Equal("LOW"))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "passed"))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+
+			It("Should keep the severity of the other rules.", func() {
+				apiContext.APIConfiguration.SeverityOverrides = map[string]string{"bandit:B101": "low", "gosec:B307": "low"}
+				results := run(banditIssue("HIGH"))
+				Expect(results.HuskyCIResults.PythonResults.HuskyCIBanditOutput.HighVulns).To(HaveLen(1))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+
+			It("Should be able to raise a severity up to the threshold.", func() {
+				apiContext.APIConfiguration.SeverityOverrides = map[string]string{"gitleaks:AWS Secret Key": "high"}
+				results := run(banditIssue("LOW"))
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns).To(HaveLen(1))
+				Expect(cResults(results)).To(HaveKeyWithValue("gitleaks", "failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+		})
+
+		Context("When a vulnerability is raised for being in a sensitive path", func() {
+			BeforeEach(func() {
+				apiContext.APIConfiguration.SensitivePaths = []string{"main.py"}
+			})
+			AfterEach(func() {
+				apiContext.APIConfiguration.SensitivePaths = nil
+			})
+
+			It("Should fail its securityTest once it reaches the threshold.", func() {
+				results := run(banditIssue("LOW"))
+				Expect(results.HuskyCIResults.PythonResults.HuskyCIBanditOutput.MediumVulns).To(HaveLen(1))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+
+			It("Should not fail the analysis while it is below the threshold.", func() {
+				apiContext.APIConfiguration.FailSeverity = "high"
+				results := run(banditIssue("LOW"))
+				Expect(results.HuskyCIResults.PythonResults.HuskyCIBanditOutput.MediumVulns).To(HaveLen(1))
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "passed"))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+
+			It("Should apply the threshold of the branch policy.", func() {
+				results := &securitytest.RunAllInfo{
+					RID:          "failSeverityRID",
+					FailSeverity: "high",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						{SecurityTest: gitleaksTest, CStatus: "finished", COutput: mediumSecret},
+						{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+						{SecurityTest: banditTest, CStatus: "finished", COutput: banditIssue("LOW")},
+					}),
+				}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(cResults(results)).To(HaveKeyWithValue("bandit", "passed"))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+	})
+
+	Describe("Grace period", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		repositoryURL := "https://github.com/globocom/huskyCI.git"
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true, GraceDays: 30}
+		banditIssue := `{"results": [{"code": "eval(x)", "filename": "main.py", "issue_confidence": "HIGH", "issue_severity": "HIGH", "issue_text": "Use of eval", "line_number": 1, "test_id": "B307", "test_name": "eval"}]}`
+		run := func(banditTest types.SecurityTest, banditOutput, failFastSeverity string) (*securitytest.RunAllInfo, types.Container) {
+			enryScan := securitytest.SecTestScanInfo{
+				RID:   "graceRID",
+				URL:   repositoryURL,
+				Codes: []types.Code{{Language: "Python", Files: []string{"main.py"}}},
+			}
+			results := &securitytest.RunAllInfo{
+				RID: "graceRID",
+				Completed: securitytest.CompletedContainers([]types.Container{
+					{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+					{SecurityTest: banditTest, CStatus: "finished", COutput: banditOutput},
+				}),
+				FailFastSeverity: failFastSeverity,
+			}
+			Expect(results.Start(enryScan)).To(Succeed())
+			for _, container := range results.Containers {
+				if container.SecurityTest.Name == banditTest.Name {
+					return results, container
+				}
+			}
+			Fail("bandit container not found")
+			return nil, types.Container{}
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitauthorsTest, banditTest})
+		})
+
+		Context("When the securityTest runs for the repository for the first time", func() {
+			It("Should start its grace days, not failing the analysis for its findings.", func() {
+				results, container := run(banditTest, banditIssue, "")
+				Expect(container.CResult).To(Equal("passed"))
+				Expect(container.CInfo).To(Equal(securitytest.GracePeriodNote))
+				Expect(container.GracePeriod).To(BeTrue())
+				Expect(results.HuskyCIResults.PythonResults.HuskyCIBanditOutput.HighVulns).To(HaveLen(1))
+				Expect(results.FinalResult).To(Equal("passed"))
+				startedAt, ok := fakeDatabase.rollout(repositoryURL, "bandit")
+				Expect(ok).To(BeTrue())
+				Expect(startedAt).To(BeTemporally("~", time.Now(), time.Minute))
+			})
+
+			It("Should start its grace days even without findings.", func() {
+				_, container := run(banditTest, `{"results": []}`, "")
+				Expect(container.CResult).To(Equal("passed"))
+				Expect(container.GracePeriod).To(BeFalse())
+				_, ok := fakeDatabase.rollout(repositoryURL, "bandit")
+				Expect(ok).To(BeTrue())
+			})
+		})
+
+		Context("When the securityTest is still within its grace days for the repository", func() {
+			It("Should keep the analysis from failing, even with fail fast.", func() {
+				fakeDatabase.startRollout(repositoryURL, "bandit", time.Now().AddDate(0, 0, -29))
+				results, container := run(banditTest, banditIssue, "high")
+				Expect(container.CResult).To(Equal("passed"))
+				Expect(container.GracePeriod).To(BeTrue())
+				Expect(results.FailFastAborted).To(BeFalse())
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When a vulnerability raised for being in a sensitive path reaches the threshold", func() {
+			AfterEach(func() {
+				apiContext.APIConfiguration.SensitivePaths = nil
+			})
+
+			It("Should keep the analysis from failing within the grace days.", func() {
+				apiContext.APIConfiguration.SensitivePaths = []string{"main.py"}
+				fakeDatabase.startRollout(repositoryURL, "bandit", time.Now().AddDate(0, 0, -29))
+				lowIssue := `{"results": [{"code": "assert x", "filename": "main.py", "issue_confidence": "HIGH", "issue_severity": "LOW", "issue_text": "Use of assert detected.", "line_number": 1, "test_id": "B101", "test_name": "assert_used"}]}`
+				results, container := run(banditTest, lowIssue, "")
+				Expect(results.HuskyCIResults.PythonResults.HuskyCIBanditOutput.MediumVulns).To(HaveLen(1))
+				Expect(container.CResult).To(Equal("passed"))
+				Expect(container.GracePeriod).To(BeTrue())
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When the grace days of the securityTest for the repository are over", func() {
+			It("Should fail the analysis for its findings.", func() {
+				fakeDatabase.startRollout(repositoryURL, "bandit", time.Now().AddDate(0, 0, -31))
+				results, container := run(banditTest, banditIssue, "")
+				Expect(container.CResult).To(Equal("failed"))
+				Expect(container.GracePeriod).To(BeFalse())
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+
+			It("Should count them for each repository on its own.", func() {
+				fakeDatabase.startRollout("https://github.com/globocom/secDevLabs.git", "bandit", time.Now().AddDate(0, 0, -31))
+				_, container := run(banditTest, banditIssue, "")
+				Expect(container.CResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When the securityTest has no grace days", func() {
+			It("Should fail the analysis right away without recording its first run.", func() {
+				enforcedTest := banditTest
+				enforcedTest.GraceDays = 0
+				fakeDatabase.reset([]types.SecurityTest{gitauthorsTest, enforcedTest})
+				results, container := run(enforcedTest, banditIssue, "")
+				Expect(container.CResult).To(Equal("failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+				_, ok := fakeDatabase.rollout(repositoryURL, "bandit")
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Accepted risks", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		repositoryURL := "https://github.com/globocom/huskyCI.git"
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true}
+		banditIssue := `{"results": [{"code": "eval(x)", "filename": "main.py", "issue_confidence": "HIGH", "issue_severity": "HIGH", "issue_text": "Use of eval", "line_number": 1, "test_id": "B307", "test_name": "eval"}]}`
+		run := func() (*securitytest.RunAllInfo, types.Container) {
+			results := &securitytest.RunAllInfo{
+				RID: "acceptedRID",
+				Completed: securitytest.CompletedContainers([]types.Container{
+					{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+					{SecurityTest: banditTest, CStatus: "finished", COutput: banditIssue},
+				}),
+			}
+			Expect(results.Start(securitytest.SecTestScanInfo{
+				RID:   "acceptedRID",
+				URL:   repositoryURL,
+				Codes: []types.Code{{Language: "Python", Files: []string{"main.py"}}},
+			})).To(Succeed())
+			for _, container := range results.Containers {
+				if container.SecurityTest.Name == banditTest.Name {
+					return results, container
+				}
+			}
+			Fail("bandit container not found")
+			return nil, types.Container{}
+		}
+		accept := func(expiresAt *time.Time) string {
+			results, _ := run()
+			highVulns := results.HuskyCIResults.PythonResults.HuskyCIBanditOutput.HighVulns
+			Expect(highVulns).To(HaveLen(1))
+			vulnFingerprint := fingerprint.Vulnerability(highVulns[0], apiContext.APIConfiguration.FingerprintAlgorithm)
+			fakeDatabase.reset([]types.SecurityTest{gitauthorsTest, banditTest})
+			fakeDatabase.acceptedRisks = []types.AcceptedRisk{{RepositoryURL: repositoryURL, Fingerprint: vulnFingerprint, AcceptedBy: "husky", ExpiresAt: expiresAt}}
+			return vulnFingerprint
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitauthorsTest, banditTest})
+		})
+
+		Context("When the risk of a vulnerability was accepted", func() {
+			It("Should not fail the analysis for it.", func() {
+				fingerprint := accept(nil)
+				results, container := run()
+				banditOutput := results.HuskyCIResults.PythonResults.HuskyCIBanditOutput
+				Expect(banditOutput.HighVulns).To(BeEmpty())
+				Expect(banditOutput.AcceptedVulns).To(HaveLen(1))
+				Expect(banditOutput.AcceptedVulns[0].Fingerprint).To(Equal(fingerprint))
+				Expect(banditOutput.AcceptedVulns[0].AcceptedRisk.AcceptedBy).To(Equal("husky"))
+				Expect(container.CResult).To(Equal("passed"))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When the accepted risk of a vulnerability expired", func() {
+			It("Should fail the analysis for it again.", func() {
+				expiresAt := time.Now().Add(-time.Hour)
+				accept(&expiresAt)
+				results, container := run()
+				banditOutput := results.HuskyCIResults.PythonResults.HuskyCIBanditOutput
+				Expect(banditOutput.HighVulns).To(HaveLen(1))
+				Expect(banditOutput.AcceptedVulns).To(BeEmpty())
+				Expect(container.CResult).To(Equal("failed"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+		})
+	})
+
+	Describe("SpotBugs build", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		spotbugsTest := types.SecurityTest{Name: "spotbugs", Type: "Language", Language: "Java", Default: true}
+		run := func(codes []types.Code, spotbugsOutput string) *securitytest.RunAllInfo {
+			results := &securitytest.RunAllInfo{
+				RID: "spotbugsRID",
+				Completed: securitytest.CompletedContainers([]types.Container{
+					{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+					{SecurityTest: spotbugsTest, CStatus: "finished", COutput: spotbugsOutput},
+				}),
+			}
+			Expect(results.Start(securitytest.SecTestScanInfo{RID: "spotbugsRID", Codes: codes})).To(Succeed())
+			return results
+		}
+		spotbugsContainer := func(results *securitytest.RunAllInfo) (types.Container, bool) {
+			for _, container := range results.Containers {
+				if container.SecurityTest.Name == "spotbugs" {
+					return container, true
+				}
+			}
+			return types.Container{}, false
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitauthorsTest, spotbugsTest})
+		})
+
+		Context("When the Java project has a build file", func() {
+			It("Should run SpotBugs.", func() {
+				results := run([]types.Code{
+					{Language: "Java", Files: []string{"src/main/java/App.java"}},
+					{Language: "Maven POM", Files: []string{"pom.xml"}},
+				}, "")
+				container, ok := spotbugsContainer(results)
+				Expect(ok).To(BeTrue())
+				Expect(container.CResult).To(Equal("passed"))
+				Expect(results.ApplicableLanguageTests).To(Equal(1))
+			})
+		})
+
+		Context("When the Java project has no build file at its root", func() {
+			It("Should not run SpotBugs.", func() {
+				results := run([]types.Code{
+					{Language: "Java", Files: []string{"src/main/java/App.java"}},
+					{Language: "Maven POM", Files: []string{"examples/pom.xml"}},
+				}, "")
+				_, ok := spotbugsContainer(results)
+				Expect(ok).To(BeFalse())
+				Expect(results.ApplicableLanguageTests).To(Equal(0))
+			})
+
+			It("Should plan SpotBugs as skipped.", func() {
+				plan, err := securitytest.Plan([]types.Code{{Language: "Java", Files: []string{"App.java"}}}, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.Skipped).To(ContainElement(types.SkippedSecurityTest{Name: "spotbugs", Language: "Java", Reason: securitytest.SkipReasonNoBuildFile}))
+			})
+		})
+
+		Context("When the project could not be built", func() {
+			It("Should fail SpotBugs with the tail of the build log.", func() {
+				results := run([]types.Code{
+					{Language: "Java", Files: []string{"src/main/java/App.java"}},
+					{Language: "Gradle", Files: []string{"build.gradle.kts"}},
+				}, "ERROR_RUNNING_GRADLE_BUILD\n> Task :compileJava FAILED\nApp.java:3: error: cannot find symbol\n")
+				container, ok := spotbugsContainer(results)
+				Expect(ok).To(BeTrue())
+				Expect(container.CResult).To(Equal("failed"))
+				Expect(container.CInfo).To(Equal("Could not build the project."))
+				Expect(container.Error).To(Equal("error running gradle build: > Task :compileJava FAILED\nApp.java:3: error: cannot find symbol"))
+				Expect(results.FinalResult).To(Equal("failed"))
+			})
+		})
+	})
+
+	Describe("Fail fast", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		gitleaksOutput, _ := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true}
+		enryScan := securitytest.SecTestScanInfo{
+			RID:   "failFastRID",
+			Codes: []types.Code{{Language: "Python", Files: []string{"main.py"}}},
+		}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest, banditTest})
+		})
+
+		Context("When a securityTest finds a vulnerability of the fail fast severity", func() {
+			It("Should cancel the remaining securityTests and keep the partial results.", func() {
+				fakeDatabase.waitForSaved("gitleaks")
+				results := securitytest.RunAllInfo{
+					RID:              "failFastRID",
+					FailFastSeverity: "high",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						{SecurityTest: gitleaksTest, CStatus: "finished", COutput: string(gitleaksOutput)},
+						{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+					}),
+				}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.FailFastAborted).To(BeTrue())
+				Expect(results.FinalResult).To(Equal("failed"))
+				Expect(results.ErrorFound).To(BeNil())
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns).To(HaveLen(1))
+				var cancelled types.Container
+				for _, container := range results.Containers {
+					if container.SecurityTest.Name == "bandit" {
+						cancelled = container
+					}
+				}
+				Expect(cancelled.CStatus).To(Equal(securitytest.CancelledStatus))
+				Expect(cancelled.CResult).To(Equal(securitytest.CancelledStatus))
+				Expect(cancelled.CInfo).To(Equal(securitytest.FailFastNote("high")))
+			})
+		})
+
+		Context("When no securityTest finds a vulnerability of the fail fast severity", func() {
+			It("Should run every securityTest to completion.", func() {
+				results := securitytest.RunAllInfo{
+					RID:              "failFastRID",
+					FailFastSeverity: "high",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						{SecurityTest: gitleaksTest, CStatus: "finished", COutput: `[]`},
+						{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+						{SecurityTest: banditTest, CStatus: "finished", COutput: `{"results": []}`},
+					}),
+				}
+				Expect(results.Start(enryScan)).To(Succeed())
+				Expect(results.FailFastAborted).To(BeFalse())
+				Expect(results.FinalResult).To(Equal("passed"))
+				Expect(results.Containers).To(HaveLen(3))
+				for _, container := range results.Containers {
+					Expect(container.CStatus).NotTo(Equal(securitytest.CancelledStatus))
+				}
+			})
+		})
+	})
+
+	Describe("Parallel securityTests", func() {
+		log.InitLog(true, "", "", "log_test", "log_test")
+
+		parallelTests := []types.SecurityTest{}
+		for _, name := range []string{"parallel1", "parallel2", "parallel3", "parallel4"} {
+			parallelTests = append(parallelTests, types.SecurityTest{Name: name, Type: "Generic", Default: true})
+		}
+		enryScan := securitytest.SecTestScanInfo{RID: "parallelRID"}
+
+		BeforeEach(func() {
+			fakeDatabase.reset(parallelTests)
+		})
+
+		Context("When the securityTests of an analysis are not limited", func() {
+			It("Should run all of them at the same time.", func() {
+				results := securitytest.RunAllInfo{RID: "parallelRID"}
+				Expect(results.Start(enryScan)).NotTo(Succeed())
+				Eventually(fakeDatabase.requested).Should(HaveLen(4))
+				Expect(fakeDatabase.maxParallel()).To(Equal(4))
+			})
+		})
+
+		Context("When the securityTests of an analysis are limited", func() {
+			It("Should run at most the limit of them at the same time.", func() {
+				results := securitytest.RunAllInfo{RID: "parallelRID", MaxParallelSecurityTests: 2}
+				Expect(results.Start(enryScan)).NotTo(Succeed())
+				Eventually(fakeDatabase.requested).Should(HaveLen(4))
+				Expect(fakeDatabase.maxParallel()).To(Equal(2))
+			})
+			It("Should apply the limit to each analysis apart.", func() {
+				var wg sync.WaitGroup
+				for _, RID := range []string{"parallelRID1", "parallelRID2"} {
+					wg.Add(1)
+					go func(RID string) {
+						defer GinkgoRecover()
+						defer wg.Done()
+						results := securitytest.RunAllInfo{RID: RID, MaxParallelSecurityTests: 1}
+						Expect(results.Start(securitytest.SecTestScanInfo{RID: RID})).NotTo(Succeed())
+					}(RID)
+				}
+				wg.Wait()
+				Eventually(fakeDatabase.requested).Should(HaveLen(8))
+				Expect(fakeDatabase.maxParallel()).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("Plan", func() {
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Image: "huskyci/gitleaks", ImageTag: "2.1.0", Type: "Generic", Default: true, TimeOutInSeconds: 360}
+		trufflehogTest := types.SecurityTest{Name: "trufflehog", Image: "huskyci/trufflehog", ImageTag: "latest", Type: "Generic"}
+		gosecTest := types.SecurityTest{Name: "gosec", Image: "huskyci/gosec", ImageTag: "2.3.0", Type: "Language", Language: "Go", Default: true, TimeOutInSeconds: 360}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, trufflehogTest, gosecTest})
+		})
+
+		Context("When the repository has supported and unsupported languages", func() {
+			codes := []types.Code{
+				{Language: "Go", Files: []string{"main.go"}},
+				{Language: "Elixir", Files: []string{"main.ex"}},
+			}
+			It("Should return the securityTests that would run without running them.", func() {
+				plan, err := securitytest.Plan(codes, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.Codes).To(Equal(codes))
+				Expect(plan.SecurityTests).To(Equal([]types.PlannedSecurityTest{
+					{Name: "gitleaks", Image: "huskyci/gitleaks", ImageTag: "2.1.0", Type: "Generic", TimeOutInSeconds: 360},
+					{Name: "gosec", Image: "huskyci/gosec", ImageTag: "2.3.0", Type: "Language", Language: "Go", TimeOutInSeconds: 360},
+				}))
+				Expect(fakeDatabase.requested()).To(BeEmpty())
+			})
+			It("Should return the skipped securityTests and languages and why.", func() {
+				plan, err := securitytest.Plan(codes, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.Skipped).To(Equal([]types.SkippedSecurityTest{
+					{Name: "trufflehog", Reason: securitytest.SkipReasonNotDefault},
+					{Language: "Elixir", Reason: securitytest.SkipReasonUnsupportedLanguage},
+				}))
+			})
+		})
+
+		Context("When the repository requests a timeout", func() {
+			It("Should return the timeout each securityTest would use.", func() {
+				plan, err := securitytest.Plan(nil, 600, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.SecurityTests).To(HaveLen(1))
+				Expect(plan.SecurityTests[0].TimeOutInSeconds).To(Equal(600))
+			})
+		})
+	})
+
+	Describe("Branch policy", func() {
+		gitleaksOutput, _ := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		gosecTest := types.SecurityTest{Name: "gosec", Type: "Language", Language: "Go", Default: true}
+		codes := []types.Code{{Language: "Go", Files: []string{"main.go"}}}
+		completed := securitytest.CompletedContainers([]types.Container{
+			{SecurityTest: gitleaksTest, CStatus: "finished", COutput: string(gitleaksOutput)},
+			{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+			{SecurityTest: gosecTest, CStatus: "finished", COutput: `{"Issues": []}`},
+		})
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest, gosecTest})
+		})
+
+		Context("When the policy never fails the analysis", func() {
+			It("Should pass it while keeping its findings.", func() {
+				results := securitytest.RunAllInfo{RID: "policyRID", Completed: completed, FailSeverity: "none"}
+				Expect(results.Start(securitytest.SecTestScanInfo{RID: "policyRID", Codes: codes})).To(Succeed())
+				Expect(results.HuskyCIResults.GenericResults.HuskyCIGitleaksOutput.HighVulns).To(HaveLen(1))
+				Expect(results.FinalResult).To(Equal("passed"))
+			})
+		})
+
+		Context("When the policy restricts the securityTests of the analysis", func() {
+			It("Should run none of the others.", func() {
+				results := securitytest.RunAllInfo{RID: "policyRID", Completed: completed, SecurityTests: []string{"gitleaks"}}
+				Expect(results.Start(securitytest.SecTestScanInfo{RID: "policyRID", Codes: codes})).To(Succeed())
+				Expect(fakeDatabase.requested()).To(BeEmpty())
+				Expect(results.Containers).To(HaveLen(1))
+				Expect(results.Containers[0].SecurityTest.Name).To(Equal("gitleaks"))
+			})
+			It("Should plan the others as not selected.", func() {
+				plan, err := securitytest.Plan(codes, 0, []string{"gitleaks", "gosec"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.SecurityTests).To(HaveLen(2))
+				Expect(plan.Skipped).To(Equal([]types.SkippedSecurityTest{
+					{Name: "gitauthors", Reason: securitytest.SkipReasonNotSelected},
+				}))
+			})
+		})
+	})
+
+	Describe("Offline mode", func() {
+		gitleaksOutput, _ := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		npmauditTest := types.SecurityTest{Name: "npmaudit", Type: "Language", Language: "JavaScript", Default: true}
+		yarnauditTest := types.SecurityTest{Name: "yarnaudit", Type: "Language", Language: "JavaScript", Default: true}
+		codes := []types.Code{{Language: "JavaScript", Files: []string{"index.js"}}}
+
+		BeforeEach(func() {
+			apiContext.APIConfiguration.OfflineMode = true
+			apiContext.APIConfiguration.OfflineMirrors = map[string]string{"yarnaudit": "https://npm.example.com"}
+		})
+		AfterEach(func() {
+			apiContext.APIConfiguration.OfflineMode = false
+			apiContext.APIConfiguration.OfflineMirrors = nil
+		})
+
+		Context("When a securityTest that needs network access has no mirror", func() {
+			It("Should skip it with a note instead of running it.", func() {
+				fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest, npmauditTest})
+				results := securitytest.RunAllInfo{
+					RID: "offlineRID",
+					Completed: securitytest.CompletedContainers([]types.Container{
+						{SecurityTest: gitleaksTest, CStatus: "finished", COutput: string(gitleaksOutput)},
+						{SecurityTest: gitauthorsTest, CStatus: "finished", COutput: `{"authors": []}`},
+					}),
+				}
+				Expect(results.Start(securitytest.SecTestScanInfo{RID: "offlineRID", Codes: codes})).To(Succeed())
+				Expect(fakeDatabase.requested()).To(BeEmpty())
+				Expect(securitytest.CompletedContainers(results.Containers)).NotTo(HaveKey("npmaudit"))
+				var skipped types.Container
+				for _, container := range results.Containers {
+					if container.SecurityTest.Name == "npmaudit" {
+						skipped = container
+					}
+				}
+				Expect(skipped.CStatus).To(Equal(securitytest.SkippedStatus))
+				Expect(skipped.CResult).To(Equal(securitytest.SkippedStatus))
+				Expect(skipped.CInfo).To(Equal(securitytest.OfflineSkipNote("npmaudit")))
+			})
+		})
+
+		Context("When planning an analysis", func() {
+			It("Should keep the securityTests with a mirror and skip the others with a note.", func() {
+				fakeDatabase.reset([]types.SecurityTest{npmauditTest, yarnauditTest})
+				plan, err := securitytest.Plan(codes, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.SecurityTests).To(HaveLen(1))
+				Expect(plan.SecurityTests[0].Name).To(Equal("yarnaudit"))
+				Expect(plan.Skipped).To(Equal([]types.SkippedSecurityTest{
+					{Name: "npmaudit", Language: "JavaScript", Reason: securitytest.OfflineSkipNote("npmaudit")},
+				}))
+			})
+		})
+	})
+
+	Describe("Scan profiles", func() {
+		gitleaksOutput, _ := ioutil.ReadFile("testdata/gitleaks_history_output.json")
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		gitauthorsTest := types.SecurityTest{Name: "gitauthors", Type: "Generic", Default: true}
+		npmauditTest := types.SecurityTest{Name: "npmaudit", Type: "Language", Language: "JavaScript", Default: true}
+		codes := []types.Code{{Language: "JavaScript", Files: []string{"index.js"}}}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, gitauthorsTest, npmauditTest})
+		})
+
+		Context("When the analysis is restricted to the securityTests of a profile", func() {
+			It("Should run none of the others.", func() {
+				results := securitytest.RunAllInfo{
+					RID:           "profileRID",
+					SecurityTests: []string{"gitleaks"},
+					Completed: securitytest.CompletedContainers([]types.Container{
+						{SecurityTest: gitleaksTest, CStatus: "finished", COutput: string(gitleaksOutput)},
+					}),
+				}
+				Expect(results.Start(securitytest.SecTestScanInfo{RID: "profileRID", Codes: codes})).To(Succeed())
+				Expect(fakeDatabase.requested()).To(BeEmpty())
+				Expect(results.Containers).To(HaveLen(1))
+				Expect(results.Containers[0].SecurityTest.Name).To(Equal("gitleaks"))
+				Expect(results.ApplicableLanguageTests).To(Equal(0))
+			})
+		})
+
+		Context("When planning an analysis restricted to the securityTests of a profile", func() {
+			It("Should skip the others as not selected.", func() {
+				plan, err := securitytest.Plan(codes, 0, []string{"gitleaks", "npmaudit"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.SecurityTests).To(HaveLen(2))
+				Expect(plan.SecurityTests[0].Name).To(Equal("gitleaks"))
+				Expect(plan.SecurityTests[1].Name).To(Equal("npmaudit"))
+				Expect(plan.Skipped).To(Equal([]types.SkippedSecurityTest{
+					{Name: "gitauthors", Reason: securitytest.SkipReasonNotSelected},
+				}))
+			})
+		})
+	})
+
+	Describe("FinishWithoutScannableContent", func() {
+		gitleaksTest := types.SecurityTest{Name: "gitleaks", Type: "Generic", Default: true}
+		banditTest := types.SecurityTest{Name: "bandit", Type: "Language", Language: "Python", Default: true}
+
+		BeforeEach(func() {
+			fakeDatabase.reset([]types.SecurityTest{gitleaksTest, banditTest})
+		})
+
+		Context("When the repository is empty", func() {
+			It("Should finish the analysis with no scannable content and no language.", func() {
+				results := securitytest.RunAllInfo{RID: "emptyRID"}
+				finished, err := results.FinishWithoutScannableContent(securitytest.SecTestScanInfo{RID: "emptyRID"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(finished).To(BeTrue())
+				Expect(results.Status).To(Equal("finished"))
+				Expect(results.FinalResult).To(Equal(securitytest.NoScannableContentResult))
+				Expect(results.Codes).To(BeEmpty())
+				Expect(results.Codes).NotTo(BeNil())
+				Expect(results.Containers).To(BeEmpty())
+			})
+		})
+
+		Context("When the repository has no file of a supported language", func() {
+			It("Should finish the analysis keeping the languages detected.", func() {
+				codes := []types.Code{{Language: "Markdown", Files: []string{"README.md"}}}
+				results := securitytest.RunAllInfo{RID: "markdownRID"}
+				finished, err := results.FinishWithoutScannableContent(securitytest.SecTestScanInfo{RID: "markdownRID", Codes: codes})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(finished).To(BeTrue())
+				Expect(results.FinalResult).To(Equal(securitytest.NoScannableContentResult))
+				Expect(results.Codes).To(Equal(codes))
+			})
+		})
+
+		Context("When the repository has a file of a supported language", func() {
+			It("Should not finish the analysis.", func() {
+				codes := []types.Code{{Language: "Markdown", Files: []string{"README.md"}}, {Language: "Python", Files: []string{"main.py"}}}
+				results := securitytest.RunAllInfo{RID: "pythonRID"}
+				finished, err := results.FinishWithoutScannableContent(securitytest.SecTestScanInfo{RID: "pythonRID", Codes: codes})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(finished).To(BeFalse())
+				Expect(results.Status).To(BeEmpty())
+				Expect(results.FinalResult).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("AddContainer", func() {
+		BeforeEach(func() {
+			fakeDatabase.reset(nil)
+		})
+
+		Context("When the container of a securityTest is added twice", func() {
+			It("Should keep only the last one and persist it every time.", func() {
+				results := securitytest.RunAllInfo{RID: "someRID"}
+				results.AddContainer(types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "running"})
+				results.AddContainer(types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "finished"})
+				Expect(results.Containers).To(HaveLen(1))
+				Expect(results.Containers[0].CStatus).To(Equal("finished"))
+				savedContainers, saves := fakeDatabase.saved()
+				Expect(savedContainers).To(Equal(results.Containers))
+				Expect(saves).To(Equal(2))
+			})
+		})
+		Context("When the analysis has no RID", func() {
+			It("Should not persist the container.", func() {
+				results := securitytest.RunAllInfo{}
+				results.AddContainer(types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}})
+				Expect(results.Containers).To(HaveLen(1))
+				_, saves := fakeDatabase.saved()
+				Expect(saves).To(BeZero())
+			})
+		})
+	})
+
+	Describe("CompletedContainers", func() {
+		It("Should return only finished containers by securityTest name.", func() {
+			finished := types.Container{SecurityTest: types.SecurityTest{Name: "gosec"}, CStatus: "finished"}
+			running := types.Container{SecurityTest: types.SecurityTest{Name: "bandit"}, CStatus: "running"}
+			failed := types.Container{SecurityTest: types.SecurityTest{Name: "safety"}, CStatus: "error running"}
+			Expect(securitytest.CompletedContainers([]types.Container{finished, running, failed})).To(Equal(map[string]types.Container{"gosec": finished}))
+		})
+	})
+})
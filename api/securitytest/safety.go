@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 	"github.com/globocom/huskyCI/api/util"
 )
@@ -21,15 +20,36 @@ type SafetyOutput struct {
 	ReqNotFound    bool
 	WarningFound   bool
 	OutputWarnings []string
+	// Packages are the pinned requirements Safety checked, vulnerable or not.
+	Packages []DependencyPackage `json:"packages"`
 }
 
 // SafetyIssue is a struct that holds the results that were scanned and the file they came from.
 type SafetyIssue struct {
-	Dependency string `json:"dependency"`
-	Below      string `json:"vulnerable_below"`
-	Version    string `json:"installed_version"`
-	Comment    string `json:"description"`
-	ID         string `json:"id"`
+	Dependency string      `json:"dependency"`
+	Below      string      `json:"vulnerable_below"`
+	Version    string      `json:"installed_version"`
+	Comment    string      `json:"description"`
+	ID         string      `json:"id"`
+	CVSSv2     *SafetyCVSS `json:"cvssv2"`
+	CVSSv3     *SafetyCVSS `json:"cvssv3"`
+}
+
+// SafetyCVSS is the struct that holds the CVSS score and vector Safety gives to a vulnerability, when available.
+type SafetyCVSS struct {
+	BaseScore    float64 `json:"base_score"`
+	VectorString string  `json:"vector_string"`
+}
+
+// cvss returns the CVSS v3 data of the issue, falling back to the CVSS v2 one, or nil when Safety gave none.
+func (issue SafetyIssue) cvss() *SafetyCVSS {
+	if issue.CVSSv3 != nil && issue.CVSSv3.BaseScore > 0 {
+		return issue.CVSSv3
+	}
+	if issue.CVSSv2 != nil && issue.CVSSv2.BaseScore > 0 {
+		return issue.CVSSv2
+	}
+	return nil
 }
 
 func analyzeSafety(safetyScan *SecTestScanInfo) error {
@@ -44,7 +64,7 @@ func analyzeSafety(safetyScan *SecTestScanInfo) error {
 	// check if there were any internal errors running safety
 	if failedRunning {
 		errorMsg := errors.New("internal error safety - ERROR_RUNNING_SAFETY")
-		log.Error("analyzeSafety", "SAFETY", 1033, errorMsg)
+		safetyScan.logger().Error("analyzeSafety", "SAFETY", 1033, errorMsg)
 		safetyScan.ErrorFound = errorMsg
 		safetyScan.prepareContainerAfterScan()
 		return errorMsg
@@ -71,12 +91,13 @@ func analyzeSafety(safetyScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a Safety struct.
 	if err := json.Unmarshal([]byte(safetyScan.Container.COutput), &safetyOutput); err != nil {
-		log.Error("analyzeSafety", "SAFETY", 1018, safetyScan.Container.COutput, err)
+		safetyScan.logger().Error("analyzeSafety", "SAFETY", 1018, safetyScan.Container.COutput, err)
 		safetyScan.ErrorFound = err
 		safetyScan.prepareContainerAfterScan()
 		return err
 	}
 	safetyScan.FinalOutput = safetyOutput
+	safetyScan.Components = components("pypi", safetyOutput.Packages)
 
 	// check results and prepare all vulnerabilities found
 	safetyScan.prepareSafetyVulns()
@@ -132,8 +153,21 @@ func (safetyScan *SecTestScanInfo) prepareSafetyVulns() {
 		safetyVuln.Code = issue.Dependency + " " + issue.Version
 		safetyVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s (%s)", issue.Dependency, issue.Below)
 		safetyVuln.VunerableBelow = issue.Below
+		safetyVuln.CVE = strings.Join(findCVEs(issue.Comment), ", ")
+		if cvss := issue.cvss(); cvss != nil {
+			safetyVuln.CVSSScore = cvss.BaseScore
+			safetyVuln.CVSSVector = cvss.VectorString
+			safetyVuln.Severity = CVSSSeverity(cvss.BaseScore)
+		}
 
-		huskyCIsafetyResults.HighVulns = append(huskyCIsafetyResults.HighVulns, safetyVuln)
+		switch safetyVuln.Severity {
+		case "low":
+			huskyCIsafetyResults.LowVulns = append(huskyCIsafetyResults.LowVulns, safetyVuln)
+		case "medium":
+			huskyCIsafetyResults.MediumVulns = append(huskyCIsafetyResults.MediumVulns, safetyVuln)
+		default:
+			huskyCIsafetyResults.HighVulns = append(huskyCIsafetyResults.HighVulns, safetyVuln)
+		}
 	}
 
 	safetyScan.Vulnerabilities = huskyCIsafetyResults
@@ -0,0 +1,61 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Safety", func() {
+	Describe("Parse", func() {
+		rawOutput, err := ioutil.ReadFile("testdata/safety_output.json")
+		It("Should read the fixture.", func() {
+			Expect(err).To(BeNil())
+		})
+
+		Context("When the issues have CVSS data", func() {
+			It("Should prefer the CVSS v3 score and derive the severity from it.", func() {
+				output, err := securitytest.Parse("safety", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Code).To(Equal("django 2.2.0"))
+				Expect(output.MediumVulns[0].Severity).To(Equal("medium"))
+				Expect(output.MediumVulns[0].CVSSScore).To(Equal(4.9))
+				Expect(output.MediumVulns[0].CVSSVector).To(Equal("CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:N/A:N"))
+			})
+
+			It("Should fall back to the CVSS v2 score.", func() {
+				output, err := securitytest.Parse("safety", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(2))
+				Expect(output.HighVulns[0].Code).To(Equal("pyyaml 5.1"))
+				Expect(output.HighVulns[0].CVSSScore).To(Equal(10.0))
+			})
+
+			It("Should extract the CVE ids from the description.", func() {
+				output, err := securitytest.Parse("safety", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns[0].CVE).To(Equal("CVE-2021-33203"))
+				Expect(output.HighVulns[0].CVE).To(Equal("CVE-2020-14343"))
+			})
+		})
+
+		Context("When an issue has no CVSS data", func() {
+			It("Should keep reporting it as high.", func() {
+				output, err := securitytest.Parse("safety", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns[1].Code).To(Equal("requests 2.19.0"))
+				Expect(output.HighVulns[1].Severity).To(Equal("high"))
+				Expect(output.HighVulns[1].CVE).To(BeEmpty())
+				Expect(output.HighVulns[1].CVSSScore).To(BeZero())
+			})
+		})
+	})
+})
@@ -2,35 +2,75 @@ package securitytest
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/globocom/huskyCI/api/changedfiles"
+	"github.com/globocom/huskyCI/api/clone"
+	"github.com/globocom/huskyCI/api/containerenv"
 	apiContext "github.com/globocom/huskyCI/api/context"
 	huskydocker "github.com/globocom/huskyCI/api/dockers"
 	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/secrets"
+	"github.com/globocom/huskyCI/api/snippet"
 	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/upload"
 	"github.com/globocom/huskyCI/api/util"
 )
 
 var securityTestAnalyze = map[string]func(scanInfo *SecTestScanInfo) error{
-	"bandit":     analyzeBandit,
-	"brakeman":   analyzeBrakeman,
-	"enry":       analyzeEnry,
-	"gitauthors": analyzeGitAuthors,
-	"gosec":      analyzeGosec,
-	"npmaudit":   analyzeNpmaudit,
-	"yarnaudit":  analyzeYarnaudit,
-	"spotbugs":   analyzeSpotBugs,
-	"gitleaks":   analyseGitleaks,
-	"safety":     analyzeSafety,
-	"tfsec":      analyzeTFSec,
+	"bandit":                analyzeBandit,
+	"brakeman":              analyzeBrakeman,
+	"dependencycheck":       analyzeDependencyCheck,
+	"dependencycheckgradle": analyzeDependencyCheck,
+	"detekt":                analyzeDetekt,
+	"enry":                  analyzeEnry,
+	"gitauthors":            analyzeGitAuthors,
+	"gosec":                 analyzeGosec,
+	"govulncheck":           analyzeGovulncheck,
+	"hadolint":              analyzeHadolint,
+	"npmaudit":              analyzeNpmaudit,
+	"osvscanner":            analyzeOSVScanner,
+	"yarnaudit":             analyzeYarnaudit,
+	"spotbugs":              analyzeSpotBugs,
+	"gitleaks":              analyseGitleaks,
+	"safety":                analyzeSafety,
+	"tfsec":                 analyzeTFSec,
+	"trivy":                 analyzeTrivy,
 }
 
+// ErrUnknownSecurityTest is returned when there is no parser for a given securityTest name.
+var ErrUnknownSecurityTest = errors.New("unknown securityTest")
+
 // SecTestScanInfo holds all information of securityTest scan.
 type SecTestScanInfo struct {
-	RID                   string
-	URL                   string
-	Branch                string
+	RID              string
+	URL              string
+	Branch           string
+	TimeOutInSeconds int
+	SubPath          string
+	Commit           string
+	SSHPrivateKey    string
+	ImageReference   string
+	BaseRef          string
+	// CloneURL, when set, is the URL with credentials used to clone the repository instead of URL.
+	CloneURL string
+	// CloneStrategy is how the repository of the analysis is cloned, which the securityTest may change for itself.
+	CloneStrategy types.CloneStrategy
+	// Source is upload.Source when the code is an archive uploaded for the analysis instead of a repository.
+	Source string
+	// ChangedFiles, when ChangedFilesOnly is set, are the only files whose vulnerabilities are reported.
+	// They are the files changed since BaseRef, found by the enry scan.
+	ChangedFiles     []string
+	ChangedFilesOnly bool
+	// SecurityTestArgs are the extra arguments, by securityTest tool, appended to the command of the securityTest.
+	SecurityTestArgs map[string][]string
+	// SecurityTestEnv are the environment variables, by securityTest tool, injected into the container of the
+	// securityTest. Secrets are still secret:NAME references, read from the secret store as it starts.
+	SecurityTestEnv       map[string]map[string]string
 	SecurityTestName      string
 	ErrorFound            error
 	ReqNotFound           bool
@@ -41,11 +81,25 @@ type SecTestScanInfo struct {
 	GitleaksErrorRunning  bool
 	GitleaksTimeout       bool
 	CommitAuthorsNotFound bool
-	CommitAuthors         GitAuthorsOutput
-	Codes                 []types.Code
-	Container             types.Container
-	FinalOutput           interface{}
-	Vulnerabilities       types.HuskyCISecurityTestOutput
+	// BuildError, when set, is the tail of the log of the project build that failed, failing the securityTest.
+	BuildError      string
+	CommitAuthors   GitAuthorsOutput
+	Codes           []types.Code
+	Container       types.Container
+	FinalOutput     interface{}
+	Vulnerabilities types.HuskyCISecurityTestOutput
+	// FailSeverity, when set by the branch policy of the analysis, is the lowest severity failing the securityTest.
+	FailSeverity string
+	// Components are the dependencies of the repository found by a dependency securityTest.
+	Components []types.Component
+	// Cancel, once closed, stops the container of the scan if it is still running.
+	Cancel <-chan struct{}
+	// diagnostics is what the scanner printed to stderr, kept in Container if the securityTest ends with an error.
+	diagnostics string
+	// resultFromVulnerabilities tells that the result of Container was set from the severity of Vulnerabilities.
+	resultFromVulnerabilities bool
+	// keptCID is the container dockerRun kept to read the code snippets of Vulnerabilities from.
+	keptCID string
 }
 
 // New creates a new huskyCI scan based given RID, URL, Branch and a securityTest name and returns an error.
@@ -61,7 +115,7 @@ func (scanInfo *SecTestScanInfo) setSecurityTestContainer(securityTestName strin
 	securityTestQuery := map[string]interface{}{"name": securityTestName}
 	securityTest, err := apiContext.APIConfiguration.DBInstance.FindOneDBSecurityTest(securityTestQuery)
 	if err != nil {
-		log.Error("createSecurityTestContainer", "SECURITYTEST", 2012, err)
+		scanInfo.logger().Error("createSecurityTestContainer", "SECURITYTEST", 2012, err)
 		return err
 	}
 	scanInfo.Container.StartedAt = time.Now()
@@ -71,12 +125,15 @@ func (scanInfo *SecTestScanInfo) setSecurityTestContainer(securityTestName strin
 
 // Start starts a new huskyCI scan!
 func (scanInfo *SecTestScanInfo) Start() error {
-	if err := scanInfo.dockerRun(scanInfo.Container.SecurityTest.TimeOutInSeconds); err != nil {
+	timeOutInSeconds := util.HandleTimeOut(scanInfo.Container.SecurityTest.TimeOutInSeconds, scanInfo.TimeOutInSeconds, apiContext.APIConfiguration.MaxTimeOutInSeconds)
+	if err := scanInfo.dockerRun(timeOutInSeconds); err != nil {
 		scanInfo.ErrorFound = err
 		scanInfo.prepareContainerAfterScan()
 		return err
 	}
-	if err := scanInfo.analyze(); err != nil {
+	err := scanInfo.analyze()
+	scanInfo.addCodeSnippets(err == nil)
+	if err != nil {
 		scanInfo.ErrorFound = err
 		scanInfo.prepareContainerAfterScan()
 		return err
@@ -85,38 +142,193 @@ func (scanInfo *SecTestScanInfo) Start() error {
 	return nil
 }
 
+// addCodeSnippets sets, when analyzed is set, the code snippets of Vulnerabilities from the code cloned in
+// the container kept by dockerRun, which is then removed.
+func (scanInfo *SecTestScanInfo) addCodeSnippets(analyzed bool) {
+	if scanInfo.keptCID == "" {
+		return
+	}
+	files, err := huskydocker.OpenContainerFiles(scanInfo.keptCID, scanInfo.logger())
+	if err != nil {
+		scanInfo.logger().Warning("addCodeSnippets", "SECURITYTEST", 136, err)
+		return
+	}
+	defer files.Remove()
+	if !analyzed {
+		return
+	}
+	readFile := func(file string) ([]byte, error) {
+		return files.ReadFile(path.Join("code", file), snippet.MaxFileSize)
+	}
+	snippet.Add(&scanInfo.Vulnerabilities, readFile, apiContext.APIConfiguration.CodeSnippetLines)
+}
+
+// logger returns the log.Entry correlating logs with the analysis and the securityTest of scanInfo.
+func (scanInfo *SecTestScanInfo) logger() log.Entry {
+	return log.ForAnalysis(scanInfo.RID, scanInfo.URL).WithSecurityTest(scanInfo.SecurityTestName)
+}
+
+// tool returns the securityTest whose parser reads the output of scanInfo, which is its own one unless it
+// runs a tool under a name of its own.
+func (scanInfo *SecTestScanInfo) tool() string {
+	if scanInfo.Container.SecurityTest.Tool != "" {
+		return scanInfo.Container.SecurityTest.Tool
+	}
+	return scanInfo.SecurityTestName
+}
+
+// toolOf returns the securityTest whose parser reads the output of securityTest.
+func toolOf(securityTest types.SecurityTest) string {
+	if securityTest.Tool != "" {
+		return securityTest.Tool
+	}
+	return securityTest.Name
+}
+
+// resume sets scanInfo from a container finished by a previous attempt of the analysis, parsing its
+// output again instead of running it. It returns false if there is no such container or its output
+// could not be parsed, meaning the securityTest has to run again.
+func (scanInfo *SecTestScanInfo) resume(RID, URL, branch string, container types.Container) bool {
+	if container.CStatus != "finished" {
+		return false
+	}
+	resumedScan := SecTestScanInfo{
+		RID:              RID,
+		URL:              URL,
+		CloneURL:         scanInfo.CloneURL,
+		Source:           scanInfo.Source,
+		Branch:           branch,
+		TimeOutInSeconds: scanInfo.TimeOutInSeconds,
+		SubPath:          scanInfo.SubPath,
+		Commit:           scanInfo.Commit,
+		ImageReference:   scanInfo.ImageReference,
+		FailSeverity:     scanInfo.FailSeverity,
+		BaseRef:          scanInfo.BaseRef,
+		ChangedFiles:     scanInfo.ChangedFiles,
+		ChangedFilesOnly: scanInfo.ChangedFilesOnly,
+		SecurityTestName: container.SecurityTest.Name,
+		Container:        container,
+	}
+	if err := resumedScan.analyze(); err != nil {
+		return false
+	}
+	resumedScan.Container.FinishedAt = container.FinishedAt
+	resumedScan.Container.DurationInSeconds = container.DurationInSeconds
+	*scanInfo = resumedScan
+	return true
+}
+
+// Parse runs the parser of a securityTest against its raw output, without running any container,
+// and returns the vulnerabilities found or the error found parsing it.
+func Parse(securityTestName, rawOutput string) (types.HuskyCISecurityTestOutput, error) {
+	if _, ok := securityTestAnalyze[securityTestName]; !ok {
+		return types.HuskyCISecurityTestOutput{}, ErrUnknownSecurityTest
+	}
+	scanInfo := SecTestScanInfo{SecurityTestName: securityTestName}
+	scanInfo.Container.COutput = rawOutput
+	if err := scanInfo.analyze(); err != nil {
+		return types.HuskyCISecurityTestOutput{}, err
+	}
+	return scanInfo.Vulnerabilities, nil
+}
+
 func (scanInfo *SecTestScanInfo) dockerRun(timeOutInSeconds int) error {
 	image := scanInfo.Container.SecurityTest.Image
 	imageTag := scanInfo.Container.SecurityTest.ImageTag
-	cmd := util.HandleCmd(scanInfo.URL, scanInfo.Branch, scanInfo.Container.SecurityTest.Cmd)
+	cmd := util.HandleCommit(scanInfo.Container.SecurityTest.Cmd, scanInfo.Commit)
+	scanInfo.Container.CloneStrategy = clone.ForSecurityTest(scanInfo.CloneStrategy, scanInfo.tool(), apiContext.APIConfiguration.GitleaksHistoryScan)
+	cmd = clone.HandleOptions(cmd, scanInfo.Container.CloneStrategy)
+	cloneURL := scanInfo.URL
+	if scanInfo.CloneURL != "" {
+		cloneURL = scanInfo.CloneURL
+	}
+	files := ""
+	if scanInfo.Source == upload.Source {
+		cloneURL = "/" + upload.Root
+		cmd = upload.HandleCmd(cmd, cloneURL, scanInfo.Branch)
+		files = upload.Path(apiContext.APIConfiguration.UploadConfig.Dir, scanInfo.RID)
+	}
+	cmd = util.HandleCmd(cloneURL, scanInfo.Branch, cmd)
+	cmd = util.HandleSubPath(cmd, scanInfo.SubPath)
+	cmd = changedfiles.HandleCmd(cmd, scanInfo.BaseRef, apiContext.APIConfiguration.DiffMode, scanInfo.SubPath)
+	cmd = util.HandleSecurityTestArgs(cmd, scanInfo.SecurityTestArgs[scanInfo.tool()])
+	cmd = util.HandleGitleaksDepth(cmd, apiContext.APIConfiguration.GitleaksHistoryScan)
+	cmd = util.HandleGitleaksShards(cmd, apiContext.APIConfiguration.GitleaksHistoryShards)
+	mirror, _ := OfflineMirror(scanInfo.tool(), apiContext.APIConfiguration.OfflineMode, apiContext.APIConfiguration.OfflineMirrors)
+	cmd = util.HandleOfflineMirror(cmd, mirror)
+	cmd = util.HandleTrivyTargets(cmd, apiContext.APIConfiguration.TrivyScanTargets, scanInfo.ImageReference)
 	cmd = util.HandleGitURLSubstitution(cmd)
+	cmd = util.HandleGitCACertificates(cmd, apiContext.APIConfiguration.GitCACertificates)
+	cmd = util.HandleSSHKnownHosts(cmd, apiContext.APIConfiguration.GitSSHKnownHosts, apiContext.APIConfiguration.GitSSHStrictHostKeyChecking)
+	cmd = util.HandleRepositorySSHKey(cmd, scanInfo.SSHPrivateKey)
+	cmd = util.HandleDiagnostics(cmd)
 	finalCMD := util.HandlePrivateSSHKey(cmd)
-	CID, cOutput, err := huskydocker.DockerRun(image, imageTag, finalCMD, timeOutInSeconds)
+	env := containerenv.Forwarded(apiContext.APIConfiguration.ContainerEnvAllowlist, scanInfo.Container.SecurityTest.EnvAllowlist, os.LookupEnv)
+	env, injectedSecrets, err := containerenv.Inject(env, scanInfo.SecurityTestEnv[scanInfo.tool()], secrets.ScopedLookup(secrets.DirLookup(apiContext.APIConfiguration.ContainerSecretsDir), apiContext.APIConfiguration.ContainerSecretScopes, scanInfo.URL))
 	if err != nil {
+		scanInfo.logger().Error("dockerRun", "SECURITYTEST", 1074, scanInfo.Container.SecurityTest.Name, err)
 		return err
 	}
-	scanInfo.Container.CID = CID
-	scanInfo.Container.COutput = cOutput
+	// the container is kept, once it finished, to read the code around the vulnerabilities found from it.
+	keep := apiContext.APIConfiguration.CodeSnippetLines > 0
+	runInfo, err := huskydocker.DockerRun(scanInfo.RID, image, imageTag, finalCMD, env, files, keep, timeOutInSeconds, scanInfo.Cancel, scanInfo.logger())
+	scanInfo.Container.CID = runInfo.CID
+	scanInfo.Container.Image = runInfo.Image
+	scanInfo.Container.ExitCode = runInfo.ExitCode
+	if err != nil {
+		return err
+	}
+	if keep {
+		scanInfo.keptCID = runInfo.CID
+	}
+	// git may print the URL it cloned, so the credentials of CloneURL are stripped from the output as well,
+	// and so are the secrets injected, which the securityTest may print.
+	output, diagnostics := util.SplitDiagnostics(secrets.Redact(strings.Replace(runInfo.Output, cloneURL, scanInfo.URL, -1), injectedSecrets))
+	scanInfo.Container.COutput = output
+	scanInfo.diagnostics = diagnostics
 	return nil
 }
 
 func (scanInfo *SecTestScanInfo) analyze() error {
+	if strings.Contains(scanInfo.Container.COutput, "ERROR_COMMIT_NOT_FOUND") {
+		errorMsg := fmt.Errorf("commit %s not found in the repository", scanInfo.Commit)
+		scanInfo.logger().Error("analyze", "SECURITYTEST", 1048, scanInfo.URL, scanInfo.Commit, errorMsg)
+		scanInfo.ErrorFound = errorMsg
+		return errorMsg
+	}
+	if strings.Contains(scanInfo.Container.COutput, "ERROR_BASE_REF_NOT_FOUND") {
+		errorMsg := fmt.Errorf("base ref %s not found in the repository", scanInfo.BaseRef)
+		scanInfo.logger().Error("analyze", "SECURITYTEST", 1061, scanInfo.URL, scanInfo.BaseRef, errorMsg)
+		scanInfo.ErrorFound = errorMsg
+		return errorMsg
+	}
 	errorCloning := strings.Contains(scanInfo.Container.COutput, "ERROR_CLONING")
 	if errorCloning {
 		errorMsg := errors.New("error cloning")
-		log.Error("analyze", "SECURITYTEST", 1031, scanInfo.URL, scanInfo.Branch, errorMsg)
+		scanInfo.logger().Error("analyze", "SECURITYTEST", 1031, scanInfo.URL, scanInfo.Branch, errorMsg)
 		scanInfo.ErrorFound = errorMsg
 		return errorMsg
 	}
-	securityTestAnalyze := securityTestAnalyze[scanInfo.SecurityTestName]
-	return securityTestAnalyze(scanInfo)
+	securityTestAnalyze, ok := securityTestAnalyze[scanInfo.tool()]
+	if !ok {
+		return ErrUnknownSecurityTest
+	}
+	if err := securityTestAnalyze(scanInfo); err != nil {
+		return err
+	}
+	// securityTests scan the whole repository, so their findings are restricted to the changed files here.
+	if scanInfo.ChangedFilesOnly {
+		changedfiles.Filter(&scanInfo.Vulnerabilities, scanInfo.ChangedFiles)
+	}
+	return nil
 }
 
 func (scanInfo *SecTestScanInfo) prepareContainerAfterScan() {
 
 	cOutputMaxSize := 1000000
 	scanInfo.Container.FinishedAt = time.Now()
-	scanInfo.Container.CInfo = "No issues found."
+	scanInfo.Container.DurationInSeconds = scanInfo.Container.FinishedAt.Sub(scanInfo.Container.StartedAt).Seconds()
+	scanInfo.Container.CInfo = noIssuesFoundInfo
 	scanInfo.Container.CResult = "passed"
 	scanInfo.Container.CStatus = "finished"
 
@@ -126,12 +338,22 @@ func (scanInfo *SecTestScanInfo) prepareContainerAfterScan() {
 	}
 
 	if scanInfo.ErrorFound != nil {
+		scanInfo.Container.Error = scanInfo.ErrorFound.Error()
+		scanInfo.Container.Diagnostics = scanInfo.diagnostics
 		scanInfo.Container.CInfo = "Error found running container"
 		scanInfo.Container.CResult = "error"
 		scanInfo.Container.CStatus = "error running"
 		return
 	}
 
+	if scanInfo.BuildError != "" {
+		scanInfo.Container.Error = scanInfo.BuildError
+		scanInfo.Container.Diagnostics = scanInfo.diagnostics
+		scanInfo.Container.CInfo = "Could not build the project."
+		scanInfo.Container.CResult = "failed"
+		return
+	}
+
 	if scanInfo.ReqNotFound {
 		scanInfo.Container.CInfo = "requeriments.txt was not found."
 		scanInfo.Container.CResult = "warning"
@@ -155,12 +377,53 @@ func (scanInfo *SecTestScanInfo) prepareContainerAfterScan() {
 		return
 	}
 
-	if len(scanInfo.Vulnerabilities.MediumVulns) > 0 || len(scanInfo.Vulnerabilities.HighVulns) > 0 {
-		scanInfo.Container.CInfo = "Issues found."
+	scanInfo.setVulnerabilitiesResult()
+}
+
+// The informational notes of a container whose result was set from the severity of its vulnerabilities.
+const (
+	noIssuesFoundInfo = "No issues found."
+	issuesFoundInfo   = "Issues found."
+	warningsFoundInfo = "Warnings found."
+)
+
+// setVulnerabilitiesResult sets the result of the container of scanInfo from the severity of its vulnerabilities.
+func (scanInfo *SecTestScanInfo) setVulnerabilitiesResult() {
+	scanInfo.resultFromVulnerabilities = true
+	scanInfo.Container.CInfo = noIssuesFoundInfo
+	scanInfo.Container.CResult = "passed"
+	if ReachesSeverity(scanInfo.Vulnerabilities, scanInfo.failSeverity()) {
+		scanInfo.Container.CInfo = issuesFoundInfo
 		scanInfo.Container.CResult = "failed"
-	} else if len(scanInfo.Vulnerabilities.LowVulns) > 0 {
-		scanInfo.Container.CInfo = "Warnings found."
+	} else if ReachesSeverity(scanInfo.Vulnerabilities, "low") {
+		scanInfo.Container.CInfo = warningsFoundInfo
 		scanInfo.Container.CResult = "passed"
 	}
+}
+
+// failSeverity returns the lowest severity of the vulnerabilities failing the securityTest of scanInfo: the one
+// of the branch policy of the analysis when set, then its own one, otherwise the global one.
+func (scanInfo *SecTestScanInfo) failSeverity() string {
+	if scanInfo.FailSeverity != "" {
+		return scanInfo.FailSeverity
+	}
+	if severity := scanInfo.Container.SecurityTest.FailSeverity; severity != "" {
+		return severity
+	}
+	if apiContext.APIConfiguration != nil && apiContext.APIConfiguration.FailSeverity != "" {
+		return apiContext.APIConfiguration.FailSeverity
+	}
+	return "medium"
+}
 
+// overrideSeverity returns the severity that config overrides for the vulnerabilities scanInfo finds with rule,
+// in upper case as the securityTests applying it rate theirs, or severity when there is no override.
+func (scanInfo *SecTestScanInfo) overrideSeverity(rule, severity string) string {
+	if apiContext.APIConfiguration == nil || rule == "" {
+		return severity
+	}
+	if override, ok := apiContext.APIConfiguration.SeverityOverrides[strings.ToLower(scanInfo.tool())+":"+rule]; ok {
+		return strings.ToUpper(override)
+	}
+	return severity
 }
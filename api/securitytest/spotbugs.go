@@ -11,7 +11,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
@@ -46,6 +45,7 @@ type SpotBugsIssue struct {
 	Category     string       `xml:"category,attr"`
 	SourceLine   []SourceLine `xml:"SourceLine"`
 	ShortMessage string       `xml:"ShortMessage"`
+	LongMessage  string       `xml:"LongMessage"`
 }
 
 // Error is the struct that holds errors that happened in analysis
@@ -72,22 +72,14 @@ func analyzeSpotBugs(spotbugsScan *SecTestScanInfo) error {
 	spotBugsOutput := SpotBugsOutput{}
 	spotbugsScan.FinalOutput = spotBugsOutput
 
-	// check if GRADLE failed running
-	errorGradleFound := strings.Contains(spotbugsScan.Container.COutput, "ERROR_RUNNING_GRADLE_BUILD")
-	if errorGradleFound {
-		spotbugsScan.ErrorFound = errors.New("error running gradle")
-		spotbugsScan.prepareSpotBugsVulns()
-		spotbugsScan.prepareContainerAfterScan()
-		return nil
-	}
-
-	// check if Maven failed running
-	errorMavenFound := strings.Contains(spotbugsScan.Container.COutput, "ERROR_RUNNING_MAVEN_BUILD")
-	if errorMavenFound {
-		spotbugsScan.ErrorFound = errors.New("error running maven")
-		spotbugsScan.prepareSpotBugsVulns()
-		spotbugsScan.prepareContainerAfterScan()
-		return nil
+	// a project that could not be built fails the securityTest with the tail of its build log.
+	for _, build := range []struct{ marker, tool string }{{"ERROR_RUNNING_GRADLE_BUILD", "gradle"}, {"ERROR_RUNNING_MAVEN_BUILD", "maven"}} {
+		if outputs := strings.SplitN(spotbugsScan.Container.COutput, build.marker, 2); len(outputs) == 2 {
+			spotbugsScan.BuildError = fmt.Sprintf("error running %s build: %s", build.tool, strings.TrimSpace(outputs[1]))
+			spotbugsScan.logger().Warning("analyzeSpotBugs", "SPOTBUGS", 130, spotbugsScan.BuildError)
+			spotbugsScan.prepareContainerAfterScan()
+			return nil
+		}
 	}
 
 	// check if unsuported java project was found
@@ -108,7 +100,7 @@ func analyzeSpotBugs(spotbugsScan *SecTestScanInfo) error {
 	// Unmarshall rawOutput into finalOutput, that is a SpotBugsOutput struct.
 	spotBugsOutput, err := parseXMLtoJSON([]byte(spotbugsScan.Container.COutput))
 	if err != nil {
-		log.Error("analyzeSpotBugs", "SPOTBUGS", 1039, spotbugsScan.Container.COutput, err)
+		spotbugsScan.logger().Error("analyzeSpotBugs", "SPOTBUGS", 1039, spotbugsScan.Container.COutput, err)
 		spotbugsScan.ErrorFound = err
 		spotbugsScan.prepareContainerAfterScan()
 		return err
@@ -166,13 +158,20 @@ func (spotbugsScan *SecTestScanInfo) prepareSpotBugsVulns() {
 			spotbugsVuln.Language = "Java"
 			spotbugsVuln.SecurityTool = "SpotBugs"
 			spotbugsVuln.Type = spotbugsOutput.SpotBugsIssue[i].Abbreviation
+			// the messages of the bug instances are only reported with -xml:withMessages.
 			spotbugsVuln.Details = spotbugsOutput.SpotBugsIssue[i].Type
+			if longMessage := spotbugsOutput.SpotBugsIssue[i].LongMessage; longMessage != "" {
+				spotbugsVuln.Details = longMessage
+			}
 			startLine := spotbugsOutput.SpotBugsIssue[i].SourceLine[j].Start
 			endLine := spotbugsOutput.SpotBugsIssue[i].SourceLine[j].End
 			spotbugsVuln.Code = fmt.Sprintf("Code beetween Line %s and Line %s.", startLine, endLine)
 			spotbugsVuln.Line = startLine
 			spotbugsVuln.File = spotbugsOutput.SpotBugsIssue[i].SourceLine[j].SourcePath
-			spotbugsVuln.Title = spotbugsVuln.Details
+			spotbugsVuln.Title = spotbugsOutput.SpotBugsIssue[i].Type
+			if shortMessage := spotbugsOutput.SpotBugsIssue[i].ShortMessage; shortMessage != "" {
+				spotbugsVuln.Title = shortMessage
+			}
 
 			switch spotbugsOutput.SpotBugsIssue[i].Priority {
 			case "1":
@@ -185,7 +184,7 @@ func (spotbugsScan *SecTestScanInfo) prepareSpotBugsVulns() {
 
 			rank, err := strconv.Atoi(spotbugsOutput.SpotBugsIssue[i].Rank)
 			if err != nil {
-				log.Warning("analyzeSpotBugs", "SPOTBUGS", 1039, "exception while reading rank from a spotbugs issue", err)
+				spotbugsScan.logger().Warning("analyzeSpotBugs", "SPOTBUGS", 1039, "exception while reading rank from a spotbugs issue", err)
 				continue
 			}
 
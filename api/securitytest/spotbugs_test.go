@@ -0,0 +1,74 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpotBugs", func() {
+	Describe("Parse", func() {
+		Context("When the report has bug instances of several ranks", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/spotbugs_output.xml")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should rate them by their bug rank.", func() {
+				output, err := securitytest.Parse("spotbugs", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Type).To(Equal("SECSQLIJDBC"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Type).To(Equal("SECPR"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Type).To(Equal("SECCU"))
+			})
+
+			It("Should extract the messages and location of each bug instance.", func() {
+				output, err := securitytest.Parse("spotbugs", string(rawOutput))
+				Expect(err).To(BeNil())
+				sqlInjection := output.HighVulns[0]
+				Expect(sqlInjection.SecurityTool).To(Equal("SpotBugs"))
+				Expect(sqlInjection.Language).To(Equal("Java"))
+				Expect(sqlInjection.Severity).To(Equal("HIGH"))
+				Expect(sqlInjection.Confidence).To(Equal("HIGH"))
+				Expect(sqlInjection.Title).To(Equal("Potential JDBC Injection"))
+				Expect(sqlInjection.Details).To(ContainSubstring("can be vulnerable to SQL injection"))
+				Expect(sqlInjection.File).To(Equal("com/example/app/UserRepository.java"))
+				Expect(sqlInjection.Line).To(Equal("31"))
+			})
+
+			It("Should fall back to the bug type when the report has no messages.", func() {
+				output, err := securitytest.Parse("spotbugs", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.LowVulns[0].Title).To(Equal("COOKIE_USAGE"))
+				Expect(output.LowVulns[0].Details).To(Equal("COOKIE_USAGE"))
+			})
+		})
+
+		Context("When the project could not be built", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("spotbugs", "ERROR_RUNNING_MAVEN_BUILD\n[ERROR] COMPILATION ERROR")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When SpotBugs could not analyze the classes", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("spotbugs", `<BugCollection><Errors errors="1" missingClasses="3"></Errors></BugCollection>`)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
@@ -41,7 +40,7 @@ func analyzeTFSec(tfsecScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a TFSec struct.
 	if err := json.Unmarshal([]byte(tfsecScan.Container.COutput), &tfsecOutput); err != nil {
-		log.Error("analyzeTFSec", "TFSEC", 1040, tfsecScan.Container.COutput, err)
+		tfsecScan.logger().Error("analyzeTFSec", "TFSEC", 1040, tfsecScan.Container.COutput, err)
 		tfsecScan.ErrorFound = err
 		return err
 	}
@@ -0,0 +1,46 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TFSec", func() {
+	Describe("Parse", func() {
+		Context("When the output has results of every severity", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/tfsec_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map tfsec severities into huskyCI severities.", func() {
+				output, err := securitytest.Parse("tfsec", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Severity).To(Equal("High"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Severity).To(Equal("Medium"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Severity).To(Equal("Low"))
+			})
+
+			It("Should extract the file and line of each result.", func() {
+				output, err := securitytest.Parse("tfsec", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns[0].File).To(Equal("/src/code/s3.tf"))
+				Expect(output.MediumVulns[0].Line).To(Equal("6"))
+				Expect(output.MediumVulns[0].Code).To(Equal("Code beetween Line 6 and Line 9."))
+				Expect(output.LowVulns[0].File).To(Equal("/src/code/sg.tf"))
+				Expect(output.LowVulns[0].Line).To(Equal("12"))
+			})
+		})
+	})
+})
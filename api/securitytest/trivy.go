@@ -0,0 +1,176 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// TrivyOutput is the struct that holds all data from trivy JSON report, merging the reports of every scanned target.
+type TrivyOutput struct {
+	Results []TrivyResult `json:"Results"`
+}
+
+// TrivyResult is the struct that holds the vulnerabilities trivy found in a target, such as a lock file or an OS.
+type TrivyResult struct {
+	Target          string               `json:"Target"`
+	Class           string               `json:"Class"`
+	Type            string               `json:"Type"`
+	Vulnerabilities []TrivyVulnerability `json:"Vulnerabilities"`
+}
+
+// TrivyVulnerability is the struct that holds detailed information of a vulnerability from trivy output.
+type TrivyVulnerability struct {
+	VulnerabilityID  string               `json:"VulnerabilityID"`
+	PkgName          string               `json:"PkgName"`
+	InstalledVersion string               `json:"InstalledVersion"`
+	FixedVersion     string               `json:"FixedVersion"`
+	Title            string               `json:"Title"`
+	Description      string               `json:"Description"`
+	Severity         string               `json:"Severity"`
+	PrimaryURL       string               `json:"PrimaryURL"`
+	CVSS             map[string]TrivyCVSS `json:"CVSS"`
+}
+
+// TrivyCVSS is the struct that holds the CVSS scores and vectors a source, such as nvd, gives to a vulnerability.
+type TrivyCVSS struct {
+	V2Vector string  `json:"V2Vector"`
+	V3Vector string  `json:"V3Vector"`
+	V2Score  float64 `json:"V2Score"`
+	V3Score  float64 `json:"V3Score"`
+}
+
+func analyzeTrivy(trivyScan *SecTestScanInfo) error {
+
+	trivyOutput := TrivyOutput{}
+	trivyScan.FinalOutput = trivyOutput
+
+	// check if there were any internal errors running trivy
+	if strings.Contains(trivyScan.Container.COutput, "ERROR_RUNNING_TRIVY") {
+		errorMsg := errors.New("internal error trivy - ERROR_RUNNING_TRIVY")
+		trivyScan.logger().Error("analyzeTrivy", "TRIVY", 1054, errorMsg)
+		trivyScan.ErrorFound = errorMsg
+		trivyScan.prepareContainerAfterScan()
+		return errorMsg
+	}
+
+	// nil cOutput states that no target was scanned.
+	if strings.TrimSpace(trivyScan.Container.COutput) == "" {
+		trivyScan.prepareContainerAfterScan()
+		return nil
+	}
+
+	// Unmarshall rawOutput into finalOutput, that is a TrivyOutput struct.
+	if err := json.Unmarshal([]byte(trivyScan.Container.COutput), &trivyOutput); err != nil {
+		trivyScan.logger().Error("analyzeTrivy", "TRIVY", 1053, trivyScan.Container.COutput, err)
+		trivyScan.ErrorFound = err
+		trivyScan.prepareContainerAfterScan()
+		return err
+	}
+	trivyScan.FinalOutput = trivyOutput
+
+	// check results and prepare all vulnerabilities found
+	trivyScan.prepareTrivyVulns()
+	trivyScan.prepareContainerAfterScan()
+	return nil
+}
+
+func (trivyScan *SecTestScanInfo) prepareTrivyVulns() {
+
+	huskyCItrivyResults := types.HuskyCISecurityTestOutput{}
+	trivyOutput := trivyScan.FinalOutput.(TrivyOutput)
+
+	for _, result := range trivyOutput.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			trivyVuln := types.HuskyCIVulnerability{}
+			trivyVuln.Language = "Generic"
+			trivyVuln.SecurityTool = "Trivy"
+			trivyVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", vulnerability.PkgName, vulnerability.InstalledVersion, vulnerability.VulnerabilityID)
+			trivyVuln.Details = trivyDetails(vulnerability)
+			trivyVuln.Type = result.Type
+			trivyVuln.File = result.Target
+			trivyVuln.Code = vulnerability.PkgName
+			trivyVuln.Version = vulnerability.InstalledVersion
+			trivyVuln.CVSSScore, trivyVuln.CVSSVector = trivyCVSS(vulnerability.CVSS)
+			if strings.HasPrefix(vulnerability.VulnerabilityID, "CVE-") {
+				trivyVuln.CVE = vulnerability.VulnerabilityID
+			}
+
+			switch strings.ToUpper(vulnerability.Severity) {
+			case "CRITICAL", "HIGH":
+				trivyVuln.Severity = "high"
+			case "MEDIUM":
+				trivyVuln.Severity = "medium"
+			case "LOW":
+				trivyVuln.Severity = "low"
+			default:
+				// UNKNOWN severities are bucketed by their CVSS score, if any.
+				trivyVuln.Severity = CVSSSeverity(trivyVuln.CVSSScore)
+				if trivyVuln.Severity == "" {
+					trivyVuln.Severity = "low"
+				}
+			}
+
+			switch trivyVuln.Severity {
+			case "high":
+				huskyCItrivyResults.HighVulns = append(huskyCItrivyResults.HighVulns, trivyVuln)
+			case "medium":
+				huskyCItrivyResults.MediumVulns = append(huskyCItrivyResults.MediumVulns, trivyVuln)
+			case "low":
+				huskyCItrivyResults.LowVulns = append(huskyCItrivyResults.LowVulns, trivyVuln)
+			}
+		}
+	}
+
+	trivyScan.Vulnerabilities = huskyCItrivyResults
+}
+
+// trivyDetails returns the title and description of a vulnerability, followed by its fixed version and link when known.
+func trivyDetails(vulnerability TrivyVulnerability) string {
+	details := []string{}
+	for _, detail := range []string{vulnerability.Title, vulnerability.Description} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if vulnerability.FixedVersion != "" {
+		details = append(details, "Fixed in version "+vulnerability.FixedVersion+".")
+	}
+	if vulnerability.PrimaryURL != "" {
+		details = append(details, vulnerability.PrimaryURL)
+	}
+	return strings.Join(details, "\n")
+}
+
+// trivyCVSS returns the CVSS score and vector of a vulnerability, preferring the nvd source and CVSS v3 over v2.
+func trivyCVSS(cvss map[string]TrivyCVSS) (float64, string) {
+	sources := make([]string, 0, len(cvss))
+	for source := range cvss {
+		if source != "nvd" {
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(sources)
+	if _, ok := cvss["nvd"]; ok {
+		sources = append([]string{"nvd"}, sources...)
+	}
+	for _, source := range sources {
+		if cvss[source].V3Score > 0 {
+			return cvss[source].V3Score, cvss[source].V3Vector
+		}
+	}
+	for _, source := range sources {
+		if cvss[source].V2Score > 0 {
+			return cvss[source].V2Score, cvss[source].V2Vector
+		}
+	}
+	return 0, ""
+}
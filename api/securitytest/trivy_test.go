@@ -0,0 +1,97 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trivy", func() {
+	Describe("Parse", func() {
+		Context("When the output has filesystem and image results", func() {
+			rawOutput, err := ioutil.ReadFile("testdata/trivy_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map trivy severities into huskyCI severities.", func() {
+				output, err := securitytest.Parse("trivy", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(2))
+				Expect(output.HighVulns[0].Code).To(Equal("lodash"))
+				Expect(output.HighVulns[1].Code).To(Equal("minimist"))
+				Expect(output.MediumVulns).To(HaveLen(2))
+				Expect(output.MediumVulns[0].Code).To(Equal("apk-tools"))
+				Expect(output.LowVulns).To(HaveLen(2))
+				Expect(output.LowVulns[0].Code).To(Equal("musl"))
+			})
+
+			It("Should bucket UNKNOWN severities by their CVSS score or as low.", func() {
+				output, err := securitytest.Parse("trivy", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns[1].Code).To(Equal("busybox"))
+				Expect(output.MediumVulns[1].Severity).To(Equal("medium"))
+				Expect(output.LowVulns[1].Code).To(Equal("ssl_client"))
+				Expect(output.LowVulns[1].Severity).To(Equal("low"))
+			})
+
+			It("Should extract the package, target and advisory of each finding.", func() {
+				output, err := securitytest.Parse("trivy", string(rawOutput))
+				Expect(err).To(BeNil())
+				lodash := output.HighVulns[0]
+				Expect(lodash.SecurityTool).To(Equal("Trivy"))
+				Expect(lodash.Language).To(Equal("Generic"))
+				Expect(lodash.Title).To(Equal("Vulnerable Dependency: lodash 4.17.15 (CVE-2021-23337)"))
+				Expect(lodash.File).To(Equal("package-lock.json"))
+				Expect(lodash.Type).To(Equal("npm"))
+				Expect(lodash.Version).To(Equal("4.17.15"))
+				Expect(lodash.CVE).To(Equal("CVE-2021-23337"))
+				Expect(lodash.Details).To(ContainSubstring("Fixed in version 4.17.21."))
+				Expect(lodash.Details).To(ContainSubstring("https://avd.aquasec.com/nvd/cve-2021-23337"))
+				Expect(output.MediumVulns[0].File).To(Equal("alpine:3.10 (alpine 3.10.9)"))
+				Expect(output.MediumVulns[1].CVE).To(BeEmpty())
+			})
+
+			It("Should prefer the nvd CVSS v3 score of each finding.", func() {
+				output, err := securitytest.Parse("trivy", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns[0].CVSSScore).To(Equal(7.2))
+				Expect(output.HighVulns[0].CVSSVector).To(Equal("CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"))
+				Expect(output.MediumVulns[0].CVSSScore).To(Equal(6.5))
+				Expect(output.MediumVulns[1].CVSSScore).To(Equal(5.0))
+				Expect(output.LowVulns[0].CVSSScore).To(BeZero())
+			})
+		})
+
+		Context("When no target was scanned", func() {
+			It("Should return no vulnerabilities.", func() {
+				output, err := securitytest.Parse("trivy", "")
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				Expect(output.MediumVulns).To(BeEmpty())
+				Expect(output.LowVulns).To(BeEmpty())
+			})
+		})
+
+		Context("When trivy failed running", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("trivy", "ERROR_RUNNING_TRIVY\nFATAL unable to initialize a scanner")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("When the output is malformed", func() {
+			It("Should return an error.", func() {
+				_, err := securitytest.Parse("trivy", `{"Results": [`)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -9,14 +9,16 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/types"
 )
 
-// YarnAuditOutput is the struct that stores all yarn audit output
+// YarnAuditOutput is the struct that stores all yarn audit output. Reports of classic yarn hold their
+// advisories in Advisories, while reports of yarn berry (2 and later), audited by yarn npm audit, hold
+// them in BerryAdvisories.
 type YarnAuditOutput struct {
-	Advisories       []YarnIssue `json:"advisories"`
-	Metadata         Metadata    `json:"metadata"`
+	Advisories       []YarnIssue         `json:"advisories"`
+	BerryAdvisories  []YarnBerryAdvisory `json:"berryAdvisories"`
+	Metadata         Metadata            `json:"metadata"`
 	YarnLockNotFound bool
 	YarnErrorRunning bool
 }
@@ -30,6 +32,8 @@ type YarnIssue struct {
 	Severity           string        `json:"severity"`
 	Overview           string        `json:"overview"`
 	Title              string        `json:"title"`
+	CVEs               []string      `json:"cves"`
+	CVSS               CVSS          `json:"cvss"`
 }
 
 // YarnFinding holds the version of a given yarn security issue found
@@ -37,6 +41,21 @@ type YarnFinding struct {
 	Version string `json:"version"`
 }
 
+// YarnBerryAdvisory is an advisory of a yarn berry report, affecting the package named Value.
+type YarnBerryAdvisory struct {
+	Value    string                   `json:"value"`
+	Children YarnBerryAdvisoryDetails `json:"children"`
+}
+
+// YarnBerryAdvisoryDetails holds the fields yarn npm audit reports for an advisory.
+type YarnBerryAdvisoryDetails struct {
+	Issue              string   `json:"Issue"`
+	URL                string   `json:"URL"`
+	Severity           string   `json:"Severity"`
+	VulnerableVersions string   `json:"Vulnerable Versions"`
+	TreeVersions       []string `json:"Tree Versions"`
+}
+
 // YarnMetadata is the struct that holds vulnerabilities summary
 type YarnMetadata struct {
 	Vulnerabilities YarnVulnerabilitiesSummary `json:"vulnerabilities"`
@@ -82,7 +101,7 @@ func analyzeYarnaudit(yarnAuditScan *SecTestScanInfo) error {
 
 	// Unmarshall rawOutput into finalOutput, that is a YarnAuditOutput struct.
 	if err := json.Unmarshal([]byte(yarnAuditScan.Container.COutput), &yarnAuditOutput); err != nil {
-		log.Error("analyzeYarnaudit", "YARNAUDIT", 1036, yarnAuditScan.Container.COutput, err)
+		yarnAuditScan.logger().Error("analyzeYarnaudit", "YARNAUDIT", 1036, yarnAuditScan.Container.COutput, err)
 		return err
 	}
 	yarnAuditScan.FinalOutput = yarnAuditOutput
@@ -122,6 +141,11 @@ func (yarnAuditScan *SecTestScanInfo) prepareYarnAuditVulns() {
 		return
 	}
 
+	if len(yarnAuditOutput.BerryAdvisories) > 0 {
+		yarnAuditScan.Vulnerabilities = yarnAuditBerryVulns(yarnAuditOutput)
+		return
+	}
+
 	for _, issue := range yarnAuditOutput.Advisories {
 		yarnauditVuln := types.HuskyCIVulnerability{}
 		yarnauditVuln.Language = "JavaScript"
@@ -130,24 +154,25 @@ func (yarnAuditScan *SecTestScanInfo) prepareYarnAuditVulns() {
 		yarnauditVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", issue.ModuleName, issue.VulnerableVersions, issue.Title)
 		yarnauditVuln.VunerableBelow = issue.VulnerableVersions
 		yarnauditVuln.Code = issue.ModuleName
+		yarnauditVuln.CVE = strings.Join(issue.CVEs, ", ")
+		yarnauditVuln.CVSSScore = issue.CVSS.Score
+		yarnauditVuln.CVSSVector = issue.CVSS.VectorString
 		yarnauditVuln.Occurrences = 1
 		for _, findings := range issue.Findings {
 			yarnauditVuln.Version = findings.Version
 		}
 
-		switch issue.Severity {
-		case "info", "low":
-			yarnauditVuln.Severity = "low"
+		yarnauditVuln.Severity = auditSeverity(issue.Severity, issue.CVSS)
+		switch yarnauditVuln.Severity {
+		case "low":
 			if !vulnListContains(huskyCIyarnauditResults.LowVulns, yarnauditVuln) {
 				huskyCIyarnauditResults.LowVulns = append(huskyCIyarnauditResults.LowVulns, yarnauditVuln)
 			}
-		case "moderate":
-			yarnauditVuln.Severity = "medium"
+		case "medium":
 			if !vulnListContains(huskyCIyarnauditResults.MediumVulns, yarnauditVuln) {
 				huskyCIyarnauditResults.MediumVulns = append(huskyCIyarnauditResults.MediumVulns, yarnauditVuln)
 			}
-		case "high", "critical":
-			yarnauditVuln.Severity = "high"
+		case "high":
 			if !vulnListContains(huskyCIyarnauditResults.HighVulns, yarnauditVuln) {
 				huskyCIyarnauditResults.HighVulns = append(huskyCIyarnauditResults.HighVulns, yarnauditVuln)
 			}
@@ -158,6 +183,50 @@ func (yarnAuditScan *SecTestScanInfo) prepareYarnAuditVulns() {
 	yarnAuditScan.Vulnerabilities = huskyCIyarnauditResults
 }
 
+// yarnAuditBerryVulns returns the advisories of a yarn berry report. It has no CVSS data, so the
+// severity is the one of the advisory and the CVEs are those mentioned in its title.
+func yarnAuditBerryVulns(yarnAuditOutput YarnAuditOutput) types.HuskyCISecurityTestOutput {
+
+	huskyCIyarnauditResults := types.HuskyCISecurityTestOutput{}
+
+	for _, advisory := range yarnAuditOutput.BerryAdvisories {
+		issue := advisory.Children
+		yarnauditVuln := types.HuskyCIVulnerability{}
+		yarnauditVuln.Language = "JavaScript"
+		yarnauditVuln.SecurityTool = "YarnAudit"
+		yarnauditVuln.Title = fmt.Sprintf("Vulnerable Dependency: %s %s (%s)", advisory.Value, issue.VulnerableVersions, issue.Issue)
+		yarnauditVuln.Details = issue.Issue
+		if issue.URL != "" {
+			yarnauditVuln.Details = fmt.Sprintf("%s. More info: %s", issue.Issue, issue.URL)
+		}
+		yarnauditVuln.VunerableBelow = issue.VulnerableVersions
+		yarnauditVuln.Code = advisory.Value
+		yarnauditVuln.CVE = strings.Join(findCVEs(issue.Issue), ", ")
+		yarnauditVuln.Occurrences = 1
+		for _, version := range issue.TreeVersions {
+			yarnauditVuln.Version = version
+		}
+
+		yarnauditVuln.Severity = auditSeverity(issue.Severity, CVSS{})
+		switch yarnauditVuln.Severity {
+		case "low":
+			if !vulnListContains(huskyCIyarnauditResults.LowVulns, yarnauditVuln) {
+				huskyCIyarnauditResults.LowVulns = append(huskyCIyarnauditResults.LowVulns, yarnauditVuln)
+			}
+		case "medium":
+			if !vulnListContains(huskyCIyarnauditResults.MediumVulns, yarnauditVuln) {
+				huskyCIyarnauditResults.MediumVulns = append(huskyCIyarnauditResults.MediumVulns, yarnauditVuln)
+			}
+		case "high":
+			if !vulnListContains(huskyCIyarnauditResults.HighVulns, yarnauditVuln) {
+				huskyCIyarnauditResults.HighVulns = append(huskyCIyarnauditResults.HighVulns, yarnauditVuln)
+			}
+		}
+	}
+
+	return huskyCIyarnauditResults
+}
+
 // vulnListContains increments the occurrence counter in case a vulnerability is found again
 func vulnListContains(vulnList []types.HuskyCIVulnerability, vuln types.HuskyCIVulnerability) bool {
 	for i := range vulnList {
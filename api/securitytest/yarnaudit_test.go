@@ -0,0 +1,78 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package securitytest_test
+
+import (
+	"io/ioutil"
+
+	"github.com/globocom/huskyCI/api/securitytest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("YarnAudit", func() {
+	Describe("Parse", func() {
+		rawOutput, err := ioutil.ReadFile("testdata/yarnaudit_output.json")
+		It("Should read the fixture.", func() {
+			Expect(err).To(BeNil())
+		})
+
+		Context("When the advisories have CVSS data", func() {
+			It("Should keep the CVE ids and CVSS scores of the advisories.", func() {
+				output, err := securitytest.Parse("yarnaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Code).To(Equal("minimist"))
+				Expect(output.MediumVulns[0].Occurrences).To(Equal(2))
+				Expect(output.MediumVulns[0].CVE).To(Equal("CVE-2020-7598"))
+				Expect(output.MediumVulns[0].CVSSScore).To(Equal(5.6))
+				Expect(output.MediumVulns[0].CVSSVector).To(Equal("CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:L/A:L"))
+			})
+
+			It("Should derive the severity from the CVSS score when yarn audit gives none.", func() {
+				output, err := securitytest.Parse("yarnaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Code).To(Equal("yargs-parser"))
+				Expect(output.LowVulns[0].Severity).To(Equal("low"))
+			})
+		})
+
+		Context("When an advisory has neither a severity nor CVSS data", func() {
+			It("Should not report it.", func() {
+				output, err := securitytest.Parse("yarnaudit", string(rawOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(BeEmpty())
+				for _, vuln := range append(output.MediumVulns, output.LowVulns...) {
+					Expect(vuln.Code).ToNot(Equal("left-pad"))
+				}
+			})
+		})
+
+		Context("When the report is one of yarn berry", func() {
+			rawBerryOutput, err := ioutil.ReadFile("testdata/yarnaudit_berry_output.json")
+			It("Should read the fixture.", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("Should map each advisory into a finding by its severity.", func() {
+				output, err := securitytest.Parse("yarnaudit", string(rawBerryOutput))
+				Expect(err).To(BeNil())
+				Expect(output.HighVulns).To(HaveLen(1))
+				Expect(output.HighVulns[0].Code).To(Equal("minimist"))
+				Expect(output.HighVulns[0].Occurrences).To(Equal(2))
+				Expect(output.HighVulns[0].VunerableBelow).To(Equal("<0.2.4"))
+				Expect(output.HighVulns[0].Details).To(Equal("Prototype Pollution in minimist. More info: https://github.com/advisories/GHSA-xvch-5gv4-984h"))
+				Expect(output.MediumVulns).To(HaveLen(1))
+				Expect(output.MediumVulns[0].Code).To(Equal("node-fetch"))
+				Expect(output.MediumVulns[0].Version).To(Equal("2.6.1"))
+				Expect(output.MediumVulns[0].CVE).To(Equal("CVE-2022-0235"))
+				Expect(output.LowVulns).To(HaveLen(1))
+				Expect(output.LowVulns[0].Code).To(Equal("ansi-regex"))
+			})
+		})
+	})
+})
@@ -0,0 +1,136 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package selftest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/globocom/huskyCI/api/db"
+	docker "github.com/globocom/huskyCI/api/dockers"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/types"
+)
+
+const logActionSelfTest = "SelfTest"
+const logInfoSelfTest = "SELFTEST"
+
+// Components checked by Run, in the order they are checked.
+const (
+	ComponentDB           = "db"
+	ComponentDocker       = "docker"
+	ComponentRegistry     = "registry"
+	ComponentSecurityTest = "securityTest"
+)
+
+// fixtureCmd runs enry against a tiny Go project written inside the container, so that the sample run
+// needs no repository to be cloned.
+const fixtureCmd = `mkdir -p /tmp/huskyci-selftest &&
+cd /tmp/huskyci-selftest &&
+printf 'package main\n\nfunc main() {}\n' > main.go &&
+enry --json | tr -d '\r\n'`
+
+const fixtureLanguage = "Go"
+
+// Check is the result of checking one of the components huskyCI depends on.
+type Check struct {
+	Component string `json:"component"`
+	Passed    bool   `json:"passed"`
+	Detail    string `json:"detail"`
+}
+
+// Report holds the result of every component checked by Run. Passed is true only when all of them passed.
+type Report struct {
+	Passed bool    `json:"passed"`
+	Checks []Check `json:"checks"`
+}
+
+// Dependencies are the components checked by Run.
+type Dependencies struct {
+	DB         db.Requests
+	DockerPing func() error
+	Registry   docker.ImageRegistry
+	RunFixture func(securityTest types.SecurityTest, cmd string) (string, error)
+	Enry       types.SecurityTest
+}
+
+// NewDependencies returns the Dependencies of a running huskyCI, using dbInstance and the enry securityTest.
+func NewDependencies(dbInstance db.Requests, enry types.SecurityTest) Dependencies {
+	return Dependencies{
+		DB:         dbInstance,
+		DockerPing: docker.HealthCheckDockerAPI,
+		Registry:   docker.NewRegistry(),
+		RunFixture: runFixture,
+		Enry:       enry,
+	}
+}
+
+func runFixture(securityTest types.SecurityTest, cmd string) (string, error) {
+	runInfo, err := docker.DockerRun("", securityTest.Image, securityTest.ImageTag, cmd, nil, "", false, securityTest.TimeOutInSeconds, nil, log.Entry{})
+	return runInfo.Output, err
+}
+
+// Run checks DB, Docker, the registry of the enry image and a sample enry run against a built-in
+// fixture, reporting which of them passed. The sample run is skipped when Docker is not reachable.
+func Run(deps Dependencies) Report {
+	report := Report{Passed: true}
+	add := func(component string, detail string, err error) {
+		check := Check{Component: component, Passed: err == nil, Detail: detail}
+		if err != nil {
+			check.Detail = err.Error()
+			report.Passed = false
+			log.Warning(logActionSelfTest, logInfoSelfTest, 126, component, err)
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	detail, err := checkDB(deps)
+	add(ComponentDB, detail, err)
+	dockerErr := deps.DockerPing()
+	add(ComponentDocker, "Docker API is up and running", dockerErr)
+	detail, err = checkRegistry(deps)
+	add(ComponentRegistry, detail, err)
+	if dockerErr != nil {
+		add(ComponentSecurityTest, "", errors.New("skipped, as Docker API is not reachable"))
+	} else {
+		detail, err = checkSecurityTest(deps)
+		add(ComponentSecurityTest, detail, err)
+	}
+
+	log.Info(logActionSelfTest, logInfoSelfTest, 29, report.Passed)
+	return report
+}
+
+func checkDB(deps Dependencies) (string, error) {
+	securityTestQuery := map[string]interface{}{"name": deps.Enry.Name}
+	if _, err := deps.DB.FindOneDBSecurityTest(securityTestQuery); err != nil {
+		return "", fmt.Errorf("could not find securityTest %s: %v", deps.Enry.Name, err)
+	}
+	return fmt.Sprintf("securityTest %s found", deps.Enry.Name), nil
+}
+
+func checkRegistry(deps Dependencies) (string, error) {
+	digest, err := deps.Registry.LatestDigest(deps.Enry.Image, deps.Enry.ImageTag)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s is %s", deps.Enry.Image, deps.Enry.ImageTag, digest), nil
+}
+
+func checkSecurityTest(deps Dependencies) (string, error) {
+	output, err := deps.RunFixture(deps.Enry, fixtureCmd)
+	if err != nil {
+		return "", fmt.Errorf("could not run %s: %v", deps.Enry.Name, err)
+	}
+	languages := make(map[string][]string)
+	if err := json.Unmarshal([]byte(output), &languages); err != nil {
+		return "", fmt.Errorf("could not parse the output of %s: %v", deps.Enry.Name, err)
+	}
+	if len(languages[fixtureLanguage]) == 0 {
+		return "", fmt.Errorf("%s did not find the %s fixture file: %s", deps.Enry.Name, fixtureLanguage, output)
+	}
+	return fmt.Sprintf("%s found %s in the fixture", deps.Enry.Name, fixtureLanguage), nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package selftest_test
+
+import (
+	"errors"
+
+	"github.com/globocom/huskyCI/api/db"
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/selftest"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDB struct {
+	db.Requests
+	err error
+}
+
+func (f fakeDB) FindOneDBSecurityTest(mapParams map[string]interface{}) (types.SecurityTest, error) {
+	if f.err != nil {
+		return types.SecurityTest{}, f.err
+	}
+	return types.SecurityTest{Name: mapParams["name"].(string)}, nil
+}
+
+type fakeRegistry struct {
+	err error
+}
+
+func (f fakeRegistry) LatestDigest(image, tag string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	return "sha256:enry", nil
+}
+
+func checkOf(report selftest.Report, component string) selftest.Check {
+	for _, check := range report.Checks {
+		if check.Component == component {
+			return check
+		}
+	}
+	return selftest.Check{}
+}
+
+var _ = Describe("SelfTest", func() {
+
+	log.InitLog(true, "", "", "log_test", "log_test")
+
+	var deps selftest.Dependencies
+	var fixtureRuns int
+	BeforeEach(func() {
+		fixtureRuns = 0
+		deps = selftest.Dependencies{
+			DB:         fakeDB{},
+			DockerPing: func() error { return nil },
+			Registry:   fakeRegistry{},
+			RunFixture: func(securityTest types.SecurityTest, cmd string) (string, error) {
+				fixtureRuns++
+				return `{"Go":["main.go"]}`, nil
+			},
+			Enry: types.SecurityTest{Name: "enry", Image: "huskyci/enry", ImageTag: "dev-b78a58c"},
+		}
+	})
+
+	Context("When every component works", func() {
+		It("Should report all of them as passed.", func() {
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeTrue())
+			Expect(report.Checks).To(HaveLen(4))
+			for i, component := range []string{selftest.ComponentDB, selftest.ComponentDocker, selftest.ComponentRegistry, selftest.ComponentSecurityTest} {
+				Expect(report.Checks[i].Component).To(Equal(component))
+				Expect(report.Checks[i].Passed).To(BeTrue())
+			}
+			Expect(checkOf(report, selftest.ComponentRegistry).Detail).To(ContainSubstring("sha256:enry"))
+			Expect(fixtureRuns).To(Equal(1))
+		})
+	})
+
+	Context("When DB is not reachable", func() {
+		It("Should report only DB as failed, with its error.", func() {
+			deps.DB = fakeDB{err: errors.New("no reachable servers")}
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentDB).Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentDB).Detail).To(ContainSubstring("no reachable servers"))
+			Expect(checkOf(report, selftest.ComponentDocker).Passed).To(BeTrue())
+			Expect(checkOf(report, selftest.ComponentRegistry).Passed).To(BeTrue())
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Passed).To(BeTrue())
+		})
+	})
+
+	Context("When Docker API is not reachable", func() {
+		It("Should report Docker as failed and skip the sample run.", func() {
+			deps.DockerPing = func() error { return errors.New("connection refused") }
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentDocker).Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentDocker).Detail).To(Equal("connection refused"))
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Detail).To(ContainSubstring("skipped"))
+			Expect(fixtureRuns).To(Equal(0))
+		})
+	})
+
+	Context("When the registry rejects the authentication", func() {
+		It("Should report the registry as failed, with its error.", func() {
+			deps.Registry = fakeRegistry{err: errors.New("registry authentication returned status code 401")}
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentRegistry).Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentRegistry).Detail).To(ContainSubstring("401"))
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Passed).To(BeTrue())
+		})
+	})
+
+	Context("When the sample run fails", func() {
+		It("Should report the securityTest as failed, with its error.", func() {
+			deps.RunFixture = func(securityTest types.SecurityTest, cmd string) (string, error) {
+				return "", errors.New("timeout")
+			}
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Detail).To(ContainSubstring("timeout"))
+		})
+	})
+
+	Context("When the sample run does not find the fixture language", func() {
+		It("Should report the securityTest as failed.", func() {
+			deps.RunFixture = func(securityTest types.SecurityTest, cmd string) (string, error) {
+				return `{}`, nil
+			}
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Detail).To(ContainSubstring("did not find"))
+		})
+	})
+
+	Context("When the sample run returns an invalid output", func() {
+		It("Should report the securityTest as failed.", func() {
+			deps.RunFixture = func(securityTest types.SecurityTest, cmd string) (string, error) {
+				return "ERROR", nil
+			}
+			report := selftest.Run(deps)
+			Expect(report.Passed).To(BeFalse())
+			Expect(checkOf(report, selftest.ComponentSecurityTest).Detail).To(ContainSubstring("could not parse"))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sensitivepath
+
+import (
+	"path"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+var escalatedSeverities = map[string]string{
+	"low":    "medium",
+	"Low":    "Medium",
+	"LOW":    "MEDIUM",
+	"medium": "high",
+	"Medium": "High",
+	"MEDIUM": "HIGH",
+}
+
+// Match reports whether file is inside a path matched by pattern. A pattern without a
+// slash, such as crypto or crypto/, matches any file or directory with that name. A pattern with a
+// slash, such as src/auth, is matched against the leading directories of file. Patterns use path.Match syntax.
+func Match(pattern, file string) bool {
+	pattern = strings.Trim(pattern, "/")
+	file = strings.TrimPrefix(path.Clean("/"+file), "/")
+	if pattern == "" || file == "" {
+		return false
+	}
+	elements := strings.Split(file, "/")
+	for i, element := range elements {
+		target := element
+		if strings.Contains(pattern, "/") {
+			target = strings.Join(elements[:i+1], "/")
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Escalate raises by one level the severity of every vulnerability of results found in a file
+// matched by one of patterns, moving it to its new severity list. It returns true when a low severity
+// vulnerability was raised to medium, as it may fail an analysis that would otherwise pass.
+func Escalate(results *types.HuskyCIResults, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	isSensitive := func(vuln types.HuskyCIVulnerability) bool {
+		for _, pattern := range patterns {
+			if Match(pattern, vuln.File) {
+				return true
+			}
+		}
+		return false
+	}
+
+	raisedToMedium := false
+	for _, output := range util.SecurityTestOutputs(results) {
+		escalate := func(vulns []types.HuskyCIVulnerability) (kept, escalated []types.HuskyCIVulnerability) {
+			for _, vuln := range vulns {
+				severity, ok := escalatedSeverities[vuln.Severity]
+				if !ok || !isSensitive(vuln) {
+					kept = append(kept, vuln)
+					continue
+				}
+				vuln.Severity = severity
+				escalated = append(escalated, vuln)
+			}
+			return kept, escalated
+		}
+		medium, toHigh := escalate(output.MediumVulns)
+		low, toMedium := escalate(output.LowVulns)
+		output.HighVulns = append(output.HighVulns, toHigh...)
+		output.MediumVulns = append(medium, toMedium...)
+		output.LowVulns = low
+		if len(toMedium) > 0 {
+			raisedToMedium = true
+		}
+	}
+	return raisedToMedium
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sensitivepath_test
+
+import (
+	"github.com/globocom/huskyCI/api/sensitivepath"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SensitivePath", func() {
+
+	Describe("Match", func() {
+		Context("When the pattern has no slash inside", func() {
+			It("Should match a directory with that name at any depth.", func() {
+				Expect(sensitivepath.Match("auth/", "auth/login.go")).To(BeTrue())
+				Expect(sensitivepath.Match("crypto", "./code/internal/crypto/aes.go")).To(BeTrue())
+				Expect(sensitivepath.Match("*crypto*", "pkg/mycrypto/aes.go")).To(BeTrue())
+			})
+			It("Should not match a different name.", func() {
+				Expect(sensitivepath.Match("auth/", "author/login.go")).To(BeFalse())
+			})
+		})
+		Context("When the pattern has a slash inside", func() {
+			It("Should match the leading directories of the file.", func() {
+				Expect(sensitivepath.Match("src/auth/", "src/auth/login.go")).To(BeTrue())
+				Expect(sensitivepath.Match("src/auth", "lib/src/auth/login.go")).To(BeFalse())
+			})
+		})
+		Context("When the file is empty", func() {
+			It("Should not match.", func() {
+				Expect(sensitivepath.Match("auth/", "")).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Escalate", func() {
+		authLowVuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "LOW", File: "auth/login.go"}
+		authMediumVuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "MEDIUM", File: "auth/token.go"}
+		authHighVuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "auth/password.go"}
+		otherLowVuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "LOW", File: "main.go"}
+		cryptoLowVuln := types.HuskyCIVulnerability{SecurityTool: "Bandit", Severity: "low", File: "crypto/keys.py"}
+
+		newResults := func() types.HuskyCIResults {
+			results := types.HuskyCIResults{}
+			results.GoResults.HuskyCIGosecOutput.LowVulns = []types.HuskyCIVulnerability{authLowVuln, otherLowVuln}
+			results.GoResults.HuskyCIGosecOutput.MediumVulns = []types.HuskyCIVulnerability{authMediumVuln}
+			results.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{authHighVuln}
+			results.PythonResults.HuskyCIBanditOutput.LowVulns = []types.HuskyCIVulnerability{cryptoLowVuln}
+			return results
+		}
+
+		Context("When vulnerabilities are in sensitive paths", func() {
+			It("Should raise their severity by one level.", func() {
+				results := newResults()
+				Expect(sensitivepath.Escalate(&results, []string{"auth/", "crypto/"})).To(BeTrue())
+
+				escalatedLow := authLowVuln
+				escalatedLow.Severity = "MEDIUM"
+				escalatedMedium := authMediumVuln
+				escalatedMedium.Severity = "HIGH"
+				escalatedCrypto := cryptoLowVuln
+				escalatedCrypto.Severity = "medium"
+				Expect(results.GoResults.HuskyCIGosecOutput.LowVulns).To(Equal([]types.HuskyCIVulnerability{otherLowVuln}))
+				Expect(results.GoResults.HuskyCIGosecOutput.MediumVulns).To(Equal([]types.HuskyCIVulnerability{escalatedLow}))
+				Expect(results.GoResults.HuskyCIGosecOutput.HighVulns).To(Equal([]types.HuskyCIVulnerability{authHighVuln, escalatedMedium}))
+				Expect(results.PythonResults.HuskyCIBanditOutput.MediumVulns).To(Equal([]types.HuskyCIVulnerability{escalatedCrypto}))
+			})
+		})
+		Context("When no vulnerability is in a sensitive path", func() {
+			It("Should keep results unchanged.", func() {
+				results := newResults()
+				Expect(sensitivepath.Escalate(&results, []string{"payments/"})).To(BeFalse())
+				Expect(results).To(Equal(newResults()))
+			})
+		})
+		Context("When no path pattern is configured", func() {
+			It("Should keep results unchanged.", func() {
+				results := newResults()
+				Expect(sensitivepath.Escalate(&results, []string{})).To(BeFalse())
+				Expect(results).To(Equal(newResults()))
+			})
+		})
+	})
+})
@@ -5,18 +5,30 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/globocom/huskyCI/api/analysis"
 	"github.com/globocom/huskyCI/api/auth"
 	apiContext "github.com/globocom/huskyCI/api/context"
+	docker "github.com/globocom/huskyCI/api/dockers"
 	"github.com/globocom/huskyCI/api/log"
 	"github.com/globocom/huskyCI/api/routes"
+	"github.com/globocom/huskyCI/api/rpc"
+	"github.com/globocom/huskyCI/api/tlsconfig"
 	"github.com/globocom/huskyCI/api/util"
 	apiUtil "github.com/globocom/huskyCI/api/util/api"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -28,7 +40,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	log.InitLog(
+	log.InitLogFormat(
+		configAPI.LogFormat,
 		configAPI.GraylogConfig.DevelopmentEnv,
 		configAPI.GraylogConfig.Address,
 		configAPI.GraylogConfig.Protocol,
@@ -47,12 +60,47 @@ func main() {
 		os.Exit(1)
 	}
 
+	// with mutual TLS, clients without a valid certificate are rejected in the TLS handshake.
+	var tlsConfig *tls.Config
+	if configAPI.UseTLS {
+		tlsConfig, err = tlsconfig.Server(util.CertFile, util.KeyFile, configAPI.ClientCAPath)
+	} else if configAPI.ClientCAPath != "" {
+		err = errors.New("mutual TLS requires HUSKYCI_API_ENABLE_HTTPS")
+	}
+	if err != nil {
+		log.Error("main", "SERVER", 1077, err)
+		os.Exit(1)
+	}
+	mutualTLS := tlsConfig != nil && tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert
+	if mutualTLS {
+		log.Info("main", "SERVER", 46, configAPI.ClientCAPath)
+	}
+
+	docker.SetMaxRunningContainers(configAPI.MaxRunningContainers)
+	docker.SetImageAllowlist(configAPI.ImageAllowlist)
+	analysis.SetMaxRunningAnalyses(configAPI.MaxRunningAnalyses)
+	analysis.SetMaxQueuedAnalyses(configAPI.MaxQueuedAnalyses)
+
+	docker.CleanupLeftoverContainers()
+
+	if configAPI.ResumeAnalyses {
+		analysis.ResumeRunningAnalyses()
+	}
+
+	if configAPI.ImageUpdateCheckInterval > 0 {
+		docker.StartImageUpdateCheck(configAPI.ImageUpdateCheckInterval, apiUtil.ConfiguredSecurityTests(configAPI))
+	}
+
 	echoInstance := echo.New()
 	echoInstance.HideBanner = true
 
 	echoInstance.Use(middleware.Logger())
 	echoInstance.Use(middleware.Recover())
 	echoInstance.Use(middleware.RequestID())
+	if mutualTLS {
+		echoInstance.Use(routes.RequireClientCertificate)
+	}
+	echoInstance.Use(routes.RequireDatabase)
 
 	echoInstance.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{configAPI.AllowOriginValue},
@@ -68,18 +116,49 @@ func main() {
 	// /token route with basic auth
 	g.POST("/token", routes.HandleToken)
 	g.POST("/token/deactivate", routes.HandleDeactivation)
+	g.POST("/token/validate", routes.HandleTokenValidation)
+
+	// /securitytest/parse route with basic auth
+	g.POST("/securitytest/parse", routes.ParseSecurityTestOutput)
+
+	// /securitytest/updates route with basic auth
+	g.GET("/securitytest/updates", routes.GetSecurityTestImageUpdates)
+
+	// /repositories routes with basic auth, restricted to admins
+	g.GET("/repositories", routes.ListRepositories, routes.RequireAdmin)
+	g.DELETE("/repositories", routes.DeleteRepository, routes.RequireAdmin)
+
+	// /acceptedrisks routes with basic auth
+	g.POST("/acceptedrisks", routes.AcceptRisk)
+	g.GET("/acceptedrisks", routes.ListAcceptedRisks)
+
+	// /selftest route with basic auth, restricted to admins
+	g.POST("/selftest", routes.SelfTest, routes.RequireAdmin)
 
 	// generic routes
 	echoInstance.GET("/healthcheck", routes.HealthCheck)
 	echoInstance.GET("/version", routes.GetAPIVersion)
+	echoInstance.GET("/openapi.json", routes.GetOpenAPI)
 
 	// analysis routes
+	echoInstance.GET("/analysis", routes.ListAnalyses)
 	echoInstance.POST("/analysis", routes.ReceiveRequest)
+	echoInstance.POST("/analysis/plan", routes.PlanAnalysis)
+	echoInstance.POST("/analysis/upload", routes.ReceiveUpload)
+	echoInstance.GET("/analysis/trend", routes.GetAnalysisTrend)
+	echoInstance.GET("/analysis/compare", routes.CompareAnalyses)
 	echoInstance.GET("/analysis/:id", routes.GetAnalysis)
+	echoInstance.GET("/analysis/:id/output/:securityTestName", routes.GetAnalysisOutput)
+	echoInstance.POST("/analysis/:id/rerun", routes.RerunAnalysis)
+	echoInstance.POST("/analysis/:id/reevaluate", routes.ReevaluateAnalysis)
 	// echoInstance.PUT("/analysis/:id", routes.UpdateAnalysis)
 	// echoInstance.DELETE("/analysis/:id", routes.DeleteAnalysis)
 
+	// push webhook routes, authenticated by the signature of the webhook
+	echoInstance.POST("/webhook/:provider", routes.ReceiveWebhook)
+
 	// stats routes
+	echoInstance.GET("/stats/queue", routes.GetQueueStats)
 	echoInstance.GET("/stats/:metric_type", routes.GetMetric)
 
 	// securityTest routes
@@ -102,9 +181,90 @@ func main() {
 
 	huskyAPIport := fmt.Sprintf(":%d", configAPI.Port)
 
-	if !configAPI.UseTLS {
-		echoInstance.Logger.Fatal(echoInstance.Start(huskyAPIport))
-	} else {
-		echoInstance.Logger.Fatal(echoInstance.StartTLS(huskyAPIport, util.CertFile, util.KeyFile))
+	go func() {
+		var err error
+		if tlsConfig == nil {
+			err = echoInstance.Start(huskyAPIport)
+		} else {
+			echoInstance.TLSServer.Addr = huskyAPIport
+			echoInstance.TLSServer.TLSConfig = tlsConfig.Clone()
+			echoInstance.TLSServer.TLSConfig.NextProtos = []string{"h2"}
+			err = echoInstance.StartServer(echoInstance.TLSServer)
+		}
+		if err != http.ErrServerClosed {
+			echoInstance.Logger.Fatal(err)
+		}
+	}()
+
+	// with mutual TLS, the probes and the webhooks of the git hosts, presenting no client certificate,
+	// reach /healthcheck and /webhook/:provider on a port of their own.
+	var publicInstance *echo.Echo
+	if mutualTLS && configAPI.PublicPort > 0 {
+		publicTLSConfig, err := tlsconfig.Server(util.CertFile, util.KeyFile, "")
+		if err != nil {
+			log.Error("main", "SERVER", 1077, err)
+			os.Exit(1)
+		}
+		publicInstance = echo.New()
+		publicInstance.HideBanner = true
+		publicInstance.Use(middleware.Logger())
+		publicInstance.Use(middleware.Recover())
+		publicInstance.Use(middleware.RequestID())
+		publicInstance.Use(routes.RequireDatabase)
+		publicInstance.GET("/healthcheck", routes.HealthCheck)
+		publicInstance.POST("/webhook/:provider", routes.ReceiveWebhook)
+
+		go func() {
+			log.Info("main", "SERVER", 48, configAPI.PublicPort)
+			publicInstance.TLSServer.Addr = fmt.Sprintf(":%d", configAPI.PublicPort)
+			publicInstance.TLSServer.TLSConfig = publicTLSConfig
+			if err := publicInstance.StartServer(publicInstance.TLSServer); err != http.ErrServerClosed {
+				log.Error("main", "SERVER", 1080, err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// the gRPC API dispatches its calls to the routes of echoInstance.
+	var grpcServer *grpc.Server
+	if configAPI.GRPCPort > 0 {
+		grpcServer, err = rpc.NewGRPCServer(echoInstance, tlsConfig)
+		if err != nil {
+			log.Error("main", "SERVER", 1070, err)
+			os.Exit(1)
+		}
+		go func() {
+			log.Info("main", "SERVER", 41, configAPI.GRPCPort)
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", configAPI.GRPCPort))
+			if err == nil {
+				err = grpcServer.Serve(listener)
+			}
+			if err != nil {
+				log.Error("main", "SERVER", 1070, err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// SIGTERM, as sent on rolling deploys, stops new analyses while the API keeps answering
+	// about the running ones until they finish or the grace period is over.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, os.Interrupt)
+	<-quit
+	analysis.Drain(configAPI.ShutdownGracePeriod)
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := echoInstance.Shutdown(ctx); err != nil {
+		echoInstance.Logger.Error(err)
+	}
+	if publicInstance != nil {
+		if err := publicInstance.Shutdown(ctx); err != nil {
+			publicInstance.Logger.Error(err)
+		}
 	}
+	log.Info("main", "SERVER", 28)
 }
@@ -0,0 +1,87 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// MaxFileSize is the size, in bytes, of the biggest file code snippets are extracted from.
+const MaxFileSize = 1 << 20
+
+// MaxLineLength is the length, in bytes, the lines of a code snippet are cut at.
+const MaxLineLength = 240
+
+// Add sets the Snippet of the vulnerabilities of output to the contextLines lines of code before
+// and after their line, read by readFile from their file relative to the analyzed code. Vulnerabilities without
+// a line, found in a past commit or in a file that can not be read as text are left without a snippet.
+func Add(output *types.HuskyCISecurityTestOutput, readFile func(file string) ([]byte, error), contextLines int) {
+	contents := make(map[string][]byte)
+	for _, vulns := range [][]types.HuskyCIVulnerability{output.NoSecVulns, output.LowVulns, output.MediumVulns, output.HighVulns, output.AcceptedVulns} {
+		for i := range vulns {
+			line := lineNumber(vulns[i].Line)
+			if vulns[i].File == "" || vulns[i].CommitHash != "" || line == 0 {
+				continue
+			}
+			file := util.CodeRelativePath(vulns[i].File)
+			content, read := contents[file]
+			if !read {
+				content, _ = readFile(file)
+				contents[file] = content
+			}
+			vulns[i].Snippet = Code(content, line, contextLines)
+		}
+	}
+}
+
+// lineNumber returns the first line of line, which securityTests may report as a range such as 12-14,
+// or 0 if it holds none.
+func lineNumber(line string) int {
+	end := strings.IndexFunc(line, func(r rune) bool { return r < '0' || r > '9' })
+	if end == -1 {
+		end = len(line)
+	}
+	first, err := strconv.Atoi(line[:end])
+	if err != nil || first < 1 {
+		return 0
+	}
+	return first
+}
+
+// Code returns the lines of content from contextLines before line to contextLines after it, each one
+// prefixed by its number and cut at MaxLineLength. It returns an empty snippet when content is binary,
+// empty, bigger than MaxFileSize or shorter than line.
+func Code(content []byte, line, contextLines int) string {
+	if len(content) == 0 || len(content) > MaxFileSize || bytes.IndexByte(content, 0) != -1 || !utf8.Valid(content) {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := line-contextLines, line+contextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	snippet := make([]string, 0, last-first+1)
+	for number := first; number <= last; number++ {
+		code := strings.TrimRight(lines[number-1], "\r")
+		if len(code) > MaxLineLength {
+			code = strings.ToValidUTF8(code[:MaxLineLength], "") + "..."
+		}
+		snippet = append(snippet, fmt.Sprintf("%d: %s", number, code))
+	}
+	return strings.Join(snippet, "\n")
+}
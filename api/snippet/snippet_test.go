@@ -0,0 +1,70 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snippet_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/snippet"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snippet", func() {
+
+	Describe("Code", func() {
+		content := []byte("package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Exit(1)\n}\n")
+
+		It("Should return the numbered lines around the line, cut at the start and end of the file.", func() {
+			Expect(snippet.Code(content, 6, 1)).To(Equal("5: func main() {\n6: \tos.Exit(1)\n7: }"))
+			Expect(snippet.Code(content, 1, 2)).To(Equal("1: package main\n2: \n3: import \"os\""))
+		})
+		It("Should cut the lines longer than MaxLineLength.", func() {
+			long := strings.Repeat("a", snippet.MaxLineLength+10)
+			Expect(snippet.Code([]byte(long), 1, 0)).To(Equal("1: " + long[:snippet.MaxLineLength] + "..."))
+		})
+		It("Should return no snippet of a binary file or of a line beyond the end of the file.", func() {
+			Expect(snippet.Code([]byte("ELF\x00\x01\n"), 1, 2)).To(BeEmpty())
+			Expect(snippet.Code(content, 8, 2)).To(BeEmpty())
+			Expect(snippet.Code(nil, 1, 2)).To(BeEmpty())
+		})
+	})
+
+	Describe("Add", func() {
+		It("Should set the snippet of the vulnerabilities with a line in a readable file, reading each file once.", func() {
+			read := []string{}
+			readFile := func(file string) ([]byte, error) {
+				read = append(read, file)
+				if file == "main.go" {
+					return []byte("package main\nfunc main() {}\n"), nil
+				}
+				return nil, errors.New("no such file")
+			}
+			output := types.HuskyCISecurityTestOutput{
+				HighVulns: []types.HuskyCIVulnerability{
+					{Title: "ranged line", File: "/go/src/code/main.go", Line: "2-3"},
+					{Title: "past commit", File: "main.go", Line: "2", CommitHash: "4f53cda"},
+				},
+				MediumVulns: []types.HuskyCIVulnerability{
+					{Title: "missing file", File: "util.go", Line: "1"},
+					{Title: "dependency without a line", File: "go.mod"},
+				},
+				LowVulns: []types.HuskyCIVulnerability{
+					{Title: "relative file", File: "./main.go", Line: "1"},
+				},
+			}
+			snippet.Add(&output, readFile, 1)
+			Expect(output.HighVulns[0].Snippet).To(Equal("1: package main\n2: func main() {}"))
+			Expect(output.HighVulns[1].Snippet).To(BeEmpty())
+			Expect(output.MediumVulns[0].Snippet).To(BeEmpty())
+			Expect(output.MediumVulns[1].Snippet).To(BeEmpty())
+			Expect(output.LowVulns[0].Snippet).To(Equal("1: package main\n2: func main() {}"))
+			Expect(read).To(Equal([]string{"main.go", "util.go"}))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sorting
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+var severityOrder = map[string]int{
+	"high":   0,
+	"medium": 1,
+	"low":    2,
+}
+
+// Less reports whether a must be sorted before b. Vulnerabilities are sorted by securityTool,
+// severity, from high to low, CVSS score, from the highest, file, line, title and then by their remaining fields,
+// so that sorting the same vulnerabilities always gives the same order.
+func Less(a, b types.HuskyCIVulnerability) bool {
+	if a.SecurityTool != b.SecurityTool {
+		return a.SecurityTool < b.SecurityTool
+	}
+	if rankA, rankB := severityRank(a.Severity), severityRank(b.Severity); rankA != rankB {
+		return rankA < rankB
+	}
+	if a.Severity != b.Severity {
+		return a.Severity < b.Severity
+	}
+	if a.CVSSScore != b.CVSSScore {
+		return a.CVSSScore > b.CVSSScore
+	}
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		lineA, errA := strconv.Atoi(a.Line)
+		lineB, errB := strconv.Atoi(b.Line)
+		if errA == nil && errB == nil {
+			return lineA < lineB
+		}
+		return a.Line < b.Line
+	}
+	for _, fields := range [][2]string{
+		{a.Title, b.Title},
+		{a.Details, b.Details},
+		{a.Code, b.Code},
+		{a.Type, b.Type},
+		{a.Version, b.Version},
+		{a.Confidence, b.Confidence},
+		{a.CVE, b.CVE},
+		{a.CommitHash, b.CommitHash},
+	} {
+		if fields[0] != fields[1] {
+			return fields[0] < fields[1]
+		}
+	}
+	return false
+}
+
+func severityRank(severity string) int {
+	if rank, ok := severityOrder[strings.ToLower(severity)]; ok {
+		return rank
+	}
+	return len(severityOrder)
+}
+
+// Results sorts every vulnerability list of results using Less.
+func Results(results *types.HuskyCIResults) {
+	for _, output := range util.SecurityTestOutputs(results) {
+		for _, vulns := range [][]types.HuskyCIVulnerability{output.NoSecVulns, output.LowVulns, output.MediumVulns, output.HighVulns, output.AcceptedVulns} {
+			sort.SliceStable(vulns, func(i, j int) bool {
+				return Less(vulns[i], vulns[j])
+			})
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sorting_test
+
+import (
+	"encoding/json"
+
+	"github.com/globocom/huskyCI/api/sorting"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sorting", func() {
+
+	Describe("Results", func() {
+		vulns := []types.HuskyCIVulnerability{
+			{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "10", Title: "G101"},
+			{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "9", Title: "G104"},
+			{SecurityTool: "GoSec", Severity: "HIGH", File: "api/server.go", Line: "30", Title: "G101"},
+			{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "10", Title: "G102"},
+			{SecurityTool: "Bandit", Severity: "HIGH", File: "main.py", Line: "1", Title: "B105"},
+		}
+		newResults := func(order []int) types.HuskyCIResults {
+			results := types.HuskyCIResults{}
+			for _, i := range order {
+				results.GoResults.HuskyCIGosecOutput.HighVulns = append(results.GoResults.HuskyCIGosecOutput.HighVulns, vulns[i])
+			}
+			return results
+		}
+
+		Context("When vulnerabilities are in any order", func() {
+			It("Should sort them by tool, severity, file, line and title.", func() {
+				results := newResults([]int{0, 1, 2, 3, 4})
+				sorting.Results(&results)
+				Expect(results.GoResults.HuskyCIGosecOutput.HighVulns).To(Equal([]types.HuskyCIVulnerability{vulns[4], vulns[2], vulns[1], vulns[0], vulns[3]}))
+			})
+		})
+		Context("When the same input is aggregated repeatedly", func() {
+			It("Should always give byte-identical results.", func() {
+				sorted := newResults([]int{0, 1, 2, 3, 4})
+				sorting.Results(&sorted)
+				want, err := json.Marshal(sorted)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, order := range [][]int{{4, 3, 2, 1, 0}, {2, 0, 4, 1, 3}, {3, 4, 0, 2, 1}} {
+					results := newResults(order)
+					sorting.Results(&results)
+					got, err := json.Marshal(results)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(got).To(Equal(want))
+				}
+			})
+		})
+	})
+
+	Describe("Less", func() {
+		It("Should sort higher severities first.", func() {
+			high := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "z.go"}
+			low := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "low", File: "a.go"}
+			Expect(sorting.Less(high, low)).To(BeTrue())
+			Expect(sorting.Less(low, high)).To(BeFalse())
+		})
+		It("Should sort higher CVSS scores first within a severity.", func() {
+			critical := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", Severity: "high", CVSSScore: 9.8, File: "z"}
+			high := types.HuskyCIVulnerability{SecurityTool: "NpmAudit", Severity: "high", CVSSScore: 7.5, File: "a"}
+			Expect(sorting.Less(critical, high)).To(BeTrue())
+			Expect(sorting.Less(high, critical)).To(BeFalse())
+		})
+		It("Should compare lines as numbers.", func() {
+			line2 := types.HuskyCIVulnerability{File: "main.go", Line: "2"}
+			line10 := types.HuskyCIVulnerability{File: "main.go", Line: "10"}
+			Expect(sorting.Less(line2, line10)).To(BeTrue())
+		})
+	})
+})
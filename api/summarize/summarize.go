@@ -0,0 +1,32 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package summarize
+
+import (
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// Results counts the vulnerabilities of results by severity and by securityTest. Vulnerabilities
+// marked as nosec are only counted as suppressed and the ones whose risk was accepted as accepted.
+func Results(results types.HuskyCIResults) types.AnalysisSummary {
+	summary := types.AnalysisSummary{
+		BySeverity:     map[string]int{"high": 0, "medium": 0, "low": 0},
+		BySecurityTest: map[string]int{},
+	}
+	for _, output := range util.NamedSecurityTestOutputs(&results) {
+		count := len(output.HighVulns) + len(output.MediumVulns) + len(output.LowVulns)
+		summary.BySeverity["high"] += len(output.HighVulns)
+		summary.BySeverity["medium"] += len(output.MediumVulns)
+		summary.BySeverity["low"] += len(output.LowVulns)
+		if count > 0 {
+			summary.BySecurityTest[output.Name] = count
+		}
+		summary.Total += count
+		summary.Suppressed += len(output.NoSecVulns)
+		summary.Accepted += len(output.AcceptedVulns)
+	}
+	return summary
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package summarize_test
+
+import (
+	"github.com/globocom/huskyCI/api/summarize"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Summarize", func() {
+
+	Describe("Results", func() {
+		Context("When the results have no vulnerabilities", func() {
+			It("Should return zero counts.", func() {
+				Expect(summarize.Results(types.HuskyCIResults{})).To(Equal(types.AnalysisSummary{
+					BySeverity:     map[string]int{"high": 0, "medium": 0, "low": 0},
+					BySecurityTest: map[string]int{},
+				}))
+			})
+		})
+		Context("When the results have vulnerabilities", func() {
+			It("Should count them by severity and securityTest, counting nosec ones as suppressed only.", func() {
+				results := types.HuskyCIResults{}
+				results.GoResults.HuskyCIGosecOutput.NoSecVulns = []types.HuskyCIVulnerability{{Title: "nosec"}}
+				results.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{{Title: "high"}, {Title: "other high"}}
+				results.GoResults.HuskyCIGosecOutput.LowVulns = []types.HuskyCIVulnerability{{Title: "low"}}
+				results.GenericResults.HuskyCITrivyOutput.MediumVulns = []types.HuskyCIVulnerability{{Title: "medium"}}
+				Expect(summarize.Results(results)).To(Equal(types.AnalysisSummary{
+					Total:          4,
+					BySeverity:     map[string]int{"high": 2, "medium": 1, "low": 1},
+					BySecurityTest: map[string]int{"gosec": 3, "trivy": 1},
+					Suppressed:     1,
+				}))
+			})
+		})
+		Context("When the results have vulnerabilities of securityTest instances", func() {
+			It("Should count them under the name of each instance.", func() {
+				results := types.HuskyCIResults{}
+				results.PythonResults.HuskyCIBanditOutput.HighVulns = []types.HuskyCIVulnerability{{Title: "high"}}
+				results.Instances = map[string]*types.HuskyCISecurityTestOutput{
+					"bandittests": {LowVulns: []types.HuskyCIVulnerability{{Title: "low"}, {Title: "other low"}}},
+				}
+				Expect(summarize.Results(results)).To(Equal(types.AnalysisSummary{
+					Total:          3,
+					BySeverity:     map[string]int{"high": 1, "medium": 0, "low": 2},
+					BySecurityTest: map[string]int{"bandit": 1, "bandittests": 2},
+				}))
+			})
+		})
+	})
+})
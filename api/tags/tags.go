@@ -0,0 +1,78 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tags
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/log"
+	"github.com/labstack/echo"
+)
+
+const logInfoAnalysis = "ANALYSIS"
+const logActionReceiveRequest = "ReceiveRequest"
+
+// MaxCount is the most tags an analysis may be submitted with, and MaxValueLength the longest value of a tag.
+const (
+	MaxCount       = 20
+	MaxValueLength = 256
+)
+
+var regexpTagKey = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Validate verifies that tags are at most MaxCount, each one with a key made of letters, digits, _ and -,
+// so that it can be queried as tags.KEY, and a value of at most MaxValueLength characters.
+func Validate(tags map[string]string) error {
+	if len(tags) > MaxCount {
+		return fmt.Errorf("%d tags given, at most %d are allowed", len(tags), MaxCount)
+	}
+	for key, value := range tags {
+		if err := validate(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(key, value string) error {
+	if !regexpTagKey.MatchString(key) {
+		return fmt.Errorf("invalid tag key %q", key)
+	}
+	if len(value) > MaxValueLength || strings.ContainsRune(value, 0) {
+		return fmt.Errorf("invalid value of tag %s", key)
+	}
+	return nil
+}
+
+// Check verifies, using Validate, the tags an analysis is submitted with.
+func Check(tags map[string]string, c echo.Context) error {
+	if err := Validate(tags); err != nil {
+		log.Error(logActionReceiveRequest, logInfoAnalysis, 1078, err)
+		reply := map[string]interface{}{"success": false, "error": "invalid tags"}
+		return c.JSON(http.StatusBadRequest, reply)
+	}
+	return nil
+}
+
+// ParseFilters returns the query matching the analyses holding every one of filters, each one a KEY:VALUE
+// pair, as a map of tags.KEY to VALUE.
+func ParseFilters(filters []string) (map[string]interface{}, error) {
+	query := make(map[string]interface{}, len(filters))
+	for _, filter := range filters {
+		i := strings.Index(filter, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid tag filter %q, expected KEY:VALUE", filter)
+		}
+		key, value := filter[:i], filter[i+1:]
+		if err := validate(key, value); err != nil {
+			return nil, err
+		}
+		query["tags."+key] = value
+	}
+	return query, nil
+}
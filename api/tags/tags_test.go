@@ -0,0 +1,74 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tags_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/tags"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tags", func() {
+
+	Describe("Validate", func() {
+		Context("When every tag has a valid key and value", func() {
+			It("Should return a nil error.", func() {
+				analysisTags := map[string]string{"team": "security", "pipeline-id": "1234", "environment": ""}
+				Expect(tags.Validate(analysisTags)).To(BeNil())
+				Expect(tags.Validate(nil)).To(BeNil())
+			})
+		})
+		Context("When a tag key is invalid", func() {
+			It("Should return an error.", func() {
+				for _, key := range []string{"", "team.name", "$where", "team name", strings.Repeat("k", 65)} {
+					Expect(tags.Validate(map[string]string{key: "value"})).To(HaveOccurred())
+				}
+			})
+		})
+		Context("When a tag value is too long", func() {
+			It("Should return an error.", func() {
+				Expect(tags.Validate(map[string]string{"team": strings.Repeat("v", tags.MaxValueLength+1)})).To(HaveOccurred())
+			})
+		})
+		Context("When there are too many tags", func() {
+			It("Should return an error.", func() {
+				analysisTags := map[string]string{}
+				for i := 0; i <= tags.MaxCount; i++ {
+					analysisTags[fmt.Sprintf("tag%d", i)] = "value"
+				}
+				Expect(tags.Validate(analysisTags)).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("ParseFilters", func() {
+		Context("When every filter is a valid KEY:VALUE pair", func() {
+			It("Should return the query of the tags.", func() {
+				query, err := tags.ParseFilters([]string{"team:security", "pipeline:build:42"})
+				Expect(err).To(BeNil())
+				Expect(query).To(Equal(map[string]interface{}{"tags.team": "security", "tags.pipeline": "build:42"}))
+			})
+		})
+		Context("When no filter is given", func() {
+			It("Should return an empty query.", func() {
+				query, err := tags.ParseFilters(nil)
+				Expect(err).To(BeNil())
+				Expect(query).To(BeEmpty())
+			})
+		})
+		Context("When a filter is invalid", func() {
+			It("Should return an error.", func() {
+				for _, filter := range []string{"team", ":security", "team.name:security", "team';--:security"} {
+					_, err := tags.ParseFilters([]string{filter})
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+	})
+})
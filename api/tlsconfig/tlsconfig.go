@@ -0,0 +1,69 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ParseCACertificates returns the number of X.509 certificates in the PEM content given and their PEM encoding,
+// free of anything but the certificates. It returns an error if any block is not a valid certificate.
+func ParseCACertificates(content string) (int, string, error) {
+	rest := []byte(content)
+	encoded := []string{}
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return 0, "", fmt.Errorf("PEM block %d is a %s, not a CERTIFICATE", len(encoded)+1, block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return 0, "", fmt.Errorf("PEM block %d is not a valid certificate: %s", len(encoded)+1, err)
+		}
+		encoded = append(encoded, strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: block.Bytes}))))
+	}
+	if strings.TrimSpace(string(rest)) != "" {
+		return 0, "", errors.New("content after the last certificate is not PEM encoded")
+	}
+	if len(encoded) == 0 {
+		return 0, "", errors.New("no PEM encoded certificate found")
+	}
+	return len(encoded), strings.Join(encoded, "\n"), nil
+}
+
+// Server returns the TLS configuration of the API serving the certificate of certFile and keyFile.
+// When clientCAPath is set, it requires mutual TLS: clients have to present a certificate issued by one of
+// the CA certificates of clientCAPath, or their TLS handshake fails.
+func Server(certFile, keyFile, clientCAPath string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}}
+	if clientCAPath == "" {
+		return tlsConfig, nil
+	}
+	content, err := ioutil.ReadFile(clientCAPath)
+	if err != nil {
+		return nil, err
+	}
+	_, certificates, err := ParseCACertificates(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("client CA certificates: %s", err)
+	}
+	tlsConfig.ClientCAs = x509.NewCertPool()
+	tlsConfig.ClientCAs.AppendCertsFromPEM([]byte(certificates))
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
@@ -11,13 +11,20 @@ import (
 
 	apiContext "github.com/globocom/huskyCI/api/context"
 	"github.com/globocom/huskyCI/api/types"
-	"github.com/globocom/huskyCI/api/util"
 	"github.com/google/uuid"
+	mgo "gopkg.in/mgo.v2"
 )
 
-// ValidateURL validates if an URL is malicious or not.
+// ValidateURL validates if an URL is from a trusted git host and
+// returns its canonical https form.
 func (tC *TCaller) ValidateURL(url string) (string, error) {
-	return util.CheckMaliciousRepoURL(url)
+	return NormalizeRepoURL(url, apiContext.APIConfiguration.TrustedGitHosts)
+}
+
+// IsPermittedRepoURL checks if access tokens can be generated for a
+// validated URL, according to the repository allowlist.
+func (tC *TCaller) IsPermittedRepoURL(url string) bool {
+	return IsPermittedRepo(url, apiContext.APIConfiguration.TokenRepositoryAllowlist)
 }
 
 func generateRandomBytes() ([]byte, error) {
@@ -55,6 +62,47 @@ func (tC *TCaller) FindRepoURL(repositoryURL string) error {
 	return err
 }
 
+// FindAllAccessTokens gets every access token, valid or not.
+func (tC *TCaller) FindAllAccessTokens() ([]types.DBToken, error) {
+	accessTokens, err := apiContext.APIConfiguration.DBInstance.FindAllDBAccessToken(map[string]interface{}{})
+	if err != nil && err.Error() == "No data found" {
+		return []types.DBToken{}, nil
+	}
+	return accessTokens, err
+}
+
+// FindAllRepositories gets every repository that was analyzed.
+func (tC *TCaller) FindAllRepositories() ([]types.Repository, error) {
+	repositories, err := apiContext.APIConfiguration.DBInstance.FindAllDBRepository(map[string]interface{}{})
+	if err != nil && err.Error() == "No data found" {
+		return []types.Repository{}, nil
+	}
+	return repositories, err
+}
+
+// FindLastAnalysisDate gets the date the last analysis of a
+// repository finished, or a zero time if it has none.
+func (tC *TCaller) FindLastAnalysisDate(repositoryURL string) (time.Time, error) {
+	analysisQuery := map[string]interface{}{"repositoryURL": repositoryURL}
+	lastAnalysis, err := apiContext.APIConfiguration.DBInstance.FindLatestDBAnalysis(analysisQuery, time.Time{})
+	if err != nil {
+		if err == mgo.ErrNotFound || err.Error() == "No data found" {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return lastAnalysis.FinishedAt, nil
+}
+
+// DeleteRepository deletes a repository and all its access tokens from MongoDB.
+func (tC *TCaller) DeleteRepository(repositoryURL string) error {
+	repoQuery := map[string]interface{}{"repositoryURL": repositoryURL}
+	if err := apiContext.APIConfiguration.DBInstance.DeleteAllDBAccessToken(repoQuery); err != nil {
+		return err
+	}
+	return apiContext.APIConfiguration.DBInstance.DeleteAllDBRepository(repoQuery)
+}
+
 // GenerateUUID returns a new UUID.
 func (tC *TCaller) GenerateUUID() string {
 	return uuid.New().String()
@@ -0,0 +1,112 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// ErrRepositoryNotFound is returned when a repository
+// has neither access tokens nor analyses.
+var ErrRepositoryNotFound = errors.New("Repository not found")
+
+// ListRepositories will return a page of the registered
+// repositories, sorted by URL. A repository is registered
+// once it has an access token or an analysis. Each one
+// has how many of its access tokens are still valid and
+// the date of its last finished analysis. Pages start
+// at 1 and a page past the last one has no repositories.
+func (tH *THandler) ListRepositories(page, pageSize int) (types.RegisteredRepositoryPage, error) {
+	registered := make(map[string]*types.RegisteredRepository)
+	register := func(repositoryURL string) *types.RegisteredRepository {
+		if _, ok := registered[repositoryURL]; !ok {
+			registered[repositoryURL] = &types.RegisteredRepository{RepositoryURL: repositoryURL}
+		}
+		return registered[repositoryURL]
+	}
+	accessTokens, err := tH.External.FindAllAccessTokens()
+	if err != nil {
+		return types.RegisteredRepositoryPage{}, err
+	}
+	for _, accessToken := range accessTokens {
+		repository := register(accessToken.URL)
+		if accessToken.IsValid {
+			repository.ValidTokens++
+		} else {
+			repository.InvalidTokens++
+		}
+	}
+	repositories, err := tH.External.FindAllRepositories()
+	if err != nil {
+		return types.RegisteredRepositoryPage{}, err
+	}
+	for _, repository := range repositories {
+		register(repository.URL)
+	}
+
+	repositoryURLs := make([]string, 0, len(registered))
+	for repositoryURL := range registered {
+		repositoryURLs = append(repositoryURLs, repositoryURL)
+	}
+	sort.Strings(repositoryURLs)
+
+	repositoryPage := types.RegisteredRepositoryPage{
+		Repositories: []types.RegisteredRepository{},
+		Page:         page,
+		PageSize:     pageSize,
+		Total:        len(repositoryURLs),
+	}
+	start := (page - 1) * pageSize
+	if start >= len(repositoryURLs) {
+		return repositoryPage, nil
+	}
+	end := start + pageSize
+	if end > len(repositoryURLs) {
+		end = len(repositoryURLs)
+	}
+	for _, repositoryURL := range repositoryURLs[start:end] {
+		repository := registered[repositoryURL]
+		lastAnalysisAt, err := tH.External.FindLastAnalysisDate(repositoryURL)
+		if err != nil {
+			return types.RegisteredRepositoryPage{}, err
+		}
+		if !lastAnalysisAt.IsZero() {
+			repository.LastAnalysisAt = &lastAnalysisAt
+		}
+		repositoryPage.Repositories = append(repositoryPage.Repositories, *repository)
+	}
+	return repositoryPage, nil
+}
+
+// DeleteRepository will delete a registered repository
+// and all its access tokens, valid or not, so that an
+// access token has to be generated again to analyze it.
+// Its analyses are kept.
+func (tH *THandler) DeleteRepository(repositoryURL string) error {
+	accessTokens, err := tH.External.FindAllAccessTokens()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, accessToken := range accessTokens {
+		found = found || accessToken.URL == repositoryURL
+	}
+	if !found {
+		repositories, err := tH.External.FindAllRepositories()
+		if err != nil {
+			return err
+		}
+		for _, repository := range repositories {
+			found = found || repository.URL == repositoryURL
+		}
+	}
+	if !found {
+		return ErrRepositoryNotFound
+	}
+	return tH.External.DeleteRepository(repositoryURL)
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package token_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/globocom/huskyCI/api/token"
+	"github.com/globocom/huskyCI/api/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Repository", func() {
+	lastAnalysis := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	newFakeExternal := func() *FakeExternal {
+		return &FakeExternal{
+			expectedAccessTokens: []types.DBToken{
+				{URL: "https://github.com/globocom/b", IsValid: true},
+				{URL: "https://github.com/globocom/b", IsValid: false},
+				{URL: "https://github.com/globocom/a", IsValid: true},
+			},
+			expectedRepositories: []types.Repository{
+				{URL: "https://github.com/globocom/c"},
+				{URL: "https://github.com/globocom/a"},
+			},
+			expectedLastAnalysisDates: map[string]time.Time{
+				"https://github.com/globocom/a": lastAnalysis,
+			},
+		}
+	}
+
+	Describe("ListRepositories", func() {
+		Context("When the page has every registered repository", func() {
+			It("Should return them sorted by URL with their tokens and last analysis", func() {
+				fakeExt := newFakeExternal()
+				tokenHandler := THandler{External: fakeExt}
+				repositoryPage, err := tokenHandler.ListRepositories(1, 20)
+				Expect(err).To(BeNil())
+				Expect(repositoryPage.Total).To(Equal(3))
+				Expect(repositoryPage.Page).To(Equal(1))
+				Expect(repositoryPage.PageSize).To(Equal(20))
+				Expect(repositoryPage.Repositories).To(Equal([]types.RegisteredRepository{
+					{RepositoryURL: "https://github.com/globocom/a", ValidTokens: 1, LastAnalysisAt: &lastAnalysis},
+					{RepositoryURL: "https://github.com/globocom/b", ValidTokens: 1, InvalidTokens: 1},
+					{RepositoryURL: "https://github.com/globocom/c"},
+				}))
+			})
+		})
+		Context("When the page has only part of the registered repositories", func() {
+			It("Should only look up the last analysis of the repositories in the page", func() {
+				fakeExt := newFakeExternal()
+				tokenHandler := THandler{External: fakeExt}
+				repositoryPage, err := tokenHandler.ListRepositories(2, 2)
+				Expect(err).To(BeNil())
+				Expect(repositoryPage.Total).To(Equal(3))
+				Expect(repositoryPage.Repositories).To(HaveLen(1))
+				Expect(repositoryPage.Repositories[0].RepositoryURL).To(Equal("https://github.com/globocom/c"))
+				Expect(fakeExt.lastAnalysisLookups).To(Equal([]string{"https://github.com/globocom/c"}))
+			})
+		})
+		Context("When the page is past the last one", func() {
+			It("Should return no repositories", func() {
+				tokenHandler := THandler{External: newFakeExternal()}
+				repositoryPage, err := tokenHandler.ListRepositories(3, 2)
+				Expect(err).To(BeNil())
+				Expect(repositoryPage.Total).To(Equal(3))
+				Expect(repositoryPage.Repositories).To(BeEmpty())
+			})
+		})
+		Context("When the repositories can't be found", func() {
+			It("Should return the same error", func() {
+				fakeExt := newFakeExternal()
+				fakeExt.expectedFindAllError = errors.New("Failed to find repositories")
+				tokenHandler := THandler{External: fakeExt}
+				_, err := tokenHandler.ListRepositories(1, 20)
+				Expect(err).To(Equal(errors.New("Failed to find repositories")))
+			})
+		})
+	})
+
+	Describe("DeleteRepository", func() {
+		Context("When the repository is registered", func() {
+			It("Should delete it", func() {
+				fakeExt := newFakeExternal()
+				tokenHandler := THandler{External: fakeExt}
+				Expect(tokenHandler.DeleteRepository("https://github.com/globocom/c")).To(BeNil())
+				Expect(fakeExt.deletedRepositories).To(Equal([]string{"https://github.com/globocom/c"}))
+			})
+		})
+		Context("When the repository is not registered", func() {
+			It("Should return ErrRepositoryNotFound", func() {
+				fakeExt := newFakeExternal()
+				tokenHandler := THandler{External: fakeExt}
+				Expect(tokenHandler.DeleteRepository("https://github.com/globocom/d")).To(Equal(ErrRepositoryNotFound))
+				Expect(fakeExt.deletedRepositories).To(BeEmpty())
+			})
+		})
+		Context("When the deletion fails", func() {
+			It("Should return the same error", func() {
+				fakeExt := newFakeExternal()
+				fakeExt.expectedDeleteError = errors.New("Failed to delete")
+				tokenHandler := THandler{External: fakeExt}
+				Expect(tokenHandler.DeleteRepository("https://github.com/globocom/a")).To(Equal(errors.New("Failed to delete")))
+			})
+		})
+	})
+})
@@ -13,8 +13,14 @@ import (
 	"github.com/globocom/huskyCI/api/types"
 )
 
+// ErrRepositoryNotPermitted is returned when an access
+// token is requested for a repository outside the
+// repository allowlist.
+var ErrRepositoryNotPermitted = errors.New("Repository not permitted")
+
 // GenerateAccessToken will generate a valid access token
-// for a the requested repository URL. The access token
+// for a the requested repository URL, as long as it is
+// in the repository allowlist. The access token
 // consists in two parts. The first is the UUID that is
 // used for identification in DB. The second part is a
 // random data. The hash of the random data is stored
@@ -29,6 +35,9 @@ func (tH *THandler) GenerateAccessToken(repo types.TokenRequest) (string, error)
 	if validatedURL == "" {
 		return "", errors.New("Empty URL is not valid")
 	}
+	if !tH.External.IsPermittedRepoURL(validatedURL) {
+		return "", ErrRepositoryNotPermitted
+	}
 	token, err := tH.External.GenerateToken()
 	if err != nil {
 		return "", err
@@ -127,6 +136,48 @@ func (tH *THandler) ValidateToken(token, repositoryURL string) error {
 	return tH.ValidateRandomData(randomData, accessToken.HuskyToken, accessToken.Salt)
 }
 
+// ValidateTokens will validate each one of the received
+// token and repository URL pairs using ValidateToken.
+// Each access token is looked up only once in DB for
+// the whole batch. The returned errors are in the same
+// order of the received pairs, being nil for the valid
+// ones.
+func (tH *THandler) ValidateTokens(pairs []types.TokenValidationRequest) []error {
+	batchHandler := THandler{
+		External: &batchCalls{
+			ExternalCalls: tH.External,
+			accessTokens:  make(map[string]batchAccessToken),
+		},
+		HashGen: tH.HashGen,
+	}
+	errs := make([]error, len(pairs))
+	for i, pair := range pairs {
+		errs[i] = batchHandler.ValidateToken(pair.HuskyToken, pair.RepositoryURL)
+	}
+	return errs
+}
+
+// batchCalls wraps ExternalCalls caching the access
+// tokens found while validating a batch of them.
+type batchCalls struct {
+	ExternalCalls
+	accessTokens map[string]batchAccessToken
+}
+
+type batchAccessToken struct {
+	accessToken types.DBToken
+	err         error
+}
+
+func (bC *batchCalls) FindAccessToken(id string) (types.DBToken, error) {
+	if found, ok := bC.accessTokens[id]; ok {
+		return found.accessToken, found.err
+	}
+	accessToken, err := bC.ExternalCalls.FindAccessToken(id)
+	bC.accessTokens[id] = batchAccessToken{accessToken: accessToken, err: err}
+	return accessToken, err
+}
+
 // VerifyRepo will verify if exists an entry
 // for the received repository
 func (tH *THandler) VerifyRepo(repositoryURL string) error {
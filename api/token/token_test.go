@@ -20,6 +20,7 @@ import (
 type FakeExternal struct {
 	expectedURL               string
 	expectedValidateError     error
+	expectedNotPermitted      bool
 	expectedToken             string
 	expectedGenerateError     error
 	expectedTime              time.Time
@@ -32,6 +33,14 @@ type FakeExternal struct {
 	expectedDecodeToError     error
 	expectedUpdateAccessError error
 	returnedAccessToken       types.DBToken
+	expectedAccessTokens      []types.DBToken
+	expectedFindAllError      error
+	expectedRepositories      []types.Repository
+	expectedLastAnalysisDates map[string]time.Time
+	expectedLastAnalysisError error
+	expectedDeleteError       error
+	deletedRepositories       []string
+	lastAnalysisLookups       []string
 }
 
 type FakeHashGen struct {
@@ -49,6 +58,10 @@ func (fE *FakeExternal) ValidateURL(url string) (string, error) {
 	return fE.expectedURL, fE.expectedValidateError
 }
 
+func (fE *FakeExternal) IsPermittedRepoURL(url string) bool {
+	return !fE.expectedNotPermitted
+}
+
 func (fE *FakeExternal) GenerateToken() (string, error) {
 	return fE.expectedToken, fE.expectedGenerateError
 }
@@ -69,6 +82,24 @@ func (fE *FakeExternal) FindRepoURL(repositoryURL string) error {
 	return fE.expectedFindRepoError
 }
 
+func (fE *FakeExternal) FindAllAccessTokens() ([]types.DBToken, error) {
+	return fE.expectedAccessTokens, fE.expectedFindAllError
+}
+
+func (fE *FakeExternal) FindAllRepositories() ([]types.Repository, error) {
+	return fE.expectedRepositories, fE.expectedFindAllError
+}
+
+func (fE *FakeExternal) FindLastAnalysisDate(repositoryURL string) (time.Time, error) {
+	fE.lastAnalysisLookups = append(fE.lastAnalysisLookups, repositoryURL)
+	return fE.expectedLastAnalysisDates[repositoryURL], fE.expectedLastAnalysisError
+}
+
+func (fE *FakeExternal) DeleteRepository(repositoryURL string) error {
+	fE.deletedRepositories = append(fE.deletedRepositories, repositoryURL)
+	return fE.expectedDeleteError
+}
+
 func (fE *FakeExternal) GenerateUUID() string {
 	return fE.expectedUuid
 }
@@ -114,6 +145,31 @@ func (fH *FakeHashGen) GetCredsFromDB(username string) (types.User, error) {
 	return types.User{}, nil
 }
 
+// BatchFakeExternal finds access tokens by UUID, counting
+// how many times each one was looked up.
+type BatchFakeExternal struct {
+	FakeExternal
+	accessTokens map[string]types.DBToken
+	findCalls    map[string]int
+}
+
+func (fE *BatchFakeExternal) ValidateURL(url string) (string, error) {
+	return url, nil
+}
+
+func (fE *BatchFakeExternal) DecodeToStringBase64(encodedVal string) (string, error) {
+	return encodedVal, nil
+}
+
+func (fE *BatchFakeExternal) FindAccessToken(id string) (types.DBToken, error) {
+	fE.findCalls[id]++
+	accessToken, ok := fE.accessTokens[id]
+	if !ok {
+		return types.DBToken{}, errors.New("No data found")
+	}
+	return accessToken, nil
+}
+
 var _ = Describe("Token", func() {
 	Context("When URL validation returns an error", func() {
 		It("Should return the same error and an empty string", func() {
@@ -147,6 +203,24 @@ var _ = Describe("Token", func() {
 			Expect(err).To(Equal(errors.New("Empty URL is not valid")))
 		})
 	})
+	Context("When the validated URL is not permitted", func() {
+		It("Should return ErrRepositoryNotPermitted and an empty string", func() {
+			fakeExt := FakeExternal{
+				expectedURL:           "MyValidURL",
+				expectedValidateError: nil,
+				expectedNotPermitted:  true,
+			}
+			tokenGen := THandler{
+				External: &fakeExt,
+			}
+			accessToken, err := tokenGen.GenerateAccessToken(types.TokenRequest{
+				RepositoryURL: "myRepo.com",
+			})
+			Expect(accessToken).To(Equal(""))
+			Expect(err).To(Equal(ErrRepositoryNotPermitted))
+			Expect(err.Error()).To(Equal("Repository not permitted"))
+		})
+	})
 	Context("When GenerateToken returns an error", func() {
 		It("Should return the same error and an empty string", func() {
 			fakeExt := FakeExternal{
@@ -643,4 +717,76 @@ var _ = Describe("Token", func() {
 			})
 		})
 	})
+	Describe("ValidateTokens", func() {
+		Context("When the batch has valid, invalid and deactivated access tokens", func() {
+			fakeHash := FakeHashGen{
+				expectedDecodedSalt: []byte("MySaltDecoded"),
+				expectedHashName:    "Sha512",
+				expectedKeyLength:   256,
+				expectedHashValue:   "MyValidHash",
+			}
+			fakeExt := BatchFakeExternal{
+				accessTokens: map[string]types.DBToken{
+					"ValidUUID": {
+						IsValid:    true,
+						HuskyToken: "MyValidHash",
+						URL:        "MyRepo",
+						Salt:       "MySalt",
+					},
+					"DeactivatedUUID": {
+						IsValid:    false,
+						HuskyToken: "MyValidHash",
+						URL:        "MyRepo",
+						Salt:       "MySalt",
+					},
+					"WrongHashUUID": {
+						IsValid:    true,
+						HuskyToken: "AnotherHash",
+						URL:        "MyRepo",
+						Salt:       "MySalt",
+					},
+				},
+				findCalls: make(map[string]int),
+			}
+			tokenVal := THandler{
+				External: &fakeExt,
+				HashGen:  &fakeHash,
+			}
+			errs := tokenVal.ValidateTokens([]types.TokenValidationRequest{
+				{HuskyToken: "ValidUUID:RandomVal", RepositoryURL: "MyRepo"},
+				{HuskyToken: "ValidUUID:RandomVal", RepositoryURL: "AnotherRepo"},
+				{HuskyToken: "DeactivatedUUID:RandomVal", RepositoryURL: "MyRepo"},
+				{HuskyToken: "WrongHashUUID:RandomVal", RepositoryURL: "MyRepo"},
+				{HuskyToken: "UnknownUUID:RandomVal", RepositoryURL: "MyRepo"},
+				{HuskyToken: "InvalidFormat", RepositoryURL: "MyRepo"},
+				{HuskyToken: "ValidUUID:RandomVal", RepositoryURL: "MyRepo"},
+			})
+			It("Should return the result of ValidateToken for each pair in order", func() {
+				Expect(errs).To(HaveLen(7))
+				Expect(errs[0]).To(BeNil())
+				Expect(errs[1]).To(Equal(errors.New("Access token doesn't have permission to run analysis in the provided repository")))
+				Expect(errs[2]).To(Equal(errors.New("Access token is invalid")))
+				Expect(errs[3]).To(Equal(errors.New("Hash value from random data is different")))
+				Expect(errs[4]).To(Equal(errors.New("No data found")))
+				Expect(errs[5]).To(Equal(errors.New("Invalid access token format")))
+				Expect(errs[6]).To(BeNil())
+			})
+			It("Should look up each access token only once", func() {
+				Expect(fakeExt.findCalls).To(Equal(map[string]int{
+					"ValidUUID":       1,
+					"DeactivatedUUID": 1,
+					"WrongHashUUID":   1,
+					"UnknownUUID":     1,
+				}))
+			})
+		})
+		Context("When the batch is empty", func() {
+			It("Should return no errors", func() {
+				tokenVal := THandler{
+					External: &BatchFakeExternal{findCalls: make(map[string]int)},
+				}
+				Expect(tokenVal.ValidateTokens(nil)).To(BeEmpty())
+			})
+		})
+	})
 })
@@ -16,12 +16,17 @@ import (
 // necessary information about TokenHandler.
 type ExternalCalls interface {
 	ValidateURL(url string) (string, error)
+	IsPermittedRepoURL(url string) bool
 	GenerateToken() (string, error)
 	GetTimeNow() time.Time
 	StoreAccessToken(accessToken types.DBToken) error
 	FindAccessToken(id string) (types.DBToken, error)
 	UpdateAccessToken(id string, accesstoken types.DBToken) error
 	FindRepoURL(repositoryURL string) error
+	FindAllAccessTokens() ([]types.DBToken, error)
+	FindAllRepositories() ([]types.Repository, error)
+	FindLastAnalysisDate(repositoryURL string) (time.Time, error)
+	DeleteRepository(repositoryURL string) error
 	GenerateUUID() string
 	EncodeBase64(m string) string
 	DecodeToStringBase64(encodedVal string) (string, error)
@@ -0,0 +1,118 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var scpLikeURL = regexp.MustCompile(`^[\w.\-]+@([\w.\-]+):(.+)$`)
+var validHost = regexp.MustCompile(`^[a-z0-9.\-]+$`)
+var validPathSegment = regexp.MustCompile(`^[\w.\-~]+$`)
+
+// NormalizeRepoURL validates a repository URL and returns its
+// canonical https form, such as https://gitlab.example.com/group/repo.git.
+// It accepts https, http, ssh:// and ssh-style (git@host:org/repo.git)
+// URLs whose host is one of trustedHosts or a subdomain of one.
+func NormalizeRepoURL(repositoryURL string, trustedHosts []string) (string, error) {
+	host, repoPath, err := splitRepoURL(strings.TrimSpace(repositoryURL))
+	if err != nil {
+		return "", err
+	}
+	host = strings.ToLower(host)
+	if !validHost.MatchString(host) {
+		return "", fmt.Errorf("Invalid URL format: %s", repositoryURL)
+	}
+	if !isTrustedHost(host, trustedHosts) {
+		return "", fmt.Errorf("Untrusted git host: %s", host)
+	}
+
+	repoPath = strings.Trim(repoPath, "/")
+	repoPath = strings.Trim(strings.TrimSuffix(repoPath, ".git"), "/")
+	segments := strings.Split(repoPath, "/")
+	if len(segments) < 2 {
+		return "", fmt.Errorf("Invalid URL format: %s", repositoryURL)
+	}
+	for _, segment := range segments {
+		if segment == "." || segment == ".." || !validPathSegment.MatchString(segment) {
+			return "", fmt.Errorf("Invalid URL format: %s", repositoryURL)
+		}
+	}
+	return fmt.Sprintf("https://%s/%s.git", host, repoPath), nil
+}
+
+// splitRepoURL returns the host and the path of the repository of an URL.
+func splitRepoURL(repositoryURL string) (string, string, error) {
+	if !strings.Contains(repositoryURL, "://") {
+		matches := scpLikeURL.FindStringSubmatch(repositoryURL)
+		if matches == nil {
+			return "", "", fmt.Errorf("Invalid URL format: %s", repositoryURL)
+		}
+		return matches[1], matches[2], nil
+	}
+	parsedURL, err := url.Parse(repositoryURL)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid URL format: %s", repositoryURL)
+	}
+	switch parsedURL.Scheme {
+	case "https", "http", "ssh":
+	default:
+		return "", "", fmt.Errorf("Invalid URL scheme: %s", parsedURL.Scheme)
+	}
+	if parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+		return "", "", fmt.Errorf("Invalid URL format: %s", repositoryURL)
+	}
+	return parsedURL.Hostname(), parsedURL.Path, nil
+}
+
+// isTrustedHost returns whether host is one of trustedHosts or a subdomain of one.
+func isTrustedHost(host string, trustedHosts []string) bool {
+	for _, trustedHost := range trustedHosts {
+		trustedHost = strings.ToLower(strings.TrimSpace(trustedHost))
+		if trustedHost != "" && (host == trustedHost || strings.HasSuffix(host, "."+trustedHost)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPermittedRepo returns whether a repository URL, in the canonical form
+// returned by NormalizeRepoURL, matches one of the allowlist patterns. A
+// domain pattern, such as gitlab.example.com, permits every repository of
+// the domain and its subdomains. An org prefix pattern, such as
+// github.com/globocom, permits the repositories under that path. An empty
+// allowlist permits every repository.
+func IsPermittedRepo(repositoryURL string, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	host, repoPath, err := splitRepoURL(repositoryURL)
+	if err != nil {
+		return false
+	}
+	host = strings.ToLower(host)
+	repoPath = strings.ToLower(strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git"))
+	for _, pattern := range allowlist {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if i := strings.Index(pattern, "://"); i >= 0 {
+			pattern = pattern[i+3:]
+		}
+		pattern = strings.TrimSuffix(strings.Trim(pattern, "/"), ".git")
+		patternParts := strings.SplitN(pattern, "/", 2)
+		if len(patternParts) == 1 {
+			if isTrustedHost(host, patternParts) {
+				return true
+			}
+			continue
+		}
+		if host == patternParts[0] && (repoPath == patternParts[1] || strings.HasPrefix(repoPath, patternParts[1]+"/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package token_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/globocom/huskyCI/api/token"
+)
+
+var _ = Describe("NormalizeRepoURL", func() {
+	trustedHosts := []string{"github.com", "gitlab.com", "bitbucket.org", "example.com"}
+
+	Context("When the URL is in the canonical https form", func() {
+		It("Should return it unchanged", func() {
+			normalizedURL, err := NormalizeRepoURL("https://github.com/globocom/huskyCI.git", trustedHosts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(normalizedURL).To(Equal("https://github.com/globocom/huskyCI.git"))
+		})
+	})
+	Context("When the URL is ssh-style", func() {
+		It("Should normalize it to https", func() {
+			for sshURL, expected := range map[string]string{
+				"git@github.com:globocom/huskyCI.git":                "https://github.com/globocom/huskyCI.git",
+				"git@gitlab.com:group/subgroup/project.git":          "https://gitlab.com/group/subgroup/project.git",
+				"git@bitbucket.org:team/repo.git":                    "https://bitbucket.org/team/repo.git",
+				"gitlab@gitlab.example.com:security/huskyCI.git":     "https://gitlab.example.com/security/huskyCI.git",
+				"ssh://git@gitlab.example.com:2222/security/huskyCI": "https://gitlab.example.com/security/huskyCI.git",
+				"git@GitHub.com:globocom/huskyCI":                    "https://github.com/globocom/huskyCI.git",
+			} {
+				normalizedURL, err := NormalizeRepoURL(sshURL, trustedHosts)
+				Expect(err).NotTo(HaveOccurred(), sshURL)
+				Expect(normalizedURL).To(Equal(expected), sshURL)
+			}
+		})
+	})
+	Context("When the https URL has credentials or a trailing slash", func() {
+		It("Should drop them", func() {
+			normalizedURL, err := NormalizeRepoURL("https://user@bitbucket.org/team/repo.git/", trustedHosts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(normalizedURL).To(Equal("https://bitbucket.org/team/repo.git"))
+		})
+	})
+	Context("When the host is not trusted", func() {
+		It("Should return an error", func() {
+			for _, untrustedURL := range []string{
+				"git@gitlab.attacker.com:security/huskyCI.git",
+				"https://github.com.attacker.com/globocom/huskyCI.git",
+				"https://notexample.com/team/repo.git",
+			} {
+				_, err := NormalizeRepoURL(untrustedURL, trustedHosts)
+				Expect(err).To(HaveOccurred(), untrustedURL)
+			}
+		})
+		It("Should trust the public hosts only when they are in the allowlist", func() {
+			_, err := NormalizeRepoURL("https://github.com/globocom/huskyCI.git", []string{"gitlab.example.com"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("When the URL is malformed", func() {
+		It("Should return an error", func() {
+			for _, invalidURL := range []string{
+				"",
+				"github.com/globocom/huskyCI.git",
+				"https://github.com/huskyCI.git",
+				"https://github.com/globocom/../huskyCI.git",
+				"https://github.com/globocom/huskyCI.git?ref=master",
+				"ftp://github.com/globocom/huskyCI.git",
+				"git@github.com:globocom/husky CI.git",
+				"git@github.com:globocom/huskyCI.git;rm -rf /",
+			} {
+				_, err := NormalizeRepoURL(invalidURL, trustedHosts)
+				Expect(err).To(HaveOccurred(), invalidURL)
+			}
+		})
+	})
+})
+
+var _ = Describe("IsPermittedRepo", func() {
+	allowlist := []string{"gitlab.example.com", "github.com/globocom", "https://bitbucket.org/team/"}
+
+	Context("When the allowlist is empty", func() {
+		It("Should permit every repository", func() {
+			Expect(IsPermittedRepo("https://github.com/someone/repo.git", nil)).To(BeTrue())
+		})
+	})
+	Context("When the repository matches a domain pattern", func() {
+		It("Should permit the repositories of the domain and its subdomains", func() {
+			Expect(IsPermittedRepo("https://gitlab.example.com/security/huskyCI.git", allowlist)).To(BeTrue())
+			Expect(IsPermittedRepo("https://git.gitlab.example.com/security/huskyCI.git", allowlist)).To(BeTrue())
+		})
+	})
+	Context("When the repository matches an org prefix pattern", func() {
+		It("Should permit the repositories under the org", func() {
+			Expect(IsPermittedRepo("https://github.com/globocom/huskyCI.git", allowlist)).To(BeTrue())
+			Expect(IsPermittedRepo("https://github.com/GloboCom/huskyCI.git", allowlist)).To(BeTrue())
+			Expect(IsPermittedRepo("https://bitbucket.org/team/repo.git", allowlist)).To(BeTrue())
+		})
+	})
+	Context("When the repository matches no pattern", func() {
+		It("Should not permit it", func() {
+			for _, repositoryURL := range []string{
+				"https://github.com/someone/huskyCI.git",
+				"https://github.com/globocom-attacker/huskyCI.git",
+				"https://gitlab.com/globocom/huskyCI.git",
+				"https://notgitlab.example.com.attacker.com/security/huskyCI.git",
+				"https://bitbucket.org/teammate/repo.git",
+			} {
+				Expect(IsPermittedRepo(repositoryURL, allowlist)).To(BeFalse(), repositoryURL)
+			}
+		})
+	})
+})
@@ -0,0 +1,51 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package truncate
+
+import (
+	"github.com/globocom/huskyCI/api/types"
+	"github.com/globocom/huskyCI/api/util"
+)
+
+// Results keeps at most maxFindings vulnerabilities in results, the most severe ones first and
+// the accepted and nosec ones last, dropping the others. It returns true when any was dropped. There is no
+// limit when maxFindings is not positive.
+func Results(results *types.HuskyCIResults, maxFindings int) bool {
+	if maxFindings <= 0 {
+		return false
+	}
+	outputs := util.SecurityTestOutputs(results)
+	remaining := maxFindings
+	truncated := false
+	keep := func(vulns []types.HuskyCIVulnerability) []types.HuskyCIVulnerability {
+		if len(vulns) <= remaining {
+			remaining -= len(vulns)
+			return vulns
+		}
+		truncated = true
+		kept := vulns[:remaining]
+		remaining = 0
+		if len(kept) == 0 {
+			return nil
+		}
+		return kept
+	}
+	for _, output := range outputs {
+		output.HighVulns = keep(output.HighVulns)
+	}
+	for _, output := range outputs {
+		output.MediumVulns = keep(output.MediumVulns)
+	}
+	for _, output := range outputs {
+		output.LowVulns = keep(output.LowVulns)
+	}
+	for _, output := range outputs {
+		output.AcceptedVulns = keep(output.AcceptedVulns)
+	}
+	for _, output := range outputs {
+		output.NoSecVulns = keep(output.NoSecVulns)
+	}
+	return truncated
+}
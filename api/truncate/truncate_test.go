@@ -0,0 +1,67 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package truncate_test
+
+import (
+	"github.com/globocom/huskyCI/api/summarize"
+	"github.com/globocom/huskyCI/api/truncate"
+	"github.com/globocom/huskyCI/api/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Truncate", func() {
+
+	Describe("Results", func() {
+		newResults := func() types.HuskyCIResults {
+			results := types.HuskyCIResults{}
+			results.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{{Title: "high"}, {Title: "other high"}}
+			results.GoResults.HuskyCIGosecOutput.MediumVulns = []types.HuskyCIVulnerability{{Title: "medium"}}
+			results.GoResults.HuskyCIGosecOutput.NoSecVulns = []types.HuskyCIVulnerability{{Title: "nosec"}}
+			results.GenericResults.HuskyCITrivyOutput.HighVulns = []types.HuskyCIVulnerability{{Title: "trivy high"}}
+			results.GenericResults.HuskyCITrivyOutput.MediumVulns = []types.HuskyCIVulnerability{{Title: "trivy medium"}}
+			results.GenericResults.HuskyCITrivyOutput.LowVulns = []types.HuskyCIVulnerability{{Title: "trivy low"}}
+			return results
+		}
+
+		Context("When the results have more vulnerabilities than the limit", func() {
+			It("Should keep the most severe ones and tell they were truncated.", func() {
+				results := newResults()
+				Expect(truncate.Results(&results, 4)).To(BeTrue())
+				Expect(results.GoResults.HuskyCIGosecOutput.HighVulns).To(HaveLen(2))
+				Expect(results.GenericResults.HuskyCITrivyOutput.HighVulns).To(HaveLen(1))
+				Expect(results.GoResults.HuskyCIGosecOutput.MediumVulns).To(HaveLen(1))
+				Expect(results.GenericResults.HuskyCITrivyOutput.MediumVulns).To(BeEmpty())
+				Expect(results.GenericResults.HuskyCITrivyOutput.LowVulns).To(BeEmpty())
+				Expect(results.GoResults.HuskyCIGosecOutput.NoSecVulns).To(BeEmpty())
+			})
+
+			It("Should keep the counts of the summary taken before truncating.", func() {
+				results := newResults()
+				summary := summarize.Results(results)
+				truncate.Results(&results, 1)
+				Expect(summary.Total).To(Equal(6))
+				Expect(summarize.Results(results).Total).To(Equal(1))
+			})
+		})
+
+		Context("When the results have no more vulnerabilities than the limit", func() {
+			It("Should keep all of them.", func() {
+				results := newResults()
+				Expect(truncate.Results(&results, 7)).To(BeFalse())
+				Expect(results).To(Equal(newResults()))
+			})
+		})
+
+		Context("When there is no limit", func() {
+			It("Should keep all of them.", func() {
+				results := newResults()
+				Expect(truncate.Results(&results, 0)).To(BeFalse())
+				Expect(results).To(Equal(newResults()))
+			})
+		})
+	})
+})
@@ -10,9 +10,61 @@ import (
 
 // Repository is the struct that stores all data from repository to be analyzed.
 type Repository struct {
-	URL       string    `bson:"repositoryURL" json:"repositoryURL"`
-	Branch    string    `json:"repositoryBranch"`
-	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	URL              string    `bson:"repositoryURL" json:"repositoryURL"`
+	Branch           string    `json:"repositoryBranch"`
+	SubPath          string    `bson:"repositorySubPath,omitempty" json:"repositorySubPath,omitempty"`
+	Commit           string    `bson:"repositoryCommit,omitempty" json:"repositoryCommit,omitempty"`
+	TimeOutInSeconds int       `bson:"timeOutInSeconds,omitempty" json:"timeOutInSeconds,omitempty"`
+	CreatedAt        time.Time `bson:"createdAt" json:"createdAt"`
+	// FailFastSeverity, when set, aborts the analysis after the first finding of this severity or higher.
+	FailFastSeverity string `bson:"failFastSeverity,omitempty" json:"failFastSeverity,omitempty"`
+	// SSHPrivateKey is the deploy key used to clone the repository over SSH. It is never stored.
+	SSHPrivateKey string `bson:"-" json:"sshPrivateKey,omitempty"`
+	// CloneURL, when set, is the URL with the credentials stripped from URL, used only to clone the repository.
+	// It is never stored.
+	CloneURL string `bson:"-" json:"-"`
+	// ImageReference is a container image built from the repository, scanned by trivy when image scans are enabled.
+	ImageReference string `bson:"imageReference,omitempty" json:"imageReference,omitempty"`
+	// BaseRef, when set, is the branch or commit the analyzed one is compared to, so that only the findings
+	// in the files changed since it are reported, as a pull request scan does.
+	BaseRef string `bson:"repositoryBaseRef,omitempty" json:"repositoryBaseRef,omitempty"`
+	// Force starts a new analysis even if the commit was recently analyzed and the analysis cache is enabled.
+	Force bool `bson:"-" json:"force,omitempty"`
+	// SecurityTestArgs holds, by tool, the extra arguments passed to every securityTest running it, such as
+	// the rules gosec excludes. Only the flags allowed for each tool are accepted.
+	SecurityTestArgs map[string][]string `bson:"securityTestArgs,omitempty" json:"securityTestArgs,omitempty"`
+	// SecurityTestEnv holds, by tool, the environment variables injected into every securityTest container
+	// running it. A value secret:NAME is read from the secret store as the container starts, so that only
+	// the reference to the secret is ever stored.
+	SecurityTestEnv map[string]map[string]string `bson:"securityTestEnv,omitempty" json:"securityTestEnv,omitempty"`
+	// Profile, when set, is the scan profile whose securityTests run instead of all the default ones.
+	Profile string `bson:"-" json:"profile,omitempty"`
+	// SecurityTests are the names of the only default securityTests the analysis runs, resolved at submission
+	// from Profile or the branch policy. An empty list runs all the default securityTests.
+	SecurityTests []string `bson:"-" json:"-"`
+	// BranchPolicy is the pattern of the branch policy resolved for Branch at submission, if any, setting
+	// SecurityTests, FailSeverity and SkipNotifications.
+	BranchPolicy      string `bson:"-" json:"-"`
+	FailSeverity      string `bson:"-" json:"-"`
+	SkipNotifications bool   `bson:"-" json:"-"`
+	// Tags are key/value pairs, such as the team or the pipeline submitting the analysis, stored with it
+	// so that analyses can be grouped and filtered. They never change how the analysis runs or its result.
+	Tags map[string]string `bson:"tags,omitempty" json:"tags,omitempty"`
+	// Source is upload when the code is an uploaded archive instead of a git repository at URL.
+	Source string `bson:"-" json:"-"`
+}
+
+// BranchPolicy is how the analyses of the branches matching Pattern run, resolved by the API instead of
+// requested by the client.
+type BranchPolicy struct {
+	// Pattern matches the branch names the policy applies to, as path.Match does, so feature/* matches feature/login.
+	Pattern string
+	// SecurityTests, when set, are the names of the only default securityTests run.
+	SecurityTests []string
+	// FailSeverity, when set, is the lowest severity failing every securityTest, none making the analysis advisory.
+	FailSeverity string
+	// Notify states whether the findings of the analysis are sent to the integrations notifying them.
+	Notify bool
 }
 
 // SecurityTest is the struct that stores all data from the security tests to be executed.
@@ -25,13 +77,29 @@ type SecurityTest struct {
 	Language         string `bson:"language" json:"language"`
 	Default          bool   `bson:"default" json:"default"`
 	TimeOutInSeconds int    `bson:"timeOutSeconds" json:"timeOutSeconds"`
+	EnvAllowlist     string `bson:"envAllowlist,omitempty" json:"envAllowlist,omitempty"`
+	// Tool is the securityTest whose parser reads the output of this one, letting the same tool run
+	// more than once under different names. It is empty for securityTests named after their tool.
+	Tool string `bson:"tool,omitempty" json:"tool,omitempty"`
+	// FailSeverity is the lowest severity of the vulnerabilities failing this securityTest. It is empty for
+	// securityTests following the global one.
+	FailSeverity string `bson:"failSeverity,omitempty" json:"failSeverity,omitempty"`
+	// GraceDays is the number of days, since it first runs for a repository, that this securityTest only
+	// warns about its findings before failing the analyses of that repository. The grace period is time based
+	// only: no baseline of the findings of the repository ends it before its days are over.
+	GraceDays int `bson:"graceDays,omitempty" json:"graceDays,omitempty"`
 }
 
+// QueuedStatus is the status of an analysis waiting for a free slot to run, before running any securityTest.
+const QueuedStatus = "queued"
+
 // Analysis is the struct that stores all data from analysis performed.
 type Analysis struct {
 	RID            string         `bson:"RID" json:"RID"`
 	URL            string         `bson:"repositoryURL" json:"repositoryURL"`
 	Branch         string         `bson:"repositoryBranch" json:"repositoryBranch"`
+	SubPath        string         `bson:"repositorySubPath,omitempty" json:"repositorySubPath,omitempty"`
+	Commit         string         `bson:"repositoryCommit,omitempty" json:"repositoryCommit,omitempty"`
 	CommitAuthors  []string       `bson:"commitAuthors" json:"commitAuthors"`
 	Status         string         `bson:"status" json:"status"`
 	Result         string         `bson:"result,omitempty" json:"result"`
@@ -41,6 +109,148 @@ type Analysis struct {
 	FinishedAt     time.Time      `bson:"finishedAt" json:"finishedAt"`
 	Codes          []Code         `bson:"codes" json:"codes"`
 	HuskyCIResults HuskyCIResults `bson:"huskyciresults,omitempty" json:"huskyciresults"`
+	// FailFastAborted states that the analysis was aborted by fail fast and holds partial results.
+	FailFastAborted bool `bson:"failFastAborted,omitempty" json:"failFastAborted,omitempty"`
+	// TimeOutInSeconds and FailFastSeverity are the ones requested for the analysis, kept to rerun it.
+	TimeOutInSeconds int    `bson:"timeOutInSeconds,omitempty" json:"timeOutInSeconds,omitempty"`
+	FailFastSeverity string `bson:"failFastSeverity,omitempty" json:"failFastSeverity,omitempty"`
+	// OriginAnalysisID is the RID of the analysis this one is a rerun of.
+	OriginAnalysisID string `bson:"originAnalysisID,omitempty" json:"originAnalysisID,omitempty"`
+	// ImageReference is the container image requested to be scanned with the repository.
+	ImageReference string `bson:"imageReference,omitempty" json:"imageReference,omitempty"`
+	// Profile is the scan profile requested for the analysis, if any.
+	Profile string `bson:"profile,omitempty" json:"profile,omitempty"`
+	// SecurityTests are the only default securityTests the analysis ran. It is empty if every one of them ran.
+	SecurityTests []string `bson:"securityTests,omitempty" json:"securityTests,omitempty"`
+	// BranchPolicy is the pattern of the branch policy the analysis ran with, FailSeverity the lowest severity
+	// it set failing every securityTest and SkipNotifications whether it kept the findings from being notified.
+	BranchPolicy      string `bson:"branchPolicy,omitempty" json:"branchPolicy,omitempty"`
+	FailSeverity      string `bson:"failSeverity,omitempty" json:"failSeverity,omitempty"`
+	SkipNotifications bool   `bson:"skipNotifications,omitempty" json:"skipNotifications,omitempty"`
+	// BaseRef is the branch or commit whose changed files the findings were restricted to.
+	BaseRef string `bson:"repositoryBaseRef,omitempty" json:"repositoryBaseRef,omitempty"`
+	// SecurityTestArgs are the extra arguments, by tool, the securityTests of the analysis ran with.
+	SecurityTestArgs map[string][]string `bson:"securityTestArgs,omitempty" json:"securityTestArgs,omitempty"`
+	// SecurityTestEnv are the environment variables, by tool, the securityTests of the analysis ran with.
+	// Secrets are kept as their secret:NAME reference.
+	SecurityTestEnv map[string]map[string]string `bson:"securityTestEnv,omitempty" json:"securityTestEnv,omitempty"`
+	// Tags are the key/value pairs the analysis was submitted with.
+	Tags map[string]string `bson:"tags,omitempty" json:"tags,omitempty"`
+	// Source is upload when the analyzed code was an uploaded archive instead of a git repository.
+	Source string `bson:"source,omitempty" json:"source,omitempty"`
+	// Summary counts the vulnerabilities of HuskyCIResults, computed when the analysis finishes.
+	Summary AnalysisSummary `bson:"summary" json:"summary"`
+	// Components are the dependencies of the repository found by the dependency securityTests, vulnerable
+	// or not, listed by its CycloneDX SBOM.
+	Components []Component `bson:"components,omitempty" json:"components,omitempty"`
+	// CloneStrategy is how the securityTests of the analysis cloned its repository, unless their containers
+	// record a strategy of their own.
+	CloneStrategy CloneStrategy `bson:"cloneStrategy,omitempty" json:"cloneStrategy,omitempty"`
+}
+
+// CloneStrategy is how the securityTests of an analysis, or one of them, cloned its repository.
+type CloneStrategy struct {
+	// Depth is the number of commits cloned from the branch, 0 for its full history.
+	Depth        int  `bson:"depth" json:"depth"`
+	SingleBranch bool `bson:"singleBranch" json:"singleBranch"`
+	// FullHistoryReason, when set, is the feature of the analysis that needed its full history, cloned instead
+	// of the configured depth.
+	FullHistoryReason string `bson:"fullHistoryReason,omitempty" json:"fullHistoryReason,omitempty"`
+}
+
+// Component is a dependency of a repository, of a package ecosystem such as pypi or npm.
+type Component struct {
+	Ecosystem string `bson:"ecosystem" json:"ecosystem"`
+	Name      string `bson:"name" json:"name"`
+	Version   string `bson:"version" json:"version"`
+}
+
+// AnalysisSummary is the struct that stores the vulnerability counts of an analysis.
+type AnalysisSummary struct {
+	Total          int            `bson:"total" json:"total"`
+	BySeverity     map[string]int `bson:"bySeverity" json:"bySeverity"`
+	BySecurityTest map[string]int `bson:"bySecurityTest" json:"bySecurityTest"`
+	// Suppressed counts the vulnerabilities marked as nosec, which are not part of the other counts.
+	Suppressed int `bson:"suppressed" json:"suppressed"`
+	// Accepted counts the vulnerabilities whose risk was accepted, which are not part of the other counts.
+	Accepted int `bson:"accepted" json:"accepted"`
+	// Truncated tells that only part of the vulnerabilities were stored, while the counts still include all of them.
+	Truncated bool `bson:"truncated" json:"truncated"`
+}
+
+// AnalysisListItem is an analysis in the list of the analyses of a repository: its status, tags and
+// number of vulnerabilities, without its findings.
+type AnalysisListItem struct {
+	RID        string            `json:"RID"`
+	Branch     string            `json:"repositoryBranch"`
+	Commit     string            `json:"repositoryCommit,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Status     string            `json:"status"`
+	Result     string            `json:"result"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Total      int               `json:"total"`
+}
+
+// AnalysisList is the list of the last analyses of a repository, most recent first.
+type AnalysisList struct {
+	RepositoryURL string             `json:"repositoryURL"`
+	Analyses      []AnalysisListItem `json:"analyses"`
+}
+
+// AnalysisTrendPoint is an analysis in the trend of a repository: its status and the counts of its
+// vulnerabilities by severity, without its findings.
+type AnalysisTrendPoint struct {
+	RID        string            `json:"RID"`
+	Branch     string            `json:"repositoryBranch"`
+	Commit     string            `json:"repositoryCommit,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Status     string            `json:"status"`
+	Result     string            `json:"result"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Total      int               `json:"total"`
+	BySeverity map[string]int    `json:"bySeverity"`
+}
+
+// AnalysisTrend is the series of the last analyses of a repository, oldest first.
+type AnalysisTrend struct {
+	RepositoryURL string               `json:"repositoryURL"`
+	Analyses      []AnalysisTrendPoint `json:"analyses"`
+}
+
+// AnalysisComparison holds the findings added, removed and unchanged from a base analysis to a head analysis
+// of the same repository, told apart by their fingerprints, with their counts.
+type AnalysisComparison struct {
+	RepositoryURL string                 `json:"repositoryURL"`
+	BaseRID       string                 `json:"baseRID"`
+	HeadRID       string                 `json:"headRID"`
+	Added         []HuskyCIVulnerability `json:"added"`
+	Removed       []HuskyCIVulnerability `json:"removed"`
+	Unchanged     []HuskyCIVulnerability `json:"unchanged"`
+	Summary       ComparisonSummary      `json:"summary"`
+}
+
+// ComparisonSummary holds the counts of the findings of an AnalysisComparison, by category.
+type ComparisonSummary struct {
+	Added     ComparisonCount `json:"added"`
+	Removed   ComparisonCount `json:"removed"`
+	Unchanged ComparisonCount `json:"unchanged"`
+}
+
+// ComparisonCount holds how many findings of an AnalysisComparison are in a category, in total and by severity.
+type ComparisonCount struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"bySeverity"`
+}
+
+// QueueStats holds how many analyses are queued and running in an API process, and their limits. A limit
+// of zero states that there is none.
+type QueueStats struct {
+	Queued     int `json:"queued"`
+	Running    int `json:"running"`
+	MaxQueued  int `json:"maxQueued"`
+	MaxRunning int `json:"maxRunning"`
 }
 
 // Container is the struct that stores all data from a container run.
@@ -53,6 +263,23 @@ type Container struct {
 	CInfo        string       `bson:"cInfo" json:"cInfo"`
 	StartedAt    time.Time    `bson:"startedAt" json:"startedAt"`
 	FinishedAt   time.Time    `bson:"finishedAt" json:"finishedAt"`
+	// DurationInSeconds is the time elapsed between StartedAt and FinishedAt.
+	DurationInSeconds float64 `bson:"durationInSeconds" json:"durationInSeconds"`
+	// Image is the reference of the image that ran, pinned to its digest when the docker API knows it.
+	Image string `bson:"image,omitempty" json:"image,omitempty"`
+	// ExitCode is the exit code of the container or -1 if it did not exit.
+	ExitCode int    `bson:"exitCode" json:"exitCode"`
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+	// COutputCodec is the codec COutput is stored compressed with, empty when it is stored as is.
+	COutputCodec string `bson:"cOutputCodec,omitempty" json:"cOutputCodec,omitempty"`
+	// GracePeriod states that the findings of the securityTest did not fail the analysis, as it was within
+	// its grace days for the repository.
+	GracePeriod bool `bson:"gracePeriod,omitempty" json:"gracePeriod,omitempty"`
+	// Diagnostics is the tail of what the scanner of the securityTest printed to stderr, kept apart from its
+	// findings when the securityTest ends with an error.
+	Diagnostics string `bson:"diagnostics,omitempty" json:"diagnostics,omitempty"`
+	// CloneStrategy is how the securityTest cloned the repository, which the gitleaks history scan clones in full.
+	CloneStrategy CloneStrategy `bson:"cloneStrategy,omitempty" json:"cloneStrategy,omitempty"`
 }
 
 // Code is the struct that stores all data from code found in a repository.
@@ -75,19 +302,39 @@ type User struct {
 
 // HuskyCIVulnerability is the struct that stores vulnerability information.
 type HuskyCIVulnerability struct {
-	Language       string `bson:"language" json:"language,omitempty"`
-	SecurityTool   string `bson:"securitytool" json:"securitytool,omitempty"`
-	Severity       string `bson:"severity,omitempty" json:"severity,omitempty"`
-	Confidence     string `bson:"confidence,omitempty" json:"confidence,omitempty"`
-	File           string `bson:"file,omitempty" json:"file,omitempty"`
-	Line           string `bson:"line,omitempty" json:"line,omitempty"`
-	Code           string `bson:"code,omitempty" json:"code,omitempty"`
-	Details        string `bson:"details" json:"details,omitempty"`
-	Type           string `bson:"type,omitempty" json:"type,omitempty"`
-	Title          string `bson:"title,omitempty" json:"title,omitempty"`
-	VunerableBelow string `bson:"vulnerablebelow,omitempty" json:"vulnerablebelow,omitempty"`
-	Version        string `bson:"version,omitempty" json:"version,omitempty"`
-	Occurrences    int    `bson:"occurrences,omitempty" json:"occurrences,omitempty"`
+	Language       string   `bson:"language" json:"language,omitempty"`
+	SecurityTool   string   `bson:"securitytool" json:"securitytool,omitempty"`
+	Severity       string   `bson:"severity,omitempty" json:"severity,omitempty"`
+	Confidence     string   `bson:"confidence,omitempty" json:"confidence,omitempty"`
+	File           string   `bson:"file,omitempty" json:"file,omitempty"`
+	Line           string   `bson:"line,omitempty" json:"line,omitempty"`
+	Code           string   `bson:"code,omitempty" json:"code,omitempty"`
+	Details        string   `bson:"details" json:"details,omitempty"`
+	Type           string   `bson:"type,omitempty" json:"type,omitempty"`
+	Title          string   `bson:"title,omitempty" json:"title,omitempty"`
+	VunerableBelow string   `bson:"vulnerablebelow,omitempty" json:"vulnerablebelow,omitempty"`
+	Version        string   `bson:"version,omitempty" json:"version,omitempty"`
+	Occurrences    int      `bson:"occurrences,omitempty" json:"occurrences,omitempty"`
+	CommitHash     string   `bson:"commitHash,omitempty" json:"commitHash,omitempty"`
+	CommitAuthor   string   `bson:"commitAuthor,omitempty" json:"commitAuthor,omitempty"`
+	CVE            string   `bson:"cve,omitempty" json:"cve,omitempty"`
+	CVSSScore      float64  `bson:"cvssScore,omitempty" json:"cvssScore,omitempty"`
+	CVSSVector     string   `bson:"cvssVector,omitempty" json:"cvssVector,omitempty"`
+	SecurityTools  []string `bson:"securitytools,omitempty" json:"securitytools,omitempty"`
+	Fingerprint    string   `bson:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+	Verified       bool     `bson:"verified,omitempty" json:"verified,omitempty"`
+	// Snippet, when code snippets are enabled, is the code around the line of the vulnerability, each
+	// line prefixed by its number.
+	Snippet string `bson:"snippet,omitempty" json:"snippet,omitempty"`
+	// Rule is the rule of the securityTest that found the vulnerability, for the securityTests whose
+	// severities can be overridden by rule.
+	Rule string `bson:"rule,omitempty" json:"rule,omitempty"`
+	// Reachability, for the securityTests that tell it, is how the code of the repository reaches the
+	// vulnerable dependency: its vulnerable functions are called, its vulnerable package is only imported
+	// or its vulnerable module is only required.
+	Reachability string `bson:"reachability,omitempty" json:"reachability,omitempty"`
+	// AcceptedRisk, when set, is the decision that accepted the risk of the vulnerability.
+	AcceptedRisk *AcceptedRisk `bson:"acceptedRisk,omitempty" json:"acceptedRisk,omitempty"`
 }
 
 // HuskyCIResults is a struct that represents huskyCI scan results.
@@ -98,12 +345,19 @@ type HuskyCIResults struct {
 	RubyResults       RubyResults       `bson:"rubyresults,omitempty" json:"rubyresults,omitempty"`
 	JavaResults       JavaResults       `bson:"javaresults,omitempty" json:"javaresults,omitempty"`
 	HclResults        HclResults        `bson:"hclresults,omitempty" json:"hclresults,omitempty"`
+	DockerfileResults DockerfileResults `bson:"dockerfileresults,omitempty" json:"dockerfileresults,omitempty"`
+	SwiftResults      SwiftResults      `bson:"swiftresults,omitempty" json:"swiftresults,omitempty"`
+	KotlinResults     KotlinResults     `bson:"kotlinresults,omitempty" json:"kotlinresults,omitempty"`
+	DartResults       DartResults       `bson:"dartresults,omitempty" json:"dartresults,omitempty"`
 	GenericResults    GenericResults    `bson:"genericresults,omitempty" json:"genericresults,omitempty"`
+	// Instances holds, by securityTest name, the results of securityTests running a tool under a name of their own.
+	Instances map[string]*HuskyCISecurityTestOutput `bson:"instances,omitempty" json:"instances,omitempty"`
 }
 
 // GoResults represents all Golang security tests results.
 type GoResults struct {
-	HuskyCIGosecOutput HuskyCISecurityTestOutput `bson:"gosecoutput,omitempty" json:"gosecoutput,omitempty"`
+	HuskyCIGosecOutput       HuskyCISecurityTestOutput `bson:"gosecoutput,omitempty" json:"gosecoutput,omitempty"`
+	HuskyCIGovulncheckOutput HuskyCISecurityTestOutput `bson:"govulncheckoutput,omitempty" json:"govulncheckoutput,omitempty"`
 }
 
 // PythonResults represents all Python security tests results.
@@ -131,6 +385,7 @@ type RubyResults struct {
 // GenericResults represents all generic securityTests results
 type GenericResults struct {
 	HuskyCIGitleaksOutput HuskyCISecurityTestOutput `bson:"gitleaksoutput,omitempty" json:"gitleaksoutput,omitempty"`
+	HuskyCITrivyOutput    HuskyCISecurityTestOutput `bson:"trivyoutput,omitempty" json:"trivyoutput,omitempty"`
 }
 
 // HclResults represents all HCL security tests results.
@@ -138,12 +393,89 @@ type HclResults struct {
 	HuskyCITFSecOutput HuskyCISecurityTestOutput `bson:"tfsecoutput,omitempty" json:"tfsecoutput,omitempty"`
 }
 
+// DockerfileResults represents all Dockerfile security tests results.
+type DockerfileResults struct {
+	HuskyCIHadolintOutput HuskyCISecurityTestOutput `bson:"hadolintoutput,omitempty" json:"hadolintoutput,omitempty"`
+}
+
+// SwiftResults represents all Swift security tests results.
+type SwiftResults struct {
+	HuskyCIDependencyCheckOutput HuskyCISecurityTestOutput `bson:"dependencycheckoutput,omitempty" json:"dependencycheckoutput,omitempty"`
+}
+
+// KotlinResults represents all Kotlin security tests results.
+type KotlinResults struct {
+	HuskyCIDetektOutput                HuskyCISecurityTestOutput `bson:"detektoutput,omitempty" json:"detektoutput,omitempty"`
+	HuskyCIDependencyCheckGradleOutput HuskyCISecurityTestOutput `bson:"dependencycheckgradleoutput,omitempty" json:"dependencycheckgradleoutput,omitempty"`
+}
+
+// DartResults represents all Dart security tests results.
+type DartResults struct {
+	HuskyCIOSVScannerOutput HuskyCISecurityTestOutput `bson:"osvscanneroutput,omitempty" json:"osvscanneroutput,omitempty"`
+}
+
 // HuskyCISecurityTestOutput stores all Low, Medium and High vulnerabilities for a sec test
 type HuskyCISecurityTestOutput struct {
 	NoSecVulns  []HuskyCIVulnerability `bson:"nosecvulns,omitempty" json:"nosecvulns,omitempty"`
 	LowVulns    []HuskyCIVulnerability `bson:"lowvulns,omitempty" json:"lowvulns,omitempty"`
 	MediumVulns []HuskyCIVulnerability `bson:"mediumvulns,omitempty" json:"mediumvulns,omitempty"`
 	HighVulns   []HuskyCIVulnerability `bson:"highvulns,omitempty" json:"highvulns,omitempty"`
+	// AcceptedVulns are the vulnerabilities whose risk was accepted, which do not fail the analysis.
+	AcceptedVulns []HuskyCIVulnerability `bson:"acceptedvulns,omitempty" json:"acceptedvulns,omitempty"`
+}
+
+// ParseRequest defines the JSON struct for a request to parse the raw output of a securityTest
+type ParseRequest struct {
+	SecurityTestName string `json:"securityTestName"`
+	Output           string `json:"output"`
+}
+
+// AnalysisPlan defines the JSON struct of a dry run, listing the securityTests an analysis would run
+type AnalysisPlan struct {
+	Codes         []Code                `json:"codes"`
+	SecurityTests []PlannedSecurityTest `json:"securityTests"`
+	Skipped       []SkippedSecurityTest `json:"skipped,omitempty"`
+}
+
+// PlannedSecurityTest defines the JSON struct of a securityTest that an analysis would run
+type PlannedSecurityTest struct {
+	Name             string `json:"name"`
+	Image            string `json:"image"`
+	ImageTag         string `json:"imageTag"`
+	Type             string `json:"type"`
+	Language         string `json:"language,omitempty"`
+	TimeOutInSeconds int    `json:"timeOutInSeconds"`
+}
+
+// ReevaluationPolicy defines the JSON struct of the severity threshold and overrides a stored analysis is re-evaluated against
+type ReevaluationPolicy struct {
+	// FailSeverity, when set, is the lowest severity failing every securityTest, in place of the configured ones.
+	FailSeverity string `json:"failSeverity,omitempty"`
+	// SeverityOverrides, when set, replace the configured severity overrides, by tool:rule.
+	SeverityOverrides map[string]string `json:"severityOverrides,omitempty"`
+}
+
+// Reevaluation defines the JSON struct of the verdict of a stored analysis re-evaluated against a policy
+type Reevaluation struct {
+	RID           string                    `json:"RID"`
+	StoredResult  string                    `json:"storedResult"`
+	Result        string                    `json:"result"`
+	SecurityTests []ReevaluatedSecurityTest `json:"securityTests"`
+}
+
+// ReevaluatedSecurityTest defines the JSON struct of the verdict of a securityTest of a re-evaluated analysis
+type ReevaluatedSecurityTest struct {
+	Name         string `json:"name"`
+	StoredResult string `json:"storedResult"`
+	Result       string `json:"result"`
+	FailSeverity string `json:"failSeverity,omitempty"`
+}
+
+// SkippedSecurityTest defines the JSON struct of a securityTest, or a language without securityTests, that an analysis would skip
+type SkippedSecurityTest struct {
+	Name     string `json:"name,omitempty"`
+	Language string `json:"language,omitempty"`
+	Reason   string `json:"reason"`
 }
 
 // TokenRequest defines the JSON struct for an access token request
@@ -157,6 +489,44 @@ type AccessToken struct {
 	HuskyToken string `bson:"huskytoken" json:"huskytoken"`
 }
 
+// TokenValidationRequest defines the JSON struct of an access token
+// and the repository URL it should be valid for
+type TokenValidationRequest struct {
+	HuskyToken    string `json:"huskytoken"`
+	RepositoryURL string `json:"repositoryURL"`
+}
+
+// TokenValidationBatch defines the JSON struct for a batch token validation request
+type TokenValidationBatch struct {
+	Tokens []TokenValidationRequest `json:"tokens"`
+}
+
+// TokenValidationResult defines the JSON struct of the validity of an
+// access token for a repository URL
+type TokenValidationResult struct {
+	RepositoryURL string `json:"repositoryURL"`
+	Valid         bool   `json:"valid"`
+}
+
+// RegisteredRepository defines the JSON struct of a repository
+// registered in huskyCI, with the validity of its access tokens
+// and the date its last analysis finished
+type RegisteredRepository struct {
+	RepositoryURL  string     `json:"repositoryURL"`
+	ValidTokens    int        `json:"validTokens"`
+	InvalidTokens  int        `json:"invalidTokens"`
+	LastAnalysisAt *time.Time `json:"lastAnalysisAt,omitempty"`
+}
+
+// RegisteredRepositoryPage defines the JSON struct of a page
+// of the registered repositories, sorted by their URL
+type RegisteredRepositoryPage struct {
+	Repositories []RegisteredRepository `json:"repositories"`
+	Page         int                    `json:"page"`
+	PageSize     int                    `json:"pageSize"`
+	Total        int                    `json:"total"`
+}
+
 // DBToken defines the struct that stores husky access token
 // for a repository URL
 type DBToken struct {
@@ -168,5 +538,31 @@ type DBToken struct {
 	UUID       string    `bson:"uuid" json:"uuid"`
 }
 
+// SecurityTestRollout stores when a securityTest first ran for a repository, starting its grace days.
+type SecurityTestRollout struct {
+	URL              string    `bson:"repositoryURL" json:"repositoryURL"`
+	SecurityTestName string    `bson:"securityTestName" json:"securityTestName"`
+	StartedAt        time.Time `bson:"startedAt" json:"startedAt"`
+}
+
+// AcceptedRisk is the decision of an API user to accept the risk of a vulnerability of a repository, identified by
+// its fingerprint. The vulnerability does not fail the analyses of the repository until ExpiresAt, if set.
+type AcceptedRisk struct {
+	RepositoryURL string     `bson:"repositoryURL" json:"repositoryURL"`
+	Fingerprint   string     `bson:"fingerprint" json:"fingerprint"`
+	AcceptedBy    string     `bson:"acceptedBy" json:"acceptedBy"`
+	AcceptedAt    time.Time  `bson:"acceptedAt" json:"acceptedAt"`
+	ExpiresAt     *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	Note          string     `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// AcceptedRiskRequest defines the JSON struct of a request accepting the risk of a vulnerability.
+type AcceptedRiskRequest struct {
+	RepositoryURL string     `json:"repositoryURL"`
+	Fingerprint   string     `json:"fingerprint"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	Note          string     `json:"note,omitempty"`
+}
+
 // NohuskyFunction represents all the #nohusky verifier methods.
 type NohuskyFunction func(string, int) bool
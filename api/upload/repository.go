@@ -0,0 +1,47 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Branch is the branch holding the files of an uploaded archive, once HandleCmd turned them into a repository.
+const Branch = "upload"
+
+// URL returns the URL that the analyses of the archives uploaded under name are registered with.
+func URL(name string) string {
+	return "upload://" + name
+}
+
+// Name returns the name of an uploaded archive whose file name is fileName, without its directory and
+// archive extension.
+func Name(fileName string) string {
+	name := path.Base(strings.Replace(fileName, `\`, "/", -1))
+	for _, extension := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(strings.ToLower(name), extension) {
+			return name[:len(name)-len(extension)]
+		}
+	}
+	return name
+}
+
+// IsValidName reports whether name can name an uploaded archive: up to 100 letters, digits, dots,
+// dashes and underscores, not starting with a dot or a dash.
+func IsValidName(name string) bool {
+	return len(name) <= 100 && regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`).MatchString(name)
+}
+
+// HandleCmd returns cmd preceded by the commands that turn dir, holding the files of an uploaded archive,
+// into a git repository whose branch has them all in a single commit, so that cmd clones it as it clones any
+// repository. safe.directory is set for every git command, as dir may belong to another user than the one
+// running cmd. Any .git directory in dir is removed first, so that no hook or config of the archive is used.
+func HandleCmd(cmd, dir, branch string) string {
+	initCmd := fmt.Sprintf(`export GIT_CONFIG_COUNT=1 GIT_CONFIG_KEY_0=safe.directory GIT_CONFIG_VALUE_0='*'; { find %s -name .git -prune -exec rm -rf {} + && git -C %s init --quiet && git -C %s symbolic-ref HEAD refs/heads/%s && git -C %s add --all && git -C %s -c user.name=huskyCI -c user.email=huskyci@localhost commit --quiet --allow-empty -m "huskyCI upload"; } > /dev/null 2>&1 || echo "ERROR_CLONING";`, dir, dir, dir, branch, dir, dir)
+	return initCmd + "\n" + cmd
+}
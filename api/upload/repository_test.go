@@ -0,0 +1,86 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/upload"
+	"github.com/globocom/huskyCI/api/util"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Repository", func() {
+
+	Describe("Name", func() {
+		It("Should strip the directories and the archive extension of the file name.", func() {
+			Expect(upload.Name("myProject.tar.gz")).To(Equal("myProject"))
+			Expect(upload.Name("build/myProject.TGZ")).To(Equal("myProject"))
+			Expect(upload.Name(`C:\builds\myProject.zip`)).To(Equal("myProject"))
+			Expect(upload.Name("myProject.tar")).To(Equal("myProject.tar"))
+		})
+	})
+
+	Describe("IsValidName", func() {
+		It("Should accept letters, digits, dots, dashes and underscores.", func() {
+			Expect(upload.IsValidName("my_project-1.2")).To(BeTrue())
+		})
+		It("Should reject empty names, names starting with a dot or a dash and other characters.", func() {
+			Expect(upload.IsValidName("")).To(BeFalse())
+			Expect(upload.IsValidName("..")).To(BeFalse())
+			Expect(upload.IsValidName("-project")).To(BeFalse())
+			Expect(upload.IsValidName("my project")).To(BeFalse())
+			Expect(upload.IsValidName("project;id")).To(BeFalse())
+		})
+		It("Should reject names longer than 100 characters.", func() {
+			Expect(upload.IsValidName(strings.Repeat("a", 101))).To(BeFalse())
+		})
+	})
+
+	Describe("HandleCmd", func() {
+		var dir string
+		BeforeEach(func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not installed")
+			}
+			var err error
+			dir, err = ioutil.TempDir("", "huskyci-upload")
+			Expect(err).To(BeNil())
+			Expect(os.Mkdir(dir+"/huskyci-upload", 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(dir+"/huskyci-upload/main.go", []byte("package main"), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("Should let the command clone the uploaded files as the branch of a repository.", func() {
+			cmd := util.HandleCmd(dir+"/huskyci-upload", upload.Branch, "git clone -b %GIT_BRANCH% --single-branch %GIT_REPO% code --quiet && cat code/main.go")
+			script := exec.Command("bash", "-c", upload.HandleCmd(cmd, dir+"/huskyci-upload", upload.Branch))
+			script.Dir = dir
+			script.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+			output, err := script.CombinedOutput()
+			Expect(err).To(BeNil(), string(output))
+			Expect(string(output)).To(Equal("package main"))
+		})
+
+		It("Should not run the hooks of a .git directory of the uploaded files.", func() {
+			Expect(os.MkdirAll(dir+"/huskyci-upload/.git/hooks", 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(dir+"/huskyci-upload/.git/hooks/pre-commit", []byte("#!/bin/sh\ntouch "+dir+"/hooked"), 0755)).To(Succeed())
+			cmd := util.HandleCmd(dir+"/huskyci-upload", upload.Branch, "git clone -b %GIT_BRANCH% --single-branch %GIT_REPO% code --quiet && cat code/main.go")
+			script := exec.Command("bash", "-c", upload.HandleCmd(cmd, dir+"/huskyci-upload", upload.Branch))
+			script.Dir = dir
+			script.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+			output, err := script.CombinedOutput()
+			Expect(err).To(BeNil(), string(output))
+			Expect(string(output)).To(Equal("package main"))
+			Expect(dir + "/hooked").NotTo(BeAnExistingFile())
+		})
+	})
+})
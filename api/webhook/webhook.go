@@ -0,0 +1,146 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/globocom/huskyCI/api/types"
+)
+
+// Providers whose push webhooks can trigger an analysis.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+const branchRefPrefix = "refs/heads/"
+
+// ErrUnknownProvider is returned for a webhook of a provider other than GitHub and GitLab.
+var ErrUnknownProvider = errors.New("unknown webhook provider")
+
+// ErrMissingSecret is returned when no webhook secret is configured, so that no webhook can be verified.
+var ErrMissingSecret = errors.New("webhook secret not configured")
+
+// ErrMissingSignature is returned for a webhook without the signature header of its provider.
+var ErrMissingSignature = errors.New("webhook signature not found")
+
+// ErrInvalidSignature is returned for a webhook whose signature does not match the configured secret.
+var ErrInvalidSignature = errors.New("webhook signature does not match")
+
+// ErrNotBranchPush is returned for a push webhook that did not push a commit to a branch, such as the
+// push of a tag or the deletion of a branch.
+var ErrNotBranchPush = errors.New("webhook is not a push to a branch")
+
+// VerifySignature verifies that the webhook of provider with header and body was sent with secret.
+// GitHub signs the body with HMAC-SHA256 in X-Hub-Signature-256, while GitLab sends the secret
+// itself in X-Gitlab-Token.
+func VerifySignature(provider, secret string, header http.Header, body []byte) error {
+	if provider != ProviderGitHub && provider != ProviderGitLab {
+		return ErrUnknownProvider
+	}
+	if secret == "" {
+		return ErrMissingSecret
+	}
+	if provider == ProviderGitLab {
+		token := header.Get("X-Gitlab-Token")
+		if token == "" {
+			return ErrMissingSignature
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	signature := header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		return ErrInvalidSignature
+	}
+	receivedMAC, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(receivedMAC, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// IsPush reports whether header belongs to a push webhook of provider. Other events, such as the
+// ping GitHub sends when a webhook is created, do not trigger analyses.
+func IsPush(provider string, header http.Header) bool {
+	switch provider {
+	case ProviderGitHub:
+		return header.Get("X-GitHub-Event") == "push"
+	case ProviderGitLab:
+		return header.Get("X-Gitlab-Event") == "Push Hook"
+	}
+	return false
+}
+
+type gitHubPush struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+	} `json:"repository"`
+}
+
+type gitLabPush struct {
+	Ref         string `json:"ref"`
+	CheckoutSHA string `json:"checkout_sha"`
+	Project     struct {
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"project"`
+}
+
+// ParsePush returns the repository, with the branch and the commit pushed, of the push webhook
+// body of provider.
+func ParsePush(provider string, body []byte) (types.Repository, error) {
+	var repository types.Repository
+	var ref string
+	switch provider {
+	case ProviderGitHub:
+		push := gitHubPush{}
+		if err := json.Unmarshal(body, &push); err != nil {
+			return types.Repository{}, err
+		}
+		if push.Deleted {
+			return types.Repository{}, ErrNotBranchPush
+		}
+		repository = types.Repository{URL: push.Repository.CloneURL, Commit: push.After}
+		ref = push.Ref
+	case ProviderGitLab:
+		push := gitLabPush{}
+		if err := json.Unmarshal(body, &push); err != nil {
+			return types.Repository{}, err
+		}
+		repository = types.Repository{URL: push.Project.GitHTTPURL, Commit: push.CheckoutSHA}
+		ref = push.Ref
+	default:
+		return types.Repository{}, ErrUnknownProvider
+	}
+	if !strings.HasPrefix(ref, branchRefPrefix) || repository.Commit == "" || strings.Trim(repository.Commit, "0") == "" {
+		return types.Repository{}, ErrNotBranchPush
+	}
+	if repository.URL == "" {
+		return types.Repository{}, errors.New("webhook has no repository URL")
+	}
+	repository.Branch = strings.TrimPrefix(ref, branchRefPrefix)
+	return repository, nil
+}
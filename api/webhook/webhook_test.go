@@ -0,0 +1,143 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook_test
+
+import (
+	"net/http"
+
+	"github.com/globocom/huskyCI/api/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const gitHubPush = `{"ref": "refs/heads/master", "after": "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d", "deleted": false, "repository": {"clone_url": "https://github.com/globocom/huskyCI.git"}}`
+
+const gitLabPush = `{"ref": "refs/heads/develop", "checkout_sha": "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d", "project": {"git_http_url": "https://gitlab.com/globocom/huskyCI.git"}}`
+
+// gitHubSignature is the HMAC-SHA256 of gitHubPush with the secret s3cr3t.
+const gitHubSignature = "sha256=eaf09c05797d3765f356e9d9a215b0145352c4cde7d730389794b78f472f3ea0"
+
+var _ = Describe("Webhook", func() {
+
+	Describe("VerifySignature", func() {
+		Context("When a GitHub webhook is signed with the secret", func() {
+			It("Should verify it.", func() {
+				header := http.Header{"X-Hub-Signature-256": {gitHubSignature}}
+				Expect(webhook.VerifySignature(webhook.ProviderGitHub, "s3cr3t", header, []byte(gitHubPush))).To(Succeed())
+			})
+		})
+
+		Context("When a GitHub webhook is signed with another secret", func() {
+			It("Should return ErrInvalidSignature.", func() {
+				header := http.Header{"X-Hub-Signature-256": {gitHubSignature}}
+				Expect(webhook.VerifySignature(webhook.ProviderGitHub, "another", header, []byte(gitHubPush))).To(Equal(webhook.ErrInvalidSignature))
+			})
+		})
+
+		Context("When the body of a GitHub webhook was changed", func() {
+			It("Should return ErrInvalidSignature.", func() {
+				header := http.Header{"X-Hub-Signature-256": {gitHubSignature}}
+				Expect(webhook.VerifySignature(webhook.ProviderGitHub, "s3cr3t", header, []byte(gitHubPush+" "))).To(Equal(webhook.ErrInvalidSignature))
+			})
+		})
+
+		Context("When the signature of a GitHub webhook is malformed", func() {
+			It("Should return ErrInvalidSignature.", func() {
+				for _, signature := range []string{"sha1=ef0e2ba5", "sha256=not-hex"} {
+					header := http.Header{"X-Hub-Signature-256": {signature}}
+					Expect(webhook.VerifySignature(webhook.ProviderGitHub, "s3cr3t", header, []byte(gitHubPush))).To(Equal(webhook.ErrInvalidSignature))
+				}
+			})
+		})
+
+		Context("When a GitHub webhook is not signed", func() {
+			It("Should return ErrMissingSignature.", func() {
+				Expect(webhook.VerifySignature(webhook.ProviderGitHub, "s3cr3t", http.Header{}, []byte(gitHubPush))).To(Equal(webhook.ErrMissingSignature))
+			})
+		})
+
+		Context("When a GitLab webhook has the secret token", func() {
+			It("Should verify it.", func() {
+				header := http.Header{"X-Gitlab-Token": {"s3cr3t"}}
+				Expect(webhook.VerifySignature(webhook.ProviderGitLab, "s3cr3t", header, []byte(gitLabPush))).To(Succeed())
+			})
+		})
+
+		Context("When a GitLab webhook has another token", func() {
+			It("Should return ErrInvalidSignature.", func() {
+				header := http.Header{"X-Gitlab-Token": {"another"}}
+				Expect(webhook.VerifySignature(webhook.ProviderGitLab, "s3cr3t", header, []byte(gitLabPush))).To(Equal(webhook.ErrInvalidSignature))
+			})
+		})
+
+		Context("When no secret is configured", func() {
+			It("Should return ErrMissingSecret.", func() {
+				header := http.Header{"X-Gitlab-Token": {""}}
+				Expect(webhook.VerifySignature(webhook.ProviderGitLab, "", header, []byte(gitLabPush))).To(Equal(webhook.ErrMissingSecret))
+			})
+		})
+
+		Context("When the provider is unknown", func() {
+			It("Should return ErrUnknownProvider.", func() {
+				Expect(webhook.VerifySignature("bitbucket", "s3cr3t", http.Header{}, []byte(gitHubPush))).To(Equal(webhook.ErrUnknownProvider))
+			})
+		})
+	})
+
+	Describe("IsPush", func() {
+		It("Should report only push events as pushes.", func() {
+			Expect(webhook.IsPush(webhook.ProviderGitHub, http.Header{"X-Github-Event": {"push"}})).To(BeTrue())
+			Expect(webhook.IsPush(webhook.ProviderGitHub, http.Header{"X-Github-Event": {"ping"}})).To(BeFalse())
+			Expect(webhook.IsPush(webhook.ProviderGitLab, http.Header{"X-Gitlab-Event": {"Push Hook"}})).To(BeTrue())
+			Expect(webhook.IsPush(webhook.ProviderGitLab, http.Header{"X-Gitlab-Event": {"Tag Push Hook"}})).To(BeFalse())
+		})
+	})
+
+	Describe("ParsePush", func() {
+		Context("When a commit is pushed to a GitHub branch", func() {
+			It("Should return its repository, branch and commit.", func() {
+				repository, err := webhook.ParsePush(webhook.ProviderGitHub, []byte(gitHubPush))
+				Expect(err).To(BeNil())
+				Expect(repository.URL).To(Equal("https://github.com/globocom/huskyCI.git"))
+				Expect(repository.Branch).To(Equal("master"))
+				Expect(repository.Commit).To(Equal("4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d"))
+			})
+		})
+
+		Context("When a commit is pushed to a GitLab branch", func() {
+			It("Should return its repository, branch and commit.", func() {
+				repository, err := webhook.ParsePush(webhook.ProviderGitLab, []byte(gitLabPush))
+				Expect(err).To(BeNil())
+				Expect(repository.URL).To(Equal("https://gitlab.com/globocom/huskyCI.git"))
+				Expect(repository.Branch).To(Equal("develop"))
+				Expect(repository.Commit).To(Equal("4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d"))
+			})
+		})
+
+		Context("When a tag is pushed", func() {
+			It("Should return ErrNotBranchPush.", func() {
+				_, err := webhook.ParsePush(webhook.ProviderGitHub, []byte(`{"ref": "refs/tags/v1.0.0", "after": "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d", "repository": {"clone_url": "https://github.com/globocom/huskyCI.git"}}`))
+				Expect(err).To(Equal(webhook.ErrNotBranchPush))
+			})
+		})
+
+		Context("When a branch is deleted", func() {
+			It("Should return ErrNotBranchPush.", func() {
+				_, err := webhook.ParsePush(webhook.ProviderGitHub, []byte(`{"ref": "refs/heads/feature", "after": "0000000000000000000000000000000000000000", "deleted": true, "repository": {"clone_url": "https://github.com/globocom/huskyCI.git"}}`))
+				Expect(err).To(Equal(webhook.ErrNotBranchPush))
+				_, err = webhook.ParsePush(webhook.ProviderGitLab, []byte(`{"ref": "refs/heads/feature", "checkout_sha": null, "project": {"git_http_url": "https://gitlab.com/globocom/huskyCI.git"}}`))
+				Expect(err).To(Equal(webhook.ErrNotBranchPush))
+			})
+		})
+
+		Context("When the payload is not JSON", func() {
+			It("Should return an error.", func() {
+				_, err := webhook.ParsePush(webhook.ProviderGitHub, []byte("payload"))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -25,8 +25,19 @@ func StartAnalysis() (string, error) {
 	huskyStartAnalysisURL := config.HuskyAPI + "/analysis"
 
 	requestPayload := types.JSONPayload{
-		RepositoryURL:    config.RepositoryURL,
-		RepositoryBranch: config.RepositoryBranch,
+		RepositoryURL:     config.RepositoryURL,
+		RepositoryBranch:  config.RepositoryBranch,
+		RepositorySubPath: config.RepositorySubPath,
+		RepositoryCommit:  config.RepositoryCommit,
+		RepositoryBaseRef: config.RepositoryBaseRef,
+		ImageReference:    config.ImageReference,
+		FailFastSeverity:  config.FailFastSeverity,
+		SSHPrivateKey:     config.RepositorySSHPrivateKey,
+		SecurityTestArgs:  config.SecurityTestArgs,
+		Tags:              config.Tags,
+		SecurityTestEnv:   config.SecurityTestEnv,
+		Force:             config.ForceAnalysis,
+		Profile:           config.ScanProfile,
 	}
 
 	marshalPayload, err := json.Marshal(requestPayload)
@@ -34,7 +45,7 @@ func StartAnalysis() (string, error) {
 		return "", err
 	}
 
-	httpClient, err := util.NewClient(config.HuskyUseTLS)
+	httpClient, err := util.NewClient(config.HuskyUseTLS, config.HuskyClientCertFile, config.HuskyClientKeyFile)
 	if err != nil {
 		return "", err
 	}
@@ -54,11 +65,19 @@ func StartAnalysis() (string, error) {
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 201 {
+	// 200 is returned instead of 201 when the API has a cached analysis of the same commit.
+	if resp.StatusCode != 201 && resp.StatusCode != 200 {
 		if resp.StatusCode == 401 {
 			errorMsg := fmt.Sprintf("Unauthorized Husky-Token %s", config.HuskyToken)
 			return "", errors.New(errorMsg)
 		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			errorMsg := fmt.Sprintf("huskyCI analysis queue is full, try again in %s seconds", resp.Header.Get("Retry-After"))
+			return "", errors.New(errorMsg)
+		}
+		if err := invalidRequestError(resp); err != nil {
+			return "", err
+		}
 		errorMsg := fmt.Sprintf("Error sending request to start analysis! StatusCode received: %d", resp.StatusCode)
 		return "", errors.New(errorMsg)
 	}
@@ -77,13 +96,90 @@ func StartAnalysis() (string, error) {
 	return RID, nil
 }
 
+// invalidRequestError returns the error replied by huskyCI API when it rejects an invalid parameter of the
+// request, such as an unknown profile, or nil if it did not.
+func invalidRequestError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusBadRequest {
+		return nil
+	}
+	reply := struct {
+		Error string `json:"error"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil || reply.Error == "" {
+		return nil
+	}
+	return fmt.Errorf("invalid request: %s", reply.Error)
+}
+
+// PlanAnalysis requests a dry run of an analysis and returns the securityTests it would run, without running them.
+func PlanAnalysis() (types.AnalysisPlan, error) {
+
+	plan := types.AnalysisPlan{}
+	huskyPlanAnalysisURL := config.HuskyAPI + "/analysis/plan"
+
+	requestPayload := types.JSONPayload{
+		RepositoryURL:     config.RepositoryURL,
+		RepositoryBranch:  config.RepositoryBranch,
+		RepositorySubPath: config.RepositorySubPath,
+		RepositoryCommit:  config.RepositoryCommit,
+		ImageReference:    config.ImageReference,
+		SSHPrivateKey:     config.RepositorySSHPrivateKey,
+		Profile:           config.ScanProfile,
+	}
+
+	marshalPayload, err := json.Marshal(requestPayload)
+	if err != nil {
+		return plan, err
+	}
+
+	httpClient, err := util.NewClient(config.HuskyUseTLS, config.HuskyClientCertFile, config.HuskyClientKeyFile)
+	if err != nil {
+		return plan, err
+	}
+
+	req, err := http.NewRequest("POST", huskyPlanAnalysisURL, bytes.NewBuffer(marshalPayload))
+	if err != nil {
+		return plan, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Husky-Token", config.HuskyToken)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return plan, err
+	}
+
+	defer resp.Body.Close()
+
+	if err := invalidRequestError(resp); err != nil {
+		return plan, err
+	}
+	if resp.StatusCode != 200 {
+		if resp.StatusCode == 401 {
+			errorMsg := fmt.Sprintf("Unauthorized Husky-Token %s", config.HuskyToken)
+			return plan, errors.New(errorMsg)
+		}
+		errorMsg := fmt.Sprintf("Error sending request to plan analysis! StatusCode received: %d", resp.StatusCode)
+		return plan, errors.New(errorMsg)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return plan, err
+	}
+
+	err = json.Unmarshal(body, &plan)
+	return plan, err
+}
+
 // GetAnalysis gets the results of an analysis.
 func GetAnalysis(RID string) (types.Analysis, error) {
 
 	analysis := types.Analysis{}
 	getAnalysisURL := config.HuskyAPI + "/analysis/" + RID
 
-	httpClient, err := util.NewClient(config.HuskyUseTLS)
+	httpClient, err := util.NewClient(config.HuskyUseTLS, config.HuskyClientCertFile, config.HuskyClientKeyFile)
 	if err != nil {
 		return analysis, err
 	}
@@ -102,6 +198,15 @@ func GetAnalysis(RID string) (types.Analysis, error) {
 
 	defer resp.Body.Close()
 
+	// the status code of a found analysis may be set by its result, so only these ones are errors.
+	if resp.StatusCode == http.StatusUnauthorized {
+		errorMsg := fmt.Sprintf("Unauthorized Husky-Token %s", config.HuskyToken)
+		return analysis, errors.New(errorMsg)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return analysis, fmt.Errorf("analysis %s not found", RID)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return analysis, err
@@ -111,38 +216,71 @@ func GetAnalysis(RID string) (types.Analysis, error) {
 	if err != nil {
 		return analysis, err
 	}
+	util.SortResults(&analysis.HuskyCIResults)
 
 	return analysis, nil
 }
 
-// MonitorAnalysis will keep monitoring an analysis until it has finished or timed out.
-func MonitorAnalysis(RID string) (types.Analysis, error) {
+// ErrMonitorTimeout is returned by MonitorAnalysis when the analysis did not finish within its timeout.
+var ErrMonitorTimeout = errors.New("time out")
+
+// DefaultMonitorTimeout and DefaultPollInterval are the ones MonitorAnalysis used before they could be set.
+const (
+	DefaultMonitorTimeout = 60 * time.Minute
+	DefaultPollInterval   = 60 * time.Second
+)
+
+// MonitorAnalysis will keep monitoring an analysis, every pollInterval, until it has finished or timeout
+// has elapsed, returning ErrMonitorTimeout with the last state of the analysis in that case. Unless the
+// output is JSON, each securityTest is printed as it completes.
+func MonitorAnalysis(RID string, timeout, pollInterval time.Duration) (types.Analysis, error) {
 
 	analysis := types.Analysis{}
-	timeout := time.After(60 * time.Minute)
-	retryTick := time.NewTicker(60 * time.Second)
+	deadline := time.After(timeout)
+	retryTick := time.NewTicker(pollInterval)
+	defer retryTick.Stop()
+	completed := make(map[string]bool)
 
 	for {
+		lastAnalysis, err := GetAnalysis(RID)
+		if err != nil {
+			return lastAnalysis, err
+		}
+		analysis = lastAnalysis
+		if !types.IsJSONoutput {
+			printCompletedSecurityTests(analysis, completed)
+		}
+		if analysis.Status == "finished" {
+			return analysis, nil
+		} else if analysis.Status == "error running" {
+			return analysis, fmt.Errorf("huskyCI encountered an error trying to execute this analysis: %v", analysis.ErrorFound)
+		}
 		select {
-		case <-timeout:
-			return analysis, errors.New("time out")
+		case <-deadline:
+			return analysis, ErrMonitorTimeout
 		case <-retryTick.C:
-			analysis, err := GetAnalysis(RID)
-			if err != nil {
-				return analysis, err
-			}
-			if analysis.Status == "finished" {
-				return analysis, nil
-			} else if analysis.Status == "error running" {
-				return analysis, fmt.Errorf("huskyCI encountered an error trying to execute this analysis: %v", analysis.ErrorFound)
-			}
-			if !types.IsJSONoutput {
+			if !types.IsJSONoutput && analysis.Status == "queued" {
+				fmt.Println("[HUSKYCI][!] Hold on! huskyCI analysis is queued, waiting for a free slot...")
+			} else if !types.IsJSONoutput {
 				fmt.Println("[HUSKYCI][!] Hold on! huskyCI is still running...")
 			}
 		}
 	}
 }
 
+// printCompletedSecurityTests prints the securityTests of analysis that completed since they were last
+// printed, marking them in completed.
+func printCompletedSecurityTests(analysis types.Analysis, completed map[string]bool) {
+	for _, container := range analysis.Containers {
+		name := container.SecurityTest.Name
+		if container.CResult == "" || completed[name] || name == "gitauthors" {
+			continue
+		}
+		completed[name] = true
+		fmt.Printf("[HUSKYCI][*] %s completed: %s\n", name, container.CResult)
+	}
+}
+
 // PrintResults prints huskyCI output either in JSON or the standard output.
 func PrintResults(analysis types.Analysis) error {
 
@@ -0,0 +1,180 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/globocom/huskyCI/client/analysis"
+	"github.com/globocom/huskyCI/client/config"
+	"github.com/globocom/huskyCI/client/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlanAnalysis", func() {
+
+	var server *httptest.Server
+	var receivedPayload types.JSONPayload
+
+	plan := types.AnalysisPlan{
+		Codes: []types.Code{{Language: "Go", Files: []string{"main.go"}}},
+		SecurityTests: []types.PlannedSecurityTest{
+			{Name: "gosec", Image: "huskyci/gosec", ImageTag: "2.3.0", Type: "Language", Language: "Go", TimeOutInSeconds: 360},
+		},
+		Skipped: []types.SkippedSecurityTest{{Language: "Elixir", Reason: "no securityTest supports this language"}},
+	}
+
+	startServer := func(statusCode int) {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/analysis/plan"))
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(body, &receivedPayload)).To(Succeed())
+			w.WriteHeader(statusCode)
+			Expect(json.NewEncoder(w).Encode(plan)).To(Succeed())
+		}))
+		config.HuskyAPI = server.URL
+		config.RepositoryURL = "https://github.com/globocom/huskyCI.git"
+		config.RepositoryBranch = "master"
+	}
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When huskyCI API returns the plan", func() {
+		It("Should send the repository and return the plan.", func() {
+			startServer(http.StatusOK)
+			receivedPlan, err := analysis.PlanAnalysis()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedPlan).To(Equal(plan))
+			Expect(receivedPayload.RepositoryURL).To(Equal("https://github.com/globocom/huskyCI.git"))
+			Expect(receivedPayload.RepositoryBranch).To(Equal("master"))
+		})
+	})
+
+	Context("When a scan profile is set", func() {
+		AfterEach(func() {
+			config.ScanProfile = ""
+		})
+		It("Should send it with the repository.", func() {
+			startServer(http.StatusOK)
+			config.ScanProfile = "quick"
+			_, err := analysis.PlanAnalysis()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedPayload.Profile).To(Equal("quick"))
+		})
+	})
+
+	Context("When huskyCI API returns an error", func() {
+		It("Should return an error.", func() {
+			startServer(http.StatusInternalServerError)
+			_, err := analysis.PlanAnalysis()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("StartAnalysis", func() {
+
+	var server *httptest.Server
+	AfterEach(func() {
+		server.Close()
+		config.ScanProfile = ""
+	})
+
+	Context("When huskyCI API rejects the scan profile", func() {
+		It("Should return the error replied by the API.", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"success": false, "error": "unknown profile \"nightly\""}`))
+			}))
+			config.HuskyAPI = server.URL
+			config.ScanProfile = "nightly"
+			_, err := analysis.StartAnalysis()
+			Expect(err).To(MatchError(`invalid request: unknown profile "nightly"`))
+		})
+	})
+
+	Context("When the analysis queue of huskyCI API is full", func() {
+		It("Should return an error telling when to try again.", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"success": false, "error": "the analysis queue is full, try again later"}`))
+			}))
+			config.HuskyAPI = server.URL
+			_, err := analysis.StartAnalysis()
+			Expect(err).To(MatchError("huskyCI analysis queue is full, try again in 30 seconds"))
+		})
+	})
+})
+
+var _ = Describe("MonitorAnalysis", func() {
+
+	var server *httptest.Server
+	var polls int
+	BeforeEach(func() {
+		polls = 0
+		types.IsJSONoutput = true
+	})
+	AfterEach(func() {
+		server.Close()
+		types.IsJSONoutput = false
+	})
+
+	Context("When the analysis finishes", func() {
+		It("Should poll until it has finished and return it.", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/analysis/a1b2c3"))
+				polls++
+				status := "running"
+				if polls == 3 {
+					status = "finished"
+				}
+				w.Write([]byte(`{"RID": "a1b2c3", "status": "` + status + `", "result": "passed"}`))
+			}))
+			config.HuskyAPI = server.URL
+			huskyAnalysis, err := analysis.MonitorAnalysis("a1b2c3", time.Minute, time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(polls).To(Equal(3))
+			Expect(huskyAnalysis.Status).To(Equal("finished"))
+		})
+	})
+
+	Context("When the analysis does not finish within the timeout", func() {
+		It("Should return ErrMonitorTimeout with the last state of the analysis.", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"RID": "a1b2c3", "status": "running"}`))
+			}))
+			config.HuskyAPI = server.URL
+			huskyAnalysis, err := analysis.MonitorAnalysis("a1b2c3", 20*time.Millisecond, time.Millisecond)
+			Expect(err).To(Equal(analysis.ErrMonitorTimeout))
+			Expect(huskyAnalysis.RID).To(Equal("a1b2c3"))
+			Expect(huskyAnalysis.Status).To(Equal("running"))
+		})
+	})
+
+	Context("When the analysis is not found", func() {
+		It("Should return an error without polling again.", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				polls++
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			config.HuskyAPI = server.URL
+			_, err := analysis.MonitorAnalysis("a1b2c3", time.Minute, time.Millisecond)
+			Expect(err).To(MatchError("analysis a1b2c3 not found"))
+			Expect(polls).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/globocom/huskyCI/client/types"
+)
+
+const (
+	// ExitCodeClean is returned when no vulnerabilities at or above the minimum severity were found.
+	ExitCodeClean = 0
+	// ExitCodeThresholdBreach is returned when vulnerabilities at or above the minimum severity were found.
+	ExitCodeThresholdBreach = 1
+	// ExitCodeError is returned when huskyci-client or huskyCI API could not complete the analysis.
+	ExitCodeError = 2
+)
+
+var severityLevels = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+}
+
+// CheckMinSeverity returns an error if the given minimum severity is not low, medium or high.
+func CheckMinSeverity(minSeverity string) error {
+	if _, ok := severityLevels[strings.ToLower(minSeverity)]; !ok {
+		return fmt.Errorf("invalid minimum severity %q: expected low, medium or high", minSeverity)
+	}
+	return nil
+}
+
+// ExitCode returns the exit code huskyci-client should use given a summary and the minimum severity that breaks the build.
+func ExitCode(summary types.HuskyCISummary, minSeverity string) int {
+	minLevel, ok := severityLevels[strings.ToLower(minSeverity)]
+	if !ok {
+		return ExitCodeError
+	}
+	if summary.HighVuln > 0 && minLevel <= severityLevels["high"] {
+		return ExitCodeThresholdBreach
+	}
+	if summary.MediumVuln > 0 && minLevel <= severityLevels["medium"] {
+		return ExitCodeThresholdBreach
+	}
+	if summary.LowVuln > 0 && minLevel <= severityLevels["low"] {
+		return ExitCodeThresholdBreach
+	}
+	return ExitCodeClean
+}
+
+// GetTotalSummary returns the total summary of the last analysis printed by PrintResults.
+func GetTotalSummary() types.HuskyCISummary {
+	return outputJSON.Summary.TotalSummary
+}
+
+// GetSummary returns the summaries, by securityTest, of the last analysis printed by PrintResults.
+func GetSummary() types.Summary {
+	return outputJSON.Summary
+}
+
+// BreachingSecurityTests returns the names, as image:tag, of the failed securityTests of containers whose
+// vulnerabilities in summary are at or above minSeverity, so that a securityTest failed by the API for findings
+// below minSeverity is not reported as blocking. A securityTest without a summary of its own is kept.
+func BreachingSecurityTests(containers []types.Container, summary types.Summary, minSeverity string) []string {
+	var breaching []string
+	for _, container := range containers {
+		if container.CResult != "failed" {
+			continue
+		}
+		if securityTestSummary, ok := summaryOf(container.SecurityTest.Name, summary); ok && ExitCode(securityTestSummary, minSeverity) != ExitCodeThresholdBreach {
+			continue
+		}
+		breaching = append(breaching, fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag))
+	}
+	return breaching
+}
+
+func summaryOf(securityTestName string, summary types.Summary) (types.HuskyCISummary, bool) {
+	summaries := map[string]types.HuskyCISummary{
+		"gosec":                 summary.GosecSummary,
+		"bandit":                summary.BanditSummary,
+		"safety":                summary.SafetySummary,
+		"npmaudit":              summary.NpmAuditSummary,
+		"yarnaudit":             summary.YarnAuditSummary,
+		"brakeman":              summary.BrakemanSummary,
+		"spotbugs":              summary.SpotBugsSummary,
+		"gitleaks":              summary.GitleaksSummary,
+		"tfsec":                 summary.TFSecSummary,
+		"hadolint":              summary.HadolintSummary,
+		"dependencycheck":       summary.DependencyCheckSummary,
+		"trivy":                 summary.TrivySummary,
+		"detekt":                summary.DetektSummary,
+		"dependencycheckgradle": summary.DependencyCheckGradleSummary,
+		"osvscanner":            summary.OSVScannerSummary,
+		"govulncheck":           summary.GovulncheckSummary,
+	}
+	securityTestSummary, ok := summaries[securityTestName]
+	return securityTestSummary, ok
+}
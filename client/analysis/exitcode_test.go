@@ -0,0 +1,62 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis_test
+
+import (
+	"github.com/globocom/huskyCI/client/analysis"
+	"github.com/globocom/huskyCI/client/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExitCode", func() {
+
+	clean := types.HuskyCISummary{}
+	lowOnly := types.HuskyCISummary{FoundInfo: true, LowVuln: 3}
+	mediumOnly := types.HuskyCISummary{FoundVuln: true, MediumVuln: 2}
+	high := types.HuskyCISummary{FoundVuln: true, HighVuln: 1, MediumVuln: 2, LowVuln: 3}
+
+	DescribeTable("Summary and minimum severity",
+		func(summary types.HuskyCISummary, minSeverity string, expectedExitCode int) {
+			Expect(analysis.ExitCode(summary, minSeverity)).To(Equal(expectedExitCode))
+		},
+		Entry("clean analysis with high threshold", clean, "high", analysis.ExitCodeClean),
+		Entry("clean analysis with low threshold", clean, "low", analysis.ExitCodeClean),
+		Entry("low findings with medium threshold", lowOnly, "medium", analysis.ExitCodeClean),
+		Entry("low findings with low threshold", lowOnly, "low", analysis.ExitCodeThresholdBreach),
+		Entry("medium findings with high threshold", mediumOnly, "high", analysis.ExitCodeClean),
+		Entry("medium findings with medium threshold", mediumOnly, "MEDIUM", analysis.ExitCodeThresholdBreach),
+		Entry("high findings with high threshold", high, "high", analysis.ExitCodeThresholdBreach),
+		Entry("invalid threshold", high, "critical", analysis.ExitCodeError),
+	)
+
+	Describe("BreachingSecurityTests", func() {
+		containers := []types.Container{
+			{SecurityTest: types.SecurityTest{Name: "gosec", Image: "huskyci/gosec", ImageTag: "2.4.0"}, CResult: "failed"},
+			{SecurityTest: types.SecurityTest{Name: "bandit", Image: "huskyci/bandit", ImageTag: "1.6.2"}, CResult: "failed"},
+			{SecurityTest: types.SecurityTest{Name: "safety", Image: "huskyci/safety", ImageTag: "1.8.7"}, CResult: "passed"},
+			{SecurityTest: types.SecurityTest{Name: "custom", Image: "example/custom", ImageTag: "1.0"}, CResult: "failed"},
+		}
+		summary := types.Summary{GosecSummary: high, BanditSummary: lowOnly, SafetySummary: high}
+
+		It("Should keep only the failed securityTests with findings at or above the minimum severity.", func() {
+			Expect(analysis.BreachingSecurityTests(containers, summary, "medium")).To(Equal([]string{"huskyci/gosec:2.4.0", "example/custom:1.0"}))
+			Expect(analysis.BreachingSecurityTests(containers, summary, "low")).To(Equal([]string{"huskyci/gosec:2.4.0", "huskyci/bandit:1.6.2", "example/custom:1.0"}))
+		})
+	})
+
+	Describe("CheckMinSeverity", func() {
+		It("Should accept low, medium and high.", func() {
+			Expect(analysis.CheckMinSeverity("low")).To(Succeed())
+			Expect(analysis.CheckMinSeverity("Medium")).To(Succeed())
+			Expect(analysis.CheckMinSeverity("HIGH")).To(Succeed())
+		})
+		It("Should return an error for an unknown severity.", func() {
+			Expect(analysis.CheckMinSeverity("critical")).NotTo(Succeed())
+		})
+	})
+})
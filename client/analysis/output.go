@@ -72,6 +72,41 @@ func printSTDOUTOutput(analysis types.Analysis) {
 	printSTDOUTOutputTFSec(outputJSON.HclResults.HuskyCITFSecOutput.MediumVulns)
 	printSTDOUTOutputTFSec(outputJSON.HclResults.HuskyCITFSecOutput.HighVulns)
 
+	// hadolint
+	printSTDOUTOutputHadolint(outputJSON.DockerfileResults.HuskyCIHadolintOutput.LowVulns)
+	printSTDOUTOutputHadolint(outputJSON.DockerfileResults.HuskyCIHadolintOutput.MediumVulns)
+	printSTDOUTOutputHadolint(outputJSON.DockerfileResults.HuskyCIHadolintOutput.HighVulns)
+
+	// dependencycheck
+	printSTDOUTOutputDependencyCheck(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.LowVulns)
+	printSTDOUTOutputDependencyCheck(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.MediumVulns)
+	printSTDOUTOutputDependencyCheck(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.HighVulns)
+
+	// detekt
+	printSTDOUTOutputDetekt(outputJSON.KotlinResults.HuskyCIDetektOutput.LowVulns)
+	printSTDOUTOutputDetekt(outputJSON.KotlinResults.HuskyCIDetektOutput.MediumVulns)
+	printSTDOUTOutputDetekt(outputJSON.KotlinResults.HuskyCIDetektOutput.HighVulns)
+
+	// dependencycheckgradle
+	printSTDOUTOutputDependencyCheck(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.LowVulns)
+	printSTDOUTOutputDependencyCheck(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.MediumVulns)
+	printSTDOUTOutputDependencyCheck(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.HighVulns)
+
+	// osvscanner
+	printSTDOUTOutputTrivy(outputJSON.DartResults.HuskyCIOSVScannerOutput.LowVulns)
+	printSTDOUTOutputTrivy(outputJSON.DartResults.HuskyCIOSVScannerOutput.MediumVulns)
+	printSTDOUTOutputTrivy(outputJSON.DartResults.HuskyCIOSVScannerOutput.HighVulns)
+
+	// govulncheck
+	printSTDOUTOutputGovulncheck(outputJSON.GoResults.HuskyCIGovulncheckOutput.LowVulns)
+	printSTDOUTOutputGovulncheck(outputJSON.GoResults.HuskyCIGovulncheckOutput.MediumVulns)
+	printSTDOUTOutputGovulncheck(outputJSON.GoResults.HuskyCIGovulncheckOutput.HighVulns)
+
+	// trivy
+	printSTDOUTOutputTrivy(outputJSON.GenericResults.HuskyCITrivyOutput.LowVulns)
+	printSTDOUTOutputTrivy(outputJSON.GenericResults.HuskyCITrivyOutput.MediumVulns)
+	printSTDOUTOutputTrivy(outputJSON.GenericResults.HuskyCITrivyOutput.HighVulns)
+
 	printAllSummary(analysis)
 }
 
@@ -85,6 +120,10 @@ func prepareAllSummary(analysis types.Analysis) {
 	outputJSON.RubyResults = analysis.HuskyCIResults.RubyResults
 	outputJSON.JavaResults = analysis.HuskyCIResults.JavaResults
 	outputJSON.HclResults = analysis.HuskyCIResults.HclResults
+	outputJSON.DockerfileResults = analysis.HuskyCIResults.DockerfileResults
+	outputJSON.SwiftResults = analysis.HuskyCIResults.SwiftResults
+	outputJSON.KotlinResults = analysis.HuskyCIResults.KotlinResults
+	outputJSON.DartResults = analysis.HuskyCIResults.DartResults
 	outputJSON.GenericResults = analysis.HuskyCIResults.GenericResults
 
 	// GoSec summary
@@ -189,22 +228,99 @@ func prepareAllSummary(analysis types.Analysis) {
 		outputJSON.Summary.TFSecSummary.FoundVuln = true
 	}
 
+	// Hadolint summary
+	outputJSON.Summary.HadolintSummary.LowVuln = len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.LowVulns)
+	outputJSON.Summary.HadolintSummary.MediumVuln = len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.MediumVulns)
+	outputJSON.Summary.HadolintSummary.HighVuln = len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.HighVulns)
+	if len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.LowVulns) > 0 || len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.HadolintSummary.FoundInfo = true
+	}
+	if len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.MediumVulns) > 0 || len(outputJSON.DockerfileResults.HuskyCIHadolintOutput.HighVulns) > 0 {
+		outputJSON.Summary.HadolintSummary.FoundVuln = true
+	}
+
+	// Dependency-Check summary
+	outputJSON.Summary.DependencyCheckSummary.LowVuln = len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.LowVulns)
+	outputJSON.Summary.DependencyCheckSummary.MediumVuln = len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.MediumVulns)
+	outputJSON.Summary.DependencyCheckSummary.HighVuln = len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.HighVulns)
+	if len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.LowVulns) > 0 || len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.DependencyCheckSummary.FoundInfo = true
+	}
+	if len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.MediumVulns) > 0 || len(outputJSON.SwiftResults.HuskyCIDependencyCheckOutput.HighVulns) > 0 {
+		outputJSON.Summary.DependencyCheckSummary.FoundVuln = true
+	}
+
+	// Trivy summary
+	outputJSON.Summary.TrivySummary.LowVuln = len(outputJSON.GenericResults.HuskyCITrivyOutput.LowVulns)
+	outputJSON.Summary.TrivySummary.MediumVuln = len(outputJSON.GenericResults.HuskyCITrivyOutput.MediumVulns)
+	outputJSON.Summary.TrivySummary.HighVuln = len(outputJSON.GenericResults.HuskyCITrivyOutput.HighVulns)
+	if len(outputJSON.GenericResults.HuskyCITrivyOutput.LowVulns) > 0 || len(outputJSON.GenericResults.HuskyCITrivyOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.TrivySummary.FoundInfo = true
+	}
+	if len(outputJSON.GenericResults.HuskyCITrivyOutput.MediumVulns) > 0 || len(outputJSON.GenericResults.HuskyCITrivyOutput.HighVulns) > 0 {
+		outputJSON.Summary.TrivySummary.FoundVuln = true
+	}
+
+	// Detekt summary
+	outputJSON.Summary.DetektSummary.LowVuln = len(outputJSON.KotlinResults.HuskyCIDetektOutput.LowVulns)
+	outputJSON.Summary.DetektSummary.MediumVuln = len(outputJSON.KotlinResults.HuskyCIDetektOutput.MediumVulns)
+	outputJSON.Summary.DetektSummary.HighVuln = len(outputJSON.KotlinResults.HuskyCIDetektOutput.HighVulns)
+	if len(outputJSON.KotlinResults.HuskyCIDetektOutput.LowVulns) > 0 || len(outputJSON.KotlinResults.HuskyCIDetektOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.DetektSummary.FoundInfo = true
+	}
+	if len(outputJSON.KotlinResults.HuskyCIDetektOutput.MediumVulns) > 0 || len(outputJSON.KotlinResults.HuskyCIDetektOutput.HighVulns) > 0 {
+		outputJSON.Summary.DetektSummary.FoundVuln = true
+	}
+
+	// Dependency-Check Gradle summary
+	outputJSON.Summary.DependencyCheckGradleSummary.LowVuln = len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.LowVulns)
+	outputJSON.Summary.DependencyCheckGradleSummary.MediumVuln = len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.MediumVulns)
+	outputJSON.Summary.DependencyCheckGradleSummary.HighVuln = len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.HighVulns)
+	if len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.LowVulns) > 0 || len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.DependencyCheckGradleSummary.FoundInfo = true
+	}
+	if len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.MediumVulns) > 0 || len(outputJSON.KotlinResults.HuskyCIDependencyCheckGradleOutput.HighVulns) > 0 {
+		outputJSON.Summary.DependencyCheckGradleSummary.FoundVuln = true
+	}
+
+	// OSV-Scanner summary
+	outputJSON.Summary.OSVScannerSummary.LowVuln = len(outputJSON.DartResults.HuskyCIOSVScannerOutput.LowVulns)
+	outputJSON.Summary.OSVScannerSummary.MediumVuln = len(outputJSON.DartResults.HuskyCIOSVScannerOutput.MediumVulns)
+	outputJSON.Summary.OSVScannerSummary.HighVuln = len(outputJSON.DartResults.HuskyCIOSVScannerOutput.HighVulns)
+	if len(outputJSON.DartResults.HuskyCIOSVScannerOutput.LowVulns) > 0 || len(outputJSON.DartResults.HuskyCIOSVScannerOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.OSVScannerSummary.FoundInfo = true
+	}
+	if len(outputJSON.DartResults.HuskyCIOSVScannerOutput.MediumVulns) > 0 || len(outputJSON.DartResults.HuskyCIOSVScannerOutput.HighVulns) > 0 {
+		outputJSON.Summary.OSVScannerSummary.FoundVuln = true
+	}
+
+	// govulncheck summary
+	outputJSON.Summary.GovulncheckSummary.LowVuln = len(outputJSON.GoResults.HuskyCIGovulncheckOutput.LowVulns)
+	outputJSON.Summary.GovulncheckSummary.MediumVuln = len(outputJSON.GoResults.HuskyCIGovulncheckOutput.MediumVulns)
+	outputJSON.Summary.GovulncheckSummary.HighVuln = len(outputJSON.GoResults.HuskyCIGovulncheckOutput.HighVulns)
+	if len(outputJSON.GoResults.HuskyCIGovulncheckOutput.LowVulns) > 0 || len(outputJSON.GoResults.HuskyCIGovulncheckOutput.NoSecVulns) > 0 {
+		outputJSON.Summary.GovulncheckSummary.FoundInfo = true
+	}
+	if len(outputJSON.GoResults.HuskyCIGovulncheckOutput.MediumVulns) > 0 || len(outputJSON.GoResults.HuskyCIGovulncheckOutput.HighVulns) > 0 {
+		outputJSON.Summary.GovulncheckSummary.FoundVuln = true
+	}
+
 	// Total summary
-	if outputJSON.Summary.GosecSummary.FoundVuln || outputJSON.Summary.BanditSummary.FoundVuln || outputJSON.Summary.SafetySummary.FoundVuln || outputJSON.Summary.BrakemanSummary.FoundVuln || outputJSON.Summary.NpmAuditSummary.FoundVuln || outputJSON.Summary.YarnAuditSummary.FoundVuln || outputJSON.Summary.GitleaksSummary.FoundVuln || outputJSON.Summary.SpotBugsSummary.FoundVuln || outputJSON.Summary.TFSecSummary.FoundVuln {
+	if outputJSON.Summary.GosecSummary.FoundVuln || outputJSON.Summary.BanditSummary.FoundVuln || outputJSON.Summary.SafetySummary.FoundVuln || outputJSON.Summary.BrakemanSummary.FoundVuln || outputJSON.Summary.NpmAuditSummary.FoundVuln || outputJSON.Summary.YarnAuditSummary.FoundVuln || outputJSON.Summary.GitleaksSummary.FoundVuln || outputJSON.Summary.SpotBugsSummary.FoundVuln || outputJSON.Summary.TFSecSummary.FoundVuln || outputJSON.Summary.HadolintSummary.FoundVuln || outputJSON.Summary.DependencyCheckSummary.FoundVuln || outputJSON.Summary.TrivySummary.FoundVuln || outputJSON.Summary.DetektSummary.FoundVuln || outputJSON.Summary.DependencyCheckGradleSummary.FoundVuln || outputJSON.Summary.OSVScannerSummary.FoundVuln || outputJSON.Summary.GovulncheckSummary.FoundVuln {
 		outputJSON.Summary.TotalSummary.FoundVuln = true
 		types.FoundVuln = true
-	} else if outputJSON.Summary.GosecSummary.FoundInfo || outputJSON.Summary.BanditSummary.FoundInfo || outputJSON.Summary.SafetySummary.FoundInfo || outputJSON.Summary.BrakemanSummary.FoundInfo || outputJSON.Summary.NpmAuditSummary.FoundInfo || outputJSON.Summary.YarnAuditSummary.FoundInfo || outputJSON.Summary.GitleaksSummary.FoundInfo || outputJSON.Summary.SpotBugsSummary.FoundInfo || outputJSON.Summary.TFSecSummary.FoundInfo {
+	} else if outputJSON.Summary.GosecSummary.FoundInfo || outputJSON.Summary.BanditSummary.FoundInfo || outputJSON.Summary.SafetySummary.FoundInfo || outputJSON.Summary.BrakemanSummary.FoundInfo || outputJSON.Summary.NpmAuditSummary.FoundInfo || outputJSON.Summary.YarnAuditSummary.FoundInfo || outputJSON.Summary.GitleaksSummary.FoundInfo || outputJSON.Summary.SpotBugsSummary.FoundInfo || outputJSON.Summary.TFSecSummary.FoundInfo || outputJSON.Summary.HadolintSummary.FoundInfo || outputJSON.Summary.DependencyCheckSummary.FoundInfo || outputJSON.Summary.TrivySummary.FoundInfo || outputJSON.Summary.DetektSummary.FoundInfo || outputJSON.Summary.DependencyCheckGradleSummary.FoundInfo || outputJSON.Summary.OSVScannerSummary.FoundInfo || outputJSON.Summary.GovulncheckSummary.FoundInfo {
 		outputJSON.Summary.TotalSummary.FoundInfo = true
 		types.FoundInfo = true
 	}
 
 	totalNoSec = outputJSON.Summary.BanditSummary.NoSecVuln + outputJSON.Summary.GosecSummary.NoSecVuln + outputJSON.Summary.GitleaksSummary.NoSecVuln
 
-	totalLow = outputJSON.Summary.BrakemanSummary.LowVuln + outputJSON.Summary.SafetySummary.LowVuln + outputJSON.Summary.BanditSummary.LowVuln + outputJSON.Summary.GosecSummary.LowVuln + outputJSON.Summary.NpmAuditSummary.LowVuln + outputJSON.Summary.YarnAuditSummary.LowVuln + outputJSON.Summary.GitleaksSummary.LowVuln + outputJSON.Summary.SpotBugsSummary.LowVuln + outputJSON.Summary.TFSecSummary.LowVuln
+	totalLow = outputJSON.Summary.BrakemanSummary.LowVuln + outputJSON.Summary.SafetySummary.LowVuln + outputJSON.Summary.BanditSummary.LowVuln + outputJSON.Summary.GosecSummary.LowVuln + outputJSON.Summary.NpmAuditSummary.LowVuln + outputJSON.Summary.YarnAuditSummary.LowVuln + outputJSON.Summary.GitleaksSummary.LowVuln + outputJSON.Summary.SpotBugsSummary.LowVuln + outputJSON.Summary.TFSecSummary.LowVuln + outputJSON.Summary.HadolintSummary.LowVuln + outputJSON.Summary.DependencyCheckSummary.LowVuln + outputJSON.Summary.TrivySummary.LowVuln + outputJSON.Summary.DetektSummary.LowVuln + outputJSON.Summary.DependencyCheckGradleSummary.LowVuln + outputJSON.Summary.OSVScannerSummary.LowVuln + outputJSON.Summary.GovulncheckSummary.LowVuln
 
-	totalMedium = outputJSON.Summary.BrakemanSummary.MediumVuln + outputJSON.Summary.SafetySummary.MediumVuln + outputJSON.Summary.BanditSummary.MediumVuln + outputJSON.Summary.GosecSummary.MediumVuln + outputJSON.Summary.NpmAuditSummary.MediumVuln + outputJSON.Summary.YarnAuditSummary.MediumVuln + outputJSON.Summary.GitleaksSummary.MediumVuln + outputJSON.Summary.SpotBugsSummary.MediumVuln + outputJSON.Summary.TFSecSummary.MediumVuln
+	totalMedium = outputJSON.Summary.BrakemanSummary.MediumVuln + outputJSON.Summary.SafetySummary.MediumVuln + outputJSON.Summary.BanditSummary.MediumVuln + outputJSON.Summary.GosecSummary.MediumVuln + outputJSON.Summary.NpmAuditSummary.MediumVuln + outputJSON.Summary.YarnAuditSummary.MediumVuln + outputJSON.Summary.GitleaksSummary.MediumVuln + outputJSON.Summary.SpotBugsSummary.MediumVuln + outputJSON.Summary.TFSecSummary.MediumVuln + outputJSON.Summary.HadolintSummary.MediumVuln + outputJSON.Summary.DependencyCheckSummary.MediumVuln + outputJSON.Summary.TrivySummary.MediumVuln + outputJSON.Summary.DetektSummary.MediumVuln + outputJSON.Summary.DependencyCheckGradleSummary.MediumVuln + outputJSON.Summary.OSVScannerSummary.MediumVuln + outputJSON.Summary.GovulncheckSummary.MediumVuln
 
-	totalHigh = outputJSON.Summary.BrakemanSummary.HighVuln + outputJSON.Summary.SafetySummary.HighVuln + outputJSON.Summary.BanditSummary.HighVuln + outputJSON.Summary.GosecSummary.HighVuln + outputJSON.Summary.NpmAuditSummary.HighVuln + outputJSON.Summary.YarnAuditSummary.HighVuln + outputJSON.Summary.GitleaksSummary.HighVuln + outputJSON.Summary.SpotBugsSummary.HighVuln + outputJSON.Summary.TFSecSummary.HighVuln
+	totalHigh = outputJSON.Summary.BrakemanSummary.HighVuln + outputJSON.Summary.SafetySummary.HighVuln + outputJSON.Summary.BanditSummary.HighVuln + outputJSON.Summary.GosecSummary.HighVuln + outputJSON.Summary.NpmAuditSummary.HighVuln + outputJSON.Summary.YarnAuditSummary.HighVuln + outputJSON.Summary.GitleaksSummary.HighVuln + outputJSON.Summary.SpotBugsSummary.HighVuln + outputJSON.Summary.TFSecSummary.HighVuln + outputJSON.Summary.HadolintSummary.HighVuln + outputJSON.Summary.DependencyCheckSummary.HighVuln + outputJSON.Summary.TrivySummary.HighVuln + outputJSON.Summary.DetektSummary.HighVuln + outputJSON.Summary.DependencyCheckGradleSummary.HighVuln + outputJSON.Summary.OSVScannerSummary.HighVuln + outputJSON.Summary.GovulncheckSummary.HighVuln
 
 	outputJSON.Summary.TotalSummary.HighVuln = totalHigh
 	outputJSON.Summary.TotalSummary.MediumVuln = totalMedium
@@ -215,7 +331,7 @@ func prepareAllSummary(analysis types.Analysis) {
 
 func printAllSummary(analysis types.Analysis) {
 
-	var gosecVersion, banditVersion, safetyVersion, brakemanVersion, npmauditVersion, yarnauditVersion, gitleaksVersion, spotbugsVersion, tfsecVersion string
+	var gosecVersion, banditVersion, safetyVersion, brakemanVersion, npmauditVersion, yarnauditVersion, gitleaksVersion, spotbugsVersion, tfsecVersion, hadolintVersion, dependencycheckVersion, trivyVersion, detektVersion, dependencycheckgradleVersion, osvscannerVersion, govulncheckVersion string
 
 	for _, container := range analysis.Containers {
 		switch container.SecurityTest.Name {
@@ -237,6 +353,20 @@ func printAllSummary(analysis types.Analysis) {
 			gitleaksVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
 		case "tfsec":
 			tfsecVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "hadolint":
+			hadolintVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "dependencycheck":
+			dependencycheckVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "trivy":
+			trivyVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "detekt":
+			detektVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "dependencycheckgradle":
+			dependencycheckgradleVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "osvscanner":
+			osvscannerVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
+		case "govulncheck":
+			govulncheckVersion = fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
 		}
 	}
 
@@ -312,6 +442,60 @@ func printAllSummary(analysis types.Analysis) {
 		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.TFSecSummary.NoSecVuln)
 	}
 
+	if outputJSON.Summary.HadolintSummary.FoundVuln || outputJSON.Summary.HadolintSummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Dockerfile -> %s\n", hadolintVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.HadolintSummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.HadolintSummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.HadolintSummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.HadolintSummary.NoSecVuln)
+	}
+
+	if outputJSON.Summary.DependencyCheckSummary.FoundVuln || outputJSON.Summary.DependencyCheckSummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Swift -> %s\n", dependencycheckVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.DependencyCheckSummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.DependencyCheckSummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.DependencyCheckSummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.DependencyCheckSummary.NoSecVuln)
+	}
+
+	if outputJSON.Summary.DetektSummary.FoundVuln || outputJSON.Summary.DetektSummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Kotlin -> %s\n", detektVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.DetektSummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.DetektSummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.DetektSummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.DetektSummary.NoSecVuln)
+	}
+
+	if outputJSON.Summary.DependencyCheckGradleSummary.FoundVuln || outputJSON.Summary.DependencyCheckGradleSummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Kotlin -> %s\n", dependencycheckgradleVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.DependencyCheckGradleSummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.DependencyCheckGradleSummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.DependencyCheckGradleSummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.DependencyCheckGradleSummary.NoSecVuln)
+	}
+
+	if outputJSON.Summary.OSVScannerSummary.FoundVuln || outputJSON.Summary.OSVScannerSummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Dart -> %s\n", osvscannerVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.OSVScannerSummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.OSVScannerSummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.OSVScannerSummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.OSVScannerSummary.NoSecVuln)
+	}
+
+	if outputJSON.Summary.GovulncheckSummary.FoundVuln || outputJSON.Summary.GovulncheckSummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Go -> %s\n", govulncheckVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.GovulncheckSummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.GovulncheckSummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.GovulncheckSummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.GovulncheckSummary.NoSecVuln)
+	}
+
 	if outputJSON.Summary.GitleaksSummary.FoundVuln || outputJSON.Summary.GitleaksSummary.FoundInfo {
 		fmt.Println()
 		fmt.Printf("[HUSKYCI][SUMMARY] Generic -> %s\n", gitleaksVersion)
@@ -321,6 +505,15 @@ func printAllSummary(analysis types.Analysis) {
 		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.GitleaksSummary.NoSecVuln)
 	}
 
+	if outputJSON.Summary.TrivySummary.FoundVuln || outputJSON.Summary.TrivySummary.FoundInfo {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][SUMMARY] Generic -> %s\n", trivyVersion)
+		fmt.Printf("[HUSKYCI][SUMMARY] High: %d\n", outputJSON.Summary.TrivySummary.HighVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Medium: %d\n", outputJSON.Summary.TrivySummary.MediumVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] Low: %d\n", outputJSON.Summary.TrivySummary.LowVuln)
+		fmt.Printf("[HUSKYCI][SUMMARY] NoSecHusky: %d\n", outputJSON.Summary.TrivySummary.NoSecVuln)
+	}
+
 	if outputJSON.Summary.TotalSummary.FoundVuln || outputJSON.Summary.TotalSummary.FoundInfo {
 		fmt.Println()
 		fmt.Printf("[HUSKYCI][SUMMARY] Total\n")
@@ -373,6 +566,7 @@ func printSTDOUTOutputSafety(issues []types.HuskyCIVulnerability) {
 		if issue.Details != "requirements.txt not found" && !strings.Contains(issue.Details, "Unpinned requirement ") {
 			fmt.Printf("[HUSKYCI][!] Code: %s\n", issue.Code)
 			fmt.Printf("[HUSKYCI][!] Vulnerable Below: %s\n", issue.VunerableBelow)
+			printCVSS(issue)
 		}
 		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
 	}
@@ -404,6 +598,7 @@ func printSTDOUTOutputNpmAudit(issues []types.HuskyCIVulnerability) {
 			fmt.Printf("[HUSKYCI][!] Code: %s\n", issue.Code)
 			fmt.Printf("[HUSKYCI][!] Version: %s\n", issue.Version)
 			fmt.Printf("[HUSKYCI][!] Vulnerable Below: %s\n", issue.VunerableBelow)
+			printCVSS(issue)
 		}
 		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
 	}
@@ -421,6 +616,7 @@ func printSTDOUTOutputYarnAudit(issues []types.HuskyCIVulnerability) {
 			fmt.Printf("[HUSKYCI][!] Occurrences: %d\n", issue.Occurrences)
 			fmt.Printf("[HUSKYCI][!] Version: %s\n", issue.Version)
 			fmt.Printf("[HUSKYCI][!] Vulnerable Below: %s\n", issue.VunerableBelow)
+			printCVSS(issue)
 		}
 		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
 	}
@@ -455,6 +651,79 @@ func printSTDOUTOutputTFSec(issues []types.HuskyCIVulnerability) {
 	}
 }
 
+func printSTDOUTOutputHadolint(issues []types.HuskyCIVulnerability) {
+	for _, issue := range issues {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][!] Title: %s\n", issue.Title)
+		fmt.Printf("[HUSKYCI][!] Language: %s\n", issue.Language)
+		fmt.Printf("[HUSKYCI][!] Tool: %s\n", issue.SecurityTool)
+		fmt.Printf("[HUSKYCI][!] Severity: %s\n", issue.Severity)
+		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
+		fmt.Printf("[HUSKYCI][!] File: %s\n", issue.File)
+		fmt.Printf("[HUSKYCI][!] Line: %s\n", issue.Line)
+	}
+}
+
+func printSTDOUTOutputDetekt(issues []types.HuskyCIVulnerability) {
+	for _, issue := range issues {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][!] Title: %s\n", issue.Title)
+		fmt.Printf("[HUSKYCI][!] Language: %s\n", issue.Language)
+		fmt.Printf("[HUSKYCI][!] Tool: %s\n", issue.SecurityTool)
+		fmt.Printf("[HUSKYCI][!] Severity: %s\n", issue.Severity)
+		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
+		fmt.Printf("[HUSKYCI][!] File: %s\n", issue.File)
+		fmt.Printf("[HUSKYCI][!] Line: %s\n", issue.Line)
+	}
+}
+
+func printSTDOUTOutputDependencyCheck(issues []types.HuskyCIVulnerability) {
+	for _, issue := range issues {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][!] Title: %s\n", issue.Title)
+		fmt.Printf("[HUSKYCI][!] Language: %s\n", issue.Language)
+		fmt.Printf("[HUSKYCI][!] Tool: %s\n", issue.SecurityTool)
+		fmt.Printf("[HUSKYCI][!] Severity: %s\n", issue.Severity)
+		fmt.Printf("[HUSKYCI][!] Code: %s\n", issue.Code)
+		fmt.Printf("[HUSKYCI][!] Version: %s\n", issue.Version)
+		fmt.Printf("[HUSKYCI][!] Advisory: %s\n", issue.CVE)
+		fmt.Printf("[HUSKYCI][!] File: %s\n", issue.File)
+		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
+	}
+}
+
+func printSTDOUTOutputTrivy(issues []types.HuskyCIVulnerability) {
+	for _, issue := range issues {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][!] Title: %s\n", issue.Title)
+		fmt.Printf("[HUSKYCI][!] Tool: %s\n", issue.SecurityTool)
+		fmt.Printf("[HUSKYCI][!] Severity: %s\n", issue.Severity)
+		fmt.Printf("[HUSKYCI][!] Code: %s\n", issue.Code)
+		fmt.Printf("[HUSKYCI][!] Version: %s\n", issue.Version)
+		fmt.Printf("[HUSKYCI][!] Advisory: %s\n", issue.CVE)
+		fmt.Printf("[HUSKYCI][!] Target: %s\n", issue.File)
+		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
+	}
+}
+
+func printSTDOUTOutputGovulncheck(issues []types.HuskyCIVulnerability) {
+	for _, issue := range issues {
+		fmt.Println()
+		fmt.Printf("[HUSKYCI][!] Title: %s\n", issue.Title)
+		fmt.Printf("[HUSKYCI][!] Tool: %s\n", issue.SecurityTool)
+		fmt.Printf("[HUSKYCI][!] Severity: %s\n", issue.Severity)
+		fmt.Printf("[HUSKYCI][!] Reachability: %s\n", issue.Reachability)
+		fmt.Printf("[HUSKYCI][!] Code: %s\n", issue.Code)
+		fmt.Printf("[HUSKYCI][!] Version: %s\n", issue.Version)
+		fmt.Printf("[HUSKYCI][!] Advisory: %s\n", issue.Type)
+		fmt.Printf("[HUSKYCI][!] File: %s\n", issue.File)
+		if issue.Line != "" {
+			fmt.Printf("[HUSKYCI][!] Line: %s\n", issue.Line)
+		}
+		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
+	}
+}
+
 func printSTDOUTOutputGitleaks(issues []types.HuskyCIVulnerability) {
 	for _, issue := range issues {
 		fmt.Println()
@@ -464,5 +733,62 @@ func printSTDOUTOutputGitleaks(issues []types.HuskyCIVulnerability) {
 		fmt.Printf("[HUSKYCI][!] Details: %s\n", issue.Details)
 		fmt.Printf("[HUSKYCI][!] File: %s\n", issue.File)
 		fmt.Printf("[HUSKYCI][!] Code: %s\n", issue.Code)
+		if issue.CommitHash != "" {
+			fmt.Printf("[HUSKYCI][!] Commit: %s\n", issue.CommitHash)
+			fmt.Printf("[HUSKYCI][!] Author: %s\n", issue.CommitAuthor)
+		}
+	}
+}
+
+// PrintPlan prints the securityTests of a dry run either in JSON or the standard output.
+func PrintPlan(plan types.AnalysisPlan) error {
+	if types.IsJSONoutput {
+		jsonReady, err := json.Marshal(plan)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonReady))
+		return nil
+	}
+	fmt.Print(FormatPlan(plan))
+	return nil
+}
+
+// FormatPlan returns the securityTests of a dry run as printed in the standard output.
+func FormatPlan(plan types.AnalysisPlan) string {
+	var output strings.Builder
+
+	languages := []string{}
+	for _, code := range plan.Codes {
+		languages = append(languages, code.Language)
+	}
+	fmt.Fprintf(&output, "[HUSKYCI][*] Languages found: %s\n", strings.Join(languages, ", "))
+
+	fmt.Fprintln(&output, "[HUSKYCI][*] The following securityTests would be executed:")
+	for _, securityTest := range plan.SecurityTests {
+		fmt.Fprintf(&output, "[HUSKYCI][*] %s -> %s:%s (timeout: %ds)\n", securityTest.Name, securityTest.Image, securityTest.ImageTag, securityTest.TimeOutInSeconds)
+	}
+
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintln(&output, "[HUSKYCI][*] The following would be skipped:")
+		for _, skipped := range plan.Skipped {
+			name := skipped.Name
+			if name == "" {
+				name = skipped.Language
+			}
+			fmt.Fprintf(&output, "[HUSKYCI][*] %s: %s\n", name, skipped.Reason)
+		}
+	}
+
+	return output.String()
+}
+
+// printCVSS prints the CVE ids and the CVSS score of a dependency vulnerability, when the securityTool gave them.
+func printCVSS(issue types.HuskyCIVulnerability) {
+	if issue.CVE != "" {
+		fmt.Printf("[HUSKYCI][!] CVE: %s\n", issue.CVE)
+	}
+	if issue.CVSSScore > 0 {
+		fmt.Printf("[HUSKYCI][!] CVSS: %.1f %s\n", issue.CVSSScore, issue.CVSSVector)
 	}
 }
@@ -0,0 +1,40 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis_test
+
+import (
+	"github.com/globocom/huskyCI/client/analysis"
+	"github.com/globocom/huskyCI/client/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FormatPlan", func() {
+
+	Context("When securityTests would be skipped", func() {
+		It("Should list the securityTests that would run and the skipped ones with their reason.", func() {
+			plan := types.AnalysisPlan{
+				Codes: []types.Code{{Language: "Go"}, {Language: "Elixir"}},
+				SecurityTests: []types.PlannedSecurityTest{
+					{Name: "gitleaks", Image: "huskyci/gitleaks", ImageTag: "2.1.0", TimeOutInSeconds: 360},
+					{Name: "gosec", Image: "huskyci/gosec", ImageTag: "2.3.0", TimeOutInSeconds: 360},
+				},
+				Skipped: []types.SkippedSecurityTest{
+					{Name: "trufflehog", Reason: "securityTest is not enabled by default"},
+					{Language: "Elixir", Reason: "no securityTest supports this language"},
+				},
+			}
+			Expect(analysis.FormatPlan(plan)).To(Equal(`[HUSKYCI][*] Languages found: Go, Elixir
+[HUSKYCI][*] The following securityTests would be executed:
+[HUSKYCI][*] gitleaks -> huskyci/gitleaks:2.1.0 (timeout: 360s)
+[HUSKYCI][*] gosec -> huskyci/gosec:2.3.0 (timeout: 360s)
+[HUSKYCI][*] The following would be skipped:
+[HUSKYCI][*] trufflehog: securityTest is not enabled by default
+[HUSKYCI][*] Elixir: no securityTest supports this language
+`))
+		})
+	})
+})
@@ -0,0 +1,124 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"github.com/globocom/huskyCI/client/types"
+	"github.com/globocom/huskyCI/client/util"
+)
+
+type securityTestOutput struct {
+	name   string
+	output types.HuskyCISecurityTestOutput
+}
+
+// securityTestOutputs returns every securityTest output of results in a stable order.
+// The outputs of securityTest instances come last, sorted by name.
+func securityTestOutputs(results types.HuskyCIResults) []securityTestOutput {
+	outputs := []securityTestOutput{
+		{"gosec", results.GoResults.HuskyCIGosecOutput},
+		{"bandit", results.PythonResults.HuskyCIBanditOutput},
+		{"safety", results.PythonResults.HuskyCISafetyOutput},
+		{"npmaudit", results.JavaScriptResults.HuskyCINpmAuditOutput},
+		{"yarnaudit", results.JavaScriptResults.HuskyCIYarnAuditOutput},
+		{"brakeman", results.RubyResults.HuskyCIBrakemanOutput},
+		{"spotbugs", results.JavaResults.HuskyCISpotBugsOutput},
+		{"tfsec", results.HclResults.HuskyCITFSecOutput},
+		{"hadolint", results.DockerfileResults.HuskyCIHadolintOutput},
+		{"dependencycheck", results.SwiftResults.HuskyCIDependencyCheckOutput},
+		{"detekt", results.KotlinResults.HuskyCIDetektOutput},
+		{"dependencycheckgradle", results.KotlinResults.HuskyCIDependencyCheckGradleOutput},
+		{"osvscanner", results.DartResults.HuskyCIOSVScannerOutput},
+		{"govulncheck", results.GoResults.HuskyCIGovulncheckOutput},
+		{"gitleaks", results.GenericResults.HuskyCIGitleaksOutput},
+		{"trivy", results.GenericResults.HuskyCITrivyOutput},
+	}
+	instanceNames := make([]string, 0, len(results.Instances))
+	for instanceName := range results.Instances {
+		instanceNames = append(instanceNames, instanceName)
+	}
+	sort.Strings(instanceNames)
+	for _, instanceName := range instanceNames {
+		outputs = append(outputs, securityTestOutput{instanceName, *results.Instances[instanceName]})
+	}
+	return outputs
+}
+
+// NewJSONReport builds a JSONReport from an analysis. A non nil clientErr is
+// recorded in the report so that failed analyses are still reported.
+func NewJSONReport(analysis types.Analysis, clientErr error) types.JSONReport {
+	report := types.JSONReport{
+		SchemaVersion:    types.JSONReportSchemaVersion,
+		RID:              analysis.RID,
+		RepositoryURL:    analysis.URL,
+		RepositoryBranch: analysis.Branch,
+		RepositoryCommit: analysis.Commit,
+		Status:           analysis.Status,
+		Result:           analysis.Result,
+		ErrorFound:       analysis.ErrorFound,
+		StartedAt:        analysis.StartedAt,
+		FinishedAt:       analysis.FinishedAt,
+		SecurityTests:    []types.JSONReportSecurityTest{},
+		Vulnerabilities:  []types.JSONReportVulnerability{},
+	}
+	if clientErr != nil {
+		report.ClientError = clientErr.Error()
+	}
+
+	for _, container := range analysis.Containers {
+		report.SecurityTests = append(report.SecurityTests, types.JSONReportSecurityTest{
+			Name:        container.SecurityTest.Name,
+			Image:       container.SecurityTest.Image,
+			ImageTag:    container.SecurityTest.ImageTag,
+			Result:      container.CResult,
+			Info:        container.CInfo,
+			Diagnostics: container.Diagnostics,
+		})
+	}
+
+	appendVulns := func(securityTest, severity string, vulns []types.HuskyCIVulnerability) {
+		sortedVulns := append([]types.HuskyCIVulnerability{}, vulns...)
+		util.SortVulnerabilities(sortedVulns)
+		for _, vuln := range sortedVulns {
+			report.Vulnerabilities = append(report.Vulnerabilities, types.JSONReportVulnerability{
+				SecurityTest:         securityTest,
+				HuskyCISeverity:      severity,
+				HuskyCIVulnerability: vuln,
+			})
+		}
+	}
+
+	for _, securityTest := range securityTestOutputs(analysis.HuskyCIResults) {
+		output := securityTest.output
+		appendVulns(securityTest.name, "high", output.HighVulns)
+		appendVulns(securityTest.name, "medium", output.MediumVulns)
+		appendVulns(securityTest.name, "low", output.LowVulns)
+		appendVulns(securityTest.name, "nosec", output.NoSecVulns)
+		appendVulns(securityTest.name, "accepted", output.AcceptedVulns)
+
+		report.Summary.HighVuln += len(output.HighVulns)
+		report.Summary.MediumVuln += len(output.MediumVulns)
+		report.Summary.LowVuln += len(output.LowVulns)
+		report.Summary.NoSecVuln += len(output.NoSecVulns)
+		report.Summary.AcceptedVuln += len(output.AcceptedVulns)
+	}
+	report.Summary.FoundVuln = report.Summary.HighVuln > 0 || report.Summary.MediumVuln > 0
+	report.Summary.FoundInfo = !report.Summary.FoundVuln && (report.Summary.LowVuln > 0 || report.Summary.NoSecVuln > 0)
+
+	return report
+}
+
+// WriteJSONReport writes the JSONReport of an analysis to the file at outputFile.
+func WriteJSONReport(analysis types.Analysis, clientErr error, outputFile string) error {
+	report, err := json.MarshalIndent(NewJSONReport(analysis, clientErr), "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.CreateFile(report, filepath.Dir(outputFile), filepath.Base(outputFile))
+}
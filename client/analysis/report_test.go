@@ -0,0 +1,169 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/globocom/huskyCI/client/analysis"
+	"github.com/globocom/huskyCI/client/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONReport", func() {
+
+	gosecVuln := types.HuskyCIVulnerability{
+		Language:     "Go",
+		SecurityTool: "GoSec",
+		Severity:     "HIGH",
+		File:         "main.go",
+		Line:         "42",
+	}
+	banditVuln := types.HuskyCIVulnerability{
+		Language:     "Python",
+		SecurityTool: "Bandit",
+		Severity:     "LOW",
+		File:         "app.py",
+		Line:         "7",
+	}
+
+	huskyAnalysis := types.Analysis{
+		RID:    "a1b2c3",
+		URL:    "https://github.com/globocom/huskyCI.git",
+		Branch: "master",
+		Commit: "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d",
+		Status: "finished",
+		Result: "failed",
+		Containers: []types.Container{
+			{SecurityTest: types.SecurityTest{Name: "gosec", Image: "huskyci/gosec", ImageTag: "2.3.0"}, CResult: "failed"},
+			{SecurityTest: types.SecurityTest{Name: "bandit", Image: "huskyci/bandit", ImageTag: "1.6.2"}, CResult: "passed"},
+		},
+	}
+	huskyAnalysis.HuskyCIResults.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{gosecVuln}
+	huskyAnalysis.HuskyCIResults.PythonResults.HuskyCIBanditOutput.LowVulns = []types.HuskyCIVulnerability{banditVuln}
+
+	Describe("NewJSONReport", func() {
+		Context("When the analysis has finished", func() {
+			report := analysis.NewJSONReport(huskyAnalysis, nil)
+			It("Should set the schema version.", func() {
+				Expect(report.SchemaVersion).To(Equal(types.JSONReportSchemaVersion))
+			})
+			It("Should set the commit analyzed.", func() {
+				Expect(report.RepositoryCommit).To(Equal("4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d"))
+			})
+			It("Should list all securityTests executed.", func() {
+				Expect(report.SecurityTests).To(HaveLen(2))
+				Expect(report.SecurityTests[0].Name).To(Equal("gosec"))
+				Expect(report.SecurityTests[0].Result).To(Equal("failed"))
+			})
+			It("Should list all vulnerabilities with their huskyCI severity.", func() {
+				Expect(report.Vulnerabilities).To(Equal([]types.JSONReportVulnerability{
+					{SecurityTest: "gosec", HuskyCISeverity: "high", HuskyCIVulnerability: gosecVuln},
+					{SecurityTest: "bandit", HuskyCISeverity: "low", HuskyCIVulnerability: banditVuln},
+				}))
+			})
+			It("Should summarize the vulnerabilities found.", func() {
+				Expect(report.Summary).To(Equal(types.HuskyCISummary{FoundVuln: true, HighVuln: 1, LowVuln: 1}))
+			})
+			It("Should not set a client error.", func() {
+				Expect(report.ClientError).To(BeEmpty())
+			})
+		})
+		Context("When the same findings are returned in a different order", func() {
+			lineTen := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "10", Title: "G101"}
+			lineNine := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "9", Title: "G101"}
+			otherFile := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "auth.go", Line: "42", Title: "G401"}
+			firstAnalysis := huskyAnalysis
+			firstAnalysis.HuskyCIResults.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{lineTen, otherFile, lineNine}
+			secondAnalysis := huskyAnalysis
+			secondAnalysis.HuskyCIResults.GoResults.HuskyCIGosecOutput.HighVulns = []types.HuskyCIVulnerability{lineNine, lineTen, otherFile}
+			It("Should sort the vulnerabilities by file and line.", func() {
+				report := analysis.NewJSONReport(firstAnalysis, nil)
+				Expect(report.Vulnerabilities[0].HuskyCIVulnerability).To(Equal(otherFile))
+				Expect(report.Vulnerabilities[1].HuskyCIVulnerability).To(Equal(lineNine))
+				Expect(report.Vulnerabilities[2].HuskyCIVulnerability).To(Equal(lineTen))
+			})
+			It("Should produce byte-identical reports.", func() {
+				firstReport, err := json.Marshal(analysis.NewJSONReport(firstAnalysis, nil))
+				Expect(err).NotTo(HaveOccurred())
+				secondReport, err := json.Marshal(analysis.NewJSONReport(secondAnalysis, nil))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(firstReport)).To(Equal(string(secondReport)))
+			})
+			It("Should not change the order of the analysis results.", func() {
+				analysis.NewJSONReport(firstAnalysis, nil)
+				Expect(firstAnalysis.HuskyCIResults.GoResults.HuskyCIGosecOutput.HighVulns[0]).To(Equal(lineTen))
+			})
+		})
+		Context("When the analysis has results of securityTest instances", func() {
+			instanceVuln := types.HuskyCIVulnerability{SecurityTool: "Bandit", Severity: "LOW", File: "tests/test_app.py", Line: "3"}
+			instanceAnalysis := huskyAnalysis
+			instanceAnalysis.HuskyCIResults.Instances = map[string]*types.HuskyCISecurityTestOutput{
+				"bandittests": {LowVulns: []types.HuskyCIVulnerability{instanceVuln}},
+			}
+			It("Should list their vulnerabilities under the name of each instance.", func() {
+				report := analysis.NewJSONReport(instanceAnalysis, nil)
+				Expect(report.Vulnerabilities).To(HaveLen(3))
+				Expect(report.Vulnerabilities[2]).To(Equal(types.JSONReportVulnerability{SecurityTest: "bandittests", HuskyCISeverity: "low", HuskyCIVulnerability: instanceVuln}))
+			})
+		})
+		Context("When the analysis has vulnerabilities whose risk was accepted", func() {
+			acceptedVuln := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "HIGH", File: "main.go", Line: "7", AcceptedRisk: &types.AcceptedRisk{AcceptedBy: "husky"}}
+			acceptedAnalysis := huskyAnalysis
+			acceptedAnalysis.HuskyCIResults.GoResults.HuskyCIGosecOutput.AcceptedVulns = []types.HuskyCIVulnerability{acceptedVuln}
+			It("Should list them as accepted without counting them as found.", func() {
+				report := analysis.NewJSONReport(acceptedAnalysis, nil)
+				Expect(report.Vulnerabilities).To(ContainElement(types.JSONReportVulnerability{SecurityTest: "gosec", HuskyCISeverity: "accepted", HuskyCIVulnerability: acceptedVuln}))
+				Expect(report.Summary.AcceptedVuln).To(Equal(1))
+				Expect(report.Summary.HighVuln).To(Equal(1))
+			})
+		})
+		Context("When a securityTest ended with an error", func() {
+			erroredAnalysis := huskyAnalysis
+			erroredAnalysis.Containers = []types.Container{
+				{SecurityTest: types.SecurityTest{Name: "bandit"}, CResult: "error", CInfo: "Error found running container", Diagnostics: "main.py:3 failed to parse"},
+			}
+			It("Should keep the diagnostics of the securityTest.", func() {
+				report := analysis.NewJSONReport(erroredAnalysis, nil)
+				Expect(report.SecurityTests).To(HaveLen(1))
+				Expect(report.SecurityTests[0].Diagnostics).To(Equal("main.py:3 failed to parse"))
+			})
+		})
+		Context("When the analysis has failed", func() {
+			failedAnalysis := huskyAnalysis
+			failedAnalysis.Status = "error running"
+			failedAnalysis.ErrorFound = "error cloning"
+			report := analysis.NewJSONReport(failedAnalysis, errors.New("huskyCI encountered an error"))
+			It("Should keep the findings and record the errors.", func() {
+				Expect(report.Vulnerabilities).To(HaveLen(2))
+				Expect(report.ErrorFound).To(Equal("error cloning"))
+				Expect(report.ClientError).To(Equal("huskyCI encountered an error"))
+			})
+		})
+	})
+
+	Describe("WriteJSONReport", func() {
+		It("Should write the report to the given file.", func() {
+			outputDir, err := ioutil.TempDir("", "huskyci-report")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(outputDir)
+
+			outputFile := filepath.Join(outputDir, "report", "huskyci.json")
+			Expect(analysis.WriteJSONReport(huskyAnalysis, nil, outputFile)).To(Succeed())
+
+			content, err := ioutil.ReadFile(outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			report := types.JSONReport{}
+			Expect(json.Unmarshal(content, &report)).To(Succeed())
+			Expect(report).To(Equal(analysis.NewJSONReport(huskyAnalysis, nil)))
+		})
+	})
+})
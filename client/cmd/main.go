@@ -5,8 +5,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/globocom/huskyCI/client/integration/sonarqube"
 
@@ -15,71 +17,124 @@ import (
 	"github.com/globocom/huskyCI/client/types"
 )
 
+const usage = `Usage: huskyci-client [flags] [JSON]
+
+Flags:
+%s
+Exit codes:
+  0  no vulnerabilities at or above the minimum severity were found
+  1  vulnerabilities at or above the minimum severity were found
+  2  huskyci-client or huskyCI API could not complete the analysis
+`
+
 func main() {
 
 	types.FoundVuln = false
 	types.IsJSONoutput = false
 
-	if len(os.Args) > 1 && os.Args[1] == "JSON" {
+	minSeverity := flag.String("min-severity", "medium", "minimum severity (low, medium or high) that causes a non-zero exit code")
+	outputJSON := flag.String("output-json", "", "path of a file where the complete analysis will be written as a versioned JSON report")
+	dryRun := flag.Bool("dry-run", false, "print the securityTests the analysis would run, based on the languages of the repository, without running them")
+	profile := flag.String("profile", "", "scan profile of huskyCI API, such as quick, whose securityTests run instead of all the default ones")
+	attachRID := flag.String("attach", "", "RID of an analysis already started to wait for, instead of starting a new one")
+	timeout := flag.Duration("timeout", analysis.DefaultMonitorTimeout, "how long to wait for the analysis to finish before exiting with an error")
+	pollInterval := flag.Duration("poll-interval", analysis.DefaultPollInterval, "how often huskyCI API is asked whether the analysis has finished")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if flag.Arg(0) == "JSON" {
 		types.IsJSONoutput = true
 	}
 
 	// step 0: check and set huskyci-client configuration
+	if err := analysis.CheckMinSeverity(*minSeverity); err != nil {
+		if !types.IsJSONoutput {
+			fmt.Println("[HUSKYCI][ERROR] Check flags:", err)
+		}
+		os.Exit(analysis.ExitCodeError)
+	}
 	if err := config.CheckEnvVars(); err != nil {
 		if !types.IsJSONoutput {
 			fmt.Println("[HUSKYCI][ERROR] Check environment variables:", err)
 		}
-		os.Exit(1)
+		os.Exit(analysis.ExitCodeError)
 	}
 	config.SetConfigs()
+	config.ScanProfile = *profile
 
-	// step 1: start analysis and get its RID.
-	if !types.IsJSONoutput {
-		s := fmt.Sprintf("[HUSKYCI][*] %s -> %s", config.RepositoryBranch, config.RepositoryURL)
-		fmt.Println(s)
+	if *dryRun {
+		runDryRun()
 	}
-	RID, err := analysis.StartAnalysis()
-	if err != nil {
-		fmt.Println("[HUSKYCI][ERROR] Sending request to huskyCI:", err)
-		os.Exit(1)
+
+	if *timeout <= 0 || *pollInterval <= 0 {
+		if !types.IsJSONoutput {
+			fmt.Println("[HUSKYCI][ERROR] Check flags: timeout and poll-interval must be positive")
+		}
+		os.Exit(analysis.ExitCodeError)
 	}
-	if !types.IsJSONoutput {
-		fmt.Println("[HUSKYCI][*] huskyCI analysis started!", RID)
+
+	// step 1: start analysis, or attach to the one given, and get its RID.
+	RID := *attachRID
+	if RID == "" {
+		if !types.IsJSONoutput {
+			s := fmt.Sprintf("[HUSKYCI][*] %s -> %s", config.RepositoryBranch, config.RepositoryURL)
+			fmt.Println(s)
+		}
+		var err error
+		RID, err = analysis.StartAnalysis()
+		if err != nil {
+			fmt.Println("[HUSKYCI][ERROR] Sending request to huskyCI:", err)
+			writeJSONReport(*outputJSON, types.Analysis{URL: config.RepositoryURL, Branch: config.RepositoryBranch, Commit: config.RepositoryCommit}, err)
+			os.Exit(analysis.ExitCodeError)
+		}
+		if !types.IsJSONoutput {
+			fmt.Println("[HUSKYCI][*] huskyCI analysis started!", RID)
+		}
+	} else if !types.IsJSONoutput {
+		fmt.Println("[HUSKYCI][*] Waiting for huskyCI analysis", RID)
 	}
 
 	// step 2.1: keep querying huskyCI API to check if a given analysis has already finished.
-	huskyAnalysis, err := analysis.MonitorAnalysis(RID)
+	huskyAnalysis, err := analysis.MonitorAnalysis(RID, *timeout, *pollInterval)
 	if err != nil {
 		s := fmt.Sprintf("[HUSKYCI][ERROR] Monitoring analysis %s: %s", RID, err)
+		if err == analysis.ErrMonitorTimeout {
+			s = fmt.Sprintf("[HUSKYCI][ERROR] Analysis %s did not finish within %s. Wait for it again with -attach %s", RID, *timeout, RID)
+		}
 		fmt.Println(s)
-		os.Exit(1)
+		if huskyAnalysis.RID == "" {
+			huskyAnalysis.RID = RID
+		}
+		writeJSONReport(*outputJSON, huskyAnalysis, err)
+		os.Exit(analysis.ExitCodeError)
 	}
+	writeJSONReport(*outputJSON, huskyAnalysis, nil)
 
 	// step 2.2: prepare the list of securityTests that ran in the analysis.
 	var passedList []string
-	var failedList []string
 	var errorList []string
+	var skippedList []string
 	for _, container := range huskyAnalysis.Containers {
 		securityTestFullName := fmt.Sprintf("%s:%s", container.SecurityTest.Image, container.SecurityTest.ImageTag)
 		if container.CResult == "passed" && container.SecurityTest.Name != "gitauthors" {
 			passedList = append(passedList, securityTestFullName)
-		} else if container.CResult == "failed" {
-			failedList = append(failedList, securityTestFullName)
 		} else if container.CResult == "error" {
-			failedList = append(errorList, securityTestFullName)
+			errorList = append(errorList, securityTestFullName)
+		} else if container.CResult == "skipped" || container.CResult == "cancelled" {
+			skippedList = append(skippedList, container.CInfo)
 		}
 	}
 
 	// step 3: print output based on os.Args(1) parameter received
 	types.IsJSONoutput = false
-	if len(os.Args) > 1 {
+	if flag.NArg() > 0 {
 		types.IsJSONoutput = true
 	}
 
 	err = analysis.PrintResults(huskyAnalysis)
 	if err != nil {
 		fmt.Println("[HUSKYCI][ERROR] Printing output:", err)
-		os.Exit(1)
+		os.Exit(analysis.ExitCodeError)
 	}
 
 	// step 3.5: integration with SonarQube
@@ -90,45 +145,87 @@ func main() {
 		fmt.Println("[HUSKYCI][ERROR] Could not create SonarQube integration file: ", err)
 	}
 
-	// step 4: block developer CI if vulnerabilities were found
-	if !types.FoundVuln && !types.FoundInfo {
-		if !types.IsJSONoutput {
-			if len(errorList) > 0 {
-				fmt.Println("[HUSKYCI][*] The following securityTests failed to run:")
-				fmt.Println("[HUSKYCI][*]", errorList)
-			}
-			fmt.Println("[HUSKYCI][*] The following securityTests were executed and no blocking vulnerabilities were found:")
-			fmt.Println("[HUSKYCI][*]", passedList)
-			fmt.Println("[HUSKYCI][*] No issues were found.")
-		}
-		os.Exit(0)
+	// step 4: block developer CI if vulnerabilities at or above the minimum severity were found
+	exitCode := analysis.ExitCode(analysis.GetTotalSummary(), *minSeverity)
+	failedList := analysis.BreachingSecurityTests(huskyAnalysis.Containers, analysis.GetSummary(), *minSeverity)
+	if types.IsJSONoutput {
+		os.Exit(exitCode)
 	}
 
-	if !types.FoundVuln && types.FoundInfo {
-		if !types.IsJSONoutput {
-			if len(errorList) > 0 {
-				fmt.Println("[HUSKYCI][*] The following securityTests failed to run:")
-				fmt.Println("[HUSKYCI][*]", errorList)
-			}
-			fmt.Println("[HUSKYCI][*] The following securityTests were executed and no blocking vulnerabilities were found:")
-			fmt.Println("[HUSKYCI][*]", passedList)
-			fmt.Println("[HUSKYCI][*] However, some LOW/INFO issues were found...")
-		}
-		os.Exit(0)
+	if len(errorList) > 0 {
+		fmt.Println("[HUSKYCI][*] The following securityTests failed to run:")
+		fmt.Println("[HUSKYCI][*]", errorList)
 	}
 
-	if types.FoundVuln && !types.IsJSONoutput {
-		if len(errorList) > 0 {
-			fmt.Println("[HUSKYCI][*] The following securityTests failed to run:")
-			fmt.Println("[HUSKYCI][*]", errorList)
+	for _, skippedInfo := range skippedList {
+		fmt.Println("[HUSKYCI][*]", skippedInfo)
+	}
+
+	if huskyAnalysis.FailFastAborted {
+		fmt.Println("[HUSKYCI][*] The analysis was aborted by fail fast and its results are partial.")
+	}
+
+	if huskyAnalysis.Result == "no scannable content" {
+		languages := []string{}
+		for _, code := range huskyAnalysis.Codes {
+			languages = append(languages, code.Language)
 		}
-		if len(passedList) > 0 {
-			fmt.Println("[HUSKYCI][*] The following securityTests were executed and no blocking vulnerabilities were found:")
-			fmt.Println("[HUSKYCI][*]", passedList)
+		fmt.Println("[HUSKYCI][*] No scannable content was found in the repository, so no securityTest ran. Languages detected:", languages)
+	}
+
+	if exitCode == analysis.ExitCodeClean {
+		fmt.Println("[HUSKYCI][*] The following securityTests were executed and no blocking vulnerabilities were found:")
+		fmt.Println("[HUSKYCI][*]", passedList)
+		if types.FoundVuln || types.FoundInfo {
+			fmt.Printf("[HUSKYCI][*] However, some issues below %s severity were found...\n", *minSeverity)
+		} else {
+			fmt.Println("[HUSKYCI][*] No issues were found.")
 		}
-		fmt.Println("[HUSKYCI][*] Some HIGH/MEDIUM issues were found in these securityTests:")
-		fmt.Println("[HUSKYCI][*]", failedList)
+		os.Exit(exitCode)
+	}
+
+	if len(passedList) > 0 {
+		fmt.Println("[HUSKYCI][*] The following securityTests were executed and no blocking vulnerabilities were found:")
+		fmt.Println("[HUSKYCI][*]", passedList)
+	}
+	fmt.Printf("[HUSKYCI][*] Some issues at or above %s severity were found in these securityTests:\n", *minSeverity)
+	fmt.Println("[HUSKYCI][*]", failedList)
+
+	os.Exit(exitCode)
+}
+
+// writeJSONReport writes the analysis report to outputFile when --output-json is set.
+func writeJSONReport(outputFile string, huskyAnalysis types.Analysis, clientErr error) {
+	if outputFile == "" {
+		return
+	}
+	if err := analysis.WriteJSONReport(huskyAnalysis, clientErr, outputFile); err != nil {
+		fmt.Println("[HUSKYCI][ERROR] Could not create JSON report file:", err)
+	}
+}
+
+// runDryRun prints the securityTests an analysis of the repository would run and exits.
+func runDryRun() {
+	if !types.IsJSONoutput {
+		s := fmt.Sprintf("[HUSKYCI][*] Dry run: %s -> %s", config.RepositoryBranch, config.RepositoryURL)
+		fmt.Println(s)
+	}
+	plan, err := analysis.PlanAnalysis()
+	if err != nil {
+		fmt.Println("[HUSKYCI][ERROR] Sending dry run request to huskyCI:", err)
+		os.Exit(analysis.ExitCodeError)
 	}
+	if err := analysis.PrintPlan(plan); err != nil {
+		fmt.Println("[HUSKYCI][ERROR] Printing output:", err)
+		os.Exit(analysis.ExitCodeError)
+	}
+	os.Exit(analysis.ExitCodeClean)
+}
 
-	os.Exit(190)
+func printUsage() {
+	var flags strings.Builder
+	flag.CommandLine.SetOutput(&flags)
+	flag.PrintDefaults()
+	flag.CommandLine.SetOutput(os.Stderr)
+	fmt.Fprintf(os.Stderr, usage, flags.String())
 }
@@ -5,6 +5,7 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 )
@@ -12,6 +13,39 @@ import (
 // RepositoryURL stores the repository URL of the project to be analyzed.
 var RepositoryURL string
 
+// RepositorySubPath stores the directory of the repository to be analyzed. An empty value analyzes the whole repository.
+var RepositorySubPath string
+
+// RepositoryCommit stores the commit SHA of the project to be analyzed. When set, it is analyzed instead of the tip of the branch.
+var RepositoryCommit string
+
+// RepositoryBaseRef stores the branch or commit a pull request is compared to. When set, only the issues found in the files changed since it are reported.
+var RepositoryBaseRef string
+
+// ImageReference stores the container image built from the project to be analyzed. When set, trivy also scans it.
+var ImageReference string
+
+// RepositorySSHPrivateKey stores the deploy key used to clone the project to be analyzed over SSH. An empty value uses the key of the API.
+var RepositorySSHPrivateKey string
+
+// FailFastSeverity stores the severity that aborts the analysis at its first finding. An empty value runs every securityTest.
+var FailFastSeverity string
+
+// SecurityTestArgs stores, by securityTest tool, the extra arguments appended to the command of the securityTests. The API rejects flags outside its allowlist.
+var SecurityTestArgs map[string][]string
+
+// Tags stores the key/value pairs, such as the team or the pipeline, the analysis is tagged with to be grouped and filtered. They do not change its result.
+var Tags map[string]string
+
+// SecurityTestEnv stores, by securityTest tool, the environment variables injected into the securityTest containers. A value secret:NAME references a secret of the secret store of the API.
+var SecurityTestEnv map[string]map[string]string
+
+// ScanProfile stores the scan profile of the API whose securityTests run instead of all the default ones. It is set by the --profile flag.
+var ScanProfile string
+
+// ForceAnalysis stores if a new analysis is to be started even if the API has a cached one of the same commit.
+var ForceAnalysis bool
+
 // HuskyAPI stores the address of Husky's API.
 var HuskyAPI string
 
@@ -24,13 +58,31 @@ var HuskyToken string
 // HuskyUseTLS stores if huskyCI is to use an HTTPS connection.
 var HuskyUseTLS bool
 
+// HuskyClientCertFile stores the path of the certificate the client authenticates with to the APIs requiring mutual TLS.
+var HuskyClientCertFile string
+
+// HuskyClientKeyFile stores the path of the key of HuskyClientCertFile.
+var HuskyClientKeyFile string
+
 // SetConfigs sets all configuration needed to start the client.
 func SetConfigs() {
 	RepositoryURL = os.Getenv(`HUSKYCI_CLIENT_REPO_URL`)
 	RepositoryBranch = os.Getenv(`HUSKYCI_CLIENT_REPO_BRANCH`)
+	RepositorySubPath = os.Getenv(`HUSKYCI_CLIENT_REPO_SUBPATH`)
+	RepositoryCommit = os.Getenv(`HUSKYCI_CLIENT_REPO_COMMIT`)
+	RepositoryBaseRef = os.Getenv(`HUSKYCI_CLIENT_REPO_BASE_REF`)
+	ImageReference = os.Getenv(`HUSKYCI_CLIENT_IMAGE_REFERENCE`)
+	RepositorySSHPrivateKey = os.Getenv(`HUSKYCI_CLIENT_REPO_SSH_PRIVATE_KEY`)
+	FailFastSeverity = os.Getenv(`HUSKYCI_CLIENT_FAIL_FAST_SEVERITY`)
+	SecurityTestArgs = getSecurityTestArgs()
+	Tags = getTags()
+	SecurityTestEnv = getSecurityTestEnv()
+	ForceAnalysis = getForceAnalysis()
 	HuskyAPI = os.Getenv(`HUSKYCI_CLIENT_API_ADDR`)
 	HuskyToken = os.Getenv(`HUSKYCI_CLIENT_TOKEN`)
 	HuskyUseTLS = getUseTLS()
+	HuskyClientCertFile = os.Getenv(`HUSKYCI_CLIENT_CERT_PATH`)
+	HuskyClientKeyFile = os.Getenv(`HUSKYCI_CLIENT_KEY_PATH`)
 }
 
 // CheckEnvVars checks if all environment vars are set.
@@ -41,7 +93,19 @@ func CheckEnvVars() error {
 		"HUSKYCI_CLIENT_REPO_URL",
 		"HUSKYCI_CLIENT_REPO_BRANCH",
 		// "HUSKYCI_CLIENT_TOKEN", (optional for now)
+		// "HUSKYCI_CLIENT_REPO_SUBPATH", (optional)
+		// "HUSKYCI_CLIENT_REPO_COMMIT", (optional)
+		// "HUSKYCI_CLIENT_REPO_BASE_REF", (optional)
+		// "HUSKYCI_CLIENT_IMAGE_REFERENCE", (optional)
+		// "HUSKYCI_CLIENT_REPO_SSH_PRIVATE_KEY", (optional)
+		// "HUSKYCI_CLIENT_FAIL_FAST_SEVERITY", (optional)
+		// "HUSKYCI_CLIENT_SECURITYTEST_ARGS", (optional)
+		// "HUSKYCI_CLIENT_TAGS", (optional)
+		// "HUSKYCI_CLIENT_SECURITYTEST_ENV", (optional)
+		// "HUSKYCI_CLIENT_FORCE_ANALYSIS", (optional)
 		// "HUSKYCI_CLIENT_API_USE_HTTPS", (optional)
+		// "HUSKYCI_CLIENT_CERT_PATH", (optional)
+		// "HUSKYCI_CLIENT_KEY_PATH", (optional)
 		// "HUSKYCI_CLIENT_NPM_DEP_URL", (optional)
 	}
 
@@ -72,3 +136,43 @@ func getUseTLS() bool {
 	}
 	return false
 }
+
+// getForceAnalysis returns TRUE or FALSE retrieved from an environment variable.
+func getForceAnalysis() bool {
+	option := os.Getenv("HUSKYCI_CLIENT_FORCE_ANALYSIS")
+	if option == "true" || option == "1" || option == "TRUE" {
+		return true
+	}
+	return false
+}
+
+// getSecurityTestArgs returns the securityTest arguments retrieved from an environment variable holding
+// a JSON object such as {"gosec": ["-exclude=G104"]}. An unset or invalid value sets no arguments.
+func getSecurityTestArgs() map[string][]string {
+	securityTestArgs := make(map[string][]string)
+	if err := json.Unmarshal([]byte(os.Getenv("HUSKYCI_CLIENT_SECURITYTEST_ARGS")), &securityTestArgs); err != nil {
+		return nil
+	}
+	return securityTestArgs
+}
+
+// getTags returns the tags of the analysis retrieved from an environment variable holding a JSON object such
+// as {"team": "security", "pipeline": "1234"}. An unset or invalid value sets no tag.
+func getTags() map[string]string {
+	tags := make(map[string]string)
+	if err := json.Unmarshal([]byte(os.Getenv("HUSKYCI_CLIENT_TAGS")), &tags); err != nil {
+		return nil
+	}
+	return tags
+}
+
+// getSecurityTestEnv returns the securityTest environment variables retrieved from an environment variable
+// holding a JSON object such as {"safety": {"ADVISORY_DB_TOKEN": "secret:advisory-db-token"}}. An unset or
+// invalid value sets no environment variable.
+func getSecurityTestEnv() map[string]map[string]string {
+	securityTestEnv := make(map[string]map[string]string)
+	if err := json.Unmarshal([]byte(os.Getenv("HUSKYCI_CLIENT_SECURITYTEST_ENV")), &securityTestEnv); err != nil {
+		return nil
+	}
+	return securityTestEnv
+}
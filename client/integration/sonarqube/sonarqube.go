@@ -68,6 +68,46 @@ func GenerateOutputFile(analysis types.Analysis, outputPath, outputFileName stri
 	allVulns = append(allVulns, analysis.HuskyCIResults.JavaResults.HuskyCISpotBugsOutput.MediumVulns...)
 	allVulns = append(allVulns, analysis.HuskyCIResults.JavaResults.HuskyCISpotBugsOutput.HighVulns...)
 
+	// tfsec
+	allVulns = append(allVulns, analysis.HuskyCIResults.HclResults.HuskyCITFSecOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.HclResults.HuskyCITFSecOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.HclResults.HuskyCITFSecOutput.HighVulns...)
+
+	// hadolint
+	allVulns = append(allVulns, analysis.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.DockerfileResults.HuskyCIHadolintOutput.HighVulns...)
+
+	// dependencycheck
+	allVulns = append(allVulns, analysis.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.SwiftResults.HuskyCIDependencyCheckOutput.HighVulns...)
+
+	// detekt
+	allVulns = append(allVulns, analysis.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.KotlinResults.HuskyCIDetektOutput.HighVulns...)
+
+	// dependencycheckgradle
+	allVulns = append(allVulns, analysis.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.KotlinResults.HuskyCIDependencyCheckGradleOutput.HighVulns...)
+
+	// osvscanner
+	allVulns = append(allVulns, analysis.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.DartResults.HuskyCIOSVScannerOutput.HighVulns...)
+
+	// govulncheck
+	allVulns = append(allVulns, analysis.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.GoResults.HuskyCIGovulncheckOutput.HighVulns...)
+
+	// trivy
+	allVulns = append(allVulns, analysis.HuskyCIResults.GenericResults.HuskyCITrivyOutput.LowVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.GenericResults.HuskyCITrivyOutput.MediumVulns...)
+	allVulns = append(allVulns, analysis.HuskyCIResults.GenericResults.HuskyCITrivyOutput.HighVulns...)
+
 	var sonarOutput HuskyCISonarOutput
 	sonarOutput.Issues = make([]SonarIssue, 0)
 
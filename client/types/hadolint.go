@@ -0,0 +1,18 @@
+// Copyright 2019 Globo.com authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+// HadolintOutput is the struct that holds all data from Hadolint output.
+type HadolintOutput []HadolintIssue
+
+// HadolintIssue is the struct that holds detailed information of issues from Hadolint output.
+type HadolintIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Column  int    `json:"column"`
+	File    string `json:"file"`
+	Level   string `json:"level"`
+	Line    int    `json:"line"`
+}
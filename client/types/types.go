@@ -10,7 +10,7 @@ import (
 	"gopkg.in/mgo.v2/bson"
 )
 
-// FoundVuln is the boolean that will be checked to return an os.exit(0) or os.exit(1)
+// FoundVuln is the boolean that will be checked to verify if medium/high severity vulnerabilities were found.
 var FoundVuln bool
 
 // FoundInfo is the boolean that will be checked to verify if only low/info severity vulnerabilites were found.
@@ -21,8 +21,43 @@ var IsJSONoutput bool
 
 // JSONPayload is a struct that represents the JSON payload needed to make a HuskyCI API request.
 type JSONPayload struct {
-	RepositoryURL    string `json:"repositoryURL"`
-	RepositoryBranch string `json:"repositoryBranch"`
+	RepositoryURL     string                       `json:"repositoryURL"`
+	RepositoryBranch  string                       `json:"repositoryBranch"`
+	RepositorySubPath string                       `json:"repositorySubPath,omitempty"`
+	RepositoryCommit  string                       `json:"repositoryCommit,omitempty"`
+	RepositoryBaseRef string                       `json:"repositoryBaseRef,omitempty"`
+	ImageReference    string                       `json:"imageReference,omitempty"`
+	FailFastSeverity  string                       `json:"failFastSeverity,omitempty"`
+	SSHPrivateKey     string                       `json:"sshPrivateKey,omitempty"`
+	SecurityTestArgs  map[string][]string          `json:"securityTestArgs,omitempty"`
+	SecurityTestEnv   map[string]map[string]string `json:"securityTestEnv,omitempty"`
+	Tags              map[string]string            `json:"tags,omitempty"`
+	Force             bool                         `json:"force,omitempty"`
+	Profile           string                       `json:"profile,omitempty"`
+}
+
+// AnalysisPlan is the struct that represents the securityTests an analysis would run, returned by a dry run.
+type AnalysisPlan struct {
+	Codes         []Code                `json:"codes"`
+	SecurityTests []PlannedSecurityTest `json:"securityTests"`
+	Skipped       []SkippedSecurityTest `json:"skipped,omitempty"`
+}
+
+// PlannedSecurityTest is the struct that represents a securityTest an analysis would run.
+type PlannedSecurityTest struct {
+	Name             string `json:"name"`
+	Image            string `json:"image"`
+	ImageTag         string `json:"imageTag"`
+	Type             string `json:"type"`
+	Language         string `json:"language,omitempty"`
+	TimeOutInSeconds int    `json:"timeOutInSeconds"`
+}
+
+// SkippedSecurityTest is the struct that represents a securityTest, or a language without securityTests, an analysis would skip.
+type SkippedSecurityTest struct {
+	Name     string `json:"name,omitempty"`
+	Language string `json:"language,omitempty"`
+	Reason   string `json:"reason"`
 }
 
 // Target is the struct that represents HuskyCI API target
@@ -39,6 +74,7 @@ type Analysis struct {
 	RID            string         `bson:"RID" json:"RID"`
 	URL            string         `bson:"repositoryURL" json:"repositoryURL"`
 	Branch         string         `bson:"repositoryBranch" json:"repositoryBranch"`
+	Commit         string         `bson:"repositoryCommit,omitempty" json:"repositoryCommit,omitempty"`
 	Status         string         `bson:"status" json:"status"`
 	Result         string         `bson:"result" json:"result"`
 	Containers     []Container    `bson:"containers" json:"containers"`
@@ -47,6 +83,8 @@ type Analysis struct {
 	FinishedAt     time.Time      `bson:"finishedAt" json:"finishedAt"`
 	Codes          []Code         `bson:"codes" json:"codes"`
 	HuskyCIResults HuskyCIResults `bson:"huskyciresults,omitempty" json:"huskyciresults"`
+	// FailFastAborted states that the analysis was aborted by fail fast and holds partial results.
+	FailFastAborted bool `bson:"failFastAborted,omitempty" json:"failFastAborted,omitempty"`
 }
 
 // Code is the struct that stores all data from code found in a repository.
@@ -63,7 +101,13 @@ type HuskyCIResults struct {
 	RubyResults       RubyResults       `bson:"rubyresults,omitempty" json:"rubyresults,omitempty"`
 	JavaResults       JavaResults       `bson:"javaresults,omitempty" json:"javaresults,omitempty"`
 	HclResults        HclResults        `bson:"hclresults,omitempty" json:"hclresults,omitempty"`
+	DockerfileResults DockerfileResults `bson:"dockerfileresults,omitempty" json:"dockerfileresults,omitempty"`
+	SwiftResults      SwiftResults      `bson:"swiftresults,omitempty" json:"swiftresults,omitempty"`
+	KotlinResults     KotlinResults     `bson:"kotlinresults,omitempty" json:"kotlinresults,omitempty"`
+	DartResults       DartResults       `bson:"dartresults,omitempty" json:"dartresults,omitempty"`
 	GenericResults    GenericResults    `bson:"genericresults,omitempty" json:"genericresults,omitempty"`
+	// Instances holds, by securityTest name, the results of securityTests running a tool under a name of their own.
+	Instances map[string]*HuskyCISecurityTestOutput `bson:"instances,omitempty" json:"instances,omitempty"`
 }
 
 // Container is the struct that stores all data from a container run.
@@ -76,6 +120,15 @@ type Container struct {
 	CInfo        string       `bson:"cInfo" json:"cInfo"`
 	StartedAt    time.Time    `bson:"startedAt" json:"startedAt"`
 	FinishedAt   time.Time    `bson:"finishedAt" json:"finishedAt"`
+	// DurationInSeconds is the time elapsed between StartedAt and FinishedAt.
+	DurationInSeconds float64 `bson:"durationInSeconds" json:"durationInSeconds"`
+	// Image is the reference of the image that ran, pinned to its digest when the docker API knows it.
+	Image string `bson:"image,omitempty" json:"image,omitempty"`
+	// ExitCode is the exit code of the container or -1 if it did not exit.
+	ExitCode int    `bson:"exitCode" json:"exitCode"`
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+	// Diagnostics is the tail of what the scanner printed to stderr when the securityTest ended with an error.
+	Diagnostics string `bson:"diagnostics,omitempty" json:"diagnostics,omitempty"`
 }
 
 // SecurityTest is the struct that stores all data from the security tests to be executed.
@@ -92,19 +145,37 @@ type SecurityTest struct {
 
 // HuskyCIVulnerability is the struct that stores vulnerability information.
 type HuskyCIVulnerability struct {
-	Language       string `json:"language,omitempty"`
-	SecurityTool   string `json:"securitytool,omitempty"`
-	Severity       string `json:"severity,omitempty"`
-	Confidence     string `json:"confidence,omitempty"`
-	File           string `json:"file,omitempty"`
-	Line           string `json:"line,omitempty"`
-	Code           string `json:"code,omitempty"`
-	Details        string `json:"details,omitempty"`
-	Type           string `json:"type,omitempty"`
-	Title          string `json:"title,omitempty"`
-	VunerableBelow string `json:"vulnerablebelow,omitempty"`
-	Version        string `json:"version,omitempty"`
-	Occurrences    int    `json:"occurrences,omitempty"`
+	Language       string        `json:"language,omitempty"`
+	SecurityTool   string        `json:"securitytool,omitempty"`
+	Severity       string        `json:"severity,omitempty"`
+	Confidence     string        `json:"confidence,omitempty"`
+	File           string        `json:"file,omitempty"`
+	Line           string        `json:"line,omitempty"`
+	Code           string        `json:"code,omitempty"`
+	Details        string        `json:"details,omitempty"`
+	Type           string        `json:"type,omitempty"`
+	Title          string        `json:"title,omitempty"`
+	VunerableBelow string        `json:"vulnerablebelow,omitempty"`
+	Version        string        `json:"version,omitempty"`
+	Occurrences    int           `json:"occurrences,omitempty"`
+	CommitHash     string        `json:"commitHash,omitempty"`
+	CommitAuthor   string        `json:"commitAuthor,omitempty"`
+	CVE            string        `json:"cve,omitempty"`
+	CVSSScore      float64       `json:"cvssScore,omitempty"`
+	CVSSVector     string        `json:"cvssVector,omitempty"`
+	SecurityTools  []string      `json:"securitytools,omitempty"`
+	Fingerprint    string        `json:"fingerprint,omitempty"`
+	Reachability   string        `json:"reachability,omitempty"`
+	Snippet        string        `json:"snippet,omitempty"`
+	AcceptedRisk   *AcceptedRisk `json:"acceptedRisk,omitempty"`
+}
+
+// AcceptedRisk is the decision of an API user to accept the risk of a vulnerability until the optional expiry.
+type AcceptedRisk struct {
+	AcceptedBy string     `json:"acceptedBy"`
+	AcceptedAt time.Time  `json:"acceptedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Note       string     `json:"note,omitempty"`
 }
 
 // JSONOutput is a truct that represents huskyCI output in a JSON format.
@@ -115,13 +186,18 @@ type JSONOutput struct {
 	RubyResults       RubyResults       `json:"rubyresults,omitempty"`
 	JavaResults       JavaResults       `json:"javaresults,omitempty"`
 	HclResults        HclResults        `json:"hclresults,omitempty"`
+	DockerfileResults DockerfileResults `json:"dockerfileresults,omitempty"`
+	SwiftResults      SwiftResults      `json:"swiftresults,omitempty"`
+	KotlinResults     KotlinResults     `json:"kotlinresults,omitempty"`
+	DartResults       DartResults       `json:"dartresults,omitempty"`
 	GenericResults    GenericResults    `json:"genericresults,omitempty"`
 	Summary           Summary           `json:"summary,omitempty"`
 }
 
 // GoResults represents all Golang security tests results.
 type GoResults struct {
-	HuskyCIGosecOutput HuskyCISecurityTestOutput `bson:"gosecoutput,omitempty" json:"gosecoutput,omitempty"`
+	HuskyCIGosecOutput       HuskyCISecurityTestOutput `bson:"gosecoutput,omitempty" json:"gosecoutput,omitempty"`
+	HuskyCIGovulncheckOutput HuskyCISecurityTestOutput `bson:"govulncheckoutput,omitempty" json:"govulncheckoutput,omitempty"`
 }
 
 // PythonResults represents all Python security tests results.
@@ -149,6 +225,7 @@ type RubyResults struct {
 // GenericResults represents all generic securityTests results.
 type GenericResults struct {
 	HuskyCIGitleaksOutput HuskyCISecurityTestOutput `bson:"gitleaksoutput,omitempty" json:"gitleaksoutput,omitempty"`
+	HuskyCITrivyOutput    HuskyCISecurityTestOutput `bson:"trivyoutput,omitempty" json:"trivyoutput,omitempty"`
 }
 
 // HclResults represents all HCL security tests results.
@@ -156,29 +233,59 @@ type HclResults struct {
 	HuskyCITFSecOutput HuskyCISecurityTestOutput `bson:"tfsecoutput,omitempty" json:"tfsecoutput,omitempty"`
 }
 
+// DockerfileResults represents all Dockerfile security tests results.
+type DockerfileResults struct {
+	HuskyCIHadolintOutput HuskyCISecurityTestOutput `bson:"hadolintoutput,omitempty" json:"hadolintoutput,omitempty"`
+}
+
+// SwiftResults represents all Swift security tests results.
+type SwiftResults struct {
+	HuskyCIDependencyCheckOutput HuskyCISecurityTestOutput `bson:"dependencycheckoutput,omitempty" json:"dependencycheckoutput,omitempty"`
+}
+
+// KotlinResults represents all Kotlin security tests results.
+type KotlinResults struct {
+	HuskyCIDetektOutput                HuskyCISecurityTestOutput `bson:"detektoutput,omitempty" json:"detektoutput,omitempty"`
+	HuskyCIDependencyCheckGradleOutput HuskyCISecurityTestOutput `bson:"dependencycheckgradleoutput,omitempty" json:"dependencycheckgradleoutput,omitempty"`
+}
+
+// DartResults represents all Dart security tests results.
+type DartResults struct {
+	HuskyCIOSVScannerOutput HuskyCISecurityTestOutput `bson:"osvscanneroutput,omitempty" json:"osvscanneroutput,omitempty"`
+}
+
 // HuskyCISecurityTestOutput stores all Low, Medium and High vulnerabilities for a sec test
 type HuskyCISecurityTestOutput struct {
 	NoSecVulns  []HuskyCIVulnerability `bson:"nosecvulns,omitempty" json:"nosecvulns,omitempty"`
 	LowVulns    []HuskyCIVulnerability `bson:"lowvulns,omitempty" json:"lowvulns,omitempty"`
 	MediumVulns []HuskyCIVulnerability `bson:"mediumvulns,omitempty" json:"mediumvulns,omitempty"`
 	HighVulns   []HuskyCIVulnerability `bson:"highvulns,omitempty" json:"highvulns,omitempty"`
+	// AcceptedVulns are the vulnerabilities whose risk was accepted, which do not fail the analysis.
+	AcceptedVulns []HuskyCIVulnerability `bson:"acceptedvulns,omitempty" json:"acceptedvulns,omitempty"`
 }
 
 // Summary holds a summary of the information on all security tests.
 type Summary struct {
-	URL              string         `json:"repositoryURL"`
-	Branch           string         `json:"repositoryBranch"`
-	RID              string         `json:"RID"`
-	GosecSummary     HuskyCISummary `json:"gosecsummary,omitempty"`
-	BanditSummary    HuskyCISummary `json:"banditsummary,omitempty"`
-	SafetySummary    HuskyCISummary `json:"safetysummary,omitempty"`
-	NpmAuditSummary  HuskyCISummary `json:"npmauditsummary,omitempty"`
-	YarnAuditSummary HuskyCISummary `json:"yarnauditsummary,omitempty"`
-	BrakemanSummary  HuskyCISummary `json:"brakemansummary,omitempty"`
-	SpotBugsSummary  HuskyCISummary `json:"spotbugssummary,omitempty"`
-	GitleaksSummary  HuskyCISummary `json:"gitleakssummary,omitempty"`
-	TFSecSummary     HuskyCISummary `json:"tfsecsummary,omitempty"`
-	TotalSummary     HuskyCISummary `json:"totalsummary,omitempty"`
+	URL                          string         `json:"repositoryURL"`
+	Branch                       string         `json:"repositoryBranch"`
+	RID                          string         `json:"RID"`
+	GosecSummary                 HuskyCISummary `json:"gosecsummary,omitempty"`
+	BanditSummary                HuskyCISummary `json:"banditsummary,omitempty"`
+	SafetySummary                HuskyCISummary `json:"safetysummary,omitempty"`
+	NpmAuditSummary              HuskyCISummary `json:"npmauditsummary,omitempty"`
+	YarnAuditSummary             HuskyCISummary `json:"yarnauditsummary,omitempty"`
+	BrakemanSummary              HuskyCISummary `json:"brakemansummary,omitempty"`
+	SpotBugsSummary              HuskyCISummary `json:"spotbugssummary,omitempty"`
+	GitleaksSummary              HuskyCISummary `json:"gitleakssummary,omitempty"`
+	TFSecSummary                 HuskyCISummary `json:"tfsecsummary,omitempty"`
+	HadolintSummary              HuskyCISummary `json:"hadolintsummary,omitempty"`
+	DependencyCheckSummary       HuskyCISummary `json:"dependencychecksummary,omitempty"`
+	TrivySummary                 HuskyCISummary `json:"trivysummary,omitempty"`
+	DetektSummary                HuskyCISummary `json:"detektsummary,omitempty"`
+	DependencyCheckGradleSummary HuskyCISummary `json:"dependencycheckgradlesummary,omitempty"`
+	OSVScannerSummary            HuskyCISummary `json:"osvscannersummary,omitempty"`
+	GovulncheckSummary           HuskyCISummary `json:"govulnchecksummary,omitempty"`
+	TotalSummary                 HuskyCISummary `json:"totalsummary,omitempty"`
 }
 
 // HuskyCISummary is the struct that holds summary information.
@@ -189,4 +296,45 @@ type HuskyCISummary struct {
 	LowVuln    int  `json:"lowvuln,omitempty"`
 	MediumVuln int  `json:"mediumvuln,omitempty"`
 	HighVuln   int  `json:"highvuln,omitempty"`
+	// AcceptedVuln counts the vulnerabilities whose risk was accepted, which are not part of the other counts.
+	AcceptedVuln int `json:"acceptedvuln,omitempty"`
+}
+
+// JSONReportSchemaVersion is the version of the JSONReport schema. It must be bumped whenever a field is renamed or removed.
+const JSONReportSchemaVersion = "1.0"
+
+// JSONReport is the versioned report written by huskyci-client when --output-json is set.
+type JSONReport struct {
+	SchemaVersion    string                    `json:"schemaVersion"`
+	RID              string                    `json:"RID"`
+	RepositoryURL    string                    `json:"repositoryURL"`
+	RepositoryBranch string                    `json:"repositoryBranch"`
+	RepositoryCommit string                    `json:"repositoryCommit,omitempty"`
+	Status           string                    `json:"status"`
+	Result           string                    `json:"result"`
+	ErrorFound       string                    `json:"errorFound,omitempty"`
+	ClientError      string                    `json:"clientError,omitempty"`
+	StartedAt        time.Time                 `json:"startedAt"`
+	FinishedAt       time.Time                 `json:"finishedAt"`
+	SecurityTests    []JSONReportSecurityTest  `json:"securityTests"`
+	Vulnerabilities  []JSONReportVulnerability `json:"vulnerabilities"`
+	Summary          HuskyCISummary            `json:"summary"`
+}
+
+// JSONReportSecurityTest holds the result of a securityTest executed in an analysis.
+type JSONReportSecurityTest struct {
+	Name     string `json:"name"`
+	Image    string `json:"image"`
+	ImageTag string `json:"imageTag"`
+	Result   string `json:"result"`
+	Info     string `json:"info,omitempty"`
+	// Diagnostics is the tail of what the scanner printed to stderr when the securityTest ended with an error.
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
+
+// JSONReportVulnerability is a vulnerability found by a securityTest and the huskyCI severity it was classified as.
+type JSONReportVulnerability struct {
+	SecurityTest    string `json:"securityTest"`
+	HuskyCISeverity string `json:"huskyciSeverity"`
+	HuskyCIVulnerability
 }
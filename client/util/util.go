@@ -8,14 +8,26 @@ import (
 	"bufio"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/globocom/huskyCI/client/types"
 )
 
-// NewClient returns an http client.
-func NewClient(httpsEnable bool) (*http.Client, error) {
+// NewClient returns an http client. When clientCertFile and clientKeyFile are set, it presents their certificate
+// to huskyCI, for the APIs that require mutual TLS.
+func NewClient(httpsEnable bool, clientCertFile, clientKeyFile string) (*http.Client, error) {
+	if (clientCertFile == "") != (clientKeyFile == "") {
+		return nil, errors.New("a client certificate requires both its certificate and key files")
+	}
+	if clientCertFile != "" && !httpsEnable {
+		return nil, errors.New("a client certificate requires an HTTPS connection")
+	}
 	if httpsEnable {
 		// Tries to find system's certificate pool
 		caCertPool, _ := x509.SystemCertPool() // #nosec - SystemCertPool tries to get local cert pool, if it fails, a new cer pool is created
@@ -38,6 +50,13 @@ func NewClient(httpsEnable bool) (*http.Client, error) {
 				},
 			},
 		}
+		if clientCertFile != "" {
+			certificate, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			client.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{certificate}
+		}
 		return client, nil
 	}
 
@@ -110,3 +129,98 @@ func CreateFile(output []byte, filePath, fileName string) error {
 
 	return nil
 }
+
+var severityOrder = map[string]int{
+	"high":   0,
+	"medium": 1,
+	"low":    2,
+}
+
+// LessVulnerability reports whether a must be sorted before b. Vulnerabilities are sorted by securityTool,
+// severity, from high to low, CVSS score, from the highest, file, line, title and then by their remaining fields,
+// matching the order of huskyCI API results.
+func LessVulnerability(a, b types.HuskyCIVulnerability) bool {
+	if a.SecurityTool != b.SecurityTool {
+		return a.SecurityTool < b.SecurityTool
+	}
+	if rankA, rankB := severityRank(a.Severity), severityRank(b.Severity); rankA != rankB {
+		return rankA < rankB
+	}
+	if a.Severity != b.Severity {
+		return a.Severity < b.Severity
+	}
+	if a.CVSSScore != b.CVSSScore {
+		return a.CVSSScore > b.CVSSScore
+	}
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		lineA, errA := strconv.Atoi(a.Line)
+		lineB, errB := strconv.Atoi(b.Line)
+		if errA == nil && errB == nil {
+			return lineA < lineB
+		}
+		return a.Line < b.Line
+	}
+	for _, fields := range [][2]string{
+		{a.Title, b.Title},
+		{a.Details, b.Details},
+		{a.Code, b.Code},
+		{a.Type, b.Type},
+		{a.Version, b.Version},
+		{a.Confidence, b.Confidence},
+		{a.CVE, b.CVE},
+		{a.CommitHash, b.CommitHash},
+	} {
+		if fields[0] != fields[1] {
+			return fields[0] < fields[1]
+		}
+	}
+	return false
+}
+
+func severityRank(severity string) int {
+	if rank, ok := severityOrder[strings.ToLower(severity)]; ok {
+		return rank
+	}
+	return len(severityOrder)
+}
+
+// SortVulnerabilities sorts vulns in place using LessVulnerability.
+func SortVulnerabilities(vulns []types.HuskyCIVulnerability) {
+	sort.SliceStable(vulns, func(i, j int) bool {
+		return LessVulnerability(vulns[i], vulns[j])
+	})
+}
+
+// SortResults sorts every vulnerability list of results using LessVulnerability.
+func SortResults(results *types.HuskyCIResults) {
+	outputs := []*types.HuskyCISecurityTestOutput{
+		&results.GoResults.HuskyCIGosecOutput,
+		&results.PythonResults.HuskyCIBanditOutput,
+		&results.PythonResults.HuskyCISafetyOutput,
+		&results.JavaScriptResults.HuskyCINpmAuditOutput,
+		&results.JavaScriptResults.HuskyCIYarnAuditOutput,
+		&results.RubyResults.HuskyCIBrakemanOutput,
+		&results.JavaResults.HuskyCISpotBugsOutput,
+		&results.HclResults.HuskyCITFSecOutput,
+		&results.DockerfileResults.HuskyCIHadolintOutput,
+		&results.SwiftResults.HuskyCIDependencyCheckOutput,
+		&results.KotlinResults.HuskyCIDetektOutput,
+		&results.KotlinResults.HuskyCIDependencyCheckGradleOutput,
+		&results.DartResults.HuskyCIOSVScannerOutput,
+		&results.GoResults.HuskyCIGovulncheckOutput,
+		&results.GenericResults.HuskyCIGitleaksOutput,
+		&results.GenericResults.HuskyCITrivyOutput,
+	}
+	for _, output := range results.Instances {
+		outputs = append(outputs, output)
+	}
+	for _, output := range outputs {
+		SortVulnerabilities(output.NoSecVulns)
+		SortVulnerabilities(output.LowVulns)
+		SortVulnerabilities(output.MediumVulns)
+		SortVulnerabilities(output.HighVulns)
+	}
+}
@@ -5,16 +5,91 @@
 package util_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/globocom/huskyCI/client/types"
 	"github.com/globocom/huskyCI/client/util"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// writeKeyPair writes a new self-signed client certificate and its key, PEM encoded, into dir and returns their paths.
+func writeKeyPair(dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "huskyci-client"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	certFile, keyFile := filepath.Join(dir, "client-cert.pem"), filepath.Join(dir, "client-key.pem")
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+	Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).To(Succeed())
+	return certFile, keyFile
+}
+
 var _ = Describe("Util", func() {
+	Describe("NewClient", func() {
+		var dir, certFile, keyFile string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "huskyci-client-tls")
+			Expect(err).NotTo(HaveOccurred())
+			certFile, keyFile = writeKeyPair(dir)
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		Context("When a client certificate is set", func() {
+			It("Should present it over HTTPS.", func() {
+				client, err := util.NewClient(true, certFile, keyFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.Transport.(*http.Transport).TLSClientConfig.Certificates).To(HaveLen(1))
+			})
+			It("Should return an error without HTTPS.", func() {
+				_, err := util.NewClient(false, certFile, keyFile)
+				Expect(err).To(MatchError(ContainSubstring("requires an HTTPS connection")))
+			})
+		})
+		Context("When only the certificate or the key is set", func() {
+			It("Should return an error.", func() {
+				_, err := util.NewClient(true, certFile, "")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("When the key does not match the certificate", func() {
+			It("Should return an error.", func() {
+				_, err := util.NewClient(true, certFile, certFile)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("When no client certificate is set", func() {
+			It("Should present none.", func() {
+				client, err := util.NewClient(true, "", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.Transport.(*http.Transport).TLSClientConfig.Certificates).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("CreateFile", func() {
 		outputFileName := "sonarqube_test.json"
 		outputFilePath := testOutputFilesPath + outputFileName
@@ -43,4 +118,24 @@ var _ = Describe("Util", func() {
 			Expect(outputString).To(Equal(fileString))
 		})
 	})
+
+	Describe("SortResults", func() {
+		gosecLow := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "LOW", File: "main.go", Line: "3", Title: "G104"}
+		gosecMedium := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "MEDIUM", File: "main.go", Line: "12", Title: "G401"}
+		gosecOtherLine := types.HuskyCIVulnerability{SecurityTool: "GoSec", Severity: "MEDIUM", File: "main.go", Line: "2", Title: "G401"}
+		gitleaks := types.HuskyCIVulnerability{SecurityTool: "GitLeaks", Severity: "MEDIUM", File: "config.yml", Line: "1", Title: "AWS key"}
+		results := types.HuskyCIResults{}
+		results.GoResults.HuskyCIGosecOutput.MediumVulns = []types.HuskyCIVulnerability{gosecMedium, gosecOtherLine}
+		results.GoResults.HuskyCIGosecOutput.LowVulns = []types.HuskyCIVulnerability{gosecLow}
+		results.GenericResults.HuskyCIGitleaksOutput.MediumVulns = []types.HuskyCIVulnerability{gitleaks}
+		util.SortResults(&results)
+		It("Should sort vulnerabilities by line number.", func() {
+			Expect(results.GoResults.HuskyCIGosecOutput.MediumVulns).To(Equal([]types.HuskyCIVulnerability{gosecOtherLine, gosecMedium}))
+		})
+		It("Should sort vulnerabilities by securityTool and severity.", func() {
+			vulns := []types.HuskyCIVulnerability{gosecLow, gosecMedium, gitleaks}
+			util.SortVulnerabilities(vulns)
+			Expect(vulns).To(Equal([]types.HuskyCIVulnerability{gitleaks, gosecMedium, gosecLow}))
+		})
+	})
 })
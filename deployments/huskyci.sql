@@ -53,6 +53,8 @@ CREATE TABLE IF NOT EXISTS public.analysis (
     "RID" text NOT NULL,
     "repositoryURL" text NOT NULL,
     "repositoryBranch" text NOT NULL,
+    "repositorySubPath" text,
+    "repositoryCommit" text,
     "commitAuthors" text[],
     status text NOT NULL,
     result text,
@@ -61,7 +63,16 @@ CREATE TABLE IF NOT EXISTS public.analysis (
     "startedAt" timestamp without time zone,
     "finishedAt" timestamp without time zone,
     codes jsonb,
-    huskyciresults jsonb
+    huskyciresults jsonb,
+    "failFastAborted" boolean,
+    "timeOutInSeconds" integer,
+    "failFastSeverity" text,
+    "originAnalysisID" text,
+    "imageReference" text,
+    "repositoryBaseRef" text,
+    summary jsonb,
+    "securityTestArgs" jsonb,
+    source text
 );
 
 
@@ -94,12 +105,49 @@ CREATE TABLE IF NOT EXISTS public."securityTest" (
     type text NOT NULL,
     language text NOT NULL,
     "default" boolean NOT NULL,
-    "timeOutSeconds" integer NOT NULL
+    "timeOutSeconds" integer NOT NULL,
+    "envAllowlist" text,
+    tool text,
+    "failSeverity" text,
+    "graceDays" integer
 );
 
 
 ALTER TABLE public."securityTest" OWNER TO "huskyCIUser";
 
+--
+-- Name: securityTestRollout; Type: TABLE; Schema: public; Owner: huskyCIUser
+--
+
+CREATE TABLE IF NOT EXISTS public."securityTestRollout" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL,
+    "repositoryURL" text NOT NULL,
+    "securityTestName" text NOT NULL,
+    "startedAt" timestamp without time zone NOT NULL,
+    UNIQUE ("repositoryURL", "securityTestName")
+);
+
+
+ALTER TABLE public."securityTestRollout" OWNER TO "huskyCIUser";
+
+--
+-- Name: acceptedRisk; Type: TABLE; Schema: public; Owner: huskyCIUser
+--
+
+CREATE TABLE IF NOT EXISTS public."acceptedRisk" (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL,
+    "repositoryURL" text NOT NULL,
+    fingerprint text NOT NULL,
+    "acceptedBy" text NOT NULL,
+    "acceptedAt" timestamp without time zone NOT NULL,
+    "expiresAt" timestamp without time zone,
+    note text,
+    UNIQUE ("repositoryURL", fingerprint)
+);
+
+
+ALTER TABLE public."acceptedRisk" OWNER TO "huskyCIUser";
+
 --
 -- Name: user; Type: TABLE; Schema: public; Owner: huskyCIUser
 --
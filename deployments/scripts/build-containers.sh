@@ -17,4 +17,11 @@ docker build deployments/dockerfiles/npmaudit/ -t huskyci/yarnaudit:latest
 docker build deployments/dockerfiles/safety/ -t huskyci/safety:latest
 docker build deployments/dockerfiles/gitleaks/ -t huskyci/gitleaks:latest
 docker build deployments/dockerfiles/spotbugs/ -t huskyci/spotbugs:latest
-docker build deployments/dockerfiles/tfsec/ -t huskyci/tfsec:latest
\ No newline at end of file
+docker build deployments/dockerfiles/tfsec/ -t huskyci/tfsec:latest
+docker build deployments/dockerfiles/hadolint/ -t huskyci/hadolint:latest
+docker build deployments/dockerfiles/dependencycheck/ -t huskyci/dependencycheck:latest
+docker build deployments/dockerfiles/trivy/ -t huskyci/trivy:latest
+docker build deployments/dockerfiles/detekt/ -t huskyci/detekt:latest
+docker build deployments/dockerfiles/dependencycheckgradle/ -t huskyci/dependencycheckgradle:latest
+docker build deployments/dockerfiles/osvscanner/ -t huskyci/osvscanner:latest
+docker build deployments/dockerfiles/govulncheck/ -t huskyci/govulncheck:latest
\ No newline at end of file
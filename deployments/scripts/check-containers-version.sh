@@ -19,6 +19,13 @@ safetyVersion=$(docker run --rm huskyci/safety:latest safety --version | awk -F
 gitleaksVersion=$(docker run --rm huskyci/gitleaks:latest gitleaks --version)
 spotbugsVersion=$(docker run --rm huskyci/spotbugs:latest cat /opt/spotbugs/version)
 tfsecVersion=$(docker run --rm huskyci/tfsec:latest ./tfsec -v)
+hadolintVersion=$(docker run --rm huskyci/hadolint:latest hadolint --version | awk -F " " '{print $4}')
+dependencyCheckVersion=$(docker run --rm huskyci/dependencycheck:latest /usr/share/dependency-check/bin/dependency-check.sh --version | awk -F " " '{print $NF}')
+trivyVersion=$(docker run --rm huskyci/trivy:latest trivy --version | head -n 1 | awk -F " " '{print $2}')
+detektVersion=$(docker run --rm huskyci/detekt:latest cat /opt/detekt/version)
+dependencyCheckGradleVersion=$(docker run --rm huskyci/dependencycheckgradle:latest cat /opt/dependencycheck/version)
+osvScannerVersion=$(docker run --rm huskyci/osvscanner:latest osv-scanner --version | head -n 1 | awk -F " " '{print $NF}')
+govulncheckVersion=$(docker run --rm huskyci/govulncheck:latest govulncheck -version | grep Scanner | awk -F "@" '{print $NF}')
 
 echo "bandit: $banditVersion"
 echo "brakeman: $brakemanVersion"
@@ -30,4 +37,11 @@ echo "yarnauditVersion: $yarnAuditVersion"
 echo "safetyVersion: $safetyVersion"
 echo "gitleaksVersion: $gitleaksVersion"
 echo "spotbugsVersion: $spotbugsVersion"
-echo "tfsecVersion: $tfsecVersion"
\ No newline at end of file
+echo "tfsecVersion: $tfsecVersion"
+echo "hadolintVersion: $hadolintVersion"
+echo "dependencyCheckVersion: $dependencyCheckVersion"
+echo "trivyVersion: $trivyVersion"
+echo "detektVersion: $detektVersion"
+echo "dependencyCheckGradleVersion: $dependencyCheckGradleVersion"
+echo "osvScannerVersion: $osvScannerVersion"
+echo "govulncheckVersion: $govulncheckVersion"
\ No newline at end of file
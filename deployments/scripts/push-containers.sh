@@ -18,6 +18,13 @@ safetyVersion=$(docker run --rm huskyci/safety:latest safety --version | awk -F
 gitleaksVersion=$(docker run --rm huskyci/gitleaks:latest gitleaks --version)
 spotbugsVersion=$(docker run --rm huskyci/spotbugs:latest cat /opt/spotbugs/version)
 tfsecVersion=$(docker run --rm huskyci/tfsec:latest ./tfsec -v)
+hadolintVersion=$(docker run --rm huskyci/hadolint:latest hadolint --version | awk -F " " '{print $4}')
+dependencyCheckVersion=$(docker run --rm huskyci/dependencycheck:latest /usr/share/dependency-check/bin/dependency-check.sh --version | awk -F " " '{print $NF}')
+trivyVersion=$(docker run --rm huskyci/trivy:latest trivy --version | head -n 1 | awk -F " " '{print $2}')
+detektVersion=$(docker run --rm huskyci/detekt:latest cat /opt/detekt/version)
+dependencyCheckGradleVersion=$(docker run --rm huskyci/dependencycheckgradle:latest cat /opt/dependencycheck/version)
+osvScannerVersion=$(docker run --rm huskyci/osvscanner:latest osv-scanner --version | head -n 1 | awk -F " " '{print $NF}')
+govulncheckVersion=$(docker run --rm huskyci/govulncheck:latest govulncheck -version | grep Scanner | awk -F "@" '{print $NF}')
 
 docker tag "huskyci/bandit:latest" "huskyci/bandit:$banditVersion"
 docker tag "huskyci/brakeman:latest" "huskyci/brakeman:$brakemanVersion"
@@ -30,6 +37,12 @@ docker tag "huskyci/safety:latest" "huskyci/safety:$safetyVersion"
 docker tag "huskyci/gitleaks:latest" "huskyci/gitleaks:$gitleaksVersion"
 docker tag "huskyci/spotbugs:latest" "huskyci/spotbugs:$spotbugsVersion"
 docker tag "huskyci/tfsec:latest" "huskyci/tfsec:$tfsecVersion"
+docker tag "huskyci/hadolint:latest" "huskyci/hadolint:$hadolintVersion"
+docker tag "huskyci/dependencycheck:latest" "huskyci/dependencycheck:$dependencyCheckVersion"
+docker tag "huskyci/trivy:latest" "huskyci/trivy:$trivyVersion"
+docker tag "huskyci/detekt:latest" "huskyci/detekt:$detektVersion"
+docker tag "huskyci/dependencycheckgradle:latest" "huskyci/dependencycheckgradle:$dependencyCheckGradleVersion"
+docker tag "huskyci/govulncheck:latest" "huskyci/govulncheck:$govulncheckVersion"
 
 docker push "huskyci/bandit:latest" && docker push "huskyci/bandit:$banditVersion"
 docker push "huskyci/brakeman:latest" && docker push "huskyci/brakeman:$brakemanVersion"
@@ -42,3 +55,10 @@ docker push "huskyci/safety:latest" && docker push "huskyci/safety:$safetyVersio
 docker push "huskyci/gitleaks:latest" && docker push "huskyci/gitleaks:$gitleaksVersion"
 docker push "huskyci/spotbugs:latest" && docker push "huskyci/spotbugs:$spotbugsVersion"
 docker push "huskyci/tfsec:latest" && docker push "huskyci/tfsec:$tfsecVersion"
+docker push "huskyci/hadolint:latest" && docker push "huskyci/hadolint:$hadolintVersion"
+docker push "huskyci/dependencycheck:latest" && docker push "huskyci/dependencycheck:$dependencyCheckVersion"
+docker push "huskyci/trivy:latest" && docker push "huskyci/trivy:$trivyVersion"
+docker push "huskyci/detekt:latest" && docker push "huskyci/detekt:$detektVersion"
+docker push "huskyci/dependencycheckgradle:latest" && docker push "huskyci/dependencycheckgradle:$dependencyCheckGradleVersion"
+docker push "huskyci/osvscanner:latest" && docker push "huskyci/osvscanner:$osvScannerVersion"
+docker push "huskyci/govulncheck:latest" && docker push "huskyci/govulncheck:$govulncheckVersion"